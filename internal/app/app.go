@@ -0,0 +1,420 @@
+// Package app собирает сервис целиком: конфиг -> хранилище -> сервисы -> роутер, и управляет
+// его жизненным циклом. cmd/app только читает окружение и вызывает Run
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"avito_backend_task/internal/audit"
+	"avito_backend_task/internal/auth"
+	"avito_backend_task/internal/config"
+	"avito_backend_task/internal/jobs"
+	"avito_backend_task/internal/live"
+	"avito_backend_task/internal/metrics"
+	"avito_backend_task/internal/notify"
+	pullrequest "avito_backend_task/internal/service/pullrequest"
+	"avito_backend_task/internal/service/stats"
+	team "avito_backend_task/internal/service/team"
+	user "avito_backend_task/internal/service/user"
+	transport "avito_backend_task/internal/transport/http"
+	"avito_backend_task/internal/transport/http/middleware"
+	"avito_backend_task/internal/transport/http/validation"
+	"avito_backend_task/pkg/clock"
+	"avito_backend_task/pkg/logctx"
+)
+
+// shutdownTimeout - сколько Run ждёт завершения запросов и фоновых воркеров после сигнала
+const shutdownTimeout = 10 * time.Second
+
+type Option func(*App)
+
+// WithLogger заменяет JSON-логгер в stdout, который New собирает по LOG_LEVEL
+func WithLogger(lg *slog.Logger) Option {
+	return func(a *App) {
+		a.lg = lg
+	}
+}
+
+// WithClock задаёт часы сервисов и планировщика задач
+func WithClock(c clock.Clock) Option {
+	return func(a *App) {
+		a.clock = c
+	}
+}
+
+// WithRepositories подменяет хранилище на Postgres, New тогда не подключается к БД
+func WithRepositories(repos Repositories) Option {
+	return func(a *App) {
+		a.repos = &repos
+	}
+}
+
+// WithoutBackgroundJobs не запускает планировщик задач независимо от JOBS_ENABLED
+func WithoutBackgroundJobs() Option {
+	return func(a *App) {
+		a.jobsDisabled = true
+	}
+}
+
+// dispatcher - фоновая очередь уведомлений, name попадает в лог при неудачной остановке
+type dispatcher struct {
+	name  string
+	queue *notify.Dispatcher
+}
+
+type App struct {
+	cfg          *config.Config
+	lg           *slog.Logger
+	clock        clock.Clock
+	repos        *Repositories
+	jobsDisabled bool
+
+	handler     http.Handler
+	server      *http.Server
+	scheduler   *jobs.Scheduler
+	dispatchers []dispatcher
+	auditSink   audit.Sink
+	// closeStorage закрывает пул БД, nil для хранилища из WithRepositories
+	closeStorage func()
+	serveErr     chan error
+}
+
+func New(cfg *config.Config, opts ...Option) (_ *App, err error) {
+	a := &App{cfg: cfg, clock: clock.New(), serveErr: make(chan error, 1)}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	if a.lg == nil {
+		// request_id и tx_id из контекста попадают во все записи, сделанные с ним
+		a.lg = slog.New(logctx.NewHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			Level: cfg.ParseLogLevel(),
+		})))
+	}
+	logger := a.lg
+
+	if a.repos == nil {
+		repos, closeStorage, err := openPostgres(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		a.repos, a.closeStorage = &repos, closeStorage
+	}
+	defer func() {
+		if err != nil {
+			a.close()
+		}
+	}()
+	if err := a.repos.validate(a.jobsEnabled()); err != nil {
+		return nil, err
+	}
+	teamRepo, userRepo, prRepo := a.repos.Teams, a.repos.Users, a.repos.PullRequests
+
+	teamService := team.NewTeamService(teamRepo, userRepo, a.repos.TxManager, logger,
+		team.WithReviewersPerPR(cfg.Reviewers.ReviewersPerPR),
+		team.WithMaxMembers(cfg.Teams.MaxMembers),
+		team.WithReviewLoad(prRepo, cfg.Reviewers.MaxOpenReviewsPerUser),
+		team.WithClock(a.clock))
+	registry := prometheus.NewRegistry()
+
+	// уведомления о ревьюерах: каналы включаются по отдельности и получают события из одной очереди
+	var channels []notify.Sink
+	if cfg.Notify.LogEnabled {
+		channels = append(channels, notify.NewLogSink(logger))
+	}
+	if cfg.Notify.SlackEnabled {
+		if cfg.Notify.SlackWebhookURL == "" {
+			return nil, fmt.Errorf("NOTIFY_SLACK_ENABLED requires SLACK_WEBHOOK_URL")
+		}
+		channels = append(channels, notify.NewSlackSink(cfg.Notify.SlackWebhookURL, nil, userRepo))
+	}
+	// счётчики общие для webhook, каналов и webhook команд, регистрируются один раз
+	var counters *metrics.NotificationCounters
+	if cfg.Notify.WebhookURL != "" || len(channels) > 0 || cfg.Notify.TeamWebhooksEnabled {
+		counters = metrics.NewNotificationCounters(registry)
+	}
+	// webhook команд доставляются через свою очередь, чтобы повторы не задерживали остальные уведомления
+	var teamHooks *notify.Dispatcher
+	if cfg.Notify.TeamWebhooksEnabled {
+		retry := notify.RetryPolicy{MaxAttempts: cfg.Notify.TeamWebhookMaxAttempts, Backoff: cfg.Notify.TeamWebhookBackoff}
+		sink := notify.NewTeamWebhookSink(teamRepo, &http.Client{Timeout: cfg.Notify.Timeout}, retry,
+			metrics.NewTeamWebhookCounters(registry).Failed)
+		teamHooks = notify.NewDispatcher(sink, logger,
+			notify.WithQueueSize(cfg.Notify.QueueSize),
+			notify.WithTimeout(retry.MaxDuration(cfg.Notify.Timeout)),
+			notify.WithCounters(counters.Dropped, counters.Failed))
+	}
+	// аудит назначений пишется после коммита, без AUDIT_SINK записи отбрасываются
+	a.auditSink, err = audit.New(audit.Config{
+		Kind:     cfg.Audit.Sink,
+		FilePath: cfg.Audit.FilePath,
+		URL:      cfg.Audit.URL,
+		Timeout:  cfg.Audit.Timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating audit sink: %w", err)
+	}
+	// подписчики /pullRequest/events получают изменения только с этой реплики
+	liveUpdates := live.NewBroker()
+
+	userOpts := []user.Option{
+		user.WithConfig(user.Config{
+			AutoCloseOrphanedPRs: cfg.Reviewers.AutoCloseOrphanedPRs,
+			MaxTeamMembers:       cfg.Teams.MaxMembers,
+			ReviewersPerPR:       cfg.Reviewers.ReviewersPerPR,
+			TopUpLimit:           cfg.Reviewers.ReactivationTopUpLimit,
+			ExcludeGroupAuthors:  cfg.Reviewers.ExcludeGroupAuthors,
+			EventsRetention:      cfg.Events.Retention,
+		}),
+		user.WithTeams(teamRepo),
+		user.WithAudit(a.auditSink),
+		user.WithLiveUpdates(liveUpdates),
+		user.WithClock(a.clock),
+	}
+	var notifier *notify.Notifier
+	var reviewerQueues []notify.Publisher
+	if len(channels) > 0 {
+		notifications := notify.NewDispatcher(notify.Channels(channels...), logger,
+			notify.WithQueueSize(cfg.Notify.QueueSize),
+			notify.WithTimeout(cfg.Notify.Timeout),
+			notify.WithCounters(counters.Dropped, counters.Failed))
+		a.dispatchers = append(a.dispatchers, dispatcher{name: "reviewer notifications", queue: notifications})
+		reviewerQueues = append(reviewerQueues, notifications)
+	}
+	if teamHooks != nil {
+		a.dispatchers = append(a.dispatchers, dispatcher{name: "team webhooks", queue: teamHooks})
+		reviewerQueues = append(reviewerQueues, teamHooks)
+	}
+	if len(reviewerQueues) > 0 {
+		notifier = notify.NewNotifier(notify.Publishers(reviewerQueues...))
+		userOpts = append(userOpts, user.WithNotifier(notifier))
+	}
+	userService := user.NewUserService(userRepo, prRepo, a.repos.TxManager, logger, userOpts...)
+
+	prOpts := []pullrequest.Option{
+		pullrequest.WithConfig(pullrequest.Config{
+			ExcludeOutsideWorkingHours: cfg.Reviewers.ExcludeOutsideWorkingHours,
+			NoReviewersPolicy:          pullrequest.NoReviewersPolicy(cfg.Reviewers.ResolvedNoReviewersPolicy()),
+			AllowCrossTeamReviewers:    cfg.Reviewers.AllowCrossTeamReviewers,
+			MaxOpenReviews:             cfg.Reviewers.MaxOpenReviewsPerUser,
+			MaxReviewers:               cfg.Reviewers.MaxReviewersPerPR,
+			ReviewersPerPR:             cfg.Reviewers.ReviewersPerPR,
+			RetryUnderstaffed:          cfg.Reviewers.RetryUnderstaffedPRs,
+			LatencyMinSamples:          cfg.Stats.LatencyMinSamples,
+			AllowMissingAuthorTeam:     cfg.Reviewers.AllowMissingAuthorTeam,
+			RejectInactiveAuthors:      cfg.Reviewers.RejectInactiveAuthors,
+			AutoCreateAuthor:           cfg.Reviewers.AutoCreateAuthor,
+			DefaultTeam:                cfg.Reviewers.DefaultTeam,
+			MaxTeamMembers:             cfg.Teams.MaxMembers,
+			ExcludeGroupAuthors:        cfg.Reviewers.ExcludeGroupAuthors,
+			MaxReassignmentsPerHour:    cfg.Reviewers.MaxReassignmentsPerHour,
+			PartialReassignPolicy:      pullrequest.PartialReassignPolicy(cfg.Reviewers.PartialReassignPolicy),
+			ShadowStrategy:             pullrequest.ShadowStrategy(cfg.Reviewers.ShadowStrategy),
+			MergeRequiresActor:         cfg.Reviewers.MergeRequiresActor,
+			MergeActorPolicy:           pullrequest.MergeActorPolicy(cfg.Reviewers.MergeActorPolicy),
+			MandatoryReviewers:         cfg.Reviewers.MandatoryReviewerMap(),
+		}),
+		pullrequest.WithBlackouts(teamRepo),
+		pullrequest.WithTeams(teamRepo),
+		pullrequest.WithAudit(a.auditSink),
+		pullrequest.WithLiveUpdates(liveUpdates),
+		pullrequest.WithClock(a.clock),
+	}
+	if notifier != nil {
+		prOpts = append(prOpts, pullrequest.WithNotifier(notifier))
+	}
+	if cfg.Reviewers.ShadowStrategy != "" {
+		shadow := metrics.NewShadowCounters(registry)
+		prOpts = append(prOpts, pullrequest.WithShadowCounters(shadow.Agreed, shadow.Disagreed))
+	}
+	// уведомления доставляются в фоне, сбой webhook не влияет на ответы API
+	var eventQueues []notify.Publisher
+	if cfg.Notify.WebhookURL != "" {
+		events := notify.NewDispatcher(notify.NewWebhookSink(cfg.Notify.WebhookURL, nil), logger,
+			notify.WithQueueSize(cfg.Notify.QueueSize),
+			notify.WithTimeout(cfg.Notify.Timeout),
+			notify.WithCounters(counters.Dropped, counters.Failed))
+		a.dispatchers = append(a.dispatchers, dispatcher{name: "notification dispatcher", queue: events})
+		eventQueues = append(eventQueues, events)
+	}
+	if teamHooks != nil {
+		eventQueues = append(eventQueues, teamHooks)
+	}
+	if len(eventQueues) > 0 {
+		prOpts = append(prOpts, pullrequest.WithEvents(notify.Publishers(eventQueues...)))
+	}
+	prService := pullrequest.NewPullRequestService(prRepo, userRepo, a.repos.TxManager, logger, prOpts...)
+
+	statsService := stats.NewStatsService(a.repos.Stats, logger, stats.WithCacheTTL(cfg.Stats.CacheTTL))
+
+	a.scheduler = jobs.NewScheduler(a.repos.Locker, logger,
+		jobs.WithJitter(cfg.Jobs.Jitter),
+		jobs.WithClock(a.clock))
+
+	sampler := metrics.NewSampler(prRepo, userRepo, metrics.NewBusinessGauges(registry), logger)
+	if err := a.scheduler.Register(sampler.Job(cfg.Metrics.SampleInterval)); err != nil {
+		return nil, fmt.Errorf("error registering metrics sampler: %w", err)
+	}
+	// в read-only режиме назначать ревьюеров некуда, задача только писала бы ошибки
+	if !cfg.Server.ReadOnlyMode {
+		if err := a.scheduler.Register(prService.PendingAssignmentJob(cfg.Jobs.PendingAssignmentInterval)); err != nil {
+			return nil, fmt.Errorf("error registering pending assignment job: %w", err)
+		}
+	}
+
+	services := transport.Services{
+		TeamService:        teamService,
+		UserService:        userService,
+		PullRequestService: prService,
+		JobScheduler:       a.scheduler,
+		ReviewerService:    prService,
+		SchemaChecker:      a.repos.Schema,
+		StatsService:       statsService,
+	}
+
+	apiKeys, err := auth.ParseKeys(cfg.Auth.APIKeys)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing api keys: %w", err)
+	}
+	if len(apiKeys) == 0 {
+		logger.Warn("API_KEYS is empty, requests are not authenticated")
+	}
+
+	routerOpts := []transport.RouterOption{
+		transport.WithAPIKeys(apiKeys),
+		transport.WithMetrics(metrics.Handler(registry)),
+	}
+	if cfg.Server.EnableCompression {
+		routerOpts = append(routerOpts, transport.WithCompression(cfg.Server.CompressionMinSize))
+	}
+	if cfg.Server.ReadOnlyMode {
+		logger.Warn("READ_ONLY_MODE is enabled, mutating requests are rejected")
+		routerOpts = append(routerOpts, transport.WithReadOnly())
+	}
+	if cfg.Server.MaxInFlight > 0 {
+		shedMetrics := metrics.NewLoadShedMetrics(registry)
+		routerOpts = append(routerOpts, transport.WithLoadShedding(middleware.LoadShedConfig{
+			MaxInFlight:  cfg.Server.MaxInFlight,
+			QueueTimeout: cfg.Server.InFlightQueueTimeout,
+			RetryAfter:   time.Second,
+			InFlight:     shedMetrics.InFlight,
+			Shed:         shedMetrics.Shed,
+		}))
+	}
+	a.handler = transport.NewRouter(services, logger, validation.New(), routerOpts...)
+
+	a.server = &http.Server{
+		Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
+		Handler:      a.handler,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+	}
+
+	return a, nil
+}
+
+// Handler - роутер со всеми маршрутами, без сервера и фоновых воркеров
+func (a *App) Handler() http.Handler {
+	return a.handler
+}
+
+func (a *App) jobsEnabled() bool {
+	return a.cfg.Jobs.Enabled && !a.jobsDisabled
+}
+
+// Start слушает адрес из конфига и запускает фоновые воркеры. Отмена ctx их не останавливает,
+// порядок остановки задаёт Shutdown
+func (a *App) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", a.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", a.server.Addr, err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	if a.jobsEnabled() {
+		a.scheduler.Start(ctx)
+	}
+	for _, d := range a.dispatchers {
+		d.queue.Start(ctx)
+	}
+
+	go func() {
+		a.lg.Info("service started", slog.String("addr", listener.Addr().String()))
+		if err := a.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.serveErr <- err
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown дожидается текущих запросов, затем останавливает воркеры и закрывает хранилище.
+// Ошибка - только если сервер не успел завершить запросы
+func (a *App) Shutdown(ctx context.Context) error {
+	err := a.server.Shutdown(ctx)
+
+	// задачи используют пул, поэтому останавливаются до его закрытия
+	if err := a.scheduler.Shutdown(ctx); err != nil {
+		a.lg.Error("job scheduler forced to shutdown", slog.Any("error", err))
+	}
+	for _, d := range a.dispatchers {
+		if err := d.queue.Shutdown(ctx); err != nil {
+			a.lg.Error("background worker forced to shutdown", slog.String("worker", d.name), slog.Any("error", err))
+		}
+	}
+	a.close()
+
+	return err
+}
+
+func (a *App) close() {
+	if a.auditSink != nil {
+		if err := a.auditSink.Close(); err != nil {
+			a.lg.Error("error closing audit sink", slog.Any("error", err))
+		}
+	}
+	if a.closeStorage != nil {
+		a.closeStorage()
+	}
+}
+
+// Run собирает приложение, обслуживает запросы до отмены ctx и корректно останавливается
+func Run(ctx context.Context, cfg *config.Config, opts ...Option) error {
+	a, err := New(cfg, opts...)
+	if err != nil {
+		return err
+	}
+
+	if err := a.Start(ctx); err != nil {
+		a.close()
+		return err
+	}
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+	case serveErr = <-a.serveErr:
+		a.lg.Error("failed to start service", slog.Any("error", serveErr))
+	}
+
+	a.lg.Info("Shutting down service...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
+
+	if err := a.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("service forced to shutdown: %w", err)
+	}
+
+	a.lg.Info("service stopped")
+	return serveErr
+}
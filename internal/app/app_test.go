@@ -0,0 +1,171 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/config"
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/repository"
+	"avito_backend_task/internal/service/stats"
+	"avito_backend_task/pkg/clock"
+	"avito_backend_task/pkg/db/migrate"
+)
+
+// memoryStore - команды и их участники в памяти. Тесты трогают только /team/add и /team/get,
+// остальные методы репозиториев паникуют на nil встроенном интерфейсе
+type memoryStore struct {
+	mu    sync.Mutex
+	teams map[string][]domain.TeamMember
+}
+
+type memoryTeams struct {
+	TeamRepository
+	*memoryStore
+}
+
+type memoryUsers struct {
+	UserRepository
+	*memoryStore
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{teams: make(map[string][]domain.TeamMember)}
+}
+
+func (s memoryTeams) Exists(_ context.Context, teamName string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.teams[teamName]
+	return ok, nil
+}
+
+func (s memoryTeams) Create(_ context.Context, teamName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teams[teamName] = nil
+	return nil
+}
+
+func (s memoryUsers) Upsert(_ context.Context, member domain.TeamMember, teamName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teams[teamName] = append(s.teams[teamName], member)
+	return nil
+}
+
+func (s memoryTeams) GetTeamByName(_ context.Context, teamName string) (*domain.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	members, ok := s.teams[teamName]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &domain.Team{TeamName: teamName, Members: members}, nil
+}
+
+type noTx struct{}
+
+func (noTx) Do(ctx context.Context, fn func(ctx context.Context) error) error         { return fn(ctx) }
+func (noTx) DoReadOnly(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }
+func (noTx) DoNested(ctx context.Context, fn func(ctx context.Context) error) error   { return fn(ctx) }
+
+type schemaFunc func() migrate.Status
+
+func (f schemaFunc) Status(context.Context) (migrate.Status, error) {
+	return f(), nil
+}
+
+func newTestApp(t *testing.T, cfg *config.Config) *App {
+	t.Helper()
+
+	store := newMemoryStore()
+	a, err := New(cfg,
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		WithClock(clock.NewFake(time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC))),
+		WithoutBackgroundJobs(),
+		WithRepositories(Repositories{
+			Teams: memoryTeams{memoryStore: store},
+			Users: memoryUsers{memoryStore: store},
+			// PR и статистика в этих тестах не запрашиваются
+			PullRequests: struct{ PullRequestRepository }{},
+			Stats:        struct{ stats.StatsRepository }{},
+			TxManager:    noTx{},
+			Schema:       schemaFunc(func() migrate.Status { return migrate.Status{Expected: 1, Applied: 1} }),
+		}),
+	)
+	require.NoError(t, err)
+	return a
+}
+
+func testConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Server.Host, cfg.Server.Port = "127.0.0.1", "0"
+	cfg.Reviewers.MaxReviewersPerPR = 2
+	cfg.Reviewers.MergeActorPolicy = "author_or_reviewer"
+	cfg.Teams.MaxMembers = 10
+	cfg.Metrics.SampleInterval = time.Minute
+	cfg.Jobs.PendingAssignmentInterval = time.Minute
+	return cfg
+}
+
+func TestNew_RequiresCompleteRepositories(t *testing.T) {
+	_, err := New(testConfig(),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		WithRepositories(Repositories{Teams: memoryTeams{memoryStore: newMemoryStore()}}))
+
+	require.ErrorContains(t, err, "repositories are incomplete")
+}
+
+func TestApp_HandlerServesTeamsFromMemory(t *testing.T) {
+	a := newTestApp(t, testConfig())
+	handler := a.Handler()
+
+	body := `{"team_name":"backend","members":[{"user_id":"u1","username":"alice","is_active":true}]}`
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/team/add", strings.NewReader(body)))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/team/get?team_name=backend", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var team struct {
+		TeamName string `json:"team_name"`
+		Members  []struct {
+			UserID string `json:"user_id"`
+		} `json:"members"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &team))
+	assert.Equal(t, "backend", team.TeamName)
+	require.Len(t, team.Members, 1)
+	assert.Equal(t, "u1", team.Members[0].UserID)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/team/get?team_name=frontend", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestApp_StartShutdown(t *testing.T) {
+	a := newTestApp(t, testConfig())
+
+	require.NoError(t, a.Start(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, a.Shutdown(ctx))
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"avito_backend_task/internal/config"
+	"avito_backend_task/internal/jobs"
+	"avito_backend_task/internal/metrics"
+	"avito_backend_task/internal/notify"
+	"avito_backend_task/internal/repository"
+	pullrequest "avito_backend_task/internal/service/pullrequest"
+	"avito_backend_task/internal/service/stats"
+	team "avito_backend_task/internal/service/team"
+	user "avito_backend_task/internal/service/user"
+	"avito_backend_task/internal/transport/http/handlers/admin"
+	"avito_backend_task/migrations"
+	"avito_backend_task/pkg/db"
+	"avito_backend_task/pkg/db/migrate"
+)
+
+// TeamRepository - всё, что сервисы, уведомления и webhook команд читают и пишут о командах
+type TeamRepository interface {
+	team.TeamRepository
+	user.TeamRepository
+	pullrequest.TeamRepository
+	pullrequest.BlackoutRepository
+	notify.TeamWebhookStore
+}
+
+// UserRepository - пользователи для сервисов, Slack-уведомлений и метрик
+type UserRepository interface {
+	team.UserRepository
+	user.UserRepository
+	pullrequest.UserRepository
+	notify.UserLookup
+	metrics.UserStats
+}
+
+// PullRequestRepository - PR и ревьюеры для сервисов и метрик
+type PullRequestRepository interface {
+	pullrequest.PullRequestRepository
+	user.PullRequestRepository
+	team.ReviewLoadRepository
+	metrics.PullRequestStats
+}
+
+// Repositories - хранилище приложения. По умолчанию New собирает его на Postgres из cfg.Database,
+// WithRepositories подменяет целиком, например хранилищем в памяти
+type Repositories struct {
+	Teams        TeamRepository
+	Users        UserRepository
+	PullRequests PullRequestRepository
+	Stats        stats.StatsRepository
+	TxManager    db.TransactionManagerInterface
+	// Schema отвечает /ready, отстаёт ли схема от миграций
+	Schema admin.SchemaChecker
+	// Locker выбирает реплику для запуска фоновых задач, не нужен при WithoutBackgroundJobs
+	Locker jobs.Locker
+}
+
+func (r Repositories) validate(jobsEnabled bool) error {
+	switch {
+	case r.Teams == nil, r.Users == nil, r.PullRequests == nil, r.Stats == nil:
+		return fmt.Errorf("repositories are incomplete")
+	case r.TxManager == nil:
+		return fmt.Errorf("repositories require a transaction manager")
+	case r.Schema == nil:
+		return fmt.Errorf("repositories require a schema checker")
+	case jobsEnabled && r.Locker == nil:
+		return fmt.Errorf("background jobs require a locker")
+	}
+	return nil
+}
+
+// openPostgres подключается к БД и собирает репозитории на одном пуле, close закрывает пул
+func openPostgres(cfg *config.Config, lg *slog.Logger) (repos Repositories, close func(), err error) {
+	pool, err := connectDB(&cfg.Database, lg)
+	if err != nil {
+		return Repositories{}, nil, fmt.Errorf("error connecting to db: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			pool.Close()
+		}
+	}()
+
+	txManager, err := db.NewTransactionManager(pool)
+	if err != nil {
+		return Repositories{}, nil, fmt.Errorf("error creating transaction manager: %w", err)
+	}
+
+	expectedVersion, err := migrate.LatestVersion(migrations.FS)
+	if err != nil {
+		return Repositories{}, nil, fmt.Errorf("error reading embedded migrations: %w", err)
+	}
+
+	dbInstance := db.NewDB(pool)
+	var prRepoOpts []repository.PullRequestRepositoryOption
+	if cfg.Reviewers.CheckActiveInDB {
+		prRepoOpts = append(prRepoOpts, repository.WithActiveReviewerCheck())
+	}
+
+	return Repositories{
+		Teams: repository.NewTeamRepository(dbInstance),
+		Users: repository.NewUserRepository(dbInstance,
+			repository.WithCandidateSampleThreshold(cfg.Reviewers.CandidateSampleThreshold)),
+		PullRequests: repository.NewPullRequestRepository(dbInstance, prRepoOpts...),
+		Stats:        repository.NewStatsRepository(dbInstance),
+		TxManager:    txManager,
+		Schema:       migrate.NewChecker(expectedVersion, migrate.NewVersionSource(pool)),
+		Locker:       db.NewAdvisoryLocker(pool),
+	}, pool.Close, nil
+}
+
+func connectDB(cfg *config.DatabaseConfig, lg *slog.Logger) (*pgxpool.Pool, error) {
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		cfg.Host,
+		cfg.Port,
+		cfg.User,
+		cfg.Password,
+		cfg.Name,
+	)
+
+	poolCfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing database config: %w", err)
+	}
+	if cfg.LogSQL {
+		poolCfg.ConnConfig.Tracer = db.NewQueryTracer(lg, !cfg.LogSQLArgs)
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating connection pool: %w", err)
+	}
+
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("error connecting to database: %w", err)
+	}
+
+	return pool, nil
+}
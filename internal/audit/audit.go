@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"avito_backend_task/internal/domain"
+)
+
+const (
+	KindFile = "file"
+	KindHTTP = "http"
+)
+
+// Sink принимает назначения и снятия ревьюеров. Close освобождает ресурсы синка при остановке сервиса
+type Sink interface {
+	RecordAssignment(ctx context.Context, record domain.AuditRecord) error
+	RecordRemoval(ctx context.Context, record domain.AuditRecord) error
+	Close() error
+}
+
+type Config struct {
+	// Kind - file, http или пустое значение для Nop
+	Kind     string
+	FilePath string
+	URL      string
+	Timeout  time.Duration
+}
+
+// New выбирает синк по Kind, без Kind аудит не ведётся
+func New(cfg Config) (Sink, error) {
+	switch cfg.Kind {
+	case "":
+		return Nop{}, nil
+	case KindFile:
+		return NewFileSink(cfg.FilePath)
+	case KindHTTP:
+		return NewHTTPSink(cfg.URL, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown audit sink %q", cfg.Kind)
+	}
+}
+
+// Nop - синк по умолчанию, записи отбрасываются
+type Nop struct{}
+
+func (Nop) RecordAssignment(context.Context, domain.AuditRecord) error { return nil }
+
+func (Nop) RecordRemoval(context.Context, domain.AuditRecord) error { return nil }
+
+func (Nop) Close() error { return nil }
+
+// entry - формат одной записи аудита в файле и в теле HTTP-запроса
+type entry struct {
+	Action           domain.ReviewerEventType `json:"action"`
+	PullRequestID    string                   `json:"pull_request_id"`
+	UserID           string                   `json:"user_id"`
+	AssignmentSource domain.AssignmentSource  `json:"assignment_source,omitempty"`
+	OccurredAt       time.Time                `json:"occurred_at"`
+}
+
+func newEntry(action domain.ReviewerEventType, record domain.AuditRecord) entry {
+	return entry{
+		Action:           action,
+		PullRequestID:    record.PullRequestID,
+		UserID:           record.UserID,
+		AssignmentSource: record.Source,
+		OccurredAt:       record.OccurredAt.UTC(),
+	}
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+)
+
+func TestFileSink_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	at := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.RecordAssignment(context.Background(), domain.AuditRecord{
+		PullRequestID: "pr1", UserID: "u2", Source: domain.AssignmentSourceAuto, OccurredAt: at,
+	}))
+	require.NoError(t, sink.Close())
+
+	// повторное открытие дописывает, а не перезаписывает файл
+	sink, err = NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.RecordRemoval(context.Background(), domain.AuditRecord{
+		PullRequestID: "pr1", UserID: "u2", OccurredAt: at,
+	}))
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+
+	assert.Equal(t, []map[string]any{
+		{"action": "ASSIGNED", "pull_request_id": "pr1", "user_id": "u2", "assignment_source": "AUTO", "occurred_at": "2025-11-03T12:00:00Z"},
+		{"action": "REMOVED", "pull_request_id": "pr1", "user_id": "u2", "occurred_at": "2025-11-03T12:00:00Z"},
+	}, lines)
+}
+
+func TestHTTPSink_Record(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		if payload["pull_request_id"] == "broken" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL, time.Second)
+	at := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+
+	err := sink.RecordAssignment(context.Background(), domain.AuditRecord{
+		PullRequestID: "pr1", UserID: "u2", Source: domain.AssignmentSourceManual, OccurredAt: at,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"action": "ASSIGNED", "pull_request_id": "pr1", "user_id": "u2",
+		"assignment_source": "MANUAL", "occurred_at": "2025-11-03T12:00:00Z",
+	}, payload)
+
+	err = sink.RecordRemoval(context.Background(), domain.AuditRecord{PullRequestID: "broken", UserID: "u2"})
+	require.ErrorContains(t, err, "status 503")
+}
+
+func TestNew(t *testing.T) {
+	sink, err := New(Config{})
+	require.NoError(t, err)
+	assert.Equal(t, Nop{}, sink)
+
+	sink, err = New(Config{Kind: KindHTTP, URL: "http://audit.local"})
+	require.NoError(t, err)
+	assert.IsType(t, &HTTPSink{}, sink)
+
+	_, err = New(Config{Kind: "kafka"})
+	require.ErrorContains(t, err, "unknown audit sink")
+}
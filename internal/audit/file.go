@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"avito_backend_task/internal/domain"
+)
+
+// FileSink дописывает записи в файл по одному JSON-объекту на строку
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewFileSink открывает файл на дозапись и создаёт его, если файла нет
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	return &FileSink{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+func (s *FileSink) RecordAssignment(_ context.Context, record domain.AuditRecord) error {
+	return s.write(newEntry(domain.ReviewerEventAssigned, record))
+}
+
+func (s *FileSink) RecordRemoval(_ context.Context, record domain.AuditRecord) error {
+	return s.write(newEntry(domain.ReviewerEventRemoved, record))
+}
+
+func (s *FileSink) write(e entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enc.Encode(e); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
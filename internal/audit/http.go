@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"avito_backend_task/internal/domain"
+)
+
+// HTTPSink отправляет каждую запись POST-запросом с JSON, любой ответ кроме 2xx считается ошибкой
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink ограничивает каждый запрос timeout, 0 - без ограничения
+func NewHTTPSink(url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *HTTPSink) RecordAssignment(ctx context.Context, record domain.AuditRecord) error {
+	return s.post(ctx, newEntry(domain.ReviewerEventAssigned, record))
+}
+
+func (s *HTTPSink) RecordRemoval(ctx context.Context, record domain.AuditRecord) error {
+	return s.post(ctx, newEntry(domain.ReviewerEventRemoved, record))
+}
+
+func (s *HTTPSink) post(ctx context.Context, e entry) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build audit request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send audit record: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit endpoint responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *HTTPSink) Close() error {
+	return nil
+}
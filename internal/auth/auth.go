@@ -0,0 +1,69 @@
+// Package auth описывает область действия API-ключа и проверки доступа по ней
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"avito_backend_task/internal/domain"
+)
+
+// Scope - область действия ключа. Пустая Team означает админский ключ без ограничений
+type Scope struct {
+	Team string
+}
+
+func (s Scope) IsAdmin() bool {
+	return s.Team == ""
+}
+
+type scopeKey struct{}
+
+func WithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+func ScopeFromContext(ctx context.Context) (Scope, bool) {
+	scope, ok := ctx.Value(scopeKey{}).(Scope)
+	return scope, ok
+}
+
+// IsTeamScoped сообщает, ограничен ли запрос одной командой
+func IsTeamScoped(ctx context.Context) bool {
+	scope, ok := ScopeFromContext(ctx)
+	return ok && !scope.IsAdmin()
+}
+
+// AuthorizeTeam возвращает ErrForbidden, если ключ привязан к другой команде.
+// Без области в контексте (аутентификация выключена) и для админских ключей проверка не ограничивает
+func AuthorizeTeam(ctx context.Context, teamName string) error {
+	scope, ok := ScopeFromContext(ctx)
+	if !ok || scope.IsAdmin() || scope.Team == teamName {
+		return nil
+	}
+
+	return domain.ErrForbidden
+}
+
+// ParseKeys разбирает список "key:team" (ключ команды) и "key" (админский ключ)
+func ParseKeys(entries []string) (map[string]Scope, error) {
+	keys := make(map[string]Scope, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, team, _ := strings.Cut(entry, ":")
+		if key == "" {
+			return nil, fmt.Errorf("empty api key in %q", entry)
+		}
+		if _, ok := keys[key]; ok {
+			return nil, fmt.Errorf("duplicate api key")
+		}
+		keys[key] = Scope{Team: team}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys([]string{"root", "k1:backend", " k2:mobile ", ""})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Scope{
+		"root": {},
+		"k1":   {Team: "backend"},
+		"k2":   {Team: "mobile"},
+	}, keys)
+
+	_, err = ParseKeys([]string{"k1:backend", "k1:mobile"})
+	assert.Error(t, err)
+
+	_, err = ParseKeys([]string{":backend"})
+	assert.Error(t, err)
+}
+
+func TestAuthorizeTeam(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, AuthorizeTeam(ctx, "backend"), "auth disabled")
+
+	admin := WithScope(ctx, Scope{})
+	assert.NoError(t, AuthorizeTeam(admin, "backend"))
+	assert.False(t, IsTeamScoped(admin))
+
+	scoped := WithScope(ctx, Scope{Team: "backend"})
+	assert.True(t, IsTeamScoped(scoped))
+	assert.NoError(t, AuthorizeTeam(scoped, "backend"))
+	assert.ErrorIs(t, AuthorizeTeam(scoped, "mobile"), domain.ErrForbidden)
+}
@@ -1,21 +1,50 @@
 package config
 
 import (
+	"fmt"
 	"log/slog"
+	"maps"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v10"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	LogLevel string `env:"LOG_LEVEL" envDefault:"info"`
+	// AppEnv - профиль окружения dev, staging или prod, задаёт значения по умолчанию из profileDefaults.
+	// Пустое значение - без профиля
+	AppEnv    string `env:"APP_ENV"`
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Jobs      JobsConfig
+	Reviewers ReviewersConfig
+	Auth      AuthConfig
+	Metrics   MetricsConfig
+	Stats     StatsConfig
+	Teams     TeamsConfig
+	Notify    NotifyConfig
+	Events    EventsConfig
+	Audit     AuditConfig
+	LogLevel  string `env:"LOG_LEVEL" envDefault:"info"`
 }
 
 type ServerConfig struct {
 	Host string `env:"SERVER_HOST,required"`
 	Port string `env:"SERVER_PORT,required"`
+	// ReadTimeout и WriteTimeout ограничивают чтение запроса и запись ответа, 0 - без ограничения
+	ReadTimeout  time.Duration `env:"SERVER_READ_TIMEOUT" envDefault:"0s"`
+	WriteTimeout time.Duration `env:"SERVER_WRITE_TIMEOUT" envDefault:"0s"`
+	// EnableCompression сжимает ответы API gzip, если клиент его принимает; /metrics не сжимается
+	EnableCompression bool `env:"ENABLE_COMPRESSION" envDefault:"false"`
+	// CompressionMinSize - ответы короче стольких байт отдаются без сжатия
+	CompressionMinSize int `env:"COMPRESSION_MIN_SIZE" envDefault:"1024"`
+	// ReadOnlyMode отклоняет изменяющие запросы API без обращения к БД, например на read-only standby
+	ReadOnlyMode bool `env:"READ_ONLY_MODE" envDefault:"false"`
+	// MaxInFlight - сколько запросов API обрабатывается одновременно, 0 - без ограничения
+	MaxInFlight int `env:"MAX_IN_FLIGHT" envDefault:"0"`
+	// InFlightQueueTimeout - сколько чтение ждёт свободного места при MAX_IN_FLIGHT, изменяющие запросы не ждут
+	InFlightQueueTimeout time.Duration `env:"IN_FLIGHT_QUEUE_TIMEOUT" envDefault:"100ms"`
 }
 
 type DatabaseConfig struct {
@@ -24,19 +53,258 @@ type DatabaseConfig struct {
 	Host     string `env:"POSTGRES_HOST,required"`
 	Port     string `env:"POSTGRES_PORT,required"`
 	Name     string `env:"POSTGRES_DATABASE,required"`
+	// LogSQL пишет каждый запрос в debug-лог, требует LOG_LEVEL=debug
+	LogSQL bool `env:"LOG_SQL" envDefault:"false"`
+	// LogSQLArgs выводит значения аргументов, иначе они заменяются на [REDACTED]
+	LogSQLArgs bool `env:"LOG_SQL_ARGS" envDefault:"false"`
+}
+
+type ReviewersConfig struct {
+	ExcludeOutsideWorkingHours bool `env:"EXCLUDE_OUTSIDE_WORKING_HOURS" envDefault:"false"`
+	// FailOnNoReviewers - устаревший синоним NO_REVIEWERS_POLICY=fail, действует, если политика не задана
+	FailOnNoReviewers       bool `env:"FAIL_ON_NO_REVIEWERS" envDefault:"false"`
+	AllowCrossTeamReviewers bool `env:"ALLOW_CROSS_TEAM_REVIEWERS" envDefault:"false"`
+	// NoReviewersPolicy - allow, fail или author: что делать с новым PR, если назначить ревьюеров некого
+	NoReviewersPolicy string `env:"NO_REVIEWERS_POLICY"`
+	// MaxReviewersPerPR - максимум ревьюеров одного PR во всех путях назначения
+	MaxReviewersPerPR int `env:"MAX_REVIEWERS_PER_PR" envDefault:"10"`
+	// ReviewersPerPR - сколько ревьюеров автоназначение набирает на PR, не больше MAX_REVIEWERS_PER_PR
+	ReviewersPerPR int `env:"REVIEWERS_PER_PR" envDefault:"2"`
+	// MaxOpenReviewsPerUser ограничивает число открытых ревью на пользователя при автоназначении, 0 - без ограничения
+	MaxOpenReviewsPerUser int `env:"MAX_OPEN_REVIEWS_PER_USER" envDefault:"0"`
+	// CheckActiveInDB дублирует проверку активности ревьюера в SQL-запросе назначения
+	CheckActiveInDB bool `env:"CHECK_REVIEWER_ACTIVE_IN_DB" envDefault:"false"`
+	// CandidateSampleThreshold - сколько кандидатов максимум загружать из команды, 0 загружает всех
+	CandidateSampleThreshold int `env:"CANDIDATE_SAMPLE_THRESHOLD" envDefault:"0"`
+	// AutoCloseOrphanedPRs закрывает открытые PR автора, удалённого из команды, вместо пометки orphaned
+	AutoCloseOrphanedPRs bool `env:"AUTO_CLOSE_ORPHANED_PRS" envDefault:"false"`
+	// ReactivationTopUpLimit - в сколько недоукомплектованных PR максимум добавляется пользователь,
+	// вернувшийся через setIsActive с rebalance, 0 - без ограничения
+	ReactivationTopUpLimit int `env:"REACTIVATION_TOP_UP_LIMIT" envDefault:"3"`
+	// RetryUnderstaffedPRs ставит PR с неполным составом ревьюеров в очередь pending assignment до появления кандидатов
+	RetryUnderstaffedPRs bool `env:"RETRY_UNDERSTAFFED_PRS" envDefault:"false"`
+	// AllowMissingAuthorTeam разрешает создавать PR без ревьюеров, если команды автора не существует
+	AllowMissingAuthorTeam bool `env:"ALLOW_MISSING_AUTHOR_TEAM" envDefault:"false"`
+	// RejectInactiveAuthors запрещает создавать PR от имени неактивного пользователя
+	RejectInactiveAuthors bool `env:"REJECT_INACTIVE_AUTHORS" envDefault:"false"`
+	// AutoCreateAuthor создаёт неизвестного автора нового PR участником DEFAULT_TEAM вместо 404
+	AutoCreateAuthor bool   `env:"AUTO_CREATE_AUTHOR" envDefault:"false"`
+	DefaultTeam      string `env:"DEFAULT_TEAM"`
+	// ExcludeGroupAuthors не назначает ревьюерами авторов других открытых PR той же группы
+	ExcludeGroupAuthors bool `env:"EXCLUDE_GROUP_AUTHORS" envDefault:"false"`
+	// MaxReassignmentsPerHour ограничивает число замен ревьюеров одного PR за скользящий час, 0 - без ограничения
+	MaxReassignmentsPerHour int `env:"MAX_REASSIGNMENTS_PER_HOUR" envDefault:"0"`
+	// PartialReassignPolicy - fail или fill: что делать, если в /pullRequest/reassign с old_user_ids
+	// заменить удаётся не всех
+	PartialReassignPolicy string `env:"PARTIAL_REASSIGN_POLICY" envDefault:"fail"`
+	// ShadowStrategy - random или least_loaded: стратегия, выбор которой при создании PR только логируется, пусто - выключено
+	ShadowStrategy string `env:"SHADOW_STRATEGY"`
+	// MergeRequiresActor делает merged_by обязательным при merge PR
+	MergeRequiresActor bool `env:"MERGE_REQUIRES_ACTOR" envDefault:"false"`
+	// MergeActorPolicy - author, reviewer или author_or_reviewer: кто из участников PR может его смержить
+	MergeActorPolicy string `env:"MERGE_ACTOR_POLICY" envDefault:"author_or_reviewer"`
+	// MandatoryReviewers - пары "label:user_id": пользователь назначается ревьюером каждого нового PR с меткой label
+	MandatoryReviewers []string `env:"MANDATORY_REVIEWERS" envSeparator:","`
+}
+
+type AuthConfig struct {
+	// APIKeys - список ключей "key" (админский) или "key:team" (только своя команда), пустой список отключает проверку
+	APIKeys []string `env:"API_KEYS" envSeparator:","`
+}
+
+type MetricsConfig struct {
+	// SampleInterval - период пересчёта бизнес-метрик, выполняется планировщиком задач
+	SampleInterval time.Duration `env:"METRICS_SAMPLE_INTERVAL" envDefault:"30s"`
+}
+
+type StatsConfig struct {
+	// CacheTTL - сколько отдаётся закэшированная сводка /stats/global, 0 отключает кэш
+	CacheTTL time.Duration `env:"STATS_CACHE_TTL" envDefault:"5s"`
+	// LatencyMinSamples - минимум смерженных PR ревьюера для /pullRequest/reviewLatency
+	LatencyMinSamples int `env:"REVIEW_LATENCY_MIN_SAMPLES" envDefault:"5"`
+}
+
+type TeamsConfig struct {
+	// MaxMembers - максимум участников команды при создании и добавлении участников, 0 отключает проверку
+	MaxMembers int `env:"MAX_TEAM_MEMBERS" envDefault:"1000"`
+}
+
+type NotifyConfig struct {
+	// WebhookURL - куда отправлять события создания и мержа PR, пустое значение отключает уведомления
+	WebhookURL string `env:"NOTIFY_WEBHOOK_URL"`
+	// QueueSize - ёмкость очереди доставки, при переполнении события отбрасываются
+	QueueSize int           `env:"NOTIFY_QUEUE_SIZE" envDefault:"1000"`
+	Timeout   time.Duration `env:"NOTIFY_TIMEOUT" envDefault:"5s"`
+	// LogEnabled пишет уведомления о назначении и замене ревьюеров в лог
+	LogEnabled bool `env:"NOTIFY_LOG_ENABLED" envDefault:"true"`
+	// SlackEnabled отправляет те же уведомления в Slack incoming webhook SlackWebhookURL
+	SlackEnabled    bool   `env:"NOTIFY_SLACK_ENABLED" envDefault:"false"`
+	SlackWebhookURL string `env:"SLACK_WEBHOOK_URL"`
+	// TeamWebhooksEnabled доставляет PR_CREATED и REVIEWERS_ASSIGNED на webhook команды автора из /team/webhooks
+	TeamWebhooksEnabled bool `env:"NOTIFY_TEAM_WEBHOOKS_ENABLED" envDefault:"true"`
+	// TeamWebhookMaxAttempts - попыток доставки на webhook команды, пауза перед повтором начинается
+	// с TeamWebhookBackoff и удваивается
+	TeamWebhookMaxAttempts int           `env:"NOTIFY_TEAM_WEBHOOK_MAX_ATTEMPTS" envDefault:"3"`
+	TeamWebhookBackoff     time.Duration `env:"NOTIFY_TEAM_WEBHOOK_BACKOFF" envDefault:"1s"`
+}
+
+type AuditConfig struct {
+	// Sink - куда записывать назначения и снятия ревьюеров: file, http или пустое значение без аудита
+	Sink string `env:"AUDIT_SINK"`
+	// FilePath - файл для AUDIT_SINK=file, записи дописываются по одной JSON-строке
+	FilePath string `env:"AUDIT_FILE_PATH"`
+	// URL - адрес для AUDIT_SINK=http, каждая запись отправляется отдельным POST
+	URL     string        `env:"AUDIT_URL"`
+	Timeout time.Duration `env:"AUDIT_TIMEOUT" envDefault:"5s"`
+}
+
+type EventsConfig struct {
+	// Retention - насколько в прошлое можно запрашивать /events/assignments, 0 - без ограничения
+	Retention time.Duration `env:"EVENTS_RETENTION" envDefault:"168h"`
+}
+
+type JobsConfig struct {
+	Enabled bool    `env:"JOBS_ENABLED" envDefault:"true"`
+	Jitter  float64 `env:"JOBS_JITTER" envDefault:"0.1"`
+	// PendingAssignmentInterval - как часто назначать ревьюеров PR, отложенным из-за blackout команды или нехватки кандидатов
+	PendingAssignmentInterval time.Duration `env:"PENDING_ASSIGNMENT_INTERVAL" envDefault:"1m"`
+}
+
+// profileDefaults - значения переменных окружения по умолчанию для каждого APP_ENV.
+// Явно заданная переменная всегда важнее профиля
+var profileDefaults = map[string]map[string]string{
+	"dev": {
+		"LOG_LEVEL": "debug",
+		"LOG_SQL":   "true",
+	},
+	"staging": {
+		"SERVER_READ_TIMEOUT":  "10s",
+		"SERVER_WRITE_TIMEOUT": "30s",
+	},
+	"prod": {
+		"SERVER_READ_TIMEOUT":  "5s",
+		"SERVER_WRITE_TIMEOUT": "15s",
+		"NOTIFY_TIMEOUT":       "2s",
+	},
 }
 
 func Load() (*Config, error) {
+	return load(env.ToMap(os.Environ()))
+}
+
+func load(environ map[string]string) (*Config, error) {
 	cfg := Config{}
 
-	err := env.Parse(&cfg)
+	err := env.ParseWithOptions(&cfg, env.Options{Environment: environ})
 	if err != nil {
 		return nil, err
 	}
 
+	// профиль известен только после разбора, поэтому его значения подставляются вместо
+	// незаданных переменных и конфиг разбирается ещё раз
+	if cfg.AppEnv != "" {
+		defaults, ok := profileDefaults[cfg.AppEnv]
+		if !ok {
+			return nil, fmt.Errorf("invalid APP_ENV %q: expected dev, staging or prod", cfg.AppEnv)
+		}
+
+		merged := maps.Clone(defaults)
+		maps.Copy(merged, environ)
+
+		cfg = Config{}
+		if err := env.ParseWithOptions(&cfg, env.Options{Environment: merged}); err != nil {
+			return nil, err
+		}
+	}
+
+	switch cfg.Reviewers.NoReviewersPolicy {
+	case "", "allow", "fail", "author":
+	default:
+		return nil, fmt.Errorf("invalid NO_REVIEWERS_POLICY %q: expected allow, fail or author", cfg.Reviewers.NoReviewersPolicy)
+	}
+
+	switch cfg.Audit.Sink {
+	case "":
+	case "file":
+		if cfg.Audit.FilePath == "" {
+			return nil, fmt.Errorf("AUDIT_SINK=file requires AUDIT_FILE_PATH")
+		}
+	case "http":
+		if cfg.Audit.URL == "" {
+			return nil, fmt.Errorf("AUDIT_SINK=http requires AUDIT_URL")
+		}
+	default:
+		return nil, fmt.Errorf("invalid AUDIT_SINK %q: expected file or http", cfg.Audit.Sink)
+	}
+
+	switch cfg.Reviewers.PartialReassignPolicy {
+	case "", "fail", "fill":
+	default:
+		return nil, fmt.Errorf("invalid PARTIAL_REASSIGN_POLICY %q: expected fail or fill", cfg.Reviewers.PartialReassignPolicy)
+	}
+
+	if cfg.Reviewers.ReviewersPerPR > cfg.Reviewers.MaxReviewersPerPR {
+		return nil, fmt.Errorf("REVIEWERS_PER_PR %d exceeds MAX_REVIEWERS_PER_PR %d", cfg.Reviewers.ReviewersPerPR, cfg.Reviewers.MaxReviewersPerPR)
+	}
+
+	if cfg.Reviewers.AutoCreateAuthor && cfg.Reviewers.DefaultTeam == "" {
+		return nil, fmt.Errorf("AUTO_CREATE_AUTHOR requires DEFAULT_TEAM")
+	}
+
+	switch cfg.Reviewers.ShadowStrategy {
+	case "", "random", "least_loaded":
+	default:
+		return nil, fmt.Errorf("invalid SHADOW_STRATEGY %q: expected random or least_loaded", cfg.Reviewers.ShadowStrategy)
+	}
+
+	if _, err := parseMandatoryReviewers(cfg.Reviewers.MandatoryReviewers); err != nil {
+		return nil, err
+	}
+
+	if cfg.Server.MaxInFlight < 0 {
+		return nil, fmt.Errorf("invalid MAX_IN_FLIGHT %d: expected 0 or more", cfg.Server.MaxInFlight)
+	}
+
+	switch cfg.Reviewers.MergeActorPolicy {
+	case "author", "reviewer", "author_or_reviewer":
+	default:
+		return nil, fmt.Errorf("invalid MERGE_ACTOR_POLICY %q: expected author, reviewer or author_or_reviewer", cfg.Reviewers.MergeActorPolicy)
+	}
+
 	return &cfg, nil
 }
 
+// MandatoryReviewerMap - обязательные ревьюеры по меткам, MANDATORY_REVIEWERS уже проверен в Load
+func (c ReviewersConfig) MandatoryReviewerMap() map[string][]string {
+	reviewers, _ := parseMandatoryReviewers(c.MandatoryReviewers)
+	return reviewers
+}
+
+func parseMandatoryReviewers(entries []string) (map[string][]string, error) {
+	reviewers := make(map[string][]string)
+	for _, entry := range entries {
+		label, userID, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		label, userID = strings.TrimSpace(label), strings.TrimSpace(userID)
+		if !ok || label == "" || userID == "" {
+			return nil, fmt.Errorf("invalid MANDATORY_REVIEWERS entry %q: expected label:user_id", entry)
+		}
+		reviewers[label] = append(reviewers[label], userID)
+	}
+	return reviewers, nil
+}
+
+// ResolvedNoReviewersPolicy учитывает устаревший FAIL_ON_NO_REVIEWERS, если NO_REVIEWERS_POLICY не задан
+func (c ReviewersConfig) ResolvedNoReviewersPolicy() string {
+	switch {
+	case c.NoReviewersPolicy != "":
+		return c.NoReviewersPolicy
+	case c.FailOnNoReviewers:
+		return "fail"
+	default:
+		return "allow"
+	}
+}
+
 func (c *Config) ParseLogLevel() slog.Level {
 	levelStr := strings.ToLower(c.LogLevel)
 
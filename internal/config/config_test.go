@@ -0,0 +1,153 @@
+package config
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEnviron(extra map[string]string) map[string]string {
+	environ := map[string]string{
+		"SERVER_HOST":       "localhost",
+		"SERVER_PORT":       "8080",
+		"POSTGRES_USERNAME": "user",
+		"POSTGRES_PASSWORD": "pass",
+		"POSTGRES_HOST":     "localhost",
+		"POSTGRES_PORT":     "5432",
+		"POSTGRES_DATABASE": "service",
+	}
+	maps.Copy(environ, extra)
+	return environ
+}
+
+func TestLoad_ProfileDefaults(t *testing.T) {
+	tests := []struct {
+		appEnv       string
+		logLevel     string
+		logSQL       bool
+		readTimeout  time.Duration
+		writeTimeout time.Duration
+		notify       time.Duration
+	}{
+		{appEnv: "", logLevel: "info", readTimeout: 0, writeTimeout: 0, notify: 5 * time.Second},
+		{appEnv: "dev", logLevel: "debug", logSQL: true, notify: 5 * time.Second},
+		{appEnv: "staging", logLevel: "info", readTimeout: 10 * time.Second, writeTimeout: 30 * time.Second, notify: 5 * time.Second},
+		{appEnv: "prod", logLevel: "info", readTimeout: 5 * time.Second, writeTimeout: 15 * time.Second, notify: 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run("profile "+tt.appEnv, func(t *testing.T) {
+			cfg, err := load(testEnviron(map[string]string{"APP_ENV": tt.appEnv}))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.logLevel, cfg.LogLevel)
+			assert.Equal(t, tt.logSQL, cfg.Database.LogSQL)
+			assert.Equal(t, tt.readTimeout, cfg.Server.ReadTimeout)
+			assert.Equal(t, tt.writeTimeout, cfg.Server.WriteTimeout)
+			assert.Equal(t, tt.notify, cfg.Notify.Timeout)
+		})
+	}
+}
+
+func TestLoad_ExplicitEnvOverridesProfile(t *testing.T) {
+	cfg, err := load(testEnviron(map[string]string{
+		"APP_ENV":             "prod",
+		"SERVER_READ_TIMEOUT": "1m",
+		"NOTIFY_TIMEOUT":      "0s",
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Minute, cfg.Server.ReadTimeout)
+	assert.Equal(t, time.Duration(0), cfg.Notify.Timeout)
+	// незаданная переменная по-прежнему берётся из профиля
+	assert.Equal(t, 15*time.Second, cfg.Server.WriteTimeout)
+
+	cfg, err = load(testEnviron(map[string]string{"APP_ENV": "dev", "LOG_LEVEL": "warn"}))
+	require.NoError(t, err)
+	assert.Equal(t, "warn", cfg.LogLevel)
+	assert.True(t, cfg.Database.LogSQL)
+}
+
+func TestLoad_UnknownProfile(t *testing.T) {
+	_, err := load(testEnviron(map[string]string{"APP_ENV": "production"}))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid APP_ENV")
+}
+
+func TestLoad_InvalidMergeActorPolicy(t *testing.T) {
+	cfg, err := load(testEnviron(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "author_or_reviewer", cfg.Reviewers.MergeActorPolicy)
+
+	_, err = load(testEnviron(map[string]string{"MERGE_ACTOR_POLICY": "anyone"}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid MERGE_ACTOR_POLICY")
+}
+
+func TestLoad_InvalidPartialReassignPolicy(t *testing.T) {
+	cfg, err := load(testEnviron(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "fail", cfg.Reviewers.PartialReassignPolicy)
+
+	_, err = load(testEnviron(map[string]string{"PARTIAL_REASSIGN_POLICY": "skip"}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid PARTIAL_REASSIGN_POLICY")
+}
+
+func TestLoad_InvalidShadowStrategy(t *testing.T) {
+	_, err := load(testEnviron(map[string]string{"SHADOW_STRATEGY": "round_robin"}))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid SHADOW_STRATEGY")
+}
+
+func TestLoad_ReviewersPerPR(t *testing.T) {
+	cfg, err := load(testEnviron(nil))
+	require.NoError(t, err)
+	assert.Equal(t, 10, cfg.Reviewers.MaxReviewersPerPR)
+	assert.Equal(t, 2, cfg.Reviewers.ReviewersPerPR)
+
+	_, err = load(testEnviron(map[string]string{"MAX_REVIEWERS_PER_PR": "3", "REVIEWERS_PER_PR": "4"}))
+	require.ErrorContains(t, err, "REVIEWERS_PER_PR 4 exceeds MAX_REVIEWERS_PER_PR 3")
+}
+
+func TestLoad_AutoCreateAuthorRequiresDefaultTeam(t *testing.T) {
+	_, err := load(testEnviron(map[string]string{"AUTO_CREATE_AUTHOR": "true"}))
+	require.ErrorContains(t, err, "AUTO_CREATE_AUTHOR requires DEFAULT_TEAM")
+
+	cfg, err := load(testEnviron(map[string]string{"AUTO_CREATE_AUTHOR": "true", "DEFAULT_TEAM": "general"}))
+	require.NoError(t, err)
+	assert.True(t, cfg.Reviewers.AutoCreateAuthor)
+	assert.Equal(t, "general", cfg.Reviewers.DefaultTeam)
+}
+
+func TestLoad_AuditSink(t *testing.T) {
+	for env, wantErr := range map[string]string{
+		"kafka": "invalid AUDIT_SINK",
+		"file":  "requires AUDIT_FILE_PATH",
+		"http":  "requires AUDIT_URL",
+	} {
+		_, err := load(testEnviron(map[string]string{"AUDIT_SINK": env}))
+		require.ErrorContains(t, err, wantErr)
+	}
+
+	cfg, err := load(testEnviron(map[string]string{"AUDIT_SINK": "file", "AUDIT_FILE_PATH": "/var/log/audit.jsonl"}))
+	require.NoError(t, err)
+	assert.Equal(t, "/var/log/audit.jsonl", cfg.Audit.FilePath)
+}
+
+func TestLoad_MandatoryReviewers(t *testing.T) {
+	_, err := load(testEnviron(map[string]string{"MANDATORY_REVIEWERS": "security"}))
+	require.ErrorContains(t, err, "invalid MANDATORY_REVIEWERS entry")
+
+	cfg, err := load(testEnviron(map[string]string{"MANDATORY_REVIEWERS": "security:sec1, security:sec2,compliance:c1"}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"security":   {"sec1", "sec2"},
+		"compliance": {"c1"},
+	}, cfg.Reviewers.MandatoryReviewerMap())
+}
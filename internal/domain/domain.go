@@ -11,19 +11,225 @@ type TeamMember struct {
 type Team struct {
 	TeamName string
 	Members  []TeamMember
+	// Blackouts - текущие и будущие окна без автоназначения ревьюеров
+	Blackouts []TeamBlackout
 }
 
-type User struct {
-	UserID   string
-	Username string
+// TeamSizeLimit - Payload ErrTeamTooLarge: лимит и размер команды, который получился бы после добавления
+type TeamSizeLimit struct {
+	Limit int
+	Size  int
+}
+
+// ReassignRateLimit - Payload ErrReassignRateExceeded: лимит замен в час, время, с которого замена снова разрешена,
+// и сколько до него осталось по часам сервиса
+type ReassignRateLimit struct {
+	Limit      int
+	RetryAt    time.Time
+	RetryAfter time.Duration
+}
+
+// ReviewerCapLimit - Payload ErrReviewerCapReached: MAX_REVIEWERS_PER_PR и число ревьюеров, которое уже есть
+// у PR или было запрошено в setReviewers
+type ReviewerCapLimit struct {
+	Limit     int
+	Reviewers int
+}
+
+// CheckReviewerCap возвращает ErrReviewerCapReached с ReviewerCapLimit, если reviewers больше limit
+func CheckReviewerCap(limit, reviewers int) error {
+	if reviewers <= limit {
+		return nil
+	}
+	return &ConflictError{Err: ErrReviewerCapReached, Payload: &ReviewerCapLimit{Limit: limit, Reviewers: reviewers}}
+}
+
+// CheckTeamSize возвращает ErrTeamTooLarge с TeamSizeLimit, если size больше limit. limit <= 0 - без ограничения
+func CheckTeamSize(limit, size int) error {
+	if limit <= 0 || size <= limit {
+		return nil
+	}
+	return &ConflictError{Err: ErrTeamTooLarge, Payload: &TeamSizeLimit{Limit: limit, Size: size}}
+}
+
+// TeamWebhook - URL, на который уходят события PR_CREATED и REVIEWERS_ASSIGNED PR авторов команды.
+// Тело каждого запроса подписывается HMAC-SHA256 с Secret
+type TeamWebhook struct {
+	TeamName  string
+	URL       string
+	Secret    string
+	Enabled   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TeamWebhookUpdate - изменение webhook команды, nil поля не меняются
+type TeamWebhookUpdate struct {
 	TeamName string
-	IsActive bool
+	URL      *string
+	Secret   *string
+	Enabled  *bool
+}
+
+// TeamWebhookStatus - итог доставок на webhook команды
+type TeamWebhookStatus struct {
+	TeamName        string
+	Enabled         bool
+	LastDeliveredAt *time.Time
+	LastFailedAt    *time.Time
+	// LastError - причина последней неудачной доставки, пуста, если доставок с ошибкой не было
+	LastError string
+	// ConsecutiveFailures - неудачные доставки подряд с последней успешной
+	ConsecutiveFailures int
+}
+
+// TeamBlackout - окно [StartsAt, EndsAt), в котором PR команды создаются без ревьюеров
+type TeamBlackout struct {
+	BlackoutID int64
+	TeamName   string
+	StartsAt   time.Time
+	EndsAt     time.Time
+	Reason     string
+}
+
+// Validate проверяет, что окно не пустое
+func (b TeamBlackout) Validate() error {
+	if !b.EndsAt.After(b.StartsAt) {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// Contains - попадает ли момент t в окно
+func (b TeamBlackout) Contains(t time.Time) bool {
+	return !t.Before(b.StartsAt) && t.Before(b.EndsAt)
+}
+
+// Коды проблем укомплектованности ревью команды для TeamHealth.Issues
+const (
+	TeamIssueNoActiveMembers = "NO_ACTIVE_MEMBERS"
+	TeamIssueSingleMember    = "SINGLE_MEMBER"
+	TeamIssueBlackoutActive  = "BLACKOUT_ACTIVE"
+	// TeamIssueMemberUncovered дополняется id участника: MEMBER_UNCOVERED:<user_id>
+	TeamIssueMemberUncovered = "MEMBER_UNCOVERED"
+)
+
+// TeamHealth - может ли команда укомплектовать ревью PR любого своего участника
+type TeamHealth struct {
+	TeamName string
+	Healthy  bool
+	Issues   []string
+}
+
+// ReconciledTeam - команда, созданная по users.team_name, и участники, ссылавшиеся на неё без записи в teams
+type ReconciledTeam struct {
+	TeamName string
+	UserIDs  []string
+}
+
+// SimulateCoverage проверяет, наберётся ли reviewersPerPR ревьюеров на PR каждого участника как автора:
+// кандидаты - активные участники команды, кроме автора. Участник без полного набора - MEMBER_UNCOVERED.
+// Окно без автоназначения, действующее в момент now, тоже считается проблемой
+func (t Team) SimulateCoverage(reviewersPerPR int, now time.Time) []string {
+	issues := []string{}
+
+	active := 0
+	for _, m := range t.Members {
+		if m.IsActive {
+			active++
+		}
+	}
+	if active == 0 {
+		issues = append(issues, TeamIssueNoActiveMembers)
+	}
+	if len(t.Members) == 1 {
+		issues = append(issues, TeamIssueSingleMember)
+	}
+	for _, b := range t.Blackouts {
+		if b.Contains(now) {
+			issues = append(issues, TeamIssueBlackoutActive)
+			break
+		}
+	}
+
+	for _, m := range t.Members {
+		candidates := active
+		if m.IsActive {
+			candidates--
+		}
+		if candidates < reviewersPerPR {
+			issues = append(issues, TeamIssueMemberUncovered+":"+m.UserID)
+		}
+	}
+
+	return issues
+}
+
+type User struct {
+	UserID       string
+	Username     string
+	TeamName     string
+	IsActive     bool
+	WorkingHours string
+	Timezone     string
+	// ReviewCapacity - желаемая доля ревью относительно обычной: 2 - готов брать вдвое больше, 0.5 - вдвое меньше
+	ReviewCapacity float64
+}
+
+// MaxReviewCapacity - верхняя граница ReviewCapacity
+const MaxReviewCapacity = 10
+
+// EffectiveLoad - нагрузка с учётом ReviewCapacity, незаданная capacity считается единицей
+func (u User) EffectiveLoad(reviews int) float64 {
+	if u.ReviewCapacity <= 0 {
+		return float64(reviews)
+	}
+	return float64(reviews) / u.ReviewCapacity
 }
 
 type PullRequestCreate struct {
 	PullRequestID   string
 	PullRequestName string
 	AuthorID        string
+	Priority        PRPriority
+	// GroupID - эпик или другая группа связанных PR, пусто - PR вне группы
+	GroupID string
+	// Labels - метки PR, по ним выбираются обязательные ревьюеры. Не сохраняются
+	Labels []string
+	// PendingAssignment - ревьюеры не назначались из-за blackout, их назначит фоновая задача
+	PendingAssignment bool
+	// Strategy - стратегия выбора ревьюеров только для этого PR, пусто - SelectionStrategyWorkingHours. Не сохраняется
+	Strategy SelectionStrategy
+}
+
+// SelectionStrategy - как из подходящих кандидатов выбираются ревьюеры нового PR
+type SelectionStrategy string
+
+const (
+	// SelectionStrategyWorkingHours - случайный выбор, сначала из тех, кто сейчас в рабочем окне
+	SelectionStrategyWorkingHours SelectionStrategy = "working_hours"
+	// SelectionStrategyRandom - случайный выбор из всех кандидатов без учёта рабочих окон
+	SelectionStrategyRandom SelectionStrategy = "random"
+	// SelectionStrategyLeastLoaded - кандидаты с наименьшим числом открытых ревью, тоже без учёта рабочих окон
+	SelectionStrategyLeastLoaded SelectionStrategy = "least_loaded"
+)
+
+// Valid - известная стратегия или пустая (по умолчанию)
+func (s SelectionStrategy) Valid() bool {
+	switch s {
+	case "", SelectionStrategyWorkingHours, SelectionStrategyRandom, SelectionStrategyLeastLoaded:
+		return true
+	}
+	return false
+}
+
+// PullRequestUpdate - частичное обновление PR, nil-поля не меняются
+type PullRequestUpdate struct {
+	PullRequestID   string
+	PullRequestName *string
+	Status          *PRStatus
+	// MergedBy - кто мержит PR, учитывается только при смене статуса на MERGED
+	MergedBy string
 }
 
 type PRStatus string
@@ -31,16 +237,91 @@ type PRStatus string
 const (
 	PRStatusOpen   PRStatus = "OPEN"
 	PRStatusMerged PRStatus = "MERGED"
+	// PRStatusClosed - PR закрыт без мержа, например после ухода автора
+	PRStatusClosed PRStatus = "CLOSED"
+)
+
+func (s PRStatus) Valid() bool {
+	return s == PRStatusOpen || s == PRStatusMerged || s == PRStatusClosed
+}
+
+type PRPriority string
+
+const (
+	PRPriorityLow    PRPriority = "LOW"
+	PRPriorityNormal PRPriority = "NORMAL"
+	PRPriorityHigh   PRPriority = "HIGH"
 )
 
+func (p PRPriority) Valid() bool {
+	return p == PRPriorityLow || p == PRPriorityNormal || p == PRPriorityHigh
+}
+
+// OrDefault возвращает NORMAL для незаданного приоритета
+func (p PRPriority) OrDefault() PRPriority {
+	if p == "" {
+		return PRPriorityNormal
+	}
+	return p
+}
+
 type PullRequest struct {
 	PullRequestID     string
 	PullRequestName   string
 	AuthorID          string
 	Status            PRStatus
+	Priority          PRPriority
+	GroupID           string
 	AssignedReviewers []string
-	CreatedAt         *time.Time
-	MergedAt          *time.Time
+	// Reviewers - ревьюеры с источником назначения, заполняется при чтении PR из БД
+	Reviewers []PRReviewer
+	// ReassignmentCount - сколько раз ревьюера PR заменяли другим
+	ReassignmentCount int
+	// Orphaned - автор PR удалён из команды
+	Orphaned bool
+	// PendingAssignment - PR создан во время blackout и ждёт автоназначения ревьюеров
+	PendingAssignment bool
+	// AuthorInactive - автор был неактивен в момент создания PR, заполняется только в ответе на создание
+	AuthorInactive bool
+	// MergedReviewerCount - число ревьюеров в момент merge, nil у несмерженных PR
+	MergedReviewerCount *int
+	// MergedUnderstaffed - PR смержен с меньшим, чем нужно, числом ревьюеров
+	MergedUnderstaffed bool
+	// MergedBy - пользователь, смерживший PR, пуст, если merge был без merged_by
+	MergedBy  string
+	CreatedAt *time.Time
+	MergedAt  *time.Time
+}
+
+// AssignmentSource - каким путём ревьюер попал на PR
+type AssignmentSource string
+
+const (
+	// AssignmentSourceAuto - автоназначение при создании PR и отложенное назначение
+	AssignmentSourceAuto AssignmentSource = "AUTO"
+	// AssignmentSourceManual - явное назначение и самоназначение
+	AssignmentSourceManual AssignmentSource = "MANUAL"
+	// AssignmentSourceReassignment - замена ревьюера, в том числе при деактивации и удалении из команды
+	AssignmentSourceReassignment AssignmentSource = "REASSIGNMENT"
+	// AssignmentSourceRebalance - перераспределение ревью команды и добор при возвращении пользователя
+	AssignmentSourceRebalance AssignmentSource = "REBALANCE"
+	// AssignmentSourceAdmin - /admin/reassignInactive и /admin/pullRequest/setReviewers
+	AssignmentSourceAdmin AssignmentSource = "ADMIN"
+)
+
+func (s AssignmentSource) Valid() bool {
+	switch s {
+	case AssignmentSourceAuto, AssignmentSourceManual, AssignmentSourceReassignment,
+		AssignmentSourceRebalance, AssignmentSourceAdmin:
+		return true
+	}
+	return false
+}
+
+// PRReviewer - ревьюер PR и источник его назначения
+type PRReviewer struct {
+	UserID string
+	Source AssignmentSource
 }
 
 type PullRequestShort struct {
@@ -48,8 +329,383 @@ type PullRequestShort struct {
 	PullRequestName string
 	AuthorID        string
 	Status          PRStatus
+	Orphaned        bool
+	// SnoozedUntil - до какого момента ревьюер отложил открытый PR, заполняется только в выдаче /users/getReview
+	SnoozedUntil *time.Time
+}
+
+// ReviewFilter - отбор PR ревьюера для /users/getReview
+type ReviewFilter struct {
+	// Status - только PR в этом статусе, пустой - в любом
+	Status PRStatus
+	// IncludeSnoozed оставляет открытые PR, отложенные ревьюером на момент Now
+	IncludeSnoozed bool
+	Now            time.Time
+}
+
+// ReviewSnooze - ревьюер отложил PR до Until, не снимаясь с него
+type ReviewSnooze struct {
+	PullRequestID string
+	UserID        string
+	Until         time.Time
+}
+
+// TeamOpenPRStats - число открытых PR команды автора, в том числе без ревьюеров
+type TeamOpenPRStats struct {
+	TeamName         string
+	Open             int
+	WithoutReviewers int
+}
+
+// PRAgeBucketLabels - корзины возраста открытых PR: <1d, 1-3d, 3-7d, >7d
+var PRAgeBucketLabels = []string{"lt_1d", "1d_3d", "3d_7d", "gt_7d"}
+
+// PRAgeBucket - число открытых PR команды в одной корзине возраста
+type PRAgeBucket struct {
+	Label string
+	Count int
+	// ExamplePRIDs - до 5 самых старых PR корзины
+	ExamplePRIDs []string
+}
+
+// TeamAgeReport - распределение открытых PR команды автора по возрасту
+type TeamAgeReport struct {
+	TeamName string
+	// Buckets - все корзины PRAgeBucketLabels по порядку, в том числе пустые
+	Buckets []PRAgeBucket
+	// OldestPRID пустой, если открытых PR нет
+	OldestPRID string
+	OldestAge  time.Duration
+}
+
+// GlobalStats - сводка по всему сервису; удалённые из команд пользователи не учитываются
+type GlobalStats struct {
+	Teams         int
+	ActiveUsers   int
+	InactiveUsers int
+	OpenPRs       int
+	MergedPRs     int
+	// AvgReviewersPerOpenPR - среднее число ревьюеров открытого PR, 0 если открытых PR нет
+	AvgReviewersPerOpenPR float64
+}
+
+// AuthorPRCount - число PR, созданных участником команды
+type AuthorPRCount struct {
+	UserID   string
+	Username string
+	Count    int
+}
+
+// AuthorPRCountOrder - порядок в выдаче CountPullRequestsByAuthor
+type AuthorPRCountOrder string
+
+const (
+	// AuthorPRCountOrderCount - по убыванию числа PR, при равенстве по user_id
+	AuthorPRCountOrderCount AuthorPRCountOrder = "count"
+	// AuthorPRCountOrderUsername - по username
+	AuthorPRCountOrderUsername AuthorPRCountOrder = "username"
+)
+
+func (o AuthorPRCountOrder) Valid() bool {
+	return o == AuthorPRCountOrderCount || o == AuthorPRCountOrderUsername
+}
+
+// ReviewerAssignment - назначение ревьюера с командой ревьюера
+type ReviewerAssignment struct {
+	PullRequestID string
+	UserID        string
+	TeamName      string
+}
+
+// UnderReviewedPR - открытый PR, у которого ревьюеров меньше нужного
+type UnderReviewedPR struct {
+	PullRequestID string
+	GroupID       string
+	ReviewerCount int
+}
+
+// ReviewerReplacement - результат замены ревьюера, пустой NewUserID означает снятие без замены
+type ReviewerReplacement struct {
+	PullRequestID string
+	OldUserID     string
+	NewUserID     string
+}
+
+// ReassignResult - итог ReassignReviewers: пары снятый -> назначенный в порядке запроса
+// и ревьюеры, оставшиеся на PR, потому что заменить их некем
+type ReassignResult struct {
+	Replacements []ReviewerReplacement
+	Unreplaced   []string
+}
+
+// ReviewerAnomaly - назначение ревьюера, которое не могло возникнуть при автоназначении.
+// ReviewerTeam пуст, если пользователя ревьюера нет в users
+type ReviewerAnomaly struct {
+	PullRequestID string
+	UserID        string
+	AuthorTeam    string
+	ReviewerTeam  string
+}
+
+// AnomalyReport - итог DetectAnomalies по категориям
+type AnomalyReport struct {
+	AuthorIsReviewer  []ReviewerAnomaly
+	CrossTeamReviewer []ReviewerAnomaly
+	// UnknownReviewer - ревьюеры без записи в users, а на открытых PR и удалённые из команды
+	UnknownReviewer []ReviewerAnomaly
+	// Skipped - категории, которые текущая конфигурация допускает и потому не проверяет
+	Skipped []AnomalyKind
+}
+
+type AnomalyKind string
+
+const (
+	AnomalyAuthorIsReviewer  AnomalyKind = "author_is_reviewer"
+	AnomalyCrossTeamReviewer AnomalyKind = "cross_team_reviewer"
+	AnomalyUnknownReviewer   AnomalyKind = "unknown_reviewer"
+)
+
+type InactiveReassignReport struct {
+	Reassigned []ReviewerReplacement
+	Removed    []ReviewerReplacement
+	// Skipped - PR, смерженные во время обработки
+	Skipped []ReviewerAssignment
 }
 
 func (pr *PullRequest) IsMerged() bool {
 	return pr.Status == PRStatusMerged
 }
+
+// EnsureOpen проверка для всех изменений PR: ревьюеров, названия, меток
+func (pr *PullRequest) EnsureOpen() error {
+	switch pr.Status {
+	case PRStatusOpen:
+		return nil
+	case PRStatusMerged:
+		return ErrPRMerged
+	case PRStatusClosed:
+		return ErrPRClosed
+	default:
+		return ErrPRNotOpen
+	}
+}
+
+// PendingAssignmentReport - итог отложенного назначения ревьюеров PR, созданных во время blackout
+type PendingAssignmentReport struct {
+	Assigned []string
+	// Waiting - PR, команда автора которых всё ещё в blackout
+	Waiting []string
+}
+
+// CandidateBreakdown объясняет NO_CANDIDATE: сколько участников команды отсеялось на каждом шаге выбора
+type CandidateBreakdown struct {
+	TeamMembers int
+	Active      int
+	// Excluded - активные участники, исключённые как автор PR или уже назначенные ревьюеры
+	Excluded int
+	// OverCapacity - оставшиеся активные участники с MaxOpenReviews открытых ревью
+	OverCapacity int
+	Remaining    int
+	// CandidatePool - сколько кандидатов вернул отбор активных до проверки нагрузки, заполняет сервис
+	CandidatePool int
+}
+
+// RebalanceReport - итог перераспределения ревью команды. Нагрузка - число открытых PR на ревью у участника
+type RebalanceReport struct {
+	Moved      []ReviewerReplacement
+	LoadBefore map[string]int
+	LoadAfter  map[string]int
+}
+
+type ReviewerEventType string
+
+const (
+	ReviewerEventAssigned ReviewerEventType = "ASSIGNED"
+	ReviewerEventRemoved  ReviewerEventType = "REMOVED"
+)
+
+type ReviewerEvent struct {
+	EventID       int64
+	PullRequestID string
+	UserID        string
+	EventType     ReviewerEventType
+	// Source - источник назначения, пуст у снятий и событий до появления источников
+	Source    AssignmentSource
+	CreatedAt time.Time
+}
+
+// AuditRecord - назначение или снятие ревьюера для внешнего аудита
+type AuditRecord struct {
+	PullRequestID string
+	UserID        string
+	// Source - источник назначения, пуст у снятий
+	Source     AssignmentSource
+	OccurredAt time.Time
+}
+
+// PRUpdateType - изменение PR в live-обновлениях /pullRequest/events
+type PRUpdateType string
+
+const (
+	PRUpdateReviewerAssigned PRUpdateType = "REVIEWER_ASSIGNED"
+	PRUpdateReviewerRemoved  PRUpdateType = "REVIEWER_REMOVED"
+	PRUpdateMerged           PRUpdateType = "PR_MERGED"
+)
+
+// PRUpdate - закоммиченное изменение одного PR для подписчиков на его live-обновления
+type PRUpdate struct {
+	Type          PRUpdateType
+	PullRequestID string
+	// UserID - назначенный или снятый ревьюер, у PR_MERGED пуст
+	UserID string
+	// Source - источник назначения, есть только у REVIEWER_ASSIGNED
+	Source     AssignmentSource
+	OccurredAt time.Time
+}
+
+// BackfillWatermark - последняя обработанная строка pr_reviewers в порядке (pull_request_id, user_id),
+// пустой watermark - начало таблицы
+type BackfillWatermark struct {
+	PullRequestID string
+	UserID        string
+}
+
+// BackfillBatch - итог одной порции backfill истории ревьюеров
+type BackfillBatch struct {
+	// Scanned - просмотренные строки pr_reviewers, меньше лимита порции - таблица пройдена до конца
+	Scanned int
+	// Inserted - созданные события ASSIGNED, строки с уже существующим событием пропускаются
+	Inserted int
+	Last     BackfillWatermark
+}
+
+type BackfillReport struct {
+	Batches  int
+	Scanned  int
+	Inserted int
+	// Watermark - где остановился backfill, следующий запуск продолжит с него
+	Watermark BackfillWatermark
+}
+
+// ReviewerEventsPage - порция событий ревьюеров всех PR после since для опроса ботами
+type ReviewerEventsPage struct {
+	Events []ReviewerEvent
+	// NextSince - since для следующего запроса: время последнего события порции или исходный since, если событий нет
+	NextSince time.Time
+	// HasMore - за порцией есть ещё события, следующий запрос можно делать сразу
+	HasMore bool
+}
+
+// PREventType - событие PR для внешних уведомлений
+type PREventType string
+
+const (
+	PREventCreated PREventType = "PR_CREATED"
+	PREventMerged  PREventType = "PR_MERGED"
+
+	// события ревьюеров доставляются только в каналы уведомлений (лог, Slack), не в webhook
+	PREventReviewersAssigned  PREventType = "REVIEWERS_ASSIGNED"
+	PREventReviewerReassigned PREventType = "REVIEWER_REASSIGNED"
+	PREventNoCandidate        PREventType = "NO_CANDIDATE"
+)
+
+// PREvent - уведомление о PR, которое отправляется после успешной операции и не влияет на её результат
+type PREvent struct {
+	Type            PREventType
+	PullRequestID   string
+	PullRequestName string
+	AuthorID        string
+	Reviewers       []string
+	// OldReviewerID - снятый ревьюер, NewReviewerID - его замена, пустая, если заменить было некем
+	OldReviewerID string
+	NewReviewerID string
+	OccurredAt    time.Time
+}
+
+// FormerReview - PR, на который пользователь когда-либо назначался, по истории pr_reviewer_events.
+// FirstAssignedAt пуст, если назначение старше истории, LastRemovedAt - если ревьюера ни разу не снимали
+type FormerReview struct {
+	PullRequestID   string
+	FirstAssignedAt *time.Time
+	LastRemovedAt   *time.Time
+}
+
+// OpenPullRequest - открытый PR в обзоре пользователя. AssignedAt заполнен только для PR, где он ревьюер
+type OpenPullRequest struct {
+	PullRequestShort
+	CreatedAt  time.Time
+	AssignedAt *time.Time
+}
+
+// RecentPullRequest - PR ленты /pullRequest/recent: имя автора и число назначенных ревьюеров
+type RecentPullRequest struct {
+	PullRequestShort
+	AuthorName    string
+	ReviewerCount int
+	CreatedAt     time.Time
+}
+
+// UserOverview - профиль пользователя, его открытые PR и открытые PR, где он ревьюер, из одного снимка БД
+type UserOverview struct {
+	User          User
+	AuthoredOpen  []OpenPullRequest
+	ReviewingOpen []OpenPullRequest
+}
+
+// TeamPolicy - правила назначения ревьюеров, которые действуют для команды сейчас: глобальные настройки
+// с подставленными значениями по умолчанию и поправками на состояние команды
+type TeamPolicy struct {
+	TeamName     string
+	MaxReviewers int
+	// ReviewersPerPR - сколько ревьюеров набирает автоназначение
+	ReviewersPerPR int
+	// MaxOpenReviews - 0 означает без ограничения
+	MaxOpenReviews             int
+	NoReviewersPolicy          string
+	ExcludeOutsideWorkingHours bool
+	AllowCrossTeamReviewers    bool
+	ExcludeGroupAuthors        bool
+	RetryUnderstaffed          bool
+	// AutoAssign - false, пока у команды идёт blackout: ревьюеры назначаются после его окончания
+	AutoAssign bool
+	// Overrides - поля, значение которых для команды отличается от глобального
+	Overrides []string
+}
+
+// ReviewerLatency - время от назначения ревьюера до мержа PR по PR, смерженным в окне отчёта
+type ReviewerLatency struct {
+	UserID  string
+	Samples int
+	Median  time.Duration
+	P90     time.Duration
+}
+
+// ReviewerQueuePosition - место PR в очереди ревьюера: Ahead его открытых PR назначены раньше
+type ReviewerQueuePosition struct {
+	UserID     string
+	AssignedAt time.Time
+	Ahead      int
+	// MedianTurnaround - медиана от назначения до мержа за последнее время, nil при малой выборке
+	MedianTurnaround *time.Duration
+}
+
+// PRQueueInfo - очереди ревьюеров PR. У смерженного или закрытого PR очереди нет
+type PRQueueInfo struct {
+	PullRequestID string
+	Status        PRStatus
+	MergedAt      *time.Time
+	Reviewers     []ReviewerQueuePosition
+}
+
+type Page struct {
+	Limit  int
+	Offset int
+	// After - keyset-пагинация: страница начинается сразу после этой записи, Offset тогда не используется
+	After *PageCursor
+}
+
+// PageCursor - ключ сортировки (created_at, id) последней записи предыдущей страницы
+type PageCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
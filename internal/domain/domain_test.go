@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullRequest_EnsureOpen(t *testing.T) {
+	open := PullRequest{Status: PRStatusOpen}
+	assert.NoError(t, open.EnsureOpen())
+
+	merged := PullRequest{Status: PRStatusMerged}
+	err := merged.EnsureOpen()
+	assert.ErrorIs(t, err, ErrPRMerged)
+	assert.ErrorIs(t, err, ErrPRNotOpen)
+
+	closed := PullRequest{Status: PRStatusClosed}
+	err = closed.EnsureOpen()
+	assert.ErrorIs(t, err, ErrPRClosed)
+	assert.ErrorIs(t, err, ErrPRNotOpen)
+	assert.NotErrorIs(t, err, ErrPRMerged)
+
+	unknown := PullRequest{Status: PRStatus("DRAFT")}
+	err = unknown.EnsureOpen()
+	assert.ErrorIs(t, err, ErrPRNotOpen)
+	assert.NotErrorIs(t, err, ErrPRMerged)
+	assert.NotErrorIs(t, err, ErrPRClosed)
+}
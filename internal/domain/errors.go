@@ -1,15 +1,87 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrInvalidInput = errors.New("invalid input")
 	ErrTeamExists   = errors.New("team already exists")
 	ErrPRExists     = errors.New("pull request already exists")
-	ErrPRMerged     = errors.New("pull request is merged")
+	// ErrPRNotOpen общая ошибка для любых изменений PR не в статусе OPEN
+	ErrPRNotOpen    = errors.New("pull request is not open")
+	ErrPRMerged     = fmt.Errorf("%w: pull request is merged", ErrPRNotOpen)
+	ErrPRClosed     = fmt.Errorf("%w: pull request is closed", ErrPRNotOpen)
 	ErrNotAssigned  = errors.New("reviewer not assigned")
 	ErrNoCandidate  = errors.New("no candidate available")
 	ErrPRNotFound   = errors.New("pull request not found")
 	ErrTeamNotFound = errors.New("team not found")
 	ErrUserNotFound = errors.New("user not found")
+
+	ErrInvalidSchedule = errors.New("invalid working hours")
+	// ErrInvalidReviewCapacity review_capacity вне (0, MaxReviewCapacity]
+	ErrInvalidReviewCapacity = errors.New("invalid review capacity")
+	// ErrInvalidTransition недопустимая смена статуса PR, например MERGED -> OPEN
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrForbidden ключ привязан к другой команде
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrReviewerNotEligible общая ошибка для ревьюеров, которых нельзя назначить на PR
+	ErrReviewerNotEligible = errors.New("reviewer is not eligible")
+	ErrReviewerInactive    = fmt.Errorf("%w: reviewer is inactive", ErrReviewerNotEligible)
+	ErrReviewerIsAuthor    = fmt.Errorf("%w: author cannot review own PR", ErrReviewerNotEligible)
+	ErrReviewerNotInTeam   = fmt.Errorf("%w: reviewer is not in author's team", ErrReviewerNotEligible)
+	ErrAlreadyAssigned     = errors.New("reviewer already assigned")
+	// ErrReviewerCapReached у PR уже максимальное число ревьюеров
+	ErrReviewerCapReached = errors.New("reviewer cap reached")
+
+	// ErrBlackoutOverlap окно пересекается с уже заданным окном команды
+	ErrBlackoutOverlap  = errors.New("blackout overlaps existing window")
+	ErrBlackoutNotFound = errors.New("blackout not found")
+
+	// ErrWebhookExists у команды уже есть webhook, изменить его можно через PATCH
+	ErrWebhookExists   = errors.New("team webhook already exists")
+	ErrWebhookNotFound = errors.New("team webhook not found")
+
+	// ErrTeamTooLarge состав команды превысил бы MAX_TEAM_MEMBERS
+	ErrTeamTooLarge = errors.New("team is too large")
+
+	// ErrAuthorTeamMissing команда автора PR удалена в обход API
+	ErrAuthorTeamMissing = errors.New("author's team does not exist")
+
+	// ErrAuthorInactive автор PR неактивен, а REJECT_INACTIVE_AUTHORS запрещает такие PR
+	ErrAuthorInactive = errors.New("author is inactive")
+
+	// ErrReassignRateExceeded ревьюеров PR за последний час меняли уже MAX_REASSIGNMENTS_PER_HOUR раз
+	ErrReassignRateExceeded = errors.New("reassignment rate exceeded")
+
+	// ErrMergeActorRequired включён MERGE_REQUIRES_ACTOR, а merged_by не передан
+	ErrMergeActorRequired = errors.New("merged_by is required")
+	// ErrMergeActorNotFound пользователя из merged_by не существует
+	ErrMergeActorNotFound = errors.New("merge actor not found")
+	// ErrMergeActorInactive смержить PR от имени неактивного пользователя нельзя
+	ErrMergeActorInactive = errors.New("merge actor is inactive")
+	// ErrMergeNotAllowed merged_by не автор и не ревьюер PR в рамках MERGE_ACTOR_POLICY
+	ErrMergeNotAllowed = errors.New("user is not allowed to merge this PR")
+
+	// ErrReadOnly запись отклонена: БД доступна только на чтение (standby после failover) или включён READ_ONLY_MODE
+	ErrReadOnly = errors.New("service is read-only")
+	// ErrOverloaded запрос отклонён без обработки: одновременно обрабатывается MAX_IN_FLIGHT запросов
+	ErrOverloaded = errors.New("service is overloaded")
 )
+
+// ConflictError дополняет доменную ошибку данными о конфликтующем объекте,
+// например уже существующим PR для ErrPRExists. errors.Is видит исходную ошибку через Unwrap
+type ConflictError struct {
+	Err     error
+	Payload any
+}
+
+func (e *ConflictError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
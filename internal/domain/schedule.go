@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// WorkingHours рабочее окно пользователя в его часовом поясе, например "Mon-Fri 09:00-18:00".
+// Если конец раньше начала, окно переходит через полночь и относится к дню начала
+type WorkingHours struct {
+	days     [7]bool
+	start    int
+	end      int
+	location *time.Location
+}
+
+// ParseWorkingHours разбирает "<дни> <HH:MM>-<HH:MM>", где дни - список через запятую
+// из отдельных дней и диапазонов (Mon-Fri, Sat,Sun, Fri-Mon), и часовой пояс IANA
+func ParseWorkingHours(spec, timezone string) (*WorkingHours, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("%w: expected \"<days> <HH:MM>-<HH:MM>\"", ErrInvalidSchedule)
+	}
+
+	wh := &WorkingHours{}
+
+	if err := wh.parseDays(fields[0]); err != nil {
+		return nil, err
+	}
+	if err := wh.parseHours(fields[1]); err != nil {
+		return nil, err
+	}
+
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidSchedule, timezone)
+	}
+	wh.location = location
+
+	return wh, nil
+}
+
+func (wh *WorkingHours) parseDays(spec string) error {
+	for _, part := range strings.Split(spec, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+
+		first, ok := weekdayNames[strings.ToLower(from)]
+		if !ok {
+			return fmt.Errorf("%w: unknown day %q", ErrInvalidSchedule, from)
+		}
+		if !isRange {
+			wh.days[first] = true
+			continue
+		}
+
+		last, ok := weekdayNames[strings.ToLower(to)]
+		if !ok {
+			return fmt.Errorf("%w: unknown day %q", ErrInvalidSchedule, to)
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			wh.days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func (wh *WorkingHours) parseHours(spec string) error {
+	from, to, ok := strings.Cut(spec, "-")
+	if !ok {
+		return fmt.Errorf("%w: expected hours range HH:MM-HH:MM", ErrInvalidSchedule)
+	}
+
+	start, err := parseClock(from)
+	if err != nil {
+		return err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return err
+	}
+	if start == end {
+		return fmt.Errorf("%w: empty hours range", ErrInvalidSchedule)
+	}
+
+	wh.start, wh.end = start, end
+	return nil
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid time %q", ErrInvalidSchedule, value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains проверяет, попадает ли момент t в рабочее окно
+func (wh *WorkingHours) Contains(t time.Time) bool {
+	local := t.In(wh.location)
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+
+	if wh.start < wh.end {
+		return wh.days[day] && minute >= wh.start && minute < wh.end
+	}
+
+	// окно через полночь: вечер дня начала или утро следующего дня
+	if minute >= wh.start {
+		return wh.days[day]
+	}
+	previous := (day + 6) % 7
+	return minute < wh.end && wh.days[previous]
+}
+
+// Schedule возвращает рабочее окно пользователя, nil если оно не задано
+func (u *User) Schedule() (*WorkingHours, error) {
+	if u.WorkingHours == "" {
+		return nil, nil
+	}
+	return ParseWorkingHours(u.WorkingHours, u.Timezone)
+}
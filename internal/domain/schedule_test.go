@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorkingHours(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		timezone string
+		wantErr  bool
+	}{
+		{name: "weekday range", spec: "Mon-Fri 09:00-18:00", timezone: "Europe/Moscow"},
+		{name: "day list", spec: "Mon,Wed,Fri 10:00-16:00", timezone: "UTC"},
+		{name: "wrapping day range", spec: "Fri-Mon 10:00-16:00"},
+		{name: "overnight hours", spec: "Mon-Fri 22:00-06:00", timezone: "Asia/Tokyo"},
+		{name: "lowercase days", spec: "mon-fri 09:00-18:00"},
+		{name: "missing hours", spec: "Mon-Fri", wantErr: true},
+		{name: "unknown day", spec: "Mon-Fry 09:00-18:00", wantErr: true},
+		{name: "invalid time", spec: "Mon-Fri 9-18", wantErr: true},
+		{name: "hour out of range", spec: "Mon-Fri 09:00-25:00", wantErr: true},
+		{name: "empty range", spec: "Mon-Fri 09:00-09:00", wantErr: true},
+		{name: "unknown timezone", spec: "Mon-Fri 09:00-18:00", timezone: "Mars/Olympus", wantErr: true},
+		{name: "extra fields", spec: "Mon-Fri 09:00-18:00 UTC", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wh, err := ParseWorkingHours(tt.spec, tt.timezone)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrInvalidSchedule)
+				assert.Nil(t, wh)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, wh)
+		})
+	}
+}
+
+func TestWorkingHours_Contains(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	// 2025-10-03 - пятница
+	friday := func(hour, minute int) time.Time {
+		return time.Date(2025, 10, 3, hour, minute, 0, 0, moscow)
+	}
+
+	t.Run("weekday window in user timezone", func(t *testing.T) {
+		wh, err := ParseWorkingHours("Mon-Fri 09:00-18:00", "Europe/Moscow")
+		require.NoError(t, err)
+
+		assert.True(t, wh.Contains(friday(9, 0)))
+		assert.True(t, wh.Contains(friday(17, 59)))
+		assert.False(t, wh.Contains(friday(18, 0)))
+		assert.False(t, wh.Contains(friday(8, 59)))
+		assert.False(t, wh.Contains(friday(12, 0).AddDate(0, 0, 1)))
+		// 07:00 UTC = 10:00 по Москве
+		assert.True(t, wh.Contains(time.Date(2025, 10, 3, 7, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("overnight window belongs to start day", func(t *testing.T) {
+		wh, err := ParseWorkingHours("Fri 22:00-06:00", "Europe/Moscow")
+		require.NoError(t, err)
+
+		assert.True(t, wh.Contains(friday(23, 0)))
+		assert.True(t, wh.Contains(friday(5, 0).AddDate(0, 0, 1)))
+		assert.False(t, wh.Contains(friday(5, 0)))
+		assert.False(t, wh.Contains(friday(6, 0).AddDate(0, 0, 1)))
+	})
+
+	t.Run("wrapping day range", func(t *testing.T) {
+		wh, err := ParseWorkingHours("Sat-Sun 10:00-16:00", "Europe/Moscow")
+		require.NoError(t, err)
+
+		assert.True(t, wh.Contains(friday(12, 0).AddDate(0, 0, 1)))
+		assert.True(t, wh.Contains(friday(12, 0).AddDate(0, 0, 2)))
+		assert.False(t, wh.Contains(friday(12, 0)))
+	})
+}
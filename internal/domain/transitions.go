@@ -0,0 +1,39 @@
+package domain
+
+// PRTransition - операция, которой меняется статус PR
+type PRTransition string
+
+const (
+	// TransitionMerge - merge через /pullRequest/merge или смену статуса в PATCH /pullRequest
+	TransitionMerge PRTransition = "merge"
+	// TransitionClose - закрытие без мержа, например PR автора, удалённого из команды
+	TransitionClose PRTransition = "close"
+	// TransitionReopen - возврат смерженного PR в работу
+	TransitionReopen PRTransition = "reopen"
+)
+
+// prTransitions - разрешённые смены статуса и единственная операция, которой каждая выполняется
+var prTransitions = map[PRStatus]map[PRStatus]PRTransition{
+	PRStatusOpen: {
+		PRStatusMerged: TransitionMerge,
+		PRStatusClosed: TransitionClose,
+	},
+	PRStatusMerged: {
+		PRStatusOpen: TransitionReopen,
+	},
+}
+
+// ValidateTransition проверяет, что операция via переводит PR из from в to. Переход в текущий статус -
+// не изменение и разрешён любой операцией, неизвестный статус - ErrInvalidInput
+func ValidateTransition(from, to PRStatus, via PRTransition) error {
+	if !to.Valid() {
+		return &ValidationError{Violations: []FieldViolation{{Field: "status", Rule: RuleOneOf}}}
+	}
+	if from == to {
+		return nil
+	}
+	if allowed, ok := prTransitions[from][to]; ok && allowed == via {
+		return nil
+	}
+	return ErrInvalidTransition
+}
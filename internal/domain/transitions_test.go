@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		from, to PRStatus
+		via      PRTransition
+		want     error
+	}{
+		{from: PRStatusOpen, to: PRStatusMerged, via: TransitionMerge},
+		{from: PRStatusOpen, to: PRStatusClosed, via: TransitionClose},
+		{from: PRStatusMerged, to: PRStatusOpen, via: TransitionReopen},
+		{from: PRStatusOpen, to: PRStatusOpen, via: TransitionMerge},
+		{from: PRStatusMerged, to: PRStatusMerged, via: TransitionMerge},
+		{from: PRStatusOpen, to: PRStatusMerged, via: TransitionClose, want: ErrInvalidTransition},
+		{from: PRStatusOpen, to: PRStatusClosed, via: TransitionMerge, want: ErrInvalidTransition},
+		{from: PRStatusMerged, to: PRStatusOpen, via: TransitionMerge, want: ErrInvalidTransition},
+		{from: PRStatusMerged, to: PRStatusClosed, via: TransitionClose, want: ErrInvalidTransition},
+		{from: PRStatusClosed, to: PRStatusMerged, via: TransitionMerge, want: ErrInvalidTransition},
+		{from: PRStatusClosed, to: PRStatusOpen, via: TransitionReopen, want: ErrInvalidTransition},
+		{from: PRStatusOpen, to: PRStatus("DRAFT"), via: TransitionMerge, want: ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to)+" via "+string(tt.via), func(t *testing.T) {
+			err := ValidateTransition(tt.from, tt.to, tt.via)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Пределы полей доменных объектов. Транспорты могут проверять их раньше, но сервисы проверяют всегда
+const (
+	// MaxIDLength - pull_request_id, user_id, team_name и group_id
+	MaxIDLength = 64
+	// MaxNameLength - pull_request_name и username
+	MaxNameLength = 64
+	// MaxLabels - меток у одного PR
+	MaxLabels      = 20
+	MaxLabelLength = 64
+)
+
+// Правила нарушений называются так же, как теги HTTP-валидатора, чтобы details ответа не зависели от того,
+// где нарушение найдено
+const (
+	RuleRequired   = "required"
+	RuleIdentifier = "identifier"
+	RuleOneOf      = "oneof"
+	// RuleRetention - время старше срока хранения данных
+	RuleRetention = "retention"
+)
+
+// FieldViolation - поле и нарушенное правило, например members[0].user_id и identifier
+type FieldViolation struct {
+	Field string
+	Rule  string
+}
+
+// ValidationError - ErrInvalidInput с перечнем нарушенных правил
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.Field + ": " + v.Rule
+	}
+	return ErrInvalidInput.Error() + ": " + strings.Join(parts, ", ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// violations собирает нарушения одного объекта, err - nil, если их нет
+type violations []FieldViolation
+
+func (v *violations) add(field, rule string) {
+	*v = append(*v, FieldViolation{Field: field, Rule: rule})
+}
+
+// merge добавляет нарушения вложенного объекта с префиксом поля
+func (v *violations) merge(prefix string, err error) {
+	if verr, ok := err.(*ValidationError); ok {
+		for _, nested := range verr.Violations {
+			v.add(prefix+"."+nested.Field, nested.Rule)
+		}
+	}
+}
+
+func (v violations) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: v}
+}
+
+// id проверяет идентификатор; необязательный пустой пропускается
+func (v *violations) id(field, value string, required bool) {
+	switch {
+	case value == "":
+		if required {
+			v.add(field, RuleRequired)
+		}
+	case utf8.RuneCountInString(value) > MaxIDLength:
+		v.add(field, fmt.Sprintf("max=%d", MaxIDLength))
+	case !IsIdentifier(value):
+		v.add(field, RuleIdentifier)
+	}
+}
+
+func (v *violations) text(field, value string, maxLen int) {
+	switch {
+	case value == "":
+		v.add(field, RuleRequired)
+	case utf8.RuneCountInString(value) > maxLen:
+		v.add(field, fmt.Sprintf("max=%d", maxLen))
+	}
+}
+
+// IsIdentifier - латиница, цифры и -_./#, без сегментов "." и ".." между слешами.
+// Пустую строку пропускает, её отсекает required
+func IsIdentifier(s string) bool {
+	for _, c := range []byte(s) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '_', c == '.', c == '/', c == '#':
+		default:
+			return false
+		}
+	}
+	for _, segment := range strings.Split(s, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate проверяет user_id и username участника
+func (m TeamMember) Validate() error {
+	var v violations
+	v.id("user_id", m.UserID, true)
+	v.text("username", m.Username, MaxNameLength)
+	return v.err()
+}
+
+// Validate проверяет имя команды и каждого участника, в команде должен быть хотя бы один
+func (t Team) Validate() error {
+	var v violations
+	v.id("team_name", t.TeamName, true)
+	if len(t.Members) == 0 {
+		v.add("members", "min=1")
+	}
+	for i, m := range t.Members {
+		v.merge(fmt.Sprintf("members[%d]", i), m.Validate())
+	}
+	return v.err()
+}
+
+// Validate проверяет поля нового PR. Пустые Priority и Strategy означают значения по умолчанию
+func (p PullRequestCreate) Validate() error {
+	var v violations
+	v.id("pull_request_id", p.PullRequestID, true)
+	v.text("pull_request_name", p.PullRequestName, MaxNameLength)
+	v.id("author_id", p.AuthorID, true)
+	v.id("group_id", p.GroupID, false)
+	if p.Priority != "" && !p.Priority.Valid() {
+		v.add("priority", RuleOneOf)
+	}
+	if !p.Strategy.Valid() {
+		v.add("strategy", RuleOneOf)
+	}
+	if len(p.Labels) > MaxLabels {
+		v.add("labels", fmt.Sprintf("max=%d", MaxLabels))
+	}
+	for i, label := range p.Labels {
+		v.text(fmt.Sprintf("labels[%d]", i), label, MaxLabelLength)
+	}
+	return v.err()
+}
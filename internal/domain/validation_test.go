@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsIdentifier(t *testing.T) {
+	for _, s := range []string{"", "u1", "team-a_b", "org/repo#12", "v1.2", "a/.b"} {
+		assert.True(t, IsIdentifier(s), s)
+	}
+	for _, s := range []string{"has space", "кириллица", "a/../b", "./a", "a/..", "x;drop"} {
+		assert.False(t, IsIdentifier(s), s)
+	}
+}
+
+func TestTeam_Validate(t *testing.T) {
+	member := TeamMember{UserID: "u1", Username: "alice"}
+
+	tests := []struct {
+		name string
+		team Team
+		want []FieldViolation
+	}{
+		{name: "valid", team: Team{TeamName: "backend", Members: []TeamMember{member}}},
+		{
+			name: "no members",
+			team: Team{TeamName: "backend"},
+			want: []FieldViolation{{Field: "members", Rule: "min=1"}},
+		},
+		{
+			name: "invalid team name",
+			team: Team{TeamName: "back end", Members: []TeamMember{member}},
+			want: []FieldViolation{{Field: "team_name", Rule: RuleIdentifier}},
+		},
+		{
+			name: "member violations are prefixed",
+			team: Team{TeamName: "backend", Members: []TeamMember{
+				member,
+				{UserID: strings.Repeat("u", MaxIDLength+1)},
+			}},
+			want: []FieldViolation{
+				{Field: "members[1].user_id", Rule: "max=64"},
+				{Field: "members[1].username", Rule: RuleRequired},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertViolations(t, tt.want, tt.team.Validate())
+		})
+	}
+}
+
+func TestPullRequestCreate_Validate(t *testing.T) {
+	valid := func() PullRequestCreate {
+		return PullRequestCreate{PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1"}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*PullRequestCreate)
+		want   []FieldViolation
+	}{
+		{name: "valid", modify: func(*PullRequestCreate) {}},
+		{
+			name: "all optional fields set",
+			modify: func(p *PullRequestCreate) {
+				p.GroupID, p.Priority, p.Strategy, p.Labels = "g1", PRPriorityHigh, SelectionStrategyRandom, []string{"bug"}
+			},
+		},
+		{
+			name:   "required fields",
+			modify: func(p *PullRequestCreate) { *p = PullRequestCreate{} },
+			want: []FieldViolation{
+				{Field: "pull_request_id", Rule: RuleRequired},
+				{Field: "pull_request_name", Rule: RuleRequired},
+				{Field: "author_id", Rule: RuleRequired},
+			},
+		},
+		{
+			name:   "identifiers",
+			modify: func(p *PullRequestCreate) { p.PullRequestID, p.AuthorID, p.GroupID = "pr 1", "../u1", "g?" },
+			want: []FieldViolation{
+				{Field: "pull_request_id", Rule: RuleIdentifier},
+				{Field: "author_id", Rule: RuleIdentifier},
+				{Field: "group_id", Rule: RuleIdentifier},
+			},
+		},
+		{
+			name:   "name length counts runes",
+			modify: func(p *PullRequestCreate) { p.PullRequestName = strings.Repeat("я", MaxNameLength+1) },
+			want:   []FieldViolation{{Field: "pull_request_name", Rule: "max=64"}},
+		},
+		{
+			name:   "unknown enums",
+			modify: func(p *PullRequestCreate) { p.Priority, p.Strategy = "URGENT", "round_robin" },
+			want: []FieldViolation{
+				{Field: "priority", Rule: RuleOneOf},
+				{Field: "strategy", Rule: RuleOneOf},
+			},
+		},
+		{
+			name:   "too many labels",
+			modify: func(p *PullRequestCreate) { p.Labels = make([]string, MaxLabels+1); fillLabels(p.Labels) },
+			want:   []FieldViolation{{Field: "labels", Rule: "max=20"}},
+		},
+		{
+			name:   "bad labels",
+			modify: func(p *PullRequestCreate) { p.Labels = []string{"ok", "", strings.Repeat("l", MaxLabelLength+1)} },
+			want: []FieldViolation{
+				{Field: "labels[1]", Rule: RuleRequired},
+				{Field: "labels[2]", Rule: "max=64"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid()
+			tt.modify(&p)
+			assertViolations(t, tt.want, p.Validate())
+		})
+	}
+}
+
+func fillLabels(labels []string) {
+	for i := range labels {
+		labels[i] = "l"
+	}
+}
+
+func assertViolations(t *testing.T, want []FieldViolation, err error) {
+	t.Helper()
+	if want == nil {
+		require.NoError(t, err)
+		return
+	}
+	require.ErrorIs(t, err, ErrInvalidInput)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, want, verr.Violations)
+}
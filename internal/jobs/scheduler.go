@@ -0,0 +1,265 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"avito_backend_task/pkg/clock"
+)
+
+var (
+	ErrJobExists      = errors.New("job already registered")
+	ErrInvalidJob     = errors.New("invalid job")
+	ErrAlreadyStarted = errors.New("scheduler already started")
+)
+
+// Locker выбирает лидера для каждого запуска: задачу выполняет только реплика, взявшая лок
+type Locker interface {
+	TryLock(ctx context.Context, name string) (release func(), acquired bool, err error)
+}
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Timeout ограничивает один запуск, 0 - без ограничения
+	Timeout time.Duration
+	// Local - задача выполняется на каждой реплике, без лока лидера
+	Local bool
+	Run   func(ctx context.Context) error
+}
+
+type Status struct {
+	Name         string
+	Interval     time.Duration
+	Running      bool
+	LastRunAt    *time.Time
+	LastDuration time.Duration
+	LastError    string
+	RunCount     int
+	SkipCount    int
+}
+
+type Option func(*Scheduler)
+
+func WithClock(c clock.Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = c
+	}
+}
+
+// WithJitter задаёт случайную добавку к интервалу как долю от него (0.1 = до 10%)
+func WithJitter(fraction float64) Option {
+	return func(s *Scheduler) {
+		s.jitter = fraction
+	}
+}
+
+type Scheduler struct {
+	locker Locker
+	clock  clock.Clock
+	jitter float64
+	lg     *slog.Logger
+
+	mu       sync.Mutex
+	jobs     []Job
+	statuses map[string]*Status
+	started  bool
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewScheduler(locker Locker, lg *slog.Logger, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		locker:   locker,
+		clock:    clock.New(),
+		jitter:   0.1,
+		lg:       lg,
+		statuses: make(map[string]*Status),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Interval <= 0 || job.Run == nil {
+		return ErrInvalidJob
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return ErrAlreadyStarted
+	}
+	if _, ok := s.statuses[job.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrJobExists, job.Name)
+	}
+
+	s.jobs = append(s.jobs, job)
+	s.statuses[job.Name] = &Status{Name: job.Name, Interval: job.Interval}
+	return nil
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+
+	s.lg.Info("job scheduler started", slog.Int("jobs_count", len(s.jobs)))
+}
+
+// Shutdown останавливает планировщик и ждёт завершения текущих запусков
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.lg.Info("job scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) Statuses() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Status, 0, len(s.statuses))
+	for _, status := range s.statuses {
+		result = append(result, *status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(s.nextDelay(job.Interval)):
+		}
+
+		s.runOnce(ctx, job)
+	}
+}
+
+func (s *Scheduler) nextDelay(interval time.Duration) time.Duration {
+	if s.jitter <= 0 {
+		return interval
+	}
+
+	return interval + time.Duration(rand.Float64()*s.jitter*float64(interval))
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	log := s.lg.With(slog.String("job", job.Name))
+
+	if !job.Local {
+		release, acquired, err := s.locker.TryLock(ctx, "job:"+job.Name)
+		if err != nil {
+			log.Warn("failed to acquire job lock", slog.Any("error", err))
+			s.recordSkip(job.Name)
+			return
+		}
+		if !acquired {
+			log.Debug("job is run by another instance")
+			s.recordSkip(job.Name)
+			return
+		}
+		defer release()
+	}
+
+	s.setRunning(job.Name, true)
+	start := s.clock.Now()
+
+	err := s.execute(ctx, job)
+
+	s.recordRun(job.Name, start, s.clock.Now().Sub(start), err)
+	if err != nil {
+		log.Error("job failed", slog.Any("error", err))
+		return
+	}
+	log.Debug("job completed")
+}
+
+func (s *Scheduler) execute(ctx context.Context, job Job) (err error) {
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return job.Run(ctx)
+}
+
+func (s *Scheduler) setRunning(name string, running bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.statuses[name].Running = running
+}
+
+func (s *Scheduler) recordSkip(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.statuses[name].SkipCount++
+}
+
+func (s *Scheduler) recordRun(name string, start time.Time, duration time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.statuses[name]
+	status.Running = false
+	status.LastRunAt = &start
+	status.LastDuration = duration
+	status.RunCount++
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+	}
+}
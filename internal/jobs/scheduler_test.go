@@ -0,0 +1,303 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/pkg/clock"
+)
+
+// fakeLocker имитирует advisory-лок, общий для нескольких реплик
+type fakeLocker struct {
+	mu     sync.Mutex
+	held   map[string]bool
+	err    error
+	taken  int
+	denied int
+}
+
+func newFakeLocker() *fakeLocker {
+	return &fakeLocker{held: make(map[string]bool)}
+}
+
+func (l *fakeLocker) TryLock(_ context.Context, name string) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.err != nil {
+		return nil, false, l.err
+	}
+	if l.held[name] {
+		l.denied++
+		return nil, false, nil
+	}
+
+	l.held[name] = true
+	l.taken++
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.held, name)
+	}, true, nil
+}
+
+func (l *fakeLocker) hold(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held[name] = true
+}
+
+func newTestScheduler(locker Locker, clk clock.Clock) *Scheduler {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	return NewScheduler(locker, logger, WithClock(clk), WithJitter(0))
+}
+
+func startScheduler(t *testing.T, s *Scheduler) {
+	t.Helper()
+
+	s.Start(context.Background())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, s.Shutdown(ctx))
+	})
+}
+
+func waitRun(t *testing.T, runs <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("job did not run")
+	}
+}
+
+func TestScheduler_RunsOnInterval(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC))
+	s := newTestScheduler(newFakeLocker(), clk)
+
+	runs := make(chan struct{}, 10)
+	require.NoError(t, s.Register(Job{
+		Name:     "sweep",
+		Interval: time.Minute,
+		Run: func(ctx context.Context) error {
+			runs <- struct{}{}
+			return nil
+		},
+	}))
+	startScheduler(t, s)
+
+	clk.BlockUntil(1)
+	clk.Advance(30 * time.Second)
+	assert.Empty(t, runs)
+
+	clk.Advance(30 * time.Second)
+	waitRun(t, runs)
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute)
+	waitRun(t, runs)
+
+	clk.BlockUntil(1)
+	status := s.Statuses()[0]
+	assert.Equal(t, "sweep", status.Name)
+	assert.Equal(t, 2, status.RunCount)
+	require.NotNil(t, status.LastRunAt)
+	assert.Equal(t, clk.Now(), *status.LastRunAt)
+	assert.Empty(t, status.LastError)
+}
+
+func TestScheduler_RecoversPanicAndRecordsError(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC))
+	s := newTestScheduler(newFakeLocker(), clk)
+
+	runs := make(chan struct{}, 10)
+	calls := 0
+	require.NoError(t, s.Register(Job{
+		Name:     "flaky",
+		Interval: time.Minute,
+		Run: func(ctx context.Context) error {
+			defer func() { runs <- struct{}{} }()
+			calls++
+			if calls == 1 {
+				panic("boom")
+			}
+			return errors.New("db unavailable")
+		},
+	}))
+	startScheduler(t, s)
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute)
+	waitRun(t, runs)
+
+	clk.BlockUntil(1)
+	assert.Contains(t, s.Statuses()[0].LastError, "boom")
+
+	clk.Advance(time.Minute)
+	waitRun(t, runs)
+
+	clk.BlockUntil(1)
+	status := s.Statuses()[0]
+	assert.Equal(t, "db unavailable", status.LastError)
+	assert.Equal(t, 2, status.RunCount)
+}
+
+func TestScheduler_AppliesTimeout(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC))
+	s := newTestScheduler(newFakeLocker(), clk)
+
+	deadlines := make(chan bool, 1)
+	require.NoError(t, s.Register(Job{
+		Name:     "bounded",
+		Interval: time.Minute,
+		Timeout:  5 * time.Second,
+		Run: func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			deadlines <- ok
+			return nil
+		},
+	}))
+	startScheduler(t, s)
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute)
+
+	select {
+	case ok := <-deadlines:
+		assert.True(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("job did not run")
+	}
+}
+
+func TestScheduler_LeaderElection(t *testing.T) {
+	start := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("skips run when another instance holds the lock", func(t *testing.T) {
+		clk := clock.NewFake(start)
+		locker := newFakeLocker()
+		s := newTestScheduler(locker, clk)
+		runs := make(chan struct{}, 10)
+		require.NoError(t, s.Register(Job{
+			Name:     "exclusive",
+			Interval: time.Minute,
+			Run: func(ctx context.Context) error {
+				runs <- struct{}{}
+				return nil
+			},
+		}))
+		locker.hold("job:exclusive")
+		startScheduler(t, s)
+
+		clk.BlockUntil(1)
+		clk.Advance(time.Minute)
+		clk.BlockUntil(1)
+
+		assert.Empty(t, runs)
+		status := s.Statuses()[0]
+		assert.Equal(t, 0, status.RunCount)
+		assert.Equal(t, 1, status.SkipCount)
+	})
+
+	t.Run("only one of two replicas runs a tick", func(t *testing.T) {
+		clk := clock.NewFake(start)
+		shared := newFakeLocker()
+		release := make(chan struct{})
+		runs := make(chan struct{}, 10)
+
+		replicas := []*Scheduler{newTestScheduler(shared, clk), newTestScheduler(shared, clk)}
+		for _, s := range replicas {
+			require.NoError(t, s.Register(Job{
+				Name:     "exclusive",
+				Interval: time.Minute,
+				Run: func(ctx context.Context) error {
+					runs <- struct{}{}
+					<-release
+					return nil
+				},
+			}))
+			startScheduler(t, s)
+		}
+
+		clk.BlockUntil(2)
+		clk.Advance(time.Minute)
+		waitRun(t, runs)
+		// вторая реплика видит занятый лок и снова встаёт в ожидание
+		clk.BlockUntil(1)
+		close(release)
+
+		assert.Empty(t, runs)
+		shared.mu.Lock()
+		assert.Equal(t, 1, shared.taken)
+		assert.Equal(t, 1, shared.denied)
+		shared.mu.Unlock()
+	})
+
+	t.Run("lock errors are recorded as skips", func(t *testing.T) {
+		clk := clock.NewFake(start)
+		failing := newFakeLocker()
+		failing.err = errors.New("connection refused")
+		s := newTestScheduler(failing, clk)
+		require.NoError(t, s.Register(Job{
+			Name:     "unlucky",
+			Interval: time.Minute,
+			Run:      func(ctx context.Context) error { return nil },
+		}))
+		startScheduler(t, s)
+
+		clk.BlockUntil(1)
+		clk.Advance(time.Minute)
+		clk.BlockUntil(1)
+
+		assert.Equal(t, 1, s.Statuses()[0].SkipCount)
+	})
+
+	t.Run("local job runs without the lock", func(t *testing.T) {
+		clk := clock.NewFake(start)
+		locker := newFakeLocker()
+		s := newTestScheduler(locker, clk)
+		runs := make(chan struct{}, 10)
+		require.NoError(t, s.Register(Job{
+			Name:     "sampler",
+			Interval: time.Minute,
+			Local:    true,
+			Run: func(ctx context.Context) error {
+				runs <- struct{}{}
+				return nil
+			},
+		}))
+		locker.hold("job:sampler")
+		startScheduler(t, s)
+
+		clk.BlockUntil(1)
+		clk.Advance(time.Minute)
+		waitRun(t, runs)
+
+		locker.mu.Lock()
+		assert.Equal(t, 0, locker.taken)
+		assert.Equal(t, 0, locker.denied)
+		locker.mu.Unlock()
+	})
+}
+
+func TestScheduler_Register(t *testing.T) {
+	s := newTestScheduler(newFakeLocker(), clock.NewFake(time.Now()))
+	job := Job{Name: "a", Interval: time.Second, Run: func(ctx context.Context) error { return nil }}
+
+	require.NoError(t, s.Register(job))
+	assert.ErrorIs(t, s.Register(job), ErrJobExists)
+	assert.ErrorIs(t, s.Register(Job{Name: "b"}), ErrInvalidJob)
+
+	startScheduler(t, s)
+	assert.ErrorIs(t, s.Register(Job{Name: "c", Interval: time.Second, Run: job.Run}), ErrAlreadyStarted)
+}
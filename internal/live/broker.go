@@ -0,0 +1,83 @@
+// Package live раздаёт закоммиченные изменения PR подписчикам внутри процесса,
+// например SSE-потокам /pullRequest/events
+package live
+
+import (
+	"sync"
+
+	"avito_backend_task/internal/domain"
+)
+
+// subscriberBuffer - сколько изменений ждут подписчика, прежде чем он будет отключён
+const subscriberBuffer = 16
+
+type subscription struct {
+	updates chan domain.PRUpdate
+	closed  bool
+}
+
+// Broker - pub/sub изменений PR в памяти одной реплики. Изменения, сделанные на других
+// репликах, подписчикам этой не приходят
+type Broker struct {
+	mu   sync.Mutex
+	subs map[string]map[*subscription]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[string]map[*subscription]struct{})}
+}
+
+// Publish не блокирует. Подписчик, не успевающий разбирать изменения, отключается: его канал
+// закрывается, чтобы клиент переподключился и перечитал PR, а не пропустил изменение молча
+func (b *Broker) Publish(update domain.PRUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs[update.PullRequestID] {
+		select {
+		case sub.updates <- update:
+		default:
+			b.remove(update.PullRequestID, sub)
+		}
+	}
+}
+
+// Subscribe - изменения PR prID с момента подписки. cancel отписывает и закрывает канал,
+// его нужно вызвать при отключении клиента, повторный вызов ничего не делает
+func (b *Broker) Subscribe(prID string) (<-chan domain.PRUpdate, func()) {
+	sub := &subscription{updates: make(chan domain.PRUpdate, subscriberBuffer)}
+
+	b.mu.Lock()
+	if b.subs[prID] == nil {
+		b.subs[prID] = make(map[*subscription]struct{})
+	}
+	b.subs[prID][sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub.updates, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(prID, sub)
+	}
+}
+
+// Subscribers - число подписчиков PR prID
+func (b *Broker) Subscribers(prID string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs[prID])
+}
+
+// remove вызывается под mu
+func (b *Broker) remove(prID string, sub *subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.updates)
+
+	delete(b.subs[prID], sub)
+	if len(b.subs[prID]) == 0 {
+		delete(b.subs, prID)
+	}
+}
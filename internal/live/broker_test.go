@@ -0,0 +1,58 @@
+package live
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+)
+
+func TestBroker_DeliversToSubscribersOfPR(t *testing.T) {
+	b := NewBroker()
+	first, cancelFirst := b.Subscribe("pr1")
+	defer cancelFirst()
+	second, cancelSecond := b.Subscribe("pr1")
+	defer cancelSecond()
+	other, cancelOther := b.Subscribe("pr2")
+	defer cancelOther()
+
+	update := domain.PRUpdate{Type: domain.PRUpdateReviewerAssigned, PullRequestID: "pr1", UserID: "u2"}
+	b.Publish(update)
+
+	assert.Equal(t, update, <-first)
+	assert.Equal(t, update, <-second)
+	assert.Empty(t, other)
+}
+
+func TestBroker_CancelUnsubscribes(t *testing.T) {
+	b := NewBroker()
+	updates, cancel := b.Subscribe("pr1")
+	require.Equal(t, 1, b.Subscribers("pr1"))
+
+	cancel()
+	cancel()
+
+	assert.Equal(t, 0, b.Subscribers("pr1"))
+	_, ok := <-updates
+	assert.False(t, ok)
+	b.Publish(domain.PRUpdate{Type: domain.PRUpdateMerged, PullRequestID: "pr1"})
+}
+
+func TestBroker_DisconnectsSlowSubscriber(t *testing.T) {
+	b := NewBroker()
+	slow, cancel := b.Subscribe("pr1")
+	defer cancel()
+
+	for range subscriberBuffer + 1 {
+		b.Publish(domain.PRUpdate{Type: domain.PRUpdateReviewerAssigned, PullRequestID: "pr1"})
+	}
+
+	assert.Equal(t, 0, b.Subscribers("pr1"))
+	received := 0
+	for range slow {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received)
+}
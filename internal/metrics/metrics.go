@@ -0,0 +1,142 @@
+// Package metrics описывает бизнес-метрики сервиса в формате Prometheus
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pr_service"
+
+// BusinessGauges - долгоживущие показатели по данным БД, их обновляет Sampler
+type BusinessGauges struct {
+	OpenPRs                 prometheus.Gauge
+	OpenPRsWithoutReviewers prometheus.Gauge
+	InactiveUsers           prometheus.Gauge
+	TeamOpenPRs             *prometheus.GaugeVec
+}
+
+func NewBusinessGauges(reg prometheus.Registerer) *BusinessGauges {
+	g := &BusinessGauges{
+		OpenPRs: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "open_pull_requests",
+			Help:      "Number of OPEN pull requests.",
+		}),
+		OpenPRsWithoutReviewers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "open_pull_requests_without_reviewers",
+			Help:      "Number of OPEN pull requests with no assigned reviewers.",
+		}),
+		InactiveUsers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "inactive_users",
+			Help:      "Number of users with is_active = false.",
+		}),
+		TeamOpenPRs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "team_open_pull_requests",
+			Help:      "Number of OPEN pull requests by author team.",
+		}, []string{"team"}),
+	}
+
+	reg.MustRegister(g.OpenPRs, g.OpenPRsWithoutReviewers, g.InactiveUsers, g.TeamOpenPRs)
+	return g
+}
+
+// NotificationCounters - счётчики доставки уведомлений, их увеличивает notify.Dispatcher
+type NotificationCounters struct {
+	Dropped prometheus.Counter
+	Failed  prometheus.Counter
+}
+
+func NewNotificationCounters(reg prometheus.Registerer) *NotificationCounters {
+	c := &NotificationCounters{
+		Dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notifications_dropped_total",
+			Help:      "Number of PR notifications dropped because the delivery queue was full.",
+		}),
+		Failed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notifications_failed_total",
+			Help:      "Number of PR notifications the sink failed to deliver.",
+		}),
+	}
+
+	reg.MustRegister(c.Dropped, c.Failed)
+	return c
+}
+
+// TeamWebhookCounters - недоставленные на webhook команд события по командам, их увеличивает notify.TeamWebhookSink
+type TeamWebhookCounters struct {
+	Failed *prometheus.CounterVec
+}
+
+func NewTeamWebhookCounters(reg prometheus.Registerer) *TeamWebhookCounters {
+	c := &TeamWebhookCounters{
+		Failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "team_webhook_failures_total",
+			Help:      "Number of PR events that could not be delivered to a team webhook after all retries.",
+		}, []string{"team"}),
+	}
+
+	reg.MustRegister(c.Failed)
+	return c
+}
+
+// LoadShedMetrics - запросы API в обработке и отклонённые из-за MAX_IN_FLIGHT, их обновляет middleware.LoadShed
+type LoadShedMetrics struct {
+	InFlight prometheus.Gauge
+	Shed     prometheus.Counter
+}
+
+func NewLoadShedMetrics(reg prometheus.Registerer) *LoadShedMetrics {
+	m := &LoadShedMetrics{
+		InFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_in_flight_requests",
+			Help:      "Number of API requests currently being handled.",
+		}),
+		Shed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_shed_requests_total",
+			Help:      "Number of API requests rejected with OVERLOADED because MAX_IN_FLIGHT was reached.",
+		}),
+	}
+
+	reg.MustRegister(m.InFlight, m.Shed)
+	return m
+}
+
+// ShadowCounters - совпадения и расхождения shadow-стратегии выбора ревьюеров с активной,
+// доля совпадений - agreed / (agreed + disagreed)
+type ShadowCounters struct {
+	Agreed    prometheus.Counter
+	Disagreed prometheus.Counter
+}
+
+func NewShadowCounters(reg prometheus.Registerer) *ShadowCounters {
+	c := &ShadowCounters{
+		Agreed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reviewer_shadow_agreed_total",
+			Help:      "Number of new PRs where the shadow strategy chose the same reviewers as the active one.",
+		}),
+		Disagreed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reviewer_shadow_disagreed_total",
+			Help:      "Number of new PRs where the shadow strategy chose different reviewers.",
+		}),
+	}
+
+	reg.MustRegister(c.Agreed, c.Disagreed)
+	return c
+}
+
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
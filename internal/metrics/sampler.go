@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/jobs"
+)
+
+const SamplerJobName = "business_metrics"
+
+type PullRequestStats interface {
+	GetOpenPRStatsByTeam(ctx context.Context) ([]domain.TeamOpenPRStats, error)
+}
+
+type UserStats interface {
+	CountInactive(ctx context.Context) (int, error)
+}
+
+// Sampler периодически пересчитывает BusinessGauges агрегирующими запросами
+type Sampler struct {
+	prStats   PullRequestStats
+	userStats UserStats
+	gauges    *BusinessGauges
+	lg        *slog.Logger
+}
+
+func NewSampler(prStats PullRequestStats, userStats UserStats, gauges *BusinessGauges, lg *slog.Logger) *Sampler {
+	return &Sampler{
+		prStats:   prStats,
+		userStats: userStats,
+		gauges:    gauges,
+		lg:        lg,
+	}
+}
+
+// Job запускает сэмплер на каждой реплике, иначе метрики обновлялись бы только у лидера
+func (s *Sampler) Job(interval time.Duration) jobs.Job {
+	return jobs.Job{
+		Name:     SamplerJobName,
+		Interval: interval,
+		Timeout:  interval,
+		Local:    true,
+		Run:      s.Sample,
+	}
+}
+
+// Sample не трогает метрики, если хотя бы один запрос упал, чтобы не выставлять нули вместо значений
+func (s *Sampler) Sample(ctx context.Context) error {
+	teamStats, err := s.prStats.GetOpenPRStatsByTeam(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sample open PR stats: %w", err)
+	}
+
+	inactive, err := s.userStats.CountInactive(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sample inactive users: %w", err)
+	}
+
+	var open, withoutReviewers int
+	// команды без открытых PR пропадают из выборки, их серии тоже должны исчезнуть
+	s.gauges.TeamOpenPRs.Reset()
+	for _, st := range teamStats {
+		open += st.Open
+		withoutReviewers += st.WithoutReviewers
+		s.gauges.TeamOpenPRs.WithLabelValues(st.TeamName).Set(float64(st.Open))
+	}
+
+	s.gauges.OpenPRs.Set(float64(open))
+	s.gauges.OpenPRsWithoutReviewers.Set(float64(withoutReviewers))
+	s.gauges.InactiveUsers.Set(float64(inactive))
+
+	s.lg.Debug("business metrics sampled",
+		slog.Int("open_prs", open),
+		slog.Int("teams", len(teamStats)),
+		slog.Int("inactive_users", inactive))
+	return nil
+}
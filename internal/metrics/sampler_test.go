@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+)
+
+// memoryStats подменяет агрегирующие запросы репозиториев
+type memoryStats struct {
+	teams    []domain.TeamOpenPRStats
+	inactive int
+	err      error
+}
+
+func (m *memoryStats) GetOpenPRStatsByTeam(context.Context) ([]domain.TeamOpenPRStats, error) {
+	return m.teams, m.err
+}
+
+func (m *memoryStats) CountInactive(context.Context) (int, error) {
+	return m.inactive, m.err
+}
+
+func newTestSampler(stats *memoryStats) (*Sampler, *prometheus.Registry) {
+	reg := prometheus.NewRegistry()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	return NewSampler(stats, stats, NewBusinessGauges(reg), logger), reg
+}
+
+func TestSampler_Sample(t *testing.T) {
+	stats := &memoryStats{
+		teams: []domain.TeamOpenPRStats{
+			{TeamName: "backend", Open: 3, WithoutReviewers: 1},
+			{TeamName: "frontend", Open: 2},
+		},
+		inactive: 4,
+	}
+	sampler, reg := newTestSampler(stats)
+
+	require.NoError(t, sampler.Sample(context.Background()))
+
+	expected := `
+# HELP pr_service_open_pull_requests Number of OPEN pull requests.
+# TYPE pr_service_open_pull_requests gauge
+pr_service_open_pull_requests 5
+# HELP pr_service_open_pull_requests_without_reviewers Number of OPEN pull requests with no assigned reviewers.
+# TYPE pr_service_open_pull_requests_without_reviewers gauge
+pr_service_open_pull_requests_without_reviewers 1
+# HELP pr_service_inactive_users Number of users with is_active = false.
+# TYPE pr_service_inactive_users gauge
+pr_service_inactive_users 4
+# HELP pr_service_team_open_pull_requests Number of OPEN pull requests by author team.
+# TYPE pr_service_team_open_pull_requests gauge
+pr_service_team_open_pull_requests{team="backend"} 3
+pr_service_team_open_pull_requests{team="frontend"} 2
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected)))
+
+	t.Run("team without open PRs disappears", func(t *testing.T) {
+		stats.teams = stats.teams[:1]
+		require.NoError(t, sampler.Sample(context.Background()))
+
+		assert.Equal(t, 1, testutil.CollectAndCount(sampler.gauges.TeamOpenPRs))
+		assert.Equal(t, float64(3), testutil.ToFloat64(sampler.gauges.OpenPRs))
+	})
+
+	t.Run("query error keeps previous values", func(t *testing.T) {
+		stats.err = errors.New("db down")
+
+		require.Error(t, sampler.Sample(context.Background()))
+		assert.Equal(t, float64(3), testutil.ToFloat64(sampler.gauges.OpenPRs))
+		assert.Equal(t, float64(4), testutil.ToFloat64(sampler.gauges.InactiveUsers))
+	})
+}
+
+func TestSampler_Job(t *testing.T) {
+	sampler, _ := newTestSampler(&memoryStats{})
+
+	job := sampler.Job(30 * time.Second)
+
+	assert.Equal(t, SamplerJobName, job.Name)
+	assert.True(t, job.Local)
+	assert.NoError(t, job.Run(context.Background()))
+}
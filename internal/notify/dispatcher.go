@@ -0,0 +1,165 @@
+// Package notify доставляет события PR во внешние системы в фоне, не задерживая API
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"avito_backend_task/internal/domain"
+)
+
+const (
+	defaultQueueSize = 1000
+	defaultTimeout   = 5 * time.Second
+)
+
+// Sink доставляет одно событие, например webhook
+type Sink interface {
+	Deliver(ctx context.Context, event domain.PREvent) error
+}
+
+type Option func(*Dispatcher)
+
+// WithQueueSize задаёт ёмкость очереди, при переполнении новые события отбрасываются
+func WithQueueSize(n int) Option {
+	return func(d *Dispatcher) {
+		if n > 0 {
+			d.queueSize = n
+		}
+	}
+}
+
+// WithTimeout ограничивает одну доставку, чтобы зависший получатель не держал очередь
+func WithTimeout(timeout time.Duration) Option {
+	return func(d *Dispatcher) {
+		if timeout > 0 {
+			d.timeout = timeout
+		}
+	}
+}
+
+// WithCounters считает отброшенные и недоставленные события, без опции счётчики никуда не экспортируются
+func WithCounters(dropped, failed prometheus.Counter) Option {
+	return func(d *Dispatcher) {
+		d.dropped = dropped
+		d.failed = failed
+	}
+}
+
+// Dispatcher принимает события в ограниченную очередь и доставляет их одним фоновым воркером.
+// Publish никогда не блокирует и не возвращает ошибку: сбой доставки не должен превращать
+// успешный запрос клиента в ошибку
+type Dispatcher struct {
+	sink      Sink
+	lg        *slog.Logger
+	queueSize int
+	timeout   time.Duration
+	dropped   prometheus.Counter
+	failed    prometheus.Counter
+	queue     chan domain.PREvent
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewDispatcher(sink Sink, lg *slog.Logger, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		sink:      sink,
+		lg:        lg,
+		queueSize: defaultQueueSize,
+		timeout:   defaultTimeout,
+		dropped:   prometheus.NewCounter(prometheus.CounterOpts{Name: "notifications_dropped_total"}),
+		failed:    prometheus.NewCounter(prometheus.CounterOpts{Name: "notifications_failed_total"}),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.queue = make(chan domain.PREvent, d.queueSize)
+
+	return d
+}
+
+// Publish ставит событие в очередь, а если она заполнена - логирует и отбрасывает его
+func (d *Dispatcher) Publish(event domain.PREvent) {
+	select {
+	case d.queue <- event:
+	default:
+		d.dropped.Inc()
+		d.lg.Warn("notification queue is full, dropping event",
+			slog.String("event", string(event.Type)),
+			slog.String("pr_id", event.PullRequestID))
+	}
+}
+
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cancel != nil {
+		return
+	}
+
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.done = make(chan struct{})
+	go d.run(ctx)
+
+	d.lg.Info("notification dispatcher started", slog.Int("queue_size", d.queueSize))
+}
+
+// Shutdown останавливает воркер и ждёт текущую доставку. События, оставшиеся в очереди, теряются
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+		d.lg.Info("notification dispatcher stopped", slog.Int("undelivered", len(d.queue)))
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	defer close(d.done)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-d.queue:
+			if err := d.deliver(ctx, event); err != nil {
+				d.failed.Inc()
+				d.lg.Warn("failed to deliver notification",
+					slog.String("event", string(event.Type)),
+					slog.String("pr_id", event.PullRequestID),
+					slog.Any("error", err))
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, event domain.PREvent) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("sink panicked: %v", r)
+		}
+	}()
+
+	return d.sink.Deliver(ctx, event)
+}
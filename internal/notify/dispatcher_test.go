@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+)
+
+type sinkFunc func(ctx context.Context, event domain.PREvent) error
+
+func (f sinkFunc) Deliver(ctx context.Context, event domain.PREvent) error {
+	return f(ctx, event)
+}
+
+func newTestDispatcher(t *testing.T, sink Sink, opts ...Option) (*Dispatcher, prometheus.Counter, prometheus.Counter) {
+	t.Helper()
+
+	dropped := prometheus.NewCounter(prometheus.CounterOpts{Name: "dropped"})
+	failed := prometheus.NewCounter(prometheus.CounterOpts{Name: "failed"})
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	d := NewDispatcher(sink, logger, append(opts, WithCounters(dropped, failed))...)
+	d.Start(context.Background())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, d.Shutdown(ctx))
+	})
+	return d, dropped, failed
+}
+
+func TestDispatcher_DropsWhenQueueFull(t *testing.T) {
+	received := make(chan struct{}, 1)
+	sink := sinkFunc(func(ctx context.Context, _ domain.PREvent) error {
+		received <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	d, dropped, _ := newTestDispatcher(t, sink, WithQueueSize(1), WithTimeout(time.Minute))
+
+	d.Publish(domain.PREvent{Type: domain.PREventCreated, PullRequestID: "pr1"})
+	<-received
+
+	start := time.Now()
+	// pr2 ждёт в очереди, pr3 отбрасывается, пока воркер висит на pr1
+	d.Publish(domain.PREvent{Type: domain.PREventCreated, PullRequestID: "pr2"})
+	d.Publish(domain.PREvent{Type: domain.PREventCreated, PullRequestID: "pr3"})
+
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, 1.0, testutil.ToFloat64(dropped))
+}
+
+func TestDispatcher_CountsFailures(t *testing.T) {
+	sink := sinkFunc(func(context.Context, domain.PREvent) error {
+		return errors.New("connection refused")
+	})
+	d, _, failed := newTestDispatcher(t, sink)
+
+	d.Publish(domain.PREvent{Type: domain.PREventMerged, PullRequestID: "pr1"})
+
+	require.Eventually(t, func() bool { return testutil.ToFloat64(failed) == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestDispatcher_SinkPanicIsFailure(t *testing.T) {
+	sink := sinkFunc(func(context.Context, domain.PREvent) error {
+		panic("boom")
+	})
+	d, _, failed := newTestDispatcher(t, sink)
+
+	d.Publish(domain.PREvent{Type: domain.PREventMerged, PullRequestID: "pr1"})
+
+	require.Eventually(t, func() bool { return testutil.ToFloat64(failed) == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestWebhookSink_Deliver(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		if payload["pull_request_id"] == "broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, server.Client())
+	at := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+
+	err := sink.Deliver(context.Background(), domain.PREvent{
+		Type: domain.PREventCreated, PullRequestID: "pr1", AuthorID: "u1", OccurredAt: at,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"event": "PR_CREATED", "pull_request_id": "pr1", "author_id": "u1",
+		"reviewers": []any{}, "occurred_at": "2025-11-03T12:00:00Z",
+	}, payload)
+
+	err = sink.Deliver(context.Background(), domain.PREvent{Type: domain.PREventCreated, PullRequestID: "broken"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 500")
+}
+
+type publisherFunc func(event domain.PREvent)
+
+func (f publisherFunc) Publish(event domain.PREvent) {
+	f(event)
+}
+
+type usersFunc func(ctx context.Context, userIDs []string) ([]domain.User, error)
+
+func (f usersFunc) GetByIDs(ctx context.Context, userIDs []string) ([]domain.User, error) {
+	return f(ctx, userIDs)
+}
+
+func TestNotifier_PublishesReviewerEvents(t *testing.T) {
+	var events []domain.PREvent
+	notifier := NewNotifier(publisherFunc(func(event domain.PREvent) { events = append(events, event) }))
+	pr := domain.PullRequest{PullRequestID: "pr1", PullRequestName: "Add search", AuthorID: "u1"}
+
+	notifier.NotifyAssignment(pr, []string{"u2", "u3"})
+	notifier.NotifyReassignment(pr, "u2", "u4")
+	notifier.NotifyNoCandidate(pr, "u3")
+
+	require.Len(t, events, 3)
+	for i := range events {
+		assert.False(t, events[i].OccurredAt.IsZero())
+		events[i].OccurredAt = time.Time{}
+	}
+	assert.Equal(t, []domain.PREvent{
+		{Type: domain.PREventReviewersAssigned, PullRequestID: "pr1", PullRequestName: "Add search", AuthorID: "u1", Reviewers: []string{"u2", "u3"}},
+		{Type: domain.PREventReviewerReassigned, PullRequestID: "pr1", PullRequestName: "Add search", AuthorID: "u1", OldReviewerID: "u2", NewReviewerID: "u4"},
+		{Type: domain.PREventNoCandidate, PullRequestID: "pr1", PullRequestName: "Add search", AuthorID: "u1", OldReviewerID: "u3"},
+	}, events)
+}
+
+func TestChannels_DeliversToEveryChannel(t *testing.T) {
+	var delivered []string
+	ok := sinkFunc(func(context.Context, domain.PREvent) error {
+		delivered = append(delivered, "ok")
+		return nil
+	})
+	broken := sinkFunc(func(context.Context, domain.PREvent) error {
+		delivered = append(delivered, "broken")
+		return errors.New("slack unavailable")
+	})
+
+	err := Channels(broken, ok).Deliver(context.Background(), domain.PREvent{Type: domain.PREventNoCandidate})
+
+	require.ErrorContains(t, err, "slack unavailable")
+	assert.Equal(t, []string{"broken", "ok"}, delivered)
+}
+
+func TestSlackSink_Deliver(t *testing.T) {
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		texts = append(texts, payload["text"])
+	}))
+	defer server.Close()
+
+	// u9 нет в базе, в сообщении остаётся его user_id
+	users := usersFunc(func(_ context.Context, userIDs []string) ([]domain.User, error) {
+		known := map[string]string{"u1": "alice", "u2": "bob", "u3": "carol"}
+		var found []domain.User
+		for _, id := range userIDs {
+			if name, ok := known[id]; ok {
+				found = append(found, domain.User{UserID: id, Username: name})
+			}
+		}
+		return found, nil
+	})
+	sink := NewSlackSink(server.URL, server.Client(), users)
+	base := domain.PREvent{PullRequestID: "pr1", PullRequestName: "Add search", AuthorID: "u1"}
+
+	for _, event := range []domain.PREvent{
+		{Type: domain.PREventReviewersAssigned, Reviewers: []string{"u2", "u9"}},
+		{Type: domain.PREventReviewerReassigned, OldReviewerID: "u2", NewReviewerID: "u3"},
+		{Type: domain.PREventNoCandidate, OldReviewerID: "u3"},
+		{Type: domain.PREventNoCandidate},
+	} {
+		event.PullRequestID, event.PullRequestName, event.AuthorID = base.PullRequestID, base.PullRequestName, base.AuthorID
+		require.NoError(t, sink.Deliver(context.Background(), event))
+	}
+
+	assert.Equal(t, []string{
+		"*Add search* (pr1) by alice: review requested from bob, u9",
+		"*Add search* (pr1) by alice: reviewer bob replaced by carol",
+		"*Add search* (pr1) by alice: reviewer carol removed, no replacement available",
+		"*Add search* (pr1) by alice: no reviewers available",
+	}, texts)
+
+	failing := NewSlackSink(server.URL, server.Client(), usersFunc(func(context.Context, []string) ([]domain.User, error) {
+		return nil, errors.New("db error")
+	}))
+	err := failing.Deliver(context.Background(), base)
+	require.ErrorContains(t, err, "failed to look up usernames")
+}
@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"avito_backend_task/internal/domain"
+)
+
+// Publisher - очередь доставки событий, например Dispatcher
+type Publisher interface {
+	Publish(event domain.PREvent)
+}
+
+// Publishers ставит событие во все очереди, например в общую и в очередь webhook команд
+func Publishers(publishers ...Publisher) Publisher {
+	return fanout(publishers)
+}
+
+type fanout []Publisher
+
+func (f fanout) Publish(event domain.PREvent) {
+	for _, p := range f {
+		p.Publish(event)
+	}
+}
+
+// Notifier превращает уведомления сервисов о ревьюерах в события и ставит их в очередь.
+// Сервисы вызывают его после коммита, доставка по каналам идёт в фоне
+type Notifier struct {
+	publisher Publisher
+}
+
+func NewNotifier(publisher Publisher) *Notifier {
+	return &Notifier{publisher: publisher}
+}
+
+func (n *Notifier) NotifyAssignment(pr domain.PullRequest, reviewerIDs []string) {
+	n.publish(domain.PREventReviewersAssigned, pr, func(e *domain.PREvent) {
+		e.Reviewers = reviewerIDs
+	})
+}
+
+func (n *Notifier) NotifyReassignment(pr domain.PullRequest, oldReviewerID, newReviewerID string) {
+	n.publish(domain.PREventReviewerReassigned, pr, func(e *domain.PREvent) {
+		e.OldReviewerID = oldReviewerID
+		e.NewReviewerID = newReviewerID
+	})
+}
+
+// NotifyNoCandidate - ревьюера reviewerID сняли без замены, пустой reviewerID - новому PR некого назначить
+func (n *Notifier) NotifyNoCandidate(pr domain.PullRequest, reviewerID string) {
+	n.publish(domain.PREventNoCandidate, pr, func(e *domain.PREvent) {
+		e.OldReviewerID = reviewerID
+	})
+}
+
+func (n *Notifier) publish(eventType domain.PREventType, pr domain.PullRequest, fill func(*domain.PREvent)) {
+	event := domain.PREvent{
+		Type:            eventType,
+		PullRequestID:   pr.PullRequestID,
+		PullRequestName: pr.PullRequestName,
+		AuthorID:        pr.AuthorID,
+		OccurredAt:      time.Now(),
+	}
+	fill(&event)
+	n.publisher.Publish(event)
+}
+
+// Channels доставляет событие во все каналы по очереди. Сбой одного канала не мешает остальным,
+// ошибки всех каналов возвращаются вместе
+func Channels(sinks ...Sink) Sink {
+	return channels(sinks)
+}
+
+type channels []Sink
+
+func (c channels) Deliver(ctx context.Context, event domain.PREvent) error {
+	var errs []error
+	for _, sink := range c {
+		if err := sink.Deliver(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LogSink пишет уведомления в структурированный лог, канал по умолчанию
+type LogSink struct {
+	lg *slog.Logger
+}
+
+func NewLogSink(lg *slog.Logger) *LogSink {
+	return &LogSink{lg: lg}
+}
+
+func (s *LogSink) Deliver(ctx context.Context, event domain.PREvent) error {
+	s.lg.InfoContext(ctx, "reviewer notification",
+		slog.String("event", string(event.Type)),
+		slog.String("pr_id", event.PullRequestID),
+		slog.String("pr_name", event.PullRequestName),
+		slog.String("author_id", event.AuthorID),
+		slog.Any("reviewers", event.Reviewers),
+		slog.String("old_reviewer_id", event.OldReviewerID),
+		slog.String("new_reviewer_id", event.NewReviewerID))
+	return nil
+}
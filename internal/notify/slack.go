@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"avito_backend_task/internal/domain"
+)
+
+// UserLookup загружает пользователей, чтобы подставить в сообщение username вместо user_id
+type UserLookup interface {
+	GetByIDs(ctx context.Context, userIDs []string) ([]domain.User, error)
+}
+
+// slackPayload - тело запроса Slack incoming webhook
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// SlackSink отправляет уведомление в Slack incoming webhook читаемым сообщением
+type SlackSink struct {
+	url    string
+	client *http.Client
+	users  UserLookup
+}
+
+func NewSlackSink(url string, client *http.Client, users UserLookup) *SlackSink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &SlackSink{url: url, client: client, users: users}
+}
+
+func (s *SlackSink) Deliver(ctx context.Context, event domain.PREvent) error {
+	names, err := s.usernames(ctx, event)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, s.client, s.url, slackPayload{Text: slackText(event, names)})
+}
+
+// usernames - username всех участников события, неизвестные пользователи остаются под своим user_id
+func (s *SlackSink) usernames(ctx context.Context, event domain.PREvent) (map[string]string, error) {
+	var ids []string
+	for _, id := range append([]string{event.AuthorID, event.OldReviewerID, event.NewReviewerID}, event.Reviewers...) {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	users, err := s.users.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up usernames: %w", err)
+	}
+
+	names := make(map[string]string, len(ids))
+	for _, id := range ids {
+		names[id] = id
+	}
+	for _, user := range users {
+		if user.Username != "" {
+			names[user.UserID] = user.Username
+		}
+	}
+	return names, nil
+}
+
+func slackText(event domain.PREvent, names map[string]string) string {
+	title := fmt.Sprintf("*%s* (%s) by %s", event.PullRequestName, event.PullRequestID, names[event.AuthorID])
+
+	switch event.Type {
+	case domain.PREventReviewersAssigned:
+		reviewers := make([]string, len(event.Reviewers))
+		for i, id := range event.Reviewers {
+			reviewers[i] = names[id]
+		}
+		return fmt.Sprintf("%s: review requested from %s", title, strings.Join(reviewers, ", "))
+	case domain.PREventReviewerReassigned:
+		return fmt.Sprintf("%s: reviewer %s replaced by %s", title, names[event.OldReviewerID], names[event.NewReviewerID])
+	case domain.PREventNoCandidate:
+		if event.OldReviewerID != "" {
+			return fmt.Sprintf("%s: reviewer %s removed, no replacement available", title, names[event.OldReviewerID])
+		}
+		return fmt.Sprintf("%s: no reviewers available", title)
+	default:
+		return fmt.Sprintf("%s: %s", title, event.Type)
+	}
+}
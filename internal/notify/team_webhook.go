@@ -0,0 +1,190 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"avito_backend_task/internal/domain"
+)
+
+const (
+	// SignatureHeader - подпись тела запроса: sha256=<hex HMAC-SHA256 с секретом webhook команды>
+	SignatureHeader = "X-Signature-256"
+	// EventHeader - тип события, тот же, что event в теле
+	EventHeader = "X-Webhook-Event"
+
+	// recordTimeout ограничивает сохранение итога доставки, когда время самой доставки уже вышло
+	recordTimeout = 5 * time.Second
+)
+
+// TeamWebhookStore - webhook команды автора и сохранение итога доставок для /team/webhooks/status
+type TeamWebhookStore interface {
+	// GetAuthorTeamWebhook - включённый webhook команды автора, nil - доставлять некуда
+	GetAuthorTeamWebhook(ctx context.Context, authorID string) (*domain.TeamWebhook, error)
+	// RecordWebhookDelivery - пустой deliveryErr означает успешную доставку
+	RecordWebhookDelivery(ctx context.Context, teamName string, at time.Time, deliveryErr string) error
+}
+
+// RetryPolicy - до MaxAttempts попыток доставки, пауза перед повтором начинается с Backoff и удваивается
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+func (p RetryPolicy) attempts() int {
+	return max(p.MaxAttempts, 1)
+}
+
+// MaxDuration - сколько может занять доставка со всеми повторами, если одна попытка ограничена attemptTimeout.
+// Подходит для WithTimeout очереди webhook команд
+func (p RetryPolicy) MaxDuration(attemptTimeout time.Duration) time.Duration {
+	total := attemptTimeout * time.Duration(p.attempts())
+	backoff := p.Backoff
+	for range p.attempts() - 1 {
+		total += backoff
+		backoff *= 2
+	}
+	return total
+}
+
+// teamWebhookPayload - тело запроса на webhook команды
+type teamWebhookPayload struct {
+	Event           domain.PREventType `json:"event"`
+	TeamName        string             `json:"team_name"`
+	PullRequestID   string             `json:"pull_request_id"`
+	PullRequestName string             `json:"pull_request_name"`
+	AuthorID        string             `json:"author_id"`
+	Reviewers       []string           `json:"reviewers"`
+	OccurredAt      time.Time          `json:"occurred_at"`
+}
+
+// TeamWebhookSink доставляет PR_CREATED и REVIEWERS_ASSIGNED на webhook команды автора PR.
+// Остальные события и авторы без включённого webhook пропускаются. Сетевые ошибки, 429 и 5xx
+// повторяются по RetryPolicy, итог сохраняется в TeamWebhookStore
+type TeamWebhookSink struct {
+	store    TeamWebhookStore
+	client   *http.Client
+	retry    RetryPolicy
+	failures *prometheus.CounterVec
+}
+
+// NewTeamWebhookSink - failures с меткой team считает доставки, не удавшиеся после всех попыток
+func NewTeamWebhookSink(store TeamWebhookStore, client *http.Client, retry RetryPolicy, failures *prometheus.CounterVec) *TeamWebhookSink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if failures == nil {
+		failures = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "team_webhook_failures_total"}, []string{"team"})
+	}
+	return &TeamWebhookSink{store: store, client: client, retry: retry, failures: failures}
+}
+
+func (s *TeamWebhookSink) Deliver(ctx context.Context, event domain.PREvent) error {
+	if event.Type != domain.PREventCreated && event.Type != domain.PREventReviewersAssigned {
+		return nil
+	}
+
+	webhook, err := s.store.GetAuthorTeamWebhook(ctx, event.AuthorID)
+	if err != nil {
+		return fmt.Errorf("failed to look up team webhook: %w", err)
+	}
+	if webhook == nil {
+		return nil
+	}
+
+	reviewers := event.Reviewers
+	if reviewers == nil {
+		reviewers = []string{}
+	}
+	body, err := json.Marshal(teamWebhookPayload{
+		Event:           event.Type,
+		TeamName:        webhook.TeamName,
+		PullRequestID:   event.PullRequestID,
+		PullRequestName: event.PullRequestName,
+		AuthorID:        event.AuthorID,
+		Reviewers:       reviewers,
+		OccurredAt:      event.OccurredAt.UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode team webhook payload: %w", err)
+	}
+
+	deliveryErr := s.deliverWithRetry(ctx, webhook, event.Type, body)
+	if deliveryErr != nil {
+		s.failures.WithLabelValues(webhook.TeamName).Inc()
+		deliveryErr = fmt.Errorf("team %s: %w", webhook.TeamName, deliveryErr)
+	}
+
+	return errors.Join(deliveryErr, s.record(ctx, webhook.TeamName, deliveryErr))
+}
+
+func (s *TeamWebhookSink) deliverWithRetry(ctx context.Context, webhook *domain.TeamWebhook, eventType domain.PREventType, body []byte) error {
+	backoff := s.retry.Backoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := s.post(ctx, webhook, eventType, body)
+		if err == nil || !retryable || attempt >= s.retry.attempts() {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post отправляет одну попытку, retryable - имеет ли смысл повторить её
+func (s *TeamWebhookSink) post(ctx context.Context, webhook *domain.TeamWebhook, eventType domain.PREventType, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to build team webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(eventType))
+	req.Header.Set(SignatureHeader, sign(webhook.Secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("failed to send team webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("team webhook responded with status %d", resp.StatusCode)
+}
+
+func (s *TeamWebhookSink) record(ctx context.Context, teamName string, deliveryErr error) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	var message string
+	if deliveryErr != nil {
+		message = deliveryErr.Error()
+	}
+	if err := s.store.RecordWebhookDelivery(ctx, teamName, time.Now(), message); err != nil {
+		return fmt.Errorf("failed to record team webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// sign - значение SignatureHeader для тела body
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,165 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+)
+
+// fakeWebhookStore отдаёт webhook только автору a1 и запоминает итоги доставок
+type fakeWebhookStore struct {
+	webhook    domain.TeamWebhook
+	deliveries []string
+}
+
+func (s *fakeWebhookStore) GetAuthorTeamWebhook(_ context.Context, authorID string) (*domain.TeamWebhook, error) {
+	if authorID != "a1" {
+		return nil, nil
+	}
+	webhook := s.webhook
+	return &webhook, nil
+}
+
+func (s *fakeWebhookStore) RecordWebhookDelivery(_ context.Context, _ string, _ time.Time, deliveryErr string) error {
+	s.deliveries = append(s.deliveries, deliveryErr)
+	return nil
+}
+
+const testWebhookSecret = "s3cret-s3cret-s3cret"
+
+// signedReceiver проверяет подпись каждого запроса и отвечает статусами из statuses по очереди, затем 200
+func signedReceiver(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32, chan map[string]any) {
+	t.Helper()
+
+	var calls atomic.Int32
+	payloads := make(chan map[string]any, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		assert.True(t, hmac.Equal([]byte(expected), []byte(r.Header.Get(SignatureHeader))), "bad signature")
+
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, payload["event"], r.Header.Get(EventHeader))
+		payloads <- payload
+
+		if n := int(calls.Add(1)); n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls, payloads
+}
+
+func newTestTeamWebhookSink(url string, retry RetryPolicy) (*TeamWebhookSink, *fakeWebhookStore, *prometheus.CounterVec) {
+	store := &fakeWebhookStore{webhook: domain.TeamWebhook{TeamName: "backend", URL: url, Secret: testWebhookSecret, Enabled: true}}
+	failures := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "failures"}, []string{"team"})
+	return NewTeamWebhookSink(store, nil, retry, failures), store, failures
+}
+
+func TestTeamWebhookSink_DeliversSignedPayload(t *testing.T) {
+	server, _, payloads := signedReceiver(t)
+	sink, store, _ := newTestTeamWebhookSink(server.URL, RetryPolicy{MaxAttempts: 1})
+	at := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+
+	err := sink.Deliver(context.Background(), domain.PREvent{
+		Type: domain.PREventReviewersAssigned, PullRequestID: "pr1", PullRequestName: "Add search",
+		AuthorID: "a1", Reviewers: []string{"u2"}, OccurredAt: at,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"event": "REVIEWERS_ASSIGNED", "team_name": "backend", "pull_request_id": "pr1",
+		"pull_request_name": "Add search", "author_id": "a1", "reviewers": []any{"u2"},
+		"occurred_at": "2025-11-03T12:00:00Z",
+	}, <-payloads)
+	assert.Equal(t, []string{""}, store.deliveries)
+}
+
+func TestTeamWebhookSink_SkipsOtherEventsAndTeams(t *testing.T) {
+	server, calls, _ := signedReceiver(t)
+	sink, store, _ := newTestTeamWebhookSink(server.URL, RetryPolicy{MaxAttempts: 1})
+
+	require.NoError(t, sink.Deliver(context.Background(), domain.PREvent{Type: domain.PREventMerged, AuthorID: "a1"}))
+	require.NoError(t, sink.Deliver(context.Background(), domain.PREvent{Type: domain.PREventCreated, AuthorID: "a2"}))
+
+	assert.Zero(t, calls.Load())
+	assert.Empty(t, store.deliveries)
+}
+
+func TestTeamWebhookSink_RetriesServerErrors(t *testing.T) {
+	server, calls, _ := signedReceiver(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+	sink, store, failures := newTestTeamWebhookSink(server.URL, RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	err := sink.Deliver(context.Background(), domain.PREvent{Type: domain.PREventCreated, PullRequestID: "pr1", AuthorID: "a1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, []string{""}, store.deliveries)
+	assert.Zero(t, testutil.ToFloat64(failures.WithLabelValues("backend")))
+}
+
+func TestTeamWebhookSink_CountsFailureAfterRetries(t *testing.T) {
+	t.Run("retries exhausted", func(t *testing.T) {
+		server, calls, _ := signedReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError)
+		sink, store, failures := newTestTeamWebhookSink(server.URL, RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond})
+
+		err := sink.Deliver(context.Background(), domain.PREvent{Type: domain.PREventCreated, PullRequestID: "pr1", AuthorID: "a1"})
+
+		require.ErrorContains(t, err, "status 500")
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Equal(t, []string{"team backend: team webhook responded with status 500"}, store.deliveries)
+		assert.Equal(t, 1.0, testutil.ToFloat64(failures.WithLabelValues("backend")))
+	})
+
+	t.Run("client error is not retried", func(t *testing.T) {
+		server, calls, _ := signedReceiver(t, http.StatusGone)
+		sink, _, failures := newTestTeamWebhookSink(server.URL, RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+		err := sink.Deliver(context.Background(), domain.PREvent{Type: domain.PREventCreated, PullRequestID: "pr1", AuthorID: "a1"})
+
+		require.ErrorContains(t, err, "status 410")
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Equal(t, 1.0, testutil.ToFloat64(failures.WithLabelValues("backend")))
+	})
+}
+
+func TestRetryPolicy_MaxDuration(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: time.Second}
+
+	// три попытки по 5s и паузы 1s и 2s между ними
+	assert.Equal(t, 18*time.Second, policy.MaxDuration(5*time.Second))
+	assert.Equal(t, 5*time.Second, RetryPolicy{}.MaxDuration(5*time.Second))
+}
+
+func TestPublishers_PublishesToEveryQueue(t *testing.T) {
+	var first, second []string
+	publisher := Publishers(
+		publisherFunc(func(event domain.PREvent) { first = append(first, event.PullRequestID) }),
+		publisherFunc(func(event domain.PREvent) { second = append(second, event.PullRequestID) }),
+	)
+
+	publisher.Publish(domain.PREvent{PullRequestID: "pr1"})
+
+	assert.Equal(t, []string{"pr1"}, first)
+	assert.Equal(t, []string{"pr1"}, second)
+}
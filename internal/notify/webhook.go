@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"avito_backend_task/internal/domain"
+)
+
+// webhookPayload - тело POST-запроса webhook
+type webhookPayload struct {
+	Event         domain.PREventType `json:"event"`
+	PullRequestID string             `json:"pull_request_id"`
+	AuthorID      string             `json:"author_id"`
+	Reviewers     []string           `json:"reviewers"`
+	OccurredAt    time.Time          `json:"occurred_at"`
+}
+
+// WebhookSink отправляет событие JSON-ом на URL, любой ответ кроме 2xx считается ошибкой доставки
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, client *http.Client) *WebhookSink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &WebhookSink{url: url, client: client}
+}
+
+func (s *WebhookSink) Deliver(ctx context.Context, event domain.PREvent) error {
+	reviewers := event.Reviewers
+	if reviewers == nil {
+		reviewers = []string{}
+	}
+
+	return postJSON(ctx, s.client, s.url, webhookPayload{
+		Event:         event.Type,
+		PullRequestID: event.PullRequestID,
+		AuthorID:      event.AuthorID,
+		Reviewers:     reviewers,
+		OccurredAt:    event.OccurredAt.UTC(),
+	})
+}
+
+// postJSON отправляет payload POST-запросом, любой ответ кроме 2xx считается ошибкой доставки
+func postJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
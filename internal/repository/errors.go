@@ -2,17 +2,54 @@ package repository
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"avito_backend_task/internal/domain"
+)
+
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+	readOnlyTransactionCode = "25006"
 )
 
 var (
 	ErrNotFound = errors.New("not found")
+	// ErrNotOpen изменение отклонено, так как PR не в статусе OPEN
+	ErrNotOpen = errors.New("pull request is not open")
+	// ErrInactiveReviewer назначение отклонено проверкой активности ревьюера в БД
+	ErrInactiveReviewer = errors.New("reviewer is not active")
+	// ErrTooManyExcluded список исключений кандидатов длиннее maxExcludedUserIDs
+	ErrTooManyExcluded = errors.New("too many excluded user ids")
+	// ErrAlreadyExists запись отклонена ограничением уникальности
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrReferenceNotFound запись ссылается на удалённую строку, например ревьюер удалён конкурентно
+	ErrReferenceNotFound = errors.New("referenced row not found")
+	// ErrReadOnly запись в read-only БД, например в standby. Совпадает с domain.ErrReadOnly,
+	// поэтому доходит до ответа 503 без перевода в каждом сервисе
+	ErrReadOnly = domain.ErrReadOnly
 )
 
+// HandleDBError переводит ошибки драйвера в типизированные ошибки репозитория,
+// имя нарушенного ограничения сохраняется в тексте
 func HandleDBError(err error) error {
 	if errors.Is(err, pgx.ErrNoRows) {
 		return ErrNotFound
 	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case uniqueViolationCode:
+			return fmt.Errorf("%w: %s", ErrAlreadyExists, pgErr.ConstraintName)
+		case foreignKeyViolationCode:
+			return fmt.Errorf("%w: %s", ErrReferenceNotFound, pgErr.ConstraintName)
+		case readOnlyTransactionCode:
+			return fmt.Errorf("%w: %s", ErrReadOnly, pgErr.Message)
+		}
+	}
 	return err
 }
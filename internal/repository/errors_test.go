@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+
+	"avito_backend_task/internal/domain"
+)
+
+func TestHandleDBError(t *testing.T) {
+	driverErr := errors.New("conn closed")
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "no rows", err: pgx.ErrNoRows, want: ErrNotFound},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505", ConstraintName: "teams_pkey"}, want: ErrAlreadyExists},
+		{name: "foreign key violation", err: &pgconn.PgError{Code: "23503"}, want: ErrReferenceNotFound},
+		{
+			name: "read-only transaction",
+			err:  fmt.Errorf("failed to insert: %w", &pgconn.PgError{Code: "25006", Message: "cannot execute INSERT in a read-only transaction"}),
+			want: domain.ErrReadOnly,
+		},
+		{name: "other error", err: driverErr, want: driverErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, HandleDBError(tt.err), tt.want)
+		})
+	}
+}
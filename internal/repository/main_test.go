@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"avito_backend_task/pkg/db"
+)
+
+// интеграционные тесты репозиториев запускаются только при заданном TEST_DATABASE_URL,
+// каждый тест получает отдельную схему с применёнными миграциями
+func setupTestDB(t *testing.T) (*db.DB, *pgxpool.Pool) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+
+	admin, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		admin.Close()
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("failed to parse dsn: %v", err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema
+
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	t.Cleanup(func() {
+		pool.Close()
+		_, _ = admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+		admin.Close()
+	})
+
+	applyMigrations(t, pool)
+
+	return db.NewDB(pool), pool
+}
+
+func applyMigrations(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join("..", "..", "migrations", "*.up.sql"))
+	if err != nil {
+		t.Fatalf("failed to list migrations: %v", err)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		sql, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+		if _, err := pool.Exec(context.Background(), string(sql)); err != nil {
+			t.Fatalf("failed to apply %s: %v", filepath.Base(file), err)
+		}
+	}
+}
+
+func mustExec(t *testing.T, pool *pgxpool.Pool, sql string, args ...any) {
+	t.Helper()
+
+	if _, err := pool.Exec(context.Background(), sql, args...); err != nil {
+		t.Fatalf("failed to exec %q: %v", strings.TrimSpace(sql), err)
+	}
+}
+
+// seedTeam создаёт команду и активных участников
+func seedTeam(t *testing.T, pool *pgxpool.Pool, teamName string, userIDs ...string) {
+	t.Helper()
+
+	mustExec(t, pool, "INSERT INTO teams (team_name) VALUES ($1)", teamName)
+	for _, userID := range userIDs {
+		mustExec(t, pool, `
+			INSERT INTO users (user_id, username, team_name, is_active)
+			VALUES ($1, $1, $2, TRUE)
+		`, userID, teamName)
+	}
+}
+
+func seedPR(t *testing.T, pool *pgxpool.Pool, prID, authorID string, createdAt time.Time) {
+	t.Helper()
+
+	mustExec(t, pool, `
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at)
+		VALUES ($1, $1, $2, 'OPEN', $3)
+	`, prID, authorID, createdAt)
+}
@@ -5,16 +5,34 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+
 	"avito_backend_task/internal/domain"
 	"avito_backend_task/pkg/db"
 )
 
 type PullRequestRepository struct {
-	db *db.DB
+	db            *db.DB
+	requireActive bool
+}
+
+type PullRequestRepositoryOption func(*PullRequestRepository)
+
+// WithActiveReviewerCheck включает проверку is_active прямо в запросе назначения ревьюера
+// как последний рубеж на случай, если сервис её пропустил
+func WithActiveReviewerCheck() PullRequestRepositoryOption {
+	return func(r *PullRequestRepository) {
+		r.requireActive = true
+	}
 }
 
-func NewPullRequestRepository(db *db.DB) *PullRequestRepository {
-	return &PullRequestRepository{db: db}
+func NewPullRequestRepository(db *db.DB, opts ...PullRequestRepositoryOption) *PullRequestRepository {
+	r := &PullRequestRepository{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
 func (r *PullRequestRepository) CreatePullRequest(ctx context.Context, pr domain.PullRequestCreate) (time.Time, error) {
@@ -22,12 +40,13 @@ func (r *PullRequestRepository) CreatePullRequest(ctx context.Context, pr domain
 
 	var createdAt time.Time
 	err := conn.QueryRow(ctx, `
-		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, priority, pending_assignment, group_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
 		RETURNING created_at
-	`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, domain.PRStatusOpen).Scan(&createdAt)
+	`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, domain.PRStatusOpen, pr.Priority.OrDefault(),
+		pr.PendingAssignment, pr.GroupID).Scan(&createdAt)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to insert PR: %w", err)
+		return time.Time{}, fmt.Errorf("failed to insert PR: %w", HandleDBError(err))
 	}
 
 	return createdAt, nil
@@ -43,17 +62,62 @@ func (r *PullRequestRepository) Exists(ctx context.Context, prID string) (bool,
 	return exists, nil
 }
 
-func (r *PullRequestRepository) AssignReviewer(ctx context.Context, prID, reviewerID string) error {
+// AssignReviewer возвращает ErrNotOpen, если PR не существует или не в статусе OPEN,
+// и ErrInactiveReviewer, если включена проверка активности и ревьюер неактивен.
+// Вставка идёт в отдельном savepoint: ErrAlreadyExists или ErrReferenceNotFound откатывают только её,
+// и вызывающий может продолжить транзакцию с другим ревьюером. source сохраняется в назначении и его событии
+func (r *PullRequestRepository) AssignReviewer(ctx context.Context, prID, reviewerID string, source domain.AssignmentSource) error {
 	conn := r.db.Conn(ctx)
 
-	_, err := conn.Exec(ctx, `
-		INSERT INTO pr_reviewers (pull_request_id, user_id)
-		VALUES ($1, $2)
-	`, prID, reviewerID)
+	savepoint, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin savepoint: %w", err)
+	}
+	defer func() { _ = savepoint.Rollback(ctx) }()
+
+	tag, err := savepoint.Exec(ctx, `
+		WITH assigned AS (
+			INSERT INTO pr_reviewers (pull_request_id, user_id, assignment_source)
+			SELECT pull_request_id, $2, $6
+			FROM pull_requests
+			WHERE pull_request_id = $1 AND status = $4
+			  AND (NOT $5 OR EXISTS (SELECT 1 FROM users WHERE user_id = $2 AND is_active))
+			RETURNING pull_request_id, user_id, assignment_source
+		)
+		INSERT INTO pr_reviewer_events (pull_request_id, user_id, event_type, assignment_source)
+		SELECT pull_request_id, user_id, $3, assignment_source FROM assigned
+	`, prID, reviewerID, domain.ReviewerEventAssigned, domain.PRStatusOpen, r.requireActive, source)
+	if err != nil {
+		return fmt.Errorf("failed to assign reviewer %s: %w", reviewerID, HandleDBError(err))
+	}
+	if err := savepoint.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	if r.requireActive && r.ensureOpen(ctx, prID) == nil {
+		return ErrInactiveReviewer
+	}
+	return ErrNotOpen
+}
+
+const pullRequestColumns = `pull_request_id, pull_request_name, author_id, status, priority, COALESCE(group_id, ''),
+	reassignment_count, orphaned, pending_assignment, merged_reviewer_count, merged_understaffed,
+	COALESCE(merged_by, ''), created_at, merged_at`
+
+func scanPullRequest(row pgx.Row, pr *domain.PullRequest) error {
+	var status, priority string
+	err := row.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &status, &priority, &pr.GroupID,
+		&pr.ReassignmentCount, &pr.Orphaned, &pr.PendingAssignment, &pr.MergedReviewerCount, &pr.MergedUnderstaffed,
+		&pr.MergedBy, &pr.CreatedAt, &pr.MergedAt)
 	if err != nil {
-		return fmt.Errorf("failed to assign reviewer %s: %w", reviewerID, err)
+		return err
 	}
 
+	pr.Status = domain.PRStatus(status)
+	pr.Priority = domain.PRPriority(priority)
 	return nil
 }
 
@@ -61,18 +125,162 @@ func (r *PullRequestRepository) GetPullRequestByID(ctx context.Context, prID str
 	conn := r.db.Conn(ctx)
 
 	var pr domain.PullRequest
-	var status string
-	err := conn.QueryRow(ctx, `
-		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
-		FROM pull_requests
+	row := conn.QueryRow(ctx, `SELECT `+pullRequestColumns+` FROM pull_requests WHERE pull_request_id = $1`, prID)
+	if err := scanPullRequest(row, &pr); err != nil {
+		return nil, HandleDBError(err)
+	}
+
+	reviewers, err := r.getReviewers(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	pr.Reviewers = reviewers
+	for _, reviewer := range reviewers {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, reviewer.UserID)
+	}
+	return &pr, nil
+}
+
+// GetPullRequestsByIDs загружает PR с ревьюерами двумя запросами на весь список. PR идут в порядке prIDs,
+// повторы схлопываются, неизвестные ID пропускаются
+func (r *PullRequestRepository) GetPullRequestsByIDs(ctx context.Context, prIDs []string) ([]domain.PullRequest, error) {
+	conn := r.db.Conn(ctx)
+	prIDs = dedupeIDs(prIDs)
+
+	rows, err := conn.Query(ctx, `SELECT `+pullRequestColumns+` FROM pull_requests WHERE pull_request_id = ANY($1)`, prIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query PRs: %w", err)
+	}
+	defer rows.Close()
+
+	found := make(map[string]*domain.PullRequest, len(prIDs))
+	for rows.Next() {
+		var pr domain.PullRequest
+		if err := scanPullRequest(rows, &pr); err != nil {
+			return nil, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		found[pr.PullRequestID] = &pr
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	reviewerRows, err := conn.Query(ctx, `
+		SELECT pull_request_id, user_id, assignment_source
+		FROM pr_reviewers
+		WHERE pull_request_id = ANY($1)
+		ORDER BY pull_request_id, assigned_at, user_id
+	`, prIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviewers: %w", err)
+	}
+	defer reviewerRows.Close()
+
+	for reviewerRows.Next() {
+		var prID, source string
+		var reviewer domain.PRReviewer
+		if err := reviewerRows.Scan(&prID, &reviewer.UserID, &source); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer: %w", err)
+		}
+		reviewer.Source = domain.AssignmentSource(source)
+		if pr, ok := found[prID]; ok {
+			pr.Reviewers = append(pr.Reviewers, reviewer)
+			pr.AssignedReviewers = append(pr.AssignedReviewers, reviewer.UserID)
+		}
+	}
+	if err := reviewerRows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	prs := make([]domain.PullRequest, 0, len(found))
+	for _, id := range prIDs {
+		if pr, ok := found[id]; ok {
+			prs = append(prs, *pr)
+		}
+	}
+	return prs, nil
+}
+
+func (r *PullRequestRepository) getReviewers(ctx context.Context, prID string) ([]domain.PRReviewer, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT user_id, assignment_source
+		FROM pr_reviewers
 		WHERE pull_request_id = $1
-	`, prID).Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &status, &pr.CreatedAt, &pr.MergedAt)
+		ORDER BY assigned_at, user_id
+	`, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	var reviewers []domain.PRReviewer
+	for rows.Next() {
+		var reviewer domain.PRReviewer
+		var source string
+		if err := rows.Scan(&reviewer.UserID, &source); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer: %w", err)
+		}
+		reviewer.Source = domain.AssignmentSource(source)
+		reviewers = append(reviewers, reviewer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return reviewers, nil
+}
+
+// GetOpenGroupAuthors - авторы других открытых PR группы groupID, кроме PR prID
+func (r *PullRequestRepository) GetOpenGroupAuthors(ctx context.Context, groupID, prID string) ([]string, error) {
+	conn := r.db.Conn(ctx)
 
+	rows, err := conn.Query(ctx, `
+		SELECT DISTINCT author_id
+		FROM pull_requests
+		WHERE group_id = $1 AND status = $2 AND pull_request_id <> $3
+		ORDER BY author_id
+	`, groupID, domain.PRStatusOpen, prID)
 	if err != nil {
-		return nil, HandleDBError(err)
+		return nil, fmt.Errorf("failed to query group authors: %w", err)
 	}
+	defer rows.Close()
 
-	pr.Status = domain.PRStatus(status)
+	authors, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan group authors: %w", err)
+	}
+	return authors, nil
+}
+
+// GetReviewerRemovalsSince - время снятий ревьюеров PR строго после since, по возрастанию
+func (r *PullRequestRepository) GetReviewerRemovalsSince(ctx context.Context, prID string, since time.Time) ([]time.Time, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT created_at
+		FROM pr_reviewer_events
+		WHERE pull_request_id = $1 AND event_type = 'REMOVED' AND created_at > $2
+		ORDER BY created_at
+	`, prID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviewer removals: %w", err)
+	}
+	defer rows.Close()
+
+	removals, err := pgx.CollectRows(rows, pgx.RowTo[time.Time])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan reviewer removals: %w", err)
+	}
+	return removals, nil
+}
+
+// GetReviewerIDs не проверяет существование PR: для несуществующего вернётся пустой список
+func (r *PullRequestRepository) GetReviewerIDs(ctx context.Context, prID string) ([]string, error) {
+	conn := r.db.Conn(ctx)
 
 	rows, err := conn.Query(ctx, `
 		SELECT user_id
@@ -97,72 +305,335 @@ func (r *PullRequestRepository) GetPullRequestByID(ctx context.Context, prID str
 		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
-	pr.AssignedReviewers = reviewers
-	return &pr, nil
+	return reviewers, nil
 }
 
-func (r *PullRequestRepository) MergePullRequest(ctx context.Context, prID string) error {
+// IncrementReassignmentCount атомарно увеличивает счётчик замен ревьюеров PR
+func (r *PullRequestRepository) IncrementReassignmentCount(ctx context.Context, prID string) error {
+	conn := r.db.Conn(ctx)
+
+	tag, err := conn.Exec(ctx, `
+		UPDATE pull_requests
+		SET reassignment_count = reassignment_count + 1
+		WHERE pull_request_id = $1
+	`, prID)
+	if err != nil {
+		return fmt.Errorf("failed to increment reassignment count: %w", HandleDBError(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// LockPullRequest сериализует изменения одного PR до конца транзакции
+func (r *PullRequestRepository) LockPullRequest(ctx context.Context, prID string) error {
+	if err := r.db.AdvisoryXactLock(ctx, "pr:"+prID); err != nil {
+		return fmt.Errorf("failed to lock PR: %w", err)
+	}
+	return nil
+}
+
+// MergePullRequest возвращает false, если PR уже не в статусе OPEN (смержен или закрыт).
+// Вместе со статусом сохраняется число ревьюеров и флаг merged_understaffed, если их меньше desiredReviewers;
+// mergedBy сохраняется, пустое значение - NULL
+func (r *PullRequestRepository) MergePullRequest(ctx context.Context, prID string, desiredReviewers int, mergedBy string) (bool, error) {
 	conn := r.db.Conn(ctx)
 	now := time.Now()
 
-	_, err := conn.Exec(ctx, `
+	tag, err := conn.Exec(ctx, `
+		WITH reviewers AS (
+			SELECT COUNT(*)::int AS n FROM pr_reviewers WHERE pull_request_id = $3
+		)
+		UPDATE pull_requests
+		SET status = $1, merged_at = $2, merged_by = NULLIF($6, ''),
+		    merged_reviewer_count = reviewers.n, merged_understaffed = reviewers.n < $5
+		FROM reviewers
+		WHERE pull_request_id = $3 AND status = $4
+	`, domain.PRStatusMerged, now, prID, domain.PRStatusOpen, desiredReviewers, mergedBy)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to update PR status: %w", HandleDBError(err))
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// RenamePullRequest возвращает ErrNotOpen, если PR не существует или не в статусе OPEN
+func (r *PullRequestRepository) RenamePullRequest(ctx context.Context, prID, name string) error {
+	conn := r.db.Conn(ctx)
+
+	tag, err := conn.Exec(ctx, `
+		UPDATE pull_requests
+		SET pull_request_name = $1
+		WHERE pull_request_id = $2 AND status = $3
+	`, name, prID, domain.PRStatusOpen)
+	if err != nil {
+		return fmt.Errorf("failed to rename PR: %w", HandleDBError(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotOpen
+	}
+
+	return nil
+}
+
+// RemoveReviewer снимает ревьюера и сообщает, была ли удалена строка: false без ошибки - ревьюер не назначен.
+// Возвращает ErrNotOpen, если PR не в статусе OPEN
+func (r *PullRequestRepository) RemoveReviewer(ctx context.Context, prID, reviewerID string) (bool, error) {
+	conn := r.db.Conn(ctx)
+
+	tag, err := conn.Exec(ctx, `
+		WITH removed AS (
+			DELETE FROM pr_reviewers rv
+			USING pull_requests pr
+			WHERE rv.pull_request_id = $1 AND rv.user_id = $2
+			  AND pr.pull_request_id = rv.pull_request_id AND pr.status = $4
+			RETURNING rv.pull_request_id, rv.user_id
+		)
+		INSERT INTO pr_reviewer_events (pull_request_id, user_id, event_type)
+		SELECT pull_request_id, user_id, $3 FROM removed
+	`, prID, reviewerID, domain.ReviewerEventRemoved, domain.PRStatusOpen)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete reviewer: %w", HandleDBError(err))
+	}
+	if tag.RowsAffected() > 0 {
+		return true, nil
+	}
+
+	// ничего не удалено: либо ревьюер не назначен, либо PR закрыт
+	return false, r.ensureOpen(ctx, prID)
+}
+
+// MarkOrphanedPullRequests помечает открытые PR автора как оставшиеся без владельца и возвращает их id
+func (r *PullRequestRepository) MarkOrphanedPullRequests(ctx context.Context, authorID string) ([]string, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
 		UPDATE pull_requests
-		SET status = $1, merged_at = $2
-		WHERE pull_request_id = $3
-	`, domain.PRStatusMerged, now, prID)
+		SET orphaned = TRUE
+		WHERE author_id = $1 AND status = $2
+		RETURNING pull_request_id
+	`, authorID, domain.PRStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark orphaned PRs: %w", HandleDBError(err))
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan orphaned PR: %w", HandleDBError(err))
+	}
+	return ids, nil
+}
+
+// CloseOrphanedPullRequests закрывает открытые PR автора и снимает с них ревьюеров с записью событий
+func (r *PullRequestRepository) CloseOrphanedPullRequests(ctx context.Context, authorID string) ([]string, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		WITH closed AS (
+			UPDATE pull_requests
+			SET status = $2, orphaned = TRUE
+			WHERE author_id = $1 AND status = $3
+			RETURNING pull_request_id
+		), removed AS (
+			DELETE FROM pr_reviewers rv
+			USING closed
+			WHERE rv.pull_request_id = closed.pull_request_id
+			RETURNING rv.pull_request_id, rv.user_id
+		), events AS (
+			INSERT INTO pr_reviewer_events (pull_request_id, user_id, event_type)
+			SELECT pull_request_id, user_id, $4 FROM removed
+		)
+		SELECT pull_request_id FROM closed
+	`, authorID, domain.PRStatusClosed, domain.PRStatusOpen, domain.ReviewerEventRemoved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close orphaned PRs: %w", HandleDBError(err))
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan closed PR: %w", HandleDBError(err))
+	}
+	return ids, nil
+}
+
+// GetPendingAssignmentPRs - открытые PR, ожидающие автоназначения ревьюеров после blackout
+func (r *PullRequestRepository) GetPendingAssignmentPRs(ctx context.Context) ([]string, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT pull_request_id
+		FROM pull_requests
+		WHERE pending_assignment AND status = $1
+		ORDER BY created_at
+	`, domain.PRStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending PRs: %w", err)
+	}
 
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
 	if err != nil {
-		return fmt.Errorf("failed to update PR status: %w", err)
+		return nil, fmt.Errorf("failed to scan pending PR: %w", err)
 	}
+	return ids, nil
+}
 
+// MarkPendingAssignment ставит PR в очередь AssignPendingReviewers
+func (r *PullRequestRepository) MarkPendingAssignment(ctx context.Context, prID string) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		UPDATE pull_requests SET pending_assignment = TRUE WHERE pull_request_id = $1
+	`, prID)
+	if err != nil {
+		return fmt.Errorf("failed to mark pending assignment: %w", HandleDBError(err))
+	}
 	return nil
 }
 
-func (r *PullRequestRepository) RemoveReviewer(ctx context.Context, prID, reviewerID string) error {
+func (r *PullRequestRepository) ClearPendingAssignment(ctx context.Context, prID string) error {
 	conn := r.db.Conn(ctx)
 
 	_, err := conn.Exec(ctx, `
-		DELETE FROM pr_reviewers
-		WHERE pull_request_id = $1 AND user_id = $2
-	`, prID, reviewerID)
+		UPDATE pull_requests SET pending_assignment = FALSE WHERE pull_request_id = $1
+	`, prID)
+	if err != nil {
+		return fmt.Errorf("failed to clear pending assignment: %w", HandleDBError(err))
+	}
+	return nil
+}
+
+// ReplaceReviewers заменяет состав ревьюеров PR на reviewerIDs и пишет события о снятых и добавленных.
+// Добавленные получают источник source, оставшиеся сохраняют прежний.
+// Статус PR и пользователей не проверяет: это задача вызывающего
+func (r *PullRequestRepository) ReplaceReviewers(
+	ctx context.Context,
+	prID string,
+	reviewerIDs []string,
+	source domain.AssignmentSource,
+) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		WITH removed AS (
+			DELETE FROM pr_reviewers
+			WHERE pull_request_id = $1 AND NOT (user_id = ANY($2))
+			RETURNING pull_request_id, user_id
+		), added AS (
+			INSERT INTO pr_reviewers (pull_request_id, user_id, assignment_source)
+			SELECT $1, id, $5 FROM unnest($2::varchar[]) AS id
+			ON CONFLICT (pull_request_id, user_id) DO NOTHING
+			RETURNING pull_request_id, user_id, assignment_source
+		)
+		INSERT INTO pr_reviewer_events (pull_request_id, user_id, event_type, assignment_source)
+		SELECT pull_request_id, user_id, $3, NULL FROM removed
+		UNION ALL
+		SELECT pull_request_id, user_id, $4, assignment_source FROM added
+	`, prID, dedupeIDs(reviewerIDs), domain.ReviewerEventRemoved, domain.ReviewerEventAssigned, source)
+	if err != nil {
+		return fmt.Errorf("failed to replace reviewers: %w", HandleDBError(err))
+	}
+
+	return nil
+}
+
+func (r *PullRequestRepository) ensureOpen(ctx context.Context, prID string) error {
+	conn := r.db.Conn(ctx)
+
+	var status string
+	err := conn.QueryRow(ctx, "SELECT status FROM pull_requests WHERE pull_request_id = $1", prID).Scan(&status)
 	if err != nil {
-		return fmt.Errorf("failed to delete reviewer: %w", err)
+		return HandleDBError(err)
+	}
+	if domain.PRStatus(status) != domain.PRStatusOpen {
+		return ErrNotOpen
 	}
 
 	return nil
 }
 
-func (r *PullRequestRepository) GetPullRequestsByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
+func (r *PullRequestRepository) GetPullRequestsByReviewer(
+	ctx context.Context,
+	userID string,
+	filter domain.ReviewFilter,
+) ([]domain.PullRequestShort, error) {
+	var prs []domain.PullRequestShort
+	err := r.StreamPullRequestsByReviewer(ctx, userID, filter, func(pr domain.PullRequestShort) error {
+		prs = append(prs, pr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return prs, nil
+}
+
+// StreamPullRequestsByReviewer передаёт PR в fn по мере чтения строк; ошибка fn прерывает чтение и возвращается как есть.
+// Snooze учитывается только у открытых PR и только пока snoozed_until позже filter.Now
+func (r *PullRequestRepository) StreamPullRequestsByReviewer(
+	ctx context.Context,
+	userID string,
+	filter domain.ReviewFilter,
+	fn func(domain.PullRequestShort) error,
+) error {
 	conn := r.db.Conn(ctx)
 	rows, err := conn.Query(ctx, `
-		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.orphaned, s.snoozed_until
 		FROM pull_requests pr
 		INNER JOIN pr_reviewers r ON pr.pull_request_id = r.pull_request_id
+		LEFT JOIN pr_snoozes s ON s.pull_request_id = r.pull_request_id
+			AND s.user_id = r.user_id
+			AND pr.status = $2
+			AND s.snoozed_until > $3
 		WHERE r.user_id = $1
-	`, userID)
+		  AND ($4 = '' OR pr.status = $4)
+		  AND ($5 OR s.snoozed_until IS NULL)
+	`, userID, domain.PRStatusOpen, filter.Now, string(filter.Status), filter.IncludeSnoozed)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query PRs: %w", err)
+		return fmt.Errorf("failed to query PRs: %w", err)
 	}
 	defer rows.Close()
 
-	var prs []domain.PullRequestShort
 	for rows.Next() {
 		var pr domain.PullRequestShort
 		var status string
-		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &status); err != nil {
-			return nil, fmt.Errorf("failed to scan PR: %w", err)
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &status, &pr.Orphaned, &pr.SnoozedUntil); err != nil {
+			return fmt.Errorf("failed to scan PR: %w", err)
 		}
 		pr.Status = domain.PRStatus(status)
-		prs = append(prs, pr)
+		if err := fn(pr); err != nil {
+			return err
+		}
 	}
 
-	return prs, rows.Err()
+	return rows.Err()
+}
+
+// SnoozeReview откладывает ревью PR до until, повторный вызов переносит срок.
+// Ревьюер должен быть назначен, иначе внешний ключ на pr_reviewers вернёт ErrReferenceNotFound
+func (r *PullRequestRepository) SnoozeReview(ctx context.Context, prID, userID string, until time.Time) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		INSERT INTO pr_snoozes (pull_request_id, user_id, snoozed_until)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (pull_request_id, user_id) DO UPDATE
+		SET snoozed_until = EXCLUDED.snoozed_until, created_at = NOW()
+	`, prID, userID, until)
+	if err != nil {
+		return fmt.Errorf("failed to snooze review: %w", HandleDBError(err))
+	}
+
+	return nil
 }
 
 func (r *PullRequestRepository) GetOpenPullRequestsByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
 	conn := r.db.Conn(ctx)
 	rows, err := conn.Query(ctx, `
-		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.orphaned
 		FROM pull_requests pr
 		INNER JOIN pr_reviewers r ON pr.pull_request_id = r.pull_request_id
 		WHERE r.user_id = $1 AND pr.status = 'OPEN'
@@ -176,7 +647,7 @@ func (r *PullRequestRepository) GetOpenPullRequestsByReviewer(ctx context.Contex
 	for rows.Next() {
 		var pr domain.PullRequestShort
 		var status string
-		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &status); err != nil {
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &status, &pr.Orphaned); err != nil {
 			return nil, fmt.Errorf("failed to scan PR: %w", err)
 		}
 		pr.Status = domain.PRStatus(status)
@@ -186,14 +657,623 @@ func (r *PullRequestRepository) GetOpenPullRequestsByReviewer(ctx context.Contex
 	return prs, rows.Err()
 }
 
-func (r *PullRequestRepository) IsReviewerAssigned(ctx context.Context, prID, userID string) (bool, error) {
+// GetOpenAuthoredPullRequests - открытые PR автора, старые первыми
+func (r *PullRequestRepository) GetOpenAuthoredPullRequests(ctx context.Context, authorID string) ([]domain.OpenPullRequest, error) {
 	conn := r.db.Conn(ctx)
-	var exists bool
+	rows, err := conn.Query(ctx, `
+		SELECT pull_request_id, pull_request_name, author_id, status, orphaned, created_at
+		FROM pull_requests
+		WHERE author_id = $1 AND status = $2
+		ORDER BY created_at, pull_request_id
+	`, authorID, domain.PRStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query authored PRs: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []domain.OpenPullRequest
+	for rows.Next() {
+		var pr domain.OpenPullRequest
+		var status string
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &status, &pr.Orphaned, &pr.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		pr.Status = domain.PRStatus(status)
+		prs = append(prs, pr)
+	}
+
+	return prs, rows.Err()
+}
+
+// GetRecentPullRequests - limit последних созданных PR всех команд, новые первыми.
+// Порядок совпадает с индексом idx_pr_created_at, поэтому таблица не сортируется целиком
+func (r *PullRequestRepository) GetRecentPullRequests(ctx context.Context, limit int) ([]domain.RecentPullRequest, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, u.username, pr.status, pr.orphaned, pr.created_at,
+			(SELECT COUNT(*) FROM pr_reviewers r WHERE r.pull_request_id = pr.pull_request_id)
+		FROM pull_requests pr
+		INNER JOIN users u ON u.user_id = pr.author_id
+		ORDER BY pr.created_at DESC, pr.pull_request_id DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent PRs: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []domain.RecentPullRequest
+	for rows.Next() {
+		var pr domain.RecentPullRequest
+		var status string
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.AuthorName, &status,
+			&pr.Orphaned, &pr.CreatedAt, &pr.ReviewerCount); err != nil {
+			return nil, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		pr.Status = domain.PRStatus(status)
+		prs = append(prs, pr)
+	}
+
+	return prs, rows.Err()
+}
+
+// GetReviewerAnomalies собирает диагностическими запросами назначения, нарушающие инварианты автоназначения:
+// автор среди ревьюеров, ревьюер из другой команды и ревьюер, которого нет (или больше нет) в команде
+func (r *PullRequestRepository) GetReviewerAnomalies(ctx context.Context) (domain.AnomalyReport, error) {
+	var report domain.AnomalyReport
+	var err error
+
+	report.AuthorIsReviewer, err = r.queryAnomalies(ctx, `
+		SELECT r.pull_request_id, r.user_id, COALESCE(a.team_name, ''), COALESCE(a.team_name, '')
+		FROM pr_reviewers r
+		INNER JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+		LEFT JOIN users a ON a.user_id = pr.author_id
+		WHERE r.user_id = pr.author_id
+		ORDER BY r.pull_request_id, r.user_id
+	`)
+	if err != nil {
+		return domain.AnomalyReport{}, fmt.Errorf("failed to find authors among reviewers: %w", err)
+	}
+
+	report.CrossTeamReviewer, err = r.queryAnomalies(ctx, `
+		SELECT r.pull_request_id, r.user_id, a.team_name, u.team_name
+		FROM pr_reviewers r
+		INNER JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+		INNER JOIN users a ON a.user_id = pr.author_id
+		INNER JOIN users u ON u.user_id = r.user_id
+		WHERE u.team_name <> a.team_name
+		ORDER BY r.pull_request_id, r.user_id
+	`)
+	if err != nil {
+		return domain.AnomalyReport{}, fmt.Errorf("failed to find cross-team reviewers: %w", err)
+	}
+
+	// у смерженных PR удалённые из команды ревьюеры остаются в истории, это не ошибка данных
+	report.UnknownReviewer, err = r.queryAnomalies(ctx, `
+		SELECT r.pull_request_id, r.user_id, COALESCE(a.team_name, ''), COALESCE(u.team_name, '')
+		FROM pr_reviewers r
+		INNER JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+		LEFT JOIN users a ON a.user_id = pr.author_id
+		LEFT JOIN users u ON u.user_id = r.user_id
+		WHERE u.user_id IS NULL OR (u.removed_at IS NOT NULL AND pr.status = $1)
+		ORDER BY r.pull_request_id, r.user_id
+	`, domain.PRStatusOpen)
+	if err != nil {
+		return domain.AnomalyReport{}, fmt.Errorf("failed to find unknown reviewers: %w", err)
+	}
+
+	return report, nil
+}
+
+func (r *PullRequestRepository) queryAnomalies(ctx context.Context, sql string, args ...any) ([]domain.ReviewerAnomaly, error) {
+	rows, err := r.db.Conn(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	anomalies := []domain.ReviewerAnomaly{}
+	for rows.Next() {
+		var a domain.ReviewerAnomaly
+		if err := rows.Scan(&a.PullRequestID, &a.UserID, &a.AuthorTeam, &a.ReviewerTeam); err != nil {
+			return nil, err
+		}
+		anomalies = append(anomalies, a)
+	}
+
+	return anomalies, rows.Err()
+}
+
+// GetOpenReviewingPullRequests - открытые PR, где пользователь ревьюер, со временем назначения, ранние первыми
+func (r *PullRequestRepository) GetOpenReviewingPullRequests(ctx context.Context, userID string) ([]domain.OpenPullRequest, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.orphaned, pr.created_at, r.assigned_at
+		FROM pull_requests pr
+		INNER JOIN pr_reviewers r ON pr.pull_request_id = r.pull_request_id
+		WHERE r.user_id = $1 AND pr.status = $2
+		ORDER BY r.assigned_at, pr.pull_request_id
+	`, userID, domain.PRStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviewing PRs: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []domain.OpenPullRequest
+	for rows.Next() {
+		var pr domain.OpenPullRequest
+		var status string
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &status, &pr.Orphaned,
+			&pr.CreatedAt, &pr.AssignedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		pr.Status = domain.PRStatus(status)
+		prs = append(prs, pr)
+	}
+
+	return prs, rows.Err()
+}
+
+// GetInactiveReviewerAssignments ищет неактивных ревьюеров в открытых PR
+func (r *PullRequestRepository) GetInactiveReviewerAssignments(ctx context.Context) ([]domain.ReviewerAssignment, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT r.pull_request_id, r.user_id, u.team_name
+		FROM pr_reviewers r
+		INNER JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+		INNER JOIN users u ON u.user_id = r.user_id
+		WHERE pr.status = $1 AND u.is_active = false
+		ORDER BY r.pull_request_id, r.user_id
+	`, domain.PRStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inactive reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []domain.ReviewerAssignment
+	for rows.Next() {
+		var a domain.ReviewerAssignment
+		if err := rows.Scan(&a.PullRequestID, &a.UserID, &a.TeamName); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer assignment: %w", err)
+		}
+		assignments = append(assignments, a)
+	}
+
+	return assignments, rows.Err()
+}
+
+// GetTeamReviewAssignments возвращает ревьюеров открытых PR, авторы которых состоят в команде teamName
+func (r *PullRequestRepository) GetTeamReviewAssignments(ctx context.Context, teamName string) ([]domain.ReviewerAssignment, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT r.pull_request_id, r.user_id, a.team_name
+		FROM pr_reviewers r
+		INNER JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+		INNER JOIN users a ON a.user_id = pr.author_id
+		WHERE pr.status = $1 AND a.team_name = $2
+		ORDER BY r.pull_request_id, r.user_id
+	`, domain.PRStatusOpen, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team review assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []domain.ReviewerAssignment
+	for rows.Next() {
+		var a domain.ReviewerAssignment
+		if err := rows.Scan(&a.PullRequestID, &a.UserID, &a.TeamName); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer assignment: %w", err)
+		}
+		assignments = append(assignments, a)
+	}
+
+	return assignments, rows.Err()
+}
+
+// GetUnderReviewedTeamPRs - открытые PR авторов команды teamName, у которых меньше target ревьюеров,
+// кроме PR самого userID и PR, где он уже ревьюер. PR в очереди pending assignment не возвращаются.
+// Сначала PR с наименьшим числом ревьюеров, среди равных - более старые
+func (r *PullRequestRepository) GetUnderReviewedTeamPRs(
+	ctx context.Context,
+	teamName, userID string,
+	target int,
+) ([]domain.UnderReviewedPR, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT pr.pull_request_id, COALESCE(pr.group_id, ''), COUNT(r.user_id)
+		FROM pull_requests pr
+		INNER JOIN users a ON a.user_id = pr.author_id
+		LEFT JOIN pr_reviewers r ON r.pull_request_id = pr.pull_request_id
+		WHERE pr.status = $1 AND a.team_name = $2 AND pr.author_id <> $3 AND NOT pr.pending_assignment
+		  AND NOT EXISTS (SELECT 1 FROM pr_reviewers x WHERE x.pull_request_id = pr.pull_request_id AND x.user_id = $3)
+		GROUP BY pr.pull_request_id
+		HAVING COUNT(r.user_id) < $4
+		ORDER BY COUNT(r.user_id), pr.created_at, pr.pull_request_id
+	`, domain.PRStatusOpen, teamName, userID, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query under-reviewed PRs: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []domain.UnderReviewedPR
+	for rows.Next() {
+		var pr domain.UnderReviewedPR
+		if err := rows.Scan(&pr.PullRequestID, &pr.GroupID, &pr.ReviewerCount); err != nil {
+			return nil, fmt.Errorf("failed to scan under-reviewed PR: %w", err)
+		}
+		prs = append(prs, pr)
+	}
+
+	return prs, rows.Err()
+}
+
+// GetOpenReviewCounts возвращает число открытых PR на ревью у каждого пользователя, без ревью в карте нет
+func (r *PullRequestRepository) GetOpenReviewCounts(ctx context.Context, userIDs []string) (map[string]int, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT r.user_id, COUNT(*)
+		FROM pr_reviewers r
+		INNER JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+		WHERE pr.status = $1 AND r.user_id = ANY($2)
+		GROUP BY r.user_id
+	`, domain.PRStatusOpen, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open review counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int, len(userIDs))
+	for rows.Next() {
+		var userID string
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan open review count: %w", err)
+		}
+		counts[userID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// GetOpenPRStatsByTeam считает открытые PR по командам авторов одним запросом
+func (r *PullRequestRepository) GetOpenPRStatsByTeam(ctx context.Context) ([]domain.TeamOpenPRStats, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT u.team_name,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE NOT EXISTS (
+				SELECT 1 FROM pr_reviewers r WHERE r.pull_request_id = pr.pull_request_id
+			))
+		FROM pull_requests pr
+		INNER JOIN users u ON u.user_id = pr.author_id
+		WHERE pr.status = $1
+		GROUP BY u.team_name
+		ORDER BY u.team_name
+	`, domain.PRStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open PR stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []domain.TeamOpenPRStats
+	for rows.Next() {
+		var st domain.TeamOpenPRStats
+		if err := rows.Scan(&st.TeamName, &st.Open, &st.WithoutReviewers); err != nil {
+			return nil, fmt.Errorf("failed to scan open PR stats: %w", err)
+		}
+		stats = append(stats, st)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetReviewLatency - медиана и p90 (merged_at - assigned_at) по ревьюерам команды для PR,
+// смерженных в [from, to). Ревьюеры с числом PR меньше minSamples не попадают в результат
+func (r *PullRequestRepository) GetReviewLatency(
+	ctx context.Context,
+	teamName string,
+	from, to *time.Time,
+	minSamples int,
+) ([]domain.ReviewerLatency, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT pr_reviewers.user_id,
+		       COUNT(*),
+		       percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM pr.merged_at - pr_reviewers.assigned_at)),
+		       percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM pr.merged_at - pr_reviewers.assigned_at))
+		FROM pr_reviewers
+		JOIN pull_requests pr ON pr.pull_request_id = pr_reviewers.pull_request_id
+		JOIN users reviewer ON reviewer.user_id = pr_reviewers.user_id
+		WHERE reviewer.team_name = $1
+		  AND pr.status = $2
+		  AND pr.merged_at >= pr_reviewers.assigned_at
+		  AND ($3::timestamptz IS NULL OR pr.merged_at >= $3)
+		  AND ($4::timestamptz IS NULL OR pr.merged_at < $4)
+		GROUP BY pr_reviewers.user_id
+		HAVING COUNT(*) >= $5
+		ORDER BY pr_reviewers.user_id
+	`, teamName, domain.PRStatusMerged, from, to, minSamples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review latency: %w", err)
+	}
+	return scanLatencies(rows)
+}
+
+// GetReviewerLatencyByUsers - то же, что GetReviewLatency, для заданных ревьюеров любых команд и PR,
+// смерженных начиная с from
+func (r *PullRequestRepository) GetReviewerLatencyByUsers(
+	ctx context.Context,
+	userIDs []string,
+	from time.Time,
+	minSamples int,
+) ([]domain.ReviewerLatency, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT pr_reviewers.user_id,
+		       COUNT(*),
+		       percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM pr.merged_at - pr_reviewers.assigned_at)),
+		       percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM pr.merged_at - pr_reviewers.assigned_at))
+		FROM pr_reviewers
+		JOIN pull_requests pr ON pr.pull_request_id = pr_reviewers.pull_request_id
+		WHERE pr_reviewers.user_id = ANY($1)
+		  AND pr.status = $2
+		  AND pr.merged_at >= pr_reviewers.assigned_at
+		  AND pr.merged_at >= $3
+		GROUP BY pr_reviewers.user_id
+		HAVING COUNT(*) >= $4
+		ORDER BY pr_reviewers.user_id
+	`, userIDs, domain.PRStatusMerged, from, minSamples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query review latency: %w", err)
+	}
+	return scanLatencies(rows)
+}
+
+func scanLatencies(rows pgx.Rows) ([]domain.ReviewerLatency, error) {
+	defer rows.Close()
+
+	var latencies []domain.ReviewerLatency
+	for rows.Next() {
+		var latency domain.ReviewerLatency
+		var median, p90 float64
+		if err := rows.Scan(&latency.UserID, &latency.Samples, &median, &p90); err != nil {
+			return nil, fmt.Errorf("failed to scan review latency: %w", err)
+		}
+		latency.Median = time.Duration(median * float64(time.Second))
+		latency.P90 = time.Duration(p90 * float64(time.Second))
+		latencies = append(latencies, latency)
+	}
+
+	return latencies, rows.Err()
+}
+
+// GetReviewerQueues - ревьюеры PR в порядке назначения и для каждого число других его открытых PR,
+// назначенных раньше (при равном assigned_at раньше идёт меньший pull_request_id)
+func (r *PullRequestRepository) GetReviewerQueues(ctx context.Context, prID string) ([]domain.ReviewerQueuePosition, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT target.user_id,
+		       target.assigned_at,
+		       (SELECT COUNT(*)
+		        FROM pr_reviewers other
+		        JOIN pull_requests pr ON pr.pull_request_id = other.pull_request_id
+		        WHERE other.user_id = target.user_id
+		          AND pr.status = $2
+		          AND (other.assigned_at, other.pull_request_id) < (target.assigned_at, target.pull_request_id))
+		FROM pr_reviewers target
+		WHERE target.pull_request_id = $1
+		ORDER BY target.assigned_at, target.user_id
+	`, prID, domain.PRStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviewer queues: %w", err)
+	}
+	defer rows.Close()
+
+	var queues []domain.ReviewerQueuePosition
+	for rows.Next() {
+		var queue domain.ReviewerQueuePosition
+		if err := rows.Scan(&queue.UserID, &queue.AssignedAt, &queue.Ahead); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer queue: %w", err)
+		}
+		queues = append(queues, queue)
+	}
+
+	return queues, rows.Err()
+}
+
+// GetFormerReviews - все PR из истории назначений пользователя, включая снятые, по времени первого назначения
+func (r *PullRequestRepository) GetFormerReviews(ctx context.Context, userID string) ([]domain.FormerReview, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT pull_request_id,
+		       MIN(created_at) FILTER (WHERE event_type = 'ASSIGNED'),
+		       MAX(created_at) FILTER (WHERE event_type = 'REMOVED')
+		FROM pr_reviewer_events
+		WHERE user_id = $1
+		GROUP BY pull_request_id
+		ORDER BY MIN(created_at), pull_request_id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query former reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []domain.FormerReview
+	for rows.Next() {
+		var review domain.FormerReview
+		if err := rows.Scan(&review.PullRequestID, &review.FirstAssignedAt, &review.LastRemovedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan former review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
+
+// события в pr_reviewer_events пишут AssignReviewer и RemoveReviewer
+func (r *PullRequestRepository) GetUserReviewTimeline(
+	ctx context.Context,
+	userID string,
+	from, to *time.Time,
+	page domain.Page,
+) ([]domain.ReviewerEvent, error) {
+	var (
+		afterTime *time.Time
+		afterID   int64
+	)
+	if page.After != nil {
+		afterTime, afterID = &page.After.CreatedAt, page.After.ID
+	}
+
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT event_id, pull_request_id, user_id, event_type, COALESCE(assignment_source, ''), created_at
+		FROM pr_reviewer_events
+		WHERE user_id = $1
+		  AND ($2::timestamptz IS NULL OR created_at >= $2)
+		  AND ($3::timestamptz IS NULL OR created_at < $3)
+		  AND ($6::timestamptz IS NULL OR (created_at, event_id) > ($6, $7::bigint))
+		ORDER BY created_at, event_id
+		LIMIT $4 OFFSET $5
+	`, userID, from, to, page.Limit, page.Offset, afterTime, afterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviewer events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []domain.ReviewerEvent
+	for rows.Next() {
+		var event domain.ReviewerEvent
+		var eventType, source string
+		if err := rows.Scan(&event.EventID, &event.PullRequestID, &event.UserID, &eventType, &source, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer event: %w", err)
+		}
+		event.EventType = domain.ReviewerEventType(eventType)
+		event.Source = domain.AssignmentSource(source)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetReviewerEventsSince - назначения и снятия ревьюеров всех PR строго после since, по времени и event_id.
+// Непустой source оставляет только назначения с этим источником
+func (r *PullRequestRepository) GetReviewerEventsSince(
+	ctx context.Context,
+	since time.Time,
+	source domain.AssignmentSource,
+	limit int,
+) ([]domain.ReviewerEvent, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT event_id, pull_request_id, user_id, event_type, COALESCE(assignment_source, ''), created_at
+		FROM pr_reviewer_events
+		WHERE created_at > $1 AND ($3 = '' OR assignment_source = $3)
+		ORDER BY created_at, event_id
+		LIMIT $2
+	`, since, limit, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviewer events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []domain.ReviewerEvent
+	for rows.Next() {
+		var event domain.ReviewerEvent
+		var eventType, source string
+		if err := rows.Scan(&event.EventID, &event.PullRequestID, &event.UserID, &eventType, &source, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer event: %w", err)
+		}
+		event.EventType = domain.ReviewerEventType(eventType)
+		event.Source = domain.AssignmentSource(source)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// LockBackfillWatermark возвращает прогресс backfill name и блокирует его строку до конца транзакции,
+// поэтому параллельные запуски обрабатывают порции по очереди. Отсутствующий прогресс создаётся пустым
+func (r *PullRequestRepository) LockBackfillWatermark(ctx context.Context, name string) (domain.BackfillWatermark, error) {
+	conn := r.db.Conn(ctx)
+	if _, err := conn.Exec(ctx, `
+		INSERT INTO backfill_watermarks (name) VALUES ($1)
+		ON CONFLICT (name) DO NOTHING
+	`, name); err != nil {
+		return domain.BackfillWatermark{}, fmt.Errorf("failed to init backfill watermark: %w", HandleDBError(err))
+	}
+
+	var watermark domain.BackfillWatermark
 	err := conn.QueryRow(ctx, `
-		SELECT EXISTS(
-			SELECT 1 FROM pr_reviewers
-			WHERE pull_request_id = $1 AND user_id = $2
+		SELECT last_pull_request_id, last_user_id
+		FROM backfill_watermarks
+		WHERE name = $1
+		FOR UPDATE
+	`, name).Scan(&watermark.PullRequestID, &watermark.UserID)
+	if err != nil {
+		return domain.BackfillWatermark{}, fmt.Errorf("failed to lock backfill watermark: %w", err)
+	}
+
+	return watermark, nil
+}
+
+func (r *PullRequestRepository) SaveBackfillWatermark(ctx context.Context, name string, watermark domain.BackfillWatermark) error {
+	conn := r.db.Conn(ctx)
+	_, err := conn.Exec(ctx, `
+		INSERT INTO backfill_watermarks (name, last_pull_request_id, last_user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE
+		SET last_pull_request_id = EXCLUDED.last_pull_request_id,
+		    last_user_id = EXCLUDED.last_user_id,
+		    updated_at = NOW()
+	`, name, watermark.PullRequestID, watermark.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to save backfill watermark: %w", HandleDBError(err))
+	}
+	return nil
+}
+
+// BackfillAssignedEvents создаёт события ASSIGNED для limit строк pr_reviewers после after
+// с временем и источником самого назначения. Строки, у которых уже есть событие ASSIGNED
+// этого ревьюера в этом PR, пропускаются, поэтому повторный проход ничего не дублирует
+func (r *PullRequestRepository) BackfillAssignedEvents(
+	ctx context.Context,
+	after domain.BackfillWatermark,
+	limit int,
+) (domain.BackfillBatch, error) {
+	var batch domain.BackfillBatch
+
+	conn := r.db.Conn(ctx)
+	err := conn.QueryRow(ctx, `
+		WITH batch AS (
+			SELECT pull_request_id, user_id, assigned_at, assignment_source
+			FROM pr_reviewers
+			WHERE (pull_request_id, user_id) > ($1, $2)
+			ORDER BY pull_request_id, user_id
+			LIMIT $3
+		), inserted AS (
+			INSERT INTO pr_reviewer_events (pull_request_id, user_id, event_type, assignment_source, created_at)
+			SELECT b.pull_request_id, b.user_id, $4, b.assignment_source, b.assigned_at
+			FROM batch b
+			WHERE NOT EXISTS (
+				SELECT 1 FROM pr_reviewer_events e
+				WHERE e.pull_request_id = b.pull_request_id AND e.user_id = b.user_id AND e.event_type = $4
+			)
+			RETURNING 1
+		), last AS (
+			SELECT pull_request_id, user_id FROM batch
+			ORDER BY pull_request_id DESC, user_id DESC
+			LIMIT 1
 		)
-	`, prID, userID).Scan(&exists)
-	return exists, err
+		SELECT (SELECT COUNT(*) FROM batch),
+		       (SELECT COUNT(*) FROM inserted),
+		       COALESCE((SELECT pull_request_id FROM last), $1),
+		       COALESCE((SELECT user_id FROM last), $2)
+	`, after.PullRequestID, after.UserID, limit, domain.ReviewerEventAssigned).
+		Scan(&batch.Scanned, &batch.Inserted, &batch.Last.PullRequestID, &batch.Last.UserID)
+	if err != nil {
+		return domain.BackfillBatch{}, fmt.Errorf("failed to backfill assigned events: %w", HandleDBError(err))
+	}
+
+	return batch, nil
 }
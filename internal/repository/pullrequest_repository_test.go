@@ -0,0 +1,1308 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/pkg/clock"
+	"avito_backend_task/pkg/db"
+)
+
+func TestPullRequestRepository_GetUserReviewTimeline(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	base := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	seedTeam(t, pool, "backend", "author", "reviewer", "other")
+	seedPR(t, pool, "pr1", "author", base)
+	seedPR(t, pool, "pr2", "author", base)
+
+	events := []struct {
+		prID      string
+		userID    string
+		eventType domain.ReviewerEventType
+		at        time.Time
+	}{
+		{"pr2", "reviewer", domain.ReviewerEventAssigned, base.Add(3 * time.Hour)},
+		{"pr1", "reviewer", domain.ReviewerEventAssigned, base.Add(1 * time.Hour)},
+		{"pr1", "other", domain.ReviewerEventAssigned, base.Add(2 * time.Hour)},
+		{"pr1", "reviewer", domain.ReviewerEventRemoved, base.Add(2 * time.Hour)},
+		{"pr2", "reviewer", domain.ReviewerEventRemoved, base.Add(48 * time.Hour)},
+	}
+	for _, e := range events {
+		mustExec(t, pool, `
+			INSERT INTO pr_reviewer_events (pull_request_id, user_id, event_type, created_at)
+			VALUES ($1, $2, $3, $4)
+		`, e.prID, e.userID, e.eventType, e.at)
+	}
+
+	t.Run("chronological order", func(t *testing.T) {
+		got, err := repo.GetUserReviewTimeline(ctx, "reviewer", nil, nil, domain.Page{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, got, 4)
+
+		assert.Equal(t, "pr1", got[0].PullRequestID)
+		assert.Equal(t, domain.ReviewerEventAssigned, got[0].EventType)
+		assert.Equal(t, "pr1", got[1].PullRequestID)
+		assert.Equal(t, domain.ReviewerEventRemoved, got[1].EventType)
+		assert.Equal(t, "pr2", got[2].PullRequestID)
+		assert.Equal(t, domain.ReviewerEventAssigned, got[2].EventType)
+		assert.Equal(t, "pr2", got[3].PullRequestID)
+		assert.Equal(t, domain.ReviewerEventRemoved, got[3].EventType)
+	})
+
+	t.Run("range filter", func(t *testing.T) {
+		from := base.Add(2 * time.Hour)
+		to := base.Add(24 * time.Hour)
+
+		got, err := repo.GetUserReviewTimeline(ctx, "reviewer", &from, &to, domain.Page{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, domain.ReviewerEventRemoved, got[0].EventType)
+		assert.Equal(t, "pr2", got[1].PullRequestID)
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		got, err := repo.GetUserReviewTimeline(ctx, "reviewer", nil, nil, domain.Page{Limit: 2, Offset: 2})
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, "pr2", got[0].PullRequestID)
+	})
+
+	t.Run("assign and remove record events", func(t *testing.T) {
+		require.NoError(t, repo.AssignReviewer(ctx, "pr2", "other", domain.AssignmentSourceAuto))
+		removed, err := repo.RemoveReviewer(ctx, "pr2", "other")
+		require.NoError(t, err)
+		require.True(t, removed)
+
+		got, err := repo.GetUserReviewTimeline(ctx, "other", &base, nil, domain.Page{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+		assert.Equal(t, domain.ReviewerEventAssigned, got[1].EventType)
+		assert.Equal(t, domain.ReviewerEventRemoved, got[2].EventType)
+	})
+}
+
+func TestPullRequestRepository_GetReviewerEventsSince(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	base := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	seedTeam(t, pool, "backend", "author", "reviewer", "other")
+	seedPR(t, pool, "pr1", "author", base)
+	seedPR(t, pool, "pr2", "author", base)
+
+	events := []struct {
+		prID      string
+		userID    string
+		eventType domain.ReviewerEventType
+		at        time.Time
+	}{
+		{"pr1", "reviewer", domain.ReviewerEventAssigned, base},
+		{"pr2", "other", domain.ReviewerEventAssigned, base.Add(time.Hour)},
+		{"pr1", "reviewer", domain.ReviewerEventRemoved, base.Add(2 * time.Hour)},
+		{"pr1", "other", domain.ReviewerEventAssigned, base.Add(2 * time.Hour)},
+	}
+	for _, e := range events {
+		mustExec(t, pool, `
+			INSERT INTO pr_reviewer_events (pull_request_id, user_id, event_type, created_at)
+			VALUES ($1, $2, $3, $4)
+		`, e.prID, e.userID, e.eventType, e.at)
+	}
+
+	t.Run("strictly after since across users", func(t *testing.T) {
+		got, err := repo.GetReviewerEventsSince(ctx, base, "", 10)
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+
+		assert.Equal(t, "other", got[0].UserID)
+		assert.Equal(t, domain.ReviewerEventRemoved, got[1].EventType)
+		assert.Equal(t, "other", got[2].UserID)
+		assert.Less(t, got[1].EventID, got[2].EventID)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		got, err := repo.GetReviewerEventsSince(ctx, base.Add(-time.Minute), "", 2)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, base, got[0].CreatedAt.UTC())
+	})
+
+	t.Run("empty window", func(t *testing.T) {
+		got, err := repo.GetReviewerEventsSince(ctx, base.Add(2*time.Hour), "", 10)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
+
+func TestPullRequestRepository_GetReviewerRemovalsSince(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	base := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	seedTeam(t, pool, "backend", "author", "r1", "r2")
+	seedPR(t, pool, "pr1", "author", base)
+	seedPR(t, pool, "pr2", "author", base)
+
+	events := []struct {
+		prID      string
+		userID    string
+		eventType domain.ReviewerEventType
+		at        time.Time
+	}{
+		{"pr1", "r1", domain.ReviewerEventRemoved, base},
+		{"pr1", "r2", domain.ReviewerEventRemoved, base.Add(20 * time.Minute)},
+		{"pr1", "r1", domain.ReviewerEventAssigned, base.Add(20 * time.Minute)},
+		{"pr2", "r1", domain.ReviewerEventRemoved, base.Add(30 * time.Minute)},
+		{"pr1", "r1", domain.ReviewerEventRemoved, base.Add(40 * time.Minute)},
+	}
+	for _, e := range events {
+		mustExec(t, pool, `
+			INSERT INTO pr_reviewer_events (pull_request_id, user_id, event_type, created_at)
+			VALUES ($1, $2, $3, $4)
+		`, e.prID, e.userID, e.eventType, e.at)
+	}
+
+	got, err := repo.GetReviewerRemovalsSince(ctx, "pr1", base)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.True(t, got[0].Equal(base.Add(20*time.Minute)))
+	assert.True(t, got[1].Equal(base.Add(40*time.Minute)))
+}
+
+func TestPullRequestRepository_GetUserReviewTimelineCursor(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	base := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	seedTeam(t, pool, "backend", "author", "reviewer")
+	seedPR(t, pool, "pr1", "author", base)
+
+	insertEvent := func(at time.Time) {
+		mustExec(t, pool, `
+			INSERT INTO pr_reviewer_events (pull_request_id, user_id, event_type, created_at)
+			VALUES ('pr1', 'reviewer', $1, $2)
+		`, domain.ReviewerEventAssigned, at)
+	}
+	// два события в одну и ту же секунду: порядок внутри неё задаёт event_id
+	for _, offset := range []time.Duration{time.Hour, 2 * time.Hour, 2 * time.Hour, 3 * time.Hour} {
+		insertEvent(base.Add(offset))
+	}
+	cursorOf := func(e domain.ReviewerEvent) *domain.PageCursor {
+		return &domain.PageCursor{CreatedAt: e.CreatedAt, ID: e.EventID}
+	}
+
+	first, err := repo.GetUserReviewTimeline(ctx, "reviewer", nil, nil, domain.Page{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+
+	// вставка до курсора сдвинула бы offset-страницу, но не keyset
+	insertEvent(base)
+
+	second, err := repo.GetUserReviewTimeline(ctx, "reviewer", nil, nil, domain.Page{Limit: 2, After: cursorOf(first[1])})
+	require.NoError(t, err)
+	require.Len(t, second, 2)
+	assert.True(t, second[0].EventID > first[1].EventID)
+	assert.Equal(t, base.Add(2*time.Hour), second[0].CreatedAt.UTC())
+	assert.Equal(t, base.Add(3*time.Hour), second[1].CreatedAt.UTC())
+
+	byOffset, err := repo.GetUserReviewTimeline(ctx, "reviewer", nil, nil, domain.Page{Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	assert.Equal(t, first[1].EventID, byOffset[0].EventID, "offset page repeats an already seen event")
+
+	// вставка после курсора попадает на следующую страницу
+	insertEvent(base.Add(4 * time.Hour))
+
+	third, err := repo.GetUserReviewTimeline(ctx, "reviewer", nil, nil, domain.Page{Limit: 2, After: cursorOf(second[1])})
+	require.NoError(t, err)
+	require.Len(t, third, 1)
+	assert.Equal(t, base.Add(4*time.Hour), third[0].CreatedAt.UTC())
+}
+
+func TestPullRequestRepository_GetFormerReviews(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	base := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	seedTeam(t, pool, "backend", "author", "reviewer", "other")
+	seedPR(t, pool, "pr1", "author", base)
+	seedPR(t, pool, "pr2", "author", base)
+	seedPR(t, pool, "pr3", "author", base)
+
+	events := []struct {
+		prID      string
+		userID    string
+		eventType domain.ReviewerEventType
+		at        time.Time
+	}{
+		// pr1: назначен, снят, снова назначен и снова снят
+		{"pr1", "reviewer", domain.ReviewerEventAssigned, base.Add(1 * time.Hour)},
+		{"pr1", "reviewer", domain.ReviewerEventRemoved, base.Add(2 * time.Hour)},
+		{"pr1", "reviewer", domain.ReviewerEventAssigned, base.Add(3 * time.Hour)},
+		{"pr1", "reviewer", domain.ReviewerEventRemoved, base.Add(4 * time.Hour)},
+		// pr2: назначен раньше pr1 и всё ещё ревьюер
+		{"pr2", "reviewer", domain.ReviewerEventAssigned, base.Add(30 * time.Minute)},
+		// pr3: чужая история
+		{"pr3", "other", domain.ReviewerEventAssigned, base},
+	}
+	for _, e := range events {
+		mustExec(t, pool, `
+			INSERT INTO pr_reviewer_events (pull_request_id, user_id, event_type, created_at)
+			VALUES ($1, $2, $3, $4)
+		`, e.prID, e.userID, e.eventType, e.at)
+	}
+
+	got, err := repo.GetFormerReviews(ctx, "reviewer")
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	assert.Equal(t, "pr2", got[0].PullRequestID)
+	require.NotNil(t, got[0].FirstAssignedAt)
+	assert.True(t, base.Add(30*time.Minute).Equal(*got[0].FirstAssignedAt))
+	assert.Nil(t, got[0].LastRemovedAt)
+
+	assert.Equal(t, "pr1", got[1].PullRequestID)
+	require.NotNil(t, got[1].FirstAssignedAt)
+	assert.True(t, base.Add(1*time.Hour).Equal(*got[1].FirstAssignedAt))
+	require.NotNil(t, got[1].LastRemovedAt)
+	assert.True(t, base.Add(4*time.Hour).Equal(*got[1].LastRemovedAt))
+
+	none, err := repo.GetFormerReviews(ctx, "author")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestPullRequestRepository_GetReviewLatency(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	base := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	seedTeam(t, pool, "backend", "author", "fast", "rare")
+	seedTeam(t, pool, "frontend", "outsider")
+
+	// fast ревьюит pr1..pr3 за 1, 2 и 4 часа, rare - только pr1, outsider - из другой команды
+	merges := []time.Duration{time.Hour, 2 * time.Hour, 4 * time.Hour}
+	for i, wait := range merges {
+		prID := fmt.Sprintf("pr%d", i+1)
+		seedPR(t, pool, prID, "author", base)
+		mustExec(t, pool, "INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at) VALUES ($1, 'fast', $2)", prID, base)
+		mustExec(t, pool, "UPDATE pull_requests SET status = 'MERGED', merged_at = $2 WHERE pull_request_id = $1", prID, base.Add(wait))
+	}
+	mustExec(t, pool, "INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at) VALUES ('pr1', 'rare', $1)", base)
+	mustExec(t, pool, "INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at) VALUES ('pr1', 'outsider', $1)", base)
+	// открытый PR не учитывается
+	seedPR(t, pool, "open", "author", base)
+	mustExec(t, pool, "INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at) VALUES ('open', 'fast', $1)", base)
+
+	t.Run("min samples filter", func(t *testing.T) {
+		got, err := repo.GetReviewLatency(ctx, "backend", nil, nil, 2)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+
+		assert.Equal(t, "fast", got[0].UserID)
+		assert.Equal(t, 3, got[0].Samples)
+		assert.Equal(t, 2*time.Hour, got[0].Median)
+		// p90 по 1h, 2h, 4h линейно интерполируется: 2h + 0.8 * 2h
+		assert.Equal(t, 3*time.Hour+36*time.Minute, got[0].P90)
+	})
+
+	t.Run("window by merged_at", func(t *testing.T) {
+		to := base.Add(3 * time.Hour)
+
+		got, err := repo.GetReviewLatency(ctx, "backend", nil, &to, 1)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, "fast", got[0].UserID)
+		assert.Equal(t, 2, got[0].Samples)
+		assert.Equal(t, "rare", got[1].UserID)
+		assert.Equal(t, time.Hour, got[1].Median)
+	})
+
+	t.Run("by users across teams", func(t *testing.T) {
+		got, err := repo.GetReviewerLatencyByUsers(ctx, []string{"fast", "outsider"}, base.Add(90*time.Minute), 1)
+		require.NoError(t, err)
+		// pr1 смержен до from, поэтому у outsider выборки нет, а у fast - 2h и 4h
+		require.Len(t, got, 1)
+		assert.Equal(t, "fast", got[0].UserID)
+		assert.Equal(t, 2, got[0].Samples)
+		assert.Equal(t, 3*time.Hour, got[0].Median)
+
+		got, err = repo.GetReviewerLatencyByUsers(ctx, []string{"outsider"}, base, 1)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, time.Hour, got[0].Median)
+	})
+}
+
+func TestPullRequestRepository_GetReviewerQueues(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	base := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	seedTeam(t, pool, "backend", "author", "u1", "u2")
+	for _, id := range []string{"old1", "old2", "merged", "target", "tie", "newer"} {
+		seedPR(t, pool, id, "author", base)
+	}
+	mustExec(t, pool, "UPDATE pull_requests SET status = 'MERGED', merged_at = $1 WHERE pull_request_id = 'merged'", base)
+	assign := func(prID, userID string, at time.Time) {
+		mustExec(t, pool, "INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at) VALUES ($1, $2, $3)", prID, userID, at)
+	}
+	// у u1 перед target два открытых PR, смерженный не считается, tie назначен в ту же секунду,
+	// но его id больше; у u2 target - первый
+	assign("old1", "u1", base)
+	assign("old2", "u1", base.Add(time.Minute))
+	assign("merged", "u1", base.Add(2*time.Minute))
+	assign("target", "u1", base.Add(3*time.Minute))
+	assign("tie", "u1", base.Add(3*time.Minute))
+	assign("newer", "u1", base.Add(4*time.Minute))
+	assign("target", "u2", base.Add(5*time.Minute))
+	assign("newer", "u2", base.Add(6*time.Minute))
+
+	queues, err := repo.GetReviewerQueues(ctx, "target")
+	require.NoError(t, err)
+	require.Len(t, queues, 2)
+	assert.Equal(t, "u1", queues[0].UserID)
+	assert.Equal(t, 2, queues[0].Ahead)
+	assert.Equal(t, base.Add(3*time.Minute), queues[0].AssignedAt.UTC())
+	assert.Equal(t, "u2", queues[1].UserID)
+	assert.Equal(t, 0, queues[1].Ahead)
+
+	tie, err := repo.GetReviewerQueues(ctx, "tie")
+	require.NoError(t, err)
+	require.Len(t, tie, 1)
+	assert.Equal(t, 3, tie[0].Ahead)
+
+	none, err := repo.GetReviewerQueues(ctx, "old1")
+	require.NoError(t, err)
+	require.Len(t, none, 1)
+	assert.Equal(t, 0, none[0].Ahead)
+}
+
+func TestPullRequestRepository_ConcurrentMerge(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	txManager, err := db.NewTransactionManager(pool)
+	require.NoError(t, err)
+
+	seedTeam(t, pool, "backend", "author")
+	seedPR(t, pool, "pr1", "author", time.Now())
+
+	const workers = 2
+	results := make(chan bool, workers)
+	start := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			err := txManager.Do(context.Background(), func(txCtx context.Context) error {
+				if err := repo.LockPullRequest(txCtx, "pr1"); err != nil {
+					return err
+				}
+				// расширяем окно гонки: без лока оба воркера прошли бы сюда одновременно
+				time.Sleep(50 * time.Millisecond)
+
+				merged, err := repo.MergePullRequest(txCtx, "pr1", 2, "")
+				if err != nil {
+					return err
+				}
+				results <- merged
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+	close(results)
+
+	changed := 0
+	for merged := range results {
+		if merged {
+			changed++
+		}
+	}
+	assert.Equal(t, 1, changed)
+
+	pr, err := repo.GetPullRequestByID(context.Background(), "pr1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PRStatusMerged, pr.Status)
+}
+
+func TestPullRequestRepository_MergedPRIsImmutable(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "reviewer1", "reviewer2")
+	seedPR(t, pool, "pr1", "author", time.Now())
+	require.NoError(t, repo.AssignReviewer(ctx, "pr1", "reviewer1", domain.AssignmentSourceAuto))
+
+	merged, err := repo.MergePullRequest(ctx, "pr1", 2, "")
+	require.NoError(t, err)
+	require.True(t, merged)
+
+	assert.ErrorIs(t, repo.AssignReviewer(ctx, "pr1", "reviewer2", domain.AssignmentSourceAuto), ErrNotOpen)
+	_, err = repo.RemoveReviewer(ctx, "pr1", "reviewer1")
+	assert.ErrorIs(t, err, ErrNotOpen)
+	_, err = repo.RemoveReviewer(ctx, "missing", "reviewer2")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	pr, err := repo.GetPullRequestByID(ctx, "pr1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reviewer1"}, pr.AssignedReviewers)
+}
+
+func TestPullRequestRepository_RemoveReviewerReportsDeletion(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "reviewer1", "reviewer2")
+	seedPR(t, pool, "pr1", "author", time.Now())
+	require.NoError(t, repo.AssignReviewer(ctx, "pr1", "reviewer1", domain.AssignmentSourceAuto))
+
+	removed, err := repo.RemoveReviewer(ctx, "pr1", "reviewer1")
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	// повторное снятие и снятие неназначенного - no-op без ошибки
+	removed, err = repo.RemoveReviewer(ctx, "pr1", "reviewer1")
+	require.NoError(t, err)
+	assert.False(t, removed)
+	removed, err = repo.RemoveReviewer(ctx, "pr1", "reviewer2")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	var events int
+	require.NoError(t, pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM pr_reviewer_events WHERE pull_request_id = 'pr1' AND event_type = $1`,
+		domain.ReviewerEventRemoved).Scan(&events))
+	assert.Equal(t, 1, events)
+}
+
+func TestPullRequestRepository_MergeRecordsReviewerCount(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "reviewer1", "reviewer2")
+	seedPR(t, pool, "short", "author", time.Now())
+	seedPR(t, pool, "full", "author", time.Now())
+	seedPR(t, pool, "open", "author", time.Now())
+	require.NoError(t, repo.AssignReviewer(ctx, "short", "reviewer1", domain.AssignmentSourceAuto))
+	for _, reviewerID := range []string{"reviewer1", "reviewer2"} {
+		require.NoError(t, repo.AssignReviewer(ctx, "full", reviewerID, domain.AssignmentSourceAuto))
+	}
+
+	for _, prID := range []string{"short", "full"} {
+		merged, err := repo.MergePullRequest(ctx, prID, 2, "")
+		require.NoError(t, err)
+		require.True(t, merged)
+	}
+
+	short, err := repo.GetPullRequestByID(ctx, "short")
+	require.NoError(t, err)
+	require.NotNil(t, short.MergedReviewerCount)
+	assert.Equal(t, 1, *short.MergedReviewerCount)
+	assert.True(t, short.MergedUnderstaffed)
+
+	full, err := repo.GetPullRequestByID(ctx, "full")
+	require.NoError(t, err)
+	require.NotNil(t, full.MergedReviewerCount)
+	assert.Equal(t, 2, *full.MergedReviewerCount)
+	assert.False(t, full.MergedUnderstaffed)
+
+	open, err := repo.GetPullRequestByID(ctx, "open")
+	require.NoError(t, err)
+	assert.Nil(t, open.MergedReviewerCount)
+	assert.False(t, open.MergedUnderstaffed)
+
+	// повторный merge не пересчитывает сохранённый состав
+	require.NoError(t, repo.AssignReviewer(ctx, "open", "reviewer1", domain.AssignmentSourceAuto))
+	merged, err := repo.MergePullRequest(ctx, "short", 1, "")
+	require.NoError(t, err)
+	assert.False(t, merged)
+	short, err = repo.GetPullRequestByID(ctx, "short")
+	require.NoError(t, err)
+	assert.True(t, short.MergedUnderstaffed)
+}
+
+func TestPullRequestRepository_GetInactiveReviewerAssignments(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "active", "inactive")
+	mustExec(t, pool, "UPDATE users SET is_active = false WHERE user_id = 'inactive'")
+	seedPR(t, pool, "open", "author", time.Now())
+	seedPR(t, pool, "merged", "author", time.Now())
+	for _, prID := range []string{"open", "merged"} {
+		require.NoError(t, repo.AssignReviewer(ctx, prID, "active", domain.AssignmentSourceAuto))
+		require.NoError(t, repo.AssignReviewer(ctx, prID, "inactive", domain.AssignmentSourceAuto))
+	}
+	_, err := repo.MergePullRequest(ctx, "merged", 2, "")
+	require.NoError(t, err)
+
+	assignments, err := repo.GetInactiveReviewerAssignments(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ReviewerAssignment{
+		{PullRequestID: "open", UserID: "inactive", TeamName: "backend"},
+	}, assignments)
+}
+
+func TestPullRequestRepository_ReassignmentCount(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "r1", "r2", "r3")
+	seedPR(t, pool, "pr1", "author", time.Now())
+	seedPR(t, pool, "pr2", "author", time.Now())
+	require.NoError(t, repo.AssignReviewer(ctx, "pr1", "r1", domain.AssignmentSourceAuto))
+
+	reassign := func(oldID, newID string) {
+		removed, err := repo.RemoveReviewer(ctx, "pr1", oldID)
+		require.NoError(t, err)
+		require.True(t, removed)
+		require.NoError(t, repo.AssignReviewer(ctx, "pr1", newID, domain.AssignmentSourceAuto))
+		require.NoError(t, repo.IncrementReassignmentCount(ctx, "pr1"))
+	}
+	reassign("r1", "r2")
+	reassign("r2", "r3")
+
+	// не связанные с заменой изменения счётчик не трогают
+	require.NoError(t, repo.AssignReviewer(ctx, "pr2", "r1", domain.AssignmentSourceAuto))
+	_, err := repo.MergePullRequest(ctx, "pr1", 2, "")
+	require.NoError(t, err)
+
+	pr, err := repo.GetPullRequestByID(ctx, "pr1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, pr.ReassignmentCount)
+
+	other, err := repo.GetPullRequestByID(ctx, "pr2")
+	require.NoError(t, err)
+	assert.Equal(t, 0, other.ReassignmentCount)
+
+	assert.ErrorIs(t, repo.IncrementReassignmentCount(ctx, "missing"), ErrNotFound)
+}
+
+func TestPullRequestRepository_ActiveReviewerCheck(t *testing.T) {
+	database, pool := setupTestDB(t)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "inactive")
+	mustExec(t, pool, "UPDATE users SET is_active = false WHERE user_id = 'inactive'")
+	seedPR(t, pool, "pr1", "author", time.Now())
+
+	t.Run("disabled allows inactive reviewer", func(t *testing.T) {
+		repo := NewPullRequestRepository(database)
+		require.NoError(t, repo.AssignReviewer(ctx, "pr1", "inactive", domain.AssignmentSourceAuto))
+		removed, err := repo.RemoveReviewer(ctx, "pr1", "inactive")
+		require.NoError(t, err)
+		require.True(t, removed)
+	})
+
+	t.Run("enabled rejects inactive reviewer", func(t *testing.T) {
+		repo := NewPullRequestRepository(database, WithActiveReviewerCheck())
+		assert.ErrorIs(t, repo.AssignReviewer(ctx, "pr1", "inactive", domain.AssignmentSourceAuto), ErrInactiveReviewer)
+
+		_, err := repo.MergePullRequest(ctx, "pr1", 2, "")
+		require.NoError(t, err)
+		assert.ErrorIs(t, repo.AssignReviewer(ctx, "pr1", "inactive", domain.AssignmentSourceAuto), ErrNotOpen)
+	})
+}
+
+func TestPullRequestRepository_GetOpenPRStatsByTeam(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "b1", "b2")
+	seedTeam(t, pool, "frontend", "f1")
+	seedPR(t, pool, "reviewed", "b1", time.Now())
+	seedPR(t, pool, "unreviewed", "b1", time.Now())
+	seedPR(t, pool, "merged", "b1", time.Now())
+	seedPR(t, pool, "front", "f1", time.Now())
+	require.NoError(t, repo.AssignReviewer(ctx, "reviewed", "b2", domain.AssignmentSourceAuto))
+	_, err := repo.MergePullRequest(ctx, "merged", 2, "")
+	require.NoError(t, err)
+
+	stats, err := repo.GetOpenPRStatsByTeam(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TeamOpenPRStats{
+		{TeamName: "backend", Open: 2, WithoutReviewers: 1},
+		{TeamName: "frontend", Open: 1, WithoutReviewers: 1},
+	}, stats)
+}
+
+func TestPullRequestRepository_GetTeamReviewAssignments(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "b1", "b2")
+	seedTeam(t, pool, "frontend", "f1")
+	seedPR(t, pool, "open", "b1", time.Now())
+	seedPR(t, pool, "merged", "b1", time.Now())
+	seedPR(t, pool, "front", "f1", time.Now())
+	for _, prID := range []string{"open", "merged", "front"} {
+		require.NoError(t, repo.AssignReviewer(ctx, prID, "b2", domain.AssignmentSourceAuto))
+	}
+	_, err := repo.MergePullRequest(ctx, "merged", 2, "")
+	require.NoError(t, err)
+
+	assignments, err := repo.GetTeamReviewAssignments(ctx, "backend")
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ReviewerAssignment{{PullRequestID: "open", UserID: "b2", TeamName: "backend"}}, assignments)
+}
+
+func TestPullRequestRepository_GetUnderReviewedTeamPRs(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	now := time.Now()
+	seedTeam(t, pool, "backend", "b1", "b2", "b3", "back")
+	seedTeam(t, pool, "frontend", "f1")
+	seedPR(t, pool, "one", "b1", now.Add(-2*time.Hour))
+	seedPR(t, pool, "none", "b1", now.Add(-time.Hour))
+	seedPR(t, pool, "full", "b1", now)
+	seedPR(t, pool, "reviewing", "b1", now)
+	seedPR(t, pool, "own", "back", now)
+	seedPR(t, pool, "merged", "b1", now)
+	seedPR(t, pool, "pending", "b1", now)
+	seedPR(t, pool, "front", "f1", now)
+	require.NoError(t, repo.AssignReviewer(ctx, "one", "b2", domain.AssignmentSourceAuto))
+	require.NoError(t, repo.AssignReviewer(ctx, "full", "b2", domain.AssignmentSourceAuto))
+	require.NoError(t, repo.AssignReviewer(ctx, "full", "b3", domain.AssignmentSourceAuto))
+	require.NoError(t, repo.AssignReviewer(ctx, "reviewing", "back", domain.AssignmentSourceAuto))
+	_, err := repo.MergePullRequest(ctx, "merged", 2, "")
+	require.NoError(t, err)
+	require.NoError(t, repo.MarkPendingAssignment(ctx, "pending"))
+
+	prs, err := repo.GetUnderReviewedTeamPRs(ctx, "backend", "back", 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.UnderReviewedPR{
+		{PullRequestID: "none", ReviewerCount: 0},
+		{PullRequestID: "one", ReviewerCount: 1},
+	}, prs)
+}
+
+func TestPullRequestRepository_PriorityAndOpenReviewCounts(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "r1", "r2")
+	_, err := repo.CreatePullRequest(ctx, domain.PullRequestCreate{
+		PullRequestID: "hotfix", PullRequestName: "hotfix", AuthorID: "author", Priority: domain.PRPriorityHigh,
+	})
+	require.NoError(t, err)
+	_, err = repo.CreatePullRequest(ctx, domain.PullRequestCreate{PullRequestID: "plain", PullRequestName: "plain", AuthorID: "author"})
+	require.NoError(t, err)
+	seedPR(t, pool, "merged", "author", time.Now())
+
+	for _, prID := range []string{"hotfix", "plain", "merged"} {
+		require.NoError(t, repo.AssignReviewer(ctx, prID, "r1", domain.AssignmentSourceAuto))
+	}
+	_, err = repo.MergePullRequest(ctx, "merged", 2, "")
+	require.NoError(t, err)
+
+	hotfix, err := repo.GetPullRequestByID(ctx, "hotfix")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PRPriorityHigh, hotfix.Priority)
+
+	plain, err := repo.GetPullRequestByID(ctx, "plain")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PRPriorityNormal, plain.Priority)
+
+	counts, err := repo.GetOpenReviewCounts(ctx, []string{"r1", "r2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"r1": 2}, counts)
+}
+
+func TestPullRequestRepository_GetPullRequestsByIDs(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "r1", "r2", "r3")
+	for _, prID := range []string{"pr1", "pr2", "pr3"} {
+		seedPR(t, pool, prID, "author", time.Now())
+	}
+	require.NoError(t, repo.AssignReviewer(ctx, "pr1", "r1", domain.AssignmentSourceAuto))
+	require.NoError(t, repo.AssignReviewer(ctx, "pr1", "r2", domain.AssignmentSourceManual))
+	require.NoError(t, repo.AssignReviewer(ctx, "pr3", "r3", domain.AssignmentSourceAuto))
+
+	prs, err := repo.GetPullRequestsByIDs(ctx, []string{"pr3", "missing", "pr2", "pr1", "pr3"})
+	require.NoError(t, err)
+
+	// порядок запроса, повтор и неизвестный ID пропущены
+	require.Len(t, prs, 3)
+	assert.Equal(t, "pr3", prs[0].PullRequestID)
+	assert.Equal(t, "pr2", prs[1].PullRequestID)
+	assert.Equal(t, "pr1", prs[2].PullRequestID)
+
+	assert.Equal(t, []string{"r3"}, prs[0].AssignedReviewers)
+	assert.Empty(t, prs[1].AssignedReviewers)
+	assert.ElementsMatch(t, []string{"r1", "r2"}, prs[2].AssignedReviewers)
+	assert.ElementsMatch(t, []domain.PRReviewer{
+		{UserID: "r1", Source: domain.AssignmentSourceAuto},
+		{UserID: "r2", Source: domain.AssignmentSourceManual},
+	}, prs[2].Reviewers)
+
+	single, err := repo.GetPullRequestByID(ctx, "pr1")
+	require.NoError(t, err)
+	assert.Equal(t, single.Status, prs[2].Status)
+	assert.Equal(t, single.CreatedAt, prs[2].CreatedAt)
+	// ревьюверы в том же порядке, что и в пакетном запросе
+	assert.Equal(t, single.AssignedReviewers, prs[2].AssignedReviewers)
+	assert.Equal(t, single.Reviewers, prs[2].Reviewers)
+
+	none, err := repo.GetPullRequestsByIDs(ctx, []string{"missing"})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestPullRequestRepository_AssignmentSource(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	since := time.Now().Add(-time.Minute)
+	seedTeam(t, pool, "backend", "author", "auto", "manual")
+	seedPR(t, pool, "pr1", "author", time.Now())
+	require.NoError(t, repo.AssignReviewer(ctx, "pr1", "auto", domain.AssignmentSourceAuto))
+	require.NoError(t, repo.AssignReviewer(ctx, "pr1", "manual", domain.AssignmentSourceManual))
+	removed, err := repo.RemoveReviewer(ctx, "pr1", "auto")
+	require.NoError(t, err)
+	require.True(t, removed)
+
+	pr, err := repo.GetPullRequestByID(ctx, "pr1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"manual"}, pr.AssignedReviewers)
+	assert.Equal(t, []domain.PRReviewer{{UserID: "manual", Source: domain.AssignmentSourceManual}}, pr.Reviewers)
+
+	all, err := repo.GetReviewerEventsSince(ctx, since, "", 10)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, domain.AssignmentSourceAuto, all[0].Source)
+	assert.Empty(t, all[2].Source, "removal has no source")
+
+	manual, err := repo.GetReviewerEventsSince(ctx, since, domain.AssignmentSourceManual, 10)
+	require.NoError(t, err)
+	require.Len(t, manual, 1)
+	assert.Equal(t, "manual", manual[0].UserID)
+	assert.Equal(t, domain.ReviewerEventAssigned, manual[0].EventType)
+}
+
+func TestPullRequestRepository_ReplaceReviewers(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "reviewer1", "reviewer2", "reviewer3")
+	seedPR(t, pool, "pr1", "author", time.Now())
+	require.NoError(t, repo.AssignReviewer(ctx, "pr1", "reviewer1", domain.AssignmentSourceAuto))
+	require.NoError(t, repo.AssignReviewer(ctx, "pr1", "reviewer2", domain.AssignmentSourceAuto))
+
+	require.NoError(t, repo.ReplaceReviewers(ctx, "pr1", []string{"reviewer2", "reviewer3"}, domain.AssignmentSourceAdmin))
+
+	reviewers, err := repo.GetReviewerIDs(ctx, "pr1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"reviewer2", "reviewer3"}, reviewers)
+
+	removed, err := repo.GetUserReviewTimeline(ctx, "reviewer1", nil, nil, domain.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, removed, 2)
+	assert.Equal(t, domain.ReviewerEventRemoved, removed[1].EventType)
+
+	// оставшийся ревьюер не получает лишних событий и сохраняет источник
+	kept, err := repo.GetUserReviewTimeline(ctx, "reviewer2", nil, nil, domain.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, kept, 1)
+
+	pr, err := repo.GetPullRequestByID(ctx, "pr1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []domain.PRReviewer{
+		{UserID: "reviewer2", Source: domain.AssignmentSourceAuto},
+		{UserID: "reviewer3", Source: domain.AssignmentSourceAdmin},
+	}, pr.Reviewers)
+
+	t.Run("merged PR", func(t *testing.T) {
+		_, err := repo.MergePullRequest(ctx, "pr1", 2, "")
+		require.NoError(t, err)
+
+		require.NoError(t, repo.ReplaceReviewers(ctx, "pr1", nil, domain.AssignmentSourceAdmin))
+
+		reviewers, err := repo.GetReviewerIDs(ctx, "pr1")
+		require.NoError(t, err)
+		assert.Empty(t, reviewers)
+	})
+}
+
+func TestPullRequestRepository_OrphanedPullRequests(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "other", "reviewer")
+	seedPR(t, pool, "open", "author", time.Now())
+	seedPR(t, pool, "merged", "author", time.Now())
+	seedPR(t, pool, "foreign", "other", time.Now())
+	for _, prID := range []string{"open", "merged", "foreign"} {
+		require.NoError(t, repo.AssignReviewer(ctx, prID, "reviewer", domain.AssignmentSourceAuto))
+	}
+	_, err := repo.MergePullRequest(ctx, "merged", 2, "")
+	require.NoError(t, err)
+
+	t.Run("mark keeps reviewers", func(t *testing.T) {
+		ids, err := repo.MarkOrphanedPullRequests(ctx, "author")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"open"}, ids)
+
+		pr, err := repo.GetPullRequestByID(ctx, "open")
+		require.NoError(t, err)
+		assert.True(t, pr.Orphaned)
+		assert.Equal(t, domain.PRStatusOpen, pr.Status)
+		assert.Equal(t, []string{"reviewer"}, pr.AssignedReviewers)
+
+		prs, err := repo.GetPullRequestsByReviewer(ctx, "reviewer", domain.ReviewFilter{Now: time.Now()})
+		require.NoError(t, err)
+		for _, short := range prs {
+			assert.Equal(t, short.PullRequestID == "open", short.Orphaned, short.PullRequestID)
+		}
+	})
+
+	t.Run("close removes reviewers", func(t *testing.T) {
+		ids, err := repo.CloseOrphanedPullRequests(ctx, "author")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"open"}, ids)
+
+		pr, err := repo.GetPullRequestByID(ctx, "open")
+		require.NoError(t, err)
+		assert.Equal(t, domain.PRStatusClosed, pr.Status)
+		assert.Empty(t, pr.AssignedReviewers)
+
+		merged, err := repo.MergePullRequest(ctx, "open", 2, "")
+		require.NoError(t, err)
+		assert.False(t, merged)
+
+		foreign, err := repo.GetPullRequestByID(ctx, "foreign")
+		require.NoError(t, err)
+		assert.False(t, foreign.Orphaned)
+		assert.Equal(t, []string{"reviewer"}, foreign.AssignedReviewers)
+	})
+}
+
+func TestPullRequestRepository_SnoozeReview(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "reviewer", "other")
+	seedPR(t, pool, "snoozed", "author", time.Now())
+	seedPR(t, pool, "active", "author", time.Now())
+	seedPR(t, pool, "merged", "author", time.Now())
+	for _, prID := range []string{"snoozed", "active", "merged"} {
+		require.NoError(t, repo.AssignReviewer(ctx, prID, "reviewer", domain.AssignmentSourceAuto))
+	}
+	require.NoError(t, repo.AssignReviewer(ctx, "snoozed", "other", domain.AssignmentSourceAuto))
+
+	until := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.SnoozeReview(ctx, "snoozed", "reviewer", until))
+	// snooze смерженного PR выдачу не меняет
+	require.NoError(t, repo.SnoozeReview(ctx, "merged", "reviewer", until))
+	_, err := repo.MergePullRequest(ctx, "merged", 2, "")
+	require.NoError(t, err)
+
+	ids := func(t *testing.T, userID string, filter domain.ReviewFilter) []string {
+		t.Helper()
+		prs, err := repo.GetPullRequestsByReviewer(ctx, userID, filter)
+		require.NoError(t, err)
+		var ids []string
+		for _, pr := range prs {
+			ids = append(ids, pr.PullRequestID)
+		}
+		return ids
+	}
+
+	t.Run("hidden before until", func(t *testing.T) {
+		filter := domain.ReviewFilter{Status: domain.PRStatusOpen, Now: until.Add(-time.Second)}
+		assert.ElementsMatch(t, []string{"active"}, ids(t, "reviewer", filter))
+		assert.ElementsMatch(t, []string{"snoozed"}, ids(t, "other", filter), "snooze is per reviewer")
+	})
+
+	t.Run("visible at until", func(t *testing.T) {
+		filter := domain.ReviewFilter{Status: domain.PRStatusOpen, Now: until}
+		assert.ElementsMatch(t, []string{"snoozed", "active"}, ids(t, "reviewer", filter))
+	})
+
+	t.Run("include snoozed", func(t *testing.T) {
+		prs, err := repo.GetPullRequestsByReviewer(ctx, "reviewer", domain.ReviewFilter{
+			IncludeSnoozed: true, Now: until.Add(-time.Second),
+		})
+		require.NoError(t, err)
+		require.Len(t, prs, 3)
+		for _, pr := range prs {
+			if pr.PullRequestID == "snoozed" {
+				require.NotNil(t, pr.SnoozedUntil)
+				assert.True(t, until.Equal(*pr.SnoozedUntil))
+			} else {
+				assert.Nil(t, pr.SnoozedUntil, pr.PullRequestID)
+			}
+		}
+	})
+
+	t.Run("status filter", func(t *testing.T) {
+		filter := domain.ReviewFilter{Status: domain.PRStatusMerged, Now: until.Add(-time.Second)}
+		assert.Equal(t, []string{"merged"}, ids(t, "reviewer", filter))
+	})
+
+	t.Run("snooze again moves until", func(t *testing.T) {
+		later := until.Add(time.Hour)
+		require.NoError(t, repo.SnoozeReview(ctx, "snoozed", "reviewer", later))
+
+		filter := domain.ReviewFilter{Status: domain.PRStatusOpen, Now: until.Add(time.Minute)}
+		assert.ElementsMatch(t, []string{"active"}, ids(t, "reviewer", filter))
+	})
+
+	t.Run("removing reviewer drops snooze", func(t *testing.T) {
+		removed, err := repo.RemoveReviewer(ctx, "snoozed", "reviewer")
+		require.NoError(t, err)
+		require.True(t, removed)
+		require.NoError(t, repo.AssignReviewer(ctx, "snoozed", "reviewer", domain.AssignmentSourceManual))
+
+		filter := domain.ReviewFilter{Status: domain.PRStatusOpen, Now: until.Add(-time.Second)}
+		assert.ElementsMatch(t, []string{"snoozed", "active"}, ids(t, "reviewer", filter))
+	})
+
+	t.Run("not assigned", func(t *testing.T) {
+		err := repo.SnoozeReview(ctx, "active", "other", until)
+		assert.ErrorIs(t, err, ErrReferenceNotFound)
+	})
+}
+
+// исключение текущих ревьюеров при переназначении и снятие ревьюера рассчитывают на то, что
+// AssignedReviewers без повторов; это гарантирует первичный ключ pr_reviewers, а не нормализация при чтении
+func TestPullRequestRepository_DuplicateReviewerRowRejected(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "r1")
+	seedPR(t, pool, "pr1", "author", time.Now())
+	require.NoError(t, repo.AssignReviewer(ctx, "pr1", "r1", domain.AssignmentSourceAuto))
+
+	_, err := pool.Exec(ctx, "INSERT INTO pr_reviewers (pull_request_id, user_id) VALUES ('pr1', 'r1')")
+	require.ErrorIs(t, HandleDBError(err), ErrAlreadyExists)
+
+	pr, err := repo.GetPullRequestByID(ctx, "pr1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"r1"}, pr.AssignedReviewers)
+}
+
+func TestPullRequestRepository_AssignReviewerConstraintKeepsTx(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	txManager, err := db.NewTransactionManager(pool)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "a1", "r1")
+	seedPR(t, pool, "pr1", "a1", time.Now())
+
+	err = txManager.Do(ctx, func(txCtx context.Context) error {
+		// удалённый пользователь и повторное назначение не ломают транзакцию
+		require.ErrorIs(t, repo.AssignReviewer(txCtx, "pr1", "ghost", domain.AssignmentSourceAuto), ErrReferenceNotFound)
+		require.NoError(t, repo.AssignReviewer(txCtx, "pr1", "r1", domain.AssignmentSourceAuto))
+		require.ErrorIs(t, repo.AssignReviewer(txCtx, "pr1", "r1", domain.AssignmentSourceAuto), ErrAlreadyExists)
+		return nil
+	})
+	require.NoError(t, err)
+
+	reviewers, err := repo.GetReviewerIDs(ctx, "pr1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"r1"}, reviewers)
+}
+
+func TestPullRequestRepository_GetOpenGroupAuthors(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "a1", "a2", "a3", "a4")
+	for _, pr := range []domain.PullRequestCreate{
+		{PullRequestID: "pr1", PullRequestName: "pr1", AuthorID: "a1", GroupID: "epic"},
+		{PullRequestID: "pr2", PullRequestName: "pr2", AuthorID: "a2", GroupID: "epic"},
+		{PullRequestID: "pr3", PullRequestName: "pr3", AuthorID: "a3", GroupID: "epic"},
+		{PullRequestID: "pr4", PullRequestName: "pr4", AuthorID: "a4", GroupID: "other"},
+		{PullRequestID: "pr5", PullRequestName: "pr5", AuthorID: "a4"},
+	} {
+		_, err := repo.CreatePullRequest(ctx, pr)
+		require.NoError(t, err)
+	}
+	mustExec(t, pool, `UPDATE pull_requests SET status = 'MERGED' WHERE pull_request_id = 'pr3'`)
+
+	pr, err := repo.GetPullRequestByID(ctx, "pr1")
+	require.NoError(t, err)
+	assert.Equal(t, "epic", pr.GroupID)
+
+	ungrouped, err := repo.GetPullRequestByID(ctx, "pr5")
+	require.NoError(t, err)
+	assert.Empty(t, ungrouped.GroupID)
+
+	authors, err := repo.GetOpenGroupAuthors(ctx, "epic", "pr1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a2"}, authors)
+}
+
+func TestPullRequestRepository_GetOpenUserPullRequests(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	base := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	seedTeam(t, pool, "backend", "u1", "u2")
+	seedPR(t, pool, "mine-new", "u1", base.Add(time.Hour))
+	seedPR(t, pool, "mine-old", "u1", base)
+	seedPR(t, pool, "mine-merged", "u1", base)
+	seedPR(t, pool, "theirs", "u2", base)
+	mustExec(t, pool, `UPDATE pull_requests SET status = 'MERGED' WHERE pull_request_id = 'mine-merged'`)
+	mustExec(t, pool, `INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at) VALUES ('theirs', 'u1', $1)`, base.Add(2*time.Hour))
+
+	authored, err := repo.GetOpenAuthoredPullRequests(ctx, "u1")
+	require.NoError(t, err)
+	require.Len(t, authored, 2)
+	assert.Equal(t, "mine-old", authored[0].PullRequestID)
+	assert.Equal(t, base, authored[0].CreatedAt.UTC())
+	assert.Nil(t, authored[0].AssignedAt)
+
+	reviewing, err := repo.GetOpenReviewingPullRequests(ctx, "u1")
+	require.NoError(t, err)
+	require.Len(t, reviewing, 1)
+	assert.Equal(t, "theirs", reviewing[0].PullRequestID)
+	require.NotNil(t, reviewing[0].AssignedAt)
+	assert.Equal(t, base.Add(2*time.Hour), reviewing[0].AssignedAt.UTC())
+}
+
+func TestPullRequestRepository_BackfillAssignedEvents(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	base := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	seedTeam(t, pool, "backend", "author", "u1", "u2", "u3")
+	seedPR(t, pool, "pr1", "author", base)
+	seedPR(t, pool, "pr2", "author", base)
+	// назначения до появления pr_reviewer_events, у pr1/u2 событие уже есть
+	for i, row := range [][2]string{{"pr1", "u1"}, {"pr1", "u2"}, {"pr2", "u1"}, {"pr2", "u3"}, {"pr1", "u3"}} {
+		mustExec(t, pool, `
+			INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at, assignment_source)
+			VALUES ($1, $2, $3, 'MANUAL')
+		`, row[0], row[1], base.Add(time.Duration(i)*time.Hour))
+	}
+	mustExec(t, pool, `
+		INSERT INTO pr_reviewer_events (pull_request_id, user_id, event_type, assignment_source, created_at)
+		VALUES ('pr1', 'u2', 'ASSIGNED', 'MANUAL', $1)
+	`, base.Add(time.Hour))
+
+	countEvents := func() int {
+		var count int
+		require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM pr_reviewer_events`).Scan(&count))
+		return count
+	}
+
+	t.Run("batch boundaries", func(t *testing.T) {
+		batch, err := repo.BackfillAssignedEvents(ctx, domain.BackfillWatermark{}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, domain.BackfillBatch{
+			Scanned: 2, Inserted: 1, Last: domain.BackfillWatermark{PullRequestID: "pr1", UserID: "u2"},
+		}, batch)
+
+		batch, err = repo.BackfillAssignedEvents(ctx, batch.Last, 2)
+		require.NoError(t, err)
+		assert.Equal(t, domain.BackfillBatch{
+			Scanned: 2, Inserted: 2, Last: domain.BackfillWatermark{PullRequestID: "pr2", UserID: "u1"},
+		}, batch)
+
+		batch, err = repo.BackfillAssignedEvents(ctx, batch.Last, 2)
+		require.NoError(t, err)
+		assert.Equal(t, domain.BackfillBatch{
+			Scanned: 1, Inserted: 1, Last: domain.BackfillWatermark{PullRequestID: "pr2", UserID: "u3"},
+		}, batch)
+
+		batch, err = repo.BackfillAssignedEvents(ctx, batch.Last, 2)
+		require.NoError(t, err)
+		assert.Equal(t, domain.BackfillBatch{Last: domain.BackfillWatermark{PullRequestID: "pr2", UserID: "u3"}}, batch)
+		assert.Equal(t, 5, countEvents())
+	})
+
+	t.Run("events keep assignment time and source", func(t *testing.T) {
+		events, err := repo.GetUserReviewTimeline(ctx, "u3", nil, nil, domain.Page{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, "pr2", events[0].PullRequestID)
+		assert.Equal(t, base.Add(3*time.Hour), events[0].CreatedAt.UTC())
+		assert.Equal(t, domain.AssignmentSourceManual, events[0].Source)
+		assert.Equal(t, "pr1", events[1].PullRequestID)
+	})
+
+	t.Run("rerun is idempotent", func(t *testing.T) {
+		batch, err := repo.BackfillAssignedEvents(ctx, domain.BackfillWatermark{}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 5, batch.Scanned)
+		assert.Zero(t, batch.Inserted)
+		assert.Equal(t, 5, countEvents())
+	})
+
+	t.Run("watermark", func(t *testing.T) {
+		txManager, err := db.NewTransactionManager(pool)
+		require.NoError(t, err)
+
+		err = txManager.Do(ctx, func(txCtx context.Context) error {
+			watermark, err := repo.LockBackfillWatermark(txCtx, "reviewer_history")
+			require.NoError(t, err)
+			assert.Equal(t, domain.BackfillWatermark{}, watermark)
+			return repo.SaveBackfillWatermark(txCtx, "reviewer_history", domain.BackfillWatermark{PullRequestID: "pr1", UserID: "u2"})
+		})
+		require.NoError(t, err)
+
+		watermark, err := repo.LockBackfillWatermark(ctx, "reviewer_history")
+		require.NoError(t, err)
+		assert.Equal(t, domain.BackfillWatermark{PullRequestID: "pr1", UserID: "u2"}, watermark)
+	})
+}
+
+func TestPullRequestRepository_MergeStoresMergedBy(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "reviewer")
+	seedPR(t, pool, "by-author", "author", time.Now())
+	seedPR(t, pool, "anonymous", "author", time.Now())
+
+	_, err := repo.MergePullRequest(ctx, "by-author", 2, "author")
+	require.NoError(t, err)
+	_, err = repo.MergePullRequest(ctx, "anonymous", 2, "")
+	require.NoError(t, err)
+	// повторный merge не перезаписывает merged_by
+	_, err = repo.MergePullRequest(ctx, "by-author", 2, "reviewer")
+	require.NoError(t, err)
+
+	prs, err := repo.GetPullRequestsByIDs(ctx, []string{"by-author", "anonymous"})
+	require.NoError(t, err)
+	require.Len(t, prs, 2)
+	assert.Equal(t, "author", prs[0].MergedBy)
+	assert.Empty(t, prs[1].MergedBy)
+}
+
+func TestPullRequestRepository_GetRecentPullRequests(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	clk := clock.NewFake(time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC))
+	seedTeam(t, pool, "backend", "u1", "u2")
+	seedTeam(t, pool, "frontend", "u3")
+	for _, pr := range []struct{ id, author string }{
+		{"pr1", "u1"}, {"pr2", "u3"}, {"pr3", "u1"}, {"pr4", "u2"},
+	} {
+		seedPR(t, pool, pr.id, pr.author, clk.Now())
+		clk.Advance(time.Minute)
+	}
+	// у pr5 тот же created_at, что у pr4: при равенстве первым идёт больший pull_request_id
+	seedPR(t, pool, "pr5", "u3", clk.Now().Add(-time.Minute))
+	mustExec(t, pool, `UPDATE pull_requests SET status = 'MERGED' WHERE pull_request_id = 'pr3'`)
+	mustExec(t, pool, `INSERT INTO pr_reviewers (pull_request_id, user_id) VALUES ('pr4', 'u1'), ('pr3', 'u2'), ('pr3', 'u3')`)
+
+	recent, err := repo.GetRecentPullRequests(ctx, 3)
+	require.NoError(t, err)
+
+	ids := make([]string, len(recent))
+	for i, pr := range recent {
+		ids[i] = pr.PullRequestID
+	}
+	assert.Equal(t, []string{"pr5", "pr4", "pr3"}, ids)
+	assert.Equal(t, "u2", recent[1].AuthorName)
+	assert.Equal(t, 1, recent[1].ReviewerCount)
+	assert.Equal(t, time.Date(2025, 10, 1, 12, 3, 0, 0, time.UTC), recent[1].CreatedAt.UTC())
+	assert.Equal(t, domain.PRStatusMerged, recent[2].Status)
+	assert.Equal(t, 2, recent[2].ReviewerCount)
+	assert.Equal(t, 0, recent[0].ReviewerCount)
+
+	all, err := repo.GetRecentPullRequests(ctx, 100)
+	require.NoError(t, err)
+	assert.Len(t, all, 5)
+	assert.Equal(t, "pr1", all[4].PullRequestID)
+}
+
+func TestPullRequestRepository_GetReviewerAnomalies(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewPullRequestRepository(database)
+	ctx := context.Background()
+	now := time.Now()
+
+	report, err := repo.GetReviewerAnomalies(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AnomalyReport{
+		AuthorIsReviewer: []domain.ReviewerAnomaly{}, CrossTeamReviewer: []domain.ReviewerAnomaly{}, UnknownReviewer: []domain.ReviewerAnomaly{},
+	}, report)
+
+	seedTeam(t, pool, "backend", "u1", "u2", "u4")
+	seedTeam(t, pool, "frontend", "u3")
+	mustExec(t, pool, "UPDATE users SET is_active = FALSE, removed_at = NOW() WHERE user_id = 'u4'")
+	for _, prID := range []string{"pr1", "pr2", "pr3", "pr4", "pr5"} {
+		seedPR(t, pool, prID, "u1", now)
+	}
+	mustExec(t, pool, "UPDATE pull_requests SET status = 'MERGED' WHERE pull_request_id = 'pr4'")
+	// ссылку на несуществующего пользователя можно получить только в обход внешнего ключа
+	mustExec(t, pool, "ALTER TABLE pr_reviewers DROP CONSTRAINT pr_reviewers_user_id_fkey")
+	mustExec(t, pool, `
+		INSERT INTO pr_reviewers (pull_request_id, user_id) VALUES
+			('pr1', 'u1'), ('pr1', 'u2'),
+			('pr2', 'u3'),
+			('pr3', 'u4'),
+			('pr4', 'u4'),
+			('pr5', 'ghost')
+	`)
+
+	report, err = repo.GetReviewerAnomalies(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []domain.ReviewerAnomaly{
+		{PullRequestID: "pr1", UserID: "u1", AuthorTeam: "backend", ReviewerTeam: "backend"},
+	}, report.AuthorIsReviewer)
+	assert.Equal(t, []domain.ReviewerAnomaly{
+		{PullRequestID: "pr2", UserID: "u3", AuthorTeam: "backend", ReviewerTeam: "frontend"},
+	}, report.CrossTeamReviewer)
+	// удалённый u4 на смерженном pr4 остаётся в истории и не считается аномалией
+	assert.Equal(t, []domain.ReviewerAnomaly{
+		{PullRequestID: "pr3", UserID: "u4", AuthorTeam: "backend", ReviewerTeam: "backend"},
+		{PullRequestID: "pr5", UserID: "ghost", AuthorTeam: "backend"},
+	}, report.UnknownReviewer)
+}
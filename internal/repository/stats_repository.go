@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/pkg/db"
+)
+
+type StatsRepository struct {
+	db *db.DB
+}
+
+func NewStatsRepository(db *db.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// GetGlobalStats считает все агрегаты одним запросом, чтобы они были согласованы между собой
+func (r *StatsRepository) GetGlobalStats(ctx context.Context) (*domain.GlobalStats, error) {
+	conn := r.db.Conn(ctx)
+
+	var stats domain.GlobalStats
+	err := conn.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM teams),
+			(SELECT COUNT(*) FROM users WHERE is_active AND removed_at IS NULL),
+			(SELECT COUNT(*) FROM users WHERE NOT is_active AND removed_at IS NULL),
+			(SELECT COUNT(*) FROM pull_requests WHERE status = $1),
+			(SELECT COUNT(*) FROM pull_requests WHERE status = $2),
+			(SELECT COALESCE(AVG(cnt), 0)::float8 FROM (
+				SELECT COUNT(rv.user_id) AS cnt
+				FROM pull_requests pr
+				LEFT JOIN pr_reviewers rv ON rv.pull_request_id = pr.pull_request_id
+				WHERE pr.status = $1
+				GROUP BY pr.pull_request_id
+			) per_pr)
+	`, domain.PRStatusOpen, domain.PRStatusMerged).Scan(
+		&stats.Teams, &stats.ActiveUsers, &stats.InactiveUsers,
+		&stats.OpenPRs, &stats.MergedPRs, &stats.AvgReviewersPerOpenPR,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query global stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// CountPullRequestsByAuthor считает PR участников команды, созданные не раньше since (nil - за всё время).
+// Авторы без PR в выдачу не попадают, limit ограничивает число авторов
+func (r *StatsRepository) CountPullRequestsByAuthor(
+	ctx context.Context,
+	teamName string,
+	since *time.Time,
+	order domain.AuthorPRCountOrder,
+	limit int,
+) ([]domain.AuthorPRCount, error) {
+	orderBy := "COUNT(*) DESC, u.user_id"
+	if order == domain.AuthorPRCountOrderUsername {
+		orderBy = "u.username, u.user_id"
+	}
+
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT u.user_id, u.username, COUNT(*)
+		FROM pull_requests pr
+		JOIN users u ON u.user_id = pr.author_id
+		WHERE u.team_name = $1
+		  AND ($2::timestamptz IS NULL OR pr.created_at >= $2)
+		GROUP BY u.user_id, u.username
+		ORDER BY `+orderBy+`
+		LIMIT $3
+	`, teamName, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pull requests by author: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []domain.AuthorPRCount
+	for rows.Next() {
+		var count domain.AuthorPRCount
+		if err := rows.Scan(&count.UserID, &count.Username, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan pull requests by author: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
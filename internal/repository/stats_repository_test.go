@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+)
+
+func TestStatsRepository_GetGlobalStats(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewStatsRepository(database)
+	prs := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	t.Run("empty database", func(t *testing.T) {
+		stats, err := repo.GetGlobalStats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, domain.GlobalStats{}, *stats)
+	})
+
+	seedTeam(t, pool, "backend", "a1", "r1", "r2", "idle")
+	seedTeam(t, pool, "frontend", "a2", "gone")
+	mustExec(t, pool, "UPDATE users SET is_active = FALSE WHERE user_id = 'idle'")
+	mustExec(t, pool, "UPDATE users SET is_active = FALSE, removed_at = NOW() WHERE user_id = 'gone'")
+
+	// открытые PR с 2, 1 и 0 ревьюерами, смерженный PR в среднее не входит
+	for _, prID := range []string{"two", "one", "none", "merged"} {
+		seedPR(t, pool, prID, "a1", time.Now())
+	}
+	require.NoError(t, prs.AssignReviewer(ctx, "two", "r1", domain.AssignmentSourceAuto))
+	require.NoError(t, prs.AssignReviewer(ctx, "two", "r2", domain.AssignmentSourceAuto))
+	require.NoError(t, prs.AssignReviewer(ctx, "one", "r1", domain.AssignmentSourceAuto))
+	require.NoError(t, prs.AssignReviewer(ctx, "merged", "r2", domain.AssignmentSourceAuto))
+	_, err := prs.MergePullRequest(ctx, "merged", 2, "")
+	require.NoError(t, err)
+
+	stats, err := repo.GetGlobalStats(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, stats.Teams)
+	assert.Equal(t, 4, stats.ActiveUsers)
+	assert.Equal(t, 1, stats.InactiveUsers)
+	assert.Equal(t, 3, stats.OpenPRs)
+	assert.Equal(t, 1, stats.MergedPRs)
+	assert.InDelta(t, 1.0, stats.AvgReviewersPerOpenPR, 1e-9)
+}
+
+func TestStatsRepository_CountPullRequestsByAuthor(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewStatsRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "a1", "a2", "a3", "quiet")
+	seedTeam(t, pool, "frontend", "f1")
+	mustExec(t, pool, "UPDATE users SET username = 'zoe' WHERE user_id = 'a1'")
+	mustExec(t, pool, "UPDATE users SET username = 'adam' WHERE user_id = 'a2'")
+	mustExec(t, pool, "UPDATE users SET username = 'mia' WHERE user_id = 'a3'")
+
+	now := time.Now()
+	old := now.Add(-30 * 24 * time.Hour)
+	// a1: 3 свежих, a2: 1 свежий и 2 старых, a3: 1 свежий, f1 из другой команды
+	for i, author := range []string{"a1", "a1", "a1", "a2", "a3", "f1", "f1", "f1", "f1"} {
+		seedPR(t, pool, fmt.Sprintf("new-%d", i), author, now)
+	}
+	seedPR(t, pool, "old-1", "a2", old)
+	seedPR(t, pool, "old-2", "a2", old)
+
+	t.Run("all time by count", func(t *testing.T) {
+		counts, err := repo.CountPullRequestsByAuthor(ctx, "backend", nil, domain.AuthorPRCountOrderCount, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.AuthorPRCount{
+			{UserID: "a1", Username: "zoe", Count: 3},
+			{UserID: "a2", Username: "adam", Count: 3},
+			{UserID: "a3", Username: "mia", Count: 1},
+		}, counts)
+	})
+
+	t.Run("since", func(t *testing.T) {
+		since := now.Add(-time.Hour)
+		counts, err := repo.CountPullRequestsByAuthor(ctx, "backend", &since, domain.AuthorPRCountOrderCount, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.AuthorPRCount{
+			{UserID: "a1", Username: "zoe", Count: 3},
+			{UserID: "a2", Username: "adam", Count: 1},
+			{UserID: "a3", Username: "mia", Count: 1},
+		}, counts)
+	})
+
+	t.Run("by username with limit", func(t *testing.T) {
+		counts, err := repo.CountPullRequestsByAuthor(ctx, "backend", nil, domain.AuthorPRCountOrderUsername, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.AuthorPRCount{
+			{UserID: "a2", Username: "adam", Count: 3},
+			{UserID: "a3", Username: "mia", Count: 1},
+		}, counts)
+	})
+
+	t.Run("unknown team", func(t *testing.T) {
+		counts, err := repo.CountPullRequestsByAuthor(ctx, "missing", nil, domain.AuthorPRCountOrderCount, 10)
+		require.NoError(t, err)
+		assert.Empty(t, counts)
+	})
+}
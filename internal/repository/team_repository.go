@@ -2,7 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 
 	"avito_backend_task/internal/domain"
 	"avito_backend_task/pkg/db"
@@ -21,7 +25,7 @@ func (r *TeamRepository) Create(ctx context.Context, teamName string) error {
 
 	_, err := conn.Exec(ctx, "INSERT INTO teams (team_name) VALUES ($1)", teamName)
 	if err != nil {
-		return fmt.Errorf("failed to insert team: %w", err)
+		return fmt.Errorf("failed to insert team: %w", HandleDBError(err))
 	}
 
 	return nil
@@ -50,7 +54,7 @@ func (r *TeamRepository) GetTeamByName(ctx context.Context, teamName string) (*d
 	rows, err := conn.Query(ctx, `
 		SELECT user_id, username, is_active
 		FROM users
-		WHERE team_name = $1
+		WHERE team_name = $1 AND removed_at IS NULL
 	`, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query team members: %w", err)
@@ -70,8 +74,366 @@ func (r *TeamRepository) GetTeamByName(ctx context.Context, teamName string) (*d
 		return nil, fmt.Errorf("error iterating team members: %w", err)
 	}
 
+	blackouts, err := r.GetUpcomingBlackouts(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
 	return &domain.Team{
-		TeamName: teamName,
-		Members:  members,
+		TeamName:  teamName,
+		Members:   members,
+		Blackouts: blackouts,
 	}, nil
 }
+
+// CountMembers - число участников команды без удалённых через RemoveFromTeam
+func (r *TeamRepository) CountMembers(ctx context.Context, teamName string) (int, error) {
+	conn := r.db.Conn(ctx)
+
+	var count int
+	err := conn.QueryRow(ctx, `
+		SELECT COUNT(*) FROM users WHERE team_name = $1 AND removed_at IS NULL
+	`, teamName).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count team members: %w", err)
+	}
+	return count, nil
+}
+
+// LockBlackouts сериализует изменения окон одной команды до конца транзакции,
+// чтобы проверка пересечений и вставка не гонялись
+func (r *TeamRepository) LockBlackouts(ctx context.Context, teamName string) error {
+	if err := r.db.AdvisoryXactLock(ctx, "team-blackouts:"+teamName); err != nil {
+		return fmt.Errorf("failed to lock team blackouts: %w", err)
+	}
+	return nil
+}
+
+func (r *TeamRepository) CreateBlackout(ctx context.Context, blackout domain.TeamBlackout) (*domain.TeamBlackout, error) {
+	conn := r.db.Conn(ctx)
+
+	err := conn.QueryRow(ctx, `
+		INSERT INTO team_blackouts (team_name, starts_at, ends_at, reason)
+		VALUES ($1, $2, $3, $4)
+		RETURNING blackout_id
+	`, blackout.TeamName, blackout.StartsAt, blackout.EndsAt, blackout.Reason).Scan(&blackout.BlackoutID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert blackout: %w", HandleDBError(err))
+	}
+
+	return &blackout, nil
+}
+
+// HasOverlappingBlackout - есть ли у команды окно, пересекающее [startsAt, endsAt).
+// Смежные окна (конец одного равен началу другого) не пересекаются
+func (r *TeamRepository) HasOverlappingBlackout(ctx context.Context, teamName string, startsAt, endsAt time.Time) (bool, error) {
+	conn := r.db.Conn(ctx)
+
+	var exists bool
+	err := conn.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM team_blackouts
+			WHERE team_name = $1 AND starts_at < $3 AND ends_at > $2
+		)
+	`, teamName, startsAt, endsAt).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check blackout overlap: %w", err)
+	}
+	return exists, nil
+}
+
+// DeleteBlackout возвращает ErrNotFound, если у команды нет окна с таким id
+func (r *TeamRepository) DeleteBlackout(ctx context.Context, teamName string, blackoutID int64) error {
+	conn := r.db.Conn(ctx)
+
+	tag, err := conn.Exec(ctx, `
+		DELETE FROM team_blackouts WHERE team_name = $1 AND blackout_id = $2
+	`, teamName, blackoutID)
+	if err != nil {
+		return fmt.Errorf("failed to delete blackout: %w", HandleDBError(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetUpcomingBlackouts - текущие и будущие окна команды по времени начала
+func (r *TeamRepository) GetUpcomingBlackouts(ctx context.Context, teamName string) ([]domain.TeamBlackout, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT blackout_id, team_name, starts_at, ends_at, reason
+		FROM team_blackouts
+		WHERE team_name = $1 AND ends_at > NOW()
+		ORDER BY starts_at
+	`, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blackouts: %w", err)
+	}
+
+	blackouts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.TeamBlackout, error) {
+		var b domain.TeamBlackout
+		err := row.Scan(&b.BlackoutID, &b.TeamName, &b.StartsAt, &b.EndsAt, &b.Reason)
+		return b, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan blackout: %w", err)
+	}
+	return blackouts, nil
+}
+
+// IsInBlackout - действует ли у команды окно в момент at
+func (r *TeamRepository) IsInBlackout(ctx context.Context, teamName string, at time.Time) (bool, error) {
+	conn := r.db.Conn(ctx)
+
+	var exists bool
+	err := conn.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM team_blackouts
+			WHERE team_name = $1 AND starts_at <= $2 AND ends_at > $2
+		)
+	`, teamName, at).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check blackout: %w", err)
+	}
+	return exists, nil
+}
+
+// prAgeReportExamples - сколько PR корзины попадает в примеры
+const prAgeReportExamples = 5
+
+// GetOpenPRAgeReport считает открытые PR команды по корзинам возраста на момент now одним запросом.
+// width_bucket по границам 1, 3 и 7 дней даёт номер корзины 0..3 в порядке domain.PRAgeBucketLabels
+func (r *TeamRepository) GetOpenPRAgeReport(ctx context.Context, teamName string, now time.Time) (*domain.TeamAgeReport, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		WITH open_prs AS (
+			SELECT pr.pull_request_id, EXTRACT(EPOCH FROM $2::timestamptz - pr.created_at)::float8 AS age
+			FROM pull_requests pr
+			INNER JOIN users u ON u.user_id = pr.author_id
+			WHERE u.team_name = $1 AND pr.status = $3
+		)
+		SELECT width_bucket(age, ARRAY[86400, 259200, 604800]::float8[]) AS bucket,
+			COUNT(*),
+			(array_agg(pull_request_id ORDER BY age DESC, pull_request_id))[1:$4::int],
+			MAX(age)
+		FROM open_prs
+		GROUP BY bucket
+		ORDER BY bucket
+	`, teamName, now, domain.PRStatusOpen, prAgeReportExamples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query PR age report: %w", err)
+	}
+	defer rows.Close()
+
+	report := &domain.TeamAgeReport{
+		TeamName: teamName,
+		Buckets:  make([]domain.PRAgeBucket, len(domain.PRAgeBucketLabels)),
+	}
+	for i, label := range domain.PRAgeBucketLabels {
+		report.Buckets[i] = domain.PRAgeBucket{Label: label, ExamplePRIDs: []string{}}
+	}
+
+	for rows.Next() {
+		var (
+			bucket   int
+			count    int
+			examples []string
+			maxAge   float64
+		)
+		if err := rows.Scan(&bucket, &count, &examples, &maxAge); err != nil {
+			return nil, fmt.Errorf("failed to scan PR age bucket: %w", err)
+		}
+
+		report.Buckets[bucket].Count = count
+		report.Buckets[bucket].ExamplePRIDs = examples
+		// корзины идут по возрастанию, самый старый PR - первый пример последней непустой
+		report.OldestPRID = examples[0]
+		report.OldestAge = time.Duration(maxAge * float64(time.Second))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating PR age buckets: %w", err)
+	}
+
+	return report, nil
+}
+
+// GetTeamsMissingFromUsers - team_name из users, для которых нет строки teams (расхождение возможно
+// только в обход внешнего ключа, например при восстановлении дампа), по имени вместе с их участниками
+func (r *TeamRepository) GetTeamsMissingFromUsers(ctx context.Context) ([]domain.ReconciledTeam, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT u.team_name, array_agg(u.user_id ORDER BY u.user_id)
+		FROM users u
+		LEFT JOIN teams t ON t.team_name = u.team_name
+		WHERE t.team_name IS NULL
+		GROUP BY u.team_name
+		ORDER BY u.team_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query missing teams: %w", HandleDBError(err))
+	}
+
+	teams, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ReconciledTeam, error) {
+		var team domain.ReconciledTeam
+		err := row.Scan(&team.TeamName, &team.UserIDs)
+		return team, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan missing teams: %w", HandleDBError(err))
+	}
+
+	return teams, nil
+}
+
+// CreateMissingTeams создаёт команды teamNames и возвращает созданные; уже существующие пропускаются
+func (r *TeamRepository) CreateMissingTeams(ctx context.Context, teamNames []string) ([]string, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		INSERT INTO teams (team_name)
+		SELECT unnest($1::varchar[])
+		ON CONFLICT (team_name) DO NOTHING
+		RETURNING team_name
+	`, teamNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create missing teams: %w", HandleDBError(err))
+	}
+
+	created, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan created teams: %w", HandleDBError(err))
+	}
+
+	return created, nil
+}
+
+// CreateWebhook возвращает ErrAlreadyExists, если у команды уже есть webhook
+func (r *TeamRepository) CreateWebhook(ctx context.Context, webhook domain.TeamWebhook) (*domain.TeamWebhook, error) {
+	conn := r.db.Conn(ctx)
+
+	err := conn.QueryRow(ctx, `
+		INSERT INTO team_webhooks (team_name, url, secret, enabled)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at
+	`, webhook.TeamName, webhook.URL, webhook.Secret, webhook.Enabled).Scan(&webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert webhook: %w", HandleDBError(err))
+	}
+
+	return &webhook, nil
+}
+
+const teamWebhookColumns = "team_name, url, secret, enabled, created_at, updated_at"
+
+func scanTeamWebhook(row pgx.Row) (*domain.TeamWebhook, error) {
+	var w domain.TeamWebhook
+	if err := row.Scan(&w.TeamName, &w.URL, &w.Secret, &w.Enabled, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// GetWebhook возвращает ErrNotFound, если у команды нет webhook
+func (r *TeamRepository) GetWebhook(ctx context.Context, teamName string) (*domain.TeamWebhook, error) {
+	conn := r.db.Conn(ctx)
+
+	webhook, err := scanTeamWebhook(conn.QueryRow(ctx,
+		"SELECT "+teamWebhookColumns+" FROM team_webhooks WHERE team_name = $1", teamName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", HandleDBError(err))
+	}
+	return webhook, nil
+}
+
+// UpdateWebhook меняет только заданные поля, ErrNotFound - у команды нет webhook
+func (r *TeamRepository) UpdateWebhook(ctx context.Context, update domain.TeamWebhookUpdate) (*domain.TeamWebhook, error) {
+	conn := r.db.Conn(ctx)
+
+	webhook, err := scanTeamWebhook(conn.QueryRow(ctx, `
+		UPDATE team_webhooks
+		SET url = COALESCE($2, url),
+		    secret = COALESCE($3, secret),
+		    enabled = COALESCE($4, enabled),
+		    updated_at = NOW()
+		WHERE team_name = $1
+		RETURNING `+teamWebhookColumns,
+		update.TeamName, update.URL, update.Secret, update.Enabled))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", HandleDBError(err))
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook возвращает ErrNotFound, если у команды нет webhook
+func (r *TeamRepository) DeleteWebhook(ctx context.Context, teamName string) error {
+	conn := r.db.Conn(ctx)
+
+	tag, err := conn.Exec(ctx, "DELETE FROM team_webhooks WHERE team_name = $1", teamName)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", HandleDBError(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetAuthorTeamWebhook - включённый webhook команды автора, nil без ошибки - webhook нет или он выключен
+func (r *TeamRepository) GetAuthorTeamWebhook(ctx context.Context, authorID string) (*domain.TeamWebhook, error) {
+	conn := r.db.Conn(ctx)
+
+	webhook, err := scanTeamWebhook(conn.QueryRow(ctx, `
+		SELECT w.team_name, w.url, w.secret, w.enabled, w.created_at, w.updated_at
+		FROM team_webhooks w
+		JOIN users u ON u.team_name = w.team_name
+		WHERE u.user_id = $1 AND w.enabled
+	`, authorID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get author team webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// RecordWebhookDelivery сохраняет итог доставки: пустой deliveryErr - успех, он сбрасывает счётчик ошибок подряд.
+// Webhook, удалённый во время доставки, пропускается
+func (r *TeamRepository) RecordWebhookDelivery(ctx context.Context, teamName string, at time.Time, deliveryErr string) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		UPDATE team_webhooks
+		SET last_delivered_at = CASE WHEN $3::text = '' THEN $2::timestamptz ELSE last_delivered_at END,
+		    last_failed_at = CASE WHEN $3::text = '' THEN last_failed_at ELSE $2::timestamptz END,
+		    last_error = CASE WHEN $3::text = '' THEN last_error ELSE $3::text END,
+		    consecutive_failures = CASE WHEN $3::text = '' THEN 0 ELSE consecutive_failures + 1 END
+		WHERE team_name = $1
+	`, teamName, at, deliveryErr)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", HandleDBError(err))
+	}
+	return nil
+}
+
+// GetWebhookStatus возвращает ErrNotFound, если у команды нет webhook
+func (r *TeamRepository) GetWebhookStatus(ctx context.Context, teamName string) (*domain.TeamWebhookStatus, error) {
+	conn := r.db.Conn(ctx)
+
+	var status domain.TeamWebhookStatus
+	err := conn.QueryRow(ctx, `
+		SELECT team_name, enabled, last_delivered_at, last_failed_at, last_error, consecutive_failures
+		FROM team_webhooks
+		WHERE team_name = $1
+	`, teamName).Scan(&status.TeamName, &status.Enabled, &status.LastDeliveredAt, &status.LastFailedAt,
+		&status.LastError, &status.ConsecutiveFailures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook status: %w", HandleDBError(err))
+	}
+	return &status, nil
+}
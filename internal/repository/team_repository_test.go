@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+)
+
+func TestTeamRepository_Blackouts(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewTeamRepository(database)
+	prs := NewPullRequestRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "a1")
+	seedTeam(t, pool, "frontend", "a2")
+
+	now := time.Now().UTC().Truncate(time.Second)
+	current, err := repo.CreateBlackout(ctx, domain.TeamBlackout{
+		TeamName: "backend", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), Reason: "freeze",
+	})
+	require.NoError(t, err)
+	_, err = repo.CreateBlackout(ctx, domain.TeamBlackout{
+		TeamName: "backend", StartsAt: now.Add(-48 * time.Hour), EndsAt: now.Add(-24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	t.Run("overlap", func(t *testing.T) {
+		overlaps, err := repo.HasOverlappingBlackout(ctx, "backend", now, now.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.True(t, overlaps)
+
+		// смежное окно не пересекается
+		overlaps, err = repo.HasOverlappingBlackout(ctx, "backend", now.Add(time.Hour), now.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.False(t, overlaps)
+
+		overlaps, err = repo.HasOverlappingBlackout(ctx, "frontend", now, now.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.False(t, overlaps)
+	})
+
+	t.Run("in blackout", func(t *testing.T) {
+		in, err := repo.IsInBlackout(ctx, "backend", now)
+		require.NoError(t, err)
+		assert.True(t, in)
+
+		in, err = repo.IsInBlackout(ctx, "backend", now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.False(t, in)
+	})
+
+	t.Run("team lists only upcoming", func(t *testing.T) {
+		team, err := repo.GetTeamByName(ctx, "backend")
+		require.NoError(t, err)
+		require.Len(t, team.Blackouts, 1)
+		assert.Equal(t, current.BlackoutID, team.Blackouts[0].BlackoutID)
+		assert.Equal(t, "freeze", team.Blackouts[0].Reason)
+	})
+
+	t.Run("pending assignment", func(t *testing.T) {
+		_, err := prs.CreatePullRequest(ctx, domain.PullRequestCreate{
+			PullRequestID: "pr1", PullRequestName: "pr1", AuthorID: "a1", PendingAssignment: true,
+		})
+		require.NoError(t, err)
+
+		pending, err := prs.GetPendingAssignmentPRs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pr1"}, pending)
+
+		require.NoError(t, prs.ClearPendingAssignment(ctx, "pr1"))
+		pr, err := prs.GetPullRequestByID(ctx, "pr1")
+		require.NoError(t, err)
+		assert.False(t, pr.PendingAssignment)
+
+		require.NoError(t, prs.MarkPendingAssignment(ctx, "pr1"))
+		pr, err = prs.GetPullRequestByID(ctx, "pr1")
+		require.NoError(t, err)
+		assert.True(t, pr.PendingAssignment)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.ErrorIs(t, repo.DeleteBlackout(ctx, "frontend", current.BlackoutID), ErrNotFound)
+		require.NoError(t, repo.DeleteBlackout(ctx, "backend", current.BlackoutID))
+		require.ErrorIs(t, repo.DeleteBlackout(ctx, "backend", current.BlackoutID), ErrNotFound)
+	})
+}
+
+func TestTeamRepository_Webhooks(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewTeamRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "a1")
+	seedTeam(t, pool, "frontend", "a2")
+
+	created, err := repo.CreateWebhook(ctx, domain.TeamWebhook{
+		TeamName: "backend", URL: "https://hooks.local/backend", Secret: "s3cret-s3cret-s3cret", Enabled: true,
+	})
+	require.NoError(t, err)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	_, err = repo.CreateWebhook(ctx, domain.TeamWebhook{TeamName: "backend", URL: "https://other.local", Secret: "x"})
+	require.ErrorIs(t, err, ErrAlreadyExists)
+
+	t.Run("author lookup", func(t *testing.T) {
+		webhook, err := repo.GetAuthorTeamWebhook(ctx, "a1")
+		require.NoError(t, err)
+		require.NotNil(t, webhook)
+		assert.Equal(t, "https://hooks.local/backend", webhook.URL)
+		assert.Equal(t, "s3cret-s3cret-s3cret", webhook.Secret)
+
+		webhook, err = repo.GetAuthorTeamWebhook(ctx, "a2")
+		require.NoError(t, err)
+		assert.Nil(t, webhook)
+	})
+
+	t.Run("partial update", func(t *testing.T) {
+		disabled := false
+		updated, err := repo.UpdateWebhook(ctx, domain.TeamWebhookUpdate{TeamName: "backend", Enabled: &disabled})
+		require.NoError(t, err)
+		assert.False(t, updated.Enabled)
+		assert.Equal(t, "https://hooks.local/backend", updated.URL)
+
+		// выключенный webhook не доставляется
+		webhook, err := repo.GetAuthorTeamWebhook(ctx, "a1")
+		require.NoError(t, err)
+		assert.Nil(t, webhook)
+
+		_, err = repo.UpdateWebhook(ctx, domain.TeamWebhookUpdate{TeamName: "frontend", Enabled: &disabled})
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("delivery status", func(t *testing.T) {
+		at := time.Now().UTC().Truncate(time.Second)
+		require.NoError(t, repo.RecordWebhookDelivery(ctx, "backend", at, "status 500"))
+		require.NoError(t, repo.RecordWebhookDelivery(ctx, "backend", at.Add(time.Minute), "status 502"))
+
+		status, err := repo.GetWebhookStatus(ctx, "backend")
+		require.NoError(t, err)
+		assert.Nil(t, status.LastDeliveredAt)
+		require.NotNil(t, status.LastFailedAt)
+		assert.True(t, at.Add(time.Minute).Equal(*status.LastFailedAt))
+		assert.Equal(t, "status 502", status.LastError)
+		assert.Equal(t, 2, status.ConsecutiveFailures)
+
+		require.NoError(t, repo.RecordWebhookDelivery(ctx, "backend", at.Add(2*time.Minute), ""))
+		status, err = repo.GetWebhookStatus(ctx, "backend")
+		require.NoError(t, err)
+		require.NotNil(t, status.LastDeliveredAt)
+		assert.True(t, at.Add(2*time.Minute).Equal(*status.LastDeliveredAt))
+		assert.Equal(t, "status 502", status.LastError)
+		assert.Zero(t, status.ConsecutiveFailures)
+
+		_, err = repo.GetWebhookStatus(ctx, "frontend")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, repo.DeleteWebhook(ctx, "backend"))
+		require.ErrorIs(t, repo.DeleteWebhook(ctx, "backend"), ErrNotFound)
+		_, err := repo.GetWebhook(ctx, "backend")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestTeamRepository_CountMembers(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewTeamRepository(database)
+	users := NewUserRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "u1", "u2", "u3")
+	seedTeam(t, pool, "frontend", "u4")
+	_, err := users.RemoveFromTeam(ctx, "u3")
+	require.NoError(t, err)
+
+	count, err := repo.CountMembers(ctx, "backend")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = repo.CountMembers(ctx, "missing")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestTeamRepository_GetOpenPRAgeReport(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewTeamRepository(database)
+	ctx := context.Background()
+
+	now := time.Date(2025, 11, 10, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	seedTeam(t, pool, "backend", "a1", "a2")
+	seedTeam(t, pool, "frontend", "f1")
+
+	t.Run("no open PRs", func(t *testing.T) {
+		report, err := repo.GetOpenPRAgeReport(ctx, "backend", now)
+		require.NoError(t, err)
+		require.Len(t, report.Buckets, 4)
+		for _, b := range report.Buckets {
+			assert.Zero(t, b.Count)
+			assert.Empty(t, b.ExamplePRIDs)
+		}
+		assert.Empty(t, report.OldestPRID)
+	})
+
+	seedPR(t, pool, "fresh", "a1", now.Add(-time.Hour))
+	seedPR(t, pool, "one-day", "a2", now.Add(-day))
+	seedPR(t, pool, "two-days", "a1", now.Add(-2*day))
+	seedPR(t, pool, "five-days", "a1", now.Add(-5*day))
+	seedPR(t, pool, "ten-days", "a2", now.Add(-10*day))
+	seedPR(t, pool, "merged", "a1", now.Add(-20*day))
+	mustExec(t, pool, "UPDATE pull_requests SET status = 'MERGED' WHERE pull_request_id = 'merged'")
+	seedPR(t, pool, "other-team", "f1", now.Add(-30*day))
+	for i := 0; i < 6; i++ {
+		seedPR(t, pool, "old-"+string(rune('a'+i)), "a1", now.Add(-8*day-time.Duration(i)*time.Hour))
+	}
+
+	report, err := repo.GetOpenPRAgeReport(ctx, "backend", now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []domain.PRAgeBucket{
+		{Label: "lt_1d", Count: 1, ExamplePRIDs: []string{"fresh"}},
+		// ровно сутки попадают в следующую корзину
+		{Label: "1d_3d", Count: 2, ExamplePRIDs: []string{"two-days", "one-day"}},
+		{Label: "3d_7d", Count: 1, ExamplePRIDs: []string{"five-days"}},
+		{Label: "gt_7d", Count: 7, ExamplePRIDs: []string{"ten-days", "old-f", "old-e", "old-d", "old-c"}},
+	}, report.Buckets)
+	assert.Equal(t, "ten-days", report.OldestPRID)
+	assert.Equal(t, 10*day, report.OldestAge)
+}
+
+func TestTeamRepository_MissingTeams(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewTeamRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "b1")
+	// расхождение возможно только без внешнего ключа, например после восстановления дампа
+	mustExec(t, pool, "ALTER TABLE users DROP CONSTRAINT users_team_name_fkey")
+	mustExec(t, pool, `
+		INSERT INTO users (user_id, username, team_name) VALUES
+			('o2', 'o2', 'ghost'), ('o1', 'o1', 'ghost'), ('o3', 'o3', 'archive')
+	`)
+
+	missing, err := repo.GetTeamsMissingFromUsers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ReconciledTeam{
+		{TeamName: "archive", UserIDs: []string{"o3"}},
+		{TeamName: "ghost", UserIDs: []string{"o1", "o2"}},
+	}, missing)
+
+	// backend уже существует и пропускается
+	created, err := repo.CreateMissingTeams(ctx, []string{"archive", "backend", "ghost"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"archive", "ghost"}, created)
+
+	for _, teamName := range []string{"backend", "ghost", "archive"} {
+		exists, err := repo.Exists(ctx, teamName)
+		require.NoError(t, err)
+		assert.True(t, exists, teamName)
+	}
+
+	team, err := repo.GetTeamByName(ctx, "ghost")
+	require.NoError(t, err)
+	assert.Len(t, team.Members, 2)
+
+	// после создания расхождений нет
+	missing, err = repo.GetTeamsMissingFromUsers(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
@@ -2,18 +2,44 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
+
 	"avito_backend_task/internal/domain"
 	"avito_backend_task/pkg/db"
 )
 
+const userColumns = `user_id, username, team_name, is_active,
+	COALESCE(working_hours, ''), COALESCE(timezone, ''), review_capacity`
+
+func scanUser(row pgx.Row, user *domain.User) error {
+	return row.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.WorkingHours, &user.Timezone, &user.ReviewCapacity)
+}
+
 type UserRepository struct {
-	db *db.DB
+	db              *db.DB
+	sampleThreshold int
+}
+
+type UserRepositoryOption func(*UserRepository)
+
+// WithCandidateSampleThreshold ограничивает выборку кандидатов в ревьюеры: команды больше threshold
+// не загружаются целиком, вместо этого берётся случайная выборка из threshold активных участников
+func WithCandidateSampleThreshold(threshold int) UserRepositoryOption {
+	return func(r *UserRepository) {
+		r.sampleThreshold = threshold
+	}
 }
 
-func NewUserRepository(db *db.DB) *UserRepository {
-	return &UserRepository{db: db}
+func NewUserRepository(db *db.DB, opts ...UserRepositoryOption) *UserRepository {
+	r := &UserRepository{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
 func (r *UserRepository) Upsert(ctx context.Context, user domain.TeamMember, teamName string) error {
@@ -26,25 +52,47 @@ func (r *UserRepository) Upsert(ctx context.Context, user domain.TeamMember, tea
         SET username = EXCLUDED.username,
             team_name = EXCLUDED.team_name,
             is_active = EXCLUDED.is_active,
+            removed_at = NULL,
             updated_at = NOW()
     `, user.UserID, user.Username, teamName, user.IsActive)
 
 	if err != nil {
-		return fmt.Errorf("failed to upsert user %s: %w", user.UserID, err)
+		return fmt.Errorf("failed to upsert user %s: %w", user.UserID, HandleDBError(err))
 	}
 
 	return nil
 }
 
+// Create добавляет нового пользователя. ErrAlreadyExists - пользователь уже есть (например, создан конкурентно),
+// ErrReferenceNotFound - команды teamName нет
+func (r *UserRepository) Create(ctx context.Context, member domain.TeamMember, teamName string) (*domain.User, error) {
+	conn := r.db.Conn(ctx)
+
+	var user domain.User
+	err := scanUser(conn.QueryRow(ctx, `
+		INSERT INTO users (user_id, username, team_name, is_active)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO NOTHING
+		RETURNING `+userColumns, member.UserID, member.Username, teamName, member.IsActive), &user)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAlreadyExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user %s: %w", member.UserID, HandleDBError(err))
+	}
+
+	return &user, nil
+}
+
 func (r *UserRepository) GetByID(ctx context.Context, userID string) (*domain.User, error) {
 	conn := r.db.Conn(ctx)
 
 	var user domain.User
-	err := conn.QueryRow(ctx, `
-		SELECT user_id, username, team_name, is_active
+	err := scanUser(conn.QueryRow(ctx, `
+		SELECT `+userColumns+`
 		FROM users
 		WHERE user_id = $1
-	`, userID).Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive)
+	`, userID), &user)
 
 	if err != nil {
 		return nil, HandleDBError(err)
@@ -53,16 +101,42 @@ func (r *UserRepository) GetByID(ctx context.Context, userID string) (*domain.Us
 	return &user, nil
 }
 
+// GetByIDs одним запросом возвращает известных пользователей из userIDs, включая удалённых из команды.
+// Неизвестные ID пропускаются, порядок - по user_id
+func (r *UserRepository) GetByIDs(ctx context.Context, userIDs []string) ([]domain.User, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT `+userColumns+`
+		FROM users
+		WHERE user_id = ANY($1)
+		ORDER BY user_id
+	`, dedupeIDs(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		var user domain.User
+		if err := scanUser(rows, &user); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
 func (r *UserRepository) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
 	conn := r.db.Conn(ctx)
 
 	var user domain.User
-	err := conn.QueryRow(ctx, `
+	err := scanUser(conn.QueryRow(ctx, `
 		UPDATE users
 		SET is_active = $1, updated_at = NOW()
 		WHERE user_id = $2
-		RETURNING user_id, username, team_name, is_active
-	`, isActive, userID).Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive)
+		RETURNING `+userColumns, isActive, userID), &user)
 
 	if err != nil {
 		return nil, HandleDBError(err)
@@ -75,7 +149,7 @@ func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]doma
 	conn := r.db.Conn(ctx)
 
 	rows, err := conn.Query(ctx, `
-		SELECT user_id, username, team_name, is_active
+		SELECT `+userColumns+`
 		FROM users
 		WHERE team_name = $1
 	`, teamName)
@@ -87,7 +161,7 @@ func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]doma
 	var users []domain.User
 	for rows.Next() {
 		var user domain.User
-		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+		if err := scanUser(rows, &user); err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
 		users = append(users, user)
@@ -96,19 +170,54 @@ func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]doma
 	return users, rows.Err()
 }
 
-func (r *UserRepository) GetActiveByTeam(ctx context.Context, teamName string, excludeUserIDs []string) ([]domain.User, error) {
-	query := `
-		SELECT user_id, username, team_name, is_active
+// maxExcludedUserIDs - верхняя граница списка исключений: автор, ревьюеры PR и
+// исключённые пары на порядки меньше, более длинный список означает ошибку вызывающего
+const maxExcludedUserIDs = 1000
+
+const activeByTeamQuery = `
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE team_name = $1 AND is_active = TRUE
-	`
+		WHERE team_name = $1 AND is_active = TRUE AND NOT (user_id = ANY($2))`
+
+// buildActiveByTeamQuery всегда передаёт массив исключений, даже пустой, чтобы текст запроса
+// не зависел от nil/пустого среза и не ломал кэш подготовленных выражений
+func buildActiveByTeamQuery(teamName string, excludeUserIDs []string, sampleThreshold int) (string, []any, error) {
+	exclude := dedupeIDs(excludeUserIDs)
+	if len(exclude) > maxExcludedUserIDs {
+		return "", nil, fmt.Errorf("%w: %d", ErrTooManyExcluded, len(exclude))
+	}
+
+	query := activeByTeamQuery
+	args := []any{teamName, exclude}
+
+	// команда не больше порога возвращается целиком, только в случайном порядке
+	if sampleThreshold > 0 {
+		query += " ORDER BY random() LIMIT $3"
+		args = append(args, sampleThreshold)
+	}
 
-	var args []interface{}
-	args = append(args, teamName)
+	return query, args, nil
+}
+
+// dedupeIDs убирает повторы с сохранением порядка и никогда не возвращает nil
+func dedupeIDs(ids []string) []string {
+	result := make([]string, 0, len(ids))
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+
+	return result
+}
 
-	if len(excludeUserIDs) > 0 {
-		query += " AND NOT (user_id = ANY($2))"
-		args = append(args, excludeUserIDs)
+func (r *UserRepository) GetActiveByTeam(ctx context.Context, teamName string, excludeUserIDs []string) ([]domain.User, error) {
+	query, args, err := buildActiveByTeamQuery(teamName, excludeUserIDs, r.sampleThreshold)
+	if err != nil {
+		return nil, err
 	}
 
 	conn := r.db.Conn(ctx)
@@ -121,7 +230,7 @@ func (r *UserRepository) GetActiveByTeam(ctx context.Context, teamName string, e
 	var users []domain.User
 	for rows.Next() {
 		var user domain.User
-		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+		if err := scanUser(rows, &user); err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
 		users = append(users, user)
@@ -129,3 +238,104 @@ func (r *UserRepository) GetActiveByTeam(ctx context.Context, teamName string, e
 
 	return users, rows.Err()
 }
+
+// GetCandidateBreakdown одним запросом считает участников команды по шагам отбора кандидатов в ревьюеры.
+// maxOpenReviews <= 0 - ограничения по нагрузке нет. Нужен только для диагностики, когда кандидатов не нашлось
+func (r *UserRepository) GetCandidateBreakdown(
+	ctx context.Context,
+	teamName string,
+	excludeUserIDs []string,
+	maxOpenReviews int,
+) (domain.CandidateBreakdown, error) {
+	conn := r.db.Conn(ctx)
+
+	var b domain.CandidateBreakdown
+	err := conn.QueryRow(ctx, `
+		WITH members AS (
+			SELECT u.user_id, u.is_active,
+				u.user_id = ANY($2) AS excluded,
+				(SELECT COUNT(*) FROM pr_reviewers r
+					INNER JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+					WHERE r.user_id = u.user_id AND pr.status = $4) AS open_reviews
+			FROM users u
+			WHERE u.team_name = $1 AND u.removed_at IS NULL
+		)
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE is_active),
+			COUNT(*) FILTER (WHERE is_active AND excluded),
+			COUNT(*) FILTER (WHERE is_active AND NOT excluded AND $3 > 0 AND open_reviews >= $3),
+			COUNT(*) FILTER (WHERE is_active AND NOT excluded AND ($3 <= 0 OR open_reviews < $3))
+		FROM members
+	`, teamName, dedupeIDs(excludeUserIDs), maxOpenReviews, domain.PRStatusOpen).
+		Scan(&b.TeamMembers, &b.Active, &b.Excluded, &b.OverCapacity, &b.Remaining)
+	if err != nil {
+		return b, fmt.Errorf("failed to query candidate breakdown: %w", err)
+	}
+
+	return b, nil
+}
+
+func (r *UserRepository) CountInactive(ctx context.Context) (int, error) {
+	conn := r.db.Conn(ctx)
+
+	var count int
+	if err := conn.QueryRow(ctx, "SELECT COUNT(*) FROM users WHERE is_active = FALSE AND removed_at IS NULL").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count inactive users: %w", err)
+	}
+
+	return count, nil
+}
+
+// RemoveFromTeam мягко удаляет участника: строка остаётся ради истории PR, но пользователь
+// деактивируется и пропадает из состава команды. Повторное добавление через Upsert его возвращает
+func (r *UserRepository) RemoveFromTeam(ctx context.Context, userID string) (*domain.User, error) {
+	conn := r.db.Conn(ctx)
+
+	var user domain.User
+	err := scanUser(conn.QueryRow(ctx, `
+		UPDATE users
+		SET is_active = FALSE, removed_at = COALESCE(removed_at, NOW()), updated_at = NOW()
+		WHERE user_id = $1
+		RETURNING `+userColumns, userID), &user)
+
+	if err != nil {
+		return nil, HandleDBError(err)
+	}
+
+	return &user, nil
+}
+
+// пустой workingHours сбрасывает рабочее окно
+func (r *UserRepository) SetSchedule(ctx context.Context, userID, workingHours, timezone string) (*domain.User, error) {
+	conn := r.db.Conn(ctx)
+
+	var user domain.User
+	err := scanUser(conn.QueryRow(ctx, `
+		UPDATE users
+		SET working_hours = NULLIF($1, ''), timezone = NULLIF($2, ''), updated_at = NOW()
+		WHERE user_id = $3
+		RETURNING `+userColumns, workingHours, timezone, userID), &user)
+
+	if err != nil {
+		return nil, HandleDBError(err)
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) SetReviewCapacity(ctx context.Context, userID string, capacity float64) (*domain.User, error) {
+	conn := r.db.Conn(ctx)
+
+	var user domain.User
+	err := scanUser(conn.QueryRow(ctx, `
+		UPDATE users
+		SET review_capacity = $1, updated_at = NOW()
+		WHERE user_id = $2
+		RETURNING `+userColumns, capacity, userID), &user)
+
+	if err != nil {
+		return nil, HandleDBError(err)
+	}
+
+	return &user, nil
+}
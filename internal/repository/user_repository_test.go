@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+)
+
+func TestUserRepository_GetActiveByTeamSampling(t *testing.T) {
+	database, pool := setupTestDB(t)
+	ctx := context.Background()
+
+	userIDs := make([]string, 0, 50)
+	for i := 0; i < 50; i++ {
+		userIDs = append(userIDs, fmt.Sprintf("u%02d", i))
+	}
+	seedTeam(t, pool, "large", userIDs...)
+	seedTeam(t, pool, "small", "s1", "s2", "s3")
+
+	repo := NewUserRepository(database, WithCandidateSampleThreshold(10))
+
+	t.Run("large team is sampled", func(t *testing.T) {
+		users, err := repo.GetActiveByTeam(ctx, "large", []string{"u00"})
+		require.NoError(t, err)
+
+		assert.Len(t, users, 10)
+		for _, user := range users {
+			assert.NotEqual(t, "u00", user.UserID)
+			assert.Equal(t, "large", user.TeamName)
+		}
+	})
+
+	t.Run("small team is loaded in full", func(t *testing.T) {
+		users, err := repo.GetActiveByTeam(ctx, "small", nil)
+		require.NoError(t, err)
+		assert.Len(t, users, 3)
+	})
+
+	t.Run("disabled loads whole team", func(t *testing.T) {
+		users, err := NewUserRepository(database).GetActiveByTeam(ctx, "large", nil)
+		require.NoError(t, err)
+		assert.Len(t, users, 50)
+	})
+}
+
+func TestUserRepository_CountInactive(t *testing.T) {
+	database, pool := setupTestDB(t)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "u1", "u2", "u3")
+	mustExec(t, pool, "UPDATE users SET is_active = false WHERE user_id IN ('u1', 'u2')")
+
+	count, err := NewUserRepository(database).CountInactive(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestUserRepository_RemoveFromTeam(t *testing.T) {
+	database, pool := setupTestDB(t)
+	users := NewUserRepository(database)
+	prs := NewPullRequestRepository(database)
+	teams := NewTeamRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "author", "leaver", "stayer")
+	seedPR(t, pool, "open", "author", time.Now())
+	seedPR(t, pool, "merged", "author", time.Now())
+	for _, prID := range []string{"open", "merged"} {
+		require.NoError(t, prs.AssignReviewer(ctx, prID, "leaver", domain.AssignmentSourceAuto))
+	}
+	_, err := prs.MergePullRequest(ctx, "merged", 2, "")
+	require.NoError(t, err)
+
+	open, err := prs.GetOpenPullRequestsByReviewer(ctx, "leaver")
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, "open", open[0].PullRequestID)
+
+	removed, err := users.RemoveFromTeam(ctx, "leaver")
+	require.NoError(t, err)
+	assert.False(t, removed.IsActive)
+
+	team, err := teams.GetTeamByName(ctx, "backend")
+	require.NoError(t, err)
+	for _, m := range team.Members {
+		assert.NotEqual(t, "leaver", m.UserID)
+	}
+
+	merged, err := prs.GetPullRequestByID(ctx, "merged")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"leaver"}, merged.AssignedReviewers)
+
+	t.Run("re-adding restores membership", func(t *testing.T) {
+		require.NoError(t, users.Upsert(ctx, domain.TeamMember{UserID: "leaver", Username: "leaver", IsActive: true}, "backend"))
+
+		team, err := teams.GetTeamByName(ctx, "backend")
+		require.NoError(t, err)
+		assert.Len(t, team.Members, 3)
+	})
+}
+
+func TestBuildActiveByTeamQuery(t *testing.T) {
+	t.Run("nil and empty exclusions produce the same statement", func(t *testing.T) {
+		nilQuery, nilArgs, err := buildActiveByTeamQuery("backend", nil, 0)
+		require.NoError(t, err)
+		emptyQuery, emptyArgs, err := buildActiveByTeamQuery("backend", []string{}, 0)
+		require.NoError(t, err)
+		withQuery, _, err := buildActiveByTeamQuery("backend", []string{"u1"}, 0)
+		require.NoError(t, err)
+
+		assert.Equal(t, nilQuery, emptyQuery)
+		assert.Equal(t, nilQuery, withQuery)
+		assert.Equal(t, []any{"backend", []string{}}, nilArgs)
+		assert.Equal(t, nilArgs, emptyArgs)
+	})
+
+	t.Run("duplicates are removed in order", func(t *testing.T) {
+		_, args, err := buildActiveByTeamQuery("backend", []string{"author", "r1", "author", "r2", "r1"}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"author", "r1", "r2"}, args[1])
+	})
+
+	t.Run("sampling adds a limit argument", func(t *testing.T) {
+		query, args, err := buildActiveByTeamQuery("backend", nil, 10)
+		require.NoError(t, err)
+		assert.Contains(t, query, "ORDER BY random() LIMIT $3")
+		assert.Equal(t, 10, args[2])
+	})
+
+	t.Run("oversized list is rejected", func(t *testing.T) {
+		ids := make([]string, 0, maxExcludedUserIDs+1)
+		for i := 0; i <= maxExcludedUserIDs; i++ {
+			ids = append(ids, fmt.Sprintf("u%d", i))
+		}
+
+		_, _, err := buildActiveByTeamQuery("backend", ids, 0)
+		assert.ErrorIs(t, err, ErrTooManyExcluded)
+
+		// повторы не считаются
+		_, _, err = buildActiveByTeamQuery("backend", append(ids[:maxExcludedUserIDs], ids[0]), 0)
+		assert.NoError(t, err)
+	})
+}
+
+func TestUserRepository_GetActiveByTeamExclusions(t *testing.T) {
+	database, pool := setupTestDB(t)
+	repo := NewUserRepository(database)
+	ctx := context.Background()
+
+	seedTeam(t, pool, "backend", "u1", "u2", "u3")
+
+	for name, exclude := range map[string][]string{"nil": nil, "empty": {}} {
+		t.Run(name+" exclusions return the whole team", func(t *testing.T) {
+			users, err := repo.GetActiveByTeam(ctx, "backend", exclude)
+			require.NoError(t, err)
+			assert.Len(t, users, 3)
+		})
+	}
+
+	t.Run("duplicated exclusions", func(t *testing.T) {
+		users, err := repo.GetActiveByTeam(ctx, "backend", []string{"u1", "u1", "u2"})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, "u3", users[0].UserID)
+	})
+}
+
+func TestUserRepository_Create(t *testing.T) {
+	database, pool := setupTestDB(t)
+	ctx := context.Background()
+	repo := NewUserRepository(database)
+
+	seedTeam(t, pool, "backend", "u1")
+
+	user, err := repo.Create(ctx, domain.TeamMember{UserID: "u2", Username: "New", IsActive: false}, "backend")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", user.TeamName)
+	assert.False(t, user.IsActive)
+
+	_, err = repo.Create(ctx, domain.TeamMember{UserID: "u1", Username: "Dup", IsActive: true}, "backend")
+	assert.ErrorIs(t, err, ErrAlreadyExists)
+
+	_, err = repo.Create(ctx, domain.TeamMember{UserID: "u3", Username: "Lost", IsActive: true}, "missing")
+	assert.ErrorIs(t, err, ErrReferenceNotFound)
+}
+
+func TestUserRepository_GetByIDs(t *testing.T) {
+	database, pool := setupTestDB(t)
+	ctx := context.Background()
+	repo := NewUserRepository(database)
+
+	seedTeam(t, pool, "backend", "u1", "u2", "u3")
+	mustExec(t, pool, "UPDATE users SET removed_at = NOW() WHERE user_id = 'u3'")
+
+	users, err := repo.GetByIDs(ctx, []string{"u3", "ghost", "u1", "u1"})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "u1", users[0].UserID)
+	assert.Equal(t, "u3", users[1].UserID)
+
+	users, err = repo.GetByIDs(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestUserRepository_GetCandidateBreakdown(t *testing.T) {
+	database, pool := setupTestDB(t)
+	ctx := context.Background()
+	repo := NewUserRepository(database)
+	prRepo := NewPullRequestRepository(database)
+
+	seedTeam(t, pool, "backend", "author", "r1", "busy", "free", "idle", "gone")
+	mustExec(t, pool, "UPDATE users SET is_active = false WHERE user_id = 'idle'")
+	mustExec(t, pool, "UPDATE users SET is_active = false, removed_at = NOW() WHERE user_id = 'gone'")
+	seedPR(t, pool, "pr1", "author", time.Now())
+	seedPR(t, pool, "pr2", "author", time.Now())
+	require.NoError(t, prRepo.AssignReviewer(ctx, "pr1", "r1", domain.AssignmentSourceAuto))
+	require.NoError(t, prRepo.AssignReviewer(ctx, "pr1", "busy", domain.AssignmentSourceAuto))
+	require.NoError(t, prRepo.AssignReviewer(ctx, "pr2", "busy", domain.AssignmentSourceAuto))
+
+	breakdown, err := repo.GetCandidateBreakdown(ctx, "backend", []string{"author", "r1"}, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.CandidateBreakdown{TeamMembers: 5, Active: 4, Excluded: 2, OverCapacity: 1, Remaining: 1}, breakdown)
+
+	unlimited, err := repo.GetCandidateBreakdown(ctx, "backend", []string{"author", "r1"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, unlimited.OverCapacity)
+	assert.Equal(t, 2, unlimited.Remaining)
+}
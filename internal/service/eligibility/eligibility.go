@@ -0,0 +1,44 @@
+// Package eligibility - условия, при которых пользователь может получить ревью при автоназначении.
+// Ими пользуются выбор ревьюеров в сервисе PR и превью пула ревьюеров в ответе /team/get
+package eligibility
+
+import "avito_backend_task/internal/domain"
+
+// Status - выполнение каждого условия автоназначения для участника команды
+type Status struct {
+	Active bool
+	// UnderCapacity - открытых ревью меньше MAX_OPEN_REVIEWS_PER_USER
+	UnderCapacity bool
+	// InBlackout - у команды сейчас окно без автоназначения
+	InBlackout bool
+}
+
+// Eligible - участник получит ревью, если автоназначение произойдёт сейчас
+func (s Status) Eligible() bool {
+	return s.Active && s.UnderCapacity && !s.InBlackout
+}
+
+// UnderCapacity - у пользователя меньше maxOpenReviews открытых ревью, maxOpenReviews <= 0 снимает ограничение
+func UnderCapacity(openReviews, maxOpenReviews int) bool {
+	return maxOpenReviews <= 0 || openReviews < maxOpenReviews
+}
+
+// FilterUnderCapacity оставляет кандидатов, прошедших UnderCapacity по числу открытых ревью из counts
+func FilterUnderCapacity(candidates []domain.User, counts map[string]int, maxOpenReviews int) []domain.User {
+	filtered := make([]domain.User, 0, len(candidates))
+	for _, c := range candidates {
+		if UnderCapacity(counts[c.UserID], maxOpenReviews) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// Evaluate проверяет участника команды по всем условиям. teamInBlackout относится ко всей команде
+func Evaluate(member domain.TeamMember, openReviews, maxOpenReviews int, teamInBlackout bool) Status {
+	return Status{
+		Active:        member.IsActive,
+		UnderCapacity: UnderCapacity(openReviews, maxOpenReviews),
+		InBlackout:    teamInBlackout,
+	}
+}
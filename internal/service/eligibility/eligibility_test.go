@@ -0,0 +1,74 @@
+package eligibility
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"avito_backend_task/internal/domain"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name           string
+		member         domain.TeamMember
+		openReviews    int
+		maxOpenReviews int
+		inBlackout     bool
+		want           Status
+		wantEligible   bool
+	}{
+		{
+			name:           "eligible",
+			member:         domain.TeamMember{UserID: "u1", IsActive: true},
+			openReviews:    1,
+			maxOpenReviews: 2,
+			want:           Status{Active: true, UnderCapacity: true},
+			wantEligible:   true,
+		},
+		{
+			name:           "inactive",
+			member:         domain.TeamMember{UserID: "u2"},
+			maxOpenReviews: 2,
+			want:           Status{UnderCapacity: true},
+		},
+		{
+			name:           "at capacity",
+			member:         domain.TeamMember{UserID: "u3", IsActive: true},
+			openReviews:    2,
+			maxOpenReviews: 2,
+			want:           Status{Active: true},
+		},
+		{
+			name:         "no capacity limit",
+			member:       domain.TeamMember{UserID: "u4", IsActive: true},
+			openReviews:  50,
+			want:         Status{Active: true, UnderCapacity: true},
+			wantEligible: true,
+		},
+		{
+			name:           "team in blackout",
+			member:         domain.TeamMember{UserID: "u5", IsActive: true},
+			maxOpenReviews: 2,
+			inBlackout:     true,
+			want:           Status{Active: true, UnderCapacity: true, InBlackout: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := Evaluate(tt.member, tt.openReviews, tt.maxOpenReviews, tt.inBlackout)
+
+			assert.Equal(t, tt.want, status)
+			assert.Equal(t, tt.wantEligible, status.Eligible())
+		})
+	}
+}
+
+func TestFilterUnderCapacity(t *testing.T) {
+	candidates := []domain.User{{UserID: "busy"}, {UserID: "free"}, {UserID: "idle"}}
+	counts := map[string]int{"busy": 3, "free": 2}
+
+	assert.Equal(t, []domain.User{{UserID: "free"}, {UserID: "idle"}}, FilterUnderCapacity(candidates, counts, 3))
+	assert.Equal(t, candidates, FilterUnderCapacity(candidates, counts, 0))
+}
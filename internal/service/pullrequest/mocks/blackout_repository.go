@@ -0,0 +1,58 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// BlackoutRepository is an autogenerated mock type for the BlackoutRepository type
+type BlackoutRepository struct {
+	mock.Mock
+}
+
+// IsInBlackout provides a mock function with given fields: ctx, teamName, at
+func (_m *BlackoutRepository) IsInBlackout(ctx context.Context, teamName string, at time.Time) (bool, error) {
+	ret := _m.Called(ctx, teamName, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsInBlackout")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (bool, error)); ok {
+		return rf(ctx, teamName, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) bool); ok {
+		r0 = rf(ctx, teamName, at)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, teamName, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewBlackoutRepository creates a new instance of BlackoutRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewBlackoutRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *BlackoutRepository {
+	mock := &BlackoutRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -16,17 +16,63 @@ type PullRequestRepository struct {
 	mock.Mock
 }
 
-// AssignReviewer provides a mock function with given fields: ctx, prID, reviewerID
-func (_m *PullRequestRepository) AssignReviewer(ctx context.Context, prID string, reviewerID string) error {
-	ret := _m.Called(ctx, prID, reviewerID)
+// AssignReviewer provides a mock function with given fields: ctx, prID, reviewerID, source
+func (_m *PullRequestRepository) AssignReviewer(ctx context.Context, prID string, reviewerID string, source domain.AssignmentSource) error {
+	ret := _m.Called(ctx, prID, reviewerID, source)
 
 	if len(ret) == 0 {
 		panic("no return value specified for AssignReviewer")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
-		r0 = rf(ctx, prID, reviewerID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, domain.AssignmentSource) error); ok {
+		r0 = rf(ctx, prID, reviewerID, source)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// BackfillAssignedEvents provides a mock function with given fields: ctx, after, limit
+func (_m *PullRequestRepository) BackfillAssignedEvents(ctx context.Context, after domain.BackfillWatermark, limit int) (domain.BackfillBatch, error) {
+	ret := _m.Called(ctx, after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BackfillAssignedEvents")
+	}
+
+	var r0 domain.BackfillBatch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BackfillWatermark, int) (domain.BackfillBatch, error)); ok {
+		return rf(ctx, after, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BackfillWatermark, int) domain.BackfillBatch); ok {
+		r0 = rf(ctx, after, limit)
+	} else {
+		r0 = ret.Get(0).(domain.BackfillBatch)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.BackfillWatermark, int) error); ok {
+		r1 = rf(ctx, after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClearPendingAssignment provides a mock function with given fields: ctx, prID
+func (_m *PullRequestRepository) ClearPendingAssignment(ctx context.Context, prID string) error {
+	ret := _m.Called(ctx, prID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClearPendingAssignment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, prID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -90,6 +136,126 @@ func (_m *PullRequestRepository) Exists(ctx context.Context, prID string) (bool,
 	return r0, r1
 }
 
+// GetInactiveReviewerAssignments provides a mock function with given fields: ctx
+func (_m *PullRequestRepository) GetInactiveReviewerAssignments(ctx context.Context) ([]domain.ReviewerAssignment, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInactiveReviewerAssignments")
+	}
+
+	var r0 []domain.ReviewerAssignment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.ReviewerAssignment, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.ReviewerAssignment); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ReviewerAssignment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOpenGroupAuthors provides a mock function with given fields: ctx, groupID, prID
+func (_m *PullRequestRepository) GetOpenGroupAuthors(ctx context.Context, groupID string, prID string) ([]string, error) {
+	ret := _m.Called(ctx, groupID, prID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOpenGroupAuthors")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]string, error)); ok {
+		return rf(ctx, groupID, prID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []string); ok {
+		r0 = rf(ctx, groupID, prID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, groupID, prID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOpenReviewCounts provides a mock function with given fields: ctx, userIDs
+func (_m *PullRequestRepository) GetOpenReviewCounts(ctx context.Context, userIDs []string) (map[string]int, error) {
+	ret := _m.Called(ctx, userIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOpenReviewCounts")
+	}
+
+	var r0 map[string]int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) (map[string]int, error)); ok {
+		return rf(ctx, userIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) map[string]int); ok {
+		r0 = rf(ctx, userIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, userIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingAssignmentPRs provides a mock function with given fields: ctx
+func (_m *PullRequestRepository) GetPendingAssignmentPRs(ctx context.Context) ([]string, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPendingAssignmentPRs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]string, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPullRequestByID provides a mock function with given fields: ctx, prID
 func (_m *PullRequestRepository) GetPullRequestByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
 	ret := _m.Called(ctx, prID)
@@ -120,27 +286,89 @@ func (_m *PullRequestRepository) GetPullRequestByID(ctx context.Context, prID st
 	return r0, r1
 }
 
-// IsReviewerAssigned provides a mock function with given fields: ctx, prID, userID
-func (_m *PullRequestRepository) IsReviewerAssigned(ctx context.Context, prID string, userID string) (bool, error) {
-	ret := _m.Called(ctx, prID, userID)
+// GetPullRequestsByIDs provides a mock function with given fields: ctx, prIDs
+func (_m *PullRequestRepository) GetPullRequestsByIDs(ctx context.Context, prIDs []string) ([]domain.PullRequest, error) {
+	ret := _m.Called(ctx, prIDs)
 
 	if len(ret) == 0 {
-		panic("no return value specified for IsReviewerAssigned")
+		panic("no return value specified for GetPullRequestsByIDs")
 	}
 
-	var r0 bool
+	var r0 []domain.PullRequest
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
-		return rf(ctx, prID, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]domain.PullRequest, error)); ok {
+		return rf(ctx, prIDs)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
-		r0 = rf(ctx, prID, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []domain.PullRequest); ok {
+		r0 = rf(ctx, prIDs)
 	} else {
-		r0 = ret.Get(0).(bool)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PullRequest)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, prID, userID)
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, prIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRecentPullRequests provides a mock function with given fields: ctx, limit
+func (_m *PullRequestRepository) GetRecentPullRequests(ctx context.Context, limit int) ([]domain.RecentPullRequest, error) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRecentPullRequests")
+	}
+
+	var r0 []domain.RecentPullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.RecentPullRequest, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.RecentPullRequest); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.RecentPullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReviewLatency provides a mock function with given fields: ctx, teamName, from, to, minSamples
+func (_m *PullRequestRepository) GetReviewLatency(ctx context.Context, teamName string, from *time.Time, to *time.Time, minSamples int) ([]domain.ReviewerLatency, error) {
+	ret := _m.Called(ctx, teamName, from, to, minSamples)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReviewLatency")
+	}
+
+	var r0 []domain.ReviewerLatency
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time, *time.Time, int) ([]domain.ReviewerLatency, error)); ok {
+		return rf(ctx, teamName, from, to, minSamples)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time, *time.Time, int) []domain.ReviewerLatency); ok {
+		r0 = rf(ctx, teamName, from, to, minSamples)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ReviewerLatency)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *time.Time, *time.Time, int) error); ok {
+		r1 = rf(ctx, teamName, from, to, minSamples)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -148,12 +376,236 @@ func (_m *PullRequestRepository) IsReviewerAssigned(ctx context.Context, prID st
 	return r0, r1
 }
 
-// MergePullRequest provides a mock function with given fields: ctx, prID
-func (_m *PullRequestRepository) MergePullRequest(ctx context.Context, prID string) error {
+// GetReviewerAnomalies provides a mock function with given fields: ctx
+func (_m *PullRequestRepository) GetReviewerAnomalies(ctx context.Context) (domain.AnomalyReport, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReviewerAnomalies")
+	}
+
+	var r0 domain.AnomalyReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (domain.AnomalyReport, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) domain.AnomalyReport); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(domain.AnomalyReport)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReviewerIDs provides a mock function with given fields: ctx, prID
+func (_m *PullRequestRepository) GetReviewerIDs(ctx context.Context, prID string) ([]string, error) {
 	ret := _m.Called(ctx, prID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for MergePullRequest")
+		panic("no return value specified for GetReviewerIDs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, prID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, prID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, prID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReviewerLatencyByUsers provides a mock function with given fields: ctx, userIDs, from, minSamples
+func (_m *PullRequestRepository) GetReviewerLatencyByUsers(ctx context.Context, userIDs []string, from time.Time, minSamples int) ([]domain.ReviewerLatency, error) {
+	ret := _m.Called(ctx, userIDs, from, minSamples)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReviewerLatencyByUsers")
+	}
+
+	var r0 []domain.ReviewerLatency
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, time.Time, int) ([]domain.ReviewerLatency, error)); ok {
+		return rf(ctx, userIDs, from, minSamples)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string, time.Time, int) []domain.ReviewerLatency); ok {
+		r0 = rf(ctx, userIDs, from, minSamples)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ReviewerLatency)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string, time.Time, int) error); ok {
+		r1 = rf(ctx, userIDs, from, minSamples)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReviewerQueues provides a mock function with given fields: ctx, prID
+func (_m *PullRequestRepository) GetReviewerQueues(ctx context.Context, prID string) ([]domain.ReviewerQueuePosition, error) {
+	ret := _m.Called(ctx, prID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReviewerQueues")
+	}
+
+	var r0 []domain.ReviewerQueuePosition
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.ReviewerQueuePosition, error)); ok {
+		return rf(ctx, prID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.ReviewerQueuePosition); ok {
+		r0 = rf(ctx, prID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ReviewerQueuePosition)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, prID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReviewerRemovalsSince provides a mock function with given fields: ctx, prID, since
+func (_m *PullRequestRepository) GetReviewerRemovalsSince(ctx context.Context, prID string, since time.Time) ([]time.Time, error) {
+	ret := _m.Called(ctx, prID, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReviewerRemovalsSince")
+	}
+
+	var r0 []time.Time
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) ([]time.Time, error)); ok {
+		return rf(ctx, prID, since)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) []time.Time); ok {
+		r0 = rf(ctx, prID, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]time.Time)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, prID, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTeamReviewAssignments provides a mock function with given fields: ctx, teamName
+func (_m *PullRequestRepository) GetTeamReviewAssignments(ctx context.Context, teamName string) ([]domain.ReviewerAssignment, error) {
+	ret := _m.Called(ctx, teamName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTeamReviewAssignments")
+	}
+
+	var r0 []domain.ReviewerAssignment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.ReviewerAssignment, error)); ok {
+		return rf(ctx, teamName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.ReviewerAssignment); ok {
+		r0 = rf(ctx, teamName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ReviewerAssignment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, teamName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IncrementReassignmentCount provides a mock function with given fields: ctx, prID
+func (_m *PullRequestRepository) IncrementReassignmentCount(ctx context.Context, prID string) error {
+	ret := _m.Called(ctx, prID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementReassignmentCount")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, prID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LockBackfillWatermark provides a mock function with given fields: ctx, name
+func (_m *PullRequestRepository) LockBackfillWatermark(ctx context.Context, name string) (domain.BackfillWatermark, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockBackfillWatermark")
+	}
+
+	var r0 domain.BackfillWatermark
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.BackfillWatermark, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.BackfillWatermark); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Get(0).(domain.BackfillWatermark)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LockPullRequest provides a mock function with given fields: ctx, prID
+func (_m *PullRequestRepository) LockPullRequest(ctx context.Context, prID string) error {
+	ret := _m.Called(ctx, prID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockPullRequest")
 	}
 
 	var r0 error
@@ -166,17 +618,145 @@ func (_m *PullRequestRepository) MergePullRequest(ctx context.Context, prID stri
 	return r0
 }
 
+// MarkPendingAssignment provides a mock function with given fields: ctx, prID
+func (_m *PullRequestRepository) MarkPendingAssignment(ctx context.Context, prID string) error {
+	ret := _m.Called(ctx, prID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkPendingAssignment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, prID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MergePullRequest provides a mock function with given fields: ctx, prID, desiredReviewers, mergedBy
+func (_m *PullRequestRepository) MergePullRequest(ctx context.Context, prID string, desiredReviewers int, mergedBy string) (bool, error) {
+	ret := _m.Called(ctx, prID, desiredReviewers, mergedBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergePullRequest")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, string) (bool, error)); ok {
+		return rf(ctx, prID, desiredReviewers, mergedBy)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, string) bool); ok {
+		r0 = rf(ctx, prID, desiredReviewers, mergedBy)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, string) error); ok {
+		r1 = rf(ctx, prID, desiredReviewers, mergedBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RemoveReviewer provides a mock function with given fields: ctx, prID, reviewerID
-func (_m *PullRequestRepository) RemoveReviewer(ctx context.Context, prID string, reviewerID string) error {
+func (_m *PullRequestRepository) RemoveReviewer(ctx context.Context, prID string, reviewerID string) (bool, error) {
 	ret := _m.Called(ctx, prID, reviewerID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RemoveReviewer")
 	}
 
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, prID, reviewerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, prID, reviewerID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, prID, reviewerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RenamePullRequest provides a mock function with given fields: ctx, prID, name
+func (_m *PullRequestRepository) RenamePullRequest(ctx context.Context, prID string, name string) error {
+	ret := _m.Called(ctx, prID, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenamePullRequest")
+	}
+
 	var r0 error
 	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
-		r0 = rf(ctx, prID, reviewerID)
+		r0 = rf(ctx, prID, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplaceReviewers provides a mock function with given fields: ctx, prID, reviewerIDs, source
+func (_m *PullRequestRepository) ReplaceReviewers(ctx context.Context, prID string, reviewerIDs []string, source domain.AssignmentSource) error {
+	ret := _m.Called(ctx, prID, reviewerIDs, source)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceReviewers")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, domain.AssignmentSource) error); ok {
+		r0 = rf(ctx, prID, reviewerIDs, source)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveBackfillWatermark provides a mock function with given fields: ctx, name, watermark
+func (_m *PullRequestRepository) SaveBackfillWatermark(ctx context.Context, name string, watermark domain.BackfillWatermark) error {
+	ret := _m.Called(ctx, name, watermark)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveBackfillWatermark")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.BackfillWatermark) error); ok {
+		r0 = rf(ctx, name, watermark)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SnoozeReview provides a mock function with given fields: ctx, prID, userID, until
+func (_m *PullRequestRepository) SnoozeReview(ctx context.Context, prID string, userID string, until time.Time) error {
+	ret := _m.Called(ctx, prID, userID, until)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SnoozeReview")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) error); ok {
+		r0 = rf(ctx, prID, userID, until)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -14,6 +14,36 @@ type UserRepository struct {
 	mock.Mock
 }
 
+// Create provides a mock function with given fields: ctx, member, teamName
+func (_m *UserRepository) Create(ctx context.Context, member domain.TeamMember, teamName string) (*domain.User, error) {
+	ret := _m.Called(ctx, member, teamName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TeamMember, string) (*domain.User, error)); ok {
+		return rf(ctx, member, teamName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TeamMember, string) *domain.User); ok {
+		r0 = rf(ctx, member, teamName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.TeamMember, string) error); ok {
+		r1 = rf(ctx, member, teamName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetActiveByTeam provides a mock function with given fields: ctx, teamName, excludeUserIDs
 func (_m *UserRepository) GetActiveByTeam(ctx context.Context, teamName string, excludeUserIDs []string) ([]domain.User, error) {
 	ret := _m.Called(ctx, teamName, excludeUserIDs)
@@ -74,6 +104,34 @@ func (_m *UserRepository) GetByID(ctx context.Context, userID string) (*domain.U
 	return r0, r1
 }
 
+// GetCandidateBreakdown provides a mock function with given fields: ctx, teamName, excludeUserIDs, maxOpenReviews
+func (_m *UserRepository) GetCandidateBreakdown(ctx context.Context, teamName string, excludeUserIDs []string, maxOpenReviews int) (domain.CandidateBreakdown, error) {
+	ret := _m.Called(ctx, teamName, excludeUserIDs, maxOpenReviews)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCandidateBreakdown")
+	}
+
+	var r0 domain.CandidateBreakdown
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, int) (domain.CandidateBreakdown, error)); ok {
+		return rf(ctx, teamName, excludeUserIDs, maxOpenReviews)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, int) domain.CandidateBreakdown); ok {
+		r0 = rf(ctx, teamName, excludeUserIDs, maxOpenReviews)
+	} else {
+		r0 = ret.Get(0).(domain.CandidateBreakdown)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, int) error); ok {
+		r1 = rf(ctx, teamName, excludeUserIDs, maxOpenReviews)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewUserRepository creates a new instance of UserRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserRepository(t interface {
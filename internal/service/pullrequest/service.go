@@ -5,36 +5,310 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+
+	"avito_backend_task/internal/auth"
 	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/jobs"
 	"avito_backend_task/internal/repository"
+	"avito_backend_task/internal/service/eligibility"
 	"avito_backend_task/internal/service/utils"
+	"avito_backend_task/pkg/clock"
 	"avito_backend_task/pkg/db"
+	"avito_backend_task/pkg/logctx"
 )
 
 //go:generate mockery --name=PullRequestRepository --output=./mocks --case=underscore
 type PullRequestRepository interface {
 	CreatePullRequest(ctx context.Context, pr domain.PullRequestCreate) (time.Time, error)
 	Exists(ctx context.Context, prID string) (bool, error)
-	AssignReviewer(ctx context.Context, prID, reviewerID string) error
+	AssignReviewer(ctx context.Context, prID, reviewerID string, source domain.AssignmentSource) error
+	IncrementReassignmentCount(ctx context.Context, prID string) error
 	GetPullRequestByID(ctx context.Context, prID string) (*domain.PullRequest, error)
-	MergePullRequest(ctx context.Context, prID string) error
-	RemoveReviewer(ctx context.Context, prID, reviewerID string) error
-	IsReviewerAssigned(ctx context.Context, prID, userID string) (bool, error)
+	GetPullRequestsByIDs(ctx context.Context, prIDs []string) ([]domain.PullRequest, error)
+	GetRecentPullRequests(ctx context.Context, limit int) ([]domain.RecentPullRequest, error)
+	GetReviewerAnomalies(ctx context.Context) (domain.AnomalyReport, error)
+	GetReviewerIDs(ctx context.Context, prID string) ([]string, error)
+	LockPullRequest(ctx context.Context, prID string) error
+	MergePullRequest(ctx context.Context, prID string, desiredReviewers int, mergedBy string) (bool, error)
+	RenamePullRequest(ctx context.Context, prID, name string) error
+	RemoveReviewer(ctx context.Context, prID, reviewerID string) (bool, error)
+	SnoozeReview(ctx context.Context, prID, userID string, until time.Time) error
+	GetInactiveReviewerAssignments(ctx context.Context) ([]domain.ReviewerAssignment, error)
+	GetOpenReviewCounts(ctx context.Context, userIDs []string) (map[string]int, error)
+	GetReviewLatency(ctx context.Context, teamName string, from, to *time.Time, minSamples int) ([]domain.ReviewerLatency, error)
+	GetReviewerLatencyByUsers(ctx context.Context, userIDs []string, from time.Time, minSamples int) ([]domain.ReviewerLatency, error)
+	GetReviewerQueues(ctx context.Context, prID string) ([]domain.ReviewerQueuePosition, error)
+	GetTeamReviewAssignments(ctx context.Context, teamName string) ([]domain.ReviewerAssignment, error)
+	ReplaceReviewers(ctx context.Context, prID string, reviewerIDs []string, source domain.AssignmentSource) error
+	GetPendingAssignmentPRs(ctx context.Context) ([]string, error)
+	GetOpenGroupAuthors(ctx context.Context, groupID, prID string) ([]string, error)
+	GetReviewerRemovalsSince(ctx context.Context, prID string, since time.Time) ([]time.Time, error)
+	MarkPendingAssignment(ctx context.Context, prID string) error
+	ClearPendingAssignment(ctx context.Context, prID string) error
+	LockBackfillWatermark(ctx context.Context, name string) (domain.BackfillWatermark, error)
+	SaveBackfillWatermark(ctx context.Context, name string, watermark domain.BackfillWatermark) error
+	BackfillAssignedEvents(ctx context.Context, after domain.BackfillWatermark, limit int) (domain.BackfillBatch, error)
 }
 
 //go:generate mockery --name=UserRepository --output=./mocks --case=underscore
 type UserRepository interface {
 	GetByID(ctx context.Context, userID string) (*domain.User, error)
+	Create(ctx context.Context, member domain.TeamMember, teamName string) (*domain.User, error)
 	GetActiveByTeam(ctx context.Context, teamName string, excludeUserIDs []string) ([]domain.User, error)
+	GetCandidateBreakdown(ctx context.Context, teamName string, excludeUserIDs []string, maxOpenReviews int) (domain.CandidateBreakdown, error)
+}
+
+//go:generate mockery --name=BlackoutRepository --output=./mocks --case=underscore
+type BlackoutRepository interface {
+	IsInBlackout(ctx context.Context, teamName string, at time.Time) (bool, error)
+}
+
+//go:generate mockery --name=TeamRepository --output=./mocks --case=underscore
+type TeamRepository interface {
+	Exists(ctx context.Context, teamName string) (bool, error)
+	CountMembers(ctx context.Context, teamName string) (int, error)
+}
+
+// LiveUpdates раздаёт закоммиченные изменения PR подписчикам /pullRequest/events. Publish не блокирует,
+// Subscribe возвращает канал изменений и функцию отписки
+type LiveUpdates interface {
+	Publish(update domain.PRUpdate)
+	Subscribe(prID string) (<-chan domain.PRUpdate, func())
+}
+
+// EventPublisher принимает уведомления о PR. Publish не должен блокировать и не возвращает ошибку:
+// доставка не влияет на ответ клиенту
+type EventPublisher interface {
+	Publish(event domain.PREvent)
+}
+
+// Notifier сообщает участникам о смене ревьюеров PR. Вызывается после коммита,
+// как и EventPublisher не блокирует и не возвращает ошибку
+type Notifier interface {
+	NotifyAssignment(pr domain.PullRequest, reviewerIDs []string)
+	NotifyReassignment(pr domain.PullRequest, oldReviewerID, newReviewerID string)
+	// NotifyNoCandidate - пустой reviewerID означает, что новому PR некого назначить
+	NotifyNoCandidate(pr domain.PullRequest, reviewerID string)
+}
+
+// AuditSink записывает назначения и снятия ревьюеров во внешний аудит. Вызывается после коммита,
+// ошибка только логируется и не меняет результат операции
+type AuditSink interface {
+	RecordAssignment(ctx context.Context, record domain.AuditRecord) error
+	RecordRemoval(ctx context.Context, record domain.AuditRecord) error
+}
+
+// PendingAssignmentJobName - задача, назначающая ревьюеров PR, созданным во время blackout или без нужного числа кандидатов
+const PendingAssignmentJobName = "pending_reviewer_assignment"
+
+// NoReviewersPolicy - что делать при создании PR, если из команды автора некого назначить
+type NoReviewersPolicy string
+
+const (
+	// NoReviewersAllow создаёт PR без ревьюеров, значение по умолчанию
+	NoReviewersAllow NoReviewersPolicy = "allow"
+	// NoReviewersFail отклоняет создание PR с ErrNoCandidate
+	NoReviewersFail NoReviewersPolicy = "fail"
+	// NoReviewersAssignAuthor назначает ревьюером самого автора, чтобы PR не висел без владельца ревью
+	NoReviewersAssignAuthor NoReviewersPolicy = "author"
+)
+
+// PartialReassignPolicy - что делать при замене нескольких ревьюеров, если заменить удаётся не всех
+type PartialReassignPolicy string
+
+const (
+	// PartialReassignFail отклоняет всю замену с ErrNoCandidate, значение по умолчанию
+	PartialReassignFail PartialReassignPolicy = "fail"
+	// PartialReassignFill заменяет тех, для кого нашлись кандидаты, остальные ревьюеры остаются на PR
+	PartialReassignFill PartialReassignPolicy = "fill"
+)
+
+// ShadowStrategy - альтернативная стратегия выбора ревьюеров, которая в shadow mode только логируется
+type ShadowStrategy string
+
+const (
+	// ShadowStrategyRandom - случайный выбор из всех кандидатов без учёта рабочих окон
+	ShadowStrategyRandom ShadowStrategy = "random"
+	// ShadowStrategyLeastLoaded - кандидаты с наименьшим числом открытых ревью
+	ShadowStrategyLeastLoaded ShadowStrategy = "least_loaded"
+)
+
+// MergeActorPolicy - кому из участников PR разрешён merge от своего имени (merged_by)
+type MergeActorPolicy string
+
+const (
+	// MergeActorAuthorOrReviewer - автор или назначенный ревьюер, значение по умолчанию
+	MergeActorAuthorOrReviewer MergeActorPolicy = "author_or_reviewer"
+	// MergeActorAuthor - только автор PR
+	MergeActorAuthor MergeActorPolicy = "author"
+	// MergeActorReviewer - только назначенный ревьюер
+	MergeActorReviewer MergeActorPolicy = "reviewer"
+)
+
+// defaultMaxReviewers - лимит ревьюеров PR, если Config.MaxReviewers не задан
+const defaultMaxReviewers = 10
+
+// defaultReviewersPerPR - сколько ревьюеров набирает автоназначение, если Config.ReviewersPerPR не задан
+const defaultReviewersPerPR = 2
+
+// defaultLatencyMinSamples - минимальная выборка ревьюера в GetReviewLatency, если Config.LatencyMinSamples не задан
+const defaultLatencyMinSamples = 5
+
+// queueTurnaroundWindow - за какой срок до запроса GetQueueInfo считает медиану ревью
+const queueTurnaroundWindow = 30 * 24 * time.Hour
+
+// defaultBackfillBatchSize - порция BackfillReviewerHistory, если размер не задан
+const defaultBackfillBatchSize = 500
+
+// reviewerHistoryBackfill - имя прогресса BackfillReviewerHistory в backfill_watermarks
+const reviewerHistoryBackfill = "reviewer_history"
+
+// reassignRateWindow - окно, в котором считаются замены для Config.MaxReassignmentsPerHour
+const reassignRateWindow = time.Hour
+
+type Config struct {
+	// ExcludeOutsideWorkingHours исключает кандидатов вне рабочего окна,
+	// иначе они лишь назначаются в последнюю очередь
+	ExcludeOutsideWorkingHours bool
+	// NoReviewersPolicy применяется, если не удалось назначить ни одного ревьюера, пустое значение - NoReviewersAllow
+	NoReviewersPolicy NoReviewersPolicy
+	// AllowCrossTeamReviewers разрешает явно назначать ревьюеров не из команды автора
+	AllowCrossTeamReviewers bool
+	// MaxOpenReviews - сколько открытых PR может быть на ревью у одного пользователя при автоназначении,
+	// 0 - без ограничения. На PR с приоритетом HIGH ограничение не действует
+	MaxOpenReviews int
+	// MaxReviewers - максимум ревьюеров одного PR для любого пути назначения, 0 - defaultMaxReviewers
+	MaxReviewers int
+	// ReviewersPerPR - сколько ревьюеров набирает автоназначение и сколько считается полным составом,
+	// 0 - defaultReviewersPerPR. Больше MaxReviewers не бывает
+	ReviewersPerPR int
+	// RetryUnderstaffed оставляет PR с неполным составом ревьюеров в очереди pending assignment:
+	// AssignPendingReviewers добирает ревьюеров, когда появляются кандидаты, а не один раз
+	RetryUnderstaffed bool
+	// LatencyMinSamples - сколько смерженных PR нужно ревьюеру, чтобы попасть в отчёт о задержке ревью,
+	// 0 - defaultLatencyMinSamples
+	LatencyMinSamples int
+	// AllowMissingAuthorTeam создаёт PR без ревьюеров, даже если команды автора нет,
+	// иначе CreatePullRequest отвечает ErrAuthorTeamMissing. Проверка работает только вместе с WithTeams
+	AllowMissingAuthorTeam bool
+	// RejectInactiveAuthors отклоняет создание PR неактивным автором с ErrAuthorInactive,
+	// иначе PR создаётся с подсказкой AuthorInactive
+	RejectInactiveAuthors bool
+	// AutoCreateAuthor создаёт неизвестного автора нового PR активным участником DefaultTeam,
+	// иначе CreatePullRequest отвечает ErrUserNotFound
+	AutoCreateAuthor bool
+	DefaultTeam      string
+	// MaxTeamMembers - максимум участников DefaultTeam, в которую AutoCreateAuthor добавляет автора,
+	// 0 - без ограничения. Работает только вместе с WithTeams
+	MaxTeamMembers int
+	// ExcludeGroupAuthors не назначает ревьюерами авторов других открытых PR той же группы (GroupID)
+	ExcludeGroupAuthors bool
+	// MaxReassignmentsPerHour - сколько раз за скользящий час можно заменить ревьюера одного PR
+	// через ReassignReviewer, 0 - без ограничения. Считаются все снятия ревьюеров PR из истории событий
+	MaxReassignmentsPerHour int
+	// PartialReassignPolicy применяется в ReassignReviewers, пустое значение - PartialReassignFail
+	PartialReassignPolicy PartialReassignPolicy
+	// ShadowStrategy при создании PR выбирает ревьюеров ещё и этой стратегией, но только логирует результат
+	// и считает совпадения с назначенными. Пустое значение отключает shadow mode
+	ShadowStrategy ShadowStrategy
+	// MergeRequiresActor отклоняет merge без merged_by с ErrMergeActorRequired
+	MergeRequiresActor bool
+	// MergeActorPolicy проверяется для каждого переданного merged_by, пустое значение - MergeActorAuthorOrReviewer
+	MergeActorPolicy MergeActorPolicy
+	// MandatoryReviewers - метка PR -> пользователи, которые назначаются ревьюерами нового PR с этой меткой
+	// вне зависимости от команды. Они занимают места из ReviewersPerPR, автоматически выбирается остаток;
+	// если обязательных больше MaxReviewers, создание отклоняется с ErrReviewerCapReached. Неактивные пропускаются
+	MandatoryReviewers map[string][]string
+}
+
+type Option func(*PullRequestService)
+
+func WithConfig(cfg Config) Option {
+	return func(s *PullRequestService) {
+		s.cfg = cfg
+	}
+}
+
+func WithClock(c clock.Clock) Option {
+	return func(s *PullRequestService) {
+		s.clock = c
+	}
+}
+
+// WithBlackouts включает окна команд без автоназначения, без опции окна не проверяются
+func WithBlackouts(repo BlackoutRepository) Option {
+	return func(s *PullRequestService) {
+		s.blackouts = repo
+	}
+}
+
+// WithEvents включает уведомления о создании и мерже PR, без опции события не публикуются
+func WithEvents(publisher EventPublisher) Option {
+	return func(s *PullRequestService) {
+		s.events = publisher
+	}
+}
+
+// WithNotifier включает уведомления о назначении и замене ревьюеров при создании PR и переназначении
+func WithNotifier(notifier Notifier) Option {
+	return func(s *PullRequestService) {
+		s.notifier = notifier
+	}
+}
+
+// WithAudit отправляет в sink каждое назначение и снятие ревьюера, без опции аудит не ведётся
+func WithAudit(sink AuditSink) Option {
+	return func(s *PullRequestService) {
+		s.audit = sink
+	}
+}
+
+// WithLiveUpdates публикует назначения, снятия ревьюеров и мерж PR для SubscribeUpdates,
+// без опции подписка на изменения недоступна
+func WithLiveUpdates(live LiveUpdates) Option {
+	return func(s *PullRequestService) {
+		s.live = live
+	}
+}
+
+// WithTeams включает проверку существования команды автора при создании PR
+func WithTeams(repo TeamRepository) Option {
+	return func(s *PullRequestService) {
+		s.teams = repo
+	}
+}
+
+// WithShadowCounters считает совпадения и расхождения shadow-стратегии с назначенными ревьюерами,
+// без опции счётчики никуда не экспортируются
+func WithShadowCounters(agreed, disagreed prometheus.Counter) Option {
+	return func(s *PullRequestService) {
+		s.shadowAgreed = agreed
+		s.shadowDisagreed = disagreed
+	}
 }
 
 type PullRequestService struct {
 	prRepo    PullRequestRepository
 	userRepo  UserRepository
+	blackouts BlackoutRepository
+	teams     TeamRepository
+	events    EventPublisher
+	notifier  Notifier
+	audit     AuditSink
+	live      LiveUpdates
 	txManager db.TransactionManagerInterface
 	lg        *slog.Logger
+	cfg       Config
+	clock     clock.Clock
+
+	shadowAgreed    prometheus.Counter
+	shadowDisagreed prometheus.Counter
 }
 
 func NewPullRequestService(
@@ -42,13 +316,23 @@ func NewPullRequestService(
 	userRepo UserRepository,
 	txManager db.TransactionManagerInterface,
 	lg *slog.Logger,
+	opts ...Option,
 ) *PullRequestService {
-	return &PullRequestService{
+	s := &PullRequestService{
 		prRepo:    prRepo,
 		userRepo:  userRepo,
 		txManager: txManager,
 		lg:        lg,
+		clock:     clock.New(),
+
+		shadowAgreed:    prometheus.NewCounter(prometheus.CounterOpts{Name: "reviewer_shadow_agreed_total"}),
+		shadowDisagreed: prometheus.NewCounter(prometheus.CounterOpts{Name: "reviewer_shadow_disagreed_total"}),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 // автоматически назначаются до двух активных ревьюеров из команды автора, исключая самого автора
@@ -56,50 +340,129 @@ func NewPullRequestService(
 // автор PR не может быть ревьюером
 func (s *PullRequestService) CreatePullRequest(ctx context.Context, prCreate domain.PullRequestCreate) (*domain.PullRequest, error) {
 	op := "PullRequestService.CreatePullRequest"
-	log := s.lg.With(
+	log := logctx.With(ctx, s.lg,
 		slog.String("op", op),
 		slog.String("pr_id", prCreate.PullRequestID),
 		slog.String("author_id", prCreate.AuthorID),
 	)
 
-	author, err := s.getPRAuthor(ctx, prCreate.AuthorID)
+	if err := prCreate.Validate(); err != nil {
+		log.DebugContext(ctx, "invalid PR", slog.Any("error", err))
+		return nil, err
+	}
+
+	author, placeholder, err := s.resolveAuthor(ctx, prCreate.AuthorID)
 	if err != nil {
 		return nil, err
 	}
-	log.Debug("found author", slog.String("team_name", author.TeamName))
+	log.DebugContext(ctx, "found author", slog.String("team_name", author.TeamName), slog.Bool("placeholder", placeholder))
 
-	var pr *domain.PullRequest
+	if err := s.checkAuthor(ctx, log, author); err != nil {
+		return nil, err
+	}
+	var (
+		pr *domain.PullRequest
+		// выбор активной стратегии и его кандидаты для сравнения в shadow mode
+		selected   []string
+		shadowPool []domain.User
+	)
 	err = s.txManager.Do(ctx, func(txCtx context.Context) error {
 		exists, err := s.prRepo.Exists(txCtx, prCreate.PullRequestID)
 		if err != nil {
 			return fmt.Errorf("failed to check PR existence: %w", err)
 		}
 		if exists {
-			return domain.ErrPRExists
+			existing, err := s.prRepo.GetPullRequestByID(txCtx, prCreate.PullRequestID)
+			if err != nil {
+				return fmt.Errorf("failed to get existing PR: %w", err)
+			}
+			return &domain.ConflictError{Err: domain.ErrPRExists, Payload: existing}
 		}
 
-		candidates, err := s.getReviewCandidates(txCtx, author.TeamName, []string{prCreate.AuthorID})
-		if err != nil {
+		// неизвестный автор сохраняется в той же транзакции: при отказе в создании PR он не остаётся
+		if placeholder {
+			if author, placeholder, err = s.createPlaceholderAuthor(txCtx, log, *author); err != nil {
+				return err
+			}
+		}
+
+		if err := s.checkAuthorTeam(txCtx, author.TeamName); err != nil {
+			log.WarnContext(txCtx, "author's team does not exist, rejecting PR", slog.String("team_name", author.TeamName))
 			return err
 		}
-		log.Debug("found candidates", slog.Int("count", len(candidates)))
 
-		reviewers := utils.SelectRandomReviewers(candidates, 2)
-		reviewerIDs := make([]string, len(reviewers))
-		for i, r := range reviewers {
-			reviewerIDs[i] = r.UserID
+		inBlackout, err := s.isInBlackout(txCtx, author.TeamName)
+		if err != nil {
+			return err
+		}
+		mandatory, err := s.mandatoryReviewers(txCtx, log, prCreate)
+		if err != nil {
+			return err
+		}
+		// обязательные ревьюеры занимают места из ReviewersPerPR, остальные достаются автоматическому выбору
+		if err := domain.CheckReviewerCap(s.maxReviewers(), len(mandatory)); err != nil {
+			log.DebugContext(txCtx, "mandatory reviewers exceed reviewer cap", slog.Int("mandatory", len(mandatory)))
+			return err
 		}
+		autoSlots := max(s.reviewersPerPR()-len(mandatory), 0)
 
-		log.Debug("selected reviewers", slog.Any("reviewer_ids", reviewerIDs))
+		reviewerIDs := []string{}
+		var candidates []domain.User
+		if inBlackout {
+			// ревьюеров назначит AssignPendingReviewers после окончания окна
+			log.InfoContext(txCtx, "author's team is in blackout, deferring reviewer assignment")
+			prCreate.PendingAssignment = true
+		} else if autoSlots > 0 {
+			groupAuthors, err := s.groupAuthors(txCtx, prCreate.GroupID, prCreate.PullRequestID)
+			if err != nil {
+				return err
+			}
+			// обязательные ревьюеры назначаются отдельно и не выбираются повторно
+			exclude := slices.Concat([]string{prCreate.AuthorID}, groupAuthors, mandatory)
+			candidates, err = s.getReviewCandidates(txCtx, author.TeamName, exclude)
+			if err != nil {
+				return err
+			}
+			poolSize := len(candidates)
+
+			candidates, err = s.filterByCapacity(txCtx, log, candidates, prCreate.Priority.OrDefault())
+			if err != nil {
+				return err
+			}
+			log.DebugContext(txCtx, "found candidates", slog.Int("pool_size", poolSize), slog.Int("count", len(candidates)))
+
+			chosen, err := s.selectByStrategy(txCtx, log, candidates, autoSlots, prCreate.Strategy)
+			if err != nil {
+				return err
+			}
+			reviewerIDs, err = s.resolveReviewers(chosen, prCreate.AuthorID, len(mandatory))
+			if err != nil {
+				log.DebugContext(txCtx, "no reviewers available, rejecting PR")
+				return err
+			}
+			log.DebugContext(txCtx, "selected reviewers", slog.Any("reviewer_ids", reviewerIDs),
+				slog.String("strategy", string(prCreate.Strategy)))
+			selected, shadowPool = reviewerIDs, candidates
+		}
 
 		_, err = s.prRepo.CreatePullRequest(txCtx, prCreate)
 		if err != nil {
 			return fmt.Errorf("failed to create PR: %w", err)
 		}
 
-		for _, reviewerID := range reviewerIDs {
-			if err := s.prRepo.AssignReviewer(txCtx, prCreate.PullRequestID, reviewerID); err != nil {
-				return fmt.Errorf("failed to assign reviewer %s: %w", reviewerID, err)
+		// обязательные назначаются первыми: NoReviewersPolicy действует, только если не назначен и никто из них
+		assignedMandatory, err := s.assignMandatory(txCtx, log, prCreate.PullRequestID, mandatory)
+		if err != nil {
+			return err
+		}
+		assigned, err := s.assignWithFallback(txCtx, log, prCreate.PullRequestID, prCreate.AuthorID, reviewerIDs, candidates, len(assignedMandatory))
+		if err != nil {
+			return err
+		}
+		if total := len(assigned) + len(assignedMandatory); !inBlackout && s.understaffed(total) {
+			log.InfoContext(txCtx, "not enough reviewers, queueing PR for retry", slog.Int("assigned", total))
+			if err := s.prRepo.MarkPendingAssignment(txCtx, prCreate.PullRequestID); err != nil {
+				return err
 			}
 		}
 
@@ -113,20 +476,42 @@ func (s *PullRequestService) CreatePullRequest(ctx context.Context, prCreate dom
 	})
 
 	if err != nil {
-		log.Error("failed to create PR", slog.Any("error", err))
+		// отключение клиента - не сбой сервиса, уровень ответа выбирает обработчик
+		if !errors.Is(err, context.Canceled) {
+			log.ErrorContext(ctx, "failed to create PR", slog.Any("error", err))
+		}
 		return nil, err
 	}
 
-	log.Info("new PR created")
+	if placeholder {
+		log.InfoContext(ctx, "placeholder author created", slog.String("team_name", author.TeamName))
+	}
+	pr.AuthorInactive = !author.IsActive
+	log.InfoContext(ctx, "new PR created", slog.Bool("author_inactive", pr.AuthorInactive))
+	s.publish(domain.PREventCreated, pr)
+	s.notifyCreated(*pr)
+	s.recordChanges(ctx, pr.PullRequestID, domain.AssignmentSourceAuto, nil, pr.AssignedReviewers)
+	// после коммита: запросы shadow-стратегии не должны влиять на транзакцию и ответ
+	s.shadowSelect(ctx, log, shadowPool, selected)
 	return pr, nil
 }
 
-func (s *PullRequestService) MergePullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
+// MergePullRequest проверяет mergedBy через checkMergeActor в той же транзакции, что и merge.
+// Повторный merge не меняет сохранённый merged_by
+func (s *PullRequestService) MergePullRequest(ctx context.Context, prID, mergedBy string) (*domain.PullRequest, error) {
 	op := "PullRequestService.MergePullRequest"
-	log := s.lg.With(slog.String("op", op), slog.String("pr_id", prID))
+	log := logctx.With(ctx, s.lg, slog.String("op", op), slog.String("pr_id", prID))
 
-	var pr *domain.PullRequest
+	var (
+		pr        *domain.PullRequest
+		mergedNow bool
+	)
 	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		// конкурентные merge одного PR выполняются по очереди
+		if err := s.prRepo.LockPullRequest(txCtx, prID); err != nil {
+			return err
+		}
+
 		exists, err := s.prRepo.Exists(txCtx, prID)
 		if err != nil {
 			return fmt.Errorf("failed to check PR existence: %w", err)
@@ -135,17 +520,37 @@ func (s *PullRequestService) MergePullRequest(ctx context.Context, prID string)
 			return domain.ErrPRNotFound
 		}
 
-		if err := s.prRepo.MergePullRequest(txCtx, prID); err != nil {
+		if mergedBy != "" || s.cfg.MergeRequiresActor {
+			current, err := s.prRepo.GetPullRequestByID(txCtx, prID)
+			if err != nil {
+				return fmt.Errorf("failed to get PR: %w", err)
+			}
+			if err := s.checkMergeActor(txCtx, current, mergedBy); err != nil {
+				log.DebugContext(txCtx, "merge actor rejected", slog.String("merged_by", mergedBy), slog.String("error", err.Error()))
+				return err
+			}
+		}
+
+		merged, err := s.prRepo.MergePullRequest(txCtx, prID, s.reviewersPerPR(), mergedBy)
+		if err != nil {
 			if errors.Is(err, repository.ErrNotFound) {
 				return domain.ErrPRNotFound
 			}
 			return fmt.Errorf("failed to merge PR: %w", err)
 		}
+		if !merged {
+			log.DebugContext(txCtx, "PR already merged")
+		}
+		mergedNow = merged
 
 		mergedPR, err := s.prRepo.GetPullRequestByID(txCtx, prID)
 		if err != nil {
 			return fmt.Errorf("failed to get merged PR: %w", err)
 		}
+		// закрытый без мержа PR смержить нельзя, повторный merge остаётся идемпотентным
+		if err := domain.ValidateTransition(mergedPR.Status, domain.PRStatusMerged, domain.TransitionMerge); err != nil {
+			return err
+		}
 		pr = mergedPR
 
 		return nil
@@ -155,14 +560,213 @@ func (s *PullRequestService) MergePullRequest(ctx context.Context, prID string)
 		return nil, err
 	}
 
-	log.Info("PR merged")
+	if pr.MergedReviewerCount != nil {
+		log = log.With(slog.Int("reviewer_count", *pr.MergedReviewerCount), slog.Bool("understaffed", pr.MergedUnderstaffed))
+	}
+	log.InfoContext(ctx, "PR merged")
+	// повторный идемпотентный merge не уведомляет второй раз
+	if mergedNow {
+		s.publishMerged(pr)
+	}
 	return pr, nil
 }
 
+// publishMerged уведомляет о мерже PR подписчиков событий и live-обновлений, вызывается после коммита
+func (s *PullRequestService) publishMerged(pr *domain.PullRequest) {
+	s.publish(domain.PREventMerged, pr)
+	if s.live != nil {
+		s.live.Publish(domain.PRUpdate{Type: domain.PRUpdateMerged, PullRequestID: pr.PullRequestID, OccurredAt: s.clock.Now()})
+	}
+}
+
+// checkMergeActor проверяет, что mergedBy существует, активен и может мержить pr по MergeActorPolicy.
+// Пустой mergedBy допустим только без MergeRequiresActor
+func (s *PullRequestService) checkMergeActor(ctx context.Context, pr *domain.PullRequest, mergedBy string) error {
+	if mergedBy == "" {
+		if s.cfg.MergeRequiresActor {
+			return domain.ErrMergeActorRequired
+		}
+		return nil
+	}
+
+	actor, err := s.userRepo.GetByID(ctx, mergedBy)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrMergeActorNotFound
+		}
+		return fmt.Errorf("failed to get merge actor: %w", err)
+	}
+	if !actor.IsActive {
+		return domain.ErrMergeActorInactive
+	}
+
+	isAuthor := mergedBy == pr.AuthorID
+	isReviewer := slices.Contains(pr.AssignedReviewers, mergedBy)
+
+	var allowed bool
+	switch s.cfg.MergeActorPolicy {
+	case MergeActorAuthor:
+		allowed = isAuthor
+	case MergeActorReviewer:
+		allowed = isReviewer
+	default:
+		allowed = isAuthor || isReviewer
+	}
+	if !allowed {
+		return domain.ErrMergeNotAllowed
+	}
+
+	return nil
+}
+
+// publish отправляет событие после закоммиченной операции, если включены уведомления
+func (s *PullRequestService) publish(eventType domain.PREventType, pr *domain.PullRequest) {
+	if s.events == nil {
+		return
+	}
+	s.events.Publish(domain.PREvent{
+		Type:            eventType,
+		PullRequestID:   pr.PullRequestID,
+		PullRequestName: pr.PullRequestName,
+		AuthorID:        pr.AuthorID,
+		Reviewers:       pr.AssignedReviewers,
+		OccurredAt:      s.clock.Now(),
+	})
+}
+
+// notifyCreated сообщает о ревьюерах нового PR. PR в ожидании (blackout, нехватка кандидатов)
+// не считается оставшимся без ревьюеров: о назначении сообщит уже AssignPendingReviewers
+func (s *PullRequestService) notifyCreated(pr domain.PullRequest) {
+	switch {
+	case s.notifier == nil:
+	case len(pr.AssignedReviewers) > 0:
+		s.notifier.NotifyAssignment(pr, pr.AssignedReviewers)
+	case !pr.PendingAssignment:
+		s.notifier.NotifyNoCandidate(pr, "")
+	}
+}
+
+// recordChanges отправляет снятия и назначения закоммиченной операции подписчикам PR и в AuditSink.
+// Отмена запроса клиентом не прерывает запись, ошибки синка только логируются
+func (s *PullRequestService) recordChanges(ctx context.Context, prID string, source domain.AssignmentSource, removed, assigned []string) {
+	now := s.clock.Now()
+	if s.live != nil {
+		for _, userID := range removed {
+			s.live.Publish(domain.PRUpdate{Type: domain.PRUpdateReviewerRemoved, PullRequestID: prID, UserID: userID, OccurredAt: now})
+		}
+		for _, userID := range assigned {
+			s.live.Publish(domain.PRUpdate{
+				Type: domain.PRUpdateReviewerAssigned, PullRequestID: prID, UserID: userID, Source: source, OccurredAt: now,
+			})
+		}
+	}
+
+	if s.audit == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	for _, userID := range removed {
+		record := domain.AuditRecord{PullRequestID: prID, UserID: userID, OccurredAt: now}
+		if err := s.audit.RecordRemoval(ctx, record); err != nil {
+			s.lg.WarnContext(ctx, "failed to record reviewer removal in audit",
+				slog.String("pr_id", prID), slog.String("user_id", userID), slog.Any("error", err))
+		}
+	}
+	for _, userID := range assigned {
+		record := domain.AuditRecord{PullRequestID: prID, UserID: userID, Source: source, OccurredAt: now}
+		if err := s.audit.RecordAssignment(ctx, record); err != nil {
+			s.lg.WarnContext(ctx, "failed to record reviewer assignment in audit",
+				slog.String("pr_id", prID), slog.String("user_id", userID), slog.Any("error", err))
+		}
+	}
+}
+
+// UpdatePullRequest применяет в одной транзакции только переданные поля.
+// Переименование возможно только у открытого PR, поэтому оно выполняется до смены статуса
+func (s *PullRequestService) UpdatePullRequest(ctx context.Context, update domain.PullRequestUpdate) (*domain.PullRequest, error) {
+	op := "PullRequestService.UpdatePullRequest"
+	log := logctx.With(ctx, s.lg, slog.String("op", op), slog.String("pr_id", update.PullRequestID))
+
+	var (
+		updatedPR *domain.PullRequest
+		mergedNow bool
+	)
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := s.prRepo.LockPullRequest(txCtx, update.PullRequestID); err != nil {
+			return err
+		}
+
+		pr, err := s.prRepo.GetPullRequestByID(txCtx, update.PullRequestID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrPRNotFound
+			}
+			return fmt.Errorf("failed to get PR: %w", err)
+		}
+
+		// PATCH меняет статус только мержем
+		if update.Status != nil {
+			if err := domain.ValidateTransition(pr.Status, *update.Status, domain.TransitionMerge); err != nil {
+				log.DebugContext(txCtx, "rejected status transition",
+					slog.String("from", string(pr.Status)),
+					slog.String("to", string(*update.Status)))
+				return err
+			}
+		}
+
+		if update.PullRequestName != nil && *update.PullRequestName != pr.PullRequestName {
+			if err := pr.EnsureOpen(); err != nil {
+				return err
+			}
+			if err := s.prRepo.RenamePullRequest(txCtx, update.PullRequestID, *update.PullRequestName); err != nil {
+				return mutationError(err, "failed to rename PR")
+			}
+		}
+
+		if update.Status != nil && *update.Status == domain.PRStatusMerged && !pr.IsMerged() {
+			if err := s.checkMergeActor(txCtx, pr, update.MergedBy); err != nil {
+				return err
+			}
+			merged, err := s.prRepo.MergePullRequest(txCtx, update.PullRequestID, s.reviewersPerPR(), update.MergedBy)
+			if err != nil {
+				return fmt.Errorf("failed to merge PR: %w", err)
+			}
+			mergedNow = merged
+		}
+
+		updatedPR, err = s.prRepo.GetPullRequestByID(txCtx, update.PullRequestID)
+		if err != nil {
+			return fmt.Errorf("failed to get updated PR: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	log.InfoContext(ctx, "PR updated", slog.Bool("merged", mergedNow))
+	if mergedNow {
+		s.publishMerged(updatedPR)
+	}
+	return updatedPR, nil
+}
+
 // после merge менять список ревьюеров нельзя
 func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*domain.PullRequest, string, error) {
-	op := "PullRequestService.ReassignReviewer"
-	log := s.lg.With(
+	return s.reassignReviewer(ctx, "PullRequestService.ReassignReviewer", prID, oldUserID, false)
+}
+
+// ReassignIfInactive заменяет ревьюера, только если он сейчас неактивен. Активный ревьюер не трогается:
+// возвращается PR без изменений и пустой id замены. Для фоновых чисток, которые не должны задевать активных
+func (s *PullRequestService) ReassignIfInactive(ctx context.Context, prID, userID string) (*domain.PullRequest, string, error) {
+	return s.reassignReviewer(ctx, "PullRequestService.ReassignIfInactive", prID, userID, true)
+}
+
+func (s *PullRequestService) reassignReviewer(ctx context.Context, op, prID, oldUserID string, onlyInactive bool) (*domain.PullRequest, string, error) {
+	log := logctx.With(ctx, s.lg,
 		slog.String("op", op),
 		slog.String("pr_id", prID),
 		slog.String("old_user_id", oldUserID),
@@ -180,17 +784,13 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, oldUser
 			return fmt.Errorf("failed to get PR: %w", err)
 		}
 
-		if pr.IsMerged() {
-			log.Debug("cannot reassign on merged PR")
-			return domain.ErrPRMerged
+		if err := pr.EnsureOpen(); err != nil {
+			log.DebugContext(txCtx, "cannot reassign on PR that is not open", slog.String("status", string(pr.Status)))
+			return err
 		}
 
-		isAssigned, err := s.prRepo.IsReviewerAssigned(txCtx, prID, oldUserID)
-		if err != nil {
-			return fmt.Errorf("failed to check reviewer assignment: %w", err)
-		}
-		if !isAssigned {
-			log.Debug("user not assigned as reviewer")
+		if !slices.Contains(pr.AssignedReviewers, oldUserID) {
+			log.DebugContext(txCtx, "user not assigned as reviewer")
 			return domain.ErrNotAssigned
 		}
 
@@ -202,31 +802,64 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, oldUser
 			return fmt.Errorf("failed to get old reviewer: %w", err)
 		}
 
-		log.Debug("found old reviewer", slog.String("team_name", oldReviewer.TeamName))
+		log.DebugContext(txCtx, "found old reviewer", slog.String("team_name", oldReviewer.TeamName))
+
+		if onlyInactive && oldReviewer.IsActive {
+			log.DebugContext(txCtx, "reviewer is active, leaving untouched")
+			updatedPR = pr
+			return nil
+		}
+
+		if !onlyInactive {
+			if err := s.checkReassignRate(txCtx, prID, 1); err != nil {
+				return err
+			}
+		}
 
+		groupAuthors, err := s.groupAuthors(txCtx, pr.GroupID, pr.PullRequestID)
+		if err != nil {
+			return err
+		}
 		excludeIDs := []string{pr.AuthorID}
 		excludeIDs = append(excludeIDs, pr.AssignedReviewers...)
+		excludeIDs = append(excludeIDs, groupAuthors...)
 
+		// замена из пула снятого ревьюера: ревьюера из другой команды заменяет участник его команды, а не команды автора
 		candidates, err := s.getReviewCandidates(txCtx, oldReviewer.TeamName, excludeIDs)
 		if err != nil {
 			return err
 		}
-		log.Debug("found candidates for reassignment", slog.Int("count", len(candidates)))
+		poolSize := len(candidates)
+		candidates, err = s.filterByCapacity(txCtx, log, candidates, pr.Priority.OrDefault())
+		if err != nil {
+			return err
+		}
+		log.DebugContext(txCtx, "found candidates for reassignment",
+			slog.Int("pool_size", poolSize), slog.Int("count", len(candidates)))
 
 		if len(candidates) == 0 {
-			log.Debug("no active replacement candidates available")
-			return domain.ErrNoCandidate
+			log.DebugContext(txCtx, "no active replacement candidates available")
+			return s.noCandidateError(txCtx, log, oldReviewer.TeamName, excludeIDs, poolSize, pr.Priority.OrDefault())
 		}
 
-		newReviewer := utils.SelectRandomReviewers(candidates, 1)[0]
-		log.Info("selected new reviewer", slog.String("new_user_id", newReviewer.UserID))
+		newReviewer := s.selectReviewers(candidates, 1)[0]
+		log.InfoContext(txCtx, "selected new reviewer", slog.String("new_user_id", newReviewer.UserID))
+
+		removed, err := s.prRepo.RemoveReviewer(txCtx, prID, oldUserID)
+		if err != nil {
+			return mutationError(err, "failed to remove reviewer")
+		}
+		if !removed {
+			log.DebugContext(txCtx, "reviewer removed concurrently")
+			return domain.ErrNotAssigned
+		}
 
-		if err := s.prRepo.RemoveReviewer(txCtx, prID, oldUserID); err != nil {
-			return fmt.Errorf("failed to remove reviewer: %w", err)
+		if err := s.prRepo.AssignReviewer(txCtx, prID, newReviewer.UserID, domain.AssignmentSourceReassignment); err != nil {
+			return mutationError(err, "failed to assign new reviewer")
 		}
 
-		if err := s.prRepo.AssignReviewer(txCtx, prID, newReviewer.UserID); err != nil {
-			return fmt.Errorf("failed to assign new reviewer: %w", err)
+		if err := s.prRepo.IncrementReassignmentCount(txCtx, prID); err != nil {
+			return fmt.Errorf("failed to count reassignment: %w", err)
 		}
 
 		pr, err = s.prRepo.GetPullRequestByID(txCtx, prID)
@@ -243,27 +876,1648 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, oldUser
 		return nil, "", err
 	}
 
-	log.Info("reviewer reassigned")
+	if newReviewerID != "" {
+		log.InfoContext(ctx, "reviewer reassigned")
+		if s.notifier != nil {
+			s.notifier.NotifyReassignment(*updatedPR, oldUserID, newReviewerID)
+		}
+		s.recordChanges(ctx, prID, domain.AssignmentSourceReassignment, []string{oldUserID}, []string{newReviewerID})
+	}
 	return updatedPR, newReviewerID, nil
 }
 
-func (s *PullRequestService) getPRAuthor(ctx context.Context, authorID string) (*domain.User, error) {
-	author, err := s.userRepo.GetByID(ctx, authorID)
-	if err != nil {
-		if errors.Is(err, repository.ErrNotFound) {
-			return nil, domain.ErrUserNotFound
-		}
-		return nil, fmt.Errorf("failed to get author: %w", err)
+// ReassignReviewers заменяет сразу несколько ревьюеров в одной транзакции. Замены выбираются по очереди
+// из команды каждого снятого, уже выбранные исключаются, поэтому один кандидат не достаётся двоим.
+// Если заменить некем хотя бы одного, решает PartialReassignPolicy; при fill без единой замены - ErrNoCandidate
+func (s *PullRequestService) ReassignReviewers(ctx context.Context, prID string, oldUserIDs []string) (*domain.PullRequest, domain.ReassignResult, error) {
+	op := "PullRequestService.ReassignReviewers"
+	log := logctx.With(ctx, s.lg,
+		slog.String("op", op),
+		slog.String("pr_id", prID),
+		slog.Any("old_user_ids", oldUserIDs),
+	)
+
+	if len(oldUserIDs) == 0 {
+		return nil, domain.ReassignResult{}, domain.ErrInvalidInput
 	}
 
-	return author, nil
-}
+	var updatedPR *domain.PullRequest
+	var result domain.ReassignResult
 
-func (s *PullRequestService) getReviewCandidates(ctx context.Context, teamName string, exclude []string) ([]domain.User, error) {
-	candidates, err := s.userRepo.GetActiveByTeam(ctx, teamName, exclude)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get team members: %w", err)
-	}
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		result = domain.ReassignResult{Replacements: []domain.ReviewerReplacement{}, Unreplaced: []string{}}
 
-	return candidates, nil
+		pr, err := s.prRepo.GetPullRequestByID(txCtx, prID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrPRNotFound
+			}
+			return fmt.Errorf("failed to get PR: %w", err)
+		}
+
+		if err := pr.EnsureOpen(); err != nil {
+			log.DebugContext(txCtx, "cannot reassign on PR that is not open", slog.String("status", string(pr.Status)))
+			return err
+		}
+
+		for _, oldUserID := range oldUserIDs {
+			if !slices.Contains(pr.AssignedReviewers, oldUserID) {
+				log.DebugContext(txCtx, "user not assigned as reviewer", slog.String("old_user_id", oldUserID))
+				return domain.ErrNotAssigned
+			}
+		}
+
+		if err := s.checkReassignRate(txCtx, prID, len(oldUserIDs)); err != nil {
+			return err
+		}
+
+		groupAuthors, err := s.groupAuthors(txCtx, pr.GroupID, pr.PullRequestID)
+		if err != nil {
+			return err
+		}
+		excludeIDs := []string{pr.AuthorID}
+		excludeIDs = append(excludeIDs, pr.AssignedReviewers...)
+		excludeIDs = append(excludeIDs, groupAuthors...)
+
+		// разбивку команды собираем, только если она попадёт в ответ
+		var noCandidate func() error
+		for _, oldUserID := range oldUserIDs {
+			oldReviewer, err := s.userRepo.GetByID(txCtx, oldUserID)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return domain.ErrUserNotFound
+				}
+				return fmt.Errorf("failed to get old reviewer: %w", err)
+			}
+
+			candidates, err := s.getReviewCandidates(txCtx, oldReviewer.TeamName, excludeIDs)
+			if err != nil {
+				return err
+			}
+			poolSize := len(candidates)
+			candidates, err = s.filterByCapacity(txCtx, log, candidates, pr.Priority.OrDefault())
+			if err != nil {
+				return err
+			}
+
+			if len(candidates) == 0 {
+				log.DebugContext(txCtx, "no active replacement candidates available", slog.String("old_user_id", oldUserID))
+				if noCandidate == nil {
+					teamName, exclude := oldReviewer.TeamName, slices.Clone(excludeIDs)
+					noCandidate = func() error {
+						return s.noCandidateError(txCtx, log, teamName, exclude, poolSize, pr.Priority.OrDefault())
+					}
+				}
+				if s.cfg.PartialReassignPolicy != PartialReassignFill {
+					return noCandidate()
+				}
+				result.Unreplaced = append(result.Unreplaced, oldUserID)
+				continue
+			}
+
+			newReviewer := s.selectReviewers(candidates, 1)[0]
+			excludeIDs = append(excludeIDs, newReviewer.UserID)
+			result.Replacements = append(result.Replacements, domain.ReviewerReplacement{
+				PullRequestID: prID,
+				OldUserID:     oldUserID,
+				NewUserID:     newReviewer.UserID,
+			})
+		}
+		if len(result.Replacements) == 0 {
+			return noCandidate()
+		}
+
+		for _, replacement := range result.Replacements {
+			removed, err := s.prRepo.RemoveReviewer(txCtx, prID, replacement.OldUserID)
+			if err != nil {
+				return mutationError(err, "failed to remove reviewer")
+			}
+			if !removed {
+				log.DebugContext(txCtx, "reviewer removed concurrently", slog.String("old_user_id", replacement.OldUserID))
+				return domain.ErrNotAssigned
+			}
+
+			if err := s.prRepo.AssignReviewer(txCtx, prID, replacement.NewUserID, domain.AssignmentSourceReassignment); err != nil {
+				return mutationError(err, "failed to assign new reviewer")
+			}
+
+			if err := s.prRepo.IncrementReassignmentCount(txCtx, prID); err != nil {
+				return fmt.Errorf("failed to count reassignment: %w", err)
+			}
+		}
+
+		updatedPR, err = s.prRepo.GetPullRequestByID(txCtx, prID)
+		if err != nil {
+			return fmt.Errorf("failed to get updated PR: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, domain.ReassignResult{}, err
+	}
+
+	removed := make([]string, len(result.Replacements))
+	assigned := make([]string, len(result.Replacements))
+	for i, replacement := range result.Replacements {
+		removed[i], assigned[i] = replacement.OldUserID, replacement.NewUserID
+		if s.notifier != nil {
+			s.notifier.NotifyReassignment(*updatedPR, replacement.OldUserID, replacement.NewUserID)
+		}
+	}
+	log.InfoContext(ctx, "reviewers reassigned",
+		slog.Any("new_user_ids", assigned), slog.Any("unreplaced", result.Unreplaced))
+	s.recordChanges(ctx, prID, domain.AssignmentSourceReassignment, removed, assigned)
+
+	return updatedPR, result, nil
+}
+
+// AssignReviewer явно назначает ревьюера на PR в обход случайного выбора
+func (s *PullRequestService) AssignReviewer(ctx context.Context, prID, userID string) (*domain.PullRequest, error) {
+	op := "PullRequestService.AssignReviewer"
+	log := logctx.With(ctx, s.lg, slog.String("op", op), slog.String("pr_id", prID), slog.String("user_id", userID))
+
+	pr, err := s.assignReviewer(ctx, prID, userID, func(txCtx context.Context, pr *domain.PullRequest) error {
+		return s.validateReviewerEligible(txCtx, pr, userID, s.cfg.AllowCrossTeamReviewers)
+	})
+	if err != nil {
+		log.DebugContext(ctx, "reviewer is not assigned", slog.Any("error", err))
+		return nil, err
+	}
+
+	log.InfoContext(ctx, "reviewer assigned explicitly")
+	return pr, nil
+}
+
+// SelfAssign добавляет пользователя ревьюером по его собственной инициативе: только из команды автора
+// (независимо от AllowCrossTeamReviewers) и только пока у PR есть место до MaxReviewers
+func (s *PullRequestService) SelfAssign(ctx context.Context, prID, userID string) (*domain.PullRequest, error) {
+	op := "PullRequestService.SelfAssign"
+	log := logctx.With(ctx, s.lg, slog.String("op", op), slog.String("pr_id", prID), slog.String("user_id", userID))
+
+	pr, err := s.assignReviewer(ctx, prID, userID, func(txCtx context.Context, pr *domain.PullRequest) error {
+		return s.validateReviewerEligible(txCtx, pr, userID, false)
+	})
+	if err != nil {
+		log.DebugContext(ctx, "self-assignment rejected", slog.Any("error", err))
+		return nil, err
+	}
+
+	log.InfoContext(ctx, "reviewer assigned themselves")
+	return pr, nil
+}
+
+// assignReviewer назначает userID под блокировкой PR, check проверяет уже загруженный открытый PR.
+// Все пути, добавляющие ревьюера к существующему PR, проходят через него и лимит MaxReviewers
+func (s *PullRequestService) assignReviewer(
+	ctx context.Context,
+	prID, userID string,
+	check func(ctx context.Context, pr *domain.PullRequest) error,
+) (*domain.PullRequest, error) {
+	var updatedPR *domain.PullRequest
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := s.prRepo.LockPullRequest(txCtx, prID); err != nil {
+			return err
+		}
+
+		pr, err := s.prRepo.GetPullRequestByID(txCtx, prID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrPRNotFound
+			}
+			return fmt.Errorf("failed to get PR: %w", err)
+		}
+
+		if err := pr.EnsureOpen(); err != nil {
+			return err
+		}
+
+		if err := check(txCtx, pr); err != nil {
+			return err
+		}
+
+		if err := s.ensureReviewerRoom(pr); err != nil {
+			return err
+		}
+
+		if err := s.prRepo.AssignReviewer(txCtx, prID, userID, domain.AssignmentSourceManual); err != nil {
+			return mutationError(err, "failed to assign reviewer")
+		}
+
+		updatedPR, err = s.prRepo.GetPullRequestByID(txCtx, prID)
+		if err != nil {
+			return fmt.Errorf("failed to get updated PR: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordChanges(ctx, prID, domain.AssignmentSourceManual, nil, []string{userID})
+	return updatedPR, nil
+}
+
+// SnoozeReview откладывает ревью открытого PR назначенным ревьюером до until, не снимая его:
+// до этого момента PR не показывается ему в /users/getReview. Повторный вызов переносит срок
+func (s *PullRequestService) SnoozeReview(ctx context.Context, prID, userID string, until time.Time) (*domain.ReviewSnooze, error) {
+	op := "PullRequestService.SnoozeReview"
+	log := logctx.With(ctx, s.lg, slog.String("op", op), slog.String("pr_id", prID), slog.String("user_id", userID))
+
+	if !until.After(s.clock.Now()) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := s.prRepo.LockPullRequest(txCtx, prID); err != nil {
+			return err
+		}
+
+		pr, err := s.prRepo.GetPullRequestByID(txCtx, prID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrPRNotFound
+			}
+			return fmt.Errorf("failed to get PR: %w", err)
+		}
+
+		if err := pr.EnsureOpen(); err != nil {
+			return err
+		}
+		if !slices.Contains(pr.AssignedReviewers, userID) {
+			return domain.ErrNotAssigned
+		}
+
+		if err := s.prRepo.SnoozeReview(txCtx, prID, userID, until); err != nil {
+			return mutationError(err, "failed to snooze review")
+		}
+		return nil
+	})
+	if err != nil {
+		log.DebugContext(ctx, "review is not snoozed", slog.Any("error", err))
+		return nil, err
+	}
+
+	log.InfoContext(ctx, "review snoozed", slog.Time("until", until))
+	return &domain.ReviewSnooze{PullRequestID: prID, UserID: userID, Until: until}, nil
+}
+
+// validateReviewerEligible проверяет инварианты, которые случайный выбор обеспечивает через GetActiveByTeam.
+// Обязателен для любого пути, назначающего конкретного пользователя
+func (s *PullRequestService) validateReviewerEligible(ctx context.Context, pr *domain.PullRequest, userID string, allowCrossTeam bool) error {
+	if userID == pr.AuthorID {
+		return domain.ErrReviewerIsAuthor
+	}
+	if slices.Contains(pr.AssignedReviewers, userID) {
+		return domain.ErrAlreadyAssigned
+	}
+
+	reviewer, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get reviewer: %w", err)
+	}
+	if !reviewer.IsActive {
+		return domain.ErrReviewerInactive
+	}
+
+	if allowCrossTeam {
+		return nil
+	}
+
+	author, err := s.getPRAuthor(ctx, pr.AuthorID)
+	if err != nil {
+		return err
+	}
+	if author.TeamName != reviewer.TeamName {
+		return domain.ErrReviewerNotInTeam
+	}
+
+	return nil
+}
+
+// SetReviewers целиком заменяет список ревьюеров PR - инструмент поддержки для ручного исправления данных.
+// Проверяет только существование пользователей, автора и лимит MaxReviewers: активность и команда
+// намеренно не учитываются. force разрешает менять смерженный PR и превышать MaxReviewers
+func (s *PullRequestService) SetReviewers(ctx context.Context, prID string, reviewerIDs []string, force bool) (*domain.PullRequest, error) {
+	op := "PullRequestService.SetReviewers"
+	log := logctx.With(ctx, s.lg, slog.String("op", op), slog.String("pr_id", prID))
+
+	reviewerIDs = uniqueIDs(reviewerIDs)
+	if !force {
+		if err := domain.CheckReviewerCap(s.maxReviewers(), len(reviewerIDs)); err != nil {
+			return nil, err
+		}
+	}
+
+	var updatedPR *domain.PullRequest
+	var oldReviewers []string
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := s.prRepo.LockPullRequest(txCtx, prID); err != nil {
+			return err
+		}
+
+		pr, err := s.prRepo.GetPullRequestByID(txCtx, prID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrPRNotFound
+			}
+			return fmt.Errorf("failed to get PR: %w", err)
+		}
+		oldReviewers = pr.AssignedReviewers
+
+		if !force {
+			if err := pr.EnsureOpen(); err != nil {
+				return err
+			}
+		}
+
+		for _, userID := range reviewerIDs {
+			if userID == pr.AuthorID {
+				return domain.ErrReviewerIsAuthor
+			}
+			if _, err := s.userRepo.GetByID(txCtx, userID); err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return domain.ErrUserNotFound
+				}
+				return fmt.Errorf("failed to get reviewer: %w", err)
+			}
+		}
+
+		if err := s.prRepo.ReplaceReviewers(txCtx, prID, reviewerIDs, domain.AssignmentSourceAdmin); err != nil {
+			return err
+		}
+
+		updatedPR, err = s.prRepo.GetPullRequestByID(txCtx, prID)
+		if err != nil {
+			return fmt.Errorf("failed to get updated PR: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.DebugContext(ctx, "reviewers are not replaced", slog.Any("error", err))
+		return nil, err
+	}
+
+	log.WarnContext(ctx, "reviewers replaced manually",
+		slog.Any("old_reviewers", oldReviewers),
+		slog.Any("new_reviewers", updatedPR.AssignedReviewers),
+		slog.String("status", string(updatedPR.Status)))
+	removed := slices.DeleteFunc(slices.Clone(oldReviewers), func(id string) bool {
+		return slices.Contains(updatedPR.AssignedReviewers, id)
+	})
+	assigned := slices.DeleteFunc(slices.Clone(updatedPR.AssignedReviewers), func(id string) bool {
+		return slices.Contains(oldReviewers, id)
+	})
+	s.recordChanges(ctx, prID, domain.AssignmentSourceAdmin, removed, assigned)
+	return updatedPR, nil
+}
+
+// GetReviewLatency считает по ревьюерам команды, сколько проходит от назначения до мержа PR,
+// смерженных в [from, to). Ревьюеры с малой выборкой отбрасываются как шум
+func (s *PullRequestService) GetReviewLatency(ctx context.Context, teamName string, from, to *time.Time) ([]domain.ReviewerLatency, error) {
+	if err := auth.AuthorizeTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+	if from != nil && to != nil && from.After(*to) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	latencies, err := s.prRepo.GetReviewLatency(ctx, teamName, from, to, s.latencyMinSamples())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review latency: %w", err)
+	}
+
+	return latencies, nil
+}
+
+// GetQueueInfo - сколько открытых PR стоит перед prID у каждого его ревьюера и медиана их ревью
+// за queueTurnaroundWindow. Очереди и медианы читаются параллельно, поэтому не внутри транзакции
+func (s *PullRequestService) GetQueueInfo(ctx context.Context, prID string) (*domain.PRQueueInfo, error) {
+	pr, err := s.prRepo.GetPullRequestByID(ctx, prID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrPRNotFound
+		}
+		return nil, fmt.Errorf("failed to get PR: %w", err)
+	}
+
+	info := &domain.PRQueueInfo{PullRequestID: pr.PullRequestID, Status: pr.Status, MergedAt: pr.MergedAt}
+	if pr.Status != domain.PRStatusOpen || len(pr.AssignedReviewers) == 0 {
+		return info, nil
+	}
+
+	var (
+		queues    []domain.ReviewerQueuePosition
+		latencies []domain.ReviewerLatency
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		if queues, err = s.prRepo.GetReviewerQueues(gctx, prID); err != nil {
+			return fmt.Errorf("failed to get reviewer queues: %w", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		var err error
+		from := s.clock.Now().Add(-queueTurnaroundWindow)
+		if latencies, err = s.prRepo.GetReviewerLatencyByUsers(gctx, pr.AssignedReviewers, from, s.latencyMinSamples()); err != nil {
+			return fmt.Errorf("failed to get review latency: %w", err)
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	medians := make(map[string]time.Duration, len(latencies))
+	for _, latency := range latencies {
+		medians[latency.UserID] = latency.Median
+	}
+	for i := range queues {
+		if median, ok := medians[queues[i].UserID]; ok {
+			queues[i].MedianTurnaround = &median
+		}
+	}
+	info.Reviewers = queues
+	return info, nil
+}
+
+// GetTeamPolicy - политика назначения, действующая для команды. Отдельных настроек у команды нет,
+// поэтому от глобальной политики её отличает только активный blackout
+func (s *PullRequestService) GetTeamPolicy(ctx context.Context, teamName string) (*domain.TeamPolicy, error) {
+	if err := auth.AuthorizeTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+	if s.teams != nil {
+		exists, err := s.teams.Exists(ctx, teamName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check team: %w", err)
+		}
+		if !exists {
+			return nil, domain.ErrTeamNotFound
+		}
+	}
+
+	noReviewers := s.cfg.NoReviewersPolicy
+	if noReviewers == "" {
+		noReviewers = NoReviewersAllow
+	}
+	policy := &domain.TeamPolicy{
+		TeamName:                   teamName,
+		MaxReviewers:               s.maxReviewers(),
+		ReviewersPerPR:             s.reviewersPerPR(),
+		MaxOpenReviews:             max(s.cfg.MaxOpenReviews, 0),
+		NoReviewersPolicy:          string(noReviewers),
+		ExcludeOutsideWorkingHours: s.cfg.ExcludeOutsideWorkingHours,
+		AllowCrossTeamReviewers:    s.cfg.AllowCrossTeamReviewers,
+		ExcludeGroupAuthors:        s.cfg.ExcludeGroupAuthors,
+		RetryUnderstaffed:          s.cfg.RetryUnderstaffed,
+		AutoAssign:                 true,
+		Overrides:                  []string{},
+	}
+
+	inBlackout, err := s.isInBlackout(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if inBlackout {
+		policy.AutoAssign = false
+		policy.Overrides = append(policy.Overrides, "auto_assign")
+	}
+
+	return policy, nil
+}
+
+// checkReassignRate проверяет, что ещё n замен уложатся в MaxReassignmentsPerHour. PR блокируется
+// до конца транзакции, чтобы параллельные замены не прошли проверку по одному и тому же числу снятий
+func (s *PullRequestService) checkReassignRate(ctx context.Context, prID string, n int) error {
+	limit := s.cfg.MaxReassignmentsPerHour
+	if limit <= 0 {
+		return nil
+	}
+
+	if err := s.prRepo.LockPullRequest(ctx, prID); err != nil {
+		return err
+	}
+	removals, err := s.prRepo.GetReviewerRemovalsSince(ctx, prID, s.clock.Now().Add(-reassignRateWindow))
+	if err != nil {
+		return fmt.Errorf("failed to count reassignments: %w", err)
+	}
+	if len(removals)+n <= limit {
+		return nil
+	}
+
+	// замена станет возможна, когда из окна выйдет столько снятий, чтобы их осталось limit-n.
+	// Больше limit замен за раз не пройдут никогда, тогда ориентиром служит последнее снятие
+	now := s.clock.Now()
+	retryAt := now.Add(reassignRateWindow)
+	if idx := min(len(removals)-limit+n-1, len(removals)-1); idx >= 0 {
+		retryAt = removals[idx].Add(reassignRateWindow)
+	}
+	return &domain.ConflictError{
+		Err:     domain.ErrReassignRateExceeded,
+		Payload: &domain.ReassignRateLimit{Limit: limit, RetryAt: retryAt, RetryAfter: retryAt.Sub(now)},
+	}
+}
+
+func (s *PullRequestService) latencyMinSamples() int {
+	if s.cfg.LatencyMinSamples > 0 {
+		return s.cfg.LatencyMinSamples
+	}
+	return defaultLatencyMinSamples
+}
+
+// GetPullRequest возвращает PR по id, на него указывает Location ответа создания PR
+func (s *PullRequestService) GetPullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	pr, err := s.prRepo.GetPullRequestByID(ctx, prID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrPRNotFound
+		}
+		return nil, fmt.Errorf("failed to get PR: %w", err)
+	}
+
+	return pr, nil
+}
+
+// SubscribeUpdates подписывает на изменения существующего PR с момента вызова. Канал закрывается
+// после cancel или если подписчик не успевает разбирать изменения
+func (s *PullRequestService) SubscribeUpdates(ctx context.Context, prID string) (<-chan domain.PRUpdate, func(), error) {
+	if s.live == nil {
+		return nil, nil, errors.New("live updates are not enabled")
+	}
+
+	exists, err := s.prRepo.Exists(ctx, prID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check PR existence: %w", err)
+	}
+	if !exists {
+		return nil, nil, domain.ErrPRNotFound
+	}
+
+	updates, cancel := s.live.Subscribe(prID)
+	return updates, cancel, nil
+}
+
+// GetPullRequestsByIDs возвращает найденные PR в порядке prIDs, неизвестные ID пропускаются
+func (s *PullRequestService) GetPullRequestsByIDs(ctx context.Context, prIDs []string) ([]domain.PullRequest, error) {
+	prs, err := s.prRepo.GetPullRequestsByIDs(ctx, prIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PRs: %w", err)
+	}
+
+	return prs, nil
+}
+
+// GetRecentPullRequests - limit последних созданных PR всех команд для ленты активности
+func (s *PullRequestService) GetRecentPullRequests(ctx context.Context, limit int) ([]domain.RecentPullRequest, error) {
+	if limit <= 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	prs, err := s.prRepo.GetRecentPullRequests(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent PRs: %w", err)
+	}
+
+	return prs, nil
+}
+
+// DetectAnomalies ищет назначения ревьюеров, которые не могли возникнуть при текущей конфигурации.
+// Автор-ревьюер допустим при NoReviewersAssignAuthor, ревьюер из другой команды - при AllowCrossTeamReviewers;
+// обязательные ревьюеры из MandatoryReviewers назначаются из любой команды и аномалией не считаются
+func (s *PullRequestService) DetectAnomalies(ctx context.Context) (*domain.AnomalyReport, error) {
+	op := "PullRequestService.DetectAnomalies"
+	log := logctx.With(ctx, s.lg, slog.String("op", op))
+
+	var report domain.AnomalyReport
+	err := s.txManager.DoReadOnly(ctx, func(txCtx context.Context) error {
+		var err error
+		report, err = s.prRepo.GetReviewerAnomalies(txCtx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect anomalies: %w", err)
+	}
+
+	report.Skipped = []domain.AnomalyKind{}
+	if s.cfg.NoReviewersPolicy == NoReviewersAssignAuthor {
+		report.AuthorIsReviewer = []domain.ReviewerAnomaly{}
+		report.Skipped = append(report.Skipped, domain.AnomalyAuthorIsReviewer)
+	}
+	if s.cfg.AllowCrossTeamReviewers {
+		report.CrossTeamReviewer = []domain.ReviewerAnomaly{}
+		report.Skipped = append(report.Skipped, domain.AnomalyCrossTeamReviewer)
+	} else {
+		mandatory := make(map[string]struct{})
+		for _, userIDs := range s.cfg.MandatoryReviewers {
+			for _, userID := range userIDs {
+				mandatory[userID] = struct{}{}
+			}
+		}
+		report.CrossTeamReviewer = slices.DeleteFunc(report.CrossTeamReviewer, func(a domain.ReviewerAnomaly) bool {
+			_, ok := mandatory[a.UserID]
+			return ok
+		})
+	}
+
+	log.InfoContext(ctx, "anomalies detected",
+		slog.Int("author_is_reviewer", len(report.AuthorIsReviewer)),
+		slog.Int("cross_team_reviewer", len(report.CrossTeamReviewer)),
+		slog.Int("unknown_reviewer", len(report.UnknownReviewer)),
+	)
+	return &report, nil
+}
+
+// облегчённый вариант GetPullRequestByID для опроса текущих ревьюеров
+func (s *PullRequestService) GetReviewerIDs(ctx context.Context, prID string) ([]string, error) {
+	exists, err := s.prRepo.Exists(ctx, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check PR existence: %w", err)
+	}
+	if !exists {
+		return nil, domain.ErrPRNotFound
+	}
+
+	reviewerIDs, err := s.prRepo.GetReviewerIDs(ctx, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewers: %w", err)
+	}
+
+	return reviewerIDs, nil
+}
+
+// ReassignInactiveReviewers заменяет неактивных ревьюеров открытых PR активными участниками их команд.
+// Если кандидатов нет, ревьюер снимается, как при деактивации. Каждая замена - отдельная транзакция
+func (s *PullRequestService) ReassignInactiveReviewers(ctx context.Context) (*domain.InactiveReassignReport, error) {
+	op := "PullRequestService.ReassignInactiveReviewers"
+	log := logctx.With(ctx, s.lg, slog.String("op", op))
+
+	assignments, err := s.prRepo.GetInactiveReviewerAssignments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan inactive reviewers: %w", err)
+	}
+	log.DebugContext(ctx, "found inactive reviewers", slog.Int("count", len(assignments)))
+
+	report := &domain.InactiveReassignReport{
+		Reassigned: []domain.ReviewerReplacement{},
+		Removed:    []domain.ReviewerReplacement{},
+		Skipped:    []domain.ReviewerAssignment{},
+	}
+
+	for _, assignment := range assignments {
+		var replacement domain.ReviewerReplacement
+		err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+			var err error
+			replacement, err = s.replaceInactiveReviewer(txCtx, assignment)
+			return err
+		})
+
+		switch {
+		case errors.Is(err, repository.ErrNotOpen) || errors.Is(err, domain.ErrPRNotOpen):
+			log.InfoContext(ctx, "skipping PR that is no longer open", slog.String("pr_id", assignment.PullRequestID))
+			report.Skipped = append(report.Skipped, assignment)
+		case errors.Is(err, domain.ErrNotAssigned):
+			log.InfoContext(ctx, "skipping reviewer removed meanwhile", slog.String("pr_id", assignment.PullRequestID))
+			report.Skipped = append(report.Skipped, assignment)
+		case err != nil:
+			return nil, fmt.Errorf("failed to replace reviewer %s on PR %s: %w", assignment.UserID, assignment.PullRequestID, err)
+		case replacement.NewUserID == "":
+			report.Removed = append(report.Removed, replacement)
+			s.recordChanges(ctx, replacement.PullRequestID, domain.AssignmentSourceAdmin, []string{replacement.OldUserID}, nil)
+		default:
+			report.Reassigned = append(report.Reassigned, replacement)
+			s.recordChanges(ctx, replacement.PullRequestID, domain.AssignmentSourceAdmin,
+				[]string{replacement.OldUserID}, []string{replacement.NewUserID})
+		}
+	}
+
+	log.InfoContext(ctx, "inactive reviewers processed",
+		slog.Int("reassigned", len(report.Reassigned)),
+		slog.Int("removed", len(report.Removed)),
+		slog.Int("skipped", len(report.Skipped)))
+
+	return report, nil
+}
+
+// BackfillReviewerHistory создаёт события ASSIGNED для назначений, сделанных до появления pr_reviewer_events.
+// Каждая порция - отдельная транзакция вместе с сохранением watermark, поэтому прерванный запуск
+// продолжается с последней завершённой порции. restart начинает с начала таблицы, уже созданные события не дублируются
+func (s *PullRequestService) BackfillReviewerHistory(ctx context.Context, batchSize int, restart bool) (*domain.BackfillReport, error) {
+	op := "PullRequestService.BackfillReviewerHistory"
+	log := logctx.With(ctx, s.lg, slog.String("op", op))
+
+	if batchSize <= 0 {
+		batchSize = defaultBackfillBatchSize
+	}
+	if restart {
+		if err := s.prRepo.SaveBackfillWatermark(ctx, reviewerHistoryBackfill, domain.BackfillWatermark{}); err != nil {
+			return nil, fmt.Errorf("failed to reset watermark: %w", err)
+		}
+	}
+
+	report := &domain.BackfillReport{}
+	for {
+		var batch domain.BackfillBatch
+		err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+			after, err := s.prRepo.LockBackfillWatermark(txCtx, reviewerHistoryBackfill)
+			if err != nil {
+				return err
+			}
+			if report.Batches == 0 {
+				report.Watermark = after
+			}
+
+			batch, err = s.prRepo.BackfillAssignedEvents(txCtx, after, batchSize)
+			if err != nil {
+				return err
+			}
+			if batch.Scanned == 0 {
+				return nil
+			}
+			return s.prRepo.SaveBackfillWatermark(txCtx, reviewerHistoryBackfill, batch.Last)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to backfill batch after %s/%s: %w",
+				report.Watermark.PullRequestID, report.Watermark.UserID, err)
+		}
+
+		if batch.Scanned > 0 {
+			report.Batches++
+			report.Scanned += batch.Scanned
+			report.Inserted += batch.Inserted
+			report.Watermark = batch.Last
+			log.InfoContext(ctx, "reviewer history batch done",
+				slog.Int("batch", report.Batches),
+				slog.Int("scanned", report.Scanned),
+				slog.Int("inserted", report.Inserted),
+				slog.String("watermark_pr_id", batch.Last.PullRequestID))
+		}
+		if batch.Scanned < batchSize {
+			break
+		}
+	}
+
+	log.InfoContext(ctx, "reviewer history backfilled",
+		slog.Int("batches", report.Batches),
+		slog.Int("scanned", report.Scanned),
+		slog.Int("inserted", report.Inserted))
+
+	return report, nil
+}
+
+func (s *PullRequestService) replaceInactiveReviewer(ctx context.Context, assignment domain.ReviewerAssignment) (domain.ReviewerReplacement, error) {
+	replacement := domain.ReviewerReplacement{
+		PullRequestID: assignment.PullRequestID,
+		OldUserID:     assignment.UserID,
+	}
+
+	if err := s.prRepo.LockPullRequest(ctx, assignment.PullRequestID); err != nil {
+		return replacement, err
+	}
+
+	pr, err := s.prRepo.GetPullRequestByID(ctx, assignment.PullRequestID)
+	if err != nil {
+		return replacement, fmt.Errorf("failed to get PR: %w", err)
+	}
+	if err := pr.EnsureOpen(); err != nil {
+		return replacement, err
+	}
+
+	groupAuthors, err := s.groupAuthors(ctx, pr.GroupID, pr.PullRequestID)
+	if err != nil {
+		return replacement, err
+	}
+	excludeIDs := []string{pr.AuthorID}
+	excludeIDs = append(excludeIDs, pr.AssignedReviewers...)
+	excludeIDs = append(excludeIDs, groupAuthors...)
+
+	candidates, err := s.getReviewCandidates(ctx, assignment.TeamName, excludeIDs)
+	if err != nil {
+		return replacement, err
+	}
+
+	removed, err := s.prRepo.RemoveReviewer(ctx, assignment.PullRequestID, assignment.UserID)
+	if err != nil {
+		return replacement, fmt.Errorf("failed to remove reviewer: %w", err)
+	}
+	if !removed {
+		return replacement, domain.ErrNotAssigned
+	}
+
+	selected := s.selectReviewers(candidates, 1)
+	if len(selected) == 0 {
+		return replacement, nil
+	}
+
+	if err := s.prRepo.AssignReviewer(ctx, assignment.PullRequestID, selected[0].UserID, domain.AssignmentSourceAdmin); err != nil {
+		return replacement, fmt.Errorf("failed to assign reviewer: %w", err)
+	}
+
+	if err := s.prRepo.IncrementReassignmentCount(ctx, assignment.PullRequestID); err != nil {
+		return replacement, fmt.Errorf("failed to count reassignment: %w", err)
+	}
+	replacement.NewUserID = selected[0].UserID
+
+	return replacement, nil
+}
+
+// RebalanceTeam переносит ревью открытых PR команды с самых загруженных участников на наименее загруженных,
+// пока разница нагрузки у пары больше одного PR. Нагрузка считается по всем открытым PR участника,
+// переносятся только ревью PR авторов команды. Каждый перенос - отдельная транзакция
+func (s *PullRequestService) RebalanceTeam(ctx context.Context, teamName string) (*domain.RebalanceReport, error) {
+	op := "PullRequestService.RebalanceTeam"
+	log := logctx.With(ctx, s.lg, slog.String("op", op), slog.String("team_name", teamName))
+
+	if err := auth.AuthorizeTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	members, err := s.getReviewCandidates(ctx, teamName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.RebalanceReport{
+		Moved:      []domain.ReviewerReplacement{},
+		LoadBefore: map[string]int{},
+		LoadAfter:  map[string]int{},
+	}
+	if len(members) < 2 {
+		return report, nil
+	}
+
+	memberIDs := make([]string, len(members))
+	for i, m := range members {
+		memberIDs[i] = m.UserID
+	}
+	counts, err := s.prRepo.GetOpenReviewCounts(ctx, memberIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open review counts: %w", err)
+	}
+	loads := make(map[string]int, len(members))
+	for _, id := range memberIDs {
+		loads[id] = counts[id]
+		report.LoadBefore[id] = counts[id]
+	}
+
+	assignments, err := s.prRepo.GetTeamReviewAssignments(ctx, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team review assignments: %w", err)
+	}
+	// ревью неактивных участников остаются для ReassignInactiveReviewers
+	assignments = slices.DeleteFunc(assignments, func(a domain.ReviewerAssignment) bool {
+		_, ok := loads[a.UserID]
+		return !ok
+	})
+	slices.SortStableFunc(assignments, func(a, b domain.ReviewerAssignment) int {
+		return loads[b.UserID] - loads[a.UserID]
+	})
+
+	for _, assignment := range assignments {
+		if loads[assignment.UserID]-slices.Min(slices.Collect(maps.Values(loads))) <= 1 {
+			// разница с наименее загруженным не больше одного, транзакция не нужна
+			continue
+		}
+
+		var moved domain.ReviewerReplacement
+		err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+			var err error
+			moved, err = s.rebalanceReview(txCtx, assignment, members, loads)
+			return err
+		})
+
+		switch {
+		case errors.Is(err, repository.ErrNotOpen) || errors.Is(err, domain.ErrPRNotOpen):
+			log.InfoContext(ctx, "skipping PR that is no longer open", slog.String("pr_id", assignment.PullRequestID))
+		case errors.Is(err, domain.ErrNotAssigned):
+			log.InfoContext(ctx, "skipping reviewer removed meanwhile", slog.String("pr_id", assignment.PullRequestID))
+		case isCandidateRace(err):
+			log.WarnContext(ctx, "skipping review, target rejected", slog.String("pr_id", assignment.PullRequestID), slog.Any("error", err))
+		case err != nil:
+			return nil, fmt.Errorf("failed to move review of %s on PR %s: %w", assignment.UserID, assignment.PullRequestID, err)
+		case moved.NewUserID != "":
+			loads[moved.OldUserID]--
+			loads[moved.NewUserID]++
+			report.Moved = append(report.Moved, moved)
+			s.recordChanges(ctx, moved.PullRequestID, domain.AssignmentSourceRebalance, []string{moved.OldUserID}, []string{moved.NewUserID})
+		}
+	}
+
+	maps.Copy(report.LoadAfter, loads)
+	log.InfoContext(ctx, "team reviews rebalanced", slog.Int("moved", len(report.Moved)))
+
+	return report, nil
+}
+
+// rebalanceReview переносит одно ревью на наименее загруженного подходящего участника.
+// Пустой NewUserID - переносить некуда или незачем
+func (s *PullRequestService) rebalanceReview(
+	ctx context.Context,
+	assignment domain.ReviewerAssignment,
+	members []domain.User,
+	loads map[string]int,
+) (domain.ReviewerReplacement, error) {
+	moved := domain.ReviewerReplacement{
+		PullRequestID: assignment.PullRequestID,
+		OldUserID:     assignment.UserID,
+	}
+
+	if err := s.prRepo.LockPullRequest(ctx, assignment.PullRequestID); err != nil {
+		return moved, err
+	}
+
+	pr, err := s.prRepo.GetPullRequestByID(ctx, assignment.PullRequestID)
+	if err != nil {
+		return moved, fmt.Errorf("failed to get PR: %w", err)
+	}
+	if err := pr.EnsureOpen(); err != nil {
+		return moved, err
+	}
+	if !slices.Contains(pr.AssignedReviewers, assignment.UserID) {
+		return moved, nil
+	}
+
+	candidates := slices.DeleteFunc(slices.Clone(members), func(u domain.User) bool {
+		return u.UserID == pr.AuthorID || slices.Contains(pr.AssignedReviewers, u.UserID)
+	})
+	target, ok := utils.SelectLeastLoaded(candidates, loads)
+	if !ok || loads[assignment.UserID]-loads[target.UserID] <= 1 {
+		return moved, nil
+	}
+
+	removed, err := s.prRepo.RemoveReviewer(ctx, pr.PullRequestID, assignment.UserID)
+	if err != nil {
+		return moved, fmt.Errorf("failed to remove reviewer: %w", err)
+	}
+	if !removed {
+		return moved, domain.ErrNotAssigned
+	}
+	if err := s.prRepo.AssignReviewer(ctx, pr.PullRequestID, target.UserID, domain.AssignmentSourceRebalance); err != nil {
+		return moved, fmt.Errorf("failed to assign reviewer %s: %w", target.UserID, err)
+	}
+	if err := s.prRepo.IncrementReassignmentCount(ctx, pr.PullRequestID); err != nil {
+		return moved, fmt.Errorf("failed to count reassignment: %w", err)
+	}
+	moved.NewUserID = target.UserID
+
+	return moved, nil
+}
+
+// AssignPendingReviewers назначает ревьюеров PR, созданным во время blackout, если окно команды автора закончилось,
+// и при RetryUnderstaffed - PR, которым при создании не хватило кандидатов.
+// PR добирается до лимита ревьюеров с учётом назначенных вручную, NoReviewersPolicy не применяется: PR уже создан.
+// Каждый PR - отдельная транзакция
+func (s *PullRequestService) AssignPendingReviewers(ctx context.Context) (*domain.PendingAssignmentReport, error) {
+	op := "PullRequestService.AssignPendingReviewers"
+	log := logctx.With(ctx, s.lg, slog.String("op", op))
+
+	prIDs, err := s.prRepo.GetPendingAssignmentPRs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending PRs: %w", err)
+	}
+
+	report := &domain.PendingAssignmentReport{
+		Assigned: []string{},
+		Waiting:  []string{},
+	}
+
+	for _, prID := range prIDs {
+		var outcome pendingOutcome
+		err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+			var err error
+			outcome, err = s.assignPendingReviewers(txCtx, prID)
+			return err
+		})
+
+		switch {
+		case errors.Is(err, repository.ErrNotOpen) || errors.Is(err, domain.ErrPRNotOpen):
+			log.InfoContext(ctx, "skipping PR that is no longer open", slog.String("pr_id", prID))
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to assign reviewers to PR %s: %w", prID, err)
+		case outcome.waiting:
+			report.Waiting = append(report.Waiting, prID)
+		default:
+			report.Assigned = append(report.Assigned, prID)
+		}
+		if s.notifier != nil && len(outcome.assigned) > 0 {
+			s.notifier.NotifyAssignment(*outcome.pr, outcome.assigned)
+		}
+		s.recordChanges(ctx, prID, domain.AssignmentSourceAuto, nil, outcome.assigned)
+	}
+
+	log.InfoContext(ctx, "pending PRs processed",
+		slog.Int("assigned", len(report.Assigned)),
+		slog.Int("waiting", len(report.Waiting)))
+
+	return report, nil
+}
+
+// PendingAssignmentJob - периодический запуск AssignPendingReviewers для планировщика
+func (s *PullRequestService) PendingAssignmentJob(interval time.Duration) jobs.Job {
+	return jobs.Job{
+		Name:     PendingAssignmentJobName,
+		Interval: interval,
+		Timeout:  interval,
+		Run: func(ctx context.Context) error {
+			_, err := s.AssignPendingReviewers(ctx)
+			return err
+		},
+	}
+}
+
+// pendingOutcome - результат assignPendingReviewers: waiting - PR остаётся в ожидании,
+// assigned - ревьюеры, назначенные в этот запуск
+type pendingOutcome struct {
+	waiting  bool
+	pr       *domain.PullRequest
+	assigned []string
+}
+
+// assignPendingReviewers оставляет PR в ожидании, если команда автора всё ещё в blackout или при RetryUnderstaffed
+// ревьюеров по-прежнему меньше лимита
+func (s *PullRequestService) assignPendingReviewers(ctx context.Context, prID string) (pendingOutcome, error) {
+	var outcome pendingOutcome
+	if err := s.prRepo.LockPullRequest(ctx, prID); err != nil {
+		return outcome, err
+	}
+
+	pr, err := s.prRepo.GetPullRequestByID(ctx, prID)
+	if err != nil {
+		return outcome, fmt.Errorf("failed to get PR: %w", err)
+	}
+	if err := pr.EnsureOpen(); err != nil {
+		return outcome, err
+	}
+	outcome.pr = pr
+
+	author, err := s.getPRAuthor(ctx, pr.AuthorID)
+	if err != nil {
+		return outcome, err
+	}
+
+	inBlackout, err := s.isInBlackout(ctx, author.TeamName)
+	if err != nil {
+		return outcome, err
+	}
+	if inBlackout {
+		outcome.waiting = true
+		return outcome, nil
+	}
+
+	if room := s.reviewersPerPR() - len(pr.AssignedReviewers); room > 0 {
+		groupAuthors, err := s.groupAuthors(ctx, pr.GroupID, pr.PullRequestID)
+		if err != nil {
+			return outcome, err
+		}
+		excludeIDs := append([]string{pr.AuthorID}, pr.AssignedReviewers...)
+		excludeIDs = append(excludeIDs, groupAuthors...)
+		candidates, err := s.getReviewCandidates(ctx, author.TeamName, excludeIDs)
+		if err != nil {
+			return outcome, err
+		}
+
+		candidates, err = s.filterByCapacity(ctx, logctx.With(ctx, s.lg, slog.String("pr_id", prID)), candidates, pr.Priority.OrDefault())
+		if err != nil {
+			return outcome, err
+		}
+
+		selected := s.selectReviewers(candidates, room)
+		for _, reviewer := range selected {
+			if err := s.prRepo.AssignReviewer(ctx, prID, reviewer.UserID, domain.AssignmentSourceAuto); err != nil {
+				return outcome, mutationError(err, "failed to assign reviewer "+reviewer.UserID)
+			}
+			outcome.assigned = append(outcome.assigned, reviewer.UserID)
+		}
+
+		if s.understaffed(len(pr.AssignedReviewers) + len(selected)) {
+			outcome.waiting = true
+			return outcome, nil
+		}
+	}
+
+	if err := s.prRepo.ClearPendingAssignment(ctx, prID); err != nil {
+		return outcome, err
+	}
+
+	return outcome, nil
+}
+
+// isInBlackout проверяет окно команды на текущий момент часов сервиса
+func (s *PullRequestService) isInBlackout(ctx context.Context, teamName string) (bool, error) {
+	if s.blackouts == nil {
+		return false, nil
+	}
+
+	inBlackout, err := s.blackouts.IsInBlackout(ctx, teamName, s.clock.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to check team blackout: %w", err)
+	}
+	return inBlackout, nil
+}
+
+// checkAuthorTeam не даёт молча создать PR без ревьюеров, если команду автора удалили в обход API
+func (s *PullRequestService) checkAuthorTeam(ctx context.Context, teamName string) error {
+	if s.teams == nil || s.cfg.AllowMissingAuthorTeam {
+		return nil
+	}
+
+	exists, err := s.teams.Exists(ctx, teamName)
+	if err != nil {
+		return fmt.Errorf("failed to check author's team: %w", err)
+	}
+	if !exists {
+		return domain.ErrAuthorTeamMissing
+	}
+	return nil
+}
+
+// groupAuthors - кого исключить из кандидатов PR prID при ExcludeGroupAuthors: авторов других открытых PR группы
+func (s *PullRequestService) groupAuthors(ctx context.Context, groupID, prID string) ([]string, error) {
+	if !s.cfg.ExcludeGroupAuthors || groupID == "" {
+		return nil, nil
+	}
+
+	authors, err := s.prRepo.GetOpenGroupAuthors(ctx, groupID, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group authors: %w", err)
+	}
+	s.lg.DebugContext(ctx, "excluding group authors", slog.String("group_id", groupID), slog.Any("author_ids", authors))
+	return authors, nil
+}
+
+func (s *PullRequestService) getPRAuthor(ctx context.Context, authorID string) (*domain.User, error) {
+	author, err := s.userRepo.GetByID(ctx, authorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get author: %w", err)
+	}
+
+	return author, nil
+}
+
+// resolveAuthor - автор нового PR. При AutoCreateAuthor неизвестный автор возвращается ещё не сохранённым
+// активным участником DefaultTeam с username, равным user_id, и placeholder = true
+func (s *PullRequestService) resolveAuthor(ctx context.Context, authorID string) (*domain.User, bool, error) {
+	author, err := s.getPRAuthor(ctx, authorID)
+	if !errors.Is(err, domain.ErrUserNotFound) || !s.cfg.AutoCreateAuthor {
+		return author, false, err
+	}
+	return &domain.User{UserID: authorID, Username: authorID, TeamName: s.cfg.DefaultTeam, IsActive: true}, true, nil
+}
+
+// checkAuthor - ключ команды создаёт PR только от имени своих участников, неактивный автор
+// отклоняется при RejectInactiveAuthors
+func (s *PullRequestService) checkAuthor(ctx context.Context, log *slog.Logger, author *domain.User) error {
+	if err := auth.AuthorizeTeam(ctx, author.TeamName); err != nil {
+		return err
+	}
+	if !author.IsActive && s.cfg.RejectInactiveAuthors {
+		log.DebugContext(ctx, "author is inactive, rejecting PR")
+		return domain.ErrAuthorInactive
+	}
+	return nil
+}
+
+// createPlaceholderAuthor сохраняет автора из resolveAuthor, если его команда не превысит MaxTeamMembers.
+// created = false - автора конкурентно создал другой запрос, возвращается сохранённый
+func (s *PullRequestService) createPlaceholderAuthor(
+	ctx context.Context,
+	log *slog.Logger,
+	author domain.User,
+) (*domain.User, bool, error) {
+	if err := s.checkTeamSize(ctx, author.TeamName); err != nil {
+		return nil, false, err
+	}
+
+	member := domain.TeamMember{UserID: author.UserID, Username: author.Username, IsActive: author.IsActive}
+	created, err := s.userRepo.Create(ctx, member, author.TeamName)
+	switch {
+	case errors.Is(err, repository.ErrAlreadyExists):
+		existing, err := s.getPRAuthor(ctx, author.UserID)
+		if err != nil {
+			return nil, false, err
+		}
+		// конкурентно созданный автор может быть в другой команде или неактивен
+		if err := s.checkAuthor(ctx, log, existing); err != nil {
+			return nil, false, err
+		}
+		return existing, false, nil
+	case errors.Is(err, repository.ErrReferenceNotFound):
+		return nil, false, domain.ErrAuthorTeamMissing
+	case err != nil:
+		return nil, false, fmt.Errorf("failed to create author: %w", err)
+	}
+	return created, true, nil
+}
+
+// checkTeamSize - команда создаваемого автора с ним не должна превысить MaxTeamMembers, без WithTeams не проверяется
+func (s *PullRequestService) checkTeamSize(ctx context.Context, teamName string) error {
+	if s.teams == nil || s.cfg.MaxTeamMembers <= 0 {
+		return nil
+	}
+
+	count, err := s.teams.CountMembers(ctx, teamName)
+	if err != nil {
+		return fmt.Errorf("failed to count team members: %w", err)
+	}
+	return domain.CheckTeamSize(s.cfg.MaxTeamMembers, count+1)
+}
+
+func (s *PullRequestService) getReviewCandidates(ctx context.Context, teamName string, exclude []string) ([]domain.User, error) {
+	exclude = normalizeExclusions(exclude)
+	s.lg.DebugContext(ctx, "loading review candidates",
+		slog.String("team_name", teamName), slog.Int("excluded", len(exclude)))
+
+	candidates, err := s.userRepo.GetActiveByTeam(ctx, teamName, exclude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team members: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// normalizeExclusions убирает пустые ID и повторы из списка исключений кандидатов, сохраняя порядок.
+// В списке могут быть ID удалённых пользователей - они безвредны и остаются. Никогда не возвращает nil
+func normalizeExclusions(ids []string) []string {
+	return uniqueIDs(slices.DeleteFunc(slices.Clone(ids), func(id string) bool { return id == "" }))
+}
+
+func (s *PullRequestService) maxReviewers() int {
+	if s.cfg.MaxReviewers > 0 {
+		return s.cfg.MaxReviewers
+	}
+	return defaultMaxReviewers
+}
+
+// reviewersPerPR - целевой состав ревьюеров PR, не больше maxReviewers
+func (s *PullRequestService) reviewersPerPR() int {
+	n := defaultReviewersPerPR
+	if s.cfg.ReviewersPerPR > 0 {
+		n = s.cfg.ReviewersPerPR
+	}
+	return min(n, s.maxReviewers())
+}
+
+// understaffed - PR с assigned ревьюерами остаётся в очереди при включённом RetryUnderstaffed
+func (s *PullRequestService) understaffed(assigned int) bool {
+	return s.cfg.RetryUnderstaffed && assigned < s.reviewersPerPR()
+}
+
+// ensureReviewerRoom - единая проверка лимита перед добавлением ревьюера к PR
+func (s *PullRequestService) ensureReviewerRoom(pr *domain.PullRequest) error {
+	if len(pr.AssignedReviewers) >= s.maxReviewers() {
+		return &domain.ConflictError{
+			Err:     domain.ErrReviewerCapReached,
+			Payload: &domain.ReviewerCapLimit{Limit: s.maxReviewers(), Reviewers: len(pr.AssignedReviewers)},
+		}
+	}
+	return nil
+}
+
+// noCandidateError дополняет ErrNoCandidate разбивкой команды по шагам отбора. Запрос выполняется
+// только на пути отказа; если он не удался, возвращается ErrNoCandidate без разбивки
+func (s *PullRequestService) noCandidateError(
+	ctx context.Context,
+	log *slog.Logger,
+	teamName string,
+	excludeIDs []string,
+	poolSize int,
+	priority domain.PRPriority,
+) error {
+	maxOpenReviews := s.cfg.MaxOpenReviews
+	if priority == domain.PRPriorityHigh {
+		maxOpenReviews = 0
+	}
+
+	breakdown, err := s.userRepo.GetCandidateBreakdown(ctx, teamName, normalizeExclusions(excludeIDs), maxOpenReviews)
+	if err != nil {
+		log.WarnContext(ctx, "failed to collect candidate breakdown", slog.Any("error", err))
+		return domain.ErrNoCandidate
+	}
+	breakdown.CandidatePool = poolSize
+
+	return &domain.ConflictError{Err: domain.ErrNoCandidate, Payload: &breakdown}
+}
+
+// filterByCapacity отбрасывает кандидатов, у которых уже MaxOpenReviews открытых ревью.
+// Для HIGH ограничение снимается, чтобы хотфикс получил ревьюера при любом активном участнике команды.
+// Если нагрузку получить не удалось, фильтр не применяется: ревьюеры выбираются случайно из всех кандидатов,
+// а не срывают создание PR. Запрос идёт в savepoint, чтобы его ошибка не прервала транзакцию
+func (s *PullRequestService) filterByCapacity(
+	ctx context.Context,
+	log *slog.Logger,
+	candidates []domain.User,
+	priority domain.PRPriority,
+) ([]domain.User, error) {
+	if s.cfg.MaxOpenReviews <= 0 || priority == domain.PRPriorityHigh || len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	userIDs := make([]string, len(candidates))
+	for i, c := range candidates {
+		userIDs[i] = c.UserID
+	}
+
+	var counts map[string]int
+	err := s.txManager.DoNested(ctx, func(ctx context.Context) error {
+		var err error
+		counts, err = s.prRepo.GetOpenReviewCounts(ctx, userIDs)
+		return err
+	})
+	if err != nil {
+		// отменённый запрос продолжать незачем
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to get open review counts: %w", err)
+		}
+		log.WarnContext(ctx, "failed to get open review counts, selecting from all candidates", slog.Any("error", err))
+		return candidates, nil
+	}
+
+	return eligibility.FilterUnderCapacity(candidates, counts, s.cfg.MaxOpenReviews), nil
+}
+
+// resolveReviewers возвращает ID выбранных стратегией ревьюеров нового PR, а если выбрать было некого
+// (в команде только автор, все неактивны или заняты) и нет других ревьюеров (mandatory), решает по NoReviewersPolicy
+func (s *PullRequestService) resolveReviewers(reviewers []domain.User, authorID string, mandatory int) ([]string, error) {
+	if len(reviewers) > 0 || mandatory > 0 {
+		reviewerIDs := make([]string, len(reviewers))
+		for i, r := range reviewers {
+			reviewerIDs[i] = r.UserID
+		}
+		return reviewerIDs, nil
+	}
+
+	switch s.cfg.NoReviewersPolicy {
+	case "", NoReviewersAllow:
+		return []string{}, nil
+	case NoReviewersFail:
+		return nil, domain.ErrNoCandidate
+	case NoReviewersAssignAuthor:
+		return []string{authorID}, nil
+	default:
+		return nil, fmt.Errorf("unknown no-reviewers policy %q", s.cfg.NoReviewersPolicy)
+	}
+}
+
+// mandatoryReviewers - активные обязательные ревьюеры для меток нового PR в порядке конфигурации.
+// Автор не назначается себе, неактивные и неизвестные пользователи пропускаются с предупреждением
+func (s *PullRequestService) mandatoryReviewers(ctx context.Context, log *slog.Logger, prCreate domain.PullRequestCreate) ([]string, error) {
+	var reviewerIDs []string
+	seen := map[string]struct{}{prCreate.AuthorID: {}}
+	for _, label := range prCreate.Labels {
+		for _, userID := range s.cfg.MandatoryReviewers[label] {
+			if _, ok := seen[userID]; ok {
+				continue
+			}
+			seen[userID] = struct{}{}
+
+			user, err := s.userRepo.GetByID(ctx, userID)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					log.WarnContext(ctx, "mandatory reviewer not found, skipping", slog.String("label", label), slog.String("reviewer_id", userID))
+					continue
+				}
+				return nil, fmt.Errorf("failed to get mandatory reviewer: %w", err)
+			}
+			if !user.IsActive {
+				log.WarnContext(ctx, "mandatory reviewer is inactive, skipping", slog.String("label", label), slog.String("reviewer_id", userID))
+				continue
+			}
+			reviewerIDs = append(reviewerIDs, userID)
+		}
+	}
+
+	return reviewerIDs, nil
+}
+
+// assignMandatory назначает обязательных ревьюеров и возвращает назначенных. Ревьюер, которого конкурентно
+// удалили или деактивировали, пропускается, как и в assignWithFallback
+func (s *PullRequestService) assignMandatory(ctx context.Context, log *slog.Logger, prID string, reviewerIDs []string) ([]string, error) {
+	assigned := make([]string, 0, len(reviewerIDs))
+	for _, reviewerID := range reviewerIDs {
+		err := s.prRepo.AssignReviewer(ctx, prID, reviewerID, domain.AssignmentSourceAuto)
+		if err == nil {
+			assigned = append(assigned, reviewerID)
+			continue
+		}
+		if !isCandidateRace(err) {
+			return nil, mutationError(err, "failed to assign mandatory reviewer "+reviewerID)
+		}
+		log.WarnContext(ctx, "mandatory reviewer rejected, skipping", slog.String("reviewer_id", reviewerID), slog.Any("error", err))
+	}
+	return assigned, nil
+}
+
+// assignWithFallback назначает выбранных ревьюеров нового PR. Если вставку ревьюера отклонила БД, потому что
+// его конкурентно удалили, деактивировали или уже назначили, кандидат отбрасывается и слот занимает следующий
+// из оставшихся в pool. Когда пул исчерпан, слот остаётся пустым; если не назначился никто, в том числе
+// из mandatory уже назначенных обязательных ревьюеров, решает NoReviewersPolicy
+func (s *PullRequestService) assignWithFallback(
+	ctx context.Context,
+	log *slog.Logger,
+	prID, authorID string,
+	chosen []string,
+	pool []domain.User,
+	mandatory int,
+) ([]string, error) {
+	remaining := slices.DeleteFunc(slices.Clone(pool), func(u domain.User) bool {
+		return slices.Contains(chosen, u.UserID)
+	})
+
+	assigned := make([]string, 0, len(chosen))
+	queue := slices.Clone(chosen)
+	for len(queue) > 0 {
+		reviewerID := queue[0]
+		queue = queue[1:]
+
+		err := s.prRepo.AssignReviewer(ctx, prID, reviewerID, domain.AssignmentSourceAuto)
+		if err == nil {
+			assigned = append(assigned, reviewerID)
+			continue
+		}
+		if !isCandidateRace(err) {
+			return nil, mutationError(err, "failed to assign reviewer "+reviewerID)
+		}
+
+		next := s.selectReviewers(remaining, 1)
+		if len(next) == 0 {
+			log.WarnContext(ctx, "reviewer rejected, candidate pool exhausted",
+				slog.String("reviewer_id", reviewerID), slog.Any("error", err))
+			continue
+		}
+
+		log.WarnContext(ctx, "reviewer rejected, falling back to next candidate",
+			slog.String("reviewer_id", reviewerID),
+			slog.String("next_reviewer_id", next[0].UserID),
+			slog.Any("error", err))
+		remaining = slices.DeleteFunc(remaining, func(u domain.User) bool { return u.UserID == next[0].UserID })
+		queue = append(queue, next[0].UserID)
+	}
+
+	// автора политика могла выбрать сама, второй раз его не пробуем
+	if len(assigned) == 0 && len(chosen) > 0 && !slices.Contains(chosen, authorID) {
+		fallback, err := s.resolveReviewers(nil, authorID, mandatory)
+		if err != nil {
+			return nil, err
+		}
+		for _, reviewerID := range fallback {
+			if err := s.prRepo.AssignReviewer(ctx, prID, reviewerID, domain.AssignmentSourceAuto); err != nil {
+				return nil, mutationError(err, "failed to assign reviewer "+reviewerID)
+			}
+		}
+		assigned = fallback
+	}
+
+	return assigned, nil
+}
+
+// isCandidateRace - вставку ревьюера отклонила БД из-за конкурентного изменения кандидата
+func isCandidateRace(err error) bool {
+	return errors.Is(err, repository.ErrReferenceNotFound) ||
+		errors.Is(err, repository.ErrAlreadyExists) ||
+		errors.Is(err, repository.ErrInactiveReviewer)
+}
+
+// selectByStrategy выбирает до count ревьюеров нового PR стратегией strategy, пустая - selectReviewers.
+// Если для least_loaded не удалось получить нагрузку, выбор идёт по умолчанию, а не срывает создание PR
+func (s *PullRequestService) selectByStrategy(
+	ctx context.Context,
+	log *slog.Logger,
+	candidates []domain.User,
+	count int,
+	strategy domain.SelectionStrategy,
+) ([]domain.User, error) {
+	switch strategy {
+	case "", domain.SelectionStrategyWorkingHours:
+		return s.selectReviewers(candidates, count), nil
+	case domain.SelectionStrategyRandom:
+		return utils.SelectRandomReviewers(candidates, count), nil
+	case domain.SelectionStrategyLeastLoaded:
+		if len(candidates) == 0 {
+			return nil, nil
+		}
+		userIDs := make([]string, len(candidates))
+		for i, c := range candidates {
+			userIDs[i] = c.UserID
+		}
+
+		var loads map[string]int
+		err := s.txManager.DoNested(ctx, func(ctx context.Context) error {
+			var err error
+			loads, err = s.prRepo.GetOpenReviewCounts(ctx, userIDs)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to get open review counts: %w", err)
+			}
+			log.WarnContext(ctx, "failed to get open review counts, using default strategy", slog.Any("error", err))
+			return s.selectReviewers(candidates, count), nil
+		}
+		return utils.SelectLeastLoadedReviewers(candidates, loads, count), nil
+	default:
+		return nil, domain.ErrInvalidInput
+	}
+}
+
+// selectReviewers выбирает до count ревьюеров, отдавая приоритет тем, кто сейчас в рабочем окне.
+// Если вне окна все кандидаты, выбор идёт из всего пула
+func (s *PullRequestService) selectReviewers(candidates []domain.User, count int) []domain.User {
+	available, outside := utils.SplitByWorkingHours(candidates, s.clock.Now())
+	if len(available) == 0 {
+		return utils.SelectRandomReviewers(candidates, count)
+	}
+
+	selected := utils.SelectRandomReviewers(available, count)
+	if s.cfg.ExcludeOutsideWorkingHours || len(selected) == count {
+		return selected
+	}
+
+	return append(selected, utils.SelectRandomReviewers(outside, count-len(selected))...)
+}
+
+// shadowSelect выбирает ревьюеров Config.ShadowStrategy из тех же кандидатов, логирует выбор и
+// считает, совпал ли он с выбором активной стратегии. Ничего не назначает, ошибки только логирует
+func (s *PullRequestService) shadowSelect(ctx context.Context, log *slog.Logger, candidates []domain.User, selected []string) {
+	if s.cfg.ShadowStrategy == "" || len(candidates) == 0 {
+		return
+	}
+
+	var shadow []domain.User
+	switch s.cfg.ShadowStrategy {
+	case ShadowStrategyRandom:
+		shadow = utils.SelectRandomReviewers(candidates, s.reviewersPerPR())
+	case ShadowStrategyLeastLoaded:
+		userIDs := make([]string, len(candidates))
+		for i, c := range candidates {
+			userIDs[i] = c.UserID
+		}
+		loads, err := s.prRepo.GetOpenReviewCounts(ctx, userIDs)
+		if err != nil {
+			log.WarnContext(ctx, "shadow strategy failed", slog.String("strategy", string(s.cfg.ShadowStrategy)), slog.Any("error", err))
+			return
+		}
+		shadow = utils.SelectLeastLoadedReviewers(candidates, loads, s.reviewersPerPR())
+	default:
+		log.WarnContext(ctx, "unknown shadow strategy", slog.String("strategy", string(s.cfg.ShadowStrategy)))
+		return
+	}
+
+	shadowIDs := make([]string, len(shadow))
+	for i, u := range shadow {
+		shadowIDs[i] = u.UserID
+	}
+
+	agreed := len(shadowIDs) == len(selected)
+	for _, id := range shadowIDs {
+		agreed = agreed && slices.Contains(selected, id)
+	}
+	if agreed {
+		s.shadowAgreed.Inc()
+	} else {
+		s.shadowDisagreed.Inc()
+	}
+
+	log.InfoContext(ctx, "shadow reviewer selection",
+		slog.String("strategy", string(s.cfg.ShadowStrategy)),
+		slog.Any("reviewer_ids", selected),
+		slog.Any("shadow_reviewer_ids", shadowIDs),
+		slog.Bool("agreed", agreed))
+}
+
+// uniqueIDs убирает повторы, сохраняя порядок
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
+
+// mutationError переводит отказ репозитория менять закрытый PR в доменную ошибку
+func mutationError(err error, msg string) error {
+	if errors.Is(err, repository.ErrNotOpen) {
+		return domain.ErrPRNotOpen
+	}
+	if errors.Is(err, repository.ErrInactiveReviewer) {
+		return domain.ErrReviewerInactive
+	}
+	return fmt.Errorf("%s: %w", msg, err)
 }
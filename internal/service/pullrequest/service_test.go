@@ -1,30 +1,43 @@
 package pullrequests
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"avito_backend_task/internal/auth"
 	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/live"
+	"avito_backend_task/internal/notify"
 	"avito_backend_task/internal/repository"
 	"avito_backend_task/internal/service/pullrequest/mocks"
+	"avito_backend_task/pkg/clock"
 	dbmocks "avito_backend_task/pkg/db/mocks"
+	"avito_backend_task/pkg/logctx"
 )
 
-func setupTestService() (*PullRequestService, *mocks.PullRequestRepository, *mocks.UserRepository, *dbmocks.MockTransactionManager) {
+func setupTestService(opts ...Option) (*PullRequestService, *mocks.PullRequestRepository, *mocks.UserRepository, *dbmocks.MockTransactionManager) {
 	prRepo := new(mocks.PullRequestRepository)
 	userRepo := new(mocks.UserRepository)
 	txManager := dbmocks.NewMockTransactionManager()
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 
-	service := NewPullRequestService(prRepo, userRepo, txManager, logger)
+	service := NewPullRequestService(prRepo, userRepo, txManager, logger, opts...)
 	return service, prRepo, userRepo, txManager
 }
 
@@ -63,7 +76,7 @@ func TestPullRequestService_CreatePullRequest(t *testing.T) {
 
 				prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
 				prRepo.On("CreatePullRequest", mock.Anything, mock.AnythingOfType("domain.PullRequestCreate")).Return(now, nil)
-				prRepo.On("AssignReviewer", mock.Anything, "pr1", mock.AnythingOfType("string")).Return(nil).Times(2)
+				prRepo.On("AssignReviewer", mock.Anything, "pr1", mock.AnythingOfType("string"), domain.AssignmentSourceAuto).Return(nil).Times(2)
 
 				createdPR := &domain.PullRequest{
 					PullRequestID:     "pr1",
@@ -107,7 +120,7 @@ func TestPullRequestService_CreatePullRequest(t *testing.T) {
 
 				prRepo.On("Exists", mock.Anything, "pr2").Return(false, nil)
 				prRepo.On("CreatePullRequest", mock.Anything, mock.AnythingOfType("domain.PullRequestCreate")).Return(now, nil)
-				prRepo.On("AssignReviewer", mock.Anything, "pr2", "reviewer1").Return(nil)
+				prRepo.On("AssignReviewer", mock.Anything, "pr2", "reviewer1", domain.AssignmentSourceAuto).Return(nil)
 
 				createdPR := &domain.PullRequest{
 					PullRequestID:     "pr2",
@@ -181,12 +194,24 @@ func TestPullRequestService_CreatePullRequest(t *testing.T) {
 
 				userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
 				prRepo.On("Exists", mock.Anything, "existing-pr").Return(true, nil)
+				prRepo.On("GetPullRequestByID", mock.Anything, "existing-pr").Return(&domain.PullRequest{
+					PullRequestID:     "existing-pr",
+					AuthorID:          "author1",
+					Status:            domain.PRStatusOpen,
+					AssignedReviewers: []string{"reviewer1"},
+				}, nil)
 			},
 			expectedError: domain.ErrPRExists,
 			validate: func(t *testing.T, pr *domain.PullRequest, err error) {
 				require.Error(t, err)
 				assert.Nil(t, pr)
 				assert.ErrorIs(t, err, domain.ErrPRExists)
+
+				var conflict *domain.ConflictError
+				require.ErrorAs(t, err, &conflict)
+				existing, ok := conflict.Payload.(*domain.PullRequest)
+				require.True(t, ok)
+				assert.Equal(t, []string{"reviewer1"}, existing.AssignedReviewers)
 			},
 		},
 		{
@@ -222,6 +247,177 @@ func TestPullRequestService_CreatePullRequest(t *testing.T) {
 	}
 }
 
+// blockingSink зависает на первой доставке до отмены контекста и отказывает на остальных
+type blockingSink struct {
+	received chan domain.PREvent
+}
+
+func (s *blockingSink) Deliver(ctx context.Context, event domain.PREvent) error {
+	s.received <- event
+	<-ctx.Done()
+	return errors.New("webhook unavailable")
+}
+
+func TestPullRequestService_EventsDoNotAffectResponse(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	sink := &blockingSink{received: make(chan domain.PREvent, 2)}
+	dispatcher := notify.NewDispatcher(sink, logger, notify.WithQueueSize(1), notify.WithTimeout(time.Minute))
+	dispatcher.Start(context.Background())
+	t.Cleanup(func() { _ = dispatcher.Shutdown(context.Background()) })
+
+	service, prRepo, userRepo, _ := setupTestService(WithEvents(dispatcher))
+
+	userRepo.On("GetByID", mock.Anything, "author1").Return(&domain.User{UserID: "author1", TeamName: "team1", IsActive: true}, nil)
+	userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).
+		Return([]domain.User{{UserID: "u2", TeamName: "team1", IsActive: true}}, nil)
+	prRepo.On("Exists", mock.Anything, mock.Anything).Return(false, nil).Times(3)
+	prRepo.On("CreatePullRequest", mock.Anything, mock.AnythingOfType("domain.PullRequestCreate")).Return(time.Now(), nil)
+	prRepo.On("AssignReviewer", mock.Anything, mock.Anything, "u2", domain.AssignmentSourceAuto).Return(nil)
+	prRepo.On("GetPullRequestByID", mock.Anything, mock.Anything).Return(func(_ context.Context, prID string) (*domain.PullRequest, error) {
+		return &domain.PullRequest{PullRequestID: prID, AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2"}}, nil
+	})
+
+	_, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR 1", AuthorID: "author1"})
+	require.NoError(t, err)
+	event := <-sink.received
+	assert.Equal(t, domain.PREvent{
+		Type: domain.PREventCreated, PullRequestID: "pr1", AuthorID: "author1",
+		Reviewers: []string{"u2"}, OccurredAt: event.OccurredAt,
+	}, event)
+
+	// доставка pr1 зависла, pr2 занимает очередь, событие pr3 отбрасывается - но запросы успешны и не ждут
+	start := time.Now()
+	for _, prID := range []string{"pr2", "pr3"} {
+		pr, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{PullRequestID: prID, PullRequestName: prID, AuthorID: "author1"})
+		require.NoError(t, err)
+		assert.Equal(t, prID, pr.PullRequestID)
+	}
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestPullRequestService_GetReviewLatency(t *testing.T) {
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(30 * 24 * time.Hour)
+
+	t.Run("default min samples", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+		latencies := []domain.ReviewerLatency{{UserID: "u2", Samples: 5, Median: time.Hour, P90: 2 * time.Hour}}
+		prRepo.On("GetReviewLatency", mock.Anything, "backend", &from, &to, defaultLatencyMinSamples).Return(latencies, nil)
+
+		got, err := service.GetReviewLatency(context.Background(), "backend", &from, &to)
+
+		require.NoError(t, err)
+		assert.Equal(t, latencies, got)
+	})
+
+	t.Run("configured min samples", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService(WithConfig(Config{LatencyMinSamples: 2}))
+		prRepo.On("GetReviewLatency", mock.Anything, "backend", (*time.Time)(nil), (*time.Time)(nil), 2).Return(nil, nil)
+
+		_, err := service.GetReviewLatency(context.Background(), "backend", nil, nil)
+
+		require.NoError(t, err)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("inverted window", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+
+		_, err := service.GetReviewLatency(context.Background(), "backend", &to, &from)
+
+		require.ErrorIs(t, err, domain.ErrInvalidInput)
+		prRepo.AssertNotCalled(t, "GetReviewLatency", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPullRequestService_GetQueueInfo(t *testing.T) {
+	now := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+	assignedAt := now.Add(-time.Hour)
+	openPR := &domain.PullRequest{PullRequestID: "pr1", AuthorID: "u1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2", "u3"}}
+
+	t.Run("queues and medians are read concurrently", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService(WithClock(clock.NewFake(now)), WithConfig(Config{LatencyMinSamples: 3}))
+		// каждый запрос ждёт, пока начнётся второй: при последовательных вызовах тест упадёт по таймауту
+		var arrived sync.WaitGroup
+		arrived.Add(2)
+		bothStarted := make(chan struct{})
+		go func() { arrived.Wait(); close(bothStarted) }()
+		waitBoth := func(mock.Arguments) {
+			arrived.Done()
+			select {
+			case <-bothStarted:
+			case <-time.After(time.Second):
+				t.Error("repository calls are not concurrent")
+			}
+		}
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(openPR, nil)
+		prRepo.On("GetReviewerQueues", mock.Anything, "pr1").Run(waitBoth).Return([]domain.ReviewerQueuePosition{
+			{UserID: "u2", AssignedAt: assignedAt, Ahead: 3},
+			{UserID: "u3", AssignedAt: assignedAt},
+		}, nil)
+		prRepo.On("GetReviewerLatencyByUsers", mock.Anything, []string{"u2", "u3"}, now.Add(-queueTurnaroundWindow), 3).Run(waitBoth).
+			Return([]domain.ReviewerLatency{{UserID: "u2", Samples: 4, Median: 2 * time.Hour}}, nil)
+
+		info, err := service.GetQueueInfo(context.Background(), "pr1")
+
+		require.NoError(t, err)
+		median := 2 * time.Hour
+		assert.Equal(t, &domain.PRQueueInfo{PullRequestID: "pr1", Status: domain.PRStatusOpen, Reviewers: []domain.ReviewerQueuePosition{
+			{UserID: "u2", AssignedAt: assignedAt, Ahead: 3, MedianTurnaround: &median},
+			{UserID: "u3", AssignedAt: assignedAt},
+		}}, info)
+	})
+
+	t.Run("merged PR has empty queue", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+		mergedAt := now.Add(-time.Minute)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", Status: domain.PRStatusMerged, AssignedReviewers: []string{"u2"}, MergedAt: &mergedAt,
+		}, nil)
+
+		info, err := service.GetQueueInfo(context.Background(), "pr1")
+
+		require.NoError(t, err)
+		assert.Equal(t, &domain.PRQueueInfo{PullRequestID: "pr1", Status: domain.PRStatusMerged, MergedAt: &mergedAt}, info)
+		prRepo.AssertNotCalled(t, "GetReviewerQueues", mock.Anything, mock.Anything)
+		prRepo.AssertNotCalled(t, "GetReviewerLatencyByUsers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown PR", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+		prRepo.On("GetPullRequestByID", mock.Anything, "missing").Return(nil, repository.ErrNotFound)
+
+		_, err := service.GetQueueInfo(context.Background(), "missing")
+
+		assert.ErrorIs(t, err, domain.ErrPRNotFound)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(openPR, nil)
+		prRepo.On("GetReviewerQueues", mock.Anything, "pr1").Return(nil, errors.New("db down"))
+		prRepo.On("GetReviewerLatencyByUsers", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+
+		_, err := service.GetQueueInfo(context.Background(), "pr1")
+
+		assert.ErrorContains(t, err, "failed to get reviewer queues")
+	})
+}
+
+func TestPullRequestService_GetPullRequest(t *testing.T) {
+	service, prRepo, _, _ := setupTestService()
+	pr := &domain.PullRequest{PullRequestID: "pr1", AuthorID: "u1", Status: domain.PRStatusOpen}
+	prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(pr, nil)
+	prRepo.On("GetPullRequestByID", mock.Anything, "missing").Return(nil, repository.ErrNotFound)
+
+	got, err := service.GetPullRequest(context.Background(), "pr1")
+	require.NoError(t, err)
+	assert.Equal(t, pr, got)
+
+	_, err = service.GetPullRequest(context.Background(), "missing")
+	assert.ErrorIs(t, err, domain.ErrPRNotFound)
+}
+
 func TestPullRequestService_MergePullRequest(t *testing.T) {
 	now := time.Now()
 	mergedAt := time.Now()
@@ -237,8 +433,9 @@ func TestPullRequestService_MergePullRequest(t *testing.T) {
 			name: "merge PR",
 			prID: "pr1",
 			setupMocks: func(prRepo *mocks.PullRequestRepository) {
+				prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
 				prRepo.On("Exists", mock.Anything, "pr1").Return(true, nil)
-				prRepo.On("MergePullRequest", mock.Anything, "pr1").Return(nil)
+				prRepo.On("MergePullRequest", mock.Anything, "pr1", 2, "").Return(true, nil)
 
 				mergedPR := &domain.PullRequest{
 					PullRequestID:     "pr1",
@@ -259,12 +456,40 @@ func TestPullRequestService_MergePullRequest(t *testing.T) {
 				assert.NotNil(t, pr.MergedAt)
 			},
 		},
+		{
+			name: "merge understaffed PR",
+			prID: "pr1",
+			setupMocks: func(prRepo *mocks.PullRequestRepository) {
+				prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+				prRepo.On("Exists", mock.Anything, "pr1").Return(true, nil)
+				// желаемое число ревьюеров - ReviewersPerPR по умолчанию
+				prRepo.On("MergePullRequest", mock.Anything, "pr1", 2, "").Return(true, nil)
+
+				reviewerCount := 1
+				prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+					PullRequestID:       "pr1",
+					AuthorID:            "author1",
+					Status:              domain.PRStatusMerged,
+					AssignedReviewers:   []string{"reviewer1"},
+					MergedReviewerCount: &reviewerCount,
+					MergedUnderstaffed:  true,
+					MergedAt:            &mergedAt,
+				}, nil)
+			},
+			validate: func(t *testing.T, pr *domain.PullRequest, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, pr.MergedReviewerCount)
+				assert.Equal(t, 1, *pr.MergedReviewerCount)
+				assert.True(t, pr.MergedUnderstaffed)
+			},
+		},
 		{
 			name: "merge PR idempotent",
 			prID: "pr2",
 			setupMocks: func(prRepo *mocks.PullRequestRepository) {
+				prRepo.On("LockPullRequest", mock.Anything, "pr2").Return(nil)
 				prRepo.On("Exists", mock.Anything, "pr2").Return(true, nil)
-				prRepo.On("MergePullRequest", mock.Anything, "pr2").Return(nil)
+				prRepo.On("MergePullRequest", mock.Anything, "pr2", 2, "").Return(false, nil)
 
 				mergedPR := &domain.PullRequest{
 					PullRequestID:     "pr2",
@@ -288,6 +513,7 @@ func TestPullRequestService_MergePullRequest(t *testing.T) {
 			name: "PR not found",
 			prID: "not-found",
 			setupMocks: func(prRepo *mocks.PullRequestRepository) {
+				prRepo.On("LockPullRequest", mock.Anything, "not-found").Return(nil)
 				prRepo.On("Exists", mock.Anything, "not-found").Return(false, nil)
 			},
 			expectedError: domain.ErrPRNotFound,
@@ -301,8 +527,9 @@ func TestPullRequestService_MergePullRequest(t *testing.T) {
 			name: "repository error on merge",
 			prID: "pr3",
 			setupMocks: func(prRepo *mocks.PullRequestRepository) {
+				prRepo.On("LockPullRequest", mock.Anything, "pr3").Return(nil)
 				prRepo.On("Exists", mock.Anything, "pr3").Return(true, nil)
-				prRepo.On("MergePullRequest", mock.Anything, "pr3").Return(errors.New("db error"))
+				prRepo.On("MergePullRequest", mock.Anything, "pr3", 2, "").Return(false, errors.New("db error"))
 			},
 			expectedError: nil,
 			validate: func(t *testing.T, pr *domain.PullRequest, err error) {
@@ -318,7 +545,7 @@ func TestPullRequestService_MergePullRequest(t *testing.T) {
 			service, prRepo, _, _ := setupTestService()
 			tt.setupMocks(prRepo)
 
-			result, err := service.MergePullRequest(context.Background(), tt.prID)
+			result, err := service.MergePullRequest(context.Background(), tt.prID, "")
 
 			tt.validate(t, result, err)
 			prRepo.AssertExpectations(t)
@@ -326,6 +553,119 @@ func TestPullRequestService_MergePullRequest(t *testing.T) {
 	}
 }
 
+func TestPullRequestService_ReassignReviewer_CrossTeamPool(t *testing.T) {
+	service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{AllowCrossTeamReviewers: true}))
+
+	pr := &domain.PullRequest{
+		PullRequestID: "pr1", AuthorID: "author", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"backend-rev", "platform-rev"},
+	}
+	prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(pr, nil).Once()
+	userRepo.On("GetByID", mock.Anything, "platform-rev").
+		Return(&domain.User{UserID: "platform-rev", TeamName: "platform", IsActive: true}, nil)
+	userRepo.On("GetActiveByTeam", mock.Anything, "platform", []string{"author", "backend-rev", "platform-rev"}).
+		Return([]domain.User{{UserID: "platform-rev2", TeamName: "platform", IsActive: true}}, nil)
+	prRepo.On("RemoveReviewer", mock.Anything, "pr1", "platform-rev").Return(true, nil)
+	prRepo.On("AssignReviewer", mock.Anything, "pr1", "platform-rev2", domain.AssignmentSourceReassignment).Return(nil)
+	prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil)
+	prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+		PullRequestID: "pr1", AuthorID: "author", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"backend-rev", "platform-rev2"},
+	}, nil).Once()
+
+	_, newReviewerID, err := service.ReassignReviewer(context.Background(), "pr1", "platform-rev")
+
+	require.NoError(t, err)
+	assert.Equal(t, "platform-rev2", newReviewerID)
+	// команда автора в кандидатах не участвует
+	userRepo.AssertNumberOfCalls(t, "GetActiveByTeam", 1)
+	userRepo.AssertExpectations(t)
+}
+
+func TestPullRequestService_MergePullRequest_Actor(t *testing.T) {
+	open := &domain.PullRequest{
+		PullRequestID: "pr1", AuthorID: "author", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"reviewer"},
+	}
+	merged := &domain.PullRequest{PullRequestID: "pr1", AuthorID: "author", Status: domain.PRStatusMerged, MergedBy: "reviewer"}
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		mergedBy string
+		actor    *domain.User
+		wantErr  error
+	}{
+		{name: "required but missing", cfg: Config{MergeRequiresActor: true}, wantErr: domain.ErrMergeActorRequired},
+		{name: "unknown user", cfg: Config{MergeRequiresActor: true}, mergedBy: "ghost", wantErr: domain.ErrMergeActorNotFound},
+		{
+			name: "inactive user", mergedBy: "reviewer",
+			actor: &domain.User{UserID: "reviewer", IsActive: false}, wantErr: domain.ErrMergeActorInactive,
+		},
+		{name: "reviewer by default", mergedBy: "reviewer", actor: &domain.User{UserID: "reviewer", IsActive: true}},
+		{name: "author by default", mergedBy: "author", actor: &domain.User{UserID: "author", IsActive: true}},
+		{
+			name: "outsider by default", mergedBy: "outsider",
+			actor: &domain.User{UserID: "outsider", IsActive: true}, wantErr: domain.ErrMergeNotAllowed,
+		},
+		{
+			name: "reviewer under author policy", cfg: Config{MergeActorPolicy: MergeActorAuthor}, mergedBy: "reviewer",
+			actor: &domain.User{UserID: "reviewer", IsActive: true}, wantErr: domain.ErrMergeNotAllowed,
+		},
+		{
+			name: "author under reviewer policy", cfg: Config{MergeActorPolicy: MergeActorReviewer}, mergedBy: "author",
+			actor: &domain.User{UserID: "author", IsActive: true}, wantErr: domain.ErrMergeNotAllowed,
+		},
+		{
+			name: "reviewer under reviewer policy", cfg: Config{MergeActorPolicy: MergeActorReviewer, MergeRequiresActor: true},
+			mergedBy: "reviewer", actor: &domain.User{UserID: "reviewer", IsActive: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, prRepo, userRepo, _ := setupTestService(WithConfig(tt.cfg))
+			prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+			prRepo.On("Exists", mock.Anything, "pr1").Return(true, nil)
+			prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(open, nil).Once()
+			if tt.mergedBy != "" {
+				if tt.actor != nil {
+					userRepo.On("GetByID", mock.Anything, tt.mergedBy).Return(tt.actor, nil)
+				} else {
+					userRepo.On("GetByID", mock.Anything, tt.mergedBy).Return(nil, repository.ErrNotFound)
+				}
+			}
+			if tt.wantErr == nil {
+				prRepo.On("MergePullRequest", mock.Anything, "pr1", 2, tt.mergedBy).Return(true, nil)
+				prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(merged, nil).Once()
+			}
+
+			pr, err := service.MergePullRequest(context.Background(), "pr1", tt.mergedBy)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				prRepo.AssertNotCalled(t, "MergePullRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "reviewer", pr.MergedBy)
+			prRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("patch to merged checks actor", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService(WithConfig(Config{MergeRequiresActor: true}))
+		status := domain.PRStatusMerged
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(open, nil)
+
+		_, err := service.UpdatePullRequest(context.Background(), domain.PullRequestUpdate{PullRequestID: "pr1", Status: &status})
+
+		require.ErrorIs(t, err, domain.ErrMergeActorRequired)
+		prRepo.AssertNotCalled(t, "MergePullRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestPullRequestService_ReassignReviewer(t *testing.T) {
 	now := time.Now()
 
@@ -351,7 +691,6 @@ func TestPullRequestService_ReassignReviewer(t *testing.T) {
 					CreatedAt:         &now,
 				}
 				prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(pr, nil).Once()
-				prRepo.On("IsReviewerAssigned", mock.Anything, "pr1", "reviewer1").Return(true, nil)
 
 				oldReviewer := &domain.User{
 					UserID:   "reviewer1",
@@ -366,8 +705,9 @@ func TestPullRequestService_ReassignReviewer(t *testing.T) {
 				}
 				userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "reviewer1", "reviewer2"}).Return(candidates, nil)
 
-				prRepo.On("RemoveReviewer", mock.Anything, "pr1", "reviewer1").Return(nil)
-				prRepo.On("AssignReviewer", mock.Anything, "pr1", "reviewer3").Return(nil)
+				prRepo.On("RemoveReviewer", mock.Anything, "pr1", "reviewer1").Return(true, nil)
+				prRepo.On("AssignReviewer", mock.Anything, "pr1", "reviewer3", domain.AssignmentSourceReassignment).Return(nil)
+				prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil)
 
 				updatedPR := &domain.PullRequest{
 					PullRequestID:     "pr1",
@@ -442,7 +782,6 @@ func TestPullRequestService_ReassignReviewer(t *testing.T) {
 					CreatedAt:         &now,
 				}
 				prRepo.On("GetPullRequestByID", mock.Anything, "pr3").Return(pr, nil)
-				prRepo.On("IsReviewerAssigned", mock.Anything, "pr3", "not-assigned").Return(false, nil)
 			},
 			expectedError: domain.ErrNotAssigned,
 			validate: func(t *testing.T, pr *domain.PullRequest, newReviewerID string, err error) {
@@ -466,7 +805,6 @@ func TestPullRequestService_ReassignReviewer(t *testing.T) {
 					CreatedAt:         &now,
 				}
 				prRepo.On("GetPullRequestByID", mock.Anything, "pr4").Return(pr, nil)
-				prRepo.On("IsReviewerAssigned", mock.Anything, "pr4", "reviewer1").Return(true, nil)
 
 				oldReviewer := &domain.User{
 					UserID:   "reviewer1",
@@ -477,6 +815,9 @@ func TestPullRequestService_ReassignReviewer(t *testing.T) {
 				userRepo.On("GetByID", mock.Anything, "reviewer1").Return(oldReviewer, nil)
 
 				userRepo.On("GetActiveByTeam", mock.Anything, "team4", []string{"author4", "reviewer1"}).Return([]domain.User{}, nil)
+				// без разбивки отказ тот же, только без diagnostics
+				userRepo.On("GetCandidateBreakdown", mock.Anything, "team4", []string{"author4", "reviewer1"}, 0).
+					Return(domain.CandidateBreakdown{}, errors.New("db error"))
 			},
 			expectedError: domain.ErrNoCandidate,
 			validate: func(t *testing.T, pr *domain.PullRequest, newReviewerID string, err error) {
@@ -501,3 +842,3097 @@ func TestPullRequestService_ReassignReviewer(t *testing.T) {
 		})
 	}
 }
+
+func TestPullRequestService_CreatePullRequest_WorkingHours(t *testing.T) {
+	// 2025-10-04 - суббота
+	saturdayNoon := time.Date(2025, 10, 4, 12, 0, 0, 0, time.UTC)
+	saturdayNight := time.Date(2025, 10, 4, 22, 0, 0, 0, time.UTC)
+
+	author := &domain.User{UserID: "author1", TeamName: "team1", IsActive: true}
+	candidates := []domain.User{
+		{UserID: "weekday1", TeamName: "team1", IsActive: true, WorkingHours: "Mon-Fri 09:00-18:00", Timezone: "UTC"},
+		{UserID: "weekday2", TeamName: "team1", IsActive: true, WorkingHours: "Mon-Fri 09:00-18:00", Timezone: "UTC"},
+		{UserID: "weekend", TeamName: "team1", IsActive: true, WorkingHours: "Sat-Sun 10:00-18:00", Timezone: "UTC"},
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		exclude  bool
+		validate func(*testing.T, []string)
+	}{
+		{
+			name: "outside window are deprioritized",
+			now:  saturdayNoon,
+			validate: func(t *testing.T, assigned []string) {
+				require.Len(t, assigned, 2)
+				assert.Equal(t, "weekend", assigned[0])
+				assert.Contains(t, []string{"weekday1", "weekday2"}, assigned[1])
+			},
+		},
+		{
+			name:    "outside window are excluded",
+			now:     saturdayNoon,
+			exclude: true,
+			validate: func(t *testing.T, assigned []string) {
+				assert.Equal(t, []string{"weekend"}, assigned)
+			},
+		},
+		{
+			name:    "everyone outside falls back to full pool",
+			now:     saturdayNight,
+			exclude: true,
+			validate: func(t *testing.T, assigned []string) {
+				assert.Len(t, assigned, 2)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, prRepo, userRepo, _ := setupTestService(
+				WithClock(clock.NewFake(tt.now)),
+				WithConfig(Config{ExcludeOutsideWorkingHours: tt.exclude}),
+			)
+
+			userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+			userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).Return(candidates, nil)
+			prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+			prRepo.On("CreatePullRequest", mock.Anything, mock.AnythingOfType("domain.PullRequestCreate")).Return(tt.now, nil)
+
+			var assigned []string
+			prRepo.On("AssignReviewer", mock.Anything, "pr1", mock.AnythingOfType("string"), domain.AssignmentSourceAuto).
+				Run(func(args mock.Arguments) { assigned = append(assigned, args.String(2)) }).
+				Return(nil)
+			prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{PullRequestID: "pr1"}, nil)
+
+			_, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{
+				PullRequestID:   "pr1",
+				PullRequestName: "PR1",
+				AuthorID:        "author1",
+			})
+
+			require.NoError(t, err)
+			tt.validate(t, assigned)
+		})
+	}
+}
+
+func TestPullRequestService_MutationsOnClosedPR(t *testing.T) {
+	now := time.Now()
+	openPR := &domain.PullRequest{
+		PullRequestID:     "pr1",
+		AuthorID:          "author1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"reviewer1"},
+		CreatedAt:         &now,
+	}
+	oldReviewer := &domain.User{UserID: "reviewer1", TeamName: "team1", IsActive: true}
+	candidates := []domain.User{{UserID: "reviewer2", TeamName: "team1", IsActive: true}}
+
+	t.Run("reassign on merged PR", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+		merged := *openPR
+		merged.Status = domain.PRStatusMerged
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&merged, nil)
+
+		_, _, err := service.ReassignReviewer(context.Background(), "pr1", "reviewer1")
+
+		assert.ErrorIs(t, err, domain.ErrPRNotOpen)
+		prRepo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("removal rejected by repository guard", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(openPR, nil)
+		userRepo.On("GetByID", mock.Anything, "reviewer1").Return(oldReviewer, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "reviewer1"}).Return(candidates, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "pr1", "reviewer1").Return(false, repository.ErrNotOpen)
+
+		_, _, err := service.ReassignReviewer(context.Background(), "pr1", "reviewer1")
+
+		assert.ErrorIs(t, err, domain.ErrPRNotOpen)
+		prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reviewer removed concurrently", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(openPR, nil)
+		userRepo.On("GetByID", mock.Anything, "reviewer1").Return(oldReviewer, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "reviewer1"}).Return(candidates, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "pr1", "reviewer1").Return(false, nil)
+
+		_, _, err := service.ReassignReviewer(context.Background(), "pr1", "reviewer1")
+
+		assert.ErrorIs(t, err, domain.ErrNotAssigned)
+		prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("assignment rejected by repository guard", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(openPR, nil)
+		userRepo.On("GetByID", mock.Anything, "reviewer1").Return(oldReviewer, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "reviewer1"}).Return(candidates, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "pr1", "reviewer1").Return(true, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "reviewer2", domain.AssignmentSourceReassignment).Return(repository.ErrNotOpen)
+
+		_, _, err := service.ReassignReviewer(context.Background(), "pr1", "reviewer1")
+
+		assert.ErrorIs(t, err, domain.ErrPRNotOpen)
+	})
+
+	t.Run("auto-assignment rejected by repository guard", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "author1").Return(&domain.User{UserID: "author1", TeamName: "team1"}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).Return(candidates, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.AnythingOfType("domain.PullRequestCreate")).Return(now, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "reviewer2", domain.AssignmentSourceAuto).Return(repository.ErrNotOpen)
+
+		_, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR 1", AuthorID: "author1"})
+
+		assert.ErrorIs(t, err, domain.ErrPRNotOpen)
+	})
+}
+
+func TestPullRequestService_GetReviewerIDs(t *testing.T) {
+	tests := []struct {
+		name       string
+		prID       string
+		setupMocks func(*mocks.PullRequestRepository)
+		validate   func(*testing.T, []string, error)
+	}{
+		{
+			name: "existing PR",
+			prID: "pr1",
+			setupMocks: func(prRepo *mocks.PullRequestRepository) {
+				prRepo.On("Exists", mock.Anything, "pr1").Return(true, nil)
+				prRepo.On("GetReviewerIDs", mock.Anything, "pr1").Return([]string{"u2", "u3"}, nil)
+			},
+			validate: func(t *testing.T, ids []string, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{"u2", "u3"}, ids)
+			},
+		},
+		{
+			name: "PR not found",
+			prID: "missing",
+			setupMocks: func(prRepo *mocks.PullRequestRepository) {
+				prRepo.On("Exists", mock.Anything, "missing").Return(false, nil)
+			},
+			validate: func(t *testing.T, ids []string, err error) {
+				assert.ErrorIs(t, err, domain.ErrPRNotFound)
+				assert.Nil(t, ids)
+			},
+		},
+		{
+			name: "repository error",
+			prID: "pr1",
+			setupMocks: func(prRepo *mocks.PullRequestRepository) {
+				prRepo.On("Exists", mock.Anything, "pr1").Return(true, nil)
+				prRepo.On("GetReviewerIDs", mock.Anything, "pr1").Return(nil, errors.New("db error"))
+			},
+			validate: func(t *testing.T, ids []string, err error) {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "failed to get reviewers")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, prRepo, _, _ := setupTestService()
+			tt.setupMocks(prRepo)
+
+			ids, err := service.GetReviewerIDs(context.Background(), tt.prID)
+
+			tt.validate(t, ids, err)
+			prRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPullRequestService_ReassignInactiveReviewers(t *testing.T) {
+	t.Run("reassigns, removes and skips", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+
+		prRepo.On("GetInactiveReviewerAssignments", mock.Anything).Return([]domain.ReviewerAssignment{
+			{PullRequestID: "pr1", UserID: "gone1", TeamName: "backend"},
+			{PullRequestID: "pr2", UserID: "gone2", TeamName: "mobile"},
+			{PullRequestID: "pr3", UserID: "gone3", TeamName: "backend"},
+		}, nil)
+		prRepo.On("LockPullRequest", mock.Anything, mock.Anything).Return(nil)
+
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "a1", Status: domain.PRStatusOpen,
+			AssignedReviewers: []string{"gone1", "u2"},
+		}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "backend", []string{"a1", "gone1", "u2"}).
+			Return([]domain.User{{UserID: "u3", TeamName: "backend", IsActive: true}}, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "pr1", "gone1").Return(true, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u3", domain.AssignmentSourceAdmin).Return(nil)
+		prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil).Once()
+
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr2").Return(&domain.PullRequest{
+			PullRequestID: "pr2", AuthorID: "a2", Status: domain.PRStatusOpen,
+			AssignedReviewers: []string{"gone2"},
+		}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "mobile", []string{"a2", "gone2"}).Return([]domain.User{}, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "pr2", "gone2").Return(true, nil)
+
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr3").Return(&domain.PullRequest{
+			PullRequestID: "pr3", AuthorID: "a1", Status: domain.PRStatusMerged,
+			AssignedReviewers: []string{"gone3"},
+		}, nil)
+
+		report, err := service.ReassignInactiveReviewers(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.ReviewerReplacement{{PullRequestID: "pr1", OldUserID: "gone1", NewUserID: "u3"}}, report.Reassigned)
+		assert.Equal(t, []domain.ReviewerReplacement{{PullRequestID: "pr2", OldUserID: "gone2"}}, report.Removed)
+		assert.Equal(t, []domain.ReviewerAssignment{{PullRequestID: "pr3", UserID: "gone3", TeamName: "backend"}}, report.Skipped)
+		prRepo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, "pr3", "gone3")
+		prRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("nothing to do", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+		prRepo.On("GetInactiveReviewerAssignments", mock.Anything).Return(nil, nil)
+
+		report, err := service.ReassignInactiveReviewers(context.Background())
+
+		require.NoError(t, err)
+		assert.Empty(t, report.Reassigned)
+		assert.Empty(t, report.Removed)
+		assert.Empty(t, report.Skipped)
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+		prRepo.On("GetInactiveReviewerAssignments", mock.Anything).Return(nil, errors.New("db error"))
+
+		report, err := service.ReassignInactiveReviewers(context.Background())
+
+		require.Error(t, err)
+		assert.Nil(t, report)
+		assert.Contains(t, err.Error(), "failed to scan inactive reviewers")
+	})
+
+	t.Run("repository error aborts", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+		prRepo.On("GetInactiveReviewerAssignments", mock.Anything).Return([]domain.ReviewerAssignment{
+			{PullRequestID: "pr1", UserID: "gone1", TeamName: "backend"},
+		}, nil)
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(errors.New("db error"))
+
+		_, err := service.ReassignInactiveReviewers(context.Background())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to replace reviewer gone1 on PR pr1")
+	})
+}
+
+func TestPullRequestService_BackfillReviewerHistory(t *testing.T) {
+	t.Run("resumes from watermark until short batch", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+
+		start := domain.BackfillWatermark{PullRequestID: "pr1", UserID: "u1"}
+		middle := domain.BackfillWatermark{PullRequestID: "pr2", UserID: "u1"}
+		end := domain.BackfillWatermark{PullRequestID: "pr3", UserID: "u2"}
+		prRepo.On("LockBackfillWatermark", mock.Anything, "reviewer_history").Return(start, nil).Once()
+		prRepo.On("BackfillAssignedEvents", mock.Anything, start, 2).
+			Return(domain.BackfillBatch{Scanned: 2, Inserted: 1, Last: middle}, nil)
+		prRepo.On("SaveBackfillWatermark", mock.Anything, "reviewer_history", middle).Return(nil)
+		prRepo.On("LockBackfillWatermark", mock.Anything, "reviewer_history").Return(middle, nil).Once()
+		prRepo.On("BackfillAssignedEvents", mock.Anything, middle, 2).
+			Return(domain.BackfillBatch{Scanned: 1, Inserted: 1, Last: end}, nil)
+		prRepo.On("SaveBackfillWatermark", mock.Anything, "reviewer_history", end).Return(nil)
+
+		report, err := service.BackfillReviewerHistory(context.Background(), 2, false)
+
+		require.NoError(t, err)
+		assert.Equal(t, &domain.BackfillReport{Batches: 2, Scanned: 3, Inserted: 2, Watermark: end}, report)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("already backfilled", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+
+		end := domain.BackfillWatermark{PullRequestID: "pr3", UserID: "u2"}
+		prRepo.On("LockBackfillWatermark", mock.Anything, "reviewer_history").Return(end, nil)
+		prRepo.On("BackfillAssignedEvents", mock.Anything, end, 500).Return(domain.BackfillBatch{Last: end}, nil)
+
+		report, err := service.BackfillReviewerHistory(context.Background(), 0, false)
+
+		require.NoError(t, err)
+		assert.Equal(t, &domain.BackfillReport{Watermark: end}, report)
+		prRepo.AssertNotCalled(t, "SaveBackfillWatermark", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("restart resets watermark", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+
+		prRepo.On("SaveBackfillWatermark", mock.Anything, "reviewer_history", domain.BackfillWatermark{}).Return(nil)
+		prRepo.On("LockBackfillWatermark", mock.Anything, "reviewer_history").Return(domain.BackfillWatermark{}, nil)
+		prRepo.On("BackfillAssignedEvents", mock.Anything, domain.BackfillWatermark{}, 10).Return(domain.BackfillBatch{}, nil)
+
+		report, err := service.BackfillReviewerHistory(context.Background(), 10, true)
+
+		require.NoError(t, err)
+		assert.Zero(t, report.Inserted)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("batch error reports watermark", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+
+		start := domain.BackfillWatermark{PullRequestID: "pr1", UserID: "u1"}
+		prRepo.On("LockBackfillWatermark", mock.Anything, "reviewer_history").Return(start, nil)
+		prRepo.On("BackfillAssignedEvents", mock.Anything, start, 2).Return(domain.BackfillBatch{}, errors.New("db error"))
+
+		report, err := service.BackfillReviewerHistory(context.Background(), 2, false)
+
+		require.Error(t, err)
+		assert.Nil(t, report)
+		assert.Contains(t, err.Error(), "failed to backfill batch after pr1/u1")
+	})
+}
+
+func TestPullRequestService_CreatePullRequest_NoReviewersPolicy(t *testing.T) {
+	now := time.Now()
+	prCreate := domain.PullRequestCreate{PullRequestID: "solo-pr", PullRequestName: "Solo", AuthorID: "solo"}
+
+	// команда из одного автора: назначить некого
+	setupSoloTeam := func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+		userRepo.On("GetByID", mock.Anything, "solo").Return(&domain.User{UserID: "solo", TeamName: "solo-team", IsActive: true}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "solo-team", []string{"solo"}).Return([]domain.User{}, nil)
+		prRepo.On("Exists", mock.Anything, "solo-pr").Return(false, nil)
+	}
+
+	t.Run("disabled creates PR without reviewers", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+		setupSoloTeam(prRepo, userRepo)
+		prRepo.On("CreatePullRequest", mock.Anything, prCreate).Return(now, nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "solo-pr").Return(&domain.PullRequest{
+			PullRequestID: "solo-pr",
+			AuthorID:      "solo",
+			Status:        domain.PRStatusOpen,
+		}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Empty(t, pr.AssignedReviewers)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("enabled rejects PR", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{NoReviewersPolicy: NoReviewersFail}))
+		setupSoloTeam(prRepo, userRepo)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		assert.ErrorIs(t, err, domain.ErrNoCandidate)
+		assert.Nil(t, pr)
+		prRepo.AssertNotCalled(t, "CreatePullRequest", mock.Anything, mock.Anything)
+	})
+
+	t.Run("author policy assigns the author", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{NoReviewersPolicy: NoReviewersAssignAuthor}))
+		setupSoloTeam(prRepo, userRepo)
+		prRepo.On("CreatePullRequest", mock.Anything, prCreate).Return(now, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "solo-pr", "solo", domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "solo-pr").Return(&domain.PullRequest{
+			PullRequestID:     "solo-pr",
+			AuthorID:          "solo",
+			Status:            domain.PRStatusOpen,
+			AssignedReviewers: []string{"solo"},
+		}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"solo"}, pr.AssignedReviewers)
+		prRepo.AssertExpectations(t)
+	})
+}
+
+func TestPullRequestService_AssignReviewer(t *testing.T) {
+	openPR := &domain.PullRequest{
+		PullRequestID:     "pr1",
+		AuthorID:          "author1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"reviewer1"},
+	}
+	author := &domain.User{UserID: "author1", TeamName: "team1", IsActive: true}
+
+	tests := []struct {
+		name       string
+		userID     string
+		opts       []Option
+		setupMocks func(*mocks.PullRequestRepository, *mocks.UserRepository)
+		wantErr    error
+	}{
+		{
+			name:   "assign active teammate",
+			userID: "reviewer2",
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "reviewer2").Return(&domain.User{UserID: "reviewer2", TeamName: "team1", IsActive: true}, nil)
+				userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+				prRepo.On("AssignReviewer", mock.Anything, "pr1", "reviewer2", domain.AssignmentSourceManual).Return(nil)
+			},
+		},
+		{
+			name:   "inactive user",
+			userID: "inactive",
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "inactive").Return(&domain.User{UserID: "inactive", TeamName: "team1", IsActive: false}, nil)
+			},
+			wantErr: domain.ErrReviewerInactive,
+		},
+		{
+			name:       "author",
+			userID:     "author1",
+			setupMocks: func(*mocks.PullRequestRepository, *mocks.UserRepository) {},
+			wantErr:    domain.ErrReviewerIsAuthor,
+		},
+		{
+			name:       "already assigned",
+			userID:     "reviewer1",
+			setupMocks: func(*mocks.PullRequestRepository, *mocks.UserRepository) {},
+			wantErr:    domain.ErrAlreadyAssigned,
+		},
+		{
+			name:   "other team",
+			userID: "stranger",
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "stranger").Return(&domain.User{UserID: "stranger", TeamName: "team2", IsActive: true}, nil)
+				userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+			},
+			wantErr: domain.ErrReviewerNotInTeam,
+		},
+		{
+			name:   "other team allowed by config",
+			userID: "stranger",
+			opts:   []Option{WithConfig(Config{AllowCrossTeamReviewers: true})},
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "stranger").Return(&domain.User{UserID: "stranger", TeamName: "team2", IsActive: true}, nil)
+				prRepo.On("AssignReviewer", mock.Anything, "pr1", "stranger", domain.AssignmentSourceManual).Return(nil)
+			},
+		},
+		{
+			name:   "unknown user",
+			userID: "ghost",
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "ghost").Return(nil, repository.ErrNotFound)
+			},
+			wantErr: domain.ErrUserNotFound,
+		},
+		{
+			name:   "inactive rejected by repository guard",
+			userID: "reviewer2",
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "reviewer2").Return(&domain.User{UserID: "reviewer2", TeamName: "team1", IsActive: true}, nil)
+				userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+				prRepo.On("AssignReviewer", mock.Anything, "pr1", "reviewer2", domain.AssignmentSourceManual).Return(repository.ErrInactiveReviewer)
+			},
+			wantErr: domain.ErrReviewerInactive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, prRepo, userRepo, _ := setupTestService(tt.opts...)
+			prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+			prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(openPR, nil)
+			tt.setupMocks(prRepo, userRepo)
+
+			pr, err := service.AssignReviewer(context.Background(), "pr1", tt.userID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, pr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, pr)
+			prRepo.AssertExpectations(t)
+			userRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("merged PR", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+		merged := *openPR
+		merged.Status = domain.PRStatusMerged
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&merged, nil)
+
+		_, err := service.AssignReviewer(context.Background(), "pr1", "reviewer2")
+
+		assert.ErrorIs(t, err, domain.ErrPRNotOpen)
+	})
+}
+
+func TestPullRequestService_UpdatePullRequest(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	statusPtr := func(s domain.PRStatus) *domain.PRStatus { return &s }
+
+	openPR := &domain.PullRequest{PullRequestID: "pr1", PullRequestName: "Old", AuthorID: "a1", Status: domain.PRStatusOpen}
+	mergedPR := &domain.PullRequest{PullRequestID: "pr1", PullRequestName: "Old", AuthorID: "a1", Status: domain.PRStatusMerged}
+
+	tests := []struct {
+		name       string
+		update     domain.PullRequestUpdate
+		current    *domain.PullRequest
+		setupMocks func(*mocks.PullRequestRepository)
+		wantErr    error
+	}{
+		{
+			name:    "rename only",
+			update:  domain.PullRequestUpdate{PullRequestID: "pr1", PullRequestName: strPtr("New")},
+			current: openPR,
+			setupMocks: func(prRepo *mocks.PullRequestRepository) {
+				prRepo.On("RenamePullRequest", mock.Anything, "pr1", "New").Return(nil)
+			},
+		},
+		{
+			name:    "merge only",
+			update:  domain.PullRequestUpdate{PullRequestID: "pr1", Status: statusPtr(domain.PRStatusMerged)},
+			current: openPR,
+			setupMocks: func(prRepo *mocks.PullRequestRepository) {
+				prRepo.On("MergePullRequest", mock.Anything, "pr1", 2, "").Return(true, nil)
+			},
+		},
+		{
+			name: "rename and merge",
+			update: domain.PullRequestUpdate{
+				PullRequestID:   "pr1",
+				PullRequestName: strPtr("New"),
+				Status:          statusPtr(domain.PRStatusMerged),
+			},
+			current: openPR,
+			setupMocks: func(prRepo *mocks.PullRequestRepository) {
+				prRepo.On("RenamePullRequest", mock.Anything, "pr1", "New").Return(nil).Once()
+				prRepo.On("MergePullRequest", mock.Anything, "pr1", 2, "").Return(true, nil).Once()
+			},
+		},
+		{
+			name:       "same values are no-op",
+			update:     domain.PullRequestUpdate{PullRequestID: "pr1", PullRequestName: strPtr("Old"), Status: statusPtr(domain.PRStatusMerged)},
+			current:    mergedPR,
+			setupMocks: func(*mocks.PullRequestRepository) {},
+		},
+		{
+			name:       "reopen merged PR",
+			update:     domain.PullRequestUpdate{PullRequestID: "pr1", Status: statusPtr(domain.PRStatusOpen)},
+			current:    mergedPR,
+			setupMocks: func(*mocks.PullRequestRepository) {},
+			wantErr:    domain.ErrInvalidTransition,
+		},
+		{
+			name:       "rename merged PR",
+			update:     domain.PullRequestUpdate{PullRequestID: "pr1", PullRequestName: strPtr("New")},
+			current:    mergedPR,
+			setupMocks: func(*mocks.PullRequestRepository) {},
+			wantErr:    domain.ErrPRMerged,
+		},
+		{
+			name:    "rename rejected by repository guard",
+			update:  domain.PullRequestUpdate{PullRequestID: "pr1", PullRequestName: strPtr("New")},
+			current: openPR,
+			setupMocks: func(prRepo *mocks.PullRequestRepository) {
+				prRepo.On("RenamePullRequest", mock.Anything, "pr1", "New").Return(repository.ErrNotOpen)
+			},
+			wantErr: domain.ErrPRNotOpen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, prRepo, _, _ := setupTestService()
+			prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+			prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(tt.current, nil)
+			tt.setupMocks(prRepo)
+
+			pr, err := service.UpdatePullRequest(context.Background(), tt.update)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, pr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, pr)
+			prRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("PR not found", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+		prRepo.On("LockPullRequest", mock.Anything, "missing").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "missing").Return(nil, repository.ErrNotFound)
+
+		_, err := service.UpdatePullRequest(context.Background(), domain.PullRequestUpdate{
+			PullRequestID:   "missing",
+			PullRequestName: strPtr("New"),
+		})
+
+		assert.ErrorIs(t, err, domain.ErrPRNotFound)
+	})
+}
+
+func TestPullRequestService_CreatePullRequest_TeamScope(t *testing.T) {
+	prCreate := domain.PullRequestCreate{PullRequestID: "pr-1", PullRequestName: "Test PR", AuthorID: "author1"}
+	author := &domain.User{UserID: "author1", TeamName: "team1", IsActive: true}
+
+	t.Run("key of another team", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+
+		ctx := auth.WithScope(context.Background(), auth.Scope{Team: "team2"})
+		result, err := service.CreatePullRequest(ctx, prCreate)
+
+		require.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, result)
+		prRepo.AssertNotCalled(t, "CreatePullRequest", mock.Anything, mock.Anything)
+	})
+
+	for name, scope := range map[string]auth.Scope{"own team key": {Team: "team1"}, "admin key": {}} {
+		t.Run(name, func(t *testing.T) {
+			service, prRepo, userRepo, _ := setupTestService()
+			userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+			prRepo.On("Exists", mock.Anything, "pr-1").Return(false, nil)
+			userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).Return([]domain.User{}, nil)
+			prRepo.On("CreatePullRequest", mock.Anything, prCreate).Return(time.Now(), nil)
+			prRepo.On("GetPullRequestByID", mock.Anything, "pr-1").Return(&domain.PullRequest{
+				PullRequestID: "pr-1",
+				AuthorID:      "author1",
+				Status:        domain.PRStatusOpen,
+			}, nil)
+
+			result, err := service.CreatePullRequest(auth.WithScope(context.Background(), scope), prCreate)
+
+			require.NoError(t, err)
+			assert.Equal(t, "pr-1", result.PullRequestID)
+		})
+	}
+}
+
+func TestPullRequestService_CreatePullRequest_Priority(t *testing.T) {
+	busy := domain.User{UserID: "busy", TeamName: "team1", IsActive: true}
+	free := domain.User{UserID: "free", TeamName: "team1", IsActive: true}
+
+	setup := func(prCreate domain.PullRequestCreate, candidates []domain.User) (*PullRequestService, *mocks.PullRequestRepository) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{MaxOpenReviews: 2}))
+		userRepo.On("GetByID", mock.Anything, "author").Return(&domain.User{UserID: "author", TeamName: "team1", IsActive: true}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author"}).Return(candidates, nil)
+		prRepo.On("Exists", mock.Anything, prCreate.PullRequestID).Return(false, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, prCreate).Return(time.Now(), nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, prCreate.PullRequestID).Return(&domain.PullRequest{
+			PullRequestID: prCreate.PullRequestID,
+			Priority:      prCreate.Priority,
+			Status:        domain.PRStatusOpen,
+		}, nil)
+		return service, prRepo
+	}
+
+	t.Run("NORMAL PR respects reviewer cap", func(t *testing.T) {
+		prCreate := domain.PullRequestCreate{PullRequestID: "pr-n", PullRequestName: "Feature", AuthorID: "author", Priority: domain.PRPriorityNormal}
+		service, prRepo := setup(prCreate, []domain.User{busy, free})
+		prRepo.On("GetOpenReviewCounts", mock.Anything, []string{"busy", "free"}).Return(map[string]int{"busy": 2, "free": 1}, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr-n", "free", domain.AssignmentSourceAuto).Return(nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, "pr-n", "busy", mock.Anything)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("HIGH PR is assigned past reviewer cap", func(t *testing.T) {
+		prCreate := domain.PullRequestCreate{PullRequestID: "pr-h", PullRequestName: "Hotfix", AuthorID: "author", Priority: domain.PRPriorityHigh}
+		service, prRepo := setup(prCreate, []domain.User{busy})
+		prRepo.On("AssignReviewer", mock.Anything, "pr-h", "busy", domain.AssignmentSourceAuto).Return(nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.PRPriorityHigh, pr.Priority)
+		prRepo.AssertNotCalled(t, "GetOpenReviewCounts", mock.Anything, mock.Anything)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("load query failure falls back to random selection", func(t *testing.T) {
+		prCreate := domain.PullRequestCreate{PullRequestID: "pr-f", PullRequestName: "Feature", AuthorID: "author", Priority: domain.PRPriorityNormal}
+		service, prRepo := setup(prCreate, []domain.User{busy, free})
+		prRepo.On("GetOpenReviewCounts", mock.Anything, []string{"busy", "free"}).Return(nil, errors.New("statement timeout"))
+		// без нагрузки фильтр не применяется, поэтому назначаются оба кандидата, включая занятого
+		prRepo.On("AssignReviewer", mock.Anything, "pr-f", "busy", domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr-f", "free", domain.AssignmentSourceAuto).Return(nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("canceled load query fails creation", func(t *testing.T) {
+		prCreate := domain.PullRequestCreate{PullRequestID: "pr-c", PullRequestName: "Feature", AuthorID: "author", Priority: domain.PRPriorityNormal}
+		service, prRepo := setup(prCreate, []domain.User{busy, free})
+		ctx, cancel := context.WithCancel(context.Background())
+		prRepo.On("GetOpenReviewCounts", mock.Anything, []string{"busy", "free"}).
+			Run(func(mock.Arguments) { cancel() }).
+			Return(nil, context.Canceled)
+
+		_, err := service.CreatePullRequest(ctx, prCreate)
+
+		require.ErrorIs(t, err, context.Canceled)
+		prRepo.AssertNotCalled(t, "CreatePullRequest", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPullRequestService_SelfAssign(t *testing.T) {
+	author := &domain.User{UserID: "author1", TeamName: "team1", IsActive: true}
+	volunteer := &domain.User{UserID: "volunteer", TeamName: "team1", IsActive: true}
+	newPR := func(status domain.PRStatus, reviewers ...string) *domain.PullRequest {
+		return &domain.PullRequest{PullRequestID: "pr1", AuthorID: "author1", Status: status, AssignedReviewers: reviewers}
+	}
+
+	tests := []struct {
+		name       string
+		userID     string
+		pr         *domain.PullRequest
+		opts       []Option
+		setupMocks func(*mocks.PullRequestRepository, *mocks.UserRepository)
+		wantErr    error
+	}{
+		{
+			name:   "volunteer joins PR with room",
+			userID: "volunteer",
+			pr:     newPR(domain.PRStatusOpen, "reviewer1"),
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "volunteer").Return(volunteer, nil)
+				userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+				prRepo.On("AssignReviewer", mock.Anything, "pr1", "volunteer", domain.AssignmentSourceManual).Return(nil)
+			},
+		},
+		{
+			name:       "merged PR",
+			userID:     "volunteer",
+			pr:         newPR(domain.PRStatusMerged),
+			setupMocks: func(*mocks.PullRequestRepository, *mocks.UserRepository) {},
+			wantErr:    domain.ErrPRMerged,
+		},
+		{
+			name:       "author",
+			userID:     "author1",
+			pr:         newPR(domain.PRStatusOpen),
+			setupMocks: func(*mocks.PullRequestRepository, *mocks.UserRepository) {},
+			wantErr:    domain.ErrReviewerIsAuthor,
+		},
+		{
+			name:       "already assigned",
+			userID:     "reviewer1",
+			pr:         newPR(domain.PRStatusOpen, "reviewer1", "reviewer2"),
+			setupMocks: func(*mocks.PullRequestRepository, *mocks.UserRepository) {},
+			wantErr:    domain.ErrAlreadyAssigned,
+		},
+		{
+			name:   "cap reached",
+			userID: "volunteer",
+			pr:     newPR(domain.PRStatusOpen, "reviewer1", "reviewer2"),
+			opts:   []Option{WithConfig(Config{MaxReviewers: 2})},
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "volunteer").Return(volunteer, nil)
+				userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+			},
+			wantErr: domain.ErrReviewerCapReached,
+		},
+		{
+			name:   "inactive user",
+			userID: "volunteer",
+			pr:     newPR(domain.PRStatusOpen),
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "volunteer").Return(&domain.User{UserID: "volunteer", TeamName: "team1"}, nil)
+			},
+			wantErr: domain.ErrReviewerInactive,
+		},
+		{
+			name:   "other team even when cross-team assignment is allowed",
+			userID: "stranger",
+			pr:     newPR(domain.PRStatusOpen),
+			opts:   []Option{WithConfig(Config{AllowCrossTeamReviewers: true})},
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "stranger").Return(&domain.User{UserID: "stranger", TeamName: "team2", IsActive: true}, nil)
+				userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+			},
+			wantErr: domain.ErrReviewerNotInTeam,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, prRepo, userRepo, _ := setupTestService(tt.opts...)
+			prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+			prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(tt.pr, nil)
+			tt.setupMocks(prRepo, userRepo)
+
+			pr, err := service.SelfAssign(context.Background(), "pr1", tt.userID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, pr)
+				prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, pr)
+			prRepo.AssertExpectations(t)
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPullRequestService_MaxReviewersPerPR(t *testing.T) {
+	author := &domain.User{UserID: "author1", TeamName: "team1", IsActive: true}
+	teammate := &domain.User{UserID: "u9", TeamName: "team1", IsActive: true}
+	openPR := func(reviewers ...string) *domain.PullRequest {
+		return &domain.PullRequest{PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: reviewers}
+	}
+
+	t.Run("auto-assignment picks no more than the cap", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{MaxReviewers: 1}))
+		prCreate := domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR", AuthorID: "author1"}
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).Return([]domain.User{
+			{UserID: "u2", TeamName: "team1", IsActive: true},
+			{UserID: "u3", TeamName: "team1", IsActive: true},
+			{UserID: "u4", TeamName: "team1", IsActive: true},
+		}, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, prCreate).Return(time.Now(), nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", mock.Anything, domain.AssignmentSourceAuto).Return(nil).Once()
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(openPR("u2"), nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		prRepo.AssertNumberOfCalls(t, "AssignReviewer", 1)
+	})
+
+	paths := map[string]func(*PullRequestService) (*domain.PullRequest, error){
+		"manual assignment": func(s *PullRequestService) (*domain.PullRequest, error) {
+			return s.AssignReviewer(context.Background(), "pr1", "u9")
+		},
+		"self-assignment": func(s *PullRequestService) (*domain.PullRequest, error) {
+			return s.SelfAssign(context.Background(), "pr1", "u9")
+		},
+	}
+
+	for name, assign := range paths {
+		t.Run(name+" rejected at the cap", func(t *testing.T) {
+			service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{MaxReviewers: 3}))
+			prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+			prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(openPR("u2", "u3", "u4"), nil)
+			userRepo.On("GetByID", mock.Anything, "u9").Return(teammate, nil)
+			userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+
+			pr, err := assign(service)
+
+			assert.ErrorIs(t, err, domain.ErrReviewerCapReached)
+			var conflict *domain.ConflictError
+			require.ErrorAs(t, err, &conflict)
+			assert.Equal(t, &domain.ReviewerCapLimit{Limit: 3, Reviewers: 3}, conflict.Payload)
+			assert.Nil(t, pr)
+			prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		t.Run(name+" allowed below a raised cap", func(t *testing.T) {
+			service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{MaxReviewers: 3}))
+			prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+			prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(openPR("u2", "u3"), nil)
+			userRepo.On("GetByID", mock.Anything, "u9").Return(teammate, nil)
+			userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+			prRepo.On("AssignReviewer", mock.Anything, "pr1", "u9", domain.AssignmentSourceManual).Return(nil)
+
+			_, err := assign(service)
+
+			require.NoError(t, err)
+			prRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("auto-assignment picks ReviewersPerPR below the cap", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{MaxReviewers: 5, ReviewersPerPR: 3}))
+		prCreate := domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR", AuthorID: "author1"}
+		candidates := make([]domain.User, 5)
+		for i := range candidates {
+			candidates[i] = domain.User{UserID: fmt.Sprintf("u%d", i+2), TeamName: "team1", IsActive: true}
+		}
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).Return(candidates, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, prCreate).Return(time.Now(), nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", mock.Anything, domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(openPR("u2", "u3", "u4"), nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		prRepo.AssertNumberOfCalls(t, "AssignReviewer", 3)
+	})
+
+	t.Run("default cap is ten", func(t *testing.T) {
+		reviewers := make([]string, 10)
+		for i := range reviewers {
+			reviewers[i] = fmt.Sprintf("r%d", i)
+		}
+		service, prRepo, userRepo, _ := setupTestService()
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(openPR(reviewers...), nil)
+		userRepo.On("GetByID", mock.Anything, "u9").Return(teammate, nil)
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+
+		_, err := service.AssignReviewer(context.Background(), "pr1", "u9")
+
+		assert.ErrorIs(t, err, domain.ErrReviewerCapReached)
+		var conflict *domain.ConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, &domain.ReviewerCapLimit{Limit: 10, Reviewers: 10}, conflict.Payload)
+	})
+}
+
+func TestPullRequestService_SetReviewers(t *testing.T) {
+	newPR := func(status domain.PRStatus, reviewers ...string) *domain.PullRequest {
+		return &domain.PullRequest{PullRequestID: "pr1", AuthorID: "author1", Status: status, AssignedReviewers: reviewers}
+	}
+	// активность и команда не проверяются
+	inactiveStranger := &domain.User{UserID: "stranger", TeamName: "team2", IsActive: false}
+
+	tests := []struct {
+		name        string
+		pr          *domain.PullRequest
+		reviewerIDs []string
+		force       bool
+		setupMocks  func(*mocks.PullRequestRepository, *mocks.UserRepository)
+		wantErr     error
+	}{
+		{
+			name:        "replaces reviewers wholesale",
+			pr:          newPR(domain.PRStatusOpen, "reviewer1", "reviewer2"),
+			reviewerIDs: []string{"stranger", "reviewer1", "stranger"},
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "stranger").Return(inactiveStranger, nil)
+				userRepo.On("GetByID", mock.Anything, "reviewer1").Return(&domain.User{UserID: "reviewer1"}, nil)
+				prRepo.On("ReplaceReviewers", mock.Anything, "pr1", []string{"stranger", "reviewer1"}, domain.AssignmentSourceAdmin).Return(nil)
+			},
+		},
+		{
+			name:        "author in list",
+			pr:          newPR(domain.PRStatusOpen),
+			reviewerIDs: []string{"reviewer1", "author1"},
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "reviewer1").Return(&domain.User{UserID: "reviewer1"}, nil)
+			},
+			wantErr: domain.ErrReviewerIsAuthor,
+		},
+		{
+			name:        "unknown user",
+			pr:          newPR(domain.PRStatusOpen),
+			reviewerIDs: []string{"ghost"},
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "ghost").Return(nil, repository.ErrNotFound)
+			},
+			wantErr: domain.ErrUserNotFound,
+		},
+		{
+			name:        "merged PR without force",
+			pr:          newPR(domain.PRStatusMerged, "reviewer1"),
+			reviewerIDs: []string{"stranger"},
+			setupMocks:  func(*mocks.PullRequestRepository, *mocks.UserRepository) {},
+			wantErr:     domain.ErrPRMerged,
+		},
+		{
+			name:        "merged PR with force",
+			pr:          newPR(domain.PRStatusMerged, "reviewer1"),
+			reviewerIDs: []string{"stranger"},
+			force:       true,
+			setupMocks: func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "stranger").Return(inactiveStranger, nil)
+				prRepo.On("ReplaceReviewers", mock.Anything, "pr1", []string{"stranger"}, domain.AssignmentSourceAdmin).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, prRepo, userRepo, _ := setupTestService()
+			prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+			prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(tt.pr, nil)
+			tt.setupMocks(prRepo, userRepo)
+
+			pr, err := service.SetReviewers(context.Background(), "pr1", tt.reviewerIDs, tt.force)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, pr)
+				prRepo.AssertNotCalled(t, "ReplaceReviewers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, pr)
+			prRepo.AssertExpectations(t)
+			userRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("list over reviewer cap", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService(WithConfig(Config{MaxReviewers: 2}))
+
+		pr, err := service.SetReviewers(context.Background(), "pr1", []string{"u1", "u2", "u3"}, false)
+
+		assert.ErrorIs(t, err, domain.ErrReviewerCapReached)
+		var conflict *domain.ConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, &domain.ReviewerCapLimit{Limit: 2, Reviewers: 3}, conflict.Payload)
+		assert.Nil(t, pr)
+		prRepo.AssertNotCalled(t, "LockPullRequest", mock.Anything, mock.Anything)
+	})
+
+	t.Run("force exceeds reviewer cap", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+		reviewers := []string{"u1", "u2", "u3"}
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen,
+		}, nil).Once()
+		for _, id := range reviewers {
+			userRepo.On("GetByID", mock.Anything, id).Return(&domain.User{UserID: id}, nil)
+		}
+		prRepo.On("ReplaceReviewers", mock.Anything, "pr1", reviewers, domain.AssignmentSourceAdmin).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: reviewers,
+		}, nil)
+
+		pr, err := service.SetReviewers(context.Background(), "pr1", reviewers, true)
+
+		require.NoError(t, err)
+		assert.Equal(t, reviewers, pr.AssignedReviewers)
+	})
+}
+
+func TestPullRequestService_MergeClosedPR(t *testing.T) {
+	service, prRepo, _, _ := setupTestService()
+	prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+	prRepo.On("Exists", mock.Anything, "pr1").Return(true, nil)
+	prRepo.On("MergePullRequest", mock.Anything, "pr1", 2, "").Return(false, nil)
+	prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{PullRequestID: "pr1", Status: domain.PRStatusClosed}, nil)
+
+	pr, err := service.MergePullRequest(context.Background(), "pr1", "")
+
+	assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+	assert.Nil(t, pr)
+}
+
+func TestPullRequestService_ResolveReviewers(t *testing.T) {
+	candidates := []domain.User{
+		{UserID: "u2", IsActive: true},
+		{UserID: "u3", IsActive: true},
+		{UserID: "u4", IsActive: true},
+	}
+
+	tests := []struct {
+		name       string
+		policy     NoReviewersPolicy
+		candidates []domain.User
+		wantIDs    []string
+		wantErr    error
+	}{
+		{name: "default policy, no candidates", policy: "", wantIDs: []string{}},
+		{name: "allow, no candidates", policy: NoReviewersAllow, wantIDs: []string{}},
+		{name: "fail, no candidates", policy: NoReviewersFail, wantErr: domain.ErrNoCandidate},
+		{name: "author, no candidates", policy: NoReviewersAssignAuthor, wantIDs: []string{"author1"}},
+		{name: "author, candidates available", policy: NoReviewersAssignAuthor, candidates: candidates[:1], wantIDs: []string{"u2"}},
+		{name: "fail, candidates available", policy: NoReviewersFail, candidates: candidates[:1], wantIDs: []string{"u2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _, _ := setupTestService(WithConfig(Config{NoReviewersPolicy: tt.policy}))
+
+			ids, err := service.resolveReviewers(tt.candidates, "author1", 0)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, ids)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+
+	t.Run("selection respects reviewers per PR", func(t *testing.T) {
+		service, _, _, _ := setupTestService()
+
+		chosen, err := service.selectByStrategy(context.Background(), service.lg, candidates, service.reviewersPerPR(), "")
+		require.NoError(t, err)
+		ids, err := service.resolveReviewers(chosen, "author1", 0)
+
+		require.NoError(t, err)
+		assert.Len(t, ids, defaultReviewersPerPR)
+		assert.NotContains(t, ids, "author1")
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		service, _, _, _ := setupTestService(WithConfig(Config{NoReviewersPolicy: "random"}))
+
+		_, err := service.resolveReviewers(nil, "author1", 0)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestPullRequestService_CreatePullRequest_Blackout(t *testing.T) {
+	now := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+	author := &domain.User{UserID: "author1", TeamName: "team1", IsActive: true}
+	prCreate := domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1"}
+
+	t.Run("team in blackout gets no reviewers", func(t *testing.T) {
+		blackouts := new(mocks.BlackoutRepository)
+		service, prRepo, userRepo, _ := setupTestService(
+			WithClock(clock.NewFake(now)),
+			WithBlackouts(blackouts),
+			WithConfig(Config{NoReviewersPolicy: NoReviewersFail}),
+		)
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		blackouts.On("IsInBlackout", mock.Anything, "team1", now).Return(true, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.MatchedBy(func(pr domain.PullRequestCreate) bool {
+			return pr.PendingAssignment
+		})).Return(now, nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen,
+			AssignedReviewers: []string{}, PendingAssignment: true,
+		}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.True(t, pr.PendingAssignment)
+		assert.Empty(t, pr.AssignedReviewers)
+		userRepo.AssertNotCalled(t, "GetActiveByTeam", mock.Anything, mock.Anything, mock.Anything)
+		prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("outside blackout assigns as usual", func(t *testing.T) {
+		blackouts := new(mocks.BlackoutRepository)
+		service, prRepo, userRepo, _ := setupTestService(WithClock(clock.NewFake(now)), WithBlackouts(blackouts))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		blackouts.On("IsInBlackout", mock.Anything, "team1", now).Return(false, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).
+			Return([]domain.User{{UserID: "u2", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.MatchedBy(func(pr domain.PullRequestCreate) bool {
+			return !pr.PendingAssignment
+		})).Return(now, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u2", domain.AssignmentSourceAuto).Return(nil).Once()
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2"},
+		}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("blackout check error", func(t *testing.T) {
+		blackouts := new(mocks.BlackoutRepository)
+		service, prRepo, userRepo, _ := setupTestService(WithClock(clock.NewFake(now)), WithBlackouts(blackouts))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		blackouts.On("IsInBlackout", mock.Anything, "team1", now).Return(false, errors.New("db error"))
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to check team blackout")
+		prRepo.AssertNotCalled(t, "CreatePullRequest", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPullRequestService_CreatePullRequest_MissingTeam(t *testing.T) {
+	now := time.Now()
+	author := &domain.User{UserID: "author1", TeamName: "deleted", IsActive: true}
+	prCreate := domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1"}
+
+	t.Run("rejected by default", func(t *testing.T) {
+		teams := new(mocks.TeamRepository)
+		service, prRepo, userRepo, _ := setupTestService(WithTeams(teams))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		teams.On("Exists", mock.Anything, "deleted").Return(false, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.ErrorIs(t, err, domain.ErrAuthorTeamMissing)
+		userRepo.AssertNotCalled(t, "GetActiveByTeam", mock.Anything, mock.Anything, mock.Anything)
+		prRepo.AssertNotCalled(t, "CreatePullRequest", mock.Anything, mock.Anything)
+	})
+
+	t.Run("allowed by config", func(t *testing.T) {
+		teams := new(mocks.TeamRepository)
+		service, prRepo, userRepo, _ := setupTestService(WithTeams(teams), WithConfig(Config{AllowMissingAuthorTeam: true}))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "deleted", []string{"author1"}).Return([]domain.User{}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(now, nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{},
+		}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Empty(t, pr.AssignedReviewers)
+		teams.AssertNotCalled(t, "Exists", mock.Anything, mock.Anything)
+	})
+
+	t.Run("existing team passes", func(t *testing.T) {
+		teams := new(mocks.TeamRepository)
+		service, prRepo, userRepo, _ := setupTestService(WithTeams(teams))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(&domain.User{UserID: "author1", TeamName: "team1", IsActive: true}, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		teams.On("Exists", mock.Anything, "team1").Return(true, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).
+			Return([]domain.User{{UserID: "u2", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(now, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u2", domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2"},
+		}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+		teams.AssertExpectations(t)
+	})
+}
+
+func TestPullRequestService_CreatePullRequest_ShadowStrategy(t *testing.T) {
+	// понедельник: u2 в рабочем окне, u3 - нет, зато у него нет открытых ревью
+	now := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+	candidates := []domain.User{
+		{UserID: "u2", TeamName: "team1", IsActive: true, WorkingHours: "Mon-Fri 09:00-18:00", Timezone: "UTC"},
+		{UserID: "u3", TeamName: "team1", IsActive: true, WorkingHours: "Sat-Sun 10:00-18:00", Timezone: "UTC"},
+	}
+	cfg := Config{ExcludeOutsideWorkingHours: true, MaxReviewers: 1, ShadowStrategy: ShadowStrategyLeastLoaded}
+
+	setup := func(t *testing.T, counts map[string]int, countsErr error) (*PullRequestService, *mocks.PullRequestRepository, *bytes.Buffer, prometheus.Counter) {
+		t.Helper()
+
+		var logs bytes.Buffer
+		prRepo := new(mocks.PullRequestRepository)
+		userRepo := new(mocks.UserRepository)
+		agreed := prometheus.NewCounter(prometheus.CounterOpts{Name: "agreed"})
+		disagreed := prometheus.NewCounter(prometheus.CounterOpts{Name: "disagreed"})
+		service := NewPullRequestService(prRepo, userRepo, dbmocks.NewMockTransactionManager(),
+			slog.New(slog.NewJSONHandler(&logs, nil)),
+			WithConfig(cfg), WithClock(clock.NewFake(now)), WithShadowCounters(agreed, disagreed))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(&domain.User{UserID: "author1", TeamName: "team1", IsActive: true}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).Return(candidates, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(now, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u2", domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2"},
+		}, nil)
+		prRepo.On("GetOpenReviewCounts", mock.Anything, []string{"u2", "u3"}).Return(counts, countsErr)
+
+		return service, prRepo, &logs, disagreed
+	}
+
+	t.Run("shadow selection is logged, not assigned", func(t *testing.T) {
+		service, prRepo, logs, disagreed := setup(t, map[string]int{"u2": 5}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(),
+			domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+		prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, "pr1", "u3", mock.Anything)
+		assert.Equal(t, 1.0, testutil.ToFloat64(disagreed))
+
+		var entry map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n")) {
+			require.NoError(t, json.Unmarshal(line, &entry))
+			if entry["msg"] == "shadow reviewer selection" {
+				break
+			}
+		}
+		assert.Equal(t, "shadow reviewer selection", entry["msg"])
+		assert.Equal(t, []any{"u2"}, entry["reviewer_ids"])
+		assert.Equal(t, []any{"u3"}, entry["shadow_reviewer_ids"])
+		assert.Equal(t, false, entry["agreed"])
+	})
+
+	t.Run("shadow failure does not fail creation", func(t *testing.T) {
+		service, _, logs, disagreed := setup(t, nil, errors.New("db error"))
+
+		pr, err := service.CreatePullRequest(context.Background(),
+			domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+		assert.Zero(t, testutil.ToFloat64(disagreed))
+		assert.Contains(t, logs.String(), "shadow strategy failed")
+	})
+}
+
+func TestPullRequestService_AssignPendingReviewers(t *testing.T) {
+	start := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+	blackoutEnd := start.Add(time.Hour)
+	inBlackout := func(at time.Time) bool { return at.Before(blackoutEnd) }
+
+	t.Run("waits for blackout end, then tops up reviewers", func(t *testing.T) {
+		fake := clock.NewFake(start)
+		blackouts := new(mocks.BlackoutRepository)
+		service, prRepo, userRepo, _ := setupTestService(WithClock(fake), WithBlackouts(blackouts))
+
+		prRepo.On("GetPendingAssignmentPRs", mock.Anything).Return([]string{"pr1"}, nil)
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		// ревьюер u2 назначен вручную во время blackout
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "a1", Status: domain.PRStatusOpen,
+			AssignedReviewers: []string{"u2"}, PendingAssignment: true,
+		}, nil)
+		userRepo.On("GetByID", mock.Anything, "a1").Return(&domain.User{UserID: "a1", TeamName: "backend"}, nil)
+		blackouts.On("IsInBlackout", mock.Anything, "backend", mock.MatchedBy(inBlackout)).Return(true, nil)
+		blackouts.On("IsInBlackout", mock.Anything, "backend", mock.MatchedBy(func(at time.Time) bool {
+			return !inBlackout(at)
+		})).Return(false, nil)
+
+		report, err := service.AssignPendingReviewers(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pr1"}, report.Waiting)
+		assert.Empty(t, report.Assigned)
+		prRepo.AssertNotCalled(t, "ClearPendingAssignment", mock.Anything, mock.Anything)
+
+		fake.Advance(time.Hour)
+		userRepo.On("GetActiveByTeam", mock.Anything, "backend", []string{"a1", "u2"}).
+			Return([]domain.User{{UserID: "u3", TeamName: "backend", IsActive: true}}, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u3", domain.AssignmentSourceAuto).Return(nil).Once()
+		prRepo.On("ClearPendingAssignment", mock.Anything, "pr1").Return(nil).Once()
+
+		report, err = service.AssignPendingReviewers(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pr1"}, report.Assigned)
+		assert.Empty(t, report.Waiting)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("full PR only clears flag", func(t *testing.T) {
+		blackouts := new(mocks.BlackoutRepository)
+		service, prRepo, userRepo, _ := setupTestService(WithClock(clock.NewFake(blackoutEnd)), WithBlackouts(blackouts))
+
+		prRepo.On("GetPendingAssignmentPRs", mock.Anything).Return([]string{"pr1"}, nil)
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "a1", Status: domain.PRStatusOpen,
+			AssignedReviewers: []string{"u2", "u3"}, PendingAssignment: true,
+		}, nil)
+		userRepo.On("GetByID", mock.Anything, "a1").Return(&domain.User{UserID: "a1", TeamName: "backend"}, nil)
+		blackouts.On("IsInBlackout", mock.Anything, "backend", blackoutEnd).Return(false, nil)
+		prRepo.On("ClearPendingAssignment", mock.Anything, "pr1").Return(nil).Once()
+
+		report, err := service.AssignPendingReviewers(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pr1"}, report.Assigned)
+		userRepo.AssertNotCalled(t, "GetActiveByTeam", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("skips PR merged meanwhile", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+
+		prRepo.On("GetPendingAssignmentPRs", mock.Anything).Return([]string{"pr1"}, nil)
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "a1", Status: domain.PRStatusMerged, PendingAssignment: true,
+		}, nil)
+
+		report, err := service.AssignPendingReviewers(context.Background())
+
+		require.NoError(t, err)
+		assert.Empty(t, report.Assigned)
+		assert.Empty(t, report.Waiting)
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+		prRepo.On("GetPendingAssignmentPRs", mock.Anything).Return(nil, errors.New("db error"))
+
+		report, err := service.AssignPendingReviewers(context.Background())
+
+		require.Error(t, err)
+		assert.Nil(t, report)
+	})
+}
+
+func TestPullRequestService_RetryUnderstaffed(t *testing.T) {
+	author := &domain.User{UserID: "a1", TeamName: "team1", IsActive: true}
+	prCreate := domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "a1"}
+	pending := &domain.PullRequest{
+		PullRequestID: "pr1", AuthorID: "a1", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"}, PendingAssignment: true,
+	}
+
+	t.Run("pending PR gets reviewer after member reactivates", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{RetryUnderstaffed: true}))
+
+		userRepo.On("GetByID", mock.Anything, "a1").Return(author, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"a1"}).
+			Return([]domain.User{{UserID: "u2", TeamName: "team1", IsActive: true}}, nil).Once()
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.AnythingOfType("domain.PullRequestCreate")).Return(time.Now(), nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u2", domain.AssignmentSourceAuto).Return(nil).Once()
+		prRepo.On("MarkPendingAssignment", mock.Anything, "pr1").Return(nil).Once()
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(pending, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.True(t, pr.PendingAssignment)
+		assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+
+		// второго кандидата пока нет - PR остаётся в очереди
+		prRepo.On("GetPendingAssignmentPRs", mock.Anything).Return([]string{"pr1"}, nil)
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"a1", "u2"}).Return(nil, nil).Once()
+
+		report, err := service.AssignPendingReviewers(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pr1"}, report.Waiting)
+		prRepo.AssertNotCalled(t, "ClearPendingAssignment", mock.Anything, mock.Anything)
+
+		// u3 снова активен
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"a1", "u2"}).
+			Return([]domain.User{{UserID: "u3", TeamName: "team1", IsActive: true}}, nil).Once()
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u3", domain.AssignmentSourceAuto).Return(nil).Once()
+		prRepo.On("ClearPendingAssignment", mock.Anything, "pr1").Return(nil).Once()
+
+		report, err = service.AssignPendingReviewers(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pr1"}, report.Assigned)
+		assert.Empty(t, report.Waiting)
+		prRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("disabled retry does not queue PR", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+
+		userRepo.On("GetByID", mock.Anything, "a1").Return(author, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"a1"}).
+			Return([]domain.User{{UserID: "u2", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.AnythingOfType("domain.PullRequestCreate")).Return(time.Now(), nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u2", domain.AssignmentSourceAuto).Return(nil).Once()
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "a1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2"},
+		}, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		prRepo.AssertNotCalled(t, "MarkPendingAssignment", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPullRequestService_ReassignIfInactive(t *testing.T) {
+	pr := &domain.PullRequest{
+		PullRequestID:     "pr1",
+		AuthorID:          "author1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"reviewer1", "reviewer2"},
+	}
+
+	t.Run("active reviewer is left untouched", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(pr, nil).Once()
+		userRepo.On("GetByID", mock.Anything, "reviewer1").
+			Return(&domain.User{UserID: "reviewer1", TeamName: "team1", IsActive: true}, nil)
+
+		result, newReviewerID, err := service.ReassignIfInactive(context.Background(), "pr1", "reviewer1")
+
+		require.NoError(t, err)
+		assert.Empty(t, newReviewerID)
+		assert.Equal(t, []string{"reviewer1", "reviewer2"}, result.AssignedReviewers)
+		userRepo.AssertNotCalled(t, "GetActiveByTeam", mock.Anything, mock.Anything, mock.Anything)
+		prRepo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, mock.Anything, mock.Anything)
+		prRepo.AssertNotCalled(t, "IncrementReassignmentCount", mock.Anything, mock.Anything)
+	})
+
+	t.Run("inactive reviewer is replaced", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(pr, nil).Once()
+		userRepo.On("GetByID", mock.Anything, "reviewer1").
+			Return(&domain.User{UserID: "reviewer1", TeamName: "team1", IsActive: false}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "reviewer1", "reviewer2"}).
+			Return([]domain.User{{UserID: "reviewer3", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "pr1", "reviewer1").Return(true, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "reviewer3", domain.AssignmentSourceReassignment).Return(nil)
+		prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen,
+			AssignedReviewers: []string{"reviewer2", "reviewer3"}, ReassignmentCount: 1,
+		}, nil).Once()
+
+		result, newReviewerID, err := service.ReassignIfInactive(context.Background(), "pr1", "reviewer1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "reviewer3", newReviewerID)
+		assert.Equal(t, []string{"reviewer2", "reviewer3"}, result.AssignedReviewers)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("reviewer not assigned", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(pr, nil).Once()
+
+		_, _, err := service.ReassignIfInactive(context.Background(), "pr1", "stranger")
+
+		require.ErrorIs(t, err, domain.ErrNotAssigned)
+	})
+}
+
+func TestPullRequestService_CreatePullRequest_AssignFallback(t *testing.T) {
+	now := time.Now()
+	author := &domain.User{UserID: "author1", TeamName: "team1", IsActive: true}
+	prCreate := domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1"}
+	deleted := fmt.Errorf("failed to assign reviewer: %w", repository.ErrReferenceNotFound)
+
+	setup := func(t *testing.T, cfg Config, candidates []domain.User) (*PullRequestService, *mocks.PullRequestRepository) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(cfg))
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).Return(candidates, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(now, nil)
+		return service, prRepo
+	}
+
+	t.Run("first candidate deleted, next one takes the slot", func(t *testing.T) {
+		// при одном слоте выбор случаен, но итог всегда u2: u1 либо не выбран, либо отвергнут
+		service, prRepo := setup(t, Config{MaxReviewers: 1}, []domain.User{
+			{UserID: "u1", TeamName: "team1", IsActive: true},
+			{UserID: "u2", TeamName: "team1", IsActive: true},
+		})
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u1", domain.AssignmentSourceAuto).Return(deleted).Maybe()
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u2", domain.AssignmentSourceAuto).Return(nil).Once()
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2"},
+		}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("concurrent duplicate and deactivation are retried", func(t *testing.T) {
+		service, prRepo := setup(t, Config{}, []domain.User{
+			{UserID: "u1", TeamName: "team1", IsActive: true},
+			{UserID: "u2", TeamName: "team1", IsActive: true},
+			{UserID: "u3", TeamName: "team1", IsActive: true},
+			{UserID: "u4", TeamName: "team1", IsActive: true},
+		})
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u1", domain.AssignmentSourceAuto).Return(repository.ErrAlreadyExists).Maybe()
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u2", domain.AssignmentSourceAuto).Return(repository.ErrInactiveReviewer).Maybe()
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u3", domain.AssignmentSourceAuto).Return(nil).Once()
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u4", domain.AssignmentSourceAuto).Return(nil).Once()
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u3", "u4"},
+		}, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("pool exhausted with fail policy", func(t *testing.T) {
+		service, prRepo := setup(t, Config{NoReviewersPolicy: NoReviewersFail}, []domain.User{
+			{UserID: "u1", TeamName: "team1", IsActive: true},
+		})
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u1", domain.AssignmentSourceAuto).Return(deleted).Once()
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.ErrorIs(t, err, domain.ErrNoCandidate)
+	})
+
+	t.Run("pool exhausted with allow policy creates PR without reviewers", func(t *testing.T) {
+		service, prRepo := setup(t, Config{}, []domain.User{
+			{UserID: "u1", TeamName: "team1", IsActive: true},
+		})
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u1", domain.AssignmentSourceAuto).Return(deleted).Once()
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", Status: domain.PRStatusOpen, AssignedReviewers: []string{},
+		}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Empty(t, pr.AssignedReviewers)
+	})
+
+	t.Run("other errors abort", func(t *testing.T) {
+		service, prRepo := setup(t, Config{}, []domain.User{
+			{UserID: "u1", TeamName: "team1", IsActive: true},
+		})
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u1", domain.AssignmentSourceAuto).Return(errors.New("connection reset")).Once()
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to assign reviewer u1")
+	})
+}
+
+func TestPullRequestService_RebalanceTeam(t *testing.T) {
+	t.Run("spreads reviews onto newcomers", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+
+		members := []domain.User{
+			{UserID: "u1", TeamName: "backend", IsActive: true},
+			{UserID: "u2", TeamName: "backend", IsActive: true},
+			{UserID: "new1", TeamName: "backend", IsActive: true},
+			{UserID: "new2", TeamName: "backend", IsActive: true},
+		}
+		reviewers := map[string][]string{}
+		var assignments []domain.ReviewerAssignment
+		for i := 1; i <= 4; i++ {
+			prID := fmt.Sprintf("pr%d", i)
+			reviewers[prID] = []string{"u1", "u2"}
+			assignments = append(assignments,
+				domain.ReviewerAssignment{PullRequestID: prID, UserID: "u1", TeamName: "backend"},
+				domain.ReviewerAssignment{PullRequestID: prID, UserID: "u2", TeamName: "backend"})
+		}
+
+		userRepo.On("GetActiveByTeam", mock.Anything, "backend", []string{}).Return(members, nil)
+		prRepo.On("GetOpenReviewCounts", mock.Anything, []string{"u1", "u2", "new1", "new2"}).
+			Return(map[string]int{"u1": 4, "u2": 4}, nil)
+		prRepo.On("GetTeamReviewAssignments", mock.Anything, "backend").Return(assignments, nil)
+		prRepo.On("LockPullRequest", mock.Anything, mock.Anything).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, mock.Anything).Return(
+			func(_ context.Context, prID string) (*domain.PullRequest, error) {
+				return &domain.PullRequest{
+					PullRequestID: prID, AuthorID: "author", Status: domain.PRStatusOpen,
+					AssignedReviewers: slices.Clone(reviewers[prID]),
+				}, nil
+			})
+		prRepo.On("RemoveReviewer", mock.Anything, mock.Anything, mock.Anything).Return(true, nil).
+			Run(func(args mock.Arguments) {
+				prID, userID := args.String(1), args.String(2)
+				reviewers[prID] = slices.DeleteFunc(reviewers[prID], func(id string) bool { return id == userID })
+			})
+		prRepo.On("AssignReviewer", mock.Anything, mock.Anything, mock.Anything, domain.AssignmentSourceRebalance).Return(nil).
+			Run(func(args mock.Arguments) {
+				prID := args.String(1)
+				reviewers[prID] = append(reviewers[prID], args.String(2))
+			})
+		prRepo.On("IncrementReassignmentCount", mock.Anything, mock.Anything).Return(nil)
+
+		report, err := service.RebalanceTeam(context.Background(), "backend")
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"u1": 4, "u2": 4, "new1": 0, "new2": 0}, report.LoadBefore)
+		assert.Equal(t, map[string]int{"u1": 2, "u2": 2, "new1": 2, "new2": 2}, report.LoadAfter)
+		assert.Len(t, report.Moved, 4)
+
+		actual := map[string]int{}
+		for prID, ids := range reviewers {
+			assert.Len(t, ids, 2, prID)
+			for _, id := range ids {
+				actual[id]++
+			}
+		}
+		assert.Equal(t, report.LoadAfter, actual)
+	})
+
+	t.Run("prefers higher capacity at equal load", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+
+		// у high и low по одному ревью, но high готов брать вдвое больше обычного, а low - вдвое меньше
+		userRepo.On("GetActiveByTeam", mock.Anything, "backend", []string{}).Return([]domain.User{
+			{UserID: "u1", TeamName: "backend", IsActive: true, ReviewCapacity: 1},
+			{UserID: "high", TeamName: "backend", IsActive: true, ReviewCapacity: 2},
+			{UserID: "low", TeamName: "backend", IsActive: true, ReviewCapacity: 0.5},
+		}, nil)
+		prRepo.On("GetOpenReviewCounts", mock.Anything, []string{"u1", "high", "low"}).
+			Return(map[string]int{"u1": 4, "high": 1, "low": 1}, nil)
+		prRepo.On("GetTeamReviewAssignments", mock.Anything, "backend").Return([]domain.ReviewerAssignment{
+			{PullRequestID: "pr1", UserID: "u1", TeamName: "backend"},
+		}, nil)
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u1"},
+		}, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "pr1", "u1").Return(true, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "high", domain.AssignmentSourceRebalance).Return(nil)
+		prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil)
+
+		report, err := service.RebalanceTeam(context.Background(), "backend")
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.ReviewerReplacement{{PullRequestID: "pr1", OldUserID: "u1", NewUserID: "high"}}, report.Moved)
+		prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, "pr1", "low", mock.Anything)
+	})
+
+	t.Run("even team untouched", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+
+		userRepo.On("GetActiveByTeam", mock.Anything, "backend", []string{}).Return([]domain.User{
+			{UserID: "u1", TeamName: "backend", IsActive: true},
+			{UserID: "u2", TeamName: "backend", IsActive: true},
+		}, nil)
+		prRepo.On("GetOpenReviewCounts", mock.Anything, []string{"u1", "u2"}).Return(map[string]int{"u1": 2, "u2": 1}, nil)
+		prRepo.On("GetTeamReviewAssignments", mock.Anything, "backend").Return([]domain.ReviewerAssignment{
+			{PullRequestID: "pr1", UserID: "u1", TeamName: "backend"},
+			{PullRequestID: "pr2", UserID: "u1", TeamName: "backend"},
+			{PullRequestID: "pr2", UserID: "u2", TeamName: "backend"},
+		}, nil)
+
+		report, err := service.RebalanceTeam(context.Background(), "backend")
+
+		require.NoError(t, err)
+		assert.Empty(t, report.Moved)
+		assert.Equal(t, report.LoadBefore, report.LoadAfter)
+		prRepo.AssertNotCalled(t, "LockPullRequest", mock.Anything, mock.Anything)
+	})
+
+	t.Run("foreign team key", func(t *testing.T) {
+		service, _, userRepo, _ := setupTestService()
+
+		ctx := auth.WithScope(context.Background(), auth.Scope{Team: "frontend"})
+		_, err := service.RebalanceTeam(ctx, "backend")
+
+		require.ErrorIs(t, err, domain.ErrForbidden)
+		userRepo.AssertNotCalled(t, "GetActiveByTeam", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestNormalizeExclusions(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []string
+		want []string
+	}{
+		{name: "nil", ids: nil, want: []string{}},
+		{name: "empty", ids: []string{}, want: []string{}},
+		{name: "only empty strings", ids: []string{"", ""}, want: []string{}},
+		{name: "duplicates keep first position", ids: []string{"author", "r1", "author", "r2", "r1"}, want: []string{"author", "r1", "r2"}},
+		{name: "empties dropped", ids: []string{"", "author", "", "r1"}, want: []string{"author", "r1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := slices.Clone(tt.ids)
+
+			got := normalizeExclusions(ids)
+
+			assert.Equal(t, tt.want, got)
+			assert.NotNil(t, got)
+			assert.Equal(t, tt.ids, ids, "input must not be modified")
+		})
+	}
+}
+
+func TestPullRequestService_ReassignReviewer_NormalizesExclusions(t *testing.T) {
+	service, prRepo, userRepo, _ := setupTestService()
+
+	// в PR повторно записан ревьюер и пустой ID из старых данных
+	prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+		PullRequestID: "pr1", AuthorID: "author", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"r1", "", "r1", "gone"},
+	}, nil)
+	userRepo.On("GetByID", mock.Anything, "r1").Return(&domain.User{UserID: "r1", TeamName: "backend", IsActive: true}, nil)
+	userRepo.On("GetActiveByTeam", mock.Anything, "backend", []string{"author", "r1", "gone"}).Return([]domain.User{}, nil)
+	userRepo.On("GetCandidateBreakdown", mock.Anything, "backend", []string{"author", "r1", "gone"}, 0).
+		Return(domain.CandidateBreakdown{TeamMembers: 2, Active: 2, Excluded: 2}, nil)
+
+	_, _, err := service.ReassignReviewer(context.Background(), "pr1", "r1")
+
+	require.ErrorIs(t, err, domain.ErrNoCandidate)
+	userRepo.AssertExpectations(t)
+}
+
+func TestPullRequestService_ReassignReviewer_NoCandidateBreakdown(t *testing.T) {
+	service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{MaxOpenReviews: 2}))
+
+	// команда: автор, два назначенных ревьюера, два перегруженных, один неактивный
+	prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+		PullRequestID: "pr1", AuthorID: "author", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"r1", "r2"},
+	}, nil)
+	userRepo.On("GetByID", mock.Anything, "r1").Return(&domain.User{UserID: "r1", TeamName: "backend", IsActive: true}, nil)
+	userRepo.On("GetActiveByTeam", mock.Anything, "backend", []string{"author", "r1", "r2"}).Return([]domain.User{
+		{UserID: "busy1", TeamName: "backend", IsActive: true},
+		{UserID: "busy2", TeamName: "backend", IsActive: true},
+	}, nil)
+	prRepo.On("GetOpenReviewCounts", mock.Anything, []string{"busy1", "busy2"}).Return(map[string]int{"busy1": 2, "busy2": 3}, nil)
+	breakdown := domain.CandidateBreakdown{TeamMembers: 6, Active: 5, Excluded: 3, OverCapacity: 2, Remaining: 0}
+	userRepo.On("GetCandidateBreakdown", mock.Anything, "backend", []string{"author", "r1", "r2"}, 2).Return(breakdown, nil)
+
+	_, _, err := service.ReassignReviewer(context.Background(), "pr1", "r1")
+
+	require.ErrorIs(t, err, domain.ErrNoCandidate)
+	var conflict *domain.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	// пул - два активных кандидата до проверки нагрузки
+	breakdown.CandidatePool = 2
+	assert.Equal(t, &breakdown, conflict.Payload)
+	prRepo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, mock.Anything, mock.Anything)
+	userRepo.AssertExpectations(t)
+}
+
+func TestPullRequestService_ReassignReviewer_SuccessSkipsBreakdown(t *testing.T) {
+	service, prRepo, userRepo, _ := setupTestService()
+
+	prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+		PullRequestID: "pr1", AuthorID: "author", Status: domain.PRStatusOpen, AssignedReviewers: []string{"r1"},
+	}, nil)
+	userRepo.On("GetByID", mock.Anything, "r1").Return(&domain.User{UserID: "r1", TeamName: "backend", IsActive: true}, nil)
+	userRepo.On("GetActiveByTeam", mock.Anything, "backend", []string{"author", "r1"}).
+		Return([]domain.User{{UserID: "r2", TeamName: "backend", IsActive: true}}, nil)
+	prRepo.On("RemoveReviewer", mock.Anything, "pr1", "r1").Return(true, nil)
+	prRepo.On("AssignReviewer", mock.Anything, "pr1", "r2", domain.AssignmentSourceReassignment).Return(nil)
+	prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil)
+
+	_, newReviewerID, err := service.ReassignReviewer(context.Background(), "pr1", "r1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "r2", newReviewerID)
+	userRepo.AssertNotCalled(t, "GetCandidateBreakdown", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPullRequestService_CreatePullRequest_InactiveAuthor(t *testing.T) {
+	now := time.Now()
+	author := &domain.User{UserID: "author1", TeamName: "team1", IsActive: false}
+	prCreate := domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1"}
+
+	t.Run("rejected by config", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{RejectInactiveAuthors: true}))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.ErrorIs(t, err, domain.ErrAuthorInactive)
+		userRepo.AssertNumberOfCalls(t, "GetByID", 1)
+		prRepo.AssertNotCalled(t, "Exists", mock.Anything, mock.Anything)
+		prRepo.AssertNotCalled(t, "CreatePullRequest", mock.Anything, mock.Anything)
+	})
+
+	t.Run("allowed by default with hint", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).
+			Return([]domain.User{{UserID: "u2", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(now, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u2", domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2"},
+		}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.True(t, pr.AuthorInactive)
+		assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+		userRepo.AssertNumberOfCalls(t, "GetByID", 1)
+	})
+
+	t.Run("active author has no hint", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{RejectInactiveAuthors: true}))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(&domain.User{UserID: "author1", TeamName: "team1", IsActive: true}, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).Return([]domain.User{}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(now, nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{},
+		}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.False(t, pr.AuthorInactive)
+	})
+}
+
+func TestPullRequestService_CreatePullRequest_ExcludesGroupAuthors(t *testing.T) {
+	now := time.Now()
+	author := &domain.User{UserID: "author1", TeamName: "team1", IsActive: true}
+	prCreate := domain.PullRequestCreate{PullRequestID: "pr2", PullRequestName: "PR2", AuthorID: "author1", GroupID: "epic-7"}
+
+	t.Run("group co-author is not a candidate", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{ExcludeGroupAuthors: true}))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		prRepo.On("Exists", mock.Anything, "pr2").Return(false, nil)
+		prRepo.On("GetOpenGroupAuthors", mock.Anything, "epic-7", "pr2").Return([]string{"coauthor"}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "coauthor"}).
+			Return([]domain.User{{UserID: "u3", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, prCreate).Return(now, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr2", "u3", domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr2").Return(&domain.PullRequest{
+			PullRequestID: "pr2", AuthorID: "author1", GroupID: "epic-7", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u3"},
+		}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u3"}, pr.AssignedReviewers)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		prRepo.On("Exists", mock.Anything, "pr2").Return(false, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).
+			Return([]domain.User{{UserID: "coauthor", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, prCreate).Return(now, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr2", "coauthor", domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr2").Return(&domain.PullRequest{
+			PullRequestID: "pr2", AuthorID: "author1", GroupID: "epic-7", Status: domain.PRStatusOpen, AssignedReviewers: []string{"coauthor"},
+		}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"coauthor"}, pr.AssignedReviewers)
+		prRepo.AssertNotCalled(t, "GetOpenGroupAuthors", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPullRequestService_GetTeamPolicy(t *testing.T) {
+	now := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+
+	t.Run("team override shadows global default", func(t *testing.T) {
+		teams := new(mocks.TeamRepository)
+		blackouts := new(mocks.BlackoutRepository)
+		service, _, _, _ := setupTestService(
+			WithClock(clock.NewFake(now)),
+			WithTeams(teams),
+			WithBlackouts(blackouts),
+			WithConfig(Config{MaxOpenReviews: 3, ExcludeGroupAuthors: true}),
+		)
+
+		teams.On("Exists", mock.Anything, "team1").Return(true, nil)
+		blackouts.On("IsInBlackout", mock.Anything, "team1", now).Return(true, nil)
+
+		policy, err := service.GetTeamPolicy(context.Background(), "team1")
+
+		require.NoError(t, err)
+		assert.False(t, policy.AutoAssign)
+		assert.Equal(t, []string{"auto_assign"}, policy.Overrides)
+		assert.Equal(t, 3, policy.MaxOpenReviews)
+		assert.True(t, policy.ExcludeGroupAuthors)
+	})
+
+	t.Run("defaults resolved without overrides", func(t *testing.T) {
+		teams := new(mocks.TeamRepository)
+		blackouts := new(mocks.BlackoutRepository)
+		service, _, _, _ := setupTestService(WithClock(clock.NewFake(now)), WithTeams(teams), WithBlackouts(blackouts))
+
+		teams.On("Exists", mock.Anything, "team1").Return(true, nil)
+		blackouts.On("IsInBlackout", mock.Anything, "team1", now).Return(false, nil)
+
+		policy, err := service.GetTeamPolicy(context.Background(), "team1")
+
+		require.NoError(t, err)
+		assert.Equal(t, &domain.TeamPolicy{
+			TeamName: "team1", MaxReviewers: defaultMaxReviewers, ReviewersPerPR: defaultReviewersPerPR, NoReviewersPolicy: string(NoReviewersAllow),
+			AutoAssign: true, Overrides: []string{},
+		}, policy)
+	})
+
+	t.Run("unknown team", func(t *testing.T) {
+		teams := new(mocks.TeamRepository)
+		blackouts := new(mocks.BlackoutRepository)
+		service, _, _, _ := setupTestService(WithTeams(teams), WithBlackouts(blackouts))
+
+		teams.On("Exists", mock.Anything, "ghost").Return(false, nil)
+
+		_, err := service.GetTeamPolicy(context.Background(), "ghost")
+
+		require.ErrorIs(t, err, domain.ErrTeamNotFound)
+		blackouts.AssertNotCalled(t, "IsInBlackout", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPullRequestService_ReassignReviewer_RateLimit(t *testing.T) {
+	now := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+	// снятия ревьюеров pr1: одно уже вне часового окна, три внутри
+	history := []time.Time{
+		now.Add(-90 * time.Minute),
+		now.Add(-50 * time.Minute),
+		now.Add(-30 * time.Minute),
+		now.Add(-10 * time.Minute),
+	}
+	removalsSince := func(_ context.Context, _ string, since time.Time) []time.Time {
+		var removals []time.Time
+		for _, at := range history {
+			if at.After(since) {
+				removals = append(removals, at)
+			}
+		}
+		return removals
+	}
+	pr := &domain.PullRequest{
+		PullRequestID: "pr1", AuthorID: "author", Status: domain.PRStatusOpen, AssignedReviewers: []string{"r1"},
+	}
+	reviewer := &domain.User{UserID: "r1", TeamName: "backend", IsActive: true}
+
+	t.Run("limit reached", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(
+			WithClock(clock.NewFake(now)),
+			WithConfig(Config{MaxReassignmentsPerHour: 2}),
+		)
+
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(pr, nil)
+		userRepo.On("GetByID", mock.Anything, "r1").Return(reviewer, nil)
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetReviewerRemovalsSince", mock.Anything, "pr1", now.Add(-time.Hour)).Return(removalsSince, nil)
+
+		_, _, err := service.ReassignReviewer(context.Background(), "pr1", "r1")
+
+		require.ErrorIs(t, err, domain.ErrReassignRateExceeded)
+		var conflict *domain.ConflictError
+		require.ErrorAs(t, err, &conflict)
+		// после выхода из окна снятия в -30m останется одно, меньше лимита
+		assert.Equal(t, &domain.ReassignRateLimit{Limit: 2, RetryAt: now.Add(30 * time.Minute), RetryAfter: 30 * time.Minute}, conflict.Payload)
+		prRepo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("under limit", func(t *testing.T) {
+		fake := clock.NewFake(now.Add(35 * time.Minute))
+		service, prRepo, userRepo, _ := setupTestService(WithClock(fake), WithConfig(Config{MaxReassignmentsPerHour: 2}))
+
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(pr, nil)
+		userRepo.On("GetByID", mock.Anything, "r1").Return(reviewer, nil)
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetReviewerRemovalsSince", mock.Anything, "pr1", fake.Now().Add(-time.Hour)).Return(removalsSince, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "backend", []string{"author", "r1"}).
+			Return([]domain.User{{UserID: "r2", TeamName: "backend", IsActive: true}}, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "pr1", "r1").Return(true, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "r2", domain.AssignmentSourceReassignment).Return(nil)
+		prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil)
+
+		_, newReviewerID, err := service.ReassignReviewer(context.Background(), "pr1", "r1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "r2", newReviewerID)
+	})
+
+	t.Run("not checked for inactive cleanup", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(
+			WithClock(clock.NewFake(now)),
+			WithConfig(Config{MaxReassignmentsPerHour: 2}),
+		)
+
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(pr, nil)
+		userRepo.On("GetByID", mock.Anything, "r1").Return(&domain.User{UserID: "r1", TeamName: "backend"}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "backend", []string{"author", "r1"}).
+			Return([]domain.User{{UserID: "r2", TeamName: "backend", IsActive: true}}, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "pr1", "r1").Return(true, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "r2", domain.AssignmentSourceReassignment).Return(nil)
+		prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil)
+
+		_, newReviewerID, err := service.ReassignIfInactive(context.Background(), "pr1", "r1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "r2", newReviewerID)
+		prRepo.AssertNotCalled(t, "GetReviewerRemovalsSince", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// recordingNotifier запоминает уведомления в порядке вызова
+type recordingNotifier struct {
+	calls []string
+}
+
+func (n *recordingNotifier) NotifyAssignment(pr domain.PullRequest, reviewerIDs []string) {
+	n.calls = append(n.calls, fmt.Sprintf("assignment %s %v", pr.PullRequestID, reviewerIDs))
+}
+
+func (n *recordingNotifier) NotifyReassignment(pr domain.PullRequest, oldReviewerID, newReviewerID string) {
+	n.calls = append(n.calls, fmt.Sprintf("reassignment %s %s->%s", pr.PullRequestID, oldReviewerID, newReviewerID))
+}
+
+func (n *recordingNotifier) NotifyNoCandidate(pr domain.PullRequest, reviewerID string) {
+	n.calls = append(n.calls, fmt.Sprintf("no_candidate %s %s", pr.PullRequestID, reviewerID))
+}
+
+func TestPullRequestService_Notifier(t *testing.T) {
+	now := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+	author := &domain.User{UserID: "author1", TeamName: "team1", IsActive: true}
+	prCreate := domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1"}
+
+	t.Run("create with reviewers", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		service, prRepo, userRepo, _ := setupTestService(WithNotifier(notifier))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).
+			Return([]domain.User{{UserID: "u2", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(now, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u2", domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2"},
+		}, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"assignment pr1 [u2]"}, notifier.calls)
+	})
+
+	t.Run("create without candidates", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		service, prRepo, userRepo, _ := setupTestService(WithNotifier(notifier))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).Return([]domain.User{}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(now, nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{},
+		}, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"no_candidate pr1 "}, notifier.calls)
+	})
+
+	t.Run("create deferred by blackout", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		blackouts := new(mocks.BlackoutRepository)
+		service, prRepo, userRepo, _ := setupTestService(
+			WithClock(clock.NewFake(now)), WithBlackouts(blackouts), WithNotifier(notifier))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		blackouts.On("IsInBlackout", mock.Anything, "team1", now).Return(true, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(now, nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen,
+			AssignedReviewers: []string{}, PendingAssignment: true,
+		}, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Empty(t, notifier.calls)
+	})
+
+	t.Run("failed create", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		service, prRepo, userRepo, _ := setupTestService(WithNotifier(notifier))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).
+			Return([]domain.User{{UserID: "u2", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(time.Time{}, errors.New("db error"))
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.Error(t, err)
+		assert.Empty(t, notifier.calls)
+	})
+
+	reassignMocks := func(prRepo *mocks.PullRequestRepository, userRepo *mocks.UserRepository, candidates []domain.User) {
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"r1"},
+		}, nil)
+		userRepo.On("GetByID", mock.Anything, "r1").Return(&domain.User{UserID: "r1", TeamName: "team1", IsActive: true}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "r1"}).Return(candidates, nil)
+	}
+
+	t.Run("reassign", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		service, prRepo, userRepo, _ := setupTestService(WithNotifier(notifier))
+		reassignMocks(prRepo, userRepo, []domain.User{{UserID: "r2", TeamName: "team1", IsActive: true}})
+		prRepo.On("RemoveReviewer", mock.Anything, "pr1", "r1").Return(true, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "r2", domain.AssignmentSourceReassignment).Return(nil)
+		prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil)
+
+		_, _, err := service.ReassignReviewer(context.Background(), "pr1", "r1")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"reassignment pr1 r1->r2"}, notifier.calls)
+	})
+
+	t.Run("reassign without candidates", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		service, prRepo, userRepo, _ := setupTestService(WithNotifier(notifier))
+		reassignMocks(prRepo, userRepo, []domain.User{})
+		userRepo.On("GetCandidateBreakdown", mock.Anything, "team1", []string{"author1", "r1"}, 0).
+			Return(domain.CandidateBreakdown{}, nil)
+
+		_, _, err := service.ReassignReviewer(context.Background(), "pr1", "r1")
+
+		require.ErrorIs(t, err, domain.ErrNoCandidate)
+		// отказ уже получил клиент, транзакция откатилась - уведомлять не о чем
+		assert.Empty(t, notifier.calls)
+	})
+
+	t.Run("pending assignment", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		service, prRepo, userRepo, _ := setupTestService(WithNotifier(notifier))
+
+		prRepo.On("GetPendingAssignmentPRs", mock.Anything).Return([]string{"pr1"}, nil)
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen,
+			AssignedReviewers: []string{"u2"}, PendingAssignment: true,
+		}, nil)
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "u2"}).
+			Return([]domain.User{{UserID: "u3", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u3", domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("ClearPendingAssignment", mock.Anything, "pr1").Return(nil)
+
+		_, err := service.AssignPendingReviewers(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"assignment pr1 [u3]"}, notifier.calls)
+	})
+}
+
+func TestPullRequestService_CreatePullRequest_SingleTransaction(t *testing.T) {
+	service, prRepo, userRepo, _ := setupTestService()
+	inTx := mock.MatchedBy(dbmocks.InTransaction)
+
+	userRepo.On("GetByID", mock.Anything, "author1").Return(&domain.User{UserID: "author1", TeamName: "team1", IsActive: true}, nil)
+	prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+	userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).
+		Return([]domain.User{{UserID: "u2", TeamName: "team1", IsActive: true}}, nil)
+	// создание PR, назначение ревьюеров и чтение результата - в одной транзакции, без окна между ними
+	prRepo.On("CreatePullRequest", inTx, mock.Anything).Return(time.Now(), nil).Once()
+	prRepo.On("AssignReviewer", inTx, "pr1", "u2", domain.AssignmentSourceAuto).Return(nil).Once()
+	prRepo.On("GetPullRequestByID", inTx, "pr1").Return(&domain.PullRequest{
+		PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2"},
+	}, nil).Once()
+
+	pr, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR 1", AuthorID: "author1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+	prRepo.AssertExpectations(t)
+}
+
+// capturingAudit запоминает записи аудита в порядке вызова
+type capturingAudit struct {
+	records []string
+}
+
+func (a *capturingAudit) RecordAssignment(_ context.Context, record domain.AuditRecord) error {
+	a.records = append(a.records, fmt.Sprintf("assigned %s %s %s", record.PullRequestID, record.UserID, record.Source))
+	return nil
+}
+
+func (a *capturingAudit) RecordRemoval(_ context.Context, record domain.AuditRecord) error {
+	a.records = append(a.records, fmt.Sprintf("removed %s %s", record.PullRequestID, record.UserID))
+	return nil
+}
+
+func TestPullRequestService_Audit(t *testing.T) {
+	now := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+	author := &domain.User{UserID: "author1", TeamName: "team1", IsActive: true}
+
+	t.Run("create records assignments", func(t *testing.T) {
+		sink := &capturingAudit{}
+		service, prRepo, userRepo, _ := setupTestService(WithAudit(sink))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).Return([]domain.User{
+			{UserID: "u2", TeamName: "team1", IsActive: true},
+			{UserID: "u3", TeamName: "team1", IsActive: true},
+		}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(now, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", mock.Anything, domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2", "u3"},
+		}, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{
+			PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"assigned pr1 u2 AUTO", "assigned pr1 u3 AUTO"}, sink.records)
+	})
+
+	t.Run("reassign records removal and assignment", func(t *testing.T) {
+		sink := &capturingAudit{}
+		service, prRepo, userRepo, _ := setupTestService(WithAudit(sink))
+
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"r1"},
+		}, nil)
+		userRepo.On("GetByID", mock.Anything, "r1").Return(&domain.User{UserID: "r1", TeamName: "team1", IsActive: true}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "r1"}).
+			Return([]domain.User{{UserID: "r2", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "pr1", "r1").Return(true, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "r2", domain.AssignmentSourceReassignment).Return(nil)
+		prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil)
+
+		_, _, err := service.ReassignReviewer(context.Background(), "pr1", "r1")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"removed pr1 r1", "assigned pr1 r2 REASSIGNMENT"}, sink.records)
+	})
+
+	t.Run("failed create records nothing", func(t *testing.T) {
+		sink := &capturingAudit{}
+		service, prRepo, userRepo, _ := setupTestService(WithAudit(sink))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).
+			Return([]domain.User{{UserID: "u2", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(time.Time{}, errors.New("db error"))
+
+		_, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{
+			PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1",
+		})
+
+		require.Error(t, err)
+		assert.Empty(t, sink.records)
+	})
+}
+
+func TestPullRequestService_SubscribeUpdates(t *testing.T) {
+	t.Run("reassign publishes removal and assignment", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithLiveUpdates(live.NewBroker()))
+
+		prRepo.On("Exists", mock.Anything, "pr1").Return(true, nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"r1"},
+		}, nil)
+		userRepo.On("GetByID", mock.Anything, "r1").Return(&domain.User{UserID: "r1", TeamName: "team1", IsActive: true}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "r1"}).
+			Return([]domain.User{{UserID: "r2", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "pr1", "r1").Return(true, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "r2", domain.AssignmentSourceReassignment).Return(nil)
+		prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil)
+
+		updates, cancel, err := service.SubscribeUpdates(context.Background(), "pr1")
+		require.NoError(t, err)
+		defer cancel()
+
+		_, _, err = service.ReassignReviewer(context.Background(), "pr1", "r1")
+		require.NoError(t, err)
+
+		removed, assigned := <-updates, <-updates
+		assert.Equal(t, domain.PRUpdateReviewerRemoved, removed.Type)
+		assert.Equal(t, "r1", removed.UserID)
+		assert.Equal(t, domain.PRUpdateReviewerAssigned, assigned.Type)
+		assert.Equal(t, "r2", assigned.UserID)
+		assert.Equal(t, domain.AssignmentSourceReassignment, assigned.Source)
+	})
+
+	t.Run("merge through update publishes merged", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService(WithLiveUpdates(live.NewBroker()))
+		merged := domain.PRStatusMerged
+
+		prRepo.On("Exists", mock.Anything, "pr1").Return(true, nil)
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen,
+		}, nil).Once()
+		prRepo.On("MergePullRequest", mock.Anything, "pr1", defaultReviewersPerPR, "").Return(true, nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusMerged,
+		}, nil).Once()
+
+		updates, cancel, err := service.SubscribeUpdates(context.Background(), "pr1")
+		require.NoError(t, err)
+		defer cancel()
+
+		_, err = service.UpdatePullRequest(context.Background(), domain.PullRequestUpdate{PullRequestID: "pr1", Status: &merged})
+		require.NoError(t, err)
+
+		update := <-updates
+		assert.Equal(t, domain.PRUpdateMerged, update.Type)
+		assert.Equal(t, "pr1", update.PullRequestID)
+	})
+
+	t.Run("unknown PR", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService(WithLiveUpdates(live.NewBroker()))
+		prRepo.On("Exists", mock.Anything, "missing").Return(false, nil)
+
+		_, _, err := service.SubscribeUpdates(context.Background(), "missing")
+
+		assert.ErrorIs(t, err, domain.ErrPRNotFound)
+	})
+}
+
+func TestPullRequestService_MandatoryReviewers(t *testing.T) {
+	author := &domain.User{UserID: "author1", TeamName: "team1", IsActive: true}
+	cfg := Config{MandatoryReviewers: map[string][]string{"security": {"sec1", "sec2"}}}
+
+	t.Run("security label takes a slot of max reviewers", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{MaxReviewers: 2, MandatoryReviewers: cfg.MandatoryReviewers}))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		userRepo.On("GetByID", mock.Anything, "sec1").Return(&domain.User{UserID: "sec1", TeamName: "security", IsActive: true}, nil)
+		userRepo.On("GetByID", mock.Anything, "sec2").Return(&domain.User{UserID: "sec2", TeamName: "security", IsActive: false}, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		// sec1 не выбирается повторно и оставляет автоматическому выбору одно место из двух
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "sec1"}).Return([]domain.User{
+			{UserID: "u2", TeamName: "team1", IsActive: true},
+			{UserID: "u3", TeamName: "team1", IsActive: true},
+		}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(time.Now(), nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", mock.Anything, domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2", "sec1"},
+		}, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{
+			PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1", Labels: []string{"security"},
+		})
+
+		require.NoError(t, err)
+		prRepo.AssertCalled(t, "AssignReviewer", mock.Anything, "pr1", "sec1", domain.AssignmentSourceAuto)
+		prRepo.AssertNumberOfCalls(t, "AssignReviewer", 2)
+		// неактивный обязательный ревьюер пропускается
+		prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, "pr1", "sec2", mock.Anything)
+	})
+
+	t.Run("mandatory reviewers fill the cap", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{
+			MaxReviewers: 2, MandatoryReviewers: cfg.MandatoryReviewers, NoReviewersPolicy: NoReviewersFail,
+		}))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		userRepo.On("GetByID", mock.Anything, "sec1").Return(&domain.User{UserID: "sec1", TeamName: "security", IsActive: true}, nil)
+		userRepo.On("GetByID", mock.Anything, "sec2").Return(&domain.User{UserID: "sec2", TeamName: "security", IsActive: true}, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(time.Now(), nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", mock.Anything, domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"sec1", "sec2"},
+		}, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{
+			PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1", Labels: []string{"security"},
+		})
+
+		// мест для автоматического выбора нет, и это не повод для NO_CANDIDATE
+		require.NoError(t, err)
+		userRepo.AssertNotCalled(t, "GetActiveByTeam", mock.Anything, mock.Anything, mock.Anything)
+		prRepo.AssertNumberOfCalls(t, "AssignReviewer", 2)
+	})
+
+	// пустой пул кандидатов при назначенном обязательном ревьюере - не случай NoReviewersPolicy
+	for _, policy := range []NoReviewersPolicy{NoReviewersFail, NoReviewersAssignAuthor} {
+		t.Run("empty pool with mandatory reviewer under "+string(policy)+" policy", func(t *testing.T) {
+			service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{
+				MandatoryReviewers: cfg.MandatoryReviewers, NoReviewersPolicy: policy,
+			}))
+
+			userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+			userRepo.On("GetByID", mock.Anything, "sec1").Return(&domain.User{UserID: "sec1", TeamName: "security", IsActive: true}, nil)
+			userRepo.On("GetByID", mock.Anything, "sec2").Return(&domain.User{UserID: "sec2", TeamName: "security", IsActive: false}, nil)
+			userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "sec1"}).Return([]domain.User{}, nil)
+			prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+			prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(time.Now(), nil)
+			prRepo.On("AssignReviewer", mock.Anything, "pr1", "sec1", domain.AssignmentSourceAuto).Return(nil).Once()
+			prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+				PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"sec1"},
+			}, nil)
+
+			pr, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{
+				PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1", Labels: []string{"security"},
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{"sec1"}, pr.AssignedReviewers)
+			prRepo.AssertNumberOfCalls(t, "AssignReviewer", 1)
+			prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, "pr1", "author1", mock.Anything)
+		})
+	}
+
+	t.Run("mandatory reviewers above the cap are rejected", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{MaxReviewers: 1, MandatoryReviewers: cfg.MandatoryReviewers}))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		userRepo.On("GetByID", mock.Anything, "sec1").Return(&domain.User{UserID: "sec1", TeamName: "security", IsActive: true}, nil)
+		userRepo.On("GetByID", mock.Anything, "sec2").Return(&domain.User{UserID: "sec2", TeamName: "security", IsActive: true}, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{
+			PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1", Labels: []string{"security"},
+		})
+
+		require.ErrorIs(t, err, domain.ErrReviewerCapReached)
+		var conflict *domain.ConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, &domain.ReviewerCapLimit{Limit: 1, Reviewers: 2}, conflict.Payload)
+		prRepo.AssertNotCalled(t, "CreatePullRequest", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unlabeled PR gets no mandatory reviewers", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(cfg))
+
+		userRepo.On("GetByID", mock.Anything, "author1").Return(author, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).
+			Return([]domain.User{{UserID: "u2", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(time.Now(), nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u2", domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2"},
+		}, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{
+			PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1",
+		})
+
+		require.NoError(t, err)
+		userRepo.AssertNotCalled(t, "GetByID", mock.Anything, "sec1")
+	})
+}
+
+func TestPullRequestService_LogsTransactionID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(logctx.NewHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	prRepo := new(mocks.PullRequestRepository)
+	userRepo := new(mocks.UserRepository)
+	service := NewPullRequestService(prRepo, userRepo, dbmocks.NewMockTransactionManager(), logger)
+
+	userRepo.On("GetByID", mock.Anything, "author1").Return(&domain.User{UserID: "author1", TeamName: "team1", IsActive: true}, nil)
+	prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+	userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).
+		Return([]domain.User{{UserID: "u2", TeamName: "team1", IsActive: true}}, nil)
+	prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(time.Now(), nil)
+	prRepo.On("AssignReviewer", mock.Anything, "pr1", "u2", domain.AssignmentSourceAuto).Return(nil)
+	prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+		PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2"},
+	}, nil)
+
+	ctx := logctx.WithRequestID(context.Background(), "req-1")
+	_, err := service.CreatePullRequest(ctx, domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1"})
+	require.NoError(t, err)
+
+	records := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records[record["msg"].(string)] = record
+	}
+
+	found, selected, created := records["found candidates"], records["selected reviewers"], records["new PR created"]
+	require.NotNil(t, found)
+	require.NotNil(t, selected)
+	require.NotNil(t, created)
+
+	// записи внутри транзакции делят один tx_id, после коммита остаётся только request_id
+	assert.NotEmpty(t, found["tx_id"])
+	assert.Equal(t, found["tx_id"], selected["tx_id"])
+	assert.NotContains(t, created, "tx_id")
+	for _, record := range []map[string]any{found, selected, created} {
+		assert.Equal(t, "req-1", record["request_id"])
+		assert.Equal(t, "PullRequestService.CreatePullRequest", record["op"])
+	}
+}
+
+func TestPullRequestService_SnoozeReview(t *testing.T) {
+	now := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+	openPR := &domain.PullRequest{
+		PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"reviewer1"},
+	}
+
+	t.Run("until must be after now", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService(WithClock(clock.NewFake(now)))
+
+		_, err := service.SnoozeReview(context.Background(), "pr1", "reviewer1", now)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+		prRepo.AssertNotCalled(t, "SnoozeReview", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("just after now is accepted", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService(WithClock(clock.NewFake(now)))
+		until := now.Add(time.Nanosecond)
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(openPR, nil)
+		prRepo.On("SnoozeReview", mock.Anything, "pr1", "reviewer1", until).Return(nil).Once()
+
+		snooze, err := service.SnoozeReview(context.Background(), "pr1", "reviewer1", until)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReviewSnooze{PullRequestID: "pr1", UserID: "reviewer1", Until: until}, *snooze)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("merged PR", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService(WithClock(clock.NewFake(now)))
+		merged := *openPR
+		merged.Status = domain.PRStatusMerged
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&merged, nil)
+
+		_, err := service.SnoozeReview(context.Background(), "pr1", "reviewer1", now.Add(time.Hour))
+
+		assert.ErrorIs(t, err, domain.ErrPRMerged)
+		prRepo.AssertNotCalled(t, "SnoozeReview", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reviewer not assigned", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService(WithClock(clock.NewFake(now)))
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(openPR, nil)
+
+		_, err := service.SnoozeReview(context.Background(), "pr1", "author1", now.Add(time.Hour))
+
+		assert.ErrorIs(t, err, domain.ErrNotAssigned)
+	})
+
+	t.Run("unknown PR", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService(WithClock(clock.NewFake(now)))
+		prRepo.On("LockPullRequest", mock.Anything, "missing").Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "missing").Return(nil, repository.ErrNotFound)
+
+		_, err := service.SnoozeReview(context.Background(), "missing", "reviewer1", now.Add(time.Hour))
+
+		assert.ErrorIs(t, err, domain.ErrPRNotFound)
+	})
+}
+
+func TestPullRequestService_GetRecentPullRequests(t *testing.T) {
+	t.Run("passes limit to repository", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+		recent := []domain.RecentPullRequest{{PullRequestShort: domain.PullRequestShort{PullRequestID: "pr2"}, ReviewerCount: 1}}
+		prRepo.On("GetRecentPullRequests", mock.Anything, 5).Return(recent, nil)
+
+		prs, err := service.GetRecentPullRequests(context.Background(), 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, recent, prs)
+	})
+
+	t.Run("non-positive limit", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+
+		_, err := service.GetRecentPullRequests(context.Background(), 0)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+		prRepo.AssertNotCalled(t, "GetRecentPullRequests", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPullRequestService_CreatePullRequest_StrategyOverride(t *testing.T) {
+	// понедельник: u2 в рабочем окне, но занят, у u3 вне окна открытых ревью нет
+	now := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+	candidates := []domain.User{
+		{UserID: "u2", TeamName: "team1", IsActive: true, WorkingHours: "Mon-Fri 09:00-18:00", Timezone: "UTC"},
+		{UserID: "u3", TeamName: "team1", IsActive: true, WorkingHours: "Sat-Sun 10:00-18:00", Timezone: "UTC"},
+	}
+
+	tests := []struct {
+		strategy domain.SelectionStrategy
+		want     string
+	}{
+		{strategy: "", want: "u2"},
+		{strategy: domain.SelectionStrategyWorkingHours, want: "u2"},
+		{strategy: domain.SelectionStrategyLeastLoaded, want: "u3"},
+	}
+
+	for _, tt := range tests {
+		t.Run("strategy "+string(tt.strategy), func(t *testing.T) {
+			service, prRepo, userRepo, _ := setupTestService(
+				WithClock(clock.NewFake(now)),
+				WithConfig(Config{ExcludeOutsideWorkingHours: true, MaxReviewers: 1}),
+			)
+			userRepo.On("GetByID", mock.Anything, "author1").Return(&domain.User{UserID: "author1", TeamName: "team1", IsActive: true}, nil)
+			userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1"}).Return(candidates, nil)
+			prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+			prRepo.On("GetOpenReviewCounts", mock.Anything, []string{"u2", "u3"}).Return(map[string]int{"u2": 5}, nil).Maybe()
+			prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(now, nil)
+			prRepo.On("AssignReviewer", mock.Anything, "pr1", tt.want, domain.AssignmentSourceAuto).Return(nil)
+			prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+				PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{tt.want},
+			}, nil)
+
+			pr, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{
+				PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1", Strategy: tt.strategy,
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.want}, pr.AssignedReviewers)
+			prRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+
+		_, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{
+			PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "author1", Strategy: "round_robin",
+		})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+		userRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+		prRepo.AssertNotCalled(t, "CreatePullRequest", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPullRequestService_CreatePullRequest_AutoCreateAuthor(t *testing.T) {
+	prCreate := domain.PullRequestCreate{PullRequestID: "pr1", PullRequestName: "PR1", AuthorID: "newcomer"}
+	placeholder := domain.TeamMember{UserID: "newcomer", Username: "newcomer", IsActive: true}
+	autoCreate := WithConfig(Config{AutoCreateAuthor: true, DefaultTeam: "general"})
+	inTx := mock.MatchedBy(dbmocks.InTransaction)
+
+	t.Run("disabled rejects unknown author", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "newcomer").Return(nil, repository.ErrNotFound)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.ErrorIs(t, err, domain.ErrUserNotFound)
+		userRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+		prRepo.AssertNotCalled(t, "CreatePullRequest", mock.Anything, mock.Anything)
+	})
+
+	t.Run("enabled creates placeholder in default team", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(autoCreate)
+		userRepo.On("GetByID", mock.Anything, "newcomer").Return(nil, repository.ErrNotFound)
+		userRepo.On("Create", inTx, placeholder, "general").
+			Return(&domain.User{UserID: "newcomer", Username: "newcomer", TeamName: "general", IsActive: true}, nil).Once()
+		userRepo.On("GetActiveByTeam", mock.Anything, "general", []string{"newcomer"}).
+			Return([]domain.User{{UserID: "u2", TeamName: "general", IsActive: true}}, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		prRepo.On("CreatePullRequest", mock.Anything, mock.Anything).Return(time.Now(), nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "u2", domain.AssignmentSourceAuto).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "newcomer", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2"},
+		}, nil)
+
+		pr, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("lookup error is not an unknown author", func(t *testing.T) {
+		service, _, userRepo, _ := setupTestService(autoCreate)
+		userRepo.On("GetByID", mock.Anything, "newcomer").Return(nil, errors.New("db down"))
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.ErrorContains(t, err, "failed to get author")
+		userRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed create leaves no placeholder behind", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{
+			AutoCreateAuthor: true, DefaultTeam: "general", NoReviewersPolicy: NoReviewersFail,
+		}))
+		userRepo.On("GetByID", mock.Anything, "newcomer").Return(nil, repository.ErrNotFound)
+		userRepo.On("Create", inTx, placeholder, "general").
+			Return(&domain.User{UserID: "newcomer", Username: "newcomer", TeamName: "general", IsActive: true}, nil).Once()
+		userRepo.On("GetActiveByTeam", inTx, "general", []string{"newcomer"}).Return([]domain.User{}, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		// автор создан внутри транзакции, и отказ откатывает его вместе с PR
+		require.ErrorIs(t, err, domain.ErrNoCandidate)
+		userRepo.AssertExpectations(t)
+		prRepo.AssertNotCalled(t, "CreatePullRequest", mock.Anything, mock.Anything)
+	})
+
+	t.Run("existing PR does not create placeholder", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(autoCreate)
+		userRepo.On("GetByID", mock.Anything, "newcomer").Return(nil, repository.ErrNotFound)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(true, nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{PullRequestID: "pr1"}, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.ErrorIs(t, err, domain.ErrPRExists)
+		userRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("full default team rejects placeholder", func(t *testing.T) {
+		teams := new(mocks.TeamRepository)
+		service, prRepo, userRepo, _ := setupTestService(WithTeams(teams), WithConfig(Config{
+			AutoCreateAuthor: true, DefaultTeam: "general", MaxTeamMembers: 3,
+		}))
+		userRepo.On("GetByID", mock.Anything, "newcomer").Return(nil, repository.ErrNotFound)
+		teams.On("CountMembers", inTx, "general").Return(3, nil)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.ErrorIs(t, err, domain.ErrTeamTooLarge)
+		var conflict *domain.ConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, &domain.TeamSizeLimit{Limit: 3, Size: 4}, conflict.Payload)
+		userRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+		prRepo.AssertNotCalled(t, "CreatePullRequest", mock.Anything, mock.Anything)
+	})
+
+	t.Run("concurrently created author is re-read", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(autoCreate)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		userRepo.On("GetByID", mock.Anything, "newcomer").Return(nil, repository.ErrNotFound).Once()
+		userRepo.On("Create", mock.Anything, placeholder, "general").Return(nil, repository.ErrAlreadyExists)
+		userRepo.On("GetByID", mock.Anything, "newcomer").
+			Return(&domain.User{UserID: "newcomer", TeamName: "backend", IsActive: false}, nil).Once()
+
+		_, err := service.CreatePullRequest(auth.WithScope(context.Background(), auth.Scope{Team: "general"}), prCreate)
+
+		// автор оказался в другой команде, и ключ general создать PR от его имени не может
+		require.ErrorIs(t, err, domain.ErrForbidden)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("missing default team", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(autoCreate)
+		prRepo.On("Exists", mock.Anything, "pr1").Return(false, nil)
+		userRepo.On("GetByID", mock.Anything, "newcomer").Return(nil, repository.ErrNotFound)
+		userRepo.On("Create", mock.Anything, placeholder, "general").Return(nil, repository.ErrReferenceNotFound)
+
+		_, err := service.CreatePullRequest(context.Background(), prCreate)
+
+		require.ErrorIs(t, err, domain.ErrAuthorTeamMissing)
+	})
+
+	t.Run("key of another team cannot create placeholder", func(t *testing.T) {
+		service, _, userRepo, _ := setupTestService(autoCreate)
+		userRepo.On("GetByID", mock.Anything, "newcomer").Return(nil, repository.ErrNotFound)
+
+		_, err := service.CreatePullRequest(auth.WithScope(context.Background(), auth.Scope{Team: "backend"}), prCreate)
+
+		require.ErrorIs(t, err, domain.ErrForbidden)
+		userRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPullRequestService_CreatePullRequest_ValidatesInput(t *testing.T) {
+	// сервис проверяет вход сам, без HTTP-валидатора перед ним
+	service, prRepo, userRepo, _ := setupTestService()
+
+	_, err := service.CreatePullRequest(context.Background(), domain.PullRequestCreate{
+		PullRequestID: "pr 1", AuthorID: "author1", Labels: []string{""},
+	})
+
+	var verr *domain.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	assert.Equal(t, []domain.FieldViolation{
+		{Field: "pull_request_id", Rule: domain.RuleIdentifier},
+		{Field: "pull_request_name", Rule: domain.RuleRequired},
+		{Field: "labels[0]", Rule: domain.RuleRequired},
+	}, verr.Violations)
+	userRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	prRepo.AssertNotCalled(t, "Exists", mock.Anything, mock.Anything)
+}
+
+func TestPullRequestService_ReassignReviewers(t *testing.T) {
+	newPR := func() *domain.PullRequest {
+		return &domain.PullRequest{
+			PullRequestID: "pr1", AuthorID: "author", Status: domain.PRStatusOpen, AssignedReviewers: []string{"r1", "r2"},
+		}
+	}
+	setup := func(t *testing.T, policy PartialReassignPolicy) (*PullRequestService, *mocks.PullRequestRepository, *mocks.UserRepository) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{PartialReassignPolicy: policy}))
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(newPR(), nil)
+		userRepo.On("GetByID", mock.Anything, "r1").Return(&domain.User{UserID: "r1", TeamName: "backend", IsActive: true}, nil)
+		userRepo.On("GetByID", mock.Anything, "r2").Return(&domain.User{UserID: "r2", TeamName: "frontend", IsActive: true}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "backend", []string{"author", "r1", "r2"}).
+			Return([]domain.User{{UserID: "c1", TeamName: "backend", IsActive: true}}, nil)
+		return service, prRepo, userRepo
+	}
+
+	t.Run("replacement already picked is excluded for the next one", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService()
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(newPR(), nil)
+		userRepo.On("GetByID", mock.Anything, "r1").Return(&domain.User{UserID: "r1", TeamName: "backend", IsActive: true}, nil)
+		userRepo.On("GetByID", mock.Anything, "r2").Return(&domain.User{UserID: "r2", TeamName: "backend", IsActive: true}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "backend", []string{"author", "r1", "r2"}).
+			Return([]domain.User{{UserID: "c1", TeamName: "backend", IsActive: true}}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "backend", []string{"author", "r1", "r2", "c1"}).
+			Return([]domain.User{{UserID: "c2", TeamName: "backend", IsActive: true}}, nil)
+		for _, pair := range [][2]string{{"r1", "c1"}, {"r2", "c2"}} {
+			prRepo.On("RemoveReviewer", mock.Anything, "pr1", pair[0]).Return(true, nil).Once()
+			prRepo.On("AssignReviewer", mock.Anything, "pr1", pair[1], domain.AssignmentSourceReassignment).Return(nil).Once()
+		}
+		prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil).Twice()
+
+		_, result, err := service.ReassignReviewers(context.Background(), "pr1", []string{"r1", "r2"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.ReviewerReplacement{
+			{PullRequestID: "pr1", OldUserID: "r1", NewUserID: "c1"},
+			{PullRequestID: "pr1", OldUserID: "r2", NewUserID: "c2"},
+		}, result.Replacements)
+		assert.Empty(t, result.Unreplaced)
+		prRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("fail policy rejects the whole call", func(t *testing.T) {
+		service, prRepo, userRepo := setup(t, "")
+		userRepo.On("GetActiveByTeam", mock.Anything, "frontend", []string{"author", "r1", "r2", "c1"}).Return([]domain.User{}, nil)
+		userRepo.On("GetCandidateBreakdown", mock.Anything, "frontend", []string{"author", "r1", "r2", "c1"}, 0).
+			Return(domain.CandidateBreakdown{TeamMembers: 1, Active: 1, Excluded: 1}, nil)
+
+		_, _, err := service.ReassignReviewers(context.Background(), "pr1", []string{"r1", "r2"})
+
+		require.ErrorIs(t, err, domain.ErrNoCandidate)
+		var conflict *domain.ConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, &domain.CandidateBreakdown{TeamMembers: 1, Active: 1, Excluded: 1}, conflict.Payload)
+		prRepo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fill policy replaces who it can", func(t *testing.T) {
+		service, prRepo, userRepo := setup(t, PartialReassignFill)
+		userRepo.On("GetActiveByTeam", mock.Anything, "frontend", []string{"author", "r1", "r2", "c1"}).Return([]domain.User{}, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "pr1", "r1").Return(true, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "pr1", "c1", domain.AssignmentSourceReassignment).Return(nil)
+		prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil).Once()
+
+		_, result, err := service.ReassignReviewers(context.Background(), "pr1", []string{"r1", "r2"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.ReviewerReplacement{{PullRequestID: "pr1", OldUserID: "r1", NewUserID: "c1"}}, result.Replacements)
+		assert.Equal(t, []string{"r2"}, result.Unreplaced)
+		prRepo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, "pr1", "r2")
+		userRepo.AssertNotCalled(t, "GetCandidateBreakdown", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("fill policy without any replacement", func(t *testing.T) {
+		service, prRepo, userRepo, _ := setupTestService(WithConfig(Config{PartialReassignPolicy: PartialReassignFill}))
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(newPR(), nil)
+		userRepo.On("GetByID", mock.Anything, "r2").Return(&domain.User{UserID: "r2", TeamName: "frontend", IsActive: true}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "frontend", []string{"author", "r1", "r2"}).Return([]domain.User{}, nil)
+		userRepo.On("GetCandidateBreakdown", mock.Anything, "frontend", []string{"author", "r1", "r2"}, 0).
+			Return(domain.CandidateBreakdown{}, nil)
+
+		_, _, err := service.ReassignReviewers(context.Background(), "pr1", []string{"r2"})
+
+		require.ErrorIs(t, err, domain.ErrNoCandidate)
+		prRepo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("every reviewer must be assigned", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService()
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(newPR(), nil)
+
+		_, _, err := service.ReassignReviewers(context.Background(), "pr1", []string{"r1", "r9"})
+
+		require.ErrorIs(t, err, domain.ErrNotAssigned)
+	})
+
+	t.Run("rate limit counts every replacement", func(t *testing.T) {
+		now := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+		service, prRepo, _, _ := setupTestService(WithClock(clock.NewFake(now)), WithConfig(Config{MaxReassignmentsPerHour: 2}))
+		prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(newPR(), nil)
+		prRepo.On("LockPullRequest", mock.Anything, "pr1").Return(nil)
+		prRepo.On("GetReviewerRemovalsSince", mock.Anything, "pr1", now.Add(-time.Hour)).
+			Return([]time.Time{now.Add(-20 * time.Minute)}, nil)
+
+		_, _, err := service.ReassignReviewers(context.Background(), "pr1", []string{"r1", "r2"})
+
+		require.ErrorIs(t, err, domain.ErrReassignRateExceeded)
+		var conflict *domain.ConflictError
+		require.ErrorAs(t, err, &conflict)
+		// одна замена уложилась бы в лимит, две - только когда снятие в -20m выйдет из окна
+		assert.Equal(t, &domain.ReassignRateLimit{Limit: 2, RetryAt: now.Add(40 * time.Minute), RetryAfter: 40 * time.Minute}, conflict.Payload)
+	})
+}
+
+func TestPullRequestService_DetectAnomalies(t *testing.T) {
+	// каждому подтесту свой отчёт: сервис фильтрует срезы на месте
+	newReport := func() domain.AnomalyReport {
+		return domain.AnomalyReport{
+			AuthorIsReviewer: []domain.ReviewerAnomaly{{PullRequestID: "pr1", UserID: "u1", AuthorTeam: "backend", ReviewerTeam: "backend"}},
+			CrossTeamReviewer: []domain.ReviewerAnomaly{
+				{PullRequestID: "pr2", UserID: "u3", AuthorTeam: "backend", ReviewerTeam: "frontend"},
+				{PullRequestID: "pr2", UserID: "sec-alice", AuthorTeam: "backend", ReviewerTeam: "security"},
+			},
+			UnknownReviewer: []domain.ReviewerAnomaly{{PullRequestID: "pr3", UserID: "ghost", AuthorTeam: "backend"}},
+		}
+	}
+
+	t.Run("mandatory reviewers are not cross-team anomalies", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService(WithConfig(Config{
+			MandatoryReviewers: map[string][]string{"security": {"sec-alice"}},
+		}))
+		found := newReport()
+		prRepo.On("GetReviewerAnomalies", mock.Anything).Return(newReport(), nil)
+
+		report, err := service.DetectAnomalies(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, found.AuthorIsReviewer, report.AuthorIsReviewer)
+		assert.Equal(t, found.CrossTeamReviewer[:1], report.CrossTeamReviewer)
+		assert.Equal(t, found.UnknownReviewer, report.UnknownReviewer)
+		assert.Empty(t, report.Skipped)
+	})
+
+	t.Run("categories allowed by config are skipped", func(t *testing.T) {
+		service, prRepo, _, _ := setupTestService(WithConfig(Config{
+			AllowCrossTeamReviewers: true,
+			NoReviewersPolicy:       NoReviewersAssignAuthor,
+		}))
+		found := newReport()
+		prRepo.On("GetReviewerAnomalies", mock.Anything).Return(newReport(), nil)
+
+		report, err := service.DetectAnomalies(context.Background())
+
+		require.NoError(t, err)
+		assert.Empty(t, report.AuthorIsReviewer)
+		assert.Empty(t, report.CrossTeamReviewer)
+		assert.Equal(t, found.UnknownReviewer, report.UnknownReviewer)
+		assert.Equal(t, []domain.AnomalyKind{domain.AnomalyAuthorIsReviewer, domain.AnomalyCrossTeamReviewer}, report.Skipped)
+	})
+}
@@ -0,0 +1,91 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "avito_backend_task/internal/domain"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// StatsRepository is an autogenerated mock type for the StatsRepository type
+type StatsRepository struct {
+	mock.Mock
+}
+
+// CountPullRequestsByAuthor provides a mock function with given fields: ctx, teamName, since, order, limit
+func (_m *StatsRepository) CountPullRequestsByAuthor(ctx context.Context, teamName string, since *time.Time, order domain.AuthorPRCountOrder, limit int) ([]domain.AuthorPRCount, error) {
+	ret := _m.Called(ctx, teamName, since, order, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountPullRequestsByAuthor")
+	}
+
+	var r0 []domain.AuthorPRCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time, domain.AuthorPRCountOrder, int) ([]domain.AuthorPRCount, error)); ok {
+		return rf(ctx, teamName, since, order, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time, domain.AuthorPRCountOrder, int) []domain.AuthorPRCount); ok {
+		r0 = rf(ctx, teamName, since, order, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuthorPRCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *time.Time, domain.AuthorPRCountOrder, int) error); ok {
+		r1 = rf(ctx, teamName, since, order, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGlobalStats provides a mock function with given fields: ctx
+func (_m *StatsRepository) GetGlobalStats(ctx context.Context) (*domain.GlobalStats, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGlobalStats")
+	}
+
+	var r0 *domain.GlobalStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*domain.GlobalStats, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *domain.GlobalStats); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.GlobalStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewStatsRepository creates a new instance of StatsRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStatsRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *StatsRepository {
+	mock := &StatsRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,121 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"avito_backend_task/internal/auth"
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/pkg/clock"
+)
+
+//go:generate mockery --name=StatsRepository --output=./mocks --case=underscore
+type StatsRepository interface {
+	GetGlobalStats(ctx context.Context) (*domain.GlobalStats, error)
+	CountPullRequestsByAuthor(
+		ctx context.Context,
+		teamName string,
+		since *time.Time,
+		order domain.AuthorPRCountOrder,
+		limit int,
+	) ([]domain.AuthorPRCount, error)
+}
+
+// defaultCacheTTL - сколько отдаётся закэшированная сводка, если WithCacheTTL не задан
+const defaultCacheTTL = 5 * time.Second
+
+type Option func(*StatsService)
+
+// WithCacheTTL задаёт время жизни кэша сводки, 0 отключает кэш
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(s *StatsService) {
+		s.ttl = ttl
+	}
+}
+
+func WithClock(c clock.Clock) Option {
+	return func(s *StatsService) {
+		s.clock = c
+	}
+}
+
+type StatsService struct {
+	repo  StatsRepository
+	lg    *slog.Logger
+	ttl   time.Duration
+	clock clock.Clock
+
+	// mu держится и на время запроса в БД, чтобы одновременные промахи кэша не дублировали агрегаты
+	mu        sync.Mutex
+	cached    *domain.GlobalStats
+	expiresAt time.Time
+}
+
+func NewStatsService(repo StatsRepository, lg *slog.Logger, opts ...Option) *StatsService {
+	s := &StatsService{
+		repo:  repo,
+		lg:    lg,
+		ttl:   defaultCacheTTL,
+		clock: clock.New(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// GetGlobalStats возвращает сводку не старше ttl; ошибка БД не сбрасывает кэш, но и не продлевает его
+func (s *StatsService) GetGlobalStats(ctx context.Context) (*domain.GlobalStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if s.cached != nil && now.Before(s.expiresAt) {
+		stats := *s.cached
+		return &stats, nil
+	}
+
+	fresh, err := s.repo.GetGlobalStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get global stats: %w", err)
+	}
+
+	if s.ttl > 0 {
+		s.cached = fresh
+		s.expiresAt = now.Add(s.ttl)
+	}
+	s.lg.DebugContext(ctx, "global stats refreshed", slog.Int("open_prs", fresh.OpenPRs))
+
+	stats := *fresh
+	return &stats, nil
+}
+
+// CountPullRequestsByAuthor - top-N авторов команды по числу PR, не кэшируется: запрос узкий и параметризован
+func (s *StatsService) CountPullRequestsByAuthor(
+	ctx context.Context,
+	teamName string,
+	since *time.Time,
+	order domain.AuthorPRCountOrder,
+	limit int,
+) ([]domain.AuthorPRCount, error) {
+	if err := auth.AuthorizeTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+	if order == "" {
+		order = domain.AuthorPRCountOrderCount
+	}
+	if !order.Valid() || limit <= 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	counts, err := s.repo.CountPullRequestsByAuthor(ctx, teamName, since, order, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pull requests by author: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,116 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/auth"
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/service/stats/mocks"
+	"avito_backend_task/pkg/clock"
+)
+
+func setupTestService(opts ...Option) (*StatsService, *mocks.StatsRepository, *clock.Fake) {
+	repo := new(mocks.StatsRepository)
+	fake := clock.NewFake(time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC))
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	opts = append([]Option{WithClock(fake), WithCacheTTL(10 * time.Second)}, opts...)
+	return NewStatsService(repo, logger, opts...), repo, fake
+}
+
+func TestStatsService_GetGlobalStats(t *testing.T) {
+	t.Run("cached within ttl", func(t *testing.T) {
+		service, repo, fake := setupTestService()
+		repo.On("GetGlobalStats", mock.Anything).Return(&domain.GlobalStats{OpenPRs: 3}, nil).Once()
+
+		first, err := service.GetGlobalStats(context.Background())
+		require.NoError(t, err)
+		fake.Advance(9 * time.Second)
+		second, err := service.GetGlobalStats(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, first, second)
+		repo.AssertNumberOfCalls(t, "GetGlobalStats", 1)
+	})
+
+	t.Run("refreshed after ttl", func(t *testing.T) {
+		service, repo, fake := setupTestService()
+		repo.On("GetGlobalStats", mock.Anything).Return(&domain.GlobalStats{OpenPRs: 3}, nil).Once()
+		repo.On("GetGlobalStats", mock.Anything).Return(&domain.GlobalStats{OpenPRs: 4}, nil).Once()
+
+		_, err := service.GetGlobalStats(context.Background())
+		require.NoError(t, err)
+		fake.Advance(10 * time.Second)
+		stats, err := service.GetGlobalStats(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 4, stats.OpenPRs)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error is not cached", func(t *testing.T) {
+		service, repo, _ := setupTestService()
+		repo.On("GetGlobalStats", mock.Anything).Return(nil, errors.New("db down")).Once()
+		repo.On("GetGlobalStats", mock.Anything).Return(&domain.GlobalStats{Teams: 1}, nil).Once()
+
+		_, err := service.GetGlobalStats(context.Background())
+		require.Error(t, err)
+		stats, err := service.GetGlobalStats(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, stats.Teams)
+	})
+
+	t.Run("zero ttl disables cache", func(t *testing.T) {
+		service, repo, _ := setupTestService(WithCacheTTL(0))
+		repo.On("GetGlobalStats", mock.Anything).Return(&domain.GlobalStats{}, nil)
+
+		for i := 0; i < 3; i++ {
+			_, err := service.GetGlobalStats(context.Background())
+			require.NoError(t, err)
+		}
+
+		repo.AssertNumberOfCalls(t, "GetGlobalStats", 3)
+	})
+}
+
+func TestStatsService_CountPullRequestsByAuthor(t *testing.T) {
+	t.Run("default order", func(t *testing.T) {
+		service, repo, _ := setupTestService()
+		expected := []domain.AuthorPRCount{{UserID: "u1", Username: "alice", Count: 4}}
+		repo.On("CountPullRequestsByAuthor", mock.Anything, "backend", (*time.Time)(nil), domain.AuthorPRCountOrderCount, 10).
+			Return(expected, nil).Once()
+
+		counts, err := service.CountPullRequestsByAuthor(context.Background(), "backend", nil, "", 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, counts)
+	})
+
+	t.Run("other team key", func(t *testing.T) {
+		service, repo, _ := setupTestService()
+		ctx := auth.WithScope(context.Background(), auth.Scope{Team: "frontend"})
+
+		_, err := service.CountPullRequestsByAuthor(ctx, "backend", nil, domain.AuthorPRCountOrderCount, 10)
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		repo.AssertNotCalled(t, "CountPullRequestsByAuthor")
+	})
+
+	t.Run("unknown order", func(t *testing.T) {
+		service, _, _ := setupTestService()
+
+		_, err := service.CountPullRequestsByAuthor(context.Background(), "backend", nil, "age", 10)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidInput)
+	})
+}
@@ -7,6 +7,8 @@ import (
 	context "context"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // TeamRepository is an autogenerated mock type for the TeamRepository type
@@ -32,6 +34,132 @@ func (_m *TeamRepository) Create(ctx context.Context, teamName string) error {
 	return r0
 }
 
+// CreateBlackout provides a mock function with given fields: ctx, blackout
+func (_m *TeamRepository) CreateBlackout(ctx context.Context, blackout domain.TeamBlackout) (*domain.TeamBlackout, error) {
+	ret := _m.Called(ctx, blackout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateBlackout")
+	}
+
+	var r0 *domain.TeamBlackout
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TeamBlackout) (*domain.TeamBlackout, error)); ok {
+		return rf(ctx, blackout)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TeamBlackout) *domain.TeamBlackout); ok {
+		r0 = rf(ctx, blackout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TeamBlackout)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.TeamBlackout) error); ok {
+		r1 = rf(ctx, blackout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateMissingTeams provides a mock function with given fields: ctx, teamNames
+func (_m *TeamRepository) CreateMissingTeams(ctx context.Context, teamNames []string) ([]string, error) {
+	ret := _m.Called(ctx, teamNames)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMissingTeams")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]string, error)); ok {
+		return rf(ctx, teamNames)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []string); ok {
+		r0 = rf(ctx, teamNames)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, teamNames)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateWebhook provides a mock function with given fields: ctx, webhook
+func (_m *TeamRepository) CreateWebhook(ctx context.Context, webhook domain.TeamWebhook) (*domain.TeamWebhook, error) {
+	ret := _m.Called(ctx, webhook)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWebhook")
+	}
+
+	var r0 *domain.TeamWebhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TeamWebhook) (*domain.TeamWebhook, error)); ok {
+		return rf(ctx, webhook)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TeamWebhook) *domain.TeamWebhook); ok {
+		r0 = rf(ctx, webhook)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TeamWebhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.TeamWebhook) error); ok {
+		r1 = rf(ctx, webhook)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteBlackout provides a mock function with given fields: ctx, teamName, blackoutID
+func (_m *TeamRepository) DeleteBlackout(ctx context.Context, teamName string, blackoutID int64) error {
+	ret := _m.Called(ctx, teamName, blackoutID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteBlackout")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) error); ok {
+		r0 = rf(ctx, teamName, blackoutID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteWebhook provides a mock function with given fields: ctx, teamName
+func (_m *TeamRepository) DeleteWebhook(ctx context.Context, teamName string) error {
+	ret := _m.Called(ctx, teamName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebhook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, teamName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Exists provides a mock function with given fields: ctx, teamName
 func (_m *TeamRepository) Exists(ctx context.Context, teamName string) (bool, error) {
 	ret := _m.Called(ctx, teamName)
@@ -60,6 +188,36 @@ func (_m *TeamRepository) Exists(ctx context.Context, teamName string) (bool, er
 	return r0, r1
 }
 
+// GetOpenPRAgeReport provides a mock function with given fields: ctx, teamName, now
+func (_m *TeamRepository) GetOpenPRAgeReport(ctx context.Context, teamName string, now time.Time) (*domain.TeamAgeReport, error) {
+	ret := _m.Called(ctx, teamName, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOpenPRAgeReport")
+	}
+
+	var r0 *domain.TeamAgeReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (*domain.TeamAgeReport, error)); ok {
+		return rf(ctx, teamName, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) *domain.TeamAgeReport); ok {
+		r0 = rf(ctx, teamName, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TeamAgeReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, teamName, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTeamByName provides a mock function with given fields: ctx, teamName
 func (_m *TeamRepository) GetTeamByName(ctx context.Context, teamName string) (*domain.Team, error) {
 	ret := _m.Called(ctx, teamName)
@@ -90,6 +248,200 @@ func (_m *TeamRepository) GetTeamByName(ctx context.Context, teamName string) (*
 	return r0, r1
 }
 
+// GetTeamsMissingFromUsers provides a mock function with given fields: ctx
+func (_m *TeamRepository) GetTeamsMissingFromUsers(ctx context.Context) ([]domain.ReconciledTeam, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTeamsMissingFromUsers")
+	}
+
+	var r0 []domain.ReconciledTeam
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.ReconciledTeam, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.ReconciledTeam); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ReconciledTeam)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWebhook provides a mock function with given fields: ctx, teamName
+func (_m *TeamRepository) GetWebhook(ctx context.Context, teamName string) (*domain.TeamWebhook, error) {
+	ret := _m.Called(ctx, teamName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhook")
+	}
+
+	var r0 *domain.TeamWebhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.TeamWebhook, error)); ok {
+		return rf(ctx, teamName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TeamWebhook); ok {
+		r0 = rf(ctx, teamName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TeamWebhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, teamName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWebhookStatus provides a mock function with given fields: ctx, teamName
+func (_m *TeamRepository) GetWebhookStatus(ctx context.Context, teamName string) (*domain.TeamWebhookStatus, error) {
+	ret := _m.Called(ctx, teamName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhookStatus")
+	}
+
+	var r0 *domain.TeamWebhookStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.TeamWebhookStatus, error)); ok {
+		return rf(ctx, teamName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TeamWebhookStatus); ok {
+		r0 = rf(ctx, teamName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TeamWebhookStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, teamName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HasOverlappingBlackout provides a mock function with given fields: ctx, teamName, startsAt, endsAt
+func (_m *TeamRepository) HasOverlappingBlackout(ctx context.Context, teamName string, startsAt time.Time, endsAt time.Time) (bool, error) {
+	ret := _m.Called(ctx, teamName, startsAt, endsAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HasOverlappingBlackout")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (bool, error)); ok {
+		return rf(ctx, teamName, startsAt, endsAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) bool); ok {
+		r0 = rf(ctx, teamName, startsAt, endsAt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, teamName, startsAt, endsAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsInBlackout provides a mock function with given fields: ctx, teamName, at
+func (_m *TeamRepository) IsInBlackout(ctx context.Context, teamName string, at time.Time) (bool, error) {
+	ret := _m.Called(ctx, teamName, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsInBlackout")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (bool, error)); ok {
+		return rf(ctx, teamName, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) bool); ok {
+		r0 = rf(ctx, teamName, at)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, teamName, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LockBlackouts provides a mock function with given fields: ctx, teamName
+func (_m *TeamRepository) LockBlackouts(ctx context.Context, teamName string) error {
+	ret := _m.Called(ctx, teamName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockBlackouts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, teamName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateWebhook provides a mock function with given fields: ctx, update
+func (_m *TeamRepository) UpdateWebhook(ctx context.Context, update domain.TeamWebhookUpdate) (*domain.TeamWebhook, error) {
+	ret := _m.Called(ctx, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateWebhook")
+	}
+
+	var r0 *domain.TeamWebhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TeamWebhookUpdate) (*domain.TeamWebhook, error)); ok {
+		return rf(ctx, update)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TeamWebhookUpdate) *domain.TeamWebhook); ok {
+		r0 = rf(ctx, update)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TeamWebhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.TeamWebhookUpdate) error); ok {
+		r1 = rf(ctx, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewTeamRepository creates a new instance of TeamRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewTeamRepository(t interface {
@@ -5,10 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"time"
 
+	"avito_backend_task/internal/auth"
 	"avito_backend_task/internal/domain"
 	"avito_backend_task/internal/repository"
+	"avito_backend_task/internal/service/eligibility"
+	"avito_backend_task/pkg/clock"
 	"avito_backend_task/pkg/db"
+	"avito_backend_task/pkg/logctx"
 )
 
 //go:generate mockery --name=TeamRepository --output=./mocks --case=underscore
@@ -16,6 +22,19 @@ type TeamRepository interface {
 	Create(ctx context.Context, teamName string) error
 	Exists(ctx context.Context, teamName string) (bool, error)
 	GetTeamByName(ctx context.Context, teamName string) (*domain.Team, error)
+	LockBlackouts(ctx context.Context, teamName string) error
+	HasOverlappingBlackout(ctx context.Context, teamName string, startsAt, endsAt time.Time) (bool, error)
+	CreateBlackout(ctx context.Context, blackout domain.TeamBlackout) (*domain.TeamBlackout, error)
+	DeleteBlackout(ctx context.Context, teamName string, blackoutID int64) error
+	GetOpenPRAgeReport(ctx context.Context, teamName string, now time.Time) (*domain.TeamAgeReport, error)
+	GetTeamsMissingFromUsers(ctx context.Context) ([]domain.ReconciledTeam, error)
+	CreateMissingTeams(ctx context.Context, teamNames []string) ([]string, error)
+	IsInBlackout(ctx context.Context, teamName string, at time.Time) (bool, error)
+	CreateWebhook(ctx context.Context, webhook domain.TeamWebhook) (*domain.TeamWebhook, error)
+	GetWebhook(ctx context.Context, teamName string) (*domain.TeamWebhook, error)
+	UpdateWebhook(ctx context.Context, update domain.TeamWebhookUpdate) (*domain.TeamWebhook, error)
+	DeleteWebhook(ctx context.Context, teamName string) error
+	GetWebhookStatus(ctx context.Context, teamName string) (*domain.TeamWebhookStatus, error)
 }
 
 //go:generate mockery --name=UserRepository --output=./mocks --case=underscore
@@ -25,24 +44,92 @@ type UserRepository interface {
 	SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error)
 }
 
+// ReviewLoadRepository - число открытых ревью участников для проверки MAX_OPEN_REVIEWS_PER_USER
+//
+//go:generate mockery --name=ReviewLoadRepository --output=./mocks --case=underscore
+type ReviewLoadRepository interface {
+	GetOpenReviewCounts(ctx context.Context, userIDs []string) (map[string]int, error)
+}
+
+// defaultReviewersPerPR - сколько ревьюеров должно набираться на PR, если WithReviewersPerPR не задан.
+// Совпадает с лимитом ревьюеров PR по умолчанию
+const defaultReviewersPerPR = 2
+
+type Option func(*TeamService)
+
+func WithClock(c clock.Clock) Option {
+	return func(s *TeamService) {
+		s.clock = c
+	}
+}
+
+// WithReviewersPerPR задаёт, сколько ревьюеров проверка здоровья команды требует на PR, 0 - defaultReviewersPerPR
+func WithReviewersPerPR(n int) Option {
+	return func(s *TeamService) {
+		if n > 0 {
+			s.reviewersPerPR = n
+		}
+	}
+}
+
+// WithMaxMembers ограничивает размер создаваемой команды, 0 - без ограничения
+func WithMaxMembers(n int) Option {
+	return func(s *TeamService) {
+		s.maxMembers = n
+	}
+}
+
+// WithReviewLoad учитывает в GetTeamEligibility лимит открытых ревью maxOpenReviews, как при автоназначении.
+// Без него или при maxOpenReviews <= 0 лимит не проверяется
+func WithReviewLoad(loads ReviewLoadRepository, maxOpenReviews int) Option {
+	return func(s *TeamService) {
+		s.loads = loads
+		s.maxOpenReviews = maxOpenReviews
+	}
+}
+
 type TeamService struct {
-	teamRepo  TeamRepository
-	userRepo  UserRepository
-	txManager db.TransactionManagerInterface
-	lg        *slog.Logger
+	teamRepo       TeamRepository
+	userRepo       UserRepository
+	txManager      db.TransactionManagerInterface
+	lg             *slog.Logger
+	clock          clock.Clock
+	reviewersPerPR int
+	maxMembers     int
+	loads          ReviewLoadRepository
+	maxOpenReviews int
 }
 
 func NewTeamService(teamRepo TeamRepository, userRepo UserRepository,
-	txManager db.TransactionManagerInterface, lg *slog.Logger) *TeamService {
-	return &TeamService{
-		teamRepo:  teamRepo,
-		userRepo:  userRepo,
-		txManager: txManager,
-		lg:        lg,
+	txManager db.TransactionManagerInterface, lg *slog.Logger, opts ...Option) *TeamService {
+	s := &TeamService{
+		teamRepo:       teamRepo,
+		userRepo:       userRepo,
+		txManager:      txManager,
+		lg:             lg,
+		clock:          clock.New(),
+		reviewersPerPR: defaultReviewersPerPR,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 func (s *TeamService) CreateTeam(ctx context.Context, team domain.Team) (*domain.Team, error) {
+	if err := team.Validate(); err != nil {
+		return nil, err
+	}
+	if err := auth.AuthorizeTeam(ctx, team.TeamName); err != nil {
+		return nil, err
+	}
+
+	// команда новая, поэтому её размер - число участников в запросе
+	if err := domain.CheckTeamSize(s.maxMembers, len(team.Members)); err != nil {
+		return nil, err
+	}
+
 	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
 		exists, err := s.teamRepo.Exists(txCtx, team.TeamName)
 		if err != nil {
@@ -69,12 +156,16 @@ func (s *TeamService) CreateTeam(ctx context.Context, team domain.Team) (*domain
 		return nil, err
 	}
 
-	s.lg.Info("new team created", slog.String("team_name", team.TeamName), slog.Int("members_count", len(team.Members)))
+	s.lg.InfoContext(ctx, "new team created", slog.String("team_name", team.TeamName), slog.Int("members_count", len(team.Members)))
 
 	return &team, nil
 }
 
 func (s *TeamService) GetTeamByName(ctx context.Context, teamName string) (*domain.Team, error) {
+	if err := auth.AuthorizeTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+
 	team, err := s.teamRepo.GetTeamByName(ctx, teamName)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
@@ -85,3 +176,297 @@ func (s *TeamService) GetTeamByName(ctx context.Context, teamName string) (*doma
 
 	return team, nil
 }
+
+// GetTeamByMember возвращает команду, в которой состоит пользователь
+func (s *TeamService) GetTeamByMember(ctx context.Context, userID string) (*domain.Team, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := auth.AuthorizeTeam(ctx, user.TeamName); err != nil {
+		return nil, err
+	}
+
+	team, err := s.teamRepo.GetTeamByName(ctx, user.TeamName)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	return team, nil
+}
+
+// CreateBlackout добавляет окно без автоназначения ревьюеров. Окна одной команды не пересекаются
+func (s *TeamService) CreateBlackout(ctx context.Context, blackout domain.TeamBlackout) (*domain.TeamBlackout, error) {
+	if err := auth.AuthorizeTeam(ctx, blackout.TeamName); err != nil {
+		return nil, err
+	}
+	if err := blackout.Validate(); err != nil {
+		return nil, err
+	}
+
+	var created *domain.TeamBlackout
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		exists, err := s.teamRepo.Exists(txCtx, blackout.TeamName)
+		if err != nil {
+			return fmt.Errorf("failed to check team existence: %w", err)
+		}
+		if !exists {
+			return domain.ErrTeamNotFound
+		}
+
+		if err := s.teamRepo.LockBlackouts(txCtx, blackout.TeamName); err != nil {
+			return err
+		}
+
+		overlaps, err := s.teamRepo.HasOverlappingBlackout(txCtx, blackout.TeamName, blackout.StartsAt, blackout.EndsAt)
+		if err != nil {
+			return err
+		}
+		if overlaps {
+			return domain.ErrBlackoutOverlap
+		}
+
+		created, err = s.teamRepo.CreateBlackout(txCtx, blackout)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.lg.InfoContext(ctx, "team blackout created",
+		slog.String("team_name", created.TeamName),
+		slog.Int64("blackout_id", created.BlackoutID),
+		slog.Time("starts_at", created.StartsAt),
+		slog.Time("ends_at", created.EndsAt))
+
+	return created, nil
+}
+
+func (s *TeamService) DeleteBlackout(ctx context.Context, teamName string, blackoutID int64) error {
+	if err := auth.AuthorizeTeam(ctx, teamName); err != nil {
+		return err
+	}
+
+	if err := s.teamRepo.DeleteBlackout(ctx, teamName, blackoutID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrBlackoutNotFound
+		}
+		return err
+	}
+
+	s.lg.InfoContext(ctx, "team blackout deleted", slog.String("team_name", teamName), slog.Int64("blackout_id", blackoutID))
+	return nil
+}
+
+// CreateWebhook задаёт команде webhook, у команды может быть только один
+func (s *TeamService) CreateWebhook(ctx context.Context, webhook domain.TeamWebhook) (*domain.TeamWebhook, error) {
+	if err := auth.AuthorizeTeam(ctx, webhook.TeamName); err != nil {
+		return nil, err
+	}
+
+	var created *domain.TeamWebhook
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		exists, err := s.teamRepo.Exists(txCtx, webhook.TeamName)
+		if err != nil {
+			return fmt.Errorf("failed to check team existence: %w", err)
+		}
+		if !exists {
+			return domain.ErrTeamNotFound
+		}
+
+		created, err = s.teamRepo.CreateWebhook(txCtx, webhook)
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return domain.ErrWebhookExists
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.lg.InfoContext(ctx, "team webhook created", slog.String("team_name", created.TeamName), slog.Bool("enabled", created.Enabled))
+	return created, nil
+}
+
+func (s *TeamService) GetWebhook(ctx context.Context, teamName string) (*domain.TeamWebhook, error) {
+	if err := auth.AuthorizeTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.teamRepo.GetWebhook(ctx, teamName)
+	if err != nil {
+		return nil, webhookError(err)
+	}
+	return webhook, nil
+}
+
+func (s *TeamService) UpdateWebhook(ctx context.Context, update domain.TeamWebhookUpdate) (*domain.TeamWebhook, error) {
+	if err := auth.AuthorizeTeam(ctx, update.TeamName); err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.teamRepo.UpdateWebhook(ctx, update)
+	if err != nil {
+		return nil, webhookError(err)
+	}
+
+	s.lg.InfoContext(ctx, "team webhook updated", slog.String("team_name", webhook.TeamName), slog.Bool("enabled", webhook.Enabled))
+	return webhook, nil
+}
+
+func (s *TeamService) DeleteWebhook(ctx context.Context, teamName string) error {
+	if err := auth.AuthorizeTeam(ctx, teamName); err != nil {
+		return err
+	}
+
+	if err := s.teamRepo.DeleteWebhook(ctx, teamName); err != nil {
+		return webhookError(err)
+	}
+
+	s.lg.InfoContext(ctx, "team webhook deleted", slog.String("team_name", teamName))
+	return nil
+}
+
+// GetWebhookStatus - итог последних доставок на webhook команды
+func (s *TeamService) GetWebhookStatus(ctx context.Context, teamName string) (*domain.TeamWebhookStatus, error) {
+	if err := auth.AuthorizeTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	status, err := s.teamRepo.GetWebhookStatus(ctx, teamName)
+	if err != nil {
+		return nil, webhookError(err)
+	}
+	return status, nil
+}
+
+func webhookError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return domain.ErrWebhookNotFound
+	}
+	return err
+}
+
+// GetAgeReport - возраст открытых PR авторов команды на текущий момент
+func (s *TeamService) GetAgeReport(ctx context.Context, teamName string) (*domain.TeamAgeReport, error) {
+	if err := auth.AuthorizeTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.teamRepo.Exists(ctx, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check team existence: %w", err)
+	}
+	if !exists {
+		return nil, domain.ErrTeamNotFound
+	}
+
+	report, err := s.teamRepo.GetOpenPRAgeReport(ctx, teamName, s.clock.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR age report: %w", err)
+	}
+
+	return report, nil
+}
+
+// GetTeamEligibility возвращает команду и по user_id участника, получил бы он ревью при автоназначении сейчас.
+// Условия те же, что у выбора ревьюеров PR с приоритетом ниже HIGH
+func (s *TeamService) GetTeamEligibility(ctx context.Context, teamName string) (*domain.Team, map[string]eligibility.Status, error) {
+	team, err := s.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inBlackout, err := s.teamRepo.IsInBlackout(ctx, teamName, s.clock.Now())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check team blackout: %w", err)
+	}
+
+	counts := map[string]int{}
+	if s.loads != nil && s.maxOpenReviews > 0 && len(team.Members) > 0 {
+		userIDs := make([]string, len(team.Members))
+		for i, m := range team.Members {
+			userIDs[i] = m.UserID
+		}
+		counts, err = s.loads.GetOpenReviewCounts(ctx, userIDs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get open review counts: %w", err)
+		}
+	}
+
+	statuses := make(map[string]eligibility.Status, len(team.Members))
+	for _, m := range team.Members {
+		statuses[m.UserID] = eligibility.Evaluate(m, counts[m.UserID], s.maxOpenReviews, inBlackout)
+	}
+	return team, statuses, nil
+}
+
+// CheckHealth - быстрая проверка для эксплуатации: симуляция назначения ревьюеров на PR каждого участника
+func (s *TeamService) CheckHealth(ctx context.Context, teamName string) (*domain.TeamHealth, error) {
+	team, err := s.GetTeamByName(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := team.SimulateCoverage(s.reviewersPerPR, s.clock.Now())
+	return &domain.TeamHealth{
+		TeamName: team.TeamName,
+		Healthy:  len(issues) == 0,
+		Issues:   issues,
+	}, nil
+}
+
+// ReconcileTeamsFromUsers создаёт недостающие команды, на которые ссылаются пользователи. Только для админского ключа.
+// С dryRun возвращает те же команды, что были бы созданы, ничего не записывая
+func (s *TeamService) ReconcileTeamsFromUsers(ctx context.Context, dryRun bool) ([]domain.ReconciledTeam, error) {
+	op := "TeamService.ReconcileTeamsFromUsers"
+	log := logctx.With(ctx, s.lg, slog.String("op", op), slog.Bool("dry_run", dryRun))
+
+	var teams []domain.ReconciledTeam
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		missing, err := s.teamRepo.GetTeamsMissingFromUsers(txCtx)
+		if err != nil {
+			return err
+		}
+		if dryRun || len(missing) == 0 {
+			teams = missing
+			return nil
+		}
+
+		names := make([]string, len(missing))
+		for i, team := range missing {
+			names[i] = team.TeamName
+		}
+		created, err := s.teamRepo.CreateMissingTeams(txCtx, names)
+		if err != nil {
+			return err
+		}
+		// команду мог успеть создать параллельный запуск, в ответ попадают только созданные здесь
+		teams = slices.DeleteFunc(missing, func(team domain.ReconciledTeam) bool {
+			return !slices.Contains(created, team.TeamName)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		log.InfoContext(ctx, "teams reconcile planned", slog.Int("missing", len(teams)))
+		return teams, nil
+	}
+	for _, team := range teams {
+		log.WarnContext(ctx, "created missing team referenced by users",
+			slog.String("team_name", team.TeamName),
+			slog.Any("user_ids", team.UserIDs))
+	}
+	log.InfoContext(ctx, "teams reconciled", slog.Int("created", len(teams)))
+	return teams, nil
+}
@@ -3,27 +3,33 @@ package teams
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"avito_backend_task/internal/auth"
 	"avito_backend_task/internal/domain"
 	"avito_backend_task/internal/repository"
+	"avito_backend_task/internal/service/eligibility"
 	"avito_backend_task/internal/service/team/mocks"
+	"avito_backend_task/pkg/clock"
 	dbmocks "avito_backend_task/pkg/db/mocks"
 )
 
-func setupTestService() (*TeamService, *mocks.TeamRepository, *mocks.UserRepository, *dbmocks.MockTransactionManager) {
+func setupTestService(opts ...Option) (*TeamService, *mocks.TeamRepository, *mocks.UserRepository, *dbmocks.MockTransactionManager) {
 	teamRepo := new(mocks.TeamRepository)
 	userRepo := new(mocks.UserRepository)
 	txManager := dbmocks.NewMockTransactionManager()
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 
-	service := NewTeamService(teamRepo, userRepo, txManager, logger)
+	service := NewTeamService(teamRepo, userRepo, txManager, logger, opts...)
 	return service, teamRepo, userRepo, txManager
 }
 
@@ -36,21 +42,16 @@ func TestTeamService_CreateTeam(t *testing.T) {
 		validate      func(*testing.T, *domain.Team, error)
 	}{
 		{
-			name: "create team without members",
+			name: "team without members rejected",
 			team: domain.Team{
 				TeamName: "team1",
 				Members:  []domain.TeamMember{},
 			},
-			setupMocks: func(teamRepo *mocks.TeamRepository, userRepo *mocks.UserRepository) {
-				teamRepo.On("Exists", mock.Anything, "team1").Return(false, nil)
-				teamRepo.On("Create", mock.Anything, "team1").Return(nil)
-			},
-			expectedError: nil,
+			setupMocks:    func(teamRepo *mocks.TeamRepository, userRepo *mocks.UserRepository) {},
+			expectedError: domain.ErrInvalidInput,
 			validate: func(t *testing.T, team *domain.Team, err error) {
-				require.NoError(t, err)
-				assert.NotNil(t, team)
-				assert.Equal(t, "team1", team.TeamName)
-				assert.Empty(t, team.Members)
+				require.ErrorIs(t, err, domain.ErrInvalidInput)
+				assert.Nil(t, team)
 			},
 		},
 		{
@@ -80,7 +81,7 @@ func TestTeamService_CreateTeam(t *testing.T) {
 			name: "team already exists",
 			team: domain.Team{
 				TeamName: "existing-team",
-				Members:  []domain.TeamMember{},
+				Members:  []domain.TeamMember{{UserID: "user1", Username: "User1", IsActive: true}},
 			},
 			setupMocks: func(teamRepo *mocks.TeamRepository, userRepo *mocks.UserRepository) {
 				teamRepo.On("Exists", mock.Anything, "existing-team").Return(true, nil)
@@ -96,7 +97,7 @@ func TestTeamService_CreateTeam(t *testing.T) {
 			name: "repository error on exists check",
 			team: domain.Team{
 				TeamName: "team3",
-				Members:  []domain.TeamMember{},
+				Members:  []domain.TeamMember{{UserID: "user1", Username: "User1", IsActive: true}},
 			},
 			setupMocks: func(teamRepo *mocks.TeamRepository, userRepo *mocks.UserRepository) {
 				teamRepo.On("Exists", mock.Anything, "team3").Return(false, errors.New("db error"))
@@ -112,7 +113,7 @@ func TestTeamService_CreateTeam(t *testing.T) {
 			name: "repository error on create",
 			team: domain.Team{
 				TeamName: "team4",
-				Members:  []domain.TeamMember{},
+				Members:  []domain.TeamMember{{UserID: "user1", Username: "User1", IsActive: true}},
 			},
 			setupMocks: func(teamRepo *mocks.TeamRepository, userRepo *mocks.UserRepository) {
 				teamRepo.On("Exists", mock.Anything, "team4").Return(false, nil)
@@ -161,6 +162,34 @@ func TestTeamService_CreateTeam(t *testing.T) {
 	}
 }
 
+func TestTeamService_CreateTeam_MaxMembers(t *testing.T) {
+	members := []domain.TeamMember{{UserID: "u1", Username: "u1"}, {UserID: "u2", Username: "u2"}, {UserID: "u3", Username: "u3"}}
+
+	t.Run("at limit", func(t *testing.T) {
+		service, teamRepo, userRepo, _ := setupTestService(WithMaxMembers(3))
+		teamRepo.On("Exists", mock.Anything, "team1").Return(false, nil)
+		teamRepo.On("Create", mock.Anything, "team1").Return(nil)
+		userRepo.On("Upsert", mock.Anything, mock.Anything, "team1").Return(nil).Times(3)
+
+		_, err := service.CreateTeam(context.Background(), domain.Team{TeamName: "team1", Members: members})
+
+		require.NoError(t, err)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("over limit", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService(WithMaxMembers(2))
+
+		_, err := service.CreateTeam(context.Background(), domain.Team{TeamName: "team1", Members: members})
+
+		require.ErrorIs(t, err, domain.ErrTeamTooLarge)
+		var conflict *domain.ConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, &domain.TeamSizeLimit{Limit: 2, Size: 3}, conflict.Payload)
+		teamRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
 func TestTeamService_GetTeamByName(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -230,3 +259,495 @@ func TestTeamService_GetTeamByName(t *testing.T) {
 		})
 	}
 }
+
+func TestTeamService_GetTeamEligibility(t *testing.T) {
+	now := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+	team := &domain.Team{
+		TeamName: "backend",
+		Members: []domain.TeamMember{
+			{UserID: "inactive", IsActive: false},
+			{UserID: "busy", IsActive: true},
+			{UserID: "free", IsActive: true},
+		},
+	}
+
+	t.Run("flags per member", func(t *testing.T) {
+		loads := new(mocks.ReviewLoadRepository)
+		service, teamRepo, _, _ := setupTestService(WithClock(clock.NewFake(now)), WithReviewLoad(loads, 2))
+		teamRepo.On("GetTeamByName", mock.Anything, "backend").Return(team, nil)
+		teamRepo.On("IsInBlackout", mock.Anything, "backend", now).Return(false, nil)
+		loads.On("GetOpenReviewCounts", mock.Anything, []string{"inactive", "busy", "free"}).
+			Return(map[string]int{"busy": 2, "free": 1}, nil)
+
+		result, statuses, err := service.GetTeamEligibility(context.Background(), "backend")
+
+		require.NoError(t, err)
+		assert.Equal(t, team, result)
+		assert.Equal(t, map[string]eligibility.Status{
+			"inactive": {Active: false, UnderCapacity: true},
+			"busy":     {Active: true, UnderCapacity: false},
+			"free":     {Active: true, UnderCapacity: true},
+		}, statuses)
+		assert.True(t, statuses["free"].Eligible())
+		assert.False(t, statuses["busy"].Eligible())
+		assert.False(t, statuses["inactive"].Eligible())
+	})
+
+	t.Run("blackout without capacity limit", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService(WithClock(clock.NewFake(now)))
+		teamRepo.On("GetTeamByName", mock.Anything, "backend").Return(team, nil)
+		teamRepo.On("IsInBlackout", mock.Anything, "backend", now).Return(true, nil)
+
+		_, statuses, err := service.GetTeamEligibility(context.Background(), "backend")
+
+		require.NoError(t, err)
+		assert.Equal(t, eligibility.Status{Active: true, UnderCapacity: true, InBlackout: true}, statuses["busy"])
+		assert.False(t, statuses["busy"].Eligible())
+	})
+
+	t.Run("load query error", func(t *testing.T) {
+		loads := new(mocks.ReviewLoadRepository)
+		service, teamRepo, _, _ := setupTestService(WithClock(clock.NewFake(now)), WithReviewLoad(loads, 2))
+		teamRepo.On("GetTeamByName", mock.Anything, "backend").Return(team, nil)
+		teamRepo.On("IsInBlackout", mock.Anything, "backend", now).Return(false, nil)
+		loads.On("GetOpenReviewCounts", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		_, _, err := service.GetTeamEligibility(context.Background(), "backend")
+
+		require.ErrorContains(t, err, "failed to get open review counts")
+	})
+}
+
+func TestTeamService_GetTeamByMember(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupMocks func(*mocks.TeamRepository, *mocks.UserRepository)
+		validate   func(*testing.T, *domain.Team, error)
+	}{
+		{
+			name: "team of existing member",
+			setupMocks: func(teamRepo *mocks.TeamRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "user1").Return(&domain.User{UserID: "user1", TeamName: "team1"}, nil)
+				teamRepo.On("GetTeamByName", mock.Anything, "team1").Return(&domain.Team{
+					TeamName: "team1",
+					Members: []domain.TeamMember{
+						{UserID: "user1", Username: "User1", IsActive: true},
+						{UserID: "user2", Username: "User2", IsActive: true},
+					},
+				}, nil)
+			},
+			validate: func(t *testing.T, team *domain.Team, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, "team1", team.TeamName)
+				assert.Len(t, team.Members, 2)
+			},
+		},
+		{
+			name: "user not found",
+			setupMocks: func(teamRepo *mocks.TeamRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "user1").Return(nil, repository.ErrNotFound)
+			},
+			validate: func(t *testing.T, team *domain.Team, err error) {
+				assert.Nil(t, team)
+				assert.ErrorIs(t, err, domain.ErrUserNotFound)
+			},
+		},
+		{
+			name: "team of member deleted",
+			setupMocks: func(teamRepo *mocks.TeamRepository, userRepo *mocks.UserRepository) {
+				userRepo.On("GetByID", mock.Anything, "user1").Return(&domain.User{UserID: "user1", TeamName: "gone"}, nil)
+				teamRepo.On("GetTeamByName", mock.Anything, "gone").Return(nil, repository.ErrNotFound)
+			},
+			validate: func(t *testing.T, team *domain.Team, err error) {
+				assert.Nil(t, team)
+				assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, teamRepo, userRepo, _ := setupTestService()
+			tt.setupMocks(teamRepo, userRepo)
+
+			result, err := service.GetTeamByMember(context.Background(), "user1")
+
+			tt.validate(t, result, err)
+			teamRepo.AssertExpectations(t)
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTeamService_TeamScope(t *testing.T) {
+	scoped := auth.WithScope(context.Background(), auth.Scope{Team: "team1"})
+
+	t.Run("cannot read another team", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+
+		team, err := service.GetTeamByName(scoped, "team2")
+
+		require.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, team)
+		teamRepo.AssertNotCalled(t, "GetTeamByName", mock.Anything, mock.Anything)
+	})
+
+	t.Run("cannot create another team", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+
+		team, err := service.CreateTeam(scoped, domain.Team{TeamName: "team2", Members: []domain.TeamMember{{UserID: "u1", Username: "u1"}}})
+
+		require.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, team)
+		teamRepo.AssertNotCalled(t, "Exists", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reads own team", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("GetTeamByName", mock.Anything, "team1").Return(&domain.Team{TeamName: "team1"}, nil)
+
+		team, err := service.GetTeamByName(scoped, "team1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "team1", team.TeamName)
+	})
+}
+
+func TestTeamService_CreateBlackout(t *testing.T) {
+	start := time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC)
+	blackout := domain.TeamBlackout{
+		TeamName: "team1",
+		StartsAt: start,
+		EndsAt:   start.Add(72 * time.Hour),
+		Reason:   "release freeze",
+	}
+
+	tests := []struct {
+		name          string
+		blackout      domain.TeamBlackout
+		setupMocks    func(*mocks.TeamRepository)
+		expectedError error
+	}{
+		{
+			name:     "create blackout",
+			blackout: blackout,
+			setupMocks: func(teamRepo *mocks.TeamRepository) {
+				teamRepo.On("Exists", mock.Anything, "team1").Return(true, nil)
+				teamRepo.On("LockBlackouts", mock.Anything, "team1").Return(nil)
+				teamRepo.On("HasOverlappingBlackout", mock.Anything, "team1", blackout.StartsAt, blackout.EndsAt).Return(false, nil)
+				created := blackout
+				created.BlackoutID = 7
+				teamRepo.On("CreateBlackout", mock.Anything, blackout).Return(&created, nil)
+			},
+		},
+		{
+			name:          "empty window",
+			blackout:      domain.TeamBlackout{TeamName: "team1", StartsAt: start, EndsAt: start},
+			setupMocks:    func(teamRepo *mocks.TeamRepository) {},
+			expectedError: domain.ErrInvalidInput,
+		},
+		{
+			name:     "team not found",
+			blackout: blackout,
+			setupMocks: func(teamRepo *mocks.TeamRepository) {
+				teamRepo.On("Exists", mock.Anything, "team1").Return(false, nil)
+			},
+			expectedError: domain.ErrTeamNotFound,
+		},
+		{
+			name:     "overlaps existing window",
+			blackout: blackout,
+			setupMocks: func(teamRepo *mocks.TeamRepository) {
+				teamRepo.On("Exists", mock.Anything, "team1").Return(true, nil)
+				teamRepo.On("LockBlackouts", mock.Anything, "team1").Return(nil)
+				teamRepo.On("HasOverlappingBlackout", mock.Anything, "team1", blackout.StartsAt, blackout.EndsAt).Return(true, nil)
+			},
+			expectedError: domain.ErrBlackoutOverlap,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, teamRepo, _, _ := setupTestService()
+			tt.setupMocks(teamRepo)
+
+			result, err := service.CreateBlackout(context.Background(), tt.blackout)
+
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+				teamRepo.AssertNotCalled(t, "CreateBlackout", mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, int64(7), result.BlackoutID)
+			}
+			teamRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("cannot create for another team", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		scoped := auth.WithScope(context.Background(), auth.Scope{Team: "team2"})
+
+		_, err := service.CreateBlackout(scoped, blackout)
+
+		require.ErrorIs(t, err, domain.ErrForbidden)
+		teamRepo.AssertNotCalled(t, "Exists", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTeamService_DeleteBlackout(t *testing.T) {
+	t.Run("delete blackout", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("DeleteBlackout", mock.Anything, "team1", int64(7)).Return(nil)
+
+		require.NoError(t, service.DeleteBlackout(context.Background(), "team1", 7))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("DeleteBlackout", mock.Anything, "team1", int64(7)).Return(repository.ErrNotFound)
+
+		err := service.DeleteBlackout(context.Background(), "team1", 7)
+
+		require.ErrorIs(t, err, domain.ErrBlackoutNotFound)
+	})
+}
+
+func TestTeamService_Webhooks(t *testing.T) {
+	webhook := domain.TeamWebhook{TeamName: "team1", URL: "https://hooks.local/team1", Secret: "s3cret-s3cret-s3cret", Enabled: true}
+
+	t.Run("create", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("Exists", mock.Anything, "team1").Return(true, nil)
+		teamRepo.On("CreateWebhook", mock.Anything, webhook).Return(&webhook, nil)
+
+		created, err := service.CreateWebhook(context.Background(), webhook)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://hooks.local/team1", created.URL)
+	})
+
+	t.Run("create for unknown team", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("Exists", mock.Anything, "team1").Return(false, nil)
+
+		_, err := service.CreateWebhook(context.Background(), webhook)
+
+		require.ErrorIs(t, err, domain.ErrTeamNotFound)
+		teamRepo.AssertNotCalled(t, "CreateWebhook", mock.Anything, mock.Anything)
+	})
+
+	t.Run("create twice", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("Exists", mock.Anything, "team1").Return(true, nil)
+		teamRepo.On("CreateWebhook", mock.Anything, webhook).
+			Return(nil, fmt.Errorf("failed to insert webhook: %w", repository.ErrAlreadyExists))
+
+		_, err := service.CreateWebhook(context.Background(), webhook)
+
+		require.ErrorIs(t, err, domain.ErrWebhookExists)
+	})
+
+	t.Run("another team is forbidden", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		scoped := auth.WithScope(context.Background(), auth.Scope{Team: "team2"})
+
+		_, err := service.CreateWebhook(scoped, webhook)
+		require.ErrorIs(t, err, domain.ErrForbidden)
+		_, err = service.GetWebhookStatus(scoped, "team1")
+		require.ErrorIs(t, err, domain.ErrForbidden)
+		require.ErrorIs(t, service.DeleteWebhook(scoped, "team1"), domain.ErrForbidden)
+
+		teamRepo.AssertNotCalled(t, "Exists", mock.Anything, mock.Anything)
+		teamRepo.AssertNotCalled(t, "GetWebhookStatus", mock.Anything, mock.Anything)
+		teamRepo.AssertNotCalled(t, "DeleteWebhook", mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing webhook", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		enabled := false
+		update := domain.TeamWebhookUpdate{TeamName: "team1", Enabled: &enabled}
+		teamRepo.On("UpdateWebhook", mock.Anything, update).Return(nil, repository.ErrNotFound)
+		teamRepo.On("GetWebhookStatus", mock.Anything, "team1").Return(nil, repository.ErrNotFound)
+
+		_, err := service.UpdateWebhook(context.Background(), update)
+		require.ErrorIs(t, err, domain.ErrWebhookNotFound)
+		_, err = service.GetWebhookStatus(context.Background(), "team1")
+		require.ErrorIs(t, err, domain.ErrWebhookNotFound)
+	})
+}
+
+func TestTeamService_GetAgeReport(t *testing.T) {
+	now := time.Date(2025, 11, 10, 12, 0, 0, 0, time.UTC)
+	report := &domain.TeamAgeReport{TeamName: "team1", OldestPRID: "pr1", OldestAge: 48 * time.Hour}
+
+	t.Run("report at service clock", func(t *testing.T) {
+		teamRepo := new(mocks.TeamRepository)
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		service := NewTeamService(teamRepo, new(mocks.UserRepository), dbmocks.NewMockTransactionManager(), logger,
+			WithClock(clock.NewFake(now)))
+
+		teamRepo.On("Exists", mock.Anything, "team1").Return(true, nil)
+		teamRepo.On("GetOpenPRAgeReport", mock.Anything, "team1", now).Return(report, nil)
+
+		result, err := service.GetAgeReport(context.Background(), "team1")
+
+		require.NoError(t, err)
+		assert.Equal(t, report, result)
+	})
+
+	t.Run("team not found", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("Exists", mock.Anything, "team1").Return(false, nil)
+
+		_, err := service.GetAgeReport(context.Background(), "team1")
+
+		require.ErrorIs(t, err, domain.ErrTeamNotFound)
+		teamRepo.AssertNotCalled(t, "GetOpenPRAgeReport", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("Exists", mock.Anything, "team1").Return(true, nil)
+		teamRepo.On("GetOpenPRAgeReport", mock.Anything, "team1", mock.Anything).Return(nil, errors.New("db error"))
+
+		_, err := service.GetAgeReport(context.Background(), "team1")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to get PR age report")
+	})
+}
+
+func TestTeamService_CheckHealth(t *testing.T) {
+	t.Run("healthy team", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("GetTeamByName", mock.Anything, "team1").Return(&domain.Team{
+			TeamName: "team1",
+			Members: []domain.TeamMember{
+				{UserID: "u1", IsActive: true},
+				{UserID: "u2", IsActive: true},
+				{UserID: "u3", IsActive: true},
+				{UserID: "u4", IsActive: false},
+			},
+		}, nil)
+
+		health, err := service.CheckHealth(context.Background(), "team1")
+
+		require.NoError(t, err)
+		assert.True(t, health.Healthy)
+		assert.Empty(t, health.Issues)
+	})
+
+	t.Run("member uncovered", func(t *testing.T) {
+		// с двумя активными участниками каждому из них остаётся один ревьюер вместо двух,
+		// а неактивному автору хватает обоих
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("GetTeamByName", mock.Anything, "team1").Return(&domain.Team{
+			TeamName: "team1",
+			Members: []domain.TeamMember{
+				{UserID: "u1", IsActive: true},
+				{UserID: "u2", IsActive: true},
+				{UserID: "u3", IsActive: false},
+			},
+		}, nil)
+
+		health, err := service.CheckHealth(context.Background(), "team1")
+
+		require.NoError(t, err)
+		assert.False(t, health.Healthy)
+		assert.Equal(t, []string{"MEMBER_UNCOVERED:u1", "MEMBER_UNCOVERED:u2"}, health.Issues)
+	})
+
+	t.Run("single member in blackout", func(t *testing.T) {
+		now := time.Date(2025, 11, 10, 12, 0, 0, 0, time.UTC)
+		teamRepo := new(mocks.TeamRepository)
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+		service := NewTeamService(teamRepo, new(mocks.UserRepository), dbmocks.NewMockTransactionManager(), logger,
+			WithClock(clock.NewFake(now)), WithReviewersPerPR(1))
+
+		teamRepo.On("GetTeamByName", mock.Anything, "team1").Return(&domain.Team{
+			TeamName:  "team1",
+			Members:   []domain.TeamMember{{UserID: "u1", IsActive: true}},
+			Blackouts: []domain.TeamBlackout{{StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}},
+		}, nil)
+
+		health, err := service.CheckHealth(context.Background(), "team1")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SINGLE_MEMBER", "BLACKOUT_ACTIVE", "MEMBER_UNCOVERED:u1"}, health.Issues)
+	})
+
+	t.Run("team not found", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("GetTeamByName", mock.Anything, "team1").Return(nil, repository.ErrNotFound)
+
+		_, err := service.CheckHealth(context.Background(), "team1")
+
+		require.ErrorIs(t, err, domain.ErrTeamNotFound)
+	})
+}
+
+func TestTeamService_ReconcileTeamsFromUsers(t *testing.T) {
+	missing := []domain.ReconciledTeam{
+		{TeamName: "archive", UserIDs: []string{"u3"}},
+		{TeamName: "ghost", UserIDs: []string{"u1", "u2"}},
+	}
+
+	t.Run("creates missing teams", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("GetTeamsMissingFromUsers", mock.Anything).Return(slices.Clone(missing), nil)
+		teamRepo.On("CreateMissingTeams", mock.Anything, []string{"archive", "ghost"}).Return([]string{"archive", "ghost"}, nil).Once()
+
+		result, err := service.ReconcileTeamsFromUsers(context.Background(), false)
+
+		require.NoError(t, err)
+		assert.Equal(t, missing, result)
+		teamRepo.AssertExpectations(t)
+	})
+
+	t.Run("dry run returns the same diff without writes", func(t *testing.T) {
+		service, teamRepo, userRepo, _ := setupTestService()
+		teamRepo.On("GetTeamsMissingFromUsers", mock.Anything).Return(slices.Clone(missing), nil)
+
+		result, err := service.ReconcileTeamsFromUsers(context.Background(), true)
+
+		require.NoError(t, err)
+		assert.Equal(t, missing, result)
+		teamRepo.AssertNotCalled(t, "CreateMissingTeams", mock.Anything, mock.Anything)
+		teamRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		userRepo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything, mock.Anything)
+		userRepo.AssertNotCalled(t, "SetIsActive", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("team created concurrently is not reported", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("GetTeamsMissingFromUsers", mock.Anything).Return(slices.Clone(missing), nil)
+		teamRepo.On("CreateMissingTeams", mock.Anything, []string{"archive", "ghost"}).Return([]string{"ghost"}, nil)
+
+		result, err := service.ReconcileTeamsFromUsers(context.Background(), false)
+
+		require.NoError(t, err)
+		assert.Equal(t, missing[1:], result)
+	})
+
+	t.Run("nothing missing", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("GetTeamsMissingFromUsers", mock.Anything).Return(nil, nil)
+
+		result, err := service.ReconcileTeamsFromUsers(context.Background(), false)
+
+		require.NoError(t, err)
+		assert.Empty(t, result)
+		teamRepo.AssertNotCalled(t, "CreateMissingTeams", mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		service, teamRepo, _, _ := setupTestService()
+		teamRepo.On("GetTeamsMissingFromUsers", mock.Anything).Return(nil, errors.New("db error"))
+
+		_, err := service.ReconcileTeamsFromUsers(context.Background(), true)
+
+		require.Error(t, err)
+	})
+}
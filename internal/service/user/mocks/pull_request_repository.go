@@ -5,6 +5,7 @@ package mocks
 import (
 	domain "avito_backend_task/internal/domain"
 	context "context"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -14,17 +15,17 @@ type PullRequestRepository struct {
 	mock.Mock
 }
 
-// AssignReviewer provides a mock function with given fields: ctx, prID, reviewerID
-func (_m *PullRequestRepository) AssignReviewer(ctx context.Context, prID string, reviewerID string) error {
-	ret := _m.Called(ctx, prID, reviewerID)
+// AssignReviewer provides a mock function with given fields: ctx, prID, reviewerID, source
+func (_m *PullRequestRepository) AssignReviewer(ctx context.Context, prID string, reviewerID string, source domain.AssignmentSource) error {
+	ret := _m.Called(ctx, prID, reviewerID, source)
 
 	if len(ret) == 0 {
 		panic("no return value specified for AssignReviewer")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
-		r0 = rf(ctx, prID, reviewerID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, domain.AssignmentSource) error); ok {
+		r0 = rf(ctx, prID, reviewerID, source)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -32,6 +33,126 @@ func (_m *PullRequestRepository) AssignReviewer(ctx context.Context, prID string
 	return r0
 }
 
+// CloseOrphanedPullRequests provides a mock function with given fields: ctx, authorID
+func (_m *PullRequestRepository) CloseOrphanedPullRequests(ctx context.Context, authorID string) ([]string, error) {
+	ret := _m.Called(ctx, authorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CloseOrphanedPullRequests")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, authorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, authorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, authorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFormerReviews provides a mock function with given fields: ctx, userID
+func (_m *PullRequestRepository) GetFormerReviews(ctx context.Context, userID string) ([]domain.FormerReview, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFormerReviews")
+	}
+
+	var r0 []domain.FormerReview
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.FormerReview, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.FormerReview); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.FormerReview)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOpenAuthoredPullRequests provides a mock function with given fields: ctx, authorID
+func (_m *PullRequestRepository) GetOpenAuthoredPullRequests(ctx context.Context, authorID string) ([]domain.OpenPullRequest, error) {
+	ret := _m.Called(ctx, authorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOpenAuthoredPullRequests")
+	}
+
+	var r0 []domain.OpenPullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.OpenPullRequest, error)); ok {
+		return rf(ctx, authorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.OpenPullRequest); ok {
+		r0 = rf(ctx, authorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.OpenPullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, authorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOpenGroupAuthors provides a mock function with given fields: ctx, groupID, prID
+func (_m *PullRequestRepository) GetOpenGroupAuthors(ctx context.Context, groupID string, prID string) ([]string, error) {
+	ret := _m.Called(ctx, groupID, prID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOpenGroupAuthors")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]string, error)); ok {
+		return rf(ctx, groupID, prID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []string); ok {
+		r0 = rf(ctx, groupID, prID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, groupID, prID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetOpenPullRequestsByReviewer provides a mock function with given fields: ctx, userID
 func (_m *PullRequestRepository) GetOpenPullRequestsByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
 	ret := _m.Called(ctx, userID)
@@ -62,6 +183,36 @@ func (_m *PullRequestRepository) GetOpenPullRequestsByReviewer(ctx context.Conte
 	return r0, r1
 }
 
+// GetOpenReviewingPullRequests provides a mock function with given fields: ctx, userID
+func (_m *PullRequestRepository) GetOpenReviewingPullRequests(ctx context.Context, userID string) ([]domain.OpenPullRequest, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOpenReviewingPullRequests")
+	}
+
+	var r0 []domain.OpenPullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.OpenPullRequest, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.OpenPullRequest); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.OpenPullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPullRequestByID provides a mock function with given fields: ctx, prID
 func (_m *PullRequestRepository) GetPullRequestByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
 	ret := _m.Called(ctx, prID)
@@ -92,9 +243,9 @@ func (_m *PullRequestRepository) GetPullRequestByID(ctx context.Context, prID st
 	return r0, r1
 }
 
-// GetPullRequestsByReviewer provides a mock function with given fields: ctx, userID
-func (_m *PullRequestRepository) GetPullRequestsByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
-	ret := _m.Called(ctx, userID)
+// GetPullRequestsByReviewer provides a mock function with given fields: ctx, userID, filter
+func (_m *PullRequestRepository) GetPullRequestsByReviewer(ctx context.Context, userID string, filter domain.ReviewFilter) ([]domain.PullRequestShort, error) {
+	ret := _m.Called(ctx, userID, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetPullRequestsByReviewer")
@@ -102,19 +253,175 @@ func (_m *PullRequestRepository) GetPullRequestsByReviewer(ctx context.Context,
 
 	var r0 []domain.PullRequestShort
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.PullRequestShort, error)); ok {
-		return rf(ctx, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.ReviewFilter) ([]domain.PullRequestShort, error)); ok {
+		return rf(ctx, userID, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.PullRequestShort); ok {
-		r0 = rf(ctx, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.ReviewFilter) []domain.PullRequestShort); ok {
+		r0 = rf(ctx, userID, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.PullRequestShort)
 		}
 	}
 
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.ReviewFilter) error); ok {
+		r1 = rf(ctx, userID, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReviewerEventsSince provides a mock function with given fields: ctx, since, source, limit
+func (_m *PullRequestRepository) GetReviewerEventsSince(ctx context.Context, since time.Time, source domain.AssignmentSource, limit int) ([]domain.ReviewerEvent, error) {
+	ret := _m.Called(ctx, since, source, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReviewerEventsSince")
+	}
+
+	var r0 []domain.ReviewerEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, domain.AssignmentSource, int) ([]domain.ReviewerEvent, error)); ok {
+		return rf(ctx, since, source, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, domain.AssignmentSource, int) []domain.ReviewerEvent); ok {
+		r0 = rf(ctx, since, source, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ReviewerEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, domain.AssignmentSource, int) error); ok {
+		r1 = rf(ctx, since, source, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUnderReviewedTeamPRs provides a mock function with given fields: ctx, teamName, userID, target
+func (_m *PullRequestRepository) GetUnderReviewedTeamPRs(ctx context.Context, teamName string, userID string, target int) ([]domain.UnderReviewedPR, error) {
+	ret := _m.Called(ctx, teamName, userID, target)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUnderReviewedTeamPRs")
+	}
+
+	var r0 []domain.UnderReviewedPR
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) ([]domain.UnderReviewedPR, error)); ok {
+		return rf(ctx, teamName, userID, target)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []domain.UnderReviewedPR); ok {
+		r0 = rf(ctx, teamName, userID, target)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.UnderReviewedPR)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, teamName, userID, target)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserReviewTimeline provides a mock function with given fields: ctx, userID, from, to, page
+func (_m *PullRequestRepository) GetUserReviewTimeline(ctx context.Context, userID string, from *time.Time, to *time.Time, page domain.Page) ([]domain.ReviewerEvent, error) {
+	ret := _m.Called(ctx, userID, from, to, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserReviewTimeline")
+	}
+
+	var r0 []domain.ReviewerEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time, *time.Time, domain.Page) ([]domain.ReviewerEvent, error)); ok {
+		return rf(ctx, userID, from, to, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time, *time.Time, domain.Page) []domain.ReviewerEvent); ok {
+		r0 = rf(ctx, userID, from, to, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ReviewerEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *time.Time, *time.Time, domain.Page) error); ok {
+		r1 = rf(ctx, userID, from, to, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IncrementReassignmentCount provides a mock function with given fields: ctx, prID
+func (_m *PullRequestRepository) IncrementReassignmentCount(ctx context.Context, prID string) error {
+	ret := _m.Called(ctx, prID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementReassignmentCount")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, prID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LockPullRequest provides a mock function with given fields: ctx, prID
+func (_m *PullRequestRepository) LockPullRequest(ctx context.Context, prID string) error {
+	ret := _m.Called(ctx, prID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockPullRequest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, prID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkOrphanedPullRequests provides a mock function with given fields: ctx, authorID
+func (_m *PullRequestRepository) MarkOrphanedPullRequests(ctx context.Context, authorID string) ([]string, error) {
+	ret := _m.Called(ctx, authorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkOrphanedPullRequests")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, authorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, authorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, userID)
+		r1 = rf(ctx, authorID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -123,16 +430,44 @@ func (_m *PullRequestRepository) GetPullRequestsByReviewer(ctx context.Context,
 }
 
 // RemoveReviewer provides a mock function with given fields: ctx, prID, reviewerID
-func (_m *PullRequestRepository) RemoveReviewer(ctx context.Context, prID string, reviewerID string) error {
+func (_m *PullRequestRepository) RemoveReviewer(ctx context.Context, prID string, reviewerID string) (bool, error) {
 	ret := _m.Called(ctx, prID, reviewerID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RemoveReviewer")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, prID, reviewerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
 		r0 = rf(ctx, prID, reviewerID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, prID, reviewerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StreamPullRequestsByReviewer provides a mock function with given fields: ctx, userID, filter, fn
+func (_m *PullRequestRepository) StreamPullRequestsByReviewer(ctx context.Context, userID string, filter domain.ReviewFilter, fn func(domain.PullRequestShort) error) error {
+	ret := _m.Called(ctx, userID, filter, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamPullRequestsByReviewer")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.ReviewFilter, func(domain.PullRequestShort) error) error); ok {
+		r0 = rf(ctx, userID, filter, fn)
 	} else {
 		r0 = ret.Error(0)
 	}
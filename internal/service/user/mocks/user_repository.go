@@ -14,6 +14,36 @@ type UserRepository struct {
 	mock.Mock
 }
 
+// Create provides a mock function with given fields: ctx, member, teamName
+func (_m *UserRepository) Create(ctx context.Context, member domain.TeamMember, teamName string) (*domain.User, error) {
+	ret := _m.Called(ctx, member, teamName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TeamMember, string) (*domain.User, error)); ok {
+		return rf(ctx, member, teamName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TeamMember, string) *domain.User); ok {
+		r0 = rf(ctx, member, teamName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.TeamMember, string) error); ok {
+		r1 = rf(ctx, member, teamName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetActiveByTeam provides a mock function with given fields: ctx, teamName, excludeUserIDs
 func (_m *UserRepository) GetActiveByTeam(ctx context.Context, teamName string, excludeUserIDs []string) ([]domain.User, error) {
 	ret := _m.Called(ctx, teamName, excludeUserIDs)
@@ -74,6 +104,66 @@ func (_m *UserRepository) GetByID(ctx context.Context, userID string) (*domain.U
 	return r0, r1
 }
 
+// GetByIDs provides a mock function with given fields: ctx, userIDs
+func (_m *UserRepository) GetByIDs(ctx context.Context, userIDs []string) ([]domain.User, error) {
+	ret := _m.Called(ctx, userIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]domain.User, error)); ok {
+		return rf(ctx, userIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []domain.User); ok {
+		r0 = rf(ctx, userIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, userIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveFromTeam provides a mock function with given fields: ctx, userID
+func (_m *UserRepository) RemoveFromTeam(ctx context.Context, userID string) (*domain.User, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveFromTeam")
+	}
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.User, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.User); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetIsActive provides a mock function with given fields: ctx, userID, isActive
 func (_m *UserRepository) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
 	ret := _m.Called(ctx, userID, isActive)
@@ -104,6 +194,66 @@ func (_m *UserRepository) SetIsActive(ctx context.Context, userID string, isActi
 	return r0, r1
 }
 
+// SetReviewCapacity provides a mock function with given fields: ctx, userID, capacity
+func (_m *UserRepository) SetReviewCapacity(ctx context.Context, userID string, capacity float64) (*domain.User, error) {
+	ret := _m.Called(ctx, userID, capacity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetReviewCapacity")
+	}
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64) (*domain.User, error)); ok {
+		return rf(ctx, userID, capacity)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64) *domain.User); ok {
+		r0 = rf(ctx, userID, capacity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, float64) error); ok {
+		r1 = rf(ctx, userID, capacity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetSchedule provides a mock function with given fields: ctx, userID, workingHours, timezone
+func (_m *UserRepository) SetSchedule(ctx context.Context, userID string, workingHours string, timezone string) (*domain.User, error) {
+	ret := _m.Called(ctx, userID, workingHours, timezone)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetSchedule")
+	}
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*domain.User, error)); ok {
+		return rf(ctx, userID, workingHours, timezone)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *domain.User); ok {
+		r0 = rf(ctx, userID, workingHours, timezone)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, userID, workingHours, timezone)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewUserRepository creates a new instance of UserRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserRepository(t interface {
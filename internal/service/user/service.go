@@ -5,10 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"time"
 
+	"avito_backend_task/internal/auth"
 	"avito_backend_task/internal/domain"
 	"avito_backend_task/internal/repository"
 	"avito_backend_task/internal/service/utils"
+	"avito_backend_task/pkg/clock"
 	"avito_backend_task/pkg/db"
 )
 
@@ -16,23 +20,143 @@ import (
 type UserRepository interface {
 	GetActiveByTeam(ctx context.Context, teamName string, excludeUserIDs []string) ([]domain.User, error)
 	GetByID(ctx context.Context, userID string) (*domain.User, error)
+	GetByIDs(ctx context.Context, userIDs []string) ([]domain.User, error)
+	Create(ctx context.Context, member domain.TeamMember, teamName string) (*domain.User, error)
 	SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error)
+	SetSchedule(ctx context.Context, userID, workingHours, timezone string) (*domain.User, error)
+	SetReviewCapacity(ctx context.Context, userID string, capacity float64) (*domain.User, error)
+	RemoveFromTeam(ctx context.Context, userID string) (*domain.User, error)
 }
 
 //go:generate mockery --name=PullRequestRepository --output=./mocks --case=underscore
 type PullRequestRepository interface {
 	GetPullRequestByID(ctx context.Context, prID string) (*domain.PullRequest, error)
-	GetPullRequestsByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error)
+	GetPullRequestsByReviewer(ctx context.Context, userID string, filter domain.ReviewFilter) ([]domain.PullRequestShort, error)
+	StreamPullRequestsByReviewer(
+		ctx context.Context,
+		userID string,
+		filter domain.ReviewFilter,
+		fn func(domain.PullRequestShort) error,
+	) error
 	GetOpenPullRequestsByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error)
-	RemoveReviewer(ctx context.Context, prID, reviewerID string) error
-	AssignReviewer(ctx context.Context, prID, reviewerID string) error
+	GetOpenAuthoredPullRequests(ctx context.Context, authorID string) ([]domain.OpenPullRequest, error)
+	GetOpenReviewingPullRequests(ctx context.Context, userID string) ([]domain.OpenPullRequest, error)
+	RemoveReviewer(ctx context.Context, prID, reviewerID string) (bool, error)
+	AssignReviewer(ctx context.Context, prID, reviewerID string, source domain.AssignmentSource) error
+	IncrementReassignmentCount(ctx context.Context, prID string) error
+	GetUserReviewTimeline(ctx context.Context, userID string, from, to *time.Time, page domain.Page) ([]domain.ReviewerEvent, error)
+	GetReviewerEventsSince(ctx context.Context, since time.Time, source domain.AssignmentSource, limit int) ([]domain.ReviewerEvent, error)
+	GetFormerReviews(ctx context.Context, userID string) ([]domain.FormerReview, error)
+	MarkOrphanedPullRequests(ctx context.Context, authorID string) ([]string, error)
+	CloseOrphanedPullRequests(ctx context.Context, authorID string) ([]string, error)
+	GetUnderReviewedTeamPRs(ctx context.Context, teamName, userID string, target int) ([]domain.UnderReviewedPR, error)
+	GetOpenGroupAuthors(ctx context.Context, groupID, prID string) ([]string, error)
+	LockPullRequest(ctx context.Context, prID string) error
+}
+
+//go:generate mockery --name=TeamRepository --output=./mocks --case=underscore
+type TeamRepository interface {
+	CountMembers(ctx context.Context, teamName string) (int, error)
+}
+
+// Notifier сообщает о замене ревьюеров при деактивации и удалении пользователя и о назначениях
+// при возвращении пользователя. Вызывается после коммита, не блокирует и не возвращает ошибку
+type Notifier interface {
+	NotifyAssignment(pr domain.PullRequest, reviewerIDs []string)
+	NotifyReassignment(pr domain.PullRequest, oldReviewerID, newReviewerID string)
+	NotifyNoCandidate(pr domain.PullRequest, reviewerID string)
+}
+
+// AuditSink записывает назначения и снятия ревьюеров во внешний аудит. Вызывается после коммита,
+// ошибка только логируется
+type AuditSink interface {
+	RecordAssignment(ctx context.Context, record domain.AuditRecord) error
+	RecordRemoval(ctx context.Context, record domain.AuditRecord) error
+}
+
+// LiveUpdates раздаёт закоммиченные изменения PR подписчикам /pullRequest/events, Publish не блокирует
+type LiveUpdates interface {
+	Publish(update domain.PRUpdate)
+}
+
+// MaxAssignmentEvents - сколько событий GetAssignmentEvents отдаёт за один запрос
+const MaxAssignmentEvents = 500
+
+const defaultReviewersPerPR = 2
+
+type Config struct {
+	// AutoCloseOrphanedPRs закрывает открытые PR удалённого из команды автора и снимает с них ревьюеров,
+	// иначе PR остаются открытыми с пометкой orphaned
+	AutoCloseOrphanedPRs bool
+	// MaxTeamMembers - максимум участников команды, в которую SetIsActiveOrCreate добавляет пользователя,
+	// 0 - без ограничения. Работает только вместе с WithTeams
+	MaxTeamMembers int
+	// ReviewersPerPR - сколько ревьюеров должно быть у PR, меньше - ActivateWithTopUp добавляет вернувшегося
+	// пользователя. 0 - значение по умолчанию, 2
+	ReviewersPerPR int
+	// TopUpLimit - в сколько PR максимум ActivateWithTopUp добавляет пользователя, 0 - без ограничения
+	TopUpLimit int
+	// ExcludeGroupAuthors не добавляет пользователя в PR группы, где у него есть свой открытый PR
+	ExcludeGroupAuthors bool
+	// EventsRetention - GetAssignmentEvents отклоняет since старше этого срока: такие события могли быть удалены.
+	// 0 - без ограничения
+	EventsRetention time.Duration
+}
+
+type Option func(*UserService)
+
+func WithConfig(cfg Config) Option {
+	return func(s *UserService) {
+		s.cfg = cfg
+	}
+}
+
+// WithTeams даёт сервису подсчёт участников команды для проверки MaxTeamMembers
+func WithTeams(repo TeamRepository) Option {
+	return func(s *UserService) {
+		s.teams = repo
+	}
+}
+
+// WithNotifier включает уведомления о ревьюерах, которых заменили или сняли с PR при уходе пользователя
+func WithNotifier(notifier Notifier) Option {
+	return func(s *UserService) {
+		s.notifier = notifier
+	}
+}
+
+// WithAudit отправляет в sink замены и снятия ревьюеров при уходе пользователя и добор при его возвращении
+func WithAudit(sink AuditSink) Option {
+	return func(s *UserService) {
+		s.audit = sink
+	}
+}
+
+// WithLiveUpdates публикует замены и снятия ревьюеров для подписчиков на изменения PR
+func WithLiveUpdates(live LiveUpdates) Option {
+	return func(s *UserService) {
+		s.live = live
+	}
+}
+
+// WithClock задаёт часы, по которым истекают snooze в /users/getReview
+func WithClock(c clock.Clock) Option {
+	return func(s *UserService) {
+		s.clock = c
+	}
 }
 
 type UserService struct {
 	userRepo  UserRepository
 	prRepo    PullRequestRepository
+	teams     TeamRepository
+	notifier  Notifier
+	audit     AuditSink
+	live      LiveUpdates
 	txManager db.TransactionManagerInterface
 	lg        *slog.Logger
+	cfg       Config
+	clock     clock.Clock
 }
 
 func NewUserService(
@@ -40,16 +164,27 @@ func NewUserService(
 	prRepo PullRequestRepository,
 	txManager db.TransactionManagerInterface,
 	lg *slog.Logger,
+	opts ...Option,
 ) *UserService {
-	return &UserService{
+	s := &UserService{
 		userRepo:  userRepo,
 		prRepo:    prRepo,
 		txManager: txManager,
 		lg:        lg,
+		clock:     clock.New(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 func (s *UserService) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+	if err := s.authorizeMember(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	if !isActive {
 		return s.deactivateUser(ctx, userID)
 	}
@@ -62,12 +197,177 @@ func (s *UserService) SetIsActive(ctx context.Context, userID string, isActive b
 		return nil, fmt.Errorf("failed to set user active status: %w", err)
 	}
 
-	s.lg.Info("user active status updated", slog.String("user_id", userID), slog.Bool("is_active", isActive))
+	s.lg.InfoContext(ctx, "user active status updated", slog.String("user_id", userID), slog.Bool("is_active", isActive))
 	return user, nil
 }
 
+// ActivateWithTopUp активирует пользователя и в той же транзакции добавляет его ревьюером в открытые PR
+// коллег по команде, где ревьюеров меньше ReviewersPerPR, но не больше чем в TopUpLimit PR.
+// Возвращает ID PR, в которые пользователь добавлен
+func (s *UserService) ActivateWithTopUp(ctx context.Context, userID string) (*domain.User, []string, error) {
+	if err := s.authorizeMember(ctx, userID); err != nil {
+		return nil, nil, err
+	}
+
+	var user *domain.User
+	var topped []domain.PullRequest
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		var err error
+		user, err = s.userRepo.SetIsActive(txCtx, userID, true)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrUserNotFound
+			}
+			return fmt.Errorf("failed to activate user: %w", err)
+		}
+
+		prs, err := s.prRepo.GetUnderReviewedTeamPRs(txCtx, user.TeamName, userID, s.reviewersPerPR())
+		if err != nil {
+			return fmt.Errorf("failed to get under-reviewed PRs: %w", err)
+		}
+
+		topped = nil
+		for _, candidate := range prs {
+			if s.cfg.TopUpLimit > 0 && len(topped) == s.cfg.TopUpLimit {
+				break
+			}
+			pr, err := s.topUpReview(txCtx, candidate, userID)
+			if err != nil {
+				return err
+			}
+			if pr != nil {
+				topped = append(topped, *pr)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, nil, domain.ErrUserNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to activate user with top-up: %w", err)
+	}
+
+	assigned := make([]string, len(topped))
+	for i, pr := range topped {
+		assigned[i] = pr.PullRequestID
+		if s.notifier != nil {
+			s.notifier.NotifyAssignment(pr, []string{userID})
+		}
+		s.recordAssignment(ctx, pr.PullRequestID, userID, domain.AssignmentSourceRebalance)
+	}
+
+	s.lg.InfoContext(ctx, "user activated with review top-up", slog.String("user_id", userID), slog.Any("assigned_prs", assigned))
+	return user, assigned, nil
+}
+
+// topUpReview добавляет userID ревьюером PR, если под блокировкой PR всё ещё открыт и недоукомплектован.
+// nil без ошибки - PR пропущен
+func (s *UserService) topUpReview(ctx context.Context, candidate domain.UnderReviewedPR, userID string) (*domain.PullRequest, error) {
+	if s.cfg.ExcludeGroupAuthors && candidate.GroupID != "" {
+		authors, err := s.prRepo.GetOpenGroupAuthors(ctx, candidate.GroupID, candidate.PullRequestID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get group authors: %w", err)
+		}
+		if slices.Contains(authors, userID) {
+			return nil, nil
+		}
+	}
+
+	if err := s.prRepo.LockPullRequest(ctx, candidate.PullRequestID); err != nil {
+		return nil, err
+	}
+	pr, err := s.prRepo.GetPullRequestByID(ctx, candidate.PullRequestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR: %w", err)
+	}
+	if pr.Status != domain.PRStatusOpen || len(pr.AssignedReviewers) >= s.reviewersPerPR() ||
+		slices.Contains(pr.AssignedReviewers, userID) {
+		return nil, nil
+	}
+
+	err = s.prRepo.AssignReviewer(ctx, pr.PullRequestID, userID, domain.AssignmentSourceRebalance)
+	switch {
+	case errors.Is(err, repository.ErrNotOpen) || errors.Is(err, repository.ErrAlreadyExists):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to assign reviewer: %w", err)
+	}
+
+	pr.AssignedReviewers = append(pr.AssignedReviewers, userID)
+	return pr, nil
+}
+
+func (s *UserService) reviewersPerPR() int {
+	if s.cfg.ReviewersPerPR > 0 {
+		return s.cfg.ReviewersPerPR
+	}
+	return defaultReviewersPerPR
+}
+
+// SetIsActiveOrCreate работает как SetIsActive, но неизвестного пользователя создаёт в команде teamName
+// с нужным is_active. created - пользователь был создан этим вызовом. Если его конкурентно создал
+// другой запрос, статус обновляется как у существующего
+func (s *UserService) SetIsActiveOrCreate(
+	ctx context.Context,
+	member domain.TeamMember,
+	teamName string,
+) (*domain.User, bool, error) {
+	user, err := s.SetIsActive(ctx, member.UserID, member.IsActive)
+	if err == nil {
+		return user, false, nil
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, false, err
+	}
+
+	if err := auth.AuthorizeTeam(ctx, teamName); err != nil {
+		return nil, false, err
+	}
+
+	err = s.txManager.Do(ctx, func(txCtx context.Context) error {
+		if err := s.checkTeamSize(txCtx, teamName); err != nil {
+			return err
+		}
+		user, err = s.userRepo.Create(txCtx, member, teamName)
+		return err
+	})
+	switch {
+	case errors.Is(err, repository.ErrAlreadyExists):
+		user, err = s.SetIsActive(ctx, member.UserID, member.IsActive)
+		return user, false, err
+	case errors.Is(err, repository.ErrReferenceNotFound):
+		return nil, false, domain.ErrTeamNotFound
+	case errors.Is(err, domain.ErrTeamTooLarge):
+		return nil, false, err
+	case err != nil:
+		return nil, false, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	s.lg.InfoContext(ctx, "user created",
+		slog.String("user_id", member.UserID),
+		slog.String("team_name", teamName),
+		slog.Bool("is_active", member.IsActive))
+	return user, true, nil
+}
+
+// checkTeamSize проверяет, что команда с ещё одним участником не превысит MaxTeamMembers.
+// Лимит мягкий: параллельные добавления могут превысить его на число одновременных запросов
+func (s *UserService) checkTeamSize(ctx context.Context, teamName string) error {
+	if s.teams == nil || s.cfg.MaxTeamMembers <= 0 {
+		return nil
+	}
+
+	count, err := s.teams.CountMembers(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	return domain.CheckTeamSize(s.cfg.MaxTeamMembers, count+1)
+}
+
 func (s *UserService) deactivateUser(ctx context.Context, userID string) (*domain.User, error) {
 	var user *domain.User
+	var changes []reviewerChange
 
 	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
 		oldUser, err := s.userRepo.GetByID(txCtx, userID)
@@ -83,25 +383,20 @@ func (s *UserService) deactivateUser(ctx context.Context, userID string) (*domai
 			return nil
 		}
 
-		openPRs, err := s.prRepo.GetOpenPullRequestsByReviewer(txCtx, userID)
+		processed, released, err := s.releaseOpenReviews(txCtx, *oldUser)
 		if err != nil {
-			return fmt.Errorf("failed to get open PRs for reviewer: %w", err)
-		}
-
-		for _, prShort := range openPRs {
-			if err := s.handleReviewerReplacement(txCtx, prShort.PullRequestID, userID, oldUser.TeamName); err != nil {
-				return fmt.Errorf("failed to handle PR %s: %w", prShort.PullRequestID, err)
-			}
+			return err
 		}
+		changes = released
 
 		user, err = s.userRepo.SetIsActive(txCtx, userID, false)
 		if err != nil {
 			return fmt.Errorf("failed to deactivate user: %w", err)
 		}
 
-		s.lg.Info("user deactivated",
+		s.lg.InfoContext(txCtx, "user deactivated",
 			slog.String("user_id", userID),
-			slog.Int("prs_processed", len(openPRs)))
+			slog.Int("prs_processed", processed))
 
 		return nil
 	})
@@ -113,80 +408,508 @@ func (s *UserService) deactivateUser(ctx context.Context, userID string) (*domai
 		return nil, fmt.Errorf("failed to deactivate user: %w", err)
 	}
 
+	s.notifyChanges(changes)
+	s.recordChanges(ctx, changes)
+	return user, nil
+}
+
+// RemoveFromTeam убирает участника из команды. Его ревью в открытых PR переназначаются или снимаются
+// так же, как при деактивации, а смерженные PR не меняются: их список ревьюеров неизменяем
+func (s *UserService) RemoveFromTeam(ctx context.Context, teamName, userID string) (*domain.User, error) {
+	if err := auth.AuthorizeTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	var user *domain.User
+	var changes []reviewerChange
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		member, err := s.userRepo.GetByID(txCtx, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrUserNotFound
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if member.TeamName != teamName {
+			return domain.ErrUserNotFound
+		}
+
+		// неактивный участник тоже может остаться ревьюером открытого PR, поэтому проверяются все
+		processed, released, err := s.releaseOpenReviews(txCtx, *member)
+		if err != nil {
+			return err
+		}
+		changes = released
+
+		orphaned, err := s.handleOrphanedPRs(txCtx, userID)
+		if err != nil {
+			return err
+		}
+
+		user, err = s.userRepo.RemoveFromTeam(txCtx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to remove user from team: %w", err)
+		}
+
+		s.lg.InfoContext(txCtx, "user removed from team",
+			slog.String("user_id", userID),
+			slog.String("team_name", teamName),
+			slog.Int("prs_processed", processed),
+			slog.Any("orphaned_prs", orphaned),
+			slog.Bool("orphaned_closed", s.cfg.AutoCloseOrphanedPRs))
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyChanges(changes)
+	s.recordChanges(ctx, changes)
 	return user, nil
 }
 
+// handleOrphanedPRs закрывает или помечает открытые PR уходящего автора в зависимости от AutoCloseOrphanedPRs
+func (s *UserService) handleOrphanedPRs(ctx context.Context, authorID string) ([]string, error) {
+	if s.cfg.AutoCloseOrphanedPRs {
+		prIDs, err := s.prRepo.CloseOrphanedPullRequests(ctx, authorID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to close orphaned PRs: %w", err)
+		}
+		return prIDs, nil
+	}
+
+	prIDs, err := s.prRepo.MarkOrphanedPullRequests(ctx, authorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark orphaned PRs: %w", err)
+	}
+	return prIDs, nil
+}
+
+// reviewerChange - замена или снятие ревьюера при уходе пользователя, newReviewerID пуст, если заменить некем
+type reviewerChange struct {
+	pr            domain.PullRequest
+	oldReviewerID string
+	newReviewerID string
+}
+
+// releaseOpenReviews заменяет или снимает пользователя во всех его открытых PR и возвращает их число
+// и сделанные изменения для уведомлений после коммита
+func (s *UserService) releaseOpenReviews(ctx context.Context, user domain.User) (int, []reviewerChange, error) {
+	openPRs, err := s.prRepo.GetOpenPullRequestsByReviewer(ctx, user.UserID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get open PRs for reviewer: %w", err)
+	}
+
+	var changes []reviewerChange
+	for _, prShort := range openPRs {
+		change, err := s.handleReviewerReplacement(ctx, prShort.PullRequestID, user.UserID, user.TeamName)
+		if errors.Is(err, repository.ErrNotOpen) {
+			// PR смержили после выборки, список ревьюеров уже неизменяем
+			s.lg.InfoContext(ctx, "skipping PR that is no longer open",
+				slog.String("pr_id", prShort.PullRequestID),
+				slog.String("user_id", user.UserID))
+			continue
+		}
+		if errors.Is(err, domain.ErrNotAssigned) {
+			// ревьюера сняли с PR конкурентно, менять нечего
+			s.lg.InfoContext(ctx, "skipping PR the user no longer reviews",
+				slog.String("pr_id", prShort.PullRequestID),
+				slog.String("user_id", user.UserID))
+			continue
+		}
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to handle PR %s: %w", prShort.PullRequestID, err)
+		}
+		changes = append(changes, change)
+	}
+
+	return len(openPRs), changes, nil
+}
+
+func (s *UserService) notifyChanges(changes []reviewerChange) {
+	if s.notifier == nil {
+		return
+	}
+	for _, change := range changes {
+		if change.newReviewerID == "" {
+			s.notifier.NotifyNoCandidate(change.pr, change.oldReviewerID)
+			continue
+		}
+		s.notifier.NotifyReassignment(change.pr, change.oldReviewerID, change.newReviewerID)
+	}
+}
+
+// recordChanges отправляет снятие ушедшего ревьюера и назначение его замены подписчикам PR и в аудит.
+// Отмена запроса клиентом не прерывает запись
+func (s *UserService) recordChanges(ctx context.Context, changes []reviewerChange) {
+	ctx = context.WithoutCancel(ctx)
+	for _, change := range changes {
+		s.recordRemoval(ctx, change.pr.PullRequestID, change.oldReviewerID)
+		if change.newReviewerID != "" {
+			s.recordAssignment(ctx, change.pr.PullRequestID, change.newReviewerID, domain.AssignmentSourceReassignment)
+		}
+	}
+}
+
+func (s *UserService) recordRemoval(ctx context.Context, prID, userID string) {
+	now := s.clock.Now()
+	if s.live != nil {
+		s.live.Publish(domain.PRUpdate{Type: domain.PRUpdateReviewerRemoved, PullRequestID: prID, UserID: userID, OccurredAt: now})
+	}
+	if s.audit == nil {
+		return
+	}
+	record := domain.AuditRecord{PullRequestID: prID, UserID: userID, OccurredAt: now}
+	if err := s.audit.RecordRemoval(ctx, record); err != nil {
+		s.lg.WarnContext(ctx, "failed to record reviewer removal in audit",
+			slog.String("pr_id", prID), slog.String("user_id", userID), slog.Any("error", err))
+	}
+}
+
+func (s *UserService) recordAssignment(ctx context.Context, prID, userID string, source domain.AssignmentSource) {
+	now := s.clock.Now()
+	if s.live != nil {
+		s.live.Publish(domain.PRUpdate{
+			Type: domain.PRUpdateReviewerAssigned, PullRequestID: prID, UserID: userID, Source: source, OccurredAt: now,
+		})
+	}
+	if s.audit == nil {
+		return
+	}
+	record := domain.AuditRecord{PullRequestID: prID, UserID: userID, Source: source, OccurredAt: now}
+	if err := s.audit.RecordAssignment(context.WithoutCancel(ctx), record); err != nil {
+		s.lg.WarnContext(ctx, "failed to record reviewer assignment in audit",
+			slog.String("pr_id", prID), slog.String("user_id", userID), slog.Any("error", err))
+	}
+}
+
+// authorizeMember пускает ключ команды только к её участникам.
+// Для админского ключа и без аутентификации пользователь не загружается
+func (s *UserService) authorizeMember(ctx context.Context, userID string) error {
+	if !auth.IsTeamScoped(ctx) {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return auth.AuthorizeTeam(ctx, user.TeamName)
+}
+
 func (s *UserService) handleReviewerReplacement(
 	ctx context.Context,
 	prID string,
 	oldUserID string,
 	teamName string,
-) error {
+) (reviewerChange, error) {
 	pr, err := s.prRepo.GetPullRequestByID(ctx, prID)
 	if err != nil {
-		return fmt.Errorf("failed to get PR %s: %w", prID, err)
+		return reviewerChange{}, fmt.Errorf("failed to get PR %s: %w", prID, err)
 	}
+	change := reviewerChange{pr: *pr, oldReviewerID: oldUserID}
 
 	excludeIDs := []string{pr.AuthorID}
 	excludeIDs = append(excludeIDs, pr.AssignedReviewers...)
 
 	candidates, err := s.userRepo.GetActiveByTeam(ctx, teamName, excludeIDs)
 	if err != nil {
-		s.lg.Warn("failed to get replacement candidates, removing reviewer",
+		s.lg.WarnContext(ctx, "failed to get replacement candidates, removing reviewer",
 			slog.String("pr_id", prID),
 			slog.String("user_id", oldUserID),
 			slog.Any("error", err))
-		return s.removeReviewer(ctx, prID, oldUserID)
+		return change, s.removeReviewer(ctx, prID, oldUserID)
 	}
 
 	if len(candidates) > 0 {
 		newReviewer, err := utils.SelectRandomReviewer(candidates)
 		if err != nil {
-			s.lg.Warn("failed to select reviewer, removing",
+			s.lg.WarnContext(ctx, "failed to select reviewer, removing",
 				slog.String("pr_id", prID),
 				slog.String("user_id", oldUserID))
-			return s.removeReviewer(ctx, prID, oldUserID)
+			return change, s.removeReviewer(ctx, prID, oldUserID)
 		}
 
-		if err := s.prRepo.RemoveReviewer(ctx, prID, oldUserID); err != nil {
-			return fmt.Errorf("failed to remove old reviewer: %w", err)
+		removed, err := s.prRepo.RemoveReviewer(ctx, prID, oldUserID)
+		if err != nil {
+			return change, fmt.Errorf("failed to remove old reviewer: %w", err)
+		}
+		if !removed {
+			return change, domain.ErrNotAssigned
 		}
 
-		if err := s.prRepo.AssignReviewer(ctx, prID, newReviewer.UserID); err != nil {
-			return fmt.Errorf("failed to assign new reviewer: %w", err)
+		if err := s.prRepo.AssignReviewer(ctx, prID, newReviewer.UserID, domain.AssignmentSourceReassignment); err != nil {
+			return change, fmt.Errorf("failed to assign new reviewer: %w", err)
 		}
 
-		s.lg.Info("reviewer reassigned during deactivation",
+		if err := s.prRepo.IncrementReassignmentCount(ctx, prID); err != nil {
+			return change, fmt.Errorf("failed to count reassignment: %w", err)
+		}
+
+		s.lg.InfoContext(ctx, "reviewer reassigned during deactivation",
 			slog.String("pr_id", prID),
 			slog.String("old_user_id", oldUserID),
 			slog.String("new_user_id", newReviewer.UserID))
-		return nil
+		change.newReviewerID = newReviewer.UserID
+		return change, nil
 	}
 
-	s.lg.Info("no replacement candidates found, removing reviewer",
+	s.lg.InfoContext(ctx, "no replacement candidates found, removing reviewer",
 		slog.String("pr_id", prID),
 		slog.String("user_id", oldUserID))
-	return s.removeReviewer(ctx, prID, oldUserID)
+	return change, s.removeReviewer(ctx, prID, oldUserID)
 }
 
 func (s *UserService) removeReviewer(ctx context.Context, prID, userID string) error {
-	if err := s.prRepo.RemoveReviewer(ctx, prID, userID); err != nil {
+	removed, err := s.prRepo.RemoveReviewer(ctx, prID, userID)
+	if err != nil {
 		return fmt.Errorf("failed to remove reviewer: %w", err)
 	}
+	if !removed {
+		return domain.ErrNotAssigned
+	}
 
-	s.lg.Info("removed inactive reviewer from PR",
+	s.lg.InfoContext(ctx, "removed inactive reviewer from PR",
 		slog.String("pr_id", prID),
 		slog.String("user_id", userID))
 
 	return nil
 }
 
-func (s *UserService) GetReviewPRsByUserID(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
-	prs, err := s.prRepo.GetPullRequestsByReviewer(ctx, userID)
+// GetReviewPRsByUserID - PR, на которые назначен ревьюер. filter.Now подставляется из часов сервиса,
+// чтобы истечение snooze не зависело от часов клиента
+func (s *UserService) GetReviewPRsByUserID(
+	ctx context.Context,
+	userID string,
+	filter domain.ReviewFilter,
+) ([]domain.PullRequestShort, error) {
+	filter.Now = s.clock.Now()
+	prs, err := s.prRepo.GetPullRequestsByReviewer(ctx, userID, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get review PRs: %w", err)
 	}
 
-	s.lg.Debug("retrieved review PRs", slog.String("user_id", userID), slog.Int("count", len(prs)))
+	s.lg.DebugContext(ctx, "retrieved review PRs", slog.String("user_id", userID), slog.Int("count", len(prs)))
 	return prs, nil
 }
+
+// StreamReviewPRsByUserID - потоковый вариант GetReviewPRsByUserID для пользователей с большим числом ревью
+func (s *UserService) StreamReviewPRsByUserID(
+	ctx context.Context,
+	userID string,
+	filter domain.ReviewFilter,
+	fn func(domain.PullRequestShort) error,
+) error {
+	filter.Now = s.clock.Now()
+	if err := s.prRepo.StreamPullRequestsByReviewer(ctx, userID, filter, fn); err != nil {
+		return fmt.Errorf("failed to stream review PRs: %w", err)
+	}
+
+	return nil
+}
+
+func (s *UserService) GetUserReviewTimeline(
+	ctx context.Context,
+	userID string,
+	from, to *time.Time,
+	page domain.Page,
+) ([]domain.ReviewerEvent, error) {
+	if from != nil && to != nil && from.After(*to) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	events, err := s.prRepo.GetUserReviewTimeline(ctx, userID, from, to, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review timeline: %w", err)
+	}
+
+	s.lg.DebugContext(ctx, "retrieved review timeline", slog.String("user_id", userID), slog.Int("count", len(events)))
+	return events, nil
+}
+
+// GetAssignmentEvents отдаёт до MaxAssignmentEvents назначений и снятий ревьюеров после since.
+// Порция не обрывается посреди событий с одним временем, чтобы запрос с NextSince их не пропустил.
+// Непустой source оставляет только назначения с этим источником, since старше EventsRetention отклоняется
+func (s *UserService) GetAssignmentEvents(
+	ctx context.Context,
+	since time.Time,
+	source domain.AssignmentSource,
+) (domain.ReviewerEventsPage, error) {
+	if s.cfg.EventsRetention > 0 && since.Before(s.clock.Now().Add(-s.cfg.EventsRetention)) {
+		s.lg.DebugContext(ctx, "since is older than retention", slog.Time("since", since))
+		return domain.ReviewerEventsPage{}, &domain.ValidationError{
+			Violations: []domain.FieldViolation{{Field: "since", Rule: domain.RuleRetention}},
+		}
+	}
+
+	events, err := s.prRepo.GetReviewerEventsSince(ctx, since, source, MaxAssignmentEvents+1)
+	if err != nil {
+		return domain.ReviewerEventsPage{}, fmt.Errorf("failed to get reviewer events: %w", err)
+	}
+
+	page := domain.ReviewerEventsPage{Events: events, NextSince: since}
+	if len(events) > MaxAssignmentEvents {
+		page.HasMore = true
+		page.Events = s.trimToWatermark(events[:MaxAssignmentEvents], events[MaxAssignmentEvents].CreatedAt)
+	}
+	if n := len(page.Events); n > 0 {
+		page.NextSince = page.Events[n-1].CreatedAt
+	}
+
+	s.lg.DebugContext(ctx, "retrieved assignment events", slog.Int("count", len(page.Events)), slog.Bool("has_more", page.HasMore))
+	return page, nil
+}
+
+// trimToWatermark убирает с конца порции события со временем next - первого не вошедшего события
+func (s *UserService) trimToWatermark(events []domain.ReviewerEvent, next time.Time) []domain.ReviewerEvent {
+	n := len(events)
+	for n > 0 && events[n-1].CreatedAt.Equal(next) {
+		n--
+	}
+	if n == 0 {
+		s.lg.Warn("assignment events page shares one timestamp, following events with it will be skipped",
+			slog.Time("created_at", next))
+		return events
+	}
+	return events[:n]
+}
+
+// ValidateUserIDs делит userIDs на известные и неизвестные одним запросом, сохраняя порядок и убирая повторы.
+// Пользователь, удалённый из команды, считается известным
+func (s *UserService) ValidateUserIDs(ctx context.Context, userIDs []string) (existing, missing []string, err error) {
+	users, err := s.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	known := make(map[string]struct{}, len(users))
+	for _, u := range users {
+		known[u.UserID] = struct{}{}
+	}
+
+	existing, missing = []string{}, []string{}
+	seen := make(map[string]struct{}, len(userIDs))
+	for _, id := range userIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+
+		if _, ok := known[id]; ok {
+			existing = append(existing, id)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	s.lg.DebugContext(ctx, "validated user ids", slog.Int("existing", len(existing)), slog.Int("missing", len(missing)))
+	return existing, missing, nil
+}
+
+// GetFormerReviews возвращает PR, на которые пользователь когда-либо назначался, для аудита после удаления
+// из команды. Удалённый пользователь остаётся в users, поэтому 404 - только для неизвестного user_id
+func (s *UserService) GetFormerReviews(ctx context.Context, userID string) ([]domain.FormerReview, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	reviews, err := s.prRepo.GetFormerReviews(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get former reviews: %w", err)
+	}
+
+	s.lg.DebugContext(ctx, "retrieved former reviews", slog.String("user_id", userID), slog.Int("count", len(reviews)))
+	return reviews, nil
+}
+
+// GetOverview собирает профиль и открытые PR пользователя в одной read-only транзакции, чтобы списки
+// были согласованы между собой. Запросы идут последовательно: транзакция держит одно соединение
+func (s *UserService) GetOverview(ctx context.Context, userID string) (*domain.UserOverview, error) {
+	var overview domain.UserOverview
+	err := s.txManager.DoReadOnly(ctx, func(txCtx context.Context) error {
+		user, err := s.userRepo.GetByID(txCtx, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrUserNotFound
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		overview.User = *user
+
+		if overview.AuthoredOpen, err = s.prRepo.GetOpenAuthoredPullRequests(txCtx, userID); err != nil {
+			return fmt.Errorf("failed to get authored PRs: %w", err)
+		}
+		if overview.ReviewingOpen, err = s.prRepo.GetOpenReviewingPullRequests(txCtx, userID); err != nil {
+			return fmt.Errorf("failed to get reviewing PRs: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.lg.DebugContext(ctx, "retrieved user overview", slog.String("user_id", userID),
+		slog.Int("authored_open", len(overview.AuthoredOpen)), slog.Int("reviewing_open", len(overview.ReviewingOpen)))
+	return &overview, nil
+}
+
+// пустой workingHours сбрасывает рабочее окно
+func (s *UserService) SetSchedule(ctx context.Context, userID, workingHours, timezone string) (*domain.User, error) {
+	if workingHours == "" && timezone != "" {
+		return nil, domain.ErrInvalidSchedule
+	}
+	if workingHours != "" {
+		if _, err := domain.ParseWorkingHours(workingHours, timezone); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.authorizeMember(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.SetSchedule(ctx, userID, workingHours, timezone)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to set user schedule: %w", err)
+	}
+
+	s.lg.InfoContext(ctx, "user schedule updated",
+		slog.String("user_id", userID),
+		slog.String("working_hours", workingHours),
+		slog.String("timezone", timezone))
+	return user, nil
+}
+
+// SetCapacity задаёт множитель review_capacity, который учитывается при выборе наименее загруженного ревьюера
+func (s *UserService) SetCapacity(ctx context.Context, userID string, capacity float64) (*domain.User, error) {
+	if capacity <= 0 || capacity > domain.MaxReviewCapacity {
+		return nil, domain.ErrInvalidReviewCapacity
+	}
+
+	if err := s.authorizeMember(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.SetReviewCapacity(ctx, userID, capacity)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to set review capacity: %w", err)
+	}
+
+	s.lg.InfoContext(ctx, "user review capacity updated", slog.String("user_id", userID), slog.Float64("review_capacity", capacity))
+	return user, nil
+}
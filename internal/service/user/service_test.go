@@ -3,28 +3,33 @@ package users
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"avito_backend_task/internal/auth"
 	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/live"
 	"avito_backend_task/internal/repository"
 	"avito_backend_task/internal/service/user/mocks"
+	"avito_backend_task/pkg/clock"
 
 	dbmocks "avito_backend_task/pkg/db/mocks"
 )
 
-func setupTestService() (*UserService, *mocks.UserRepository, *mocks.PullRequestRepository, *dbmocks.MockTransactionManager) {
+func setupTestService(opts ...Option) (*UserService, *mocks.UserRepository, *mocks.PullRequestRepository, *dbmocks.MockTransactionManager) {
 	userRepo := new(mocks.UserRepository)
 	prRepo := new(mocks.PullRequestRepository)
 	txManager := dbmocks.NewMockTransactionManager()
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 
-	service := NewUserService(userRepo, prRepo, txManager, logger)
+	service := NewUserService(userRepo, prRepo, txManager, logger, opts...)
 	return service, userRepo, prRepo, txManager
 }
 
@@ -131,8 +136,9 @@ func TestUserService_SetIsActive(t *testing.T) {
 				}
 				userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "user3", "reviewer2"}).Return(candidates, nil)
 
-				prRepo.On("RemoveReviewer", mock.Anything, "pr1", "user3").Return(nil)
-				prRepo.On("AssignReviewer", mock.Anything, "pr1", "candidate1").Return(nil)
+				prRepo.On("RemoveReviewer", mock.Anything, "pr1", "user3").Return(true, nil)
+				prRepo.On("AssignReviewer", mock.Anything, "pr1", "candidate1", domain.AssignmentSourceReassignment).Return(nil)
+				prRepo.On("IncrementReassignmentCount", mock.Anything, "pr1").Return(nil)
 
 				deactivatedUser := &domain.User{
 					UserID:   "user3",
@@ -249,7 +255,7 @@ func TestUserService_SetIsActive(t *testing.T) {
 
 				userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "user7", "reviewer2"}).Return([]domain.User{}, nil)
 
-				prRepo.On("RemoveReviewer", mock.Anything, "pr2", "user7").Return(nil)
+				prRepo.On("RemoveReviewer", mock.Anything, "pr2", "user7").Return(true, nil)
 
 				deactivatedUser := &domain.User{
 					UserID:   "user7",
@@ -281,6 +287,190 @@ func TestUserService_SetIsActive(t *testing.T) {
 		})
 	}
 }
+func TestUserService_GetFormerReviews(t *testing.T) {
+	removedAt := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("removed user keeps history", func(t *testing.T) {
+		service, userRepo, prRepo, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "u1").Return(&domain.User{UserID: "u1", TeamName: "backend"}, nil)
+		reviews := []domain.FormerReview{{PullRequestID: "pr1", FirstAssignedAt: &removedAt, LastRemovedAt: &removedAt}}
+		prRepo.On("GetFormerReviews", mock.Anything, "u1").Return(reviews, nil)
+
+		got, err := service.GetFormerReviews(context.Background(), "u1")
+
+		require.NoError(t, err)
+		assert.Equal(t, reviews, got)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		service, userRepo, prRepo, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "ghost").Return(nil, repository.ErrNotFound)
+
+		_, err := service.GetFormerReviews(context.Background(), "ghost")
+
+		require.ErrorIs(t, err, domain.ErrUserNotFound)
+		prRepo.AssertNotCalled(t, "GetFormerReviews", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_ValidateUserIDs(t *testing.T) {
+	t.Run("keeps request order and dedupes", func(t *testing.T) {
+		service, userRepo, _, _ := setupTestService()
+		userRepo.On("GetByIDs", mock.Anything, []string{"u3", "ghost", "u1", "u3"}).
+			Return([]domain.User{{UserID: "u1"}, {UserID: "u3"}}, nil)
+
+		existing, missing, err := service.ValidateUserIDs(context.Background(), []string{"u3", "ghost", "u1", "u3"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u3", "u1"}, existing)
+		assert.Equal(t, []string{"ghost"}, missing)
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		service, userRepo, _, _ := setupTestService()
+		userRepo.On("GetByIDs", mock.Anything, []string{"ghost"}).Return(nil, nil)
+
+		existing, missing, err := service.ValidateUserIDs(context.Background(), []string{"ghost"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{}, existing)
+		assert.Equal(t, []string{"ghost"}, missing)
+	})
+}
+
+func TestUserService_GetAssignmentEvents(t *testing.T) {
+	since := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	eventsAt := func(times ...time.Time) []domain.ReviewerEvent {
+		events := make([]domain.ReviewerEvent, len(times))
+		for i, at := range times {
+			events[i] = domain.ReviewerEvent{EventID: int64(i + 1), PullRequestID: "pr1", UserID: "u1", CreatedAt: at}
+		}
+		return events
+	}
+
+	t.Run("empty window keeps since", func(t *testing.T) {
+		service, _, prRepo, _ := setupTestService()
+		prRepo.On("GetReviewerEventsSince", mock.Anything, since, domain.AssignmentSource(""), MaxAssignmentEvents+1).Return(nil, nil)
+
+		page, err := service.GetAssignmentEvents(context.Background(), since, "")
+
+		require.NoError(t, err)
+		assert.Empty(t, page.Events)
+		assert.Equal(t, since, page.NextSince)
+		assert.False(t, page.HasMore)
+	})
+
+	t.Run("watermark is last event", func(t *testing.T) {
+		service, _, prRepo, _ := setupTestService()
+		events := eventsAt(since.Add(time.Second), since.Add(2*time.Second))
+		prRepo.On("GetReviewerEventsSince", mock.Anything, since, domain.AssignmentSource(""), MaxAssignmentEvents+1).Return(events, nil)
+
+		page, err := service.GetAssignmentEvents(context.Background(), since, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, events, page.Events)
+		assert.Equal(t, since.Add(2*time.Second), page.NextSince)
+		assert.False(t, page.HasMore)
+	})
+
+	t.Run("capped page does not split one timestamp", func(t *testing.T) {
+		service, _, prRepo, _ := setupTestService()
+		times := make([]time.Time, MaxAssignmentEvents+1)
+		for i := range times {
+			times[i] = since.Add(time.Duration(i+1) * time.Millisecond)
+		}
+		// последние два события в порции и первое за ней созданы в одной транзакции
+		tail := since.Add(time.Hour)
+		times[MaxAssignmentEvents-2], times[MaxAssignmentEvents-1], times[MaxAssignmentEvents] = tail, tail, tail
+		prRepo.On("GetReviewerEventsSince", mock.Anything, since, domain.AssignmentSource(""), MaxAssignmentEvents+1).Return(eventsAt(times...), nil)
+
+		page, err := service.GetAssignmentEvents(context.Background(), since, "")
+
+		require.NoError(t, err)
+		assert.True(t, page.HasMore)
+		require.Len(t, page.Events, MaxAssignmentEvents-2)
+		assert.Equal(t, times[MaxAssignmentEvents-3], page.NextSince)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		service, _, prRepo, _ := setupTestService()
+		prRepo.On("GetReviewerEventsSince", mock.Anything, since, domain.AssignmentSource(""), MaxAssignmentEvents+1).Return(nil, errors.New("db down"))
+
+		_, err := service.GetAssignmentEvents(context.Background(), since, "")
+
+		require.Error(t, err)
+	})
+
+	t.Run("retention is measured by the service clock", func(t *testing.T) {
+		cfg := WithConfig(Config{EventsRetention: 24 * time.Hour})
+		service, _, prRepo, _ := setupTestService(cfg, WithClock(clock.NewFake(since.Add(24*time.Hour+time.Second))))
+
+		_, err := service.GetAssignmentEvents(context.Background(), since, "")
+
+		var verr *domain.ValidationError
+		require.ErrorAs(t, err, &verr)
+		assert.Equal(t, []domain.FieldViolation{{Field: "since", Rule: domain.RuleRetention}}, verr.Violations)
+		prRepo.AssertNotCalled(t, "GetReviewerEventsSince", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		service, _, prRepo, _ = setupTestService(cfg, WithClock(clock.NewFake(since.Add(24*time.Hour))))
+		prRepo.On("GetReviewerEventsSince", mock.Anything, since, domain.AssignmentSource(""), MaxAssignmentEvents+1).Return(nil, nil)
+
+		_, err = service.GetAssignmentEvents(context.Background(), since, "")
+
+		require.NoError(t, err)
+	})
+}
+
+func TestUserService_GetOverview(t *testing.T) {
+	createdAt := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	assignedAt := createdAt.Add(time.Hour)
+
+	t.Run("profile with authored and reviewing PRs", func(t *testing.T) {
+		service, userRepo, prRepo, _ := setupTestService()
+		user := &domain.User{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true}
+		authored := []domain.OpenPullRequest{{
+			PullRequestShort: domain.PullRequestShort{PullRequestID: "pr1", AuthorID: "u1", Status: domain.PRStatusOpen},
+			CreatedAt:        createdAt,
+		}}
+		reviewing := []domain.OpenPullRequest{{
+			PullRequestShort: domain.PullRequestShort{PullRequestID: "pr2", AuthorID: "u2", Status: domain.PRStatusOpen},
+			CreatedAt:        createdAt,
+			AssignedAt:       &assignedAt,
+		}}
+		userRepo.On("GetByID", mock.Anything, "u1").Return(user, nil)
+		prRepo.On("GetOpenAuthoredPullRequests", mock.Anything, "u1").Return(authored, nil)
+		prRepo.On("GetOpenReviewingPullRequests", mock.Anything, "u1").Return(reviewing, nil)
+
+		overview, err := service.GetOverview(context.Background(), "u1")
+
+		require.NoError(t, err)
+		assert.Equal(t, *user, overview.User)
+		assert.Equal(t, authored, overview.AuthoredOpen)
+		assert.Equal(t, reviewing, overview.ReviewingOpen)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		service, userRepo, prRepo, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "ghost").Return(nil, repository.ErrNotFound)
+
+		_, err := service.GetOverview(context.Background(), "ghost")
+
+		require.ErrorIs(t, err, domain.ErrUserNotFound)
+		prRepo.AssertNotCalled(t, "GetOpenAuthoredPullRequests", mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		service, userRepo, prRepo, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "u1").Return(&domain.User{UserID: "u1"}, nil)
+		prRepo.On("GetOpenAuthoredPullRequests", mock.Anything, "u1").Return(nil, nil)
+		prRepo.On("GetOpenReviewingPullRequests", mock.Anything, "u1").Return(nil, errors.New("db down"))
+
+		_, err := service.GetOverview(context.Background(), "u1")
+
+		require.Error(t, err)
+	})
+}
+
 func TestUserService_GetReviewPRsByUserID(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -307,7 +497,7 @@ func TestUserService_GetReviewPRsByUserID(t *testing.T) {
 						Status:          domain.PRStatusMerged,
 					},
 				}
-				prRepo.On("GetPullRequestsByReviewer", mock.Anything, "reviewer1").Return(prs, nil)
+				prRepo.On("GetPullRequestsByReviewer", mock.Anything, "reviewer1", mock.Anything).Return(prs, nil)
 			},
 			expectedError: nil,
 			validate: func(t *testing.T, prs []domain.PullRequestShort, err error) {
@@ -321,7 +511,7 @@ func TestUserService_GetReviewPRsByUserID(t *testing.T) {
 			name:   "no PRs for reviewer",
 			userID: "reviewer2",
 			setupMocks: func(prRepo *mocks.PullRequestRepository) {
-				prRepo.On("GetPullRequestsByReviewer", mock.Anything, "reviewer2").Return([]domain.PullRequestShort{}, nil)
+				prRepo.On("GetPullRequestsByReviewer", mock.Anything, "reviewer2", mock.Anything).Return([]domain.PullRequestShort{}, nil)
 			},
 			expectedError: nil,
 			validate: func(t *testing.T, prs []domain.PullRequestShort, err error) {
@@ -333,7 +523,7 @@ func TestUserService_GetReviewPRsByUserID(t *testing.T) {
 			name:   "repository error",
 			userID: "reviewer3",
 			setupMocks: func(prRepo *mocks.PullRequestRepository) {
-				prRepo.On("GetPullRequestsByReviewer", mock.Anything, "reviewer3").Return(nil, errors.New("db error"))
+				prRepo.On("GetPullRequestsByReviewer", mock.Anything, "reviewer3", mock.Anything).Return(nil, errors.New("db error"))
 			},
 			expectedError: nil,
 			validate: func(t *testing.T, prs []domain.PullRequestShort, err error) {
@@ -349,10 +539,648 @@ func TestUserService_GetReviewPRsByUserID(t *testing.T) {
 			service, _, prRepo, _ := setupTestService()
 			tt.setupMocks(prRepo)
 
-			result, err := service.GetReviewPRsByUserID(context.Background(), tt.userID)
+			result, err := service.GetReviewPRsByUserID(context.Background(), tt.userID, domain.ReviewFilter{})
 
 			tt.validate(t, result, err)
 			prRepo.AssertExpectations(t)
 		})
 	}
 }
+
+func TestUserService_GetReviewPRsByUserID_SnoozeUsesServiceClock(t *testing.T) {
+	now := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+	service, _, prRepo, _ := setupTestService(WithClock(clock.NewFake(now)))
+	expected := domain.ReviewFilter{Status: domain.PRStatusOpen, Now: now}
+	prRepo.On("GetPullRequestsByReviewer", mock.Anything, "reviewer1", expected).Return([]domain.PullRequestShort{}, nil).Once()
+
+	// Now из запроса не используется: истечение snooze определяют часы сервиса
+	_, err := service.GetReviewPRsByUserID(context.Background(), "reviewer1", domain.ReviewFilter{
+		Status: domain.PRStatusOpen, Now: now.Add(time.Hour),
+	})
+
+	require.NoError(t, err)
+	prRepo.AssertExpectations(t)
+}
+
+func TestUserService_SetIsActiveOrCreate(t *testing.T) {
+	member := domain.TeamMember{UserID: "u9", Username: "New", IsActive: true}
+	created := &domain.User{UserID: "u9", Username: "New", TeamName: "backend", IsActive: true}
+
+	tests := []struct {
+		name        string
+		setupMocks  func(*mocks.UserRepository)
+		wantCreated bool
+		wantErr     error
+	}{
+		{
+			name: "existing user updated",
+			setupMocks: func(userRepo *mocks.UserRepository) {
+				userRepo.On("SetIsActive", mock.Anything, "u9", true).Return(created, nil)
+			},
+		},
+		{
+			name: "missing user created",
+			setupMocks: func(userRepo *mocks.UserRepository) {
+				userRepo.On("SetIsActive", mock.Anything, "u9", true).Return(nil, repository.ErrNotFound)
+				userRepo.On("Create", mock.Anything, member, "backend").Return(created, nil)
+			},
+			wantCreated: true,
+		},
+		{
+			name: "created concurrently",
+			setupMocks: func(userRepo *mocks.UserRepository) {
+				userRepo.On("SetIsActive", mock.Anything, "u9", true).Return(nil, repository.ErrNotFound).Once()
+				userRepo.On("Create", mock.Anything, member, "backend").Return(nil, repository.ErrAlreadyExists)
+				userRepo.On("SetIsActive", mock.Anything, "u9", true).Return(created, nil).Once()
+			},
+		},
+		{
+			name: "team not found",
+			setupMocks: func(userRepo *mocks.UserRepository) {
+				userRepo.On("SetIsActive", mock.Anything, "u9", true).Return(nil, repository.ErrNotFound)
+				userRepo.On("Create", mock.Anything, member, "backend").
+					Return(nil, fmt.Errorf("users_team_name_fkey: %w", repository.ErrReferenceNotFound))
+			},
+			wantErr: domain.ErrTeamNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, userRepo, _, _ := setupTestService()
+			tt.setupMocks(userRepo)
+
+			user, wasCreated, err := service.SetIsActiveOrCreate(context.Background(), member, "backend")
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, user)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, created, user)
+			}
+			assert.Equal(t, tt.wantCreated, wasCreated)
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_SetIsActiveOrCreate_MaxTeamMembers(t *testing.T) {
+	member := domain.TeamMember{UserID: "u9", Username: "New", IsActive: true}
+
+	t.Run("last free slot", func(t *testing.T) {
+		teams := new(mocks.TeamRepository)
+		service, userRepo, _, _ := setupTestService(WithConfig(Config{MaxTeamMembers: 3}), WithTeams(teams))
+		userRepo.On("SetIsActive", mock.Anything, "u9", true).Return(nil, repository.ErrNotFound)
+		teams.On("CountMembers", mock.Anything, "backend").Return(2, nil)
+		userRepo.On("Create", mock.Anything, member, "backend").
+			Return(&domain.User{UserID: "u9", TeamName: "backend", IsActive: true}, nil)
+
+		_, wasCreated, err := service.SetIsActiveOrCreate(context.Background(), member, "backend")
+
+		require.NoError(t, err)
+		assert.True(t, wasCreated)
+	})
+
+	t.Run("team is full", func(t *testing.T) {
+		teams := new(mocks.TeamRepository)
+		service, userRepo, _, _ := setupTestService(WithConfig(Config{MaxTeamMembers: 3}), WithTeams(teams))
+		userRepo.On("SetIsActive", mock.Anything, "u9", true).Return(nil, repository.ErrNotFound)
+		teams.On("CountMembers", mock.Anything, "backend").Return(3, nil)
+
+		_, _, err := service.SetIsActiveOrCreate(context.Background(), member, "backend")
+
+		require.ErrorIs(t, err, domain.ErrTeamTooLarge)
+		var conflict *domain.ConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, &domain.TeamSizeLimit{Limit: 3, Size: 4}, conflict.Payload)
+		userRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_SetIsActiveOrCreate_ForeignTeam(t *testing.T) {
+	service, userRepo, _, _ := setupTestService()
+	userRepo.On("GetByID", mock.Anything, "u9").Return(nil, repository.ErrNotFound)
+
+	ctx := auth.WithScope(context.Background(), auth.Scope{Team: "frontend"})
+	member := domain.TeamMember{UserID: "u9", Username: "New", IsActive: true}
+	_, _, err := service.SetIsActiveOrCreate(ctx, member, "backend")
+
+	require.ErrorIs(t, err, domain.ErrForbidden)
+	userRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserService_SetSchedule(t *testing.T) {
+	tests := []struct {
+		name          string
+		workingHours  string
+		timezone      string
+		setupMocks    func(*mocks.UserRepository)
+		expectedError error
+	}{
+		{
+			name:         "set schedule",
+			workingHours: "Mon-Fri 09:00-18:00",
+			timezone:     "Europe/Moscow",
+			setupMocks: func(userRepo *mocks.UserRepository) {
+				userRepo.On("SetSchedule", mock.Anything, "user1", "Mon-Fri 09:00-18:00", "Europe/Moscow").
+					Return(&domain.User{UserID: "user1", WorkingHours: "Mon-Fri 09:00-18:00", Timezone: "Europe/Moscow"}, nil)
+			},
+		},
+		{
+			name: "clear schedule",
+			setupMocks: func(userRepo *mocks.UserRepository) {
+				userRepo.On("SetSchedule", mock.Anything, "user1", "", "").Return(&domain.User{UserID: "user1"}, nil)
+			},
+		},
+		{
+			name:          "invalid format",
+			workingHours:  "weekdays 9-6",
+			setupMocks:    func(userRepo *mocks.UserRepository) {},
+			expectedError: domain.ErrInvalidSchedule,
+		},
+		{
+			name:          "unknown timezone",
+			workingHours:  "Mon-Fri 09:00-18:00",
+			timezone:      "Nowhere/City",
+			setupMocks:    func(userRepo *mocks.UserRepository) {},
+			expectedError: domain.ErrInvalidSchedule,
+		},
+		{
+			name:          "timezone without hours",
+			timezone:      "UTC",
+			setupMocks:    func(userRepo *mocks.UserRepository) {},
+			expectedError: domain.ErrInvalidSchedule,
+		},
+		{
+			name:         "user not found",
+			workingHours: "Mon-Fri 09:00-18:00",
+			setupMocks: func(userRepo *mocks.UserRepository) {
+				userRepo.On("SetSchedule", mock.Anything, "user1", "Mon-Fri 09:00-18:00", "").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: domain.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, userRepo, _, _ := setupTestService()
+			tt.setupMocks(userRepo)
+
+			user, err := service.SetSchedule(context.Background(), "user1", tt.workingHours, tt.timezone)
+
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, user)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.workingHours, user.WorkingHours)
+			}
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_SetCapacity(t *testing.T) {
+	t.Run("set capacity", func(t *testing.T) {
+		service, userRepo, _, _ := setupTestService()
+		userRepo.On("SetReviewCapacity", mock.Anything, "user1", 1.5).
+			Return(&domain.User{UserID: "user1", ReviewCapacity: 1.5}, nil)
+
+		user, err := service.SetCapacity(context.Background(), "user1", 1.5)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1.5, user.ReviewCapacity)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		service, userRepo, _, _ := setupTestService()
+
+		for _, capacity := range []float64{0, -1, domain.MaxReviewCapacity + 0.5} {
+			user, err := service.SetCapacity(context.Background(), "user1", capacity)
+
+			require.ErrorIs(t, err, domain.ErrInvalidReviewCapacity)
+			assert.Nil(t, user)
+		}
+		userRepo.AssertNotCalled(t, "SetReviewCapacity", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		service, userRepo, _, _ := setupTestService()
+		userRepo.On("SetReviewCapacity", mock.Anything, "user1", 2.0).Return(nil, repository.ErrNotFound)
+
+		user, err := service.SetCapacity(context.Background(), "user1", 2)
+
+		require.ErrorIs(t, err, domain.ErrUserNotFound)
+		assert.Nil(t, user)
+	})
+}
+
+func TestUserService_ActivateWithTopUp(t *testing.T) {
+	activated := &domain.User{UserID: "user1", TeamName: "team1", IsActive: true}
+	openPR := func(prID, groupID string, reviewers ...string) *domain.PullRequest {
+		return &domain.PullRequest{
+			PullRequestID: prID, AuthorID: "author", Status: domain.PRStatusOpen, GroupID: groupID, AssignedReviewers: reviewers,
+		}
+	}
+
+	t.Run("assigns to under-reviewed PRs up to the limit", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		service, userRepo, prRepo, _ := setupTestService(
+			WithConfig(Config{ReviewersPerPR: 2, TopUpLimit: 2, ExcludeGroupAuthors: true}),
+			WithNotifier(notifier))
+
+		userRepo.On("SetIsActive", mock.MatchedBy(dbmocks.InTransaction), "user1", true).Return(activated, nil)
+		prRepo.On("GetUnderReviewedTeamPRs", mock.MatchedBy(dbmocks.InTransaction), "team1", "user1", 2).
+			Return([]domain.UnderReviewedPR{
+				{PullRequestID: "empty", ReviewerCount: 0},
+				{PullRequestID: "own-group", GroupID: "epic", ReviewerCount: 1},
+				{PullRequestID: "filled-meanwhile", ReviewerCount: 1},
+				{PullRequestID: "one-left", ReviewerCount: 1},
+				{PullRequestID: "over-limit", ReviewerCount: 1},
+			}, nil)
+		// у user1 свой открытый PR в epic
+		prRepo.On("GetOpenGroupAuthors", mock.Anything, "epic", "own-group").Return([]string{"user1"}, nil)
+		prRepo.On("LockPullRequest", mock.Anything, mock.Anything).Return(nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "empty").Return(openPR("empty", ""), nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "filled-meanwhile").Return(openPR("filled-meanwhile", "", "u2", "u3"), nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "one-left").Return(openPR("one-left", "", "u2"), nil)
+		prRepo.On("AssignReviewer", mock.MatchedBy(dbmocks.InTransaction), "empty", "user1", domain.AssignmentSourceRebalance).Return(nil)
+		prRepo.On("AssignReviewer", mock.MatchedBy(dbmocks.InTransaction), "one-left", "user1", domain.AssignmentSourceRebalance).Return(nil)
+
+		user, assigned, err := service.ActivateWithTopUp(context.Background(), "user1")
+
+		require.NoError(t, err)
+		assert.True(t, user.IsActive)
+		assert.Equal(t, []string{"empty", "one-left"}, assigned)
+		assert.Equal(t, []string{"assignment empty [user1]", "assignment one-left [user1]"}, notifier.calls)
+		prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, "own-group", mock.Anything, mock.Anything)
+		prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, "filled-meanwhile", mock.Anything, mock.Anything)
+		prRepo.AssertNotCalled(t, "GetPullRequestByID", mock.Anything, "over-limit")
+	})
+
+	t.Run("no under-reviewed PRs", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		service, userRepo, prRepo, _ := setupTestService(WithNotifier(notifier))
+
+		userRepo.On("SetIsActive", mock.Anything, "user1", true).Return(activated, nil)
+		prRepo.On("GetUnderReviewedTeamPRs", mock.Anything, "team1", "user1", defaultReviewersPerPR).Return(nil, nil)
+
+		user, assigned, err := service.ActivateWithTopUp(context.Background(), "user1")
+
+		require.NoError(t, err)
+		assert.Equal(t, activated, user)
+		assert.Empty(t, assigned)
+		assert.NotNil(t, assigned)
+		assert.Empty(t, notifier.calls)
+		prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		service, userRepo, prRepo, _ := setupTestService()
+		userRepo.On("SetIsActive", mock.Anything, "ghost", true).Return(nil, repository.ErrNotFound)
+
+		user, assigned, err := service.ActivateWithTopUp(context.Background(), "ghost")
+
+		require.ErrorIs(t, err, domain.ErrUserNotFound)
+		assert.Nil(t, user)
+		assert.Nil(t, assigned)
+		prRepo.AssertNotCalled(t, "GetUnderReviewedTeamPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_DeactivateSkipsMergedPR(t *testing.T) {
+	service, userRepo, prRepo, _ := setupTestService()
+
+	userRepo.On("GetByID", mock.Anything, "user1").Return(&domain.User{UserID: "user1", TeamName: "team1", IsActive: true}, nil)
+	prRepo.On("GetOpenPullRequestsByReviewer", mock.Anything, "user1").Return([]domain.PullRequestShort{
+		{PullRequestID: "merged-meanwhile", AuthorID: "author1", Status: domain.PRStatusOpen},
+		{PullRequestID: "still-open", AuthorID: "author1", Status: domain.PRStatusOpen},
+	}, nil)
+
+	prRepo.On("GetPullRequestByID", mock.Anything, "merged-meanwhile").Return(&domain.PullRequest{
+		PullRequestID:     "merged-meanwhile",
+		AuthorID:          "author1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"user1"},
+	}, nil)
+	prRepo.On("GetPullRequestByID", mock.Anything, "still-open").Return(&domain.PullRequest{
+		PullRequestID:     "still-open",
+		AuthorID:          "author1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"user1"},
+	}, nil)
+	userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "user1"}).Return([]domain.User{}, nil)
+
+	prRepo.On("RemoveReviewer", mock.Anything, "merged-meanwhile", "user1").Return(false, repository.ErrNotOpen)
+	prRepo.On("RemoveReviewer", mock.Anything, "still-open", "user1").Return(true, nil)
+	userRepo.On("SetIsActive", mock.Anything, "user1", false).Return(&domain.User{UserID: "user1", IsActive: false}, nil)
+
+	user, err := service.SetIsActive(context.Background(), "user1", false)
+
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+	prRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+}
+
+func TestUserService_DeactivatePublishesWithServiceClock(t *testing.T) {
+	now := time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC)
+	broker := live.NewBroker()
+	service, userRepo, prRepo, _ := setupTestService(WithClock(clock.NewFake(now)), WithLiveUpdates(broker))
+
+	userRepo.On("GetByID", mock.Anything, "user1").Return(&domain.User{UserID: "user1", TeamName: "team1", IsActive: true}, nil)
+	prRepo.On("GetOpenPullRequestsByReviewer", mock.Anything, "user1").Return([]domain.PullRequestShort{
+		{PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen},
+	}, nil)
+	prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+		PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"user1"},
+	}, nil)
+	userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "user1"}).Return([]domain.User{}, nil)
+	prRepo.On("RemoveReviewer", mock.Anything, "pr1", "user1").Return(true, nil)
+	userRepo.On("SetIsActive", mock.Anything, "user1", false).Return(&domain.User{UserID: "user1", IsActive: false}, nil)
+
+	updates, cancel := broker.Subscribe("pr1")
+	defer cancel()
+
+	_, err := service.SetIsActive(context.Background(), "user1", false)
+	require.NoError(t, err)
+
+	removed := <-updates
+	assert.Equal(t, domain.PRUpdateReviewerRemoved, removed.Type)
+	assert.Equal(t, now, removed.OccurredAt)
+}
+
+func TestUserService_DeactivateSkipsReviewerRemovedMeanwhile(t *testing.T) {
+	service, userRepo, prRepo, _ := setupTestService()
+
+	userRepo.On("GetByID", mock.Anything, "user1").Return(&domain.User{UserID: "user1", TeamName: "team1", IsActive: true}, nil)
+	prRepo.On("GetOpenPullRequestsByReviewer", mock.Anything, "user1").Return([]domain.PullRequestShort{
+		{PullRequestID: "pr1", AuthorID: "author1", Status: domain.PRStatusOpen},
+	}, nil)
+	prRepo.On("GetPullRequestByID", mock.Anything, "pr1").Return(&domain.PullRequest{
+		PullRequestID:     "pr1",
+		AuthorID:          "author1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"user1"},
+	}, nil)
+	userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "user1"}).
+		Return([]domain.User{{UserID: "user2", TeamName: "team1", IsActive: true}}, nil)
+	// ревьюера сняли конкурентно: DELETE ничего не удалил
+	prRepo.On("RemoveReviewer", mock.Anything, "pr1", "user1").Return(false, nil)
+	userRepo.On("SetIsActive", mock.Anything, "user1", false).Return(&domain.User{UserID: "user1", IsActive: false}, nil)
+
+	user, err := service.SetIsActive(context.Background(), "user1", false)
+
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+	prRepo.AssertNotCalled(t, "AssignReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserService_TeamScope(t *testing.T) {
+	member := &domain.User{UserID: "user1", TeamName: "team1", IsActive: true}
+
+	t.Run("key of another team cannot toggle user", func(t *testing.T) {
+		service, userRepo, _, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "user1").Return(member, nil)
+
+		ctx := auth.WithScope(context.Background(), auth.Scope{Team: "team2"})
+		user, err := service.SetIsActive(ctx, "user1", true)
+
+		require.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, user)
+		userRepo.AssertNotCalled(t, "SetIsActive", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("key of another team cannot set schedule", func(t *testing.T) {
+		service, userRepo, _, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "user1").Return(member, nil)
+
+		ctx := auth.WithScope(context.Background(), auth.Scope{Team: "team2"})
+		user, err := service.SetSchedule(ctx, "user1", "", "")
+
+		require.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, user)
+		userRepo.AssertNotCalled(t, "SetSchedule", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("own team key", func(t *testing.T) {
+		service, userRepo, _, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "user1").Return(member, nil)
+		userRepo.On("SetIsActive", mock.Anything, "user1", true).Return(member, nil)
+
+		ctx := auth.WithScope(context.Background(), auth.Scope{Team: "team1"})
+		user, err := service.SetIsActive(ctx, "user1", true)
+
+		require.NoError(t, err)
+		assert.Equal(t, "user1", user.UserID)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown user with scoped key", func(t *testing.T) {
+		service, userRepo, _, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "ghost").Return(nil, repository.ErrNotFound)
+
+		ctx := auth.WithScope(context.Background(), auth.Scope{Team: "team1"})
+		_, err := service.SetIsActive(ctx, "ghost", true)
+
+		require.ErrorIs(t, err, domain.ErrUserNotFound)
+	})
+
+	t.Run("admin key skips membership lookup", func(t *testing.T) {
+		service, userRepo, _, _ := setupTestService()
+		userRepo.On("SetIsActive", mock.Anything, "user1", true).Return(member, nil)
+
+		_, err := service.SetIsActive(auth.WithScope(context.Background(), auth.Scope{}), "user1", true)
+
+		require.NoError(t, err)
+		userRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_RemoveFromTeam(t *testing.T) {
+	member := &domain.User{UserID: "user1", TeamName: "team1", IsActive: true}
+
+	t.Run("only open PRs change", func(t *testing.T) {
+		service, userRepo, prRepo, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "user1").Return(member, nil)
+		// смерженный PR репозиторий не возвращает, в выборку попадают только открытые
+		prRepo.On("GetOpenPullRequestsByReviewer", mock.Anything, "user1").Return([]domain.PullRequestShort{
+			{PullRequestID: "open-pr", AuthorID: "author1", Status: domain.PRStatusOpen},
+		}, nil)
+		prRepo.On("GetPullRequestByID", mock.Anything, "open-pr").Return(&domain.PullRequest{
+			PullRequestID:     "open-pr",
+			AuthorID:          "author1",
+			Status:            domain.PRStatusOpen,
+			AssignedReviewers: []string{"user1"},
+		}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "user1"}).
+			Return([]domain.User{{UserID: "user2", TeamName: "team1", IsActive: true}}, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "open-pr", "user1").Return(true, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "open-pr", "user2", domain.AssignmentSourceReassignment).Return(nil)
+		prRepo.On("IncrementReassignmentCount", mock.Anything, "open-pr").Return(nil)
+		prRepo.On("MarkOrphanedPullRequests", mock.Anything, "user1").Return([]string{}, nil)
+		userRepo.On("RemoveFromTeam", mock.Anything, "user1").Return(&domain.User{UserID: "user1", TeamName: "team1"}, nil)
+
+		user, err := service.RemoveFromTeam(context.Background(), "team1", "user1")
+
+		require.NoError(t, err)
+		assert.False(t, user.IsActive)
+		prRepo.AssertNotCalled(t, "GetPullRequestByID", mock.Anything, "merged-pr")
+		prRepo.AssertNotCalled(t, "RemoveReviewer", mock.Anything, "merged-pr", mock.Anything)
+		prRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("inactive member is still released from open PRs", func(t *testing.T) {
+		service, userRepo, prRepo, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "user1").Return(&domain.User{UserID: "user1", TeamName: "team1"}, nil)
+		prRepo.On("GetOpenPullRequestsByReviewer", mock.Anything, "user1").Return([]domain.PullRequestShort{}, nil)
+		prRepo.On("MarkOrphanedPullRequests", mock.Anything, "user1").Return([]string{}, nil)
+		userRepo.On("RemoveFromTeam", mock.Anything, "user1").Return(&domain.User{UserID: "user1", TeamName: "team1"}, nil)
+
+		_, err := service.RemoveFromTeam(context.Background(), "team1", "user1")
+
+		require.NoError(t, err)
+		prRepo.AssertExpectations(t)
+	})
+
+	t.Run("member of another team", func(t *testing.T) {
+		service, userRepo, prRepo, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "user1").Return(member, nil)
+
+		_, err := service.RemoveFromTeam(context.Background(), "team2", "user1")
+
+		require.ErrorIs(t, err, domain.ErrUserNotFound)
+		prRepo.AssertNotCalled(t, "GetOpenPullRequestsByReviewer", mock.Anything, mock.Anything)
+		userRepo.AssertNotCalled(t, "RemoveFromTeam", mock.Anything, mock.Anything)
+	})
+
+	t.Run("key of another team", func(t *testing.T) {
+		service, userRepo, _, _ := setupTestService()
+
+		ctx := auth.WithScope(context.Background(), auth.Scope{Team: "team2"})
+		_, err := service.RemoveFromTeam(ctx, "team1", "user1")
+
+		require.ErrorIs(t, err, domain.ErrForbidden)
+		userRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_RemoveFromTeam_OrphanedPRs(t *testing.T) {
+	member := &domain.User{UserID: "author1", TeamName: "team1", IsActive: true}
+
+	t.Run("open PRs are marked orphaned by default", func(t *testing.T) {
+		service, userRepo, prRepo, _ := setupTestService()
+		userRepo.On("GetByID", mock.Anything, "author1").Return(member, nil)
+		prRepo.On("GetOpenPullRequestsByReviewer", mock.Anything, "author1").Return([]domain.PullRequestShort{}, nil)
+		prRepo.On("MarkOrphanedPullRequests", mock.Anything, "author1").Return([]string{"pr1", "pr2"}, nil)
+		userRepo.On("RemoveFromTeam", mock.Anything, "author1").Return(&domain.User{UserID: "author1", TeamName: "team1"}, nil)
+
+		_, err := service.RemoveFromTeam(context.Background(), "team1", "author1")
+
+		require.NoError(t, err)
+		prRepo.AssertNotCalled(t, "CloseOrphanedPullRequests", mock.Anything, mock.Anything)
+		prRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("open PRs are closed with AutoCloseOrphanedPRs", func(t *testing.T) {
+		service, userRepo, prRepo, _ := setupTestService(WithConfig(Config{AutoCloseOrphanedPRs: true}))
+		userRepo.On("GetByID", mock.Anything, "author1").Return(member, nil)
+		prRepo.On("GetOpenPullRequestsByReviewer", mock.Anything, "author1").Return([]domain.PullRequestShort{}, nil)
+		prRepo.On("CloseOrphanedPullRequests", mock.Anything, "author1").Return([]string{"pr1"}, nil)
+		userRepo.On("RemoveFromTeam", mock.Anything, "author1").Return(&domain.User{UserID: "author1", TeamName: "team1"}, nil)
+
+		_, err := service.RemoveFromTeam(context.Background(), "team1", "author1")
+
+		require.NoError(t, err)
+		prRepo.AssertNotCalled(t, "MarkOrphanedPullRequests", mock.Anything, mock.Anything)
+		prRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("failure keeps the user in the team", func(t *testing.T) {
+		service, userRepo, prRepo, _ := setupTestService(WithConfig(Config{AutoCloseOrphanedPRs: true}))
+		userRepo.On("GetByID", mock.Anything, "author1").Return(member, nil)
+		prRepo.On("GetOpenPullRequestsByReviewer", mock.Anything, "author1").Return([]domain.PullRequestShort{}, nil)
+		prRepo.On("CloseOrphanedPullRequests", mock.Anything, "author1").Return(nil, errors.New("db down"))
+
+		_, err := service.RemoveFromTeam(context.Background(), "team1", "author1")
+
+		require.Error(t, err)
+		userRepo.AssertNotCalled(t, "RemoveFromTeam", mock.Anything, mock.Anything)
+	})
+}
+
+// recordingNotifier запоминает уведомления в порядке вызова
+type recordingNotifier struct {
+	calls []string
+}
+
+func (n *recordingNotifier) NotifyAssignment(pr domain.PullRequest, reviewerIDs []string) {
+	n.calls = append(n.calls, fmt.Sprintf("assignment %s %v", pr.PullRequestID, reviewerIDs))
+}
+
+func (n *recordingNotifier) NotifyReassignment(pr domain.PullRequest, oldReviewerID, newReviewerID string) {
+	n.calls = append(n.calls, fmt.Sprintf("reassignment %s %s->%s", pr.PullRequestID, oldReviewerID, newReviewerID))
+}
+
+func (n *recordingNotifier) NotifyNoCandidate(pr domain.PullRequest, reviewerID string) {
+	n.calls = append(n.calls, fmt.Sprintf("no_candidate %s %s", pr.PullRequestID, reviewerID))
+}
+
+func TestUserService_DeactivateNotifies(t *testing.T) {
+	setup := func(notifier *recordingNotifier) (*UserService, *mocks.UserRepository, *mocks.PullRequestRepository) {
+		service, userRepo, prRepo, _ := setupTestService(WithNotifier(notifier))
+
+		userRepo.On("GetByID", mock.Anything, "user1").Return(&domain.User{UserID: "user1", TeamName: "team1", IsActive: true}, nil)
+		prRepo.On("GetOpenPullRequestsByReviewer", mock.Anything, "user1").Return([]domain.PullRequestShort{
+			{PullRequestID: "replaced", AuthorID: "author1", Status: domain.PRStatusOpen},
+			{PullRequestID: "removed", AuthorID: "author2", Status: domain.PRStatusOpen},
+			{PullRequestID: "merged-meanwhile", AuthorID: "author2", Status: domain.PRStatusOpen},
+		}, nil)
+		for _, pr := range []struct{ id, author string }{
+			{"replaced", "author1"}, {"removed", "author2"}, {"merged-meanwhile", "author2"},
+		} {
+			prRepo.On("GetPullRequestByID", mock.Anything, pr.id).Return(&domain.PullRequest{
+				PullRequestID: pr.id, AuthorID: pr.author, Status: domain.PRStatusOpen, AssignedReviewers: []string{"user1"},
+			}, nil)
+		}
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author1", "user1"}).
+			Return([]domain.User{{UserID: "user2", TeamName: "team1", IsActive: true}}, nil)
+		userRepo.On("GetActiveByTeam", mock.Anything, "team1", []string{"author2", "user1"}).Return([]domain.User{}, nil)
+
+		prRepo.On("RemoveReviewer", mock.Anything, "replaced", "user1").Return(true, nil)
+		prRepo.On("AssignReviewer", mock.Anything, "replaced", "user2", domain.AssignmentSourceReassignment).Return(nil)
+		prRepo.On("IncrementReassignmentCount", mock.Anything, "replaced").Return(nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "removed", "user1").Return(true, nil)
+		prRepo.On("RemoveReviewer", mock.Anything, "merged-meanwhile", "user1").Return(false, repository.ErrNotOpen)
+		return service, userRepo, prRepo
+	}
+
+	t.Run("after commit", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		service, userRepo, _ := setup(notifier)
+		userRepo.On("SetIsActive", mock.Anything, "user1", false).Return(&domain.User{UserID: "user1"}, nil)
+
+		_, err := service.SetIsActive(context.Background(), "user1", false)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"reassignment replaced user1->user2",
+			"no_candidate removed user1",
+		}, notifier.calls)
+	})
+
+	t.Run("nothing on rollback", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		service, userRepo, _ := setup(notifier)
+		userRepo.On("SetIsActive", mock.Anything, "user1", false).Return(nil, errors.New("db error"))
+
+		_, err := service.SetIsActive(context.Background(), "user1", false)
+
+		require.Error(t, err)
+		assert.Empty(t, notifier.calls)
+	})
+}
@@ -1,8 +1,11 @@
 package utils
 
 import (
+	"cmp"
 	"fmt"
 	"math/rand/v2"
+	"slices"
+	"time"
 
 	"avito_backend_task/internal/domain"
 )
@@ -35,3 +38,49 @@ func SelectRandomReviewer(candidates []domain.User) (domain.User, error) {
 	index := rand.IntN(len(candidates))
 	return candidates[index], nil
 }
+
+// SelectLeastLoaded выбирает кандидата с наименьшей нагрузкой из loads с учётом ReviewCapacity, среди равных - случайного
+func SelectLeastLoaded(candidates []domain.User, loads map[string]int) (domain.User, bool) {
+	if len(candidates) == 0 {
+		return domain.User{}, false
+	}
+
+	best := -1
+	for _, i := range rand.Perm(len(candidates)) {
+		if best < 0 || effectiveLoad(candidates[i], loads) < effectiveLoad(candidates[best], loads) {
+			best = i
+		}
+	}
+
+	return candidates[best], true
+}
+
+// SelectLeastLoadedReviewers выбирает до maxCount кандидатов с наименьшей нагрузкой из loads с учётом ReviewCapacity,
+// среди равных - случайных
+func SelectLeastLoadedReviewers(candidates []domain.User, loads map[string]int, maxCount int) []domain.User {
+	shuffled := SelectRandomReviewers(candidates, len(candidates))
+	slices.SortStableFunc(shuffled, func(a, b domain.User) int {
+		return cmp.Compare(effectiveLoad(a, loads), effectiveLoad(b, loads))
+	})
+
+	return shuffled[:min(maxCount, len(shuffled))]
+}
+
+func effectiveLoad(user domain.User, loads map[string]int) float64 {
+	return user.EffectiveLoad(loads[user.UserID])
+}
+
+// SplitByWorkingHours делит кандидатов на находящихся сейчас в рабочем окне и остальных.
+// Пользователи без окна (или с некорректно сохранённым окном) считаются доступными
+func SplitByWorkingHours(candidates []domain.User, now time.Time) (available, outside []domain.User) {
+	for _, candidate := range candidates {
+		schedule, err := candidate.Schedule()
+		if err != nil || schedule == nil || schedule.Contains(now) {
+			available = append(available, candidate)
+			continue
+		}
+		outside = append(outside, candidate)
+	}
+
+	return available, outside
+}
@@ -0,0 +1,367 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/jobs"
+	"avito_backend_task/internal/service/eligibility"
+	"avito_backend_task/internal/transport/http/validation"
+	"avito_backend_task/pkg/db/migrate"
+)
+
+// emptyBackend реализует все сервисы роутера и отдаёт объекты с nil-коллекциями,
+// как их возвращают репозитории на пустых данных
+type emptyBackend struct{}
+
+func emptyPR(id string) *domain.PullRequest {
+	return &domain.PullRequest{PullRequestID: id, AuthorID: "u1", Status: domain.PRStatusOpen}
+}
+
+func (emptyBackend) CreateTeam(_ context.Context, team domain.Team) (*domain.Team, error) {
+	return &domain.Team{TeamName: team.TeamName}, nil
+}
+
+func (emptyBackend) GetTeamByName(_ context.Context, teamName string) (*domain.Team, error) {
+	return &domain.Team{TeamName: teamName}, nil
+}
+
+func (emptyBackend) GetTeamEligibility(_ context.Context, teamName string) (*domain.Team, map[string]eligibility.Status, error) {
+	return &domain.Team{TeamName: teamName}, nil, nil
+}
+
+func (emptyBackend) GetTeamByMember(context.Context, string) (*domain.Team, error) {
+	return &domain.Team{TeamName: "backend"}, nil
+}
+
+func (emptyBackend) CreateBlackout(_ context.Context, blackout domain.TeamBlackout) (*domain.TeamBlackout, error) {
+	return &blackout, nil
+}
+
+func (emptyBackend) DeleteBlackout(context.Context, string, int64) error {
+	return nil
+}
+
+func (emptyBackend) CreateWebhook(_ context.Context, webhook domain.TeamWebhook) (*domain.TeamWebhook, error) {
+	return &webhook, nil
+}
+
+func (emptyBackend) GetWebhook(_ context.Context, teamName string) (*domain.TeamWebhook, error) {
+	return &domain.TeamWebhook{TeamName: teamName}, nil
+}
+
+func (emptyBackend) UpdateWebhook(_ context.Context, update domain.TeamWebhookUpdate) (*domain.TeamWebhook, error) {
+	return &domain.TeamWebhook{TeamName: update.TeamName}, nil
+}
+
+func (emptyBackend) DeleteWebhook(context.Context, string) error {
+	return nil
+}
+
+func (emptyBackend) GetWebhookStatus(_ context.Context, teamName string) (*domain.TeamWebhookStatus, error) {
+	return &domain.TeamWebhookStatus{TeamName: teamName}, nil
+}
+
+func (emptyBackend) GetAgeReport(_ context.Context, teamName string) (*domain.TeamAgeReport, error) {
+	buckets := make([]domain.PRAgeBucket, len(domain.PRAgeBucketLabels))
+	for i, label := range domain.PRAgeBucketLabels {
+		buckets[i] = domain.PRAgeBucket{Label: label}
+	}
+	return &domain.TeamAgeReport{TeamName: teamName, Buckets: buckets}, nil
+}
+
+func (emptyBackend) ReconcileTeamsFromUsers(context.Context, bool) ([]domain.ReconciledTeam, error) {
+	return nil, nil
+}
+
+func (emptyBackend) CheckHealth(_ context.Context, teamName string) (*domain.TeamHealth, error) {
+	return &domain.TeamHealth{TeamName: teamName, Healthy: true}, nil
+}
+
+func (emptyBackend) SetIsActive(_ context.Context, userID string, isActive bool) (*domain.User, error) {
+	return &domain.User{UserID: userID, IsActive: isActive}, nil
+}
+
+func (emptyBackend) SetIsActiveOrCreate(_ context.Context, member domain.TeamMember, teamName string) (*domain.User, bool, error) {
+	return &domain.User{UserID: member.UserID, TeamName: teamName}, true, nil
+}
+
+func (emptyBackend) ActivateWithTopUp(_ context.Context, userID string) (*domain.User, []string, error) {
+	return &domain.User{UserID: userID, IsActive: true}, nil, nil
+}
+
+func (emptyBackend) SetSchedule(_ context.Context, userID, _, _ string) (*domain.User, error) {
+	return &domain.User{UserID: userID}, nil
+}
+
+func (emptyBackend) SetCapacity(_ context.Context, userID string, capacity float64) (*domain.User, error) {
+	return &domain.User{UserID: userID, ReviewCapacity: capacity}, nil
+}
+
+func (emptyBackend) RemoveFromTeam(_ context.Context, _, userID string) (*domain.User, error) {
+	return &domain.User{UserID: userID}, nil
+}
+
+func (emptyBackend) GetReviewPRsByUserID(context.Context, string, domain.ReviewFilter) ([]domain.PullRequestShort, error) {
+	return nil, nil
+}
+
+func (emptyBackend) StreamReviewPRsByUserID(context.Context, string, domain.ReviewFilter, func(domain.PullRequestShort) error) error {
+	return nil
+}
+
+func (emptyBackend) GetUserReviewTimeline(context.Context, string, *time.Time, *time.Time, domain.Page) ([]domain.ReviewerEvent, error) {
+	return nil, nil
+}
+
+func (emptyBackend) GetFormerReviews(context.Context, string) ([]domain.FormerReview, error) {
+	return nil, nil
+}
+
+func (emptyBackend) GetAssignmentEvents(_ context.Context, since time.Time, _ domain.AssignmentSource) (domain.ReviewerEventsPage, error) {
+	return domain.ReviewerEventsPage{NextSince: since}, nil
+}
+
+func (emptyBackend) GetOverview(_ context.Context, userID string) (*domain.UserOverview, error) {
+	return &domain.UserOverview{User: domain.User{UserID: userID}}, nil
+}
+
+func (emptyBackend) GetTeamPolicy(_ context.Context, teamName string) (*domain.TeamPolicy, error) {
+	return &domain.TeamPolicy{TeamName: teamName}, nil
+}
+
+func (emptyBackend) ValidateUserIDs(context.Context, []string) ([]string, []string, error) {
+	return nil, nil, nil
+}
+
+func (emptyBackend) CreatePullRequest(_ context.Context, pr domain.PullRequestCreate) (*domain.PullRequest, error) {
+	return emptyPR(pr.PullRequestID), nil
+}
+
+func (emptyBackend) MergePullRequest(_ context.Context, prID, _ string) (*domain.PullRequest, error) {
+	return emptyPR(prID), nil
+}
+
+func (emptyBackend) UpdatePullRequest(_ context.Context, update domain.PullRequestUpdate) (*domain.PullRequest, error) {
+	return emptyPR(update.PullRequestID), nil
+}
+
+func (emptyBackend) ReassignReviewer(_ context.Context, prID, _ string) (*domain.PullRequest, string, error) {
+	return emptyPR(prID), "", nil
+}
+
+func (emptyBackend) ReassignReviewers(_ context.Context, prID string, _ []string) (*domain.PullRequest, domain.ReassignResult, error) {
+	return emptyPR(prID), domain.ReassignResult{}, nil
+}
+
+func (emptyBackend) ReassignIfInactive(_ context.Context, prID, _ string) (*domain.PullRequest, string, error) {
+	return emptyPR(prID), "", nil
+}
+
+func (emptyBackend) AssignReviewer(_ context.Context, prID, _ string) (*domain.PullRequest, error) {
+	return emptyPR(prID), nil
+}
+
+func (emptyBackend) SelfAssign(_ context.Context, prID, _ string) (*domain.PullRequest, error) {
+	return emptyPR(prID), nil
+}
+
+func (emptyBackend) SnoozeReview(_ context.Context, prID, userID string, until time.Time) (*domain.ReviewSnooze, error) {
+	return &domain.ReviewSnooze{PullRequestID: prID, UserID: userID, Until: until}, nil
+}
+
+func (emptyBackend) GetPullRequest(_ context.Context, prID string) (*domain.PullRequest, error) {
+	return emptyPR(prID), nil
+}
+
+func (emptyBackend) GetPullRequestsByIDs(_ context.Context, prIDs []string) ([]domain.PullRequest, error) {
+	return nil, nil
+}
+
+// SSE-поток не отдаёт коллекций, в paths его нет
+func (emptyBackend) SubscribeUpdates(context.Context, string) (<-chan domain.PRUpdate, func(), error) {
+	return nil, nil, domain.ErrPRNotFound
+}
+
+func (emptyBackend) GetReviewerIDs(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+
+func (emptyBackend) SetReviewers(_ context.Context, prID string, _ []string, _ bool) (*domain.PullRequest, error) {
+	return emptyPR(prID), nil
+}
+
+func (emptyBackend) RebalanceTeam(context.Context, string) (*domain.RebalanceReport, error) {
+	return &domain.RebalanceReport{}, nil
+}
+
+func (emptyBackend) GetRecentPullRequests(context.Context, int) ([]domain.RecentPullRequest, error) {
+	return nil, nil
+}
+
+func (emptyBackend) GetQueueInfo(_ context.Context, prID string) (*domain.PRQueueInfo, error) {
+	return &domain.PRQueueInfo{PullRequestID: prID, Status: domain.PRStatusMerged}, nil
+}
+
+func (emptyBackend) GetReviewLatency(context.Context, string, *time.Time, *time.Time) ([]domain.ReviewerLatency, error) {
+	return nil, nil
+}
+
+func (emptyBackend) Statuses() []jobs.Status {
+	return nil
+}
+
+func (emptyBackend) ReassignInactiveReviewers(context.Context) (*domain.InactiveReassignReport, error) {
+	return &domain.InactiveReassignReport{}, nil
+}
+
+func (emptyBackend) BackfillReviewerHistory(context.Context, int, bool) (*domain.BackfillReport, error) {
+	return &domain.BackfillReport{}, nil
+}
+
+func (emptyBackend) DetectAnomalies(context.Context) (*domain.AnomalyReport, error) {
+	return &domain.AnomalyReport{}, nil
+}
+
+func (emptyBackend) Status(context.Context) (migrate.Status, error) {
+	return migrate.Status{Expected: 1, Applied: 1}, nil
+}
+
+func (emptyBackend) GetGlobalStats(context.Context) (*domain.GlobalStats, error) {
+	return &domain.GlobalStats{}, nil
+}
+
+func (emptyBackend) CountPullRequestsByAuthor(
+	context.Context, string, *time.Time, domain.AuthorPRCountOrder, int,
+) ([]domain.AuthorPRCount, error) {
+	return nil, nil
+}
+
+// nullableFields - поля, для которых null - часть контракта, а не пустая коллекция
+var nullableFields = map[string]bool{"oldest": true}
+
+// findNulls возвращает пути к null-значениям в разобранном JSON
+func findNulls(path string, value any) []string {
+	switch v := value.(type) {
+	case nil:
+		return []string{path}
+	case map[string]any:
+		var nulls []string
+		for key, item := range v {
+			if item == nil && nullableFields[key] {
+				continue
+			}
+			nulls = append(nulls, findNulls(path+"."+key, item)...)
+		}
+		return nulls
+	case []any:
+		var nulls []string
+		for _, item := range v {
+			nulls = append(nulls, findNulls(path+"[]", item)...)
+		}
+		return nulls
+	default:
+		return nil
+	}
+}
+
+func TestRouter_EmptyCollectionsAreArrays(t *testing.T) {
+	backend := emptyBackend{}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	router := NewRouter(Services{
+		TeamService:        backend,
+		UserService:        backend,
+		PullRequestService: backend,
+		JobScheduler:       backend,
+		ReviewerService:    backend,
+		SchemaChecker:      backend,
+		StatsService:       backend,
+	}, logger, validation.New())
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: http.MethodPost, path: "/team/add", body: `{"team_name":"backend","members":[{"user_id":"u1","username":"A","is_active":true}]}`},
+		{method: http.MethodGet, path: "/team/get?team_name=backend"},
+		{method: http.MethodGet, path: "/team/get?team_name=backend&include_eligibility=true"},
+		{method: http.MethodGet, path: "/team/byMember?user_id=u1"},
+		{method: http.MethodGet, path: "/team/ageReport?team_name=backend"},
+		{method: http.MethodGet, path: "/team/isHealthy?team_name=backend"},
+		{method: http.MethodPost, path: "/team/blackouts", body: `{"team_name":"backend","starts_at":"2025-11-01T00:00:00Z","ends_at":"2025-11-02T00:00:00Z"}`},
+		{method: http.MethodPost, path: "/team/webhooks", body: `{"team_name":"backend","url":"https://hooks.local/backend","secret":"s3cret-s3cret-s3cret"}`},
+		{method: http.MethodGet, path: "/team/webhooks?team_name=backend"},
+		{method: http.MethodPatch, path: "/team/webhooks", body: `{"team_name":"backend","enabled":false}`},
+		{method: http.MethodGet, path: "/team/webhooks/status?team_name=backend"},
+		{method: http.MethodPost, path: "/team/rebalance", body: `{"team_name":"backend"}`},
+		{method: http.MethodPost, path: "/team/removeMember", body: `{"team_name":"backend","user_id":"u1"}`},
+		{method: http.MethodPost, path: "/users/setIsActive", body: `{"user_id":"u1","is_active":true}`},
+		{method: http.MethodPost, path: "/users/setIsActive", body: `{"user_id":"u1","is_active":true,"create_if_missing":true,"username":"A","team_name":"backend"}`},
+		{method: http.MethodPost, path: "/users/setIsActive", body: `{"user_id":"u1","is_active":true,"rebalance":true}`},
+		{method: http.MethodPost, path: "/users/setSchedule", body: `{"user_id":"u1"}`},
+		{method: http.MethodPost, path: "/users/setCapacity", body: `{"user_id":"u1","review_capacity":2}`},
+		{method: http.MethodGet, path: "/users/getReview?user_id=u1"},
+		{method: http.MethodGet, path: "/users/getReview?user_id=u1&stream=true"},
+		{method: http.MethodGet, path: "/users/getReview?user_id=u1&status=OPEN&include_snoozed=true"},
+		{method: http.MethodGet, path: "/users/timeline?user_id=u1"},
+		{method: http.MethodGet, path: "/users/formerReviews?user_id=u1"},
+		{method: http.MethodPost, path: "/users/validate", body: `{"user_ids":["u1"]}`},
+		{method: http.MethodGet, path: "/users/overview?user_id=u1"},
+		{method: http.MethodGet, path: "/team/policy?team_name=backend"},
+		{method: http.MethodPost, path: "/pullRequest/create", body: `{"pull_request_id":"pr1","pull_request_name":"PR","author_id":"u1"}`},
+		{method: http.MethodPost, path: "/pullRequest/merge", body: `{"pull_request_id":"pr1"}`},
+		{method: http.MethodPost, path: "/pullRequest/mergeBulk", body: `{"pull_request_ids":["pr1"]}`},
+		{method: http.MethodPost, path: "/pullRequest/batchGet", body: `{"pull_request_ids":["pr1"]}`},
+		{method: http.MethodGet, path: "/pullRequest/recent"},
+		{method: http.MethodPatch, path: "/pullRequest", body: `{"pull_request_id":"pr1","pull_request_name":"PR"}`},
+		{method: http.MethodPost, path: "/pullRequest/reassign", body: `{"pull_request_id":"pr1","old_user_id":"u2"}`},
+		{method: http.MethodPost, path: "/pullRequest/reassign", body: `{"pull_request_id":"pr1","old_user_ids":["u2","u3"]}`},
+		{method: http.MethodPost, path: "/pullRequest/reassignIfInactive", body: `{"pull_request_id":"pr1","user_id":"u2"}`},
+		{method: http.MethodPost, path: "/pullRequest/assign", body: `{"pull_request_id":"pr1","user_id":"u2"}`},
+		{method: http.MethodPost, path: "/pullRequest/selfAssign", body: `{"pull_request_id":"pr1","user_id":"u2"}`},
+		{method: http.MethodPost, path: "/pullRequest/snooze", body: `{"pull_request_id":"pr1","user_id":"u2","until":"2030-01-01T00:00:00Z"}`},
+		{method: http.MethodGet, path: "/pullRequest/get?pull_request_id=pr1"},
+		{method: http.MethodGet, path: "/pullRequest/reviewerIds?pull_request_id=pr1"},
+		{method: http.MethodGet, path: "/pullRequest/reviewLatency?team_name=backend"},
+		{method: http.MethodGet, path: "/pullRequest/queueInfo?pull_request_id=pr1"},
+		{method: http.MethodPost, path: "/admin/pullRequest/setReviewers", body: `{"pull_request_id":"pr1","reviewer_ids":[]}`},
+		{method: http.MethodGet, path: "/admin/jobs"},
+		{method: http.MethodGet, path: "/admin/schema"},
+		{method: http.MethodPost, path: "/admin/reassignInactive"},
+		{method: http.MethodGet, path: "/admin/anomalies"},
+		{method: http.MethodPost, path: "/admin/backfill"},
+		{method: http.MethodPost, path: "/admin/reconcileTeams"},
+		{method: http.MethodPost, path: "/admin/reconcileTeams?dry_run=true"},
+		{method: http.MethodGet, path: "/stats/global"},
+		{method: http.MethodGet, path: "/stats/byAuthor?team_name=backend"},
+		{method: http.MethodGet, path: "/events/assignments?since=2025-10-01T12:00:00Z"},
+	}
+
+	for _, prefix := range []string{"", "/api/v1"} {
+		for _, tt := range tests {
+			t.Run(tt.method+" "+prefix+tt.path, func(t *testing.T) {
+				req := httptest.NewRequest(tt.method, prefix+tt.path, strings.NewReader(tt.body))
+				rec := httptest.NewRecorder()
+
+				router.ServeHTTP(rec, req)
+
+				require.Less(t, rec.Code, http.StatusBadRequest, rec.Body.String())
+				var body any
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Empty(t, findNulls("$", body), rec.Body.String())
+			})
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package admin
+
+import (
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/jobs"
+	"avito_backend_task/internal/transport/http/response"
+	"avito_backend_task/pkg/db/migrate"
+)
+
+type JobStatusDTO struct {
+	Name         string             `json:"name"`
+	Interval     string             `json:"interval"`
+	Running      bool               `json:"running"`
+	LastRunAt    *response.JSONTime `json:"last_run_at,omitempty"`
+	LastDuration string             `json:"last_duration,omitempty"`
+	LastError    string             `json:"last_error,omitempty"`
+	RunCount     int                `json:"run_count"`
+	SkipCount    int                `json:"skip_count"`
+}
+
+type JobsResponse struct {
+	Jobs []JobStatusDTO `json:"jobs"`
+}
+
+func jobStatusToDTO(status jobs.Status) JobStatusDTO {
+	dto := JobStatusDTO{
+		Name:      status.Name,
+		Interval:  status.Interval.String(),
+		Running:   status.Running,
+		LastRunAt: response.OptionalJSONTime(status.LastRunAt),
+		LastError: status.LastError,
+		RunCount:  status.RunCount,
+		SkipCount: status.SkipCount,
+	}
+	if status.LastRunAt != nil {
+		dto.LastDuration = status.LastDuration.String()
+	}
+	return dto
+}
+
+type ReplacementDTO struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldUserID     string `json:"old_user_id"`
+	NewUserID     string `json:"new_user_id,omitempty"`
+}
+
+type SkippedDTO struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+}
+
+type ReassignInactiveResponse struct {
+	Reassigned []ReplacementDTO `json:"reassigned"`
+	Removed    []ReplacementDTO `json:"removed"`
+	Skipped    []SkippedDTO     `json:"skipped"`
+}
+
+func reportToDTO(report domain.InactiveReassignReport) ReassignInactiveResponse {
+	resp := ReassignInactiveResponse{
+		Reassigned: make([]ReplacementDTO, len(report.Reassigned)),
+		Removed:    make([]ReplacementDTO, len(report.Removed)),
+		Skipped:    make([]SkippedDTO, len(report.Skipped)),
+	}
+	for i, r := range report.Reassigned {
+		resp.Reassigned[i] = replacementToDTO(r)
+	}
+	for i, r := range report.Removed {
+		resp.Removed[i] = replacementToDTO(r)
+	}
+	for i, a := range report.Skipped {
+		resp.Skipped[i] = SkippedDTO{PullRequestID: a.PullRequestID, UserID: a.UserID}
+	}
+	return resp
+}
+
+func replacementToDTO(r domain.ReviewerReplacement) ReplacementDTO {
+	return ReplacementDTO{
+		PullRequestID: r.PullRequestID,
+		OldUserID:     r.OldUserID,
+		NewUserID:     r.NewUserID,
+	}
+}
+
+type AnomalyDTO struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+	AuthorTeam    string `json:"author_team,omitempty"`
+	ReviewerTeam  string `json:"reviewer_team,omitempty"`
+}
+
+// AnomaliesResponse - найденные аномалии по категориям; skipped - категории, которые конфигурация допускает
+type AnomaliesResponse struct {
+	AuthorIsReviewer  []AnomalyDTO `json:"author_is_reviewer"`
+	CrossTeamReviewer []AnomalyDTO `json:"cross_team_reviewer"`
+	UnknownReviewer   []AnomalyDTO `json:"unknown_reviewer"`
+	Skipped           []string     `json:"skipped"`
+	Total             int          `json:"total"`
+}
+
+func anomalyReportToDTO(report domain.AnomalyReport) AnomaliesResponse {
+	resp := AnomaliesResponse{
+		AuthorIsReviewer:  anomaliesToDTO(report.AuthorIsReviewer),
+		CrossTeamReviewer: anomaliesToDTO(report.CrossTeamReviewer),
+		UnknownReviewer:   anomaliesToDTO(report.UnknownReviewer),
+		Skipped:           make([]string, len(report.Skipped)),
+	}
+	for i, kind := range report.Skipped {
+		resp.Skipped[i] = string(kind)
+	}
+	resp.Total = len(resp.AuthorIsReviewer) + len(resp.CrossTeamReviewer) + len(resp.UnknownReviewer)
+	return resp
+}
+
+func anomaliesToDTO(anomalies []domain.ReviewerAnomaly) []AnomalyDTO {
+	dtos := make([]AnomalyDTO, len(anomalies))
+	for i, a := range anomalies {
+		dtos[i] = AnomalyDTO{
+			PullRequestID: a.PullRequestID,
+			UserID:        a.UserID,
+			AuthorTeam:    a.AuthorTeam,
+			ReviewerTeam:  a.ReviewerTeam,
+		}
+	}
+	return dtos
+}
+
+type BackfillResponse struct {
+	Batches  int `json:"batches"`
+	Scanned  int `json:"scanned"`
+	Inserted int `json:"inserted"`
+	// watermark пуст, если pr_reviewers ещё ни разу не обрабатывались
+	WatermarkPullRequestID string `json:"watermark_pull_request_id"`
+	WatermarkUserID        string `json:"watermark_user_id"`
+}
+
+func backfillReportToDTO(report domain.BackfillReport) BackfillResponse {
+	return BackfillResponse{
+		Batches:                report.Batches,
+		Scanned:                report.Scanned,
+		Inserted:               report.Inserted,
+		WatermarkPullRequestID: report.Watermark.PullRequestID,
+		WatermarkUserID:        report.Watermark.UserID,
+	}
+}
+
+type SchemaStatusResponse struct {
+	ExpectedVersion uint `json:"expected_version"`
+	AppliedVersion  uint `json:"applied_version"`
+	Dirty           bool `json:"dirty"`
+	UpToDate        bool `json:"up_to_date"`
+}
+
+func schemaStatusToDTO(status migrate.Status) SchemaStatusResponse {
+	return SchemaStatusResponse{
+		ExpectedVersion: status.Expected,
+		AppliedVersion:  status.Applied,
+		Dirty:           status.Dirty,
+		UpToDate:        status.UpToDate(),
+	}
+}
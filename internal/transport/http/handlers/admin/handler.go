@@ -0,0 +1,137 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/jobs"
+	"avito_backend_task/internal/transport/http/query"
+	"avito_backend_task/internal/transport/http/response"
+	"avito_backend_task/pkg/db/migrate"
+)
+
+type JobScheduler interface {
+	Statuses() []jobs.Status
+}
+
+type ReviewerService interface {
+	ReassignInactiveReviewers(ctx context.Context) (*domain.InactiveReassignReport, error)
+	BackfillReviewerHistory(ctx context.Context, batchSize int, restart bool) (*domain.BackfillReport, error)
+	DetectAnomalies(ctx context.Context) (*domain.AnomalyReport, error)
+}
+
+// maxBackfillBatchSize ограничивает порцию, чтобы одна транзакция backfill не держала блокировки долго
+const maxBackfillBatchSize = 5000
+
+type SchemaChecker interface {
+	Status(ctx context.Context) (migrate.Status, error)
+}
+
+type AdminHandler struct {
+	scheduler JobScheduler
+	reviewers ReviewerService
+	schema    SchemaChecker
+	lg        *slog.Logger
+}
+
+func NewAdminHandler(scheduler JobScheduler, reviewers ReviewerService, schema SchemaChecker, lg *slog.Logger) *AdminHandler {
+	return &AdminHandler{
+		scheduler: scheduler,
+		reviewers: reviewers,
+		schema:    schema,
+		lg:        lg,
+	}
+}
+
+// GET /admin/jobs
+func (h *AdminHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
+	statuses := h.scheduler.Statuses()
+
+	jobDTOs := make([]JobStatusDTO, len(statuses))
+	for i, status := range statuses {
+		jobDTOs[i] = jobStatusToDTO(status)
+	}
+
+	response.RespondJSON(w, http.StatusOK, JobsResponse{Jobs: jobDTOs})
+}
+
+// POST /admin/reassignInactive
+func (h *AdminHandler) ReassignInactive(w http.ResponseWriter, r *http.Request) {
+	op := "AdminHandler.ReassignInactive"
+	log := h.lg.With(slog.String("op", op))
+
+	report, err := h.reviewers.ReassignInactiveReviewers(r.Context())
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, reportToDTO(*report))
+}
+
+// POST /admin/backfill
+func (h *AdminHandler) Backfill(w http.ResponseWriter, r *http.Request) {
+	op := "AdminHandler.Backfill"
+	log := h.lg.With(slog.String("op", op))
+
+	// 0 - размер порции по умолчанию из сервиса
+	batchSize, err := query.Int(r, "batch_size", 0)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+	if batchSize < 0 {
+		response.RespondError(w, log, response.InvalidRequest(response.FieldError{Field: "batch_size", Rule: "min=0"}))
+		return
+	}
+	if batchSize > maxBackfillBatchSize {
+		response.RespondError(w, log, response.InvalidRequest(response.FieldError{
+			Field: "batch_size", Rule: fmt.Sprintf("max=%d", maxBackfillBatchSize),
+		}))
+		return
+	}
+	restart, err := query.Bool(r, "restart", false)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	report, err := h.reviewers.BackfillReviewerHistory(r.Context(), batchSize, restart)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, backfillReportToDTO(*report))
+}
+
+// GET /admin/anomalies
+func (h *AdminHandler) GetAnomalies(w http.ResponseWriter, r *http.Request) {
+	op := "AdminHandler.GetAnomalies"
+	log := h.lg.With(slog.String("op", op))
+
+	report, err := h.reviewers.DetectAnomalies(r.Context())
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, anomalyReportToDTO(*report))
+}
+
+// GET /admin/schema
+func (h *AdminHandler) GetSchema(w http.ResponseWriter, r *http.Request) {
+	op := "AdminHandler.GetSchema"
+	log := h.lg.With(slog.String("op", op))
+
+	status, err := h.schema.Status(r.Context())
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, schemaStatusToDTO(status))
+}
@@ -1,53 +1,469 @@
 package pullrequest
 
 import (
+	"encoding/json"
 	"time"
 
 	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/transport/http/response"
 )
 
 type CreatePullRequestRequest struct {
-	PullRequestID   string `json:"pull_request_id" validate:"required,max=64"`
+	PullRequestID   string `json:"pull_request_id" validate:"required,max=64,identifier"`
 	PullRequestName string `json:"pull_request_name" validate:"required,max=64"`
-	AuthorID        string `json:"author_id" validate:"required,max=64"`
+	AuthorID        string `json:"author_id" validate:"required,max=64,identifier"`
+	// Priority по умолчанию NORMAL
+	Priority string `json:"priority" validate:"omitempty,oneof=LOW NORMAL HIGH"`
+	// GroupID связывает PR одного эпика, необязателен
+	GroupID string `json:"group_id" validate:"omitempty,max=64,identifier"`
+	// Labels - метки PR для MANDATORY_REVIEWERS, необязательны
+	Labels []string `json:"labels" validate:"omitempty,max=20,dive,required,max=64"`
 }
 
 type MergePullRequestRequest struct {
-	PullRequestID string `json:"pull_request_id" validate:"required,max=64"`
+	PullRequestID string `json:"pull_request_id" validate:"required,max=64,identifier"`
+	// MergedBy - кто мержит PR, обязателен при MERGE_REQUIRES_ACTOR
+	MergedBy string `json:"merged_by" validate:"omitempty,max=64,identifier"`
 }
 
+// MergeBulkRequest - PR, которые мержатся по одному, как при отдельных вызовах /pullRequest/merge.
+// MergedBy проверяется для каждого PR отдельно
+type MergeBulkRequest struct {
+	PullRequestIDs []string `json:"pull_request_ids" validate:"required,min=1,max=100,dive,required,max=64,identifier"`
+	MergedBy       string   `json:"merged_by" validate:"omitempty,max=64,identifier"`
+}
+
+// BatchGetRequest - PR для /pullRequest/batchGet, не больше 100 за запрос
+type BatchGetRequest struct {
+	PullRequestIDs []string `json:"pull_request_ids" validate:"required,min=1,max=100,dive,required,max=64,identifier"`
+}
+
+// UpdatePullRequestRequest - частичное обновление, отсутствующие поля не меняются
+type UpdatePullRequestRequest struct {
+	PullRequestID   string  `json:"pull_request_id" validate:"required,max=64,identifier"`
+	PullRequestName *string `json:"pull_request_name" validate:"omitempty,min=1,max=64"`
+	Status          *string `json:"status" validate:"omitempty,oneof=OPEN MERGED"`
+	// MergedBy учитывается только вместе со status MERGED, как merged_by в /pullRequest/merge
+	MergedBy string `json:"merged_by" validate:"omitempty,max=64,identifier"`
+}
+
+// ReassignReviewerRequest - заменяется old_user_id или все old_user_ids одной транзакцией, но не оба сразу
 type ReassignReviewerRequest struct {
-	PullRequestID string `json:"pull_request_id" validate:"required,max=64"`
-	OldUserID     string `json:"old_user_id" validate:"required,max=64"`
+	PullRequestID string   `json:"pull_request_id" validate:"required,max=64,identifier"`
+	OldUserID     string   `json:"old_user_id" validate:"required_without=OldUserIDs,excluded_with=OldUserIDs,omitempty,max=64,identifier"`
+	OldUserIDs    []string `json:"old_user_ids" validate:"omitempty,min=1,max=10,unique,dive,required,max=64,identifier"`
+}
+
+type ReassignIfInactiveRequest struct {
+	PullRequestID string `json:"pull_request_id" validate:"required,max=64,identifier"`
+	UserID        string `json:"user_id" validate:"required,max=64,identifier"`
+}
+
+type AssignReviewerRequest struct {
+	PullRequestID string `json:"pull_request_id" validate:"required,max=64,identifier"`
+	UserID        string `json:"user_id" validate:"required,max=64,identifier"`
+}
+
+// SnoozeReviewRequest - until должен быть в будущем, иначе 400
+type SnoozeReviewRequest struct {
+	PullRequestID string     `json:"pull_request_id" validate:"required,max=64,identifier"`
+	UserID        string     `json:"user_id" validate:"required,max=64,identifier"`
+	Until         *time.Time `json:"until" validate:"required"`
+}
+
+type SnoozeResponse struct {
+	Snooze SnoozeDTO `json:"snooze"`
+}
+
+type SnoozeDTO struct {
+	PullRequestID string            `json:"pull_request_id"`
+	UserID        string            `json:"user_id"`
+	Until         response.JSONTime `json:"until"`
+}
+
+// SetReviewersRequest - полный новый список ревьюеров, пустой список снимает всех
+type RebalanceTeamRequest struct {
+	TeamName string `json:"team_name" validate:"required,max=64,identifier"`
+}
+
+type SetReviewersRequest struct {
+	PullRequestID string   `json:"pull_request_id" validate:"required,max=64,identifier"`
+	ReviewerIDs   []string `json:"reviewer_ids" validate:"required,dive,required,max=64,identifier"`
+	// Force разрешает менять смерженный PR и задавать больше MAX_REVIEWERS_PER_PR ревьюеров
+	Force bool `json:"force"`
+}
+
+// prStatusCodes - числовые коды статуса PR, новые статусы добавляются в конец
+var prStatusCodes = map[string]int{
+	string(domain.PRStatusOpen):   0,
+	string(domain.PRStatusMerged): 1,
+	string(domain.PRStatusClosed): 2,
+}
+
+// StatusDTO - статус PR, по умолчанию строка, по запросу клиента - числовой код из prStatusCodes
+type StatusDTO struct {
+	Value string
+
+	numeric bool
+}
+
+func (s StatusDTO) MarshalJSON() ([]byte, error) {
+	if code, ok := prStatusCodes[s.Value]; ok && s.numeric {
+		return json.Marshal(code)
+	}
+	return json.Marshal(s.Value)
 }
 
 type PullRequestDTO struct {
-	PullRequestID     string     `json:"pull_request_id"`
-	PullRequestName   string     `json:"pull_request_name"`
-	AuthorID          string     `json:"author_id"`
-	Status            string     `json:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers"`
-	CreatedAt         *time.Time `json:"createdAt,omitempty"`
-	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	PullRequestID     string             `json:"pull_request_id"`
+	PullRequestName   string             `json:"pull_request_name"`
+	AuthorID          string             `json:"author_id"`
+	Status            StatusDTO          `json:"status"`
+	Priority          string             `json:"priority"`
+	GroupID           string             `json:"group_id,omitempty"`
+	AssignedReviewers []string           `json:"assigned_reviewers"`
+	Reviewers         []ReviewerDTO      `json:"reviewers,omitempty"`
+	ReassignmentCount int                `json:"reassignment_count"`
+	Orphaned          bool               `json:"orphaned"`
+	PendingAssignment bool               `json:"pending_assignment"`
+	AuthorInactive    bool               `json:"author_inactive,omitempty"`
+	ReviewersAtMerge  *int               `json:"merged_reviewer_count,omitempty"`
+	Understaffed      bool               `json:"merged_understaffed,omitempty"`
+	MergedBy          string             `json:"merged_by,omitempty"`
+	CreatedAt         *response.JSONTime `json:"createdAt,omitempty"`
+	MergedAt          *response.JSONTime `json:"mergedAt,omitempty"`
+
+	snakeCase bool
+}
+
+// ReviewerDTO - ревьюер PR с источником назначения: AUTO, MANUAL, REASSIGNMENT, REBALANCE или ADMIN
+type ReviewerDTO struct {
+	UserID           string `json:"user_id"`
+	AssignmentSource string `json:"assignment_source"`
+}
+
+// pullRequestDTOV1 - представление PR под /api/v1, где все поля в snake_case
+type pullRequestDTOV1 struct {
+	PullRequestID     string             `json:"pull_request_id"`
+	PullRequestName   string             `json:"pull_request_name"`
+	AuthorID          string             `json:"author_id"`
+	Status            StatusDTO          `json:"status"`
+	Priority          string             `json:"priority"`
+	GroupID           string             `json:"group_id,omitempty"`
+	AssignedReviewers []string           `json:"assigned_reviewers"`
+	Reviewers         []ReviewerDTO      `json:"reviewers,omitempty"`
+	ReassignmentCount int                `json:"reassignment_count"`
+	Orphaned          bool               `json:"orphaned"`
+	PendingAssignment bool               `json:"pending_assignment"`
+	AuthorInactive    bool               `json:"author_inactive,omitempty"`
+	ReviewersAtMerge  *int               `json:"merged_reviewer_count,omitempty"`
+	Understaffed      bool               `json:"merged_understaffed,omitempty"`
+	MergedBy          string             `json:"merged_by,omitempty"`
+	CreatedAt         *response.JSONTime `json:"created_at,omitempty"`
+	MergedAt          *response.JSONTime `json:"merged_at,omitempty"`
+}
+
+// старые маршруты сохраняют createdAt/mergedAt ради обратной совместимости
+func (d PullRequestDTO) MarshalJSON() ([]byte, error) {
+	if !d.snakeCase {
+		type legacy PullRequestDTO
+		return json.Marshal(legacy(d))
+	}
+
+	return json.Marshal(pullRequestDTOV1{
+		PullRequestID:     d.PullRequestID,
+		PullRequestName:   d.PullRequestName,
+		AuthorID:          d.AuthorID,
+		Status:            d.Status,
+		Priority:          d.Priority,
+		GroupID:           d.GroupID,
+		AssignedReviewers: d.AssignedReviewers,
+		Reviewers:         d.Reviewers,
+		ReassignmentCount: d.ReassignmentCount,
+		Orphaned:          d.Orphaned,
+		PendingAssignment: d.PendingAssignment,
+		AuthorInactive:    d.AuthorInactive,
+		ReviewersAtMerge:  d.ReviewersAtMerge,
+		Understaffed:      d.Understaffed,
+		MergedBy:          d.MergedBy,
+		CreatedAt:         d.CreatedAt,
+		MergedAt:          d.MergedAt,
+	})
 }
 
 type PullRequestResponse struct {
 	PR PullRequestDTO `json:"pr"`
 }
 
+// RecentPullRequestDTO - элемент ленты /pullRequest/recent. Маршрут новый, поэтому created_at
+// в snake_case и под старым префиксом
+type RecentPullRequestDTO struct {
+	PullRequestID   string            `json:"pull_request_id"`
+	PullRequestName string            `json:"pull_request_name"`
+	AuthorID        string            `json:"author_id"`
+	AuthorName      string            `json:"author_name"`
+	Status          StatusDTO         `json:"status"`
+	Orphaned        bool              `json:"orphaned"`
+	ReviewerCount   int               `json:"reviewer_count"`
+	CreatedAt       response.JSONTime `json:"created_at"`
+}
+
+func recentPRToDTO(pr domain.RecentPullRequest, numericStatus bool) RecentPullRequestDTO {
+	return RecentPullRequestDTO{
+		PullRequestID:   pr.PullRequestID,
+		PullRequestName: pr.PullRequestName,
+		AuthorID:        pr.AuthorID,
+		AuthorName:      pr.AuthorName,
+		Status:          StatusDTO{Value: string(pr.Status), numeric: numericStatus},
+		Orphaned:        pr.Orphaned,
+		ReviewerCount:   pr.ReviewerCount,
+		CreatedAt:       response.NewJSONTime(pr.CreatedAt),
+	}
+}
+
+// RecentPullRequestsResponse - последние созданные PR, новые первыми
+type RecentPullRequestsResponse struct {
+	PullRequests []RecentPullRequestDTO `json:"pull_requests"`
+}
+
+// BatchGetResponse - найденные PR в порядке запроса, неизвестные ID пропущены
+type BatchGetResponse struct {
+	PullRequests []PullRequestDTO `json:"pull_requests"`
+}
+
+// PRUpdateDTO - data события SSE-потока /pullRequest/events, event совпадает с type
+type PRUpdateDTO struct {
+	Type             string            `json:"type"`
+	PullRequestID    string            `json:"pull_request_id"`
+	UserID           string            `json:"user_id,omitempty"`
+	AssignmentSource string            `json:"assignment_source,omitempty"`
+	OccurredAt       response.JSONTime `json:"occurred_at"`
+}
+
+func prUpdateToDTO(u domain.PRUpdate) PRUpdateDTO {
+	return PRUpdateDTO{
+		Type:             string(u.Type),
+		PullRequestID:    u.PullRequestID,
+		UserID:           u.UserID,
+		AssignmentSource: string(u.Source),
+		OccurredAt:       response.NewJSONTime(u.OccurredAt),
+	}
+}
+
 type ReassignResponse struct {
 	PR         PullRequestDTO `json:"pr"`
 	ReplacedBy string         `json:"replaced_by"`
 }
 
+// ReassignReviewersResponse - ответ на reassign с old_user_ids: пары замен в порядке запроса
+// и ревьюеры, которых при PARTIAL_REASSIGN_POLICY=fill заменить было некем
+type ReassignReviewersResponse struct {
+	PR           PullRequestDTO   `json:"pr"`
+	Replacements []ReplacementDTO `json:"replacements"`
+	Unreplaced   []string         `json:"unreplaced"`
+}
+
+type ReplacementDTO struct {
+	OldUserID string `json:"old_user_id"`
+	NewUserID string `json:"new_user_id"`
+}
+
+// ReassignIfInactiveResponse - reassigned=false, если ревьюер активен и остался на PR
+type ReassignIfInactiveResponse struct {
+	PR         PullRequestDTO `json:"pr"`
+	Reassigned bool           `json:"reassigned"`
+	ReplacedBy string         `json:"replaced_by,omitempty"`
+}
+
+// CandidateBreakdownDTO - diagnostics ответа NO_CANDIDATE при переназначении
+type CandidateBreakdownDTO struct {
+	TeamMembers   int `json:"team_members"`
+	Active        int `json:"active"`
+	Excluded      int `json:"excluded"`
+	OverCapacity  int `json:"over_capacity"`
+	Remaining     int `json:"remaining"`
+	CandidatePool int `json:"candidate_pool"`
+}
+
+func breakdownToDTO(b domain.CandidateBreakdown) CandidateBreakdownDTO {
+	return CandidateBreakdownDTO{
+		TeamMembers:   b.TeamMembers,
+		Active:        b.Active,
+		Excluded:      b.Excluded,
+		OverCapacity:  b.OverCapacity,
+		Remaining:     b.Remaining,
+		CandidatePool: b.CandidatePool,
+	}
+}
+
+// ReassignRateLimitDTO - retry_at - самое раннее время, когда замена ревьюера будет разрешена
+type ReassignRateLimitDTO struct {
+	Limit   int       `json:"limit"`
+	RetryAt time.Time `json:"retry_at"`
+}
+
+func rateLimitToDTO(l domain.ReassignRateLimit) ReassignRateLimitDTO {
+	return ReassignRateLimitDTO{Limit: l.Limit, RetryAt: l.RetryAt.UTC()}
+}
+
+// ReviewerLatencyDTO - задержка от назначения до мержа в секундах
+type ReviewerLatencyDTO struct {
+	UserID        string  `json:"user_id"`
+	Samples       int     `json:"samples"`
+	MedianSeconds float64 `json:"median_seconds"`
+	P90Seconds    float64 `json:"p90_seconds"`
+}
+
+type ReviewLatencyResponse struct {
+	TeamName  string               `json:"team_name"`
+	Reviewers []ReviewerLatencyDTO `json:"reviewers"`
+}
+
+// TeamPolicyResponse - overrides перечисляет поля, которые для команды отличаются от глобальных
+type TeamPolicyResponse struct {
+	TeamName                   string   `json:"team_name"`
+	MaxReviewers               int      `json:"max_reviewers"`
+	ReviewersPerPR             int      `json:"reviewers_per_pr"`
+	MaxOpenReviews             int      `json:"max_open_reviews"`
+	NoReviewersPolicy          string   `json:"no_reviewers_policy"`
+	ExcludeOutsideWorkingHours bool     `json:"exclude_outside_working_hours"`
+	AllowCrossTeamReviewers    bool     `json:"allow_cross_team_reviewers"`
+	ExcludeGroupAuthors        bool     `json:"exclude_group_authors"`
+	RetryUnderstaffed          bool     `json:"retry_understaffed"`
+	AutoAssign                 bool     `json:"auto_assign"`
+	Overrides                  []string `json:"overrides"`
+}
+
+func teamPolicyToDTO(policy domain.TeamPolicy) TeamPolicyResponse {
+	return TeamPolicyResponse{
+		TeamName:                   policy.TeamName,
+		MaxReviewers:               policy.MaxReviewers,
+		ReviewersPerPR:             policy.ReviewersPerPR,
+		MaxOpenReviews:             policy.MaxOpenReviews,
+		NoReviewersPolicy:          policy.NoReviewersPolicy,
+		ExcludeOutsideWorkingHours: policy.ExcludeOutsideWorkingHours,
+		AllowCrossTeamReviewers:    policy.AllowCrossTeamReviewers,
+		ExcludeGroupAuthors:        policy.ExcludeGroupAuthors,
+		RetryUnderstaffed:          policy.RetryUnderstaffed,
+		AutoAssign:                 policy.AutoAssign,
+		Overrides:                  response.EmptyIfNil(policy.Overrides),
+	}
+}
+
+func latencyToDTO(latency domain.ReviewerLatency) ReviewerLatencyDTO {
+	return ReviewerLatencyDTO{
+		UserID:        latency.UserID,
+		Samples:       latency.Samples,
+		MedianSeconds: latency.Median.Seconds(),
+		P90Seconds:    latency.P90.Seconds(),
+	}
+}
+
+// ReviewerQueueDTO - ahead открытых PR ревьюера назначены раньше этого. median_turnaround_seconds - null,
+// если за последние 30 дней у ревьюера мало смерженных ревью
+type ReviewerQueueDTO struct {
+	UserID                  string            `json:"user_id"`
+	AssignedAt              response.JSONTime `json:"assigned_at"`
+	Ahead                   int               `json:"ahead"`
+	MedianTurnaroundSeconds *float64          `json:"median_turnaround_seconds"`
+}
+
+// QueueInfoResponse - у смерженного или закрытого PR queue пуст
+type QueueInfoResponse struct {
+	PullRequestID string             `json:"pull_request_id"`
+	Status        StatusDTO          `json:"status"`
+	MergedAt      *response.JSONTime `json:"merged_at,omitempty"`
+	Queue         []ReviewerQueueDTO `json:"queue"`
+}
+
+func queueInfoToDTO(info domain.PRQueueInfo, numericStatus bool) QueueInfoResponse {
+	queue := make([]ReviewerQueueDTO, len(info.Reviewers))
+	for i, reviewer := range info.Reviewers {
+		queue[i] = ReviewerQueueDTO{
+			UserID:     reviewer.UserID,
+			AssignedAt: response.NewJSONTime(reviewer.AssignedAt),
+			Ahead:      reviewer.Ahead,
+		}
+		if reviewer.MedianTurnaround != nil {
+			seconds := reviewer.MedianTurnaround.Seconds()
+			queue[i].MedianTurnaroundSeconds = &seconds
+		}
+	}
+	return QueueInfoResponse{
+		PullRequestID: info.PullRequestID,
+		Status:        StatusDTO{Value: string(info.Status), numeric: numericStatus},
+		MergedAt:      response.OptionalJSONTime(info.MergedAt),
+		Queue:         queue,
+	}
+}
+
+type ReviewerIDsResponse struct {
+	PullRequestID string   `json:"pull_request_id"`
+	Reviewers     []string `json:"reviewers"`
+}
+
+type MovedReviewDTO struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldUserID     string `json:"old_user_id"`
+	NewUserID     string `json:"new_user_id"`
+}
+
+// RebalanceTeamResponse - перенесённые ревью и нагрузка участников до и после
+type RebalanceTeamResponse struct {
+	TeamName   string           `json:"team_name"`
+	Moved      []MovedReviewDTO `json:"moved"`
+	LoadBefore map[string]int   `json:"load_before"`
+	LoadAfter  map[string]int   `json:"load_after"`
+}
+
+func rebalanceToDTO(teamName string, report domain.RebalanceReport) RebalanceTeamResponse {
+	moved := make([]MovedReviewDTO, len(report.Moved))
+	for i, m := range report.Moved {
+		moved[i] = MovedReviewDTO{
+			PullRequestID: m.PullRequestID,
+			OldUserID:     m.OldUserID,
+			NewUserID:     m.NewUserID,
+		}
+	}
+
+	resp := RebalanceTeamResponse{
+		TeamName:   teamName,
+		Moved:      moved,
+		LoadBefore: report.LoadBefore,
+		LoadAfter:  report.LoadAfter,
+	}
+	if resp.LoadBefore == nil {
+		resp.LoadBefore = map[string]int{}
+	}
+	if resp.LoadAfter == nil {
+		resp.LoadAfter = map[string]int{}
+	}
+	return resp
+}
+
 func prToDTO(pr domain.PullRequest) PullRequestDTO {
+	var reviewers []ReviewerDTO
+	for _, reviewer := range pr.Reviewers {
+		reviewers = append(reviewers, ReviewerDTO{UserID: reviewer.UserID, AssignmentSource: string(reviewer.Source)})
+	}
+
 	return PullRequestDTO{
 		PullRequestID:     pr.PullRequestID,
 		PullRequestName:   pr.PullRequestName,
 		AuthorID:          pr.AuthorID,
-		Status:            string(pr.Status),
-		AssignedReviewers: pr.AssignedReviewers,
-		CreatedAt:         pr.CreatedAt,
-		MergedAt:          pr.MergedAt,
+		Status:            StatusDTO{Value: string(pr.Status)},
+		Priority:          string(pr.Priority),
+		GroupID:           pr.GroupID,
+		AssignedReviewers: response.EmptyIfNil(pr.AssignedReviewers),
+		Reviewers:         reviewers,
+		ReassignmentCount: pr.ReassignmentCount,
+		Orphaned:          pr.Orphaned,
+		PendingAssignment: pr.PendingAssignment,
+		AuthorInactive:    pr.AuthorInactive,
+		ReviewersAtMerge:  pr.MergedReviewerCount,
+		Understaffed:      pr.MergedUnderstaffed,
+		MergedBy:          pr.MergedBy,
+		CreatedAt:         response.OptionalJSONTime(pr.CreatedAt),
+		MergedAt:          response.OptionalJSONTime(pr.MergedAt),
 	}
 }
@@ -3,33 +3,82 @@ package pullrequest
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"math"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 
 	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/transport/http/query"
 	"avito_backend_task/internal/transport/http/response"
+	"avito_backend_task/internal/transport/http/validation"
 )
 
+//go:generate mockery --name=PullRequestService --output=./mocks --case=underscore
 type PullRequestService interface {
 	CreatePullRequest(ctx context.Context, pr domain.PullRequestCreate) (*domain.PullRequest, error)
-	MergePullRequest(ctx context.Context, prID string) (*domain.PullRequest, error)
+	MergePullRequest(ctx context.Context, prID, mergedBy string) (*domain.PullRequest, error)
+	UpdatePullRequest(ctx context.Context, update domain.PullRequestUpdate) (*domain.PullRequest, error)
 	ReassignReviewer(ctx context.Context, prID string, oldUserID string) (*domain.PullRequest, string, error)
+	ReassignReviewers(ctx context.Context, prID string, oldUserIDs []string) (*domain.PullRequest, domain.ReassignResult, error)
+	ReassignIfInactive(ctx context.Context, prID string, userID string) (*domain.PullRequest, string, error)
+	AssignReviewer(ctx context.Context, prID, userID string) (*domain.PullRequest, error)
+	SelfAssign(ctx context.Context, prID, userID string) (*domain.PullRequest, error)
+	SnoozeReview(ctx context.Context, prID, userID string, until time.Time) (*domain.ReviewSnooze, error)
+	GetPullRequest(ctx context.Context, prID string) (*domain.PullRequest, error)
+	GetPullRequestsByIDs(ctx context.Context, prIDs []string) ([]domain.PullRequest, error)
+	GetRecentPullRequests(ctx context.Context, limit int) ([]domain.RecentPullRequest, error)
+	SubscribeUpdates(ctx context.Context, prID string) (<-chan domain.PRUpdate, func(), error)
+	GetReviewerIDs(ctx context.Context, prID string) ([]string, error)
+	SetReviewers(ctx context.Context, prID string, reviewerIDs []string, force bool) (*domain.PullRequest, error)
+	RebalanceTeam(ctx context.Context, teamName string) (*domain.RebalanceReport, error)
+	GetReviewLatency(ctx context.Context, teamName string, from, to *time.Time) ([]domain.ReviewerLatency, error)
+	GetQueueInfo(ctx context.Context, prID string) (*domain.PRQueueInfo, error)
+	GetTeamPolicy(ctx context.Context, teamName string) (*domain.TeamPolicy, error)
 }
 
+const (
+	defaultRecentLimit = 20
+	maxRecentLimit     = 100
+
+	// selectionStrategyHeader переопределяет стратегию выбора ревьюеров для одного POST /pullRequest/create
+	selectionStrategyHeader = "X-Selection-Strategy"
+)
+
 type PullRequestHandler struct {
 	service   PullRequestService
 	lg        *slog.Logger
 	validator *validator.Validate
+	snakeCase bool
 }
 
-func NewPullRequestHandler(service PullRequestService, lg *slog.Logger, validator *validator.Validate) *PullRequestHandler {
-	return &PullRequestHandler{
+type Option func(*PullRequestHandler)
+
+// WithSnakeCase отдаёт поля времени PR как created_at/merged_at (формат /api/v1)
+func WithSnakeCase() Option {
+	return func(h *PullRequestHandler) {
+		h.snakeCase = true
+	}
+}
+
+func NewPullRequestHandler(service PullRequestService, lg *slog.Logger, validator *validator.Validate, opts ...Option) *PullRequestHandler {
+	h := &PullRequestHandler{
 		service:   service,
 		lg:        lg,
 		validator: validator,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
 // POST /pullRequest/create
@@ -40,13 +89,21 @@ func (h *PullRequestHandler) CreatePullRequest(w http.ResponseWriter, r *http.Re
 	var req CreatePullRequestRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Debug("failed to decode request body", slog.String("error", err.Error()))
-		response.RespondError(w, response.ErrInvalidRequest)
+		response.RespondError(w, log, response.ErrInvalidRequest)
 		return
 	}
 
 	if err := h.validator.Struct(req); err != nil {
 		log.Debug("validation failed", slog.String("error", err.Error()))
-		response.RespondError(w, response.ErrInvalidRequest)
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	// стратегия из заголовка действует только на этот PR, например для экспериментов в проде
+	strategy := domain.SelectionStrategy(r.Header.Get(selectionStrategyHeader))
+	if !strategy.Valid() {
+		log.Debug("unknown selection strategy", slog.String("strategy", string(strategy)))
+		response.RespondError(w, log, response.InvalidRequest(response.FieldError{Field: selectionStrategyHeader, Rule: "oneof"}))
 		return
 	}
 
@@ -54,20 +111,31 @@ func (h *PullRequestHandler) CreatePullRequest(w http.ResponseWriter, r *http.Re
 		PullRequestID:   req.PullRequestID,
 		PullRequestName: req.PullRequestName,
 		AuthorID:        req.AuthorID,
+		Priority:        domain.PRPriority(req.Priority).OrDefault(),
+		GroupID:         req.GroupID,
+		Labels:          req.Labels,
+		Strategy:        strategy,
 	}
 
 	pr, err := h.service.CreatePullRequest(r.Context(), prCreate)
 	if err != nil {
-		log.Error("failed to create pull request", slog.Any("error", err))
-		response.RespondError(w, err)
+		var conflict *domain.ConflictError
+		if errors.As(err, &conflict) {
+			if existing, ok := conflict.Payload.(*domain.PullRequest); ok && existing != nil {
+				response.RespondConflict(w, log, err, h.prToDTO(r, *existing))
+				return
+			}
+		}
+		response.RespondError(w, log, err)
 		return
 	}
 
 	responseDTO := PullRequestResponse{
-		PR: prToDTO(*pr),
+		PR: h.prToDTO(r, *pr),
 	}
 
-	response.RespondJSON(w, http.StatusCreated, responseDTO)
+	location := response.Location(r, "/pullRequest/get", url.Values{"pull_request_id": {pr.PullRequestID}})
+	response.RespondCreated(w, location, responseDTO)
 }
 
 // POST /pullRequest/merge
@@ -78,25 +146,108 @@ func (h *PullRequestHandler) MergePullRequest(w http.ResponseWriter, r *http.Req
 	var req MergePullRequestRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Debug("failed to decode request body", slog.String("error", err.Error()))
-		response.RespondError(w, response.ErrInvalidRequest)
+		response.RespondError(w, log, response.ErrInvalidRequest)
 		return
 	}
 
 	if err := h.validator.Struct(req); err != nil {
 		log.Debug("validation failed", slog.String("error", err.Error()))
-		response.RespondError(w, response.ErrInvalidRequest)
+		response.RespondError(w, log, validation.Details(err))
 		return
 	}
 
-	pr, err := h.service.MergePullRequest(r.Context(), req.PullRequestID)
+	pr, err := h.service.MergePullRequest(r.Context(), req.PullRequestID, req.MergedBy)
 	if err != nil {
-		log.Error("failed to merge pull request", slog.Any("error", err))
-		response.RespondError(w, err)
+		response.RespondError(w, log, err)
 		return
 	}
 
 	responseDTO := PullRequestResponse{
-		PR: prToDTO(*pr),
+		PR: h.prToDTO(r, *pr),
+	}
+
+	response.RespondJSON(w, http.StatusOK, responseDTO)
+}
+
+// POST /pullRequest/mergeBulk
+func (h *PullRequestHandler) MergeBulk(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.MergeBulk"
+	log := h.lg.With(slog.String("op", op))
+
+	var req MergeBulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	// каждый PR мержится в своей транзакции: отказ одного не откатывает остальные
+	result := response.NewBulkResult[PullRequestDTO](len(req.PullRequestIDs))
+	for i, prID := range req.PullRequestIDs {
+		pr, err := h.service.MergePullRequest(r.Context(), prID, req.MergedBy)
+		if response.ClientGone(err) {
+			response.RespondError(w, log, err)
+			return
+		}
+		if err != nil {
+			log.Debug("bulk merge item failed", slog.String("pr_id", prID), slog.String("error", err.Error()))
+			result.Fail(i, err)
+			continue
+		}
+		result.Succeed(i, http.StatusOK, h.prToDTO(r, *pr))
+	}
+
+	response.RespondBulk(w, log, result)
+}
+
+// PATCH /pullRequest
+func (h *PullRequestHandler) UpdatePullRequest(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.UpdatePullRequest"
+	log := h.lg.With(slog.String("op", op))
+
+	var req UpdatePullRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	if req.PullRequestName == nil && req.Status == nil {
+		log.Debug("no fields to update")
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	update := domain.PullRequestUpdate{
+		PullRequestID:   req.PullRequestID,
+		PullRequestName: req.PullRequestName,
+		MergedBy:        req.MergedBy,
+	}
+	if req.Status != nil {
+		status := domain.PRStatus(*req.Status)
+		update.Status = &status
+	}
+
+	pr, err := h.service.UpdatePullRequest(r.Context(), update)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	responseDTO := PullRequestResponse{
+		PR: h.prToDTO(r, *pr),
 	}
 
 	response.RespondJSON(w, http.StatusOK, responseDTO)
@@ -110,27 +261,529 @@ func (h *PullRequestHandler) ReassignReviewer(w http.ResponseWriter, r *http.Req
 	var req ReassignReviewerRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Debug("failed to decode request body", slog.String("error", err.Error()))
-		response.RespondError(w, response.ErrInvalidRequest)
+		response.RespondError(w, log, response.ErrInvalidRequest)
 		return
 	}
 
 	if err := h.validator.Struct(req); err != nil {
 		log.Debug("validation failed", slog.String("error", err.Error()))
-		response.RespondError(w, response.ErrInvalidRequest)
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	if len(req.OldUserIDs) > 0 {
+		pr, result, err := h.service.ReassignReviewers(r.Context(), req.PullRequestID, req.OldUserIDs)
+		if err != nil {
+			respondReassignError(w, log, err)
+			return
+		}
+
+		responseDTO := ReassignReviewersResponse{
+			PR:           h.prToDTO(r, *pr),
+			Replacements: make([]ReplacementDTO, len(result.Replacements)),
+			Unreplaced:   append([]string{}, result.Unreplaced...),
+		}
+		for i, replacement := range result.Replacements {
+			responseDTO.Replacements[i] = ReplacementDTO{OldUserID: replacement.OldUserID, NewUserID: replacement.NewUserID}
+		}
+		response.RespondJSON(w, http.StatusOK, responseDTO)
 		return
 	}
 
 	pr, newReviewerID, err := h.service.ReassignReviewer(r.Context(), req.PullRequestID, req.OldUserID)
 	if err != nil {
-		log.Error("failed to reassign reviewer", slog.Any("error", err))
-		response.RespondError(w, err)
+		respondReassignError(w, log, err)
 		return
 	}
 
 	responseDTO := ReassignResponse{
-		PR:         prToDTO(*pr),
+		PR:         h.prToDTO(r, *pr),
+		ReplacedBy: newReviewerID,
+	}
+
+	response.RespondJSON(w, http.StatusOK, responseDTO)
+}
+
+// POST /pullRequest/reassignIfInactive
+func (h *PullRequestHandler) ReassignIfInactive(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.ReassignIfInactive"
+	log := h.lg.With(slog.String("op", op))
+
+	var req ReassignIfInactiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	pr, newReviewerID, err := h.service.ReassignIfInactive(r.Context(), req.PullRequestID, req.UserID)
+	if err != nil {
+		respondReassignError(w, log, err)
+		return
+	}
+
+	responseDTO := ReassignIfInactiveResponse{
+		PR:         h.prToDTO(r, *pr),
+		Reassigned: newReviewerID != "",
 		ReplacedBy: newReviewerID,
 	}
 
 	response.RespondJSON(w, http.StatusOK, responseDTO)
 }
+
+// POST /pullRequest/assign
+func (h *PullRequestHandler) AssignReviewer(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.AssignReviewer"
+	log := h.lg.With(slog.String("op", op))
+
+	var req AssignReviewerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	pr, err := h.service.AssignReviewer(r.Context(), req.PullRequestID, req.UserID)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	responseDTO := PullRequestResponse{
+		PR: h.prToDTO(r, *pr),
+	}
+
+	response.RespondJSON(w, http.StatusOK, responseDTO)
+}
+
+// POST /pullRequest/selfAssign
+func (h *PullRequestHandler) SelfAssign(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.SelfAssign"
+	log := h.lg.With(slog.String("op", op))
+
+	var req AssignReviewerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	pr, err := h.service.SelfAssign(r.Context(), req.PullRequestID, req.UserID)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	responseDTO := PullRequestResponse{
+		PR: h.prToDTO(r, *pr),
+	}
+
+	response.RespondJSON(w, http.StatusOK, responseDTO)
+}
+
+// POST /pullRequest/snooze
+func (h *PullRequestHandler) SnoozeReview(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.SnoozeReview"
+	log := h.lg.With(slog.String("op", op))
+
+	var req SnoozeReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	snooze, err := h.service.SnoozeReview(r.Context(), req.PullRequestID, req.UserID, *req.Until)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, SnoozeResponse{
+		Snooze: SnoozeDTO{
+			PullRequestID: snooze.PullRequestID,
+			UserID:        snooze.UserID,
+			Until:         response.NewJSONTime(snooze.Until),
+		},
+	})
+}
+
+// GET /pullRequest/reviewerIds?pull_request_id
+// GET /pullRequest/get?pull_request_id
+func (h *PullRequestHandler) GetPullRequest(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.GetPullRequest"
+	log := h.lg.With(slog.String("op", op))
+
+	prID, err := query.RequiredID(r, "pull_request_id")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	pr, err := h.service.GetPullRequest(r.Context(), prID)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("pr_id", prID)), err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, PullRequestResponse{PR: h.prToDTO(r, *pr)})
+}
+
+// GET /pullRequest/events?pull_request_id - SSE-поток назначений, снятий ревьюеров и мержа PR.
+// Поток завершается при отключении клиента или если он не успевает читать изменения
+func (h *PullRequestHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.StreamEvents"
+	log := h.lg.With(slog.String("op", op))
+
+	prID, err := query.RequiredID(r, "pull_request_id")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+	log = log.With(slog.String("pr_id", prID))
+
+	updates, cancel, err := h.service.SubscribeUpdates(r.Context(), prID)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+	defer cancel()
+
+	stream := response.NewEventStream(w)
+	if err := stream.Start(); err != nil {
+		log.Warn("failed to start event stream", slog.Any("error", err))
+		return
+	}
+
+	heartbeat := time.NewTicker(response.EventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if err := stream.Heartbeat(); err != nil {
+				return
+			}
+		case update, ok := <-updates:
+			if !ok {
+				log.Info("event stream subscriber fell behind, closing stream")
+				return
+			}
+			if err := stream.Send(string(update.Type), prUpdateToDTO(update)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// POST /pullRequest/batchGet
+func (h *PullRequestHandler) BatchGet(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.BatchGet"
+	log := h.lg.With(slog.String("op", op))
+
+	var req BatchGetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	prs, err := h.service.GetPullRequestsByIDs(r.Context(), req.PullRequestIDs)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	dtos := make([]PullRequestDTO, len(prs))
+	for i, pr := range prs {
+		dtos[i] = h.prToDTO(r, pr)
+	}
+	response.RespondJSON(w, http.StatusOK, BatchGetResponse{PullRequests: dtos})
+}
+
+func (h *PullRequestHandler) GetReviewerIDs(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.GetReviewerIDs"
+	log := h.lg.With(slog.String("op", op))
+
+	prID, err := query.RequiredID(r, "pull_request_id")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	reviewerIDs, err := h.service.GetReviewerIDs(r.Context(), prID)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("pr_id", prID)), err)
+		return
+	}
+
+	responseDTO := ReviewerIDsResponse{
+		PullRequestID: prID,
+		Reviewers:     response.EmptyIfNil(reviewerIDs),
+	}
+
+	response.RespondJSON(w, http.StatusOK, responseDTO)
+}
+
+// POST /team/rebalance
+func (h *PullRequestHandler) RebalanceTeam(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.RebalanceTeam"
+	log := h.lg.With(slog.String("op", op))
+
+	var req RebalanceTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	report, err := h.service.RebalanceTeam(r.Context(), req.TeamName)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("team_name", req.TeamName)), err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, rebalanceToDTO(req.TeamName, *report))
+}
+
+// GET /pullRequest/reviewLatency?team_name&from&to
+func (h *PullRequestHandler) GetReviewLatency(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.GetReviewLatency"
+	log := h.lg.With(slog.String("op", op))
+
+	teamName, err := query.RequiredID(r, "team_name")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	from, err := query.Time(r, "from")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	to, err := query.Time(r, "to")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	latencies, err := h.service.GetReviewLatency(r.Context(), teamName, from, to)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("team_name", teamName)), err)
+		return
+	}
+
+	reviewers := make([]ReviewerLatencyDTO, len(latencies))
+	for i, latency := range latencies {
+		reviewers[i] = latencyToDTO(latency)
+	}
+
+	response.RespondJSON(w, http.StatusOK, ReviewLatencyResponse{
+		TeamName:  teamName,
+		Reviewers: reviewers,
+	})
+}
+
+// GET /pullRequest/queueInfo?pull_request_id
+func (h *PullRequestHandler) GetQueueInfo(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.GetQueueInfo"
+	log := h.lg.With(slog.String("op", op))
+
+	prID, err := query.RequiredID(r, "pull_request_id")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	info, err := h.service.GetQueueInfo(r.Context(), prID)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("pr_id", prID)), err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, queueInfoToDTO(*info, numericStatusRequested(r)))
+}
+
+// GET /pullRequest/recent?limit
+func (h *PullRequestHandler) GetRecentPullRequests(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.GetRecentPullRequests"
+	log := h.lg.With(slog.String("op", op))
+
+	limit, err := query.Int(r, "limit", defaultRecentLimit)
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+	if limit <= 0 {
+		response.RespondError(w, log, response.InvalidRequest(response.FieldError{Field: "limit", Rule: "min=1"}))
+		return
+	}
+	if limit > maxRecentLimit {
+		response.RespondError(w, log, response.InvalidRequest(response.FieldError{
+			Field: "limit", Rule: "max=" + strconv.Itoa(maxRecentLimit),
+		}))
+		return
+	}
+
+	prs, err := h.service.GetRecentPullRequests(r.Context(), limit)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	numeric := numericStatusRequested(r)
+	dtos := make([]RecentPullRequestDTO, len(prs))
+	for i, pr := range prs {
+		dtos[i] = recentPRToDTO(pr, numeric)
+	}
+
+	response.RespondJSON(w, http.StatusOK, RecentPullRequestsResponse{PullRequests: dtos})
+}
+
+// GET /team/policy?team_name
+func (h *PullRequestHandler) GetTeamPolicy(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.GetTeamPolicy"
+	log := h.lg.With(slog.String("op", op))
+
+	teamName, err := query.RequiredID(r, "team_name")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	policy, err := h.service.GetTeamPolicy(r.Context(), teamName)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("team_name", teamName)), err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, teamPolicyToDTO(*policy))
+}
+
+// respondReassignError отдаёт NO_CANDIDATE с разбивкой кандидатов в diagnostics, если сервис её собрал,
+// а REASSIGNMENT_RATE_EXCEEDED - с временем следующей попытки в diagnostics и Retry-After
+func respondReassignError(w http.ResponseWriter, log *slog.Logger, err error) {
+	var conflict *domain.ConflictError
+	if errors.As(err, &conflict) {
+		switch payload := conflict.Payload.(type) {
+		case *domain.CandidateBreakdown:
+			if payload != nil {
+				response.RespondDiagnostics(w, log, err, breakdownToDTO(*payload))
+				return
+			}
+		case *domain.ReassignRateLimit:
+			if payload != nil {
+				retryAfter := max(int(math.Ceil(payload.RetryAfter.Seconds())), 1)
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				response.RespondDiagnostics(w, log, err, rateLimitToDTO(*payload))
+				return
+			}
+		}
+	}
+	response.RespondError(w, log, err)
+}
+
+func (h *PullRequestHandler) prToDTO(r *http.Request, pr domain.PullRequest) PullRequestDTO {
+	dto := prToDTO(pr)
+	dto.snakeCase = h.snakeCase
+	dto.Status.numeric = numericStatusRequested(r)
+	return dto
+}
+
+// numericStatusRequested - клиент попросил числовой статус через ?status_format=numeric
+// или параметр медиатипа Accept: application/json; status=numeric
+func numericStatusRequested(r *http.Request) bool {
+	if r.URL.Query().Get("status_format") == "numeric" {
+		return true
+	}
+
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			_, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err == nil && params["status"] == "numeric" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// POST /admin/pullRequest/setReviewers
+func (h *PullRequestHandler) SetReviewers(w http.ResponseWriter, r *http.Request) {
+	op := "PullRequestHandler.SetReviewers"
+	log := h.lg.With(slog.String("op", op))
+
+	var req SetReviewersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	pr, err := h.service.SetReviewers(r.Context(), req.PullRequestID, req.ReviewerIDs, req.Force)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	responseDTO := PullRequestResponse{
+		PR: h.prToDTO(r, *pr),
+	}
+
+	response.RespondJSON(w, http.StatusOK, responseDTO)
+}
@@ -0,0 +1,999 @@
+package pullrequest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/live"
+	"avito_backend_task/internal/transport/http/handlers/pullrequest/mocks"
+	"avito_backend_task/internal/transport/http/response"
+	"avito_backend_task/internal/transport/http/validation"
+)
+
+func setupTestHandler(t *testing.T, opts ...Option) (*PullRequestHandler, *mocks.PullRequestService) {
+	service := mocks.NewPullRequestService(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	return NewPullRequestHandler(service, logger, validation.New(), opts...), service
+}
+
+func TestPullRequestHandler_GetReviewerIDs(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		setupMocks func(*mocks.PullRequestService)
+		wantStatus int
+		validate   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "found",
+			query: "?pull_request_id=pr1",
+			setupMocks: func(service *mocks.PullRequestService) {
+				service.On("GetReviewerIDs", mock.Anything, "pr1").Return([]string{"u2", "u3"}, nil)
+			},
+			wantStatus: http.StatusOK,
+			validate: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var resp ReviewerIDsResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				assert.Equal(t, "pr1", resp.PullRequestID)
+				assert.Equal(t, []string{"u2", "u3"}, resp.Reviewers)
+			},
+		},
+		{
+			name:  "found without reviewers",
+			query: "?pull_request_id=pr1",
+			setupMocks: func(service *mocks.PullRequestService) {
+				service.On("GetReviewerIDs", mock.Anything, "pr1").Return(nil, nil)
+			},
+			wantStatus: http.StatusOK,
+			validate: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"pull_request_id":"pr1","reviewers":[]}`, rec.Body.String())
+			},
+		},
+		{
+			name:  "not found",
+			query: "?pull_request_id=missing",
+			setupMocks: func(service *mocks.PullRequestService) {
+				service.On("GetReviewerIDs", mock.Anything, "missing").Return(nil, domain.ErrPRNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			validate: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var resp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				assert.Equal(t, response.ErrorCodeNotFound, resp.Error.Code)
+			},
+		},
+		{
+			name:       "missing pull_request_id",
+			query:      "",
+			setupMocks: func(service *mocks.PullRequestService) {},
+			wantStatus: http.StatusBadRequest,
+			validate:   func(t *testing.T, rec *httptest.ResponseRecorder) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t)
+			tt.setupMocks(service)
+
+			req := httptest.NewRequest(http.MethodGet, "/pullRequest/reviewerIds"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			handler.GetReviewerIDs(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			tt.validate(t, rec)
+		})
+	}
+}
+
+func TestPullRequestHandler_BatchGet(t *testing.T) {
+	t.Run("returns found PRs in order", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("GetPullRequestsByIDs", mock.Anything, []string{"pr2", "missing", "pr1"}).Return([]domain.PullRequest{
+			{PullRequestID: "pr2", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u3"}},
+			{PullRequestID: "pr1", Status: domain.PRStatusMerged},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/batchGet",
+			strings.NewReader(`{"pull_request_ids":["pr2","missing","pr1"]}`))
+		rec := httptest.NewRecorder()
+
+		handler.BatchGet(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var resp struct {
+			PullRequests []struct {
+				PullRequestID     string   `json:"pull_request_id"`
+				AssignedReviewers []string `json:"assigned_reviewers"`
+			} `json:"pull_requests"`
+		}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		require.Len(t, resp.PullRequests, 2)
+		assert.Equal(t, "pr2", resp.PullRequests[0].PullRequestID)
+		assert.Equal(t, []string{"u3"}, resp.PullRequests[0].AssignedReviewers)
+		assert.Equal(t, "pr1", resp.PullRequests[1].PullRequestID)
+	})
+
+	t.Run("batch over limit", func(t *testing.T) {
+		handler, _ := setupTestHandler(t)
+		ids := make([]string, 101)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("pr%d", i)
+		}
+		body, err := json.Marshal(BatchGetRequest{PullRequestIDs: ids})
+		require.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		handler.BatchGet(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/batchGet", bytes.NewReader(body)))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"rule":"max=100"`)
+	})
+}
+
+// контрактные тесты: формат старых маршрутов и /api/v1 не должен меняться
+func TestPullRequestHandler_ResponseShape(t *testing.T) {
+	createdAt := time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC)
+	mergedAt := time.Date(2025, 11, 2, 12, 30, 0, 0, time.UTC)
+	reviewerCount := 2
+	merged := &domain.PullRequest{
+		PullRequestID:       "pr1",
+		PullRequestName:     "Add search",
+		AuthorID:            "u1",
+		Status:              domain.PRStatusMerged,
+		Priority:            domain.PRPriorityHigh,
+		AssignedReviewers:   []string{"u2", "u3"},
+		ReassignmentCount:   1,
+		MergedReviewerCount: &reviewerCount,
+		CreatedAt:           &createdAt,
+		MergedAt:            &mergedAt,
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "legacy camelCase timestamps",
+			want: `{"pr":{
+				"pull_request_id":"pr1","pull_request_name":"Add search","author_id":"u1","status":"MERGED","priority":"HIGH",
+				"assigned_reviewers":["u2","u3"],"reassignment_count":1,"orphaned":false,"pending_assignment":false,
+				"merged_reviewer_count":2,"createdAt":"2025-11-01T10:00:00.000Z","mergedAt":"2025-11-02T12:30:00.000Z"}}`,
+		},
+		{
+			name: "v1 snake_case timestamps",
+			opts: []Option{WithSnakeCase()},
+			want: `{"pr":{
+				"pull_request_id":"pr1","pull_request_name":"Add search","author_id":"u1","status":"MERGED","priority":"HIGH",
+				"assigned_reviewers":["u2","u3"],"reassignment_count":1,"orphaned":false,"pending_assignment":false,
+				"merged_reviewer_count":2,"created_at":"2025-11-01T10:00:00.000Z","merged_at":"2025-11-02T12:30:00.000Z"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t, tt.opts...)
+			service.On("MergePullRequest", mock.Anything, "pr1", "").Return(merged, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/pullRequest/merge", strings.NewReader(`{"pull_request_id":"pr1"}`))
+			rec := httptest.NewRecorder()
+
+			handler.MergePullRequest(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, tt.want, rec.Body.String())
+		})
+	}
+}
+
+func TestPullRequestHandler_MergeActor(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "merged by reviewer", wantStatus: http.StatusOK},
+		{name: "actor required", err: domain.ErrMergeActorRequired, wantStatus: http.StatusUnprocessableEntity, wantCode: "MERGE_ACTOR_REQUIRED"},
+		{name: "unknown actor", err: domain.ErrMergeActorNotFound, wantStatus: http.StatusUnprocessableEntity, wantCode: "MERGE_ACTOR_NOT_FOUND"},
+		{name: "inactive actor", err: domain.ErrMergeActorInactive, wantStatus: http.StatusForbidden, wantCode: "MERGE_ACTOR_INACTIVE"},
+		{name: "outsider", err: domain.ErrMergeNotAllowed, wantStatus: http.StatusForbidden, wantCode: "MERGE_NOT_ALLOWED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t)
+			if tt.err != nil {
+				service.On("MergePullRequest", mock.Anything, "pr1", "u2").Return(nil, tt.err)
+			} else {
+				service.On("MergePullRequest", mock.Anything, "pr1", "u2").Return(&domain.PullRequest{
+					PullRequestID: "pr1", AuthorID: "u1", Status: domain.PRStatusMerged, MergedBy: "u2",
+				}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/pullRequest/merge",
+				strings.NewReader(`{"pull_request_id":"pr1","merged_by":"u2"}`))
+			rec := httptest.NewRecorder()
+
+			handler.MergePullRequest(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			var body struct {
+				PR struct {
+					MergedBy string `json:"merged_by"`
+				} `json:"pr"`
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			if tt.err != nil {
+				assert.Equal(t, tt.wantCode, body.Error.Code)
+				return
+			}
+			assert.Equal(t, "u2", body.PR.MergedBy)
+		})
+	}
+}
+
+func TestPullRequestHandler_ContextErrors(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithTimeout(context.Background(), -time.Second)
+	defer cancelExpired()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "client disconnected", ctx: canceled, wantStatus: response.StatusClientClosedRequest},
+		{
+			name:       "server deadline exceeded",
+			ctx:        expired,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"error":{"code":"TIMEOUT","message":"request timed out"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t)
+			service.On("MergePullRequest", mock.Anything, "pr1", "").
+				Return(nil, fmt.Errorf("failed to check PR existence: %w", tt.ctx.Err()))
+
+			req := httptest.NewRequest(http.MethodPost, "/pullRequest/merge", strings.NewReader(`{"pull_request_id":"pr1"}`)).
+				WithContext(tt.ctx)
+			rec := httptest.NewRecorder()
+
+			handler.MergePullRequest(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody == "" {
+				assert.Empty(t, rec.Body.String())
+				return
+			}
+			assert.JSONEq(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestPullRequestHandler_AssignReviewer(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   response.ErrorCode
+	}{
+		{name: "inactive user", err: domain.ErrReviewerInactive, wantStatus: http.StatusUnprocessableEntity, wantCode: response.ErrorCodeNotEligible},
+		{name: "author", err: domain.ErrReviewerIsAuthor, wantStatus: http.StatusUnprocessableEntity, wantCode: response.ErrorCodeNotEligible},
+		{name: "other team", err: domain.ErrReviewerNotInTeam, wantStatus: http.StatusUnprocessableEntity, wantCode: response.ErrorCodeNotEligible},
+		{name: "already assigned", err: domain.ErrAlreadyAssigned, wantStatus: http.StatusConflict, wantCode: response.ErrorCodeAssigned},
+		{name: "merged PR", err: domain.ErrPRMerged, wantStatus: http.StatusConflict, wantCode: response.ErrorCodePRMerged},
+		{name: "closed PR", err: domain.ErrPRClosed, wantStatus: http.StatusConflict, wantCode: response.ErrorCodePRClosed},
+		{name: "PR closed concurrently", err: domain.ErrPRNotOpen, wantStatus: http.StatusConflict, wantCode: response.ErrorCodePRNotOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t)
+			service.On("AssignReviewer", mock.Anything, "pr1", "u9").Return(nil, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/pullRequest/assign", strings.NewReader(`{"pull_request_id":"pr1","user_id":"u9"}`))
+			rec := httptest.NewRecorder()
+
+			handler.AssignReviewer(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			var resp response.ErrorResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, tt.wantCode, resp.Error.Code)
+		})
+	}
+
+	t.Run("cap reached reports the current count", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("AssignReviewer", mock.Anything, "pr1", "u9").Return(nil, &domain.ConflictError{
+			Err:     domain.ErrReviewerCapReached,
+			Payload: &domain.ReviewerCapLimit{Limit: 2, Reviewers: 2},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/assign", strings.NewReader(`{"pull_request_id":"pr1","user_id":"u9"}`))
+		rec := httptest.NewRecorder()
+
+		handler.AssignReviewer(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.JSONEq(t, `{
+			"error": {"code": "TOO_MANY_REVIEWERS", "message": "PR already has the maximum number of reviewers"},
+			"diagnostics": {"limit": 2, "reviewers": 2}
+		}`, rec.Body.String())
+	})
+
+	t.Run("assigned", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("AssignReviewer", mock.Anything, "pr1", "u9").Return(&domain.PullRequest{
+			PullRequestID:     "pr1",
+			Status:            domain.PRStatusOpen,
+			AssignedReviewers: []string{"u9"},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/assign", strings.NewReader(`{"pull_request_id":"pr1","user_id":"u9"}`))
+		rec := httptest.NewRecorder()
+
+		handler.AssignReviewer(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestPullRequestHandler_UpdatePullRequest(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	statusPtr := func(s domain.PRStatus) *domain.PRStatus { return &s }
+	updated := &domain.PullRequest{PullRequestID: "pr1", PullRequestName: "New", Status: domain.PRStatusMerged}
+
+	tests := []struct {
+		name       string
+		body       string
+		setupMocks func(*mocks.PullRequestService)
+		wantStatus int
+	}{
+		{
+			name: "name only",
+			body: `{"pull_request_id":"pr1","pull_request_name":"New"}`,
+			setupMocks: func(service *mocks.PullRequestService) {
+				service.On("UpdatePullRequest", mock.Anything, domain.PullRequestUpdate{
+					PullRequestID:   "pr1",
+					PullRequestName: strPtr("New"),
+				}).Return(updated, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "name and status",
+			body: `{"pull_request_id":"pr1","pull_request_name":"New","status":"MERGED"}`,
+			setupMocks: func(service *mocks.PullRequestService) {
+				service.On("UpdatePullRequest", mock.Anything, domain.PullRequestUpdate{
+					PullRequestID:   "pr1",
+					PullRequestName: strPtr("New"),
+					Status:          statusPtr(domain.PRStatusMerged),
+				}).Return(updated, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "invalid transition",
+			body: `{"pull_request_id":"pr1","status":"OPEN"}`,
+			setupMocks: func(service *mocks.PullRequestService) {
+				service.On("UpdatePullRequest", mock.Anything, mock.Anything).Return(nil, domain.ErrInvalidTransition)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "no fields",
+			body:       `{"pull_request_id":"pr1"}`,
+			setupMocks: func(*mocks.PullRequestService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "empty name",
+			body:       `{"pull_request_id":"pr1","pull_request_name":""}`,
+			setupMocks: func(*mocks.PullRequestService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown status",
+			body:       `{"pull_request_id":"pr1","status":"CLOSED"}`,
+			setupMocks: func(*mocks.PullRequestService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t)
+			tt.setupMocks(service)
+
+			req := httptest.NewRequest(http.MethodPatch, "/pullRequest", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.UpdatePullRequest(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestPullRequestHandler_CreatePullRequest_Priority(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		wantPriority domain.PRPriority
+		wantStatus   int
+	}{
+		{name: "defaults to NORMAL", body: `{"pull_request_id":"pr1","pull_request_name":"PR","author_id":"u1"}`, wantPriority: domain.PRPriorityNormal, wantStatus: http.StatusCreated},
+		{name: "HIGH", body: `{"pull_request_id":"pr1","pull_request_name":"PR","author_id":"u1","priority":"HIGH"}`, wantPriority: domain.PRPriorityHigh, wantStatus: http.StatusCreated},
+		{name: "unknown value", body: `{"pull_request_id":"pr1","pull_request_name":"PR","author_id":"u1","priority":"URGENT"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t)
+			if tt.wantPriority != "" {
+				service.On("CreatePullRequest", mock.Anything, domain.PullRequestCreate{
+					PullRequestID:   "pr1",
+					PullRequestName: "PR",
+					AuthorID:        "u1",
+					Priority:        tt.wantPriority,
+				}).Return(&domain.PullRequest{PullRequestID: "pr1", Priority: tt.wantPriority, Status: domain.PRStatusOpen}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.CreatePullRequest(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantPriority != "" {
+				assert.Contains(t, rec.Body.String(), `"priority":"`+string(tt.wantPriority)+`"`)
+			}
+		})
+	}
+}
+
+func TestPullRequestHandler_CreatePullRequest_SelectionStrategy(t *testing.T) {
+	body := `{"pull_request_id":"pr1","pull_request_name":"PR","author_id":"u1"}`
+
+	t.Run("header overrides strategy", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("CreatePullRequest", mock.Anything, domain.PullRequestCreate{
+			PullRequestID:   "pr1",
+			PullRequestName: "PR",
+			AuthorID:        "u1",
+			Priority:        domain.PRPriorityNormal,
+			Strategy:        domain.SelectionStrategyLeastLoaded,
+		}).Return(&domain.PullRequest{PullRequestID: "pr1", Status: domain.PRStatusOpen}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(body))
+		req.Header.Set("X-Selection-Strategy", "least_loaded")
+		rec := httptest.NewRecorder()
+
+		handler.CreatePullRequest(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(body))
+		req.Header.Set("X-Selection-Strategy", "round_robin")
+		rec := httptest.NewRecorder()
+
+		handler.CreatePullRequest(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		var resp response.ErrorResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		assert.Equal(t, []response.FieldError{{Field: "X-Selection-Strategy", Rule: "oneof"}}, resp.Details)
+		service.AssertNotCalled(t, "CreatePullRequest", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPullRequestHandler_SelfAssign(t *testing.T) {
+	t.Run("cap reached", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("SelfAssign", mock.Anything, "pr1", "u9").Return(nil, domain.ErrReviewerCapReached)
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/selfAssign", strings.NewReader(`{"pull_request_id":"pr1","user_id":"u9"}`))
+		rec := httptest.NewRecorder()
+
+		handler.SelfAssign(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		var resp response.ErrorResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		assert.Equal(t, response.ErrorCodeTooManyReviewers, resp.Error.Code)
+	})
+
+	t.Run("assigned", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("SelfAssign", mock.Anything, "pr1", "u9").Return(&domain.PullRequest{
+			PullRequestID:     "pr1",
+			Status:            domain.PRStatusOpen,
+			AssignedReviewers: []string{"u2", "u9"},
+			Reviewers: []domain.PRReviewer{
+				{UserID: "u2", Source: domain.AssignmentSourceAuto},
+				{UserID: "u9", Source: domain.AssignmentSourceManual},
+			},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/selfAssign", strings.NewReader(`{"pull_request_id":"pr1","user_id":"u9"}`))
+		rec := httptest.NewRecorder()
+
+		handler.SelfAssign(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"assigned_reviewers":["u2","u9"]`)
+		assert.Contains(t, rec.Body.String(),
+			`"reviewers":[{"user_id":"u2","assignment_source":"AUTO"},{"user_id":"u9","assignment_source":"MANUAL"}]`)
+	})
+}
+
+func TestPullRequestHandler_CreatePullRequest_ExistingAttached(t *testing.T) {
+	createdAt := time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC)
+	existing := &domain.PullRequest{
+		PullRequestID:     "pr1",
+		PullRequestName:   "Add search",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		Priority:          domain.PRPriorityNormal,
+		AssignedReviewers: []string{"u2", "u3"},
+		CreatedAt:         &createdAt,
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "legacy",
+			want: `{"error":{"code":"PR_EXISTS","message":"PR id already exists"},"existing":{
+				"pull_request_id":"pr1","pull_request_name":"Add search","author_id":"u1","status":"OPEN","priority":"NORMAL",
+				"assigned_reviewers":["u2","u3"],"reassignment_count":0,"orphaned":false,"pending_assignment":false,"createdAt":"2025-11-01T10:00:00.000Z"}}`,
+		},
+		{
+			name: "v1",
+			opts: []Option{WithSnakeCase()},
+			want: `{"error":{"code":"PR_EXISTS","message":"PR id already exists"},"existing":{
+				"pull_request_id":"pr1","pull_request_name":"Add search","author_id":"u1","status":"OPEN","priority":"NORMAL",
+				"assigned_reviewers":["u2","u3"],"reassignment_count":0,"orphaned":false,"pending_assignment":false,"created_at":"2025-11-01T10:00:00.000Z"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t, tt.opts...)
+			service.On("CreatePullRequest", mock.Anything, mock.Anything).
+				Return(nil, &domain.ConflictError{Err: domain.ErrPRExists, Payload: existing})
+
+			req := httptest.NewRequest(http.MethodPost, "/pullRequest/create",
+				strings.NewReader(`{"pull_request_id":"pr1","pull_request_name":"Add search","author_id":"u1"}`))
+			rec := httptest.NewRecorder()
+
+			handler.CreatePullRequest(rec, req)
+
+			require.Equal(t, http.StatusConflict, rec.Code)
+			assert.JSONEq(t, tt.want, rec.Body.String())
+		})
+	}
+
+	t.Run("plain error has no existing key", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("CreatePullRequest", mock.Anything, mock.Anything).Return(nil, domain.ErrPRExists)
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/create",
+			strings.NewReader(`{"pull_request_id":"pr1","pull_request_name":"Add search","author_id":"u1"}`))
+		rec := httptest.NewRecorder()
+
+		handler.CreatePullRequest(rec, req)
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "existing")
+		assert.Empty(t, rec.Header().Get("Location"))
+	})
+}
+
+func TestPullRequestHandler_SetReviewers(t *testing.T) {
+	t.Run("force flag is passed through", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("SetReviewers", mock.Anything, "pr1", []string{"u3"}, true).Return(&domain.PullRequest{
+			PullRequestID:     "pr1",
+			Status:            domain.PRStatusMerged,
+			AssignedReviewers: []string{"u3"},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/pullRequest/setReviewers",
+			strings.NewReader(`{"pull_request_id":"pr1","reviewer_ids":["u3"],"force":true}`))
+		rec := httptest.NewRecorder()
+
+		handler.SetReviewers(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"assigned_reviewers":["u3"]`)
+	})
+
+	t.Run("reviewer_ids is required", func(t *testing.T) {
+		handler, _ := setupTestHandler(t)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/pullRequest/setReviewers", strings.NewReader(`{"pull_request_id":"pr1"}`))
+		rec := httptest.NewRecorder()
+
+		handler.SetReviewers(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestPullRequestHandler_ReassignIfInactive(t *testing.T) {
+	pr := &domain.PullRequest{PullRequestID: "pr1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u2"}}
+
+	t.Run("active reviewer is a no-op", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("ReassignIfInactive", mock.Anything, "pr1", "u2").Return(pr, "", nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/reassignIfInactive",
+			strings.NewReader(`{"pull_request_id":"pr1","user_id":"u2"}`))
+		rec := httptest.NewRecorder()
+
+		handler.ReassignIfInactive(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"reassigned":false`)
+		assert.NotContains(t, rec.Body.String(), `"replaced_by"`)
+	})
+
+	t.Run("inactive reviewer is replaced", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("ReassignIfInactive", mock.Anything, "pr1", "u2").Return(pr, "u3", nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/reassignIfInactive",
+			strings.NewReader(`{"pull_request_id":"pr1","user_id":"u2"}`))
+		rec := httptest.NewRecorder()
+
+		handler.ReassignIfInactive(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"reassigned":true,"replaced_by":"u3"`)
+	})
+}
+
+func TestPullRequestHandler_NumericStatus(t *testing.T) {
+	merged := &domain.PullRequest{PullRequestID: "pr1", Status: domain.PRStatusMerged, AssignedReviewers: []string{}}
+
+	tests := []struct {
+		name   string
+		target string
+		accept string
+		opts   []Option
+		want   string
+	}{
+		{name: "string by default", target: "/pullRequest/merge", want: `"status":"MERGED"`},
+		{name: "query flag", target: "/pullRequest/merge?status_format=numeric", want: `"status":1`},
+		{name: "accept parameter", target: "/pullRequest/merge", accept: "text/html, application/json; status=numeric", want: `"status":1`},
+		{name: "v1 format", target: "/pullRequest/merge?status_format=numeric", opts: []Option{WithSnakeCase()}, want: `"status":1`},
+		{name: "unknown format keeps string", target: "/pullRequest/merge?status_format=roman", want: `"status":"MERGED"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t, tt.opts...)
+			service.On("MergePullRequest", mock.Anything, "pr1", "").Return(merged, nil)
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(`{"pull_request_id":"pr1"}`))
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.MergePullRequest(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.want)
+		})
+	}
+}
+
+func TestPullRequestHandler_ReassignReviewers(t *testing.T) {
+	t.Run("replacements reported in request order", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("ReassignReviewers", mock.Anything, "pr1", []string{"u2", "u3"}).Return(
+			&domain.PullRequest{PullRequestID: "pr1", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u3", "u4"}},
+			domain.ReassignResult{
+				Replacements: []domain.ReviewerReplacement{{PullRequestID: "pr1", OldUserID: "u2", NewUserID: "u4"}},
+				Unreplaced:   []string{"u3"},
+			}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/reassign",
+			strings.NewReader(`{"pull_request_id":"pr1","old_user_ids":["u2","u3"]}`))
+		rec := httptest.NewRecorder()
+
+		handler.ReassignReviewer(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"replacements":[{"old_user_id":"u2","new_user_id":"u4"}],"unreplaced":["u3"]`)
+		service.AssertNotCalled(t, "ReassignReviewer", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	for _, tt := range []struct {
+		name string
+		body string
+		want response.FieldError
+	}{
+		{name: "neither field", body: `{"pull_request_id":"pr1"}`, want: response.FieldError{Field: "old_user_id", Rule: "required_without=OldUserIDs"}},
+		{name: "both fields", body: `{"pull_request_id":"pr1","old_user_id":"u2","old_user_ids":["u3"]}`, want: response.FieldError{Field: "old_user_id", Rule: "excluded_with=OldUserIDs"}},
+		{name: "empty list", body: `{"pull_request_id":"pr1","old_user_ids":[]}`, want: response.FieldError{Field: "old_user_ids", Rule: "min=1"}},
+		{name: "duplicates", body: `{"pull_request_id":"pr1","old_user_ids":["u2","u2"]}`, want: response.FieldError{Field: "old_user_ids", Rule: "unique"}},
+		{name: "too many", body: `{"pull_request_id":"pr1","old_user_ids":["1","2","3","4","5","6","7","8","9","10","11"]}`, want: response.FieldError{Field: "old_user_ids", Rule: "max=10"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t)
+
+			rec := httptest.NewRecorder()
+			handler.ReassignReviewer(rec, httptest.NewRequest(http.MethodPost, "/pullRequest/reassign", strings.NewReader(tt.body)))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			var resp response.ErrorResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, []response.FieldError{tt.want}, resp.Details)
+			service.AssertNotCalled(t, "ReassignReviewers", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPullRequestHandler_ReassignNoCandidateDiagnostics(t *testing.T) {
+	t.Run("breakdown attached", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		breakdown := &domain.CandidateBreakdown{TeamMembers: 4, Active: 3, Excluded: 2, OverCapacity: 1, CandidatePool: 1}
+		service.On("ReassignReviewer", mock.Anything, "pr1", "u2").
+			Return(nil, "", &domain.ConflictError{Err: domain.ErrNoCandidate, Payload: breakdown})
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/reassign",
+			strings.NewReader(`{"pull_request_id":"pr1","old_user_id":"u2"}`))
+		rec := httptest.NewRecorder()
+
+		handler.ReassignReviewer(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.JSONEq(t, `{
+			"error": {"code": "NO_CANDIDATE", "message": "no active replacement candidate in team"},
+			"diagnostics": {"team_members": 4, "active": 3, "excluded": 2, "over_capacity": 1, "remaining": 0, "candidate_pool": 1}
+		}`, rec.Body.String())
+	})
+
+	t.Run("rate limit exceeded", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		// время сервиса не совпадает с настенными часами: Retry-After берётся из payload
+		retryAt := time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC)
+		service.On("ReassignReviewer", mock.Anything, "pr1", "u2").Return(nil, "", &domain.ConflictError{
+			Err:     domain.ErrReassignRateExceeded,
+			Payload: &domain.ReassignRateLimit{Limit: 3, RetryAt: retryAt, RetryAfter: 89*time.Second + 300*time.Millisecond},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/reassign",
+			strings.NewReader(`{"pull_request_id":"pr1","old_user_id":"u2"}`))
+		rec := httptest.NewRecorder()
+
+		handler.ReassignReviewer(rec, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "90", rec.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{
+			"error": {"code": "REASSIGNMENT_RATE_EXCEEDED", "message": "too many reassignments for this PR, retry later"},
+			"diagnostics": {"limit": 3, "retry_at": "`+retryAt.UTC().Format(time.RFC3339)+`"}
+		}`, rec.Body.String())
+	})
+
+	t.Run("plain error", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("ReassignReviewer", mock.Anything, "pr1", "u2").Return(nil, "", domain.ErrNoCandidate)
+
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/reassign",
+			strings.NewReader(`{"pull_request_id":"pr1","old_user_id":"u2"}`))
+		rec := httptest.NewRecorder()
+
+		handler.ReassignReviewer(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.NotContains(t, rec.Body.String(), `"diagnostics"`)
+	})
+}
+
+func TestPullRequestHandler_GetReviewLatency(t *testing.T) {
+	handler, service := setupTestHandler(t)
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	service.On("GetReviewLatency", mock.Anything, "backend", &from, (*time.Time)(nil)).Return([]domain.ReviewerLatency{
+		{UserID: "u2", Samples: 7, Median: 90 * time.Minute, P90: 26 * time.Hour},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/pullRequest/reviewLatency?team_name=backend&from=2025-10-01T00:00:00Z", nil)
+	rec := httptest.NewRecorder()
+
+	handler.GetReviewLatency(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"team_name":"backend","reviewers":[
+		{"user_id":"u2","samples":7,"median_seconds":5400,"p90_seconds":93600}]}`, rec.Body.String())
+}
+
+func TestPullRequestHandler_GetQueueInfo(t *testing.T) {
+	assignedAt := time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC)
+
+	t.Run("open PR", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		median := 90 * time.Minute
+		service.On("GetQueueInfo", mock.Anything, "pr1").Return(&domain.PRQueueInfo{
+			PullRequestID: "pr1", Status: domain.PRStatusOpen, Reviewers: []domain.ReviewerQueuePosition{
+				{UserID: "u2", AssignedAt: assignedAt, Ahead: 2, MedianTurnaround: &median},
+				{UserID: "u3", AssignedAt: assignedAt},
+			},
+		}, nil)
+
+		rec := httptest.NewRecorder()
+		handler.GetQueueInfo(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/queueInfo?pull_request_id=pr1", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"pull_request_id":"pr1","status":"OPEN","queue":[
+			{"user_id":"u2","assigned_at":"2025-11-03T10:00:00.000Z","ahead":2,"median_turnaround_seconds":5400},
+			{"user_id":"u3","assigned_at":"2025-11-03T10:00:00.000Z","ahead":0,"median_turnaround_seconds":null}]}`, rec.Body.String())
+	})
+
+	t.Run("merged PR", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		mergedAt := assignedAt.Add(time.Hour)
+		service.On("GetQueueInfo", mock.Anything, "pr1").Return(&domain.PRQueueInfo{
+			PullRequestID: "pr1", Status: domain.PRStatusMerged, MergedAt: &mergedAt,
+		}, nil)
+
+		rec := httptest.NewRecorder()
+		handler.GetQueueInfo(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/queueInfo?pull_request_id=pr1&status_format=numeric", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"pull_request_id":"pr1","status":1,"merged_at":"2025-11-03T11:00:00.000Z","queue":[]}`, rec.Body.String())
+	})
+
+	t.Run("unknown PR", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("GetQueueInfo", mock.Anything, "missing").Return(nil, domain.ErrPRNotFound)
+
+		rec := httptest.NewRecorder()
+		handler.GetQueueInfo(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/queueInfo?pull_request_id=missing", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestPullRequestHandler_StreamEvents(t *testing.T) {
+	t.Run("streams reassignment", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		broker := live.NewBroker()
+		service.On("SubscribeUpdates", mock.Anything, "pr1").
+			Return(func(context.Context, string) (<-chan domain.PRUpdate, func(), error) {
+				updates, cancel := broker.Subscribe("pr1")
+				return updates, cancel, nil
+			})
+		server := httptest.NewServer(http.HandlerFunc(handler.StreamEvents))
+		defer server.Close()
+
+		resp, err := server.Client().Get(server.URL + "/pullRequest/events?pull_request_id=pr1")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		at := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+		broker.Publish(domain.PRUpdate{Type: domain.PRUpdateReviewerRemoved, PullRequestID: "pr1", UserID: "u2", OccurredAt: at})
+		broker.Publish(domain.PRUpdate{
+			Type: domain.PRUpdateReviewerAssigned, PullRequestID: "pr1", UserID: "u3",
+			Source: domain.AssignmentSourceReassignment, OccurredAt: at,
+		})
+
+		reader := bufio.NewReader(resp.Body)
+		readEvent := func() string {
+			var lines []string
+			for {
+				line, err := reader.ReadString('\n')
+				require.NoError(t, err)
+				if line == "\n" {
+					return strings.Join(lines, "")
+				}
+				lines = append(lines, line)
+			}
+		}
+
+		assert.Equal(t, "event: REVIEWER_REMOVED\n"+
+			`data: {"type":"REVIEWER_REMOVED","pull_request_id":"pr1","user_id":"u2","occurred_at":"2025-11-03T12:00:00.000Z"}`+"\n",
+			readEvent())
+		assert.Equal(t, "event: REVIEWER_ASSIGNED\n"+
+			`data: {"type":"REVIEWER_ASSIGNED","pull_request_id":"pr1","user_id":"u3","assignment_source":"REASSIGNMENT","occurred_at":"2025-11-03T12:00:00.000Z"}`+"\n",
+			readEvent())
+
+		// отключение клиента снимает подписку
+		resp.Body.Close()
+		require.Eventually(t, func() bool { return broker.Subscribers("pr1") == 0 }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("unknown PR", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("SubscribeUpdates", mock.Anything, "missing").Return(nil, nil, domain.ErrPRNotFound)
+
+		rec := httptest.NewRecorder()
+		handler.StreamEvents(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/events?pull_request_id=missing", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestPullRequestHandler_GetRecentPullRequests(t *testing.T) {
+	t.Run("default limit", func(t *testing.T) {
+		handler, service := setupTestHandler(t)
+		service.On("GetRecentPullRequests", mock.Anything, defaultRecentLimit).Return([]domain.RecentPullRequest{{
+			PullRequestShort: domain.PullRequestShort{PullRequestID: "pr2", PullRequestName: "Add search", AuthorID: "u1", Status: domain.PRStatusOpen},
+			AuthorName:       "alice",
+			ReviewerCount:    2,
+			CreatedAt:        time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC),
+		}}, nil)
+
+		rec := httptest.NewRecorder()
+		handler.GetRecentPullRequests(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/recent", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"pull_requests":[{
+			"pull_request_id": "pr2", "pull_request_name": "Add search", "author_id": "u1", "author_name": "alice",
+			"status": "OPEN", "orphaned": false, "reviewer_count": 2, "created_at": "2025-10-01T12:00:00.000Z"
+		}]}`, rec.Body.String())
+	})
+
+	for _, tt := range []struct {
+		query string
+		rule  string
+	}{
+		{query: "limit=0", rule: "min=1"},
+		{query: "limit=101", rule: "max=100"},
+		{query: "limit=ten", rule: "integer"},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			handler, service := setupTestHandler(t)
+
+			rec := httptest.NewRecorder()
+			handler.GetRecentPullRequests(rec, httptest.NewRequest(http.MethodGet, "/pullRequest/recent?"+tt.query, nil))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			var resp response.ErrorResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, []response.FieldError{{Field: "limit", Rule: tt.rule}}, resp.Details)
+			service.AssertNotCalled(t, "GetRecentPullRequests", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,631 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "avito_backend_task/internal/domain"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// PullRequestService is an autogenerated mock type for the PullRequestService type
+type PullRequestService struct {
+	mock.Mock
+}
+
+// AssignReviewer provides a mock function with given fields: ctx, prID, userID
+func (_m *PullRequestService) AssignReviewer(ctx context.Context, prID string, userID string) (*domain.PullRequest, error) {
+	ret := _m.Called(ctx, prID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AssignReviewer")
+	}
+
+	var r0 *domain.PullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.PullRequest, error)); ok {
+		return rf(ctx, prID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.PullRequest); ok {
+		r0 = rf(ctx, prID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, prID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreatePullRequest provides a mock function with given fields: ctx, pr
+func (_m *PullRequestService) CreatePullRequest(ctx context.Context, pr domain.PullRequestCreate) (*domain.PullRequest, error) {
+	ret := _m.Called(ctx, pr)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePullRequest")
+	}
+
+	var r0 *domain.PullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.PullRequestCreate) (*domain.PullRequest, error)); ok {
+		return rf(ctx, pr)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.PullRequestCreate) *domain.PullRequest); ok {
+		r0 = rf(ctx, pr)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.PullRequestCreate) error); ok {
+		r1 = rf(ctx, pr)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPullRequest provides a mock function with given fields: ctx, prID
+func (_m *PullRequestService) GetPullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	ret := _m.Called(ctx, prID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPullRequest")
+	}
+
+	var r0 *domain.PullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.PullRequest, error)); ok {
+		return rf(ctx, prID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.PullRequest); ok {
+		r0 = rf(ctx, prID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, prID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPullRequestsByIDs provides a mock function with given fields: ctx, prIDs
+func (_m *PullRequestService) GetPullRequestsByIDs(ctx context.Context, prIDs []string) ([]domain.PullRequest, error) {
+	ret := _m.Called(ctx, prIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPullRequestsByIDs")
+	}
+
+	var r0 []domain.PullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]domain.PullRequest, error)); ok {
+		return rf(ctx, prIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []domain.PullRequest); ok {
+		r0 = rf(ctx, prIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, prIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetQueueInfo provides a mock function with given fields: ctx, prID
+func (_m *PullRequestService) GetQueueInfo(ctx context.Context, prID string) (*domain.PRQueueInfo, error) {
+	ret := _m.Called(ctx, prID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQueueInfo")
+	}
+
+	var r0 *domain.PRQueueInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.PRQueueInfo, error)); ok {
+		return rf(ctx, prID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.PRQueueInfo); ok {
+		r0 = rf(ctx, prID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PRQueueInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, prID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRecentPullRequests provides a mock function with given fields: ctx, limit
+func (_m *PullRequestService) GetRecentPullRequests(ctx context.Context, limit int) ([]domain.RecentPullRequest, error) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRecentPullRequests")
+	}
+
+	var r0 []domain.RecentPullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.RecentPullRequest, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.RecentPullRequest); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.RecentPullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReviewLatency provides a mock function with given fields: ctx, teamName, from, to
+func (_m *PullRequestService) GetReviewLatency(ctx context.Context, teamName string, from *time.Time, to *time.Time) ([]domain.ReviewerLatency, error) {
+	ret := _m.Called(ctx, teamName, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReviewLatency")
+	}
+
+	var r0 []domain.ReviewerLatency
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time, *time.Time) ([]domain.ReviewerLatency, error)); ok {
+		return rf(ctx, teamName, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time, *time.Time) []domain.ReviewerLatency); ok {
+		r0 = rf(ctx, teamName, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ReviewerLatency)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *time.Time, *time.Time) error); ok {
+		r1 = rf(ctx, teamName, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReviewerIDs provides a mock function with given fields: ctx, prID
+func (_m *PullRequestService) GetReviewerIDs(ctx context.Context, prID string) ([]string, error) {
+	ret := _m.Called(ctx, prID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReviewerIDs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, prID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, prID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, prID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTeamPolicy provides a mock function with given fields: ctx, teamName
+func (_m *PullRequestService) GetTeamPolicy(ctx context.Context, teamName string) (*domain.TeamPolicy, error) {
+	ret := _m.Called(ctx, teamName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTeamPolicy")
+	}
+
+	var r0 *domain.TeamPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.TeamPolicy, error)); ok {
+		return rf(ctx, teamName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.TeamPolicy); ok {
+		r0 = rf(ctx, teamName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TeamPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, teamName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MergePullRequest provides a mock function with given fields: ctx, prID, mergedBy
+func (_m *PullRequestService) MergePullRequest(ctx context.Context, prID string, mergedBy string) (*domain.PullRequest, error) {
+	ret := _m.Called(ctx, prID, mergedBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergePullRequest")
+	}
+
+	var r0 *domain.PullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.PullRequest, error)); ok {
+		return rf(ctx, prID, mergedBy)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.PullRequest); ok {
+		r0 = rf(ctx, prID, mergedBy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, prID, mergedBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReassignIfInactive provides a mock function with given fields: ctx, prID, userID
+func (_m *PullRequestService) ReassignIfInactive(ctx context.Context, prID string, userID string) (*domain.PullRequest, string, error) {
+	ret := _m.Called(ctx, prID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReassignIfInactive")
+	}
+
+	var r0 *domain.PullRequest
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.PullRequest, string, error)); ok {
+		return rf(ctx, prID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.PullRequest); ok {
+		r0 = rf(ctx, prID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) string); ok {
+		r1 = rf(ctx, prID, userID)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, prID, userID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ReassignReviewer provides a mock function with given fields: ctx, prID, oldUserID
+func (_m *PullRequestService) ReassignReviewer(ctx context.Context, prID string, oldUserID string) (*domain.PullRequest, string, error) {
+	ret := _m.Called(ctx, prID, oldUserID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReassignReviewer")
+	}
+
+	var r0 *domain.PullRequest
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.PullRequest, string, error)); ok {
+		return rf(ctx, prID, oldUserID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.PullRequest); ok {
+		r0 = rf(ctx, prID, oldUserID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) string); ok {
+		r1 = rf(ctx, prID, oldUserID)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, prID, oldUserID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ReassignReviewers provides a mock function with given fields: ctx, prID, oldUserIDs
+func (_m *PullRequestService) ReassignReviewers(ctx context.Context, prID string, oldUserIDs []string) (*domain.PullRequest, domain.ReassignResult, error) {
+	ret := _m.Called(ctx, prID, oldUserIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReassignReviewers")
+	}
+
+	var r0 *domain.PullRequest
+	var r1 domain.ReassignResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (*domain.PullRequest, domain.ReassignResult, error)); ok {
+		return rf(ctx, prID, oldUserIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) *domain.PullRequest); ok {
+		r0 = rf(ctx, prID, oldUserIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) domain.ReassignResult); ok {
+		r1 = rf(ctx, prID, oldUserIDs)
+	} else {
+		r1 = ret.Get(1).(domain.ReassignResult)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, []string) error); ok {
+		r2 = rf(ctx, prID, oldUserIDs)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// RebalanceTeam provides a mock function with given fields: ctx, teamName
+func (_m *PullRequestService) RebalanceTeam(ctx context.Context, teamName string) (*domain.RebalanceReport, error) {
+	ret := _m.Called(ctx, teamName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RebalanceTeam")
+	}
+
+	var r0 *domain.RebalanceReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.RebalanceReport, error)); ok {
+		return rf(ctx, teamName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.RebalanceReport); ok {
+		r0 = rf(ctx, teamName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.RebalanceReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, teamName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SelfAssign provides a mock function with given fields: ctx, prID, userID
+func (_m *PullRequestService) SelfAssign(ctx context.Context, prID string, userID string) (*domain.PullRequest, error) {
+	ret := _m.Called(ctx, prID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SelfAssign")
+	}
+
+	var r0 *domain.PullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.PullRequest, error)); ok {
+		return rf(ctx, prID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.PullRequest); ok {
+		r0 = rf(ctx, prID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, prID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetReviewers provides a mock function with given fields: ctx, prID, reviewerIDs, force
+func (_m *PullRequestService) SetReviewers(ctx context.Context, prID string, reviewerIDs []string, force bool) (*domain.PullRequest, error) {
+	ret := _m.Called(ctx, prID, reviewerIDs, force)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetReviewers")
+	}
+
+	var r0 *domain.PullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, bool) (*domain.PullRequest, error)); ok {
+		return rf(ctx, prID, reviewerIDs, force)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, bool) *domain.PullRequest); ok {
+		r0 = rf(ctx, prID, reviewerIDs, force)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, bool) error); ok {
+		r1 = rf(ctx, prID, reviewerIDs, force)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SnoozeReview provides a mock function with given fields: ctx, prID, userID, until
+func (_m *PullRequestService) SnoozeReview(ctx context.Context, prID string, userID string, until time.Time) (*domain.ReviewSnooze, error) {
+	ret := _m.Called(ctx, prID, userID, until)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SnoozeReview")
+	}
+
+	var r0 *domain.ReviewSnooze
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) (*domain.ReviewSnooze, error)); ok {
+		return rf(ctx, prID, userID, until)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) *domain.ReviewSnooze); ok {
+		r0 = rf(ctx, prID, userID, until)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ReviewSnooze)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time) error); ok {
+		r1 = rf(ctx, prID, userID, until)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SubscribeUpdates provides a mock function with given fields: ctx, prID
+func (_m *PullRequestService) SubscribeUpdates(ctx context.Context, prID string) (<-chan domain.PRUpdate, func(), error) {
+	ret := _m.Called(ctx, prID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SubscribeUpdates")
+	}
+
+	var r0 <-chan domain.PRUpdate
+	var r1 func()
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (<-chan domain.PRUpdate, func(), error)); ok {
+		return rf(ctx, prID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) <-chan domain.PRUpdate); ok {
+		r0 = rf(ctx, prID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan domain.PRUpdate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) func()); ok {
+		r1 = rf(ctx, prID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, prID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UpdatePullRequest provides a mock function with given fields: ctx, update
+func (_m *PullRequestService) UpdatePullRequest(ctx context.Context, update domain.PullRequestUpdate) (*domain.PullRequest, error) {
+	ret := _m.Called(ctx, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePullRequest")
+	}
+
+	var r0 *domain.PullRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.PullRequestUpdate) (*domain.PullRequest, error)); ok {
+		return rf(ctx, update)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.PullRequestUpdate) *domain.PullRequest); ok {
+		r0 = rf(ctx, update)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PullRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.PullRequestUpdate) error); ok {
+		r1 = rf(ctx, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPullRequestService creates a new instance of PullRequestService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPullRequestService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PullRequestService {
+	mock := &PullRequestService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,42 @@
+package stats
+
+import "avito_backend_task/internal/domain"
+
+type GlobalStatsResponse struct {
+	Teams                 int     `json:"teams"`
+	ActiveUsers           int     `json:"active_users"`
+	InactiveUsers         int     `json:"inactive_users"`
+	OpenPRs               int     `json:"open_prs"`
+	MergedPRs             int     `json:"merged_prs"`
+	AvgReviewersPerOpenPR float64 `json:"avg_reviewers_per_open_pr"`
+}
+
+func globalStatsToDTO(stats domain.GlobalStats) GlobalStatsResponse {
+	return GlobalStatsResponse{
+		Teams:                 stats.Teams,
+		ActiveUsers:           stats.ActiveUsers,
+		InactiveUsers:         stats.InactiveUsers,
+		OpenPRs:               stats.OpenPRs,
+		MergedPRs:             stats.MergedPRs,
+		AvgReviewersPerOpenPR: stats.AvgReviewersPerOpenPR,
+	}
+}
+
+type AuthorPRCountsResponse struct {
+	TeamName string             `json:"team_name"`
+	Authors  []AuthorPRCountDTO `json:"authors"`
+}
+
+type AuthorPRCountDTO struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	PRCount  int    `json:"pr_count"`
+}
+
+func authorPRCountToDTO(count domain.AuthorPRCount) AuthorPRCountDTO {
+	return AuthorPRCountDTO{
+		UserID:   count.UserID,
+		Username: count.Username,
+		PRCount:  count.Count,
+	}
+}
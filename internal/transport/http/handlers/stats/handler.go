@@ -0,0 +1,115 @@
+package stats
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/transport/http/query"
+	"avito_backend_task/internal/transport/http/response"
+)
+
+type StatsService interface {
+	GetGlobalStats(ctx context.Context) (*domain.GlobalStats, error)
+	CountPullRequestsByAuthor(
+		ctx context.Context,
+		teamName string,
+		since *time.Time,
+		order domain.AuthorPRCountOrder,
+		limit int,
+	) ([]domain.AuthorPRCount, error)
+}
+
+const (
+	defaultAuthorsLimit = 10
+	maxAuthorsLimit     = 100
+)
+
+type StatsHandler struct {
+	service StatsService
+	lg      *slog.Logger
+}
+
+func NewStatsHandler(service StatsService, lg *slog.Logger) *StatsHandler {
+	return &StatsHandler{
+		service: service,
+		lg:      lg,
+	}
+}
+
+// GET /stats/global
+func (h *StatsHandler) GetGlobal(w http.ResponseWriter, r *http.Request) {
+	op := "StatsHandler.GetGlobal"
+	log := h.lg.With(slog.String("op", op))
+
+	stats, err := h.service.GetGlobalStats(r.Context())
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, globalStatsToDTO(*stats))
+}
+
+// GET /stats/byAuthor?team_name&since&order&limit
+func (h *StatsHandler) GetByAuthor(w http.ResponseWriter, r *http.Request) {
+	op := "StatsHandler.GetByAuthor"
+	log := h.lg.With(slog.String("op", op))
+
+	teamName, err := query.RequiredID(r, "team_name")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	since, err := query.Time(r, "since")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	order := domain.AuthorPRCountOrder(r.URL.Query().Get("order"))
+	if order != "" && !order.Valid() {
+		log.Debug("unknown order", slog.String("order", string(order)))
+		response.RespondError(w, log, response.InvalidRequest(response.FieldError{Field: "order", Rule: "oneof"}))
+		return
+	}
+
+	limit, err := query.Int(r, "limit", defaultAuthorsLimit)
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+	if limit <= 0 {
+		response.RespondError(w, log, response.InvalidRequest(response.FieldError{Field: "limit", Rule: "min=1"}))
+		return
+	}
+	if limit > maxAuthorsLimit {
+		response.RespondError(w, log, response.InvalidRequest(response.FieldError{
+			Field: "limit", Rule: "max=" + strconv.Itoa(maxAuthorsLimit),
+		}))
+		return
+	}
+
+	counts, err := h.service.CountPullRequestsByAuthor(r.Context(), teamName, since, order, limit)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("team_name", teamName)), err)
+		return
+	}
+
+	authors := make([]AuthorPRCountDTO, len(counts))
+	for i, count := range counts {
+		authors[i] = authorPRCountToDTO(count)
+	}
+
+	response.RespondJSON(w, http.StatusOK, AuthorPRCountsResponse{
+		TeamName: teamName,
+		Authors:  authors,
+	})
+}
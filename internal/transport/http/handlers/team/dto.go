@@ -1,16 +1,129 @@
 package team
 
-import "avito_backend_task/internal/domain"
+import (
+	"time"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/service/eligibility"
+	"avito_backend_task/internal/transport/http/response"
+)
 
 type TeamMemberDTO struct {
-	UserID   string `json:"user_id" validate:"required,max=64"`
+	UserID   string `json:"user_id" validate:"required,max=64,identifier"`
 	Username string `json:"username" validate:"required,max=64"`
 	IsActive bool   `json:"is_active"`
+	// Eligibility только в ответе /team/get?include_eligibility=true
+	Eligibility *EligibilityDTO `json:"eligibility,omitempty" validate:"-"`
+}
+
+// EligibilityDTO - получил бы участник ревью при автоназначении сейчас и какие условия он проходит
+type EligibilityDTO struct {
+	Eligible      bool `json:"eligible"`
+	Active        bool `json:"active"`
+	UnderCapacity bool `json:"under_capacity"`
+	InBlackout    bool `json:"in_blackout"`
 }
 
 type TeamDTO struct {
-	TeamName string          `json:"team_name" validate:"required,max=64"`
+	TeamName string          `json:"team_name" validate:"required,max=64,identifier"`
 	Members  []TeamMemberDTO `json:"members" validate:"required,min=1,dive"`
+	// Blackouts только в ответах, при создании команды игнорируется
+	Blackouts []BlackoutDTO `json:"blackouts,omitempty" validate:"-"`
+}
+
+type BlackoutDTO struct {
+	BlackoutID int64             `json:"blackout_id"`
+	TeamName   string            `json:"team_name"`
+	StartsAt   response.JSONTime `json:"starts_at"`
+	EndsAt     response.JSONTime `json:"ends_at"`
+	Reason     string            `json:"reason"`
+}
+
+type CreateBlackoutRequest struct {
+	TeamName string    `json:"team_name" validate:"required,max=64,identifier"`
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+	EndsAt   time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+	Reason   string    `json:"reason" validate:"max=256"`
+}
+
+// TeamWebhookDTO - webhook команды в ответах, secret не возвращается
+type TeamWebhookDTO struct {
+	TeamName  string            `json:"team_name"`
+	URL       string            `json:"url"`
+	Enabled   bool              `json:"enabled"`
+	CreatedAt response.JSONTime `json:"created_at"`
+	UpdatedAt response.JSONTime `json:"updated_at"`
+}
+
+// CreateWebhookRequest - enabled по умолчанию true
+type CreateWebhookRequest struct {
+	TeamName string `json:"team_name" validate:"required,max=64,identifier"`
+	URL      string `json:"url" validate:"required,max=2048,http_url"`
+	Secret   string `json:"secret" validate:"required,min=16,max=256"`
+	Enabled  *bool  `json:"enabled"`
+}
+
+// UpdateWebhookRequest - частичное обновление, отсутствующие поля не меняются
+type UpdateWebhookRequest struct {
+	TeamName string  `json:"team_name" validate:"required,max=64,identifier"`
+	URL      *string `json:"url" validate:"omitempty,max=2048,http_url"`
+	Secret   *string `json:"secret" validate:"omitempty,min=16,max=256"`
+	Enabled  *bool   `json:"enabled"`
+}
+
+type WebhookResponse struct {
+	Webhook TeamWebhookDTO `json:"webhook"`
+}
+
+// WebhookStatusResponse - last_delivered_at и last_failed_at пропускаются, пока таких доставок не было
+type WebhookStatusResponse struct {
+	TeamName            string             `json:"team_name"`
+	Enabled             bool               `json:"enabled"`
+	LastDeliveredAt     *response.JSONTime `json:"last_delivered_at,omitempty"`
+	LastFailedAt        *response.JSONTime `json:"last_failed_at,omitempty"`
+	LastError           string             `json:"last_error,omitempty"`
+	ConsecutiveFailures int                `json:"consecutive_failures"`
+}
+
+type AgeBucketDTO struct {
+	Bucket       string   `json:"bucket"`
+	Count        int      `json:"count"`
+	ExamplePRIDs []string `json:"example_pr_ids"`
+}
+
+type OldestPRDTO struct {
+	PullRequestID string `json:"pull_request_id"`
+	AgeSeconds    int64  `json:"age_seconds"`
+}
+
+// ReconcileTeamsResponse - команды, созданные по users.team_name; пустой список, если расхождений не было.
+// При dry_run в created те, что были бы созданы
+type ReconcileTeamsResponse struct {
+	DryRun  bool                `json:"dry_run"`
+	Created []ReconciledTeamDTO `json:"created"`
+}
+
+type ReconciledTeamDTO struct {
+	TeamName string   `json:"team_name"`
+	UserIDs  []string `json:"user_ids"`
+}
+
+type AgeReportResponse struct {
+	TeamName string         `json:"team_name"`
+	Buckets  []AgeBucketDTO `json:"buckets"`
+	// Oldest - null, если у команды нет открытых PR
+	Oldest *OldestPRDTO `json:"oldest"`
+}
+
+// HealthResponse - healthy=false, если issues не пуст
+type HealthResponse struct {
+	TeamName string   `json:"team_name"`
+	Healthy  bool     `json:"healthy"`
+	Issues   []string `json:"issues"`
+}
+
+type BlackoutResponse struct {
+	Blackout BlackoutDTO `json:"blackout"`
 }
 
 type TeamResponse struct {
@@ -41,8 +154,90 @@ func teamToDTO(team domain.Team) TeamDTO {
 			IsActive: m.IsActive,
 		}
 	}
+	var blackouts []BlackoutDTO
+	for _, b := range team.Blackouts {
+		blackouts = append(blackouts, blackoutToDTO(b))
+	}
 	return TeamDTO{
-		TeamName: team.TeamName,
-		Members:  members,
+		TeamName:  team.TeamName,
+		Members:   members,
+		Blackouts: blackouts,
+	}
+}
+
+// teamWithEligibilityToDTO - teamToDTO с eligibility у каждого участника
+func teamWithEligibilityToDTO(team domain.Team, statuses map[string]eligibility.Status) TeamDTO {
+	dto := teamToDTO(team)
+	for i := range dto.Members {
+		status := statuses[dto.Members[i].UserID]
+		dto.Members[i].Eligibility = &EligibilityDTO{
+			Eligible:      status.Eligible(),
+			Active:        status.Active,
+			UnderCapacity: status.UnderCapacity,
+			InBlackout:    status.InBlackout,
+		}
+	}
+	return dto
+}
+
+func blackoutToDTO(b domain.TeamBlackout) BlackoutDTO {
+	return BlackoutDTO{
+		BlackoutID: b.BlackoutID,
+		TeamName:   b.TeamName,
+		StartsAt:   response.NewJSONTime(b.StartsAt),
+		EndsAt:     response.NewJSONTime(b.EndsAt),
+		Reason:     b.Reason,
+	}
+}
+
+func webhookToDTO(w domain.TeamWebhook) TeamWebhookDTO {
+	return TeamWebhookDTO{
+		TeamName:  w.TeamName,
+		URL:       w.URL,
+		Enabled:   w.Enabled,
+		CreatedAt: response.NewJSONTime(w.CreatedAt),
+		UpdatedAt: response.NewJSONTime(w.UpdatedAt),
+	}
+}
+
+func webhookStatusToDTO(s domain.TeamWebhookStatus) WebhookStatusResponse {
+	return WebhookStatusResponse{
+		TeamName:            s.TeamName,
+		Enabled:             s.Enabled,
+		LastDeliveredAt:     response.OptionalJSONTime(s.LastDeliveredAt),
+		LastFailedAt:        response.OptionalJSONTime(s.LastFailedAt),
+		LastError:           s.LastError,
+		ConsecutiveFailures: s.ConsecutiveFailures,
+	}
+}
+
+func ageReportToDTO(report domain.TeamAgeReport) AgeReportResponse {
+	buckets := make([]AgeBucketDTO, len(report.Buckets))
+	for i, b := range report.Buckets {
+		buckets[i] = AgeBucketDTO{
+			Bucket:       b.Label,
+			Count:        b.Count,
+			ExamplePRIDs: response.EmptyIfNil(b.ExamplePRIDs),
+		}
+	}
+
+	resp := AgeReportResponse{
+		TeamName: report.TeamName,
+		Buckets:  buckets,
+	}
+	if report.OldestPRID != "" {
+		resp.Oldest = &OldestPRDTO{
+			PullRequestID: report.OldestPRID,
+			AgeSeconds:    int64(report.OldestAge.Seconds()),
+		}
+	}
+	return resp
+}
+
+func reconciledTeamsToDTO(teams []domain.ReconciledTeam, dryRun bool) ReconcileTeamsResponse {
+	created := make([]ReconciledTeamDTO, len(teams))
+	for i, team := range teams {
+		created[i] = ReconciledTeamDTO{TeamName: team.TeamName, UserIDs: response.EmptyIfNil(team.UserIDs)}
 	}
+	return ReconcileTeamsResponse{DryRun: dryRun, Created: created}
 }
@@ -5,16 +5,32 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"net/url"
 
 	"github.com/go-playground/validator/v10"
 
 	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/service/eligibility"
+	"avito_backend_task/internal/transport/http/query"
 	"avito_backend_task/internal/transport/http/response"
+	"avito_backend_task/internal/transport/http/validation"
 )
 
 type TeamService interface {
 	CreateTeam(ctx context.Context, team domain.Team) (*domain.Team, error)
 	GetTeamByName(ctx context.Context, teamName string) (*domain.Team, error)
+	GetTeamEligibility(ctx context.Context, teamName string) (*domain.Team, map[string]eligibility.Status, error)
+	GetTeamByMember(ctx context.Context, userID string) (*domain.Team, error)
+	CreateBlackout(ctx context.Context, blackout domain.TeamBlackout) (*domain.TeamBlackout, error)
+	DeleteBlackout(ctx context.Context, teamName string, blackoutID int64) error
+	GetAgeReport(ctx context.Context, teamName string) (*domain.TeamAgeReport, error)
+	CheckHealth(ctx context.Context, teamName string) (*domain.TeamHealth, error)
+	ReconcileTeamsFromUsers(ctx context.Context, dryRun bool) ([]domain.ReconciledTeam, error)
+	CreateWebhook(ctx context.Context, webhook domain.TeamWebhook) (*domain.TeamWebhook, error)
+	GetWebhook(ctx context.Context, teamName string) (*domain.TeamWebhook, error)
+	UpdateWebhook(ctx context.Context, update domain.TeamWebhookUpdate) (*domain.TeamWebhook, error)
+	DeleteWebhook(ctx context.Context, teamName string) error
+	GetWebhookStatus(ctx context.Context, teamName string) (*domain.TeamWebhookStatus, error)
 }
 
 type TeamHandler struct {
@@ -39,13 +55,13 @@ func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 	var dto TeamDTO
 	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
 		log.Debug("failed to decode request body", slog.String("error", err.Error()))
-		response.RespondError(w, response.ErrInvalidRequest)
+		response.RespondError(w, log, response.ErrInvalidRequest)
 		return
 	}
 
 	if err := h.validator.Struct(dto); err != nil {
 		log.Debug("validation failed", slog.String("error", err.Error()))
-		response.RespondError(w, response.ErrInvalidRequest)
+		response.RespondError(w, log, validation.Details(err))
 		return
 	}
 
@@ -53,8 +69,7 @@ func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 
 	createdTeam, err := h.service.CreateTeam(r.Context(), team)
 	if err != nil {
-		log.Error("failed to create team", slog.Any("error", err))
-		response.RespondError(w, err)
+		response.RespondError(w, log, err)
 		return
 	}
 
@@ -62,7 +77,8 @@ func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 		Team: teamToDTO(*createdTeam),
 	}
 
-	response.RespondJSON(w, http.StatusCreated, responseDTO)
+	location := response.Location(r, "/team/get", url.Values{"team_name": {createdTeam.TeamName}})
+	response.RespondCreated(w, location, responseDTO)
 }
 
 // GET /team/get?team_name
@@ -70,20 +86,312 @@ func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 	op := "TeamHandler.GetTeam"
 	log := h.lg.With(slog.String("op", op))
 
-	teamName := r.URL.Query().Get("team_name")
-	if teamName == "" {
-		log.Debug("team_name parameter is required")
-		response.RespondError(w, response.ErrInvalidRequest)
+	teamName, err := query.RequiredID(r, "team_name")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	includeEligibility, err := query.Bool(r, "include_eligibility", false)
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+	if includeEligibility {
+		team, statuses, err := h.service.GetTeamEligibility(r.Context(), teamName)
+		if err != nil {
+			response.RespondError(w, log.With(slog.String("team_name", teamName)), err)
+			return
+		}
+		response.RespondJSON(w, http.StatusOK, teamWithEligibilityToDTO(*team, statuses))
 		return
 	}
 
 	team, err := h.service.GetTeamByName(r.Context(), teamName)
 	if err != nil {
-		log.Error("failed to get team", slog.String("team_name", teamName), slog.Any("error", err))
-		response.RespondError(w, err)
+		response.RespondError(w, log.With(slog.String("team_name", teamName)), err)
 		return
 	}
 
 	responseDTO := teamToDTO(*team)
 	response.RespondJSON(w, http.StatusOK, responseDTO)
 }
+
+// GET /team/byMember?user_id
+func (h *TeamHandler) GetTeamByMember(w http.ResponseWriter, r *http.Request) {
+	op := "TeamHandler.GetTeamByMember"
+	log := h.lg.With(slog.String("op", op))
+
+	userID, err := query.RequiredID(r, "user_id")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	team, err := h.service.GetTeamByMember(r.Context(), userID)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("user_id", userID)), err)
+		return
+	}
+
+	responseDTO := teamToDTO(*team)
+	response.RespondJSON(w, http.StatusOK, responseDTO)
+}
+
+// GET /team/ageReport?team_name
+func (h *TeamHandler) GetAgeReport(w http.ResponseWriter, r *http.Request) {
+	op := "TeamHandler.GetAgeReport"
+	log := h.lg.With(slog.String("op", op))
+
+	teamName, err := query.RequiredID(r, "team_name")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	report, err := h.service.GetAgeReport(r.Context(), teamName)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("team_name", teamName)), err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, ageReportToDTO(*report))
+}
+
+// GET /team/isHealthy?team_name
+func (h *TeamHandler) IsHealthy(w http.ResponseWriter, r *http.Request) {
+	op := "TeamHandler.IsHealthy"
+	log := h.lg.With(slog.String("op", op))
+
+	teamName, err := query.RequiredID(r, "team_name")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	health, err := h.service.CheckHealth(r.Context(), teamName)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("team_name", teamName)), err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, HealthResponse{
+		TeamName: health.TeamName,
+		Healthy:  health.Healthy,
+		Issues:   response.EmptyIfNil(health.Issues),
+	})
+}
+
+// POST /team/blackouts
+func (h *TeamHandler) CreateBlackout(w http.ResponseWriter, r *http.Request) {
+	op := "TeamHandler.CreateBlackout"
+	log := h.lg.With(slog.String("op", op))
+
+	var req CreateBlackoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	blackout, err := h.service.CreateBlackout(r.Context(), domain.TeamBlackout{
+		TeamName: req.TeamName,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Reason:   req.Reason,
+	})
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("team_name", req.TeamName)), err)
+		return
+	}
+
+	// отдельного GET для окна нет, окна команды отдаёт /team/get
+	location := response.Location(r, "/team/get", url.Values{"team_name": {blackout.TeamName}})
+	response.RespondCreated(w, location, BlackoutResponse{Blackout: blackoutToDTO(*blackout)})
+}
+
+// DELETE /team/blackouts?team_name&blackout_id
+func (h *TeamHandler) DeleteBlackout(w http.ResponseWriter, r *http.Request) {
+	op := "TeamHandler.DeleteBlackout"
+	log := h.lg.With(slog.String("op", op))
+
+	teamName, err := query.RequiredID(r, "team_name")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+	blackoutID, err := query.RequiredInt64(r, "blackout_id")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	if err := h.service.DeleteBlackout(r.Context(), teamName, blackoutID); err != nil {
+		response.RespondError(w, log.With(slog.String("team_name", teamName)), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /team/webhooks
+func (h *TeamHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	op := "TeamHandler.CreateWebhook"
+	log := h.lg.With(slog.String("op", op))
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	enabled := req.Enabled == nil || *req.Enabled
+	webhook, err := h.service.CreateWebhook(r.Context(), domain.TeamWebhook{
+		TeamName: req.TeamName,
+		URL:      req.URL,
+		Secret:   req.Secret,
+		Enabled:  enabled,
+	})
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("team_name", req.TeamName)), err)
+		return
+	}
+
+	location := response.Location(r, "/team/webhooks", url.Values{"team_name": {webhook.TeamName}})
+	response.RespondCreated(w, location, WebhookResponse{Webhook: webhookToDTO(*webhook)})
+}
+
+// GET /team/webhooks?team_name
+func (h *TeamHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	op := "TeamHandler.GetWebhook"
+	log := h.lg.With(slog.String("op", op))
+
+	teamName, err := query.RequiredID(r, "team_name")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	webhook, err := h.service.GetWebhook(r.Context(), teamName)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("team_name", teamName)), err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, WebhookResponse{Webhook: webhookToDTO(*webhook)})
+}
+
+// PATCH /team/webhooks
+func (h *TeamHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	op := "TeamHandler.UpdateWebhook"
+	log := h.lg.With(slog.String("op", op))
+
+	var req UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	webhook, err := h.service.UpdateWebhook(r.Context(), domain.TeamWebhookUpdate{
+		TeamName: req.TeamName,
+		URL:      req.URL,
+		Secret:   req.Secret,
+		Enabled:  req.Enabled,
+	})
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("team_name", req.TeamName)), err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, WebhookResponse{Webhook: webhookToDTO(*webhook)})
+}
+
+// DELETE /team/webhooks?team_name
+func (h *TeamHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	op := "TeamHandler.DeleteWebhook"
+	log := h.lg.With(slog.String("op", op))
+
+	teamName, err := query.RequiredID(r, "team_name")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	if err := h.service.DeleteWebhook(r.Context(), teamName); err != nil {
+		response.RespondError(w, log.With(slog.String("team_name", teamName)), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /team/webhooks/status?team_name
+func (h *TeamHandler) GetWebhookStatus(w http.ResponseWriter, r *http.Request) {
+	op := "TeamHandler.GetWebhookStatus"
+	log := h.lg.With(slog.String("op", op))
+
+	teamName, err := query.RequiredID(r, "team_name")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	status, err := h.service.GetWebhookStatus(r.Context(), teamName)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("team_name", teamName)), err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, webhookStatusToDTO(*status))
+}
+
+// POST /admin/reconcileTeams?dry_run
+func (h *TeamHandler) ReconcileTeams(w http.ResponseWriter, r *http.Request) {
+	op := "TeamHandler.ReconcileTeams"
+	log := h.lg.With(slog.String("op", op))
+
+	dryRun, err := query.Bool(r, "dry_run", false)
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	teams, err := h.service.ReconcileTeamsFromUsers(r.Context(), dryRun)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, reconciledTeamsToDTO(teams, dryRun))
+}
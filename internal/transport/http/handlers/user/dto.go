@@ -1,28 +1,100 @@
 package user
 
-import "avito_backend_task/internal/domain"
+import (
+	"time"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/transport/http/response"
+)
 
 type SetIsActiveRequest struct {
-	UserID   string `json:"user_id" validate:"required,max=64"`
+	UserID   string `json:"user_id" validate:"required,max=64,identifier"`
 	IsActive bool   `json:"is_active"`
+	// CreateIfMissing создаёт неизвестного пользователя в TeamName вместо USER_NOT_FOUND,
+	// Username и TeamName обязательны только вместе с ним
+	CreateIfMissing bool   `json:"create_if_missing"`
+	Username        string `json:"username" validate:"required_if=CreateIfMissing true,max=64"`
+	TeamName        string `json:"team_name" validate:"required_if=CreateIfMissing true,max=64,identifier"`
+	// Rebalance после активации добавляет пользователя в недоукомплектованные PR команды,
+	// только с is_active=true и без create_if_missing
+	Rebalance bool `json:"rebalance" validate:"excluded_unless=IsActive true,excluded_with=CreateIfMissing"`
+}
+
+type RemoveFromTeamRequest struct {
+	TeamName string `json:"team_name" validate:"required,max=64,identifier"`
+	UserID   string `json:"user_id" validate:"required,max=64,identifier"`
+}
+
+type SetScheduleRequest struct {
+	UserID       string `json:"user_id" validate:"required,max=64,identifier"`
+	WorkingHours string `json:"working_hours" validate:"max=64"`
+	Timezone     string `json:"timezone" validate:"max=64"`
+}
+
+// SetCapacityRequest - review_capacity от 0 (не включая) до 10
+type SetCapacityRequest struct {
+	UserID         string  `json:"user_id" validate:"required,max=64,identifier"`
+	ReviewCapacity float64 `json:"review_capacity" validate:"gt=0,lte=10"`
+}
+
+// ValidateUserIDsRequest - не больше 500 ID за запрос
+type ValidateUserIDsRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required,min=1,max=500,dive,required,max=64,identifier"`
+}
+
+type ValidateUserIDsResponse struct {
+	Existing []string `json:"existing"`
+	Missing  []string `json:"missing"`
 }
 
 type UserDTO struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID       string `json:"user_id"`
+	Username     string `json:"username"`
+	TeamName     string `json:"team_name"`
+	IsActive     bool   `json:"is_active"`
+	WorkingHours string `json:"working_hours,omitempty"`
+	Timezone     string `json:"timezone,omitempty"`
+	// ReviewCapacity - 1 у пользователей, не задававших множитель
+	ReviewCapacity float64 `json:"review_capacity"`
 }
 
 type UserResponse struct {
 	User UserDTO `json:"user"`
 }
 
+// SetIsActiveResponse - ответ setIsActive с create_if_missing: created отличает созданного пользователя от обновлённого
+type SetIsActiveResponse struct {
+	User    UserDTO `json:"user"`
+	Created bool    `json:"created"`
+}
+
+// ActivateWithTopUpResponse - ответ setIsActive с rebalance: PR, в которые пользователь добавлен ревьюером
+type ActivateWithTopUpResponse struct {
+	User                 UserDTO  `json:"user"`
+	AssignedPullRequests []string `json:"assigned_pull_requests"`
+}
+
 type PullRequestShortDTO struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
 	Status          string `json:"status"`
+	Orphaned        bool   `json:"orphaned"`
+	// SnoozedUntil есть только у отложенных PR в ответе с include_snoozed=true
+	SnoozedUntil *response.JSONTime `json:"snoozed_until,omitempty"`
+}
+
+// OpenPullRequestDTO - PR в обзоре пользователя, assigned_at только в reviewing_open
+type OpenPullRequestDTO struct {
+	PullRequestShortDTO
+	CreatedAt  response.JSONTime  `json:"created_at"`
+	AssignedAt *response.JSONTime `json:"assigned_at,omitempty"`
+}
+
+type OverviewResponse struct {
+	User          UserDTO              `json:"user"`
+	AuthoredOpen  []OpenPullRequestDTO `json:"authored_open"`
+	ReviewingOpen []OpenPullRequestDTO `json:"reviewing_open"`
 }
 
 type GetReviewResponse struct {
@@ -30,12 +102,60 @@ type GetReviewResponse struct {
 	PullRequests []PullRequestShortDTO `json:"pull_requests"`
 }
 
+type ReviewerEventDTO struct {
+	PullRequestID string            `json:"pull_request_id"`
+	EventType     string            `json:"event_type"`
+	CreatedAt     response.JSONTime `json:"created_at"`
+}
+
+type TimelineResponse struct {
+	UserID string             `json:"user_id"`
+	Events []ReviewerEventDTO `json:"events"`
+	Limit  int                `json:"limit"`
+	Offset int                `json:"offset"`
+	// NextCursor - значение cursor для следующей страницы, нет на последней
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// AssignmentEventDTO - событие ревьюера для /events/assignments, в отличие от хронологии содержит user_id
+type AssignmentEventDTO struct {
+	EventID       int64  `json:"event_id"`
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+	EventType     string `json:"event_type"`
+	// AssignmentSource - источник назначения, нет у снятий
+	AssignmentSource string            `json:"assignment_source,omitempty"`
+	CreatedAt        response.JSONTime `json:"created_at"`
+}
+
+type AssignmentEventsResponse struct {
+	Events []AssignmentEventDTO `json:"events"`
+	// NextSince - since для следующего запроса, в RFC3339 с полной точностью, чтобы не повторять события
+	NextSince string `json:"next_since"`
+	HasMore   bool   `json:"has_more"`
+}
+
+// FormerReviewDTO - last_removed_at нет, если пользователя с PR не снимали
+type FormerReviewDTO struct {
+	PullRequestID   string             `json:"pull_request_id"`
+	FirstAssignedAt *response.JSONTime `json:"first_assigned_at,omitempty"`
+	LastRemovedAt   *response.JSONTime `json:"last_removed_at,omitempty"`
+}
+
+type FormerReviewsResponse struct {
+	UserID       string            `json:"user_id"`
+	PullRequests []FormerReviewDTO `json:"pull_requests"`
+}
+
 func userToDTO(user domain.User) UserDTO {
 	return UserDTO{
-		UserID:   user.UserID,
-		Username: user.Username,
-		TeamName: user.TeamName,
-		IsActive: user.IsActive,
+		UserID:         user.UserID,
+		Username:       user.Username,
+		TeamName:       user.TeamName,
+		IsActive:       user.IsActive,
+		WorkingHours:   user.WorkingHours,
+		Timezone:       user.Timezone,
+		ReviewCapacity: user.ReviewCapacity,
 	}
 }
 
@@ -45,5 +165,63 @@ func prShortToDTO(pr domain.PullRequestShort) PullRequestShortDTO {
 		PullRequestName: pr.PullRequestName,
 		AuthorID:        pr.AuthorID,
 		Status:          string(pr.Status),
+		Orphaned:        pr.Orphaned,
+		SnoozedUntil:    response.OptionalJSONTime(pr.SnoozedUntil),
+	}
+}
+
+func overviewToDTO(overview domain.UserOverview) OverviewResponse {
+	return OverviewResponse{
+		User:          userToDTO(overview.User),
+		AuthoredOpen:  openPRsToDTO(overview.AuthoredOpen),
+		ReviewingOpen: openPRsToDTO(overview.ReviewingOpen),
+	}
+}
+
+func openPRsToDTO(prs []domain.OpenPullRequest) []OpenPullRequestDTO {
+	dtos := make([]OpenPullRequestDTO, len(prs))
+	for i, pr := range prs {
+		dtos[i] = OpenPullRequestDTO{
+			PullRequestShortDTO: prShortToDTO(pr.PullRequestShort),
+			CreatedAt:           response.NewJSONTime(pr.CreatedAt),
+			AssignedAt:          response.OptionalJSONTime(pr.AssignedAt),
+		}
+	}
+	return dtos
+}
+
+func eventToDTO(event domain.ReviewerEvent) ReviewerEventDTO {
+	return ReviewerEventDTO{
+		PullRequestID: event.PullRequestID,
+		EventType:     string(event.EventType),
+		CreatedAt:     response.NewJSONTime(event.CreatedAt),
+	}
+}
+
+func assignmentEventsToDTO(page domain.ReviewerEventsPage) AssignmentEventsResponse {
+	events := make([]AssignmentEventDTO, len(page.Events))
+	for i, event := range page.Events {
+		events[i] = AssignmentEventDTO{
+			EventID:          event.EventID,
+			PullRequestID:    event.PullRequestID,
+			UserID:           event.UserID,
+			EventType:        string(event.EventType),
+			AssignmentSource: string(event.Source),
+			CreatedAt:        response.NewJSONTime(event.CreatedAt),
+		}
+	}
+
+	return AssignmentEventsResponse{
+		Events:    events,
+		NextSince: page.NextSince.UTC().Format(time.RFC3339Nano),
+		HasMore:   page.HasMore,
+	}
+}
+
+func formerReviewToDTO(review domain.FormerReview) FormerReviewDTO {
+	return FormerReviewDTO{
+		PullRequestID:   review.PullRequestID,
+		FirstAssignedAt: response.OptionalJSONTime(review.FirstAssignedAt),
+		LastRemovedAt:   response.OptionalJSONTime(review.LastRemovedAt),
 	}
 }
@@ -5,16 +5,37 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 
 	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/transport/http/query"
 	"avito_backend_task/internal/transport/http/response"
+	"avito_backend_task/internal/transport/http/validation"
 )
 
+//go:generate mockery --name=UserService --output=./mocks --case=underscore
 type UserService interface {
 	SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error)
-	GetReviewPRsByUserID(ctx context.Context, userID string) ([]domain.PullRequestShort, error)
+	SetIsActiveOrCreate(ctx context.Context, member domain.TeamMember, teamName string) (*domain.User, bool, error)
+	ActivateWithTopUp(ctx context.Context, userID string) (*domain.User, []string, error)
+	SetSchedule(ctx context.Context, userID, workingHours, timezone string) (*domain.User, error)
+	SetCapacity(ctx context.Context, userID string, capacity float64) (*domain.User, error)
+	RemoveFromTeam(ctx context.Context, teamName, userID string) (*domain.User, error)
+	GetReviewPRsByUserID(ctx context.Context, userID string, filter domain.ReviewFilter) ([]domain.PullRequestShort, error)
+	StreamReviewPRsByUserID(
+		ctx context.Context,
+		userID string,
+		filter domain.ReviewFilter,
+		fn func(domain.PullRequestShort) error,
+	) error
+	GetUserReviewTimeline(ctx context.Context, userID string, from, to *time.Time, page domain.Page) ([]domain.ReviewerEvent, error)
+	GetFormerReviews(ctx context.Context, userID string) ([]domain.FormerReview, error)
+	GetAssignmentEvents(ctx context.Context, since time.Time, source domain.AssignmentSource) (domain.ReviewerEventsPage, error)
+	GetOverview(ctx context.Context, userID string) (*domain.UserOverview, error)
+	ValidateUserIDs(ctx context.Context, userIDs []string) (existing, missing []string, err error)
 }
 
 type UserHandler struct {
@@ -24,11 +45,13 @@ type UserHandler struct {
 }
 
 func NewUserHandler(service UserService, lg *slog.Logger, validator *validator.Validate) *UserHandler {
-	return &UserHandler{
+	h := &UserHandler{
 		service:   service,
 		lg:        lg,
 		validator: validator,
 	}
+
+	return h
 }
 
 // POST /users/setIsActive
@@ -39,20 +62,99 @@ func (h *UserHandler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 	var req SetIsActiveRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Debug("failed to decode request body", slog.String("error", err.Error()))
-		response.RespondError(w, response.ErrInvalidRequest)
+		response.RespondError(w, log, response.ErrInvalidRequest)
 		return
 	}
 
 	if err := h.validator.Struct(req); err != nil {
 		log.Debug("validation failed", slog.String("error", err.Error()))
-		response.RespondError(w, response.ErrInvalidRequest)
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	if req.CreateIfMissing {
+		h.setIsActiveOrCreate(w, r, log, req)
+		return
+	}
+	if req.Rebalance {
+		h.activateWithTopUp(w, r, log, req.UserID)
 		return
 	}
 
 	user, err := h.service.SetIsActive(r.Context(), req.UserID, req.IsActive)
 	if err != nil {
-		log.Error("failed to set user active status", slog.Any("error", err))
-		response.RespondError(w, err)
+		response.RespondError(w, log, err)
+		return
+	}
+
+	responseDTO := UserResponse{
+		User: userToDTO(*user),
+	}
+
+	response.RespondJSON(w, http.StatusOK, responseDTO)
+}
+
+func (h *UserHandler) activateWithTopUp(w http.ResponseWriter, r *http.Request, log *slog.Logger, userID string) {
+	user, assigned, err := h.service.ActivateWithTopUp(r.Context(), userID)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, ActivateWithTopUpResponse{
+		User:                 userToDTO(*user),
+		AssignedPullRequests: response.EmptyIfNil(assigned),
+	})
+}
+
+func (h *UserHandler) setIsActiveOrCreate(w http.ResponseWriter, r *http.Request, log *slog.Logger, req SetIsActiveRequest) {
+	member := domain.TeamMember{
+		UserID:   req.UserID,
+		Username: req.Username,
+		IsActive: req.IsActive,
+	}
+
+	user, created, err := h.service.SetIsActiveOrCreate(r.Context(), member, req.TeamName)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	responseDTO := SetIsActiveResponse{
+		User:    userToDTO(*user),
+		Created: created,
+	}
+	if !created {
+		response.RespondJSON(w, http.StatusOK, responseDTO)
+		return
+	}
+
+	// пользователя отдаёт /team/byMember вместе с его командой
+	location := response.Location(r, "/team/byMember", url.Values{"user_id": {user.UserID}})
+	response.RespondCreated(w, location, responseDTO)
+}
+
+// POST /team/removeMember
+func (h *UserHandler) RemoveFromTeam(w http.ResponseWriter, r *http.Request) {
+	op := "UserHandler.RemoveFromTeam"
+	log := h.lg.With(slog.String("op", op))
+
+	var req RemoveFromTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	user, err := h.service.RemoveFromTeam(r.Context(), req.TeamName, req.UserID)
+	if err != nil {
+		response.RespondError(w, log, err)
 		return
 	}
 
@@ -63,22 +165,109 @@ func (h *UserHandler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 	response.RespondJSON(w, http.StatusOK, responseDTO)
 }
 
-// GET /users/getReview?user_id
+// POST /users/setSchedule
+func (h *UserHandler) SetSchedule(w http.ResponseWriter, r *http.Request) {
+	op := "UserHandler.SetSchedule"
+	log := h.lg.With(slog.String("op", op))
+
+	var req SetScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	user, err := h.service.SetSchedule(r.Context(), req.UserID, req.WorkingHours, req.Timezone)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	responseDTO := UserResponse{
+		User: userToDTO(*user),
+	}
+
+	response.RespondJSON(w, http.StatusOK, responseDTO)
+}
+
+// POST /users/setCapacity
+func (h *UserHandler) SetCapacity(w http.ResponseWriter, r *http.Request) {
+	op := "UserHandler.SetCapacity"
+	log := h.lg.With(slog.String("op", op))
+
+	var req SetCapacityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	user, err := h.service.SetCapacity(r.Context(), req.UserID, req.ReviewCapacity)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	responseDTO := UserResponse{
+		User: userToDTO(*user),
+	}
+
+	response.RespondJSON(w, http.StatusOK, responseDTO)
+}
+
+// GET /users/getReview?user_id&status&include_snoozed&stream
 func (h *UserHandler) GetReview(w http.ResponseWriter, r *http.Request) {
 	op := "UserHandler.GetReview"
 	log := h.lg.With(slog.String("op", op))
 
-	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		log.Debug("user_id parameter is required")
-		response.RespondError(w, response.ErrInvalidRequest)
+	userID, err := query.RequiredID(r, "user_id")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	status := domain.PRStatus(r.URL.Query().Get("status"))
+	if status != "" && !status.Valid() {
+		log.Debug("unknown PR status", slog.String("status", string(status)))
+		response.RespondError(w, log, response.InvalidRequest(response.FieldError{Field: "status", Rule: "oneof"}))
+		return
+	}
+
+	includeSnoozed, err := query.Bool(r, "include_snoozed", false)
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+	filter := domain.ReviewFilter{Status: status, IncludeSnoozed: includeSnoozed}
+
+	stream, err := query.Bool(r, "stream", false)
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+	if stream {
+		h.streamReview(w, r, log.With(slog.String("user_id", userID)), userID, filter)
 		return
 	}
 
-	prs, err := h.service.GetReviewPRsByUserID(r.Context(), userID)
+	prs, err := h.service.GetReviewPRsByUserID(r.Context(), userID, filter)
 	if err != nil {
-		log.Error("failed to get review PRs", slog.String("user_id", userID), slog.Any("error", err))
-		response.RespondError(w, err)
+		response.RespondError(w, log.With(slog.String("user_id", userID)), err)
 		return
 	}
 
@@ -94,3 +283,205 @@ func (h *UserHandler) GetReview(w http.ResponseWriter, r *http.Request) {
 
 	response.RespondJSON(w, http.StatusOK, responseDTO)
 }
+
+// streamReview отдаёт тот же ответ, что GetReview, но пишет PR по мере чтения из БД
+func (h *UserHandler) streamReview(
+	w http.ResponseWriter,
+	r *http.Request,
+	log *slog.Logger,
+	userID string,
+	filter domain.ReviewFilter,
+) {
+	stream, err := response.NewArrayStream(w, struct {
+		UserID string `json:"user_id"`
+	}{UserID: userID}, "pull_requests")
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	err = h.service.StreamReviewPRsByUserID(r.Context(), userID, filter, func(pr domain.PullRequestShort) error {
+		return stream.Write(prShortToDTO(pr))
+	})
+	if err == nil {
+		err = stream.Close()
+	}
+	if err != nil {
+		if !stream.Started() {
+			response.RespondError(w, log, err)
+			return
+		}
+		// заголовки уже отправлены: массив остаётся незакрытым, клиент получит невалидный JSON
+		if response.ClientGone(err) {
+			log.Info("review stream canceled by client", slog.Any("error", err))
+			return
+		}
+		log.Error("review stream aborted", slog.Any("error", err))
+	}
+}
+
+// GET /users/timeline?user_id&from&to&limit&offset
+func (h *UserHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	op := "UserHandler.GetTimeline"
+	log := h.lg.With(slog.String("op", op))
+
+	userID, err := query.RequiredID(r, "user_id")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	from, err := query.Time(r, "from")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	to, err := query.Time(r, "to")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	page, err := query.Page(r)
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	events, err := h.service.GetUserReviewTimeline(r.Context(), userID, from, to, page)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("user_id", userID)), err)
+		return
+	}
+
+	eventDTOs := make([]ReviewerEventDTO, len(events))
+	for i, event := range events {
+		eventDTOs[i] = eventToDTO(event)
+	}
+
+	responseDTO := TimelineResponse{
+		UserID: userID,
+		Events: eventDTOs,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	}
+	if len(events) > 0 {
+		last := events[len(events)-1]
+		responseDTO.NextCursor = query.NextCursor(page, len(events), domain.PageCursor{CreatedAt: last.CreatedAt, ID: last.EventID})
+	}
+
+	response.RespondJSON(w, http.StatusOK, responseDTO)
+}
+
+// GET /users/formerReviews?user_id
+func (h *UserHandler) GetFormerReviews(w http.ResponseWriter, r *http.Request) {
+	op := "UserHandler.GetFormerReviews"
+	log := h.lg.With(slog.String("op", op))
+
+	userID, err := query.RequiredID(r, "user_id")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	reviews, err := h.service.GetFormerReviews(r.Context(), userID)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("user_id", userID)), err)
+		return
+	}
+
+	reviewDTOs := make([]FormerReviewDTO, len(reviews))
+	for i, review := range reviews {
+		reviewDTOs[i] = formerReviewToDTO(review)
+	}
+
+	response.RespondJSON(w, http.StatusOK, FormerReviewsResponse{
+		UserID:       userID,
+		PullRequests: reviewDTOs,
+	})
+}
+
+// GET /users/overview?user_id
+func (h *UserHandler) GetOverview(w http.ResponseWriter, r *http.Request) {
+	op := "UserHandler.GetOverview"
+	log := h.lg.With(slog.String("op", op))
+
+	userID, err := query.RequiredID(r, "user_id")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	overview, err := h.service.GetOverview(r.Context(), userID)
+	if err != nil {
+		response.RespondError(w, log.With(slog.String("user_id", userID)), err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, overviewToDTO(*overview))
+}
+
+// GET /events/assignments?since
+func (h *UserHandler) GetAssignmentEvents(w http.ResponseWriter, r *http.Request) {
+	op := "UserHandler.GetAssignmentEvents"
+	log := h.lg.With(slog.String("op", op))
+
+	since, err := query.RequiredTime(r, "since")
+	if err != nil {
+		log.Debug("invalid query parameter", slog.String("error", err.Error()))
+		response.RespondError(w, log, err)
+		return
+	}
+
+	source := domain.AssignmentSource(r.URL.Query().Get("source"))
+	if source != "" && !source.Valid() {
+		log.Debug("unknown assignment source", slog.String("source", string(source)))
+		response.RespondError(w, log, response.InvalidRequest(response.FieldError{Field: "source", Rule: "oneof"}))
+		return
+	}
+
+	page, err := h.service.GetAssignmentEvents(r.Context(), since, source)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, assignmentEventsToDTO(page))
+}
+
+// POST /users/validate
+func (h *UserHandler) ValidateUserIDs(w http.ResponseWriter, r *http.Request) {
+	op := "UserHandler.ValidateUserIDs"
+	log := h.lg.With(slog.String("op", op))
+
+	var req ValidateUserIDsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Debug("failed to decode request body", slog.String("error", err.Error()))
+		response.RespondError(w, log, response.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		log.Debug("validation failed", slog.String("error", err.Error()))
+		response.RespondError(w, log, validation.Details(err))
+		return
+	}
+
+	existing, missing, err := h.service.ValidateUserIDs(r.Context(), req.UserIDs)
+	if err != nil {
+		response.RespondError(w, log, err)
+		return
+	}
+
+	response.RespondJSON(w, http.StatusOK, ValidateUserIDsResponse{
+		Existing: response.EmptyIfNil(existing),
+		Missing:  response.EmptyIfNil(missing),
+	})
+}
@@ -0,0 +1,330 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/transport/http/handlers/user/mocks"
+	"avito_backend_task/internal/transport/http/validation"
+)
+
+func setupTestHandler(t *testing.T) (*UserHandler, *mocks.UserService) {
+	service := mocks.NewUserService(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	return NewUserHandler(service, logger, validation.New()), service
+}
+
+func TestUserHandler_ValidateUserIDs(t *testing.T) {
+	tooMany := make([]string, 501)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf(`"u%d"`, i)
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		setupMocks func(*mocks.UserService)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "mix of existing and missing",
+			body: `{"user_ids":["u1","ghost","u2","u1"]}`,
+			setupMocks: func(s *mocks.UserService) {
+				s.On("ValidateUserIDs", mock.Anything, []string{"u1", "ghost", "u2", "u1"}).
+					Return([]string{"u1", "u2"}, []string{"ghost"}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"existing":["u1","u2"],"missing":["ghost"]}`,
+		},
+		{
+			name: "all missing",
+			body: `{"user_ids":["ghost"]}`,
+			setupMocks: func(s *mocks.UserService) {
+				s.On("ValidateUserIDs", mock.Anything, []string{"ghost"}).Return(nil, []string{"ghost"}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"existing":[],"missing":["ghost"]}`,
+		},
+		{
+			name:       "empty list",
+			body:       `{"user_ids":[]}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"BAD_REQUEST","message":"invalid request"},"details":[{"field":"user_ids","rule":"min=1"}]}`,
+		},
+		{
+			name:       "over the cap",
+			body:       `{"user_ids":[` + strings.Join(tooMany, ",") + `]}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"BAD_REQUEST","message":"invalid request"},"details":[{"field":"user_ids","rule":"max=500"}]}`,
+		},
+		{
+			name: "service error",
+			body: `{"user_ids":["u1"]}`,
+			setupMocks: func(s *mocks.UserService) {
+				s.On("ValidateUserIDs", mock.Anything, []string{"u1"}).Return(nil, nil, errors.New("db down"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t)
+			if tt.setupMocks != nil {
+				tt.setupMocks(service)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/users/validate", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.ValidateUserIDs(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestUserHandler_SetIsActiveRebalance(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		setupMocks func(*mocks.UserService)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "activated with top-up",
+			body: `{"user_id":"u1","is_active":true,"rebalance":true}`,
+			setupMocks: func(s *mocks.UserService) {
+				s.On("ActivateWithTopUp", mock.Anything, "u1").Return(
+					&domain.User{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true, ReviewCapacity: 1},
+					[]string{"pr1", "pr2"}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"user":{"user_id":"u1","username":"Alice","team_name":"backend","is_active":true,"review_capacity":1},
+				"assigned_pull_requests":["pr1","pr2"]}`,
+		},
+		{
+			name:       "rebalance on deactivation",
+			body:       `{"user_id":"u1","is_active":false,"rebalance":true}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"BAD_REQUEST","message":"invalid request"},"details":[{"field":"rebalance","rule":"excluded_unless=IsActive true"}]}`,
+		},
+		{
+			name:       "rebalance with create_if_missing",
+			body:       `{"user_id":"u1","is_active":true,"rebalance":true,"create_if_missing":true,"username":"A","team_name":"backend"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t)
+			if tt.setupMocks != nil {
+				tt.setupMocks(service)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/users/setIsActive", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.SetIsActive(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestUserHandler_GetAssignmentEvents(t *testing.T) {
+	since := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	at := since.Add(90*time.Minute + 123456*time.Microsecond)
+
+	tests := []struct {
+		name       string
+		query      string
+		setupMocks func(*mocks.UserService)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:  "empty window keeps since",
+			query: "since=" + since.Format(time.RFC3339),
+			setupMocks: func(s *mocks.UserService) {
+				s.On("GetAssignmentEvents", mock.Anything, since, domain.AssignmentSource("")).Return(domain.ReviewerEventsPage{NextSince: since}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"events":[],"next_since":"` + since.Format(time.RFC3339Nano) + `","has_more":false}`,
+		},
+		{
+			name:  "watermark keeps full precision",
+			query: "since=" + since.Format(time.RFC3339),
+			setupMocks: func(s *mocks.UserService) {
+				s.On("GetAssignmentEvents", mock.Anything, since, domain.AssignmentSource("")).Return(domain.ReviewerEventsPage{
+					Events: []domain.ReviewerEvent{{
+						EventID: 7, PullRequestID: "pr1", UserID: "u2", EventType: domain.ReviewerEventAssigned, CreatedAt: at,
+					}},
+					NextSince: at,
+					HasMore:   true,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"events":[{"event_id":7,"pull_request_id":"pr1","user_id":"u2","event_type":"ASSIGNED","created_at":"` +
+				at.Format("2006-01-02T15:04:05.000Z07:00") + `"}],"next_since":"` + at.Format(time.RFC3339Nano) + `","has_more":true}`,
+		},
+		{
+			name:  "filter by source",
+			query: "since=" + since.Format(time.RFC3339) + "&source=MANUAL",
+			setupMocks: func(s *mocks.UserService) {
+				s.On("GetAssignmentEvents", mock.Anything, since, domain.AssignmentSourceManual).Return(domain.ReviewerEventsPage{
+					Events: []domain.ReviewerEvent{{
+						EventID: 8, PullRequestID: "pr1", UserID: "u3", EventType: domain.ReviewerEventAssigned,
+						Source: domain.AssignmentSourceManual, CreatedAt: at,
+					}},
+					NextSince: at,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"events":[{"event_id":8,"pull_request_id":"pr1","user_id":"u3","event_type":"ASSIGNED","assignment_source":"MANUAL","created_at":"` +
+				at.Format("2006-01-02T15:04:05.000Z07:00") + `"}],"next_since":"` + at.Format(time.RFC3339Nano) + `","has_more":false}`,
+		},
+		{
+			name:       "invalid source",
+			query:      "since=" + since.Format(time.RFC3339) + "&source=CRON",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"BAD_REQUEST","message":"invalid request"},"details":[{"field":"source","rule":"oneof"}]}`,
+		},
+		{
+			name:       "missing since",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"BAD_REQUEST","message":"invalid request"},"details":[{"field":"since","rule":"required"}]}`,
+		},
+		{
+			name:       "invalid since",
+			query:      "since=2025-10-01",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"BAD_REQUEST","message":"invalid request"},"details":[{"field":"since","rule":"rfc3339"}]}`,
+		},
+		{
+			name:  "older than retention",
+			query: "since=" + since.Format(time.RFC3339),
+			setupMocks: func(s *mocks.UserService) {
+				s.On("GetAssignmentEvents", mock.Anything, since, domain.AssignmentSource("")).Return(domain.ReviewerEventsPage{}, &domain.ValidationError{
+					Violations: []domain.FieldViolation{{Field: "since", Rule: domain.RuleRetention}},
+				})
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"BAD_REQUEST","message":"invalid input"},"details":[{"field":"since","rule":"retention"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t)
+			if tt.setupMocks != nil {
+				tt.setupMocks(service)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/events/assignments?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			handler.GetAssignmentEvents(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			assert.JSONEq(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_GetOverview(t *testing.T) {
+	createdAt := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	assignedAt := createdAt.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		query      string
+		setupMocks func(*mocks.UserService)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:  "full overview",
+			query: "user_id=u1",
+			setupMocks: func(s *mocks.UserService) {
+				s.On("GetOverview", mock.Anything, "u1").Return(&domain.UserOverview{
+					User: domain.User{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true, ReviewCapacity: 1},
+					AuthoredOpen: []domain.OpenPullRequest{{
+						PullRequestShort: domain.PullRequestShort{PullRequestID: "pr1", PullRequestName: "Fix", AuthorID: "u1", Status: domain.PRStatusOpen},
+						CreatedAt:        createdAt,
+					}},
+					ReviewingOpen: []domain.OpenPullRequest{{
+						PullRequestShort: domain.PullRequestShort{PullRequestID: "pr2", PullRequestName: "Add", AuthorID: "u2", Status: domain.PRStatusOpen},
+						CreatedAt:        createdAt,
+						AssignedAt:       &assignedAt,
+					}},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"user":{"user_id":"u1","username":"Alice","team_name":"backend","is_active":true,"review_capacity":1},
+				"authored_open":[{"pull_request_id":"pr1","pull_request_name":"Fix","author_id":"u1","status":"OPEN","orphaned":false,
+					"created_at":"2025-10-01T12:00:00.000Z"}],
+				"reviewing_open":[{"pull_request_id":"pr2","pull_request_name":"Add","author_id":"u2","status":"OPEN","orphaned":false,
+					"created_at":"2025-10-01T12:00:00.000Z","assigned_at":"2025-10-01T13:00:00.000Z"}]}`,
+		},
+		{
+			name:  "no open PRs",
+			query: "user_id=u1",
+			setupMocks: func(s *mocks.UserService) {
+				s.On("GetOverview", mock.Anything, "u1").Return(&domain.UserOverview{
+					User: domain.User{UserID: "u1", Username: "Alice", TeamName: "backend", ReviewCapacity: 1},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"user":{"user_id":"u1","username":"Alice","team_name":"backend","is_active":false,"review_capacity":1},
+				"authored_open":[],"reviewing_open":[]}`,
+		},
+		{
+			name:  "unknown user",
+			query: "user_id=ghost",
+			setupMocks: func(s *mocks.UserService) {
+				s.On("GetOverview", mock.Anything, "ghost").Return(nil, domain.ErrUserNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, service := setupTestHandler(t)
+			tt.setupMocks(service)
+
+			req := httptest.NewRequest(http.MethodGet, "/users/overview?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			handler.GetOverview(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,431 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "avito_backend_task/internal/domain"
+	context "context"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UserService is an autogenerated mock type for the UserService type
+type UserService struct {
+	mock.Mock
+}
+
+// ActivateWithTopUp provides a mock function with given fields: ctx, userID
+func (_m *UserService) ActivateWithTopUp(ctx context.Context, userID string) (*domain.User, []string, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ActivateWithTopUp")
+	}
+
+	var r0 *domain.User
+	var r1 []string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.User, []string, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.User); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) []string); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, userID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetAssignmentEvents provides a mock function with given fields: ctx, since, source
+func (_m *UserService) GetAssignmentEvents(ctx context.Context, since time.Time, source domain.AssignmentSource) (domain.ReviewerEventsPage, error) {
+	ret := _m.Called(ctx, since, source)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAssignmentEvents")
+	}
+
+	var r0 domain.ReviewerEventsPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, domain.AssignmentSource) (domain.ReviewerEventsPage, error)); ok {
+		return rf(ctx, since, source)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, domain.AssignmentSource) domain.ReviewerEventsPage); ok {
+		r0 = rf(ctx, since, source)
+	} else {
+		r0 = ret.Get(0).(domain.ReviewerEventsPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, domain.AssignmentSource) error); ok {
+		r1 = rf(ctx, since, source)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFormerReviews provides a mock function with given fields: ctx, userID
+func (_m *UserService) GetFormerReviews(ctx context.Context, userID string) ([]domain.FormerReview, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFormerReviews")
+	}
+
+	var r0 []domain.FormerReview
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.FormerReview, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.FormerReview); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.FormerReview)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOverview provides a mock function with given fields: ctx, userID
+func (_m *UserService) GetOverview(ctx context.Context, userID string) (*domain.UserOverview, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOverview")
+	}
+
+	var r0 *domain.UserOverview
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.UserOverview, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.UserOverview); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.UserOverview)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReviewPRsByUserID provides a mock function with given fields: ctx, userID, filter
+func (_m *UserService) GetReviewPRsByUserID(ctx context.Context, userID string, filter domain.ReviewFilter) ([]domain.PullRequestShort, error) {
+	ret := _m.Called(ctx, userID, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReviewPRsByUserID")
+	}
+
+	var r0 []domain.PullRequestShort
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.ReviewFilter) ([]domain.PullRequestShort, error)); ok {
+		return rf(ctx, userID, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.ReviewFilter) []domain.PullRequestShort); ok {
+		r0 = rf(ctx, userID, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PullRequestShort)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.ReviewFilter) error); ok {
+		r1 = rf(ctx, userID, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserReviewTimeline provides a mock function with given fields: ctx, userID, from, to, page
+func (_m *UserService) GetUserReviewTimeline(ctx context.Context, userID string, from *time.Time, to *time.Time, page domain.Page) ([]domain.ReviewerEvent, error) {
+	ret := _m.Called(ctx, userID, from, to, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserReviewTimeline")
+	}
+
+	var r0 []domain.ReviewerEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time, *time.Time, domain.Page) ([]domain.ReviewerEvent, error)); ok {
+		return rf(ctx, userID, from, to, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time, *time.Time, domain.Page) []domain.ReviewerEvent); ok {
+		r0 = rf(ctx, userID, from, to, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ReviewerEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *time.Time, *time.Time, domain.Page) error); ok {
+		r1 = rf(ctx, userID, from, to, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveFromTeam provides a mock function with given fields: ctx, teamName, userID
+func (_m *UserService) RemoveFromTeam(ctx context.Context, teamName string, userID string) (*domain.User, error) {
+	ret := _m.Called(ctx, teamName, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveFromTeam")
+	}
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.User, error)); ok {
+		return rf(ctx, teamName, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.User); ok {
+		r0 = rf(ctx, teamName, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, teamName, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetCapacity provides a mock function with given fields: ctx, userID, capacity
+func (_m *UserService) SetCapacity(ctx context.Context, userID string, capacity float64) (*domain.User, error) {
+	ret := _m.Called(ctx, userID, capacity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCapacity")
+	}
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64) (*domain.User, error)); ok {
+		return rf(ctx, userID, capacity)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64) *domain.User); ok {
+		r0 = rf(ctx, userID, capacity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, float64) error); ok {
+		r1 = rf(ctx, userID, capacity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetIsActive provides a mock function with given fields: ctx, userID, isActive
+func (_m *UserService) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+	ret := _m.Called(ctx, userID, isActive)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetIsActive")
+	}
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (*domain.User, error)); ok {
+		return rf(ctx, userID, isActive)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) *domain.User); ok {
+		r0 = rf(ctx, userID, isActive)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, userID, isActive)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetIsActiveOrCreate provides a mock function with given fields: ctx, member, teamName
+func (_m *UserService) SetIsActiveOrCreate(ctx context.Context, member domain.TeamMember, teamName string) (*domain.User, bool, error) {
+	ret := _m.Called(ctx, member, teamName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetIsActiveOrCreate")
+	}
+
+	var r0 *domain.User
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TeamMember, string) (*domain.User, bool, error)); ok {
+		return rf(ctx, member, teamName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TeamMember, string) *domain.User); ok {
+		r0 = rf(ctx, member, teamName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.TeamMember, string) bool); ok {
+		r1 = rf(ctx, member, teamName)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, domain.TeamMember, string) error); ok {
+		r2 = rf(ctx, member, teamName)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SetSchedule provides a mock function with given fields: ctx, userID, workingHours, timezone
+func (_m *UserService) SetSchedule(ctx context.Context, userID string, workingHours string, timezone string) (*domain.User, error) {
+	ret := _m.Called(ctx, userID, workingHours, timezone)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetSchedule")
+	}
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*domain.User, error)); ok {
+		return rf(ctx, userID, workingHours, timezone)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *domain.User); ok {
+		r0 = rf(ctx, userID, workingHours, timezone)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, userID, workingHours, timezone)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StreamReviewPRsByUserID provides a mock function with given fields: ctx, userID, filter, fn
+func (_m *UserService) StreamReviewPRsByUserID(ctx context.Context, userID string, filter domain.ReviewFilter, fn func(domain.PullRequestShort) error) error {
+	ret := _m.Called(ctx, userID, filter, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamReviewPRsByUserID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.ReviewFilter, func(domain.PullRequestShort) error) error); ok {
+		r0 = rf(ctx, userID, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ValidateUserIDs provides a mock function with given fields: ctx, userIDs
+func (_m *UserService) ValidateUserIDs(ctx context.Context, userIDs []string) ([]string, []string, error) {
+	ret := _m.Called(ctx, userIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateUserIDs")
+	}
+
+	var r0 []string
+	var r1 []string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]string, []string, error)); ok {
+		return rf(ctx, userIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []string); ok {
+		r0 = rf(ctx, userIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) []string); ok {
+		r1 = rf(ctx, userIDs)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, []string) error); ok {
+		r2 = rf(ctx, userIDs)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewUserService creates a new instance of UserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserService {
+	mock := &UserService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+
+	"avito_backend_task/internal/auth"
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/transport/http/response"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuth пропускает только запросы с известным ключом и кладёт его область в контекст
+func APIKeyAuth(keys map[string]auth.Scope, lg *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope, ok := lookupKey(keys, r.Header.Get(APIKeyHeader))
+			if !ok {
+				response.RespondError(w, lg.With(slog.String("path", r.URL.Path)), response.ErrUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithScope(r.Context(), scope)))
+		})
+	}
+}
+
+// RequireAdmin закрывает маршрут для ключей, привязанных к команде
+func RequireAdmin(lg *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if auth.IsTeamScoped(r.Context()) {
+				response.RespondError(w, lg.With(slog.String("path", r.URL.Path)), domain.ErrForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func lookupKey(keys map[string]auth.Scope, key string) (auth.Scope, bool) {
+	if key == "" {
+		return auth.Scope{}, false
+	}
+
+	// сравнение за постоянное время, чтобы не раскрывать ключи по времени ответа
+	for candidate, scope := range keys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			return scope, true
+		}
+	}
+	return auth.Scope{}, false
+}
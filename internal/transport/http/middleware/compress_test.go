@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	large := `{"items":[` + strings.Repeat(`"pr-1001",`, 500) + `"pr-1002"]}`
+	small := `{"status":"ok"}`
+
+	tests := []struct {
+		name           string
+		body           string
+		acceptEncoding string
+		wantGzip       bool
+	}{
+		{name: "large body accepted", body: large, acceptEncoding: "gzip, deflate, br", wantGzip: true},
+		{name: "large body without accept", body: large},
+		{name: "large body with gzip refused", body: large, acceptEncoding: "gzip;q=0, deflate"},
+		{name: "small body accepted", body: small, acceptEncoding: "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Compress(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				// пишем частями, чтобы порог набирался в буфере
+				for i := 0; i < len(tt.body); i += 100 {
+					_, _ = w.Write([]byte(tt.body[i:min(i+100, len(tt.body))]))
+				}
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+			if !tt.wantGzip {
+				assert.Empty(t, rec.Header().Get("Content-Encoding"))
+				assert.Equal(t, tt.body, rec.Body.String())
+				return
+			}
+
+			assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+			assert.Less(t, rec.Body.Len(), len(tt.body))
+			reader, err := gzip.NewReader(rec.Body)
+			require.NoError(t, err)
+			decoded, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, tt.body, string(decoded))
+		})
+	}
+}
+
+func TestCompress_EmptyResponse(t *testing.T) {
+	handler := Compress(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Zero(t, rec.Body.Len())
+}
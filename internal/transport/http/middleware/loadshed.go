@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/transport/http/response"
+)
+
+// LoadShedConfig - ограничение одновременно обрабатываемых запросов API
+type LoadShedConfig struct {
+	// MaxInFlight - сколько запросов обрабатывается одновременно
+	MaxInFlight int
+	// QueueTimeout - сколько GET, HEAD и OPTIONS ждут свободного места, прежде чем получить OVERLOADED
+	QueueTimeout time.Duration
+	// RetryAfter - значение заголовка Retry-After в ответе OVERLOADED
+	RetryAfter time.Duration
+	// InFlight и Shed - текущее число запросов и число отклонённых, nil - без метрик
+	InFlight prometheus.Gauge
+	Shed     prometheus.Counter
+	// ExemptPaths не ограничиваются, например долгие SSE-потоки, которые иначе занимали бы место до отключения
+	ExemptPaths []string
+}
+
+// LoadShed пропускает не больше cfg.MaxInFlight запросов одновременно. Сверх лимита изменяющие
+// запросы сразу получают 503 OVERLOADED с Retry-After, а чтение ждёт освобождения места до cfg.QueueTimeout
+func LoadShed(cfg LoadShedConfig) func(next http.Handler) http.Handler {
+	slots := make(chan struct{}, max(cfg.MaxInFlight, 1))
+	retryAfter := strconv.Itoa(max(int(cfg.RetryAfter.Round(time.Second)/time.Second), 1))
+	exempt := make(map[string]struct{}, len(cfg.ExemptPaths))
+	for _, path := range cfg.ExemptPaths {
+		exempt[path] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := exempt[r.URL.Path]; ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err := acquire(r, slots, cfg.QueueTimeout); err != nil {
+				if !response.ClientGone(err) {
+					if cfg.Shed != nil {
+						cfg.Shed.Inc()
+					}
+					w.Header().Set("Retry-After", retryAfter)
+				}
+				// перегрузку не логируем по запросу: при всплеске это тысячи записей, её видно по метрике
+				response.RespondError(w, nil, err)
+				return
+			}
+			if cfg.InFlight != nil {
+				cfg.InFlight.Inc()
+			}
+			defer func() {
+				if cfg.InFlight != nil {
+					cfg.InFlight.Dec()
+				}
+				<-slots
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// acquire занимает место в slots: изменяющие запросы не ждут, чтение ждёт до queueTimeout
+func acquire(r *http.Request, slots chan struct{}, queueTimeout time.Duration) error {
+	select {
+	case slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return domain.ErrOverloaded
+	}
+	if queueTimeout <= 0 {
+		return domain.ErrOverloaded
+	}
+
+	timer := time.NewTimer(queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return domain.ErrOverloaded
+	case <-r.Context().Done():
+		return r.Context().Err()
+	}
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingHandler держит запросы, пока не закрыт release, и сообщает в started о каждом начатом
+func blockingHandler(started chan<- struct{}, release <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func newLoadShed(t *testing.T, queueTimeout time.Duration) (http.Handler, chan struct{}, chan struct{}, prometheus.Gauge, prometheus.Counter) {
+	t.Helper()
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	inFlight := prometheus.NewGauge(prometheus.GaugeOpts{Name: "in_flight"})
+	shed := prometheus.NewCounter(prometheus.CounterOpts{Name: "shed"})
+
+	handler := LoadShed(LoadShedConfig{
+		MaxInFlight:  1,
+		QueueTimeout: queueTimeout,
+		RetryAfter:   2 * time.Second,
+		InFlight:     inFlight,
+		Shed:         shed,
+	})(blockingHandler(started, release))
+	return handler, started, release, inFlight, shed
+}
+
+// occupy занимает единственное место запросом, который висит до закрытия release
+func occupy(t *testing.T, handler http.Handler, started <-chan struct{}) <-chan struct{} {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/pullRequest/create", nil))
+	}()
+	<-started
+	return done
+}
+
+func assertOverloaded(t *testing.T, rec *httptest.ResponseRecorder) {
+	t.Helper()
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, "OVERLOADED", body.Error.Code)
+}
+
+func TestLoadShed_MutatingRequestShedImmediately(t *testing.T) {
+	handler, started, release, inFlight, shed := newLoadShed(t, time.Minute)
+	done := occupy(t, handler, started)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(inFlight))
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/team/add", nil))
+
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assertOverloaded(t, rec)
+	assert.Equal(t, 1.0, testutil.ToFloat64(shed))
+
+	close(release)
+	<-done
+	assert.Equal(t, 0.0, testutil.ToFloat64(inFlight))
+}
+
+func TestLoadShed_ReadWaitsForSlot(t *testing.T) {
+	handler, started, release, _, shed := newLoadShed(t, time.Second)
+	done := occupy(t, handler, started)
+
+	rec := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/team/get", nil))
+	}()
+
+	// GET ждёт в очереди, а не отклоняется
+	select {
+	case <-served:
+		t.Fatal("GET finished while the slot was busy")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-done
+	<-started
+	<-served
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 0.0, testutil.ToFloat64(shed))
+}
+
+func TestLoadShed_ReadShedAfterQueueTimeout(t *testing.T) {
+	handler, started, release, _, shed := newLoadShed(t, 50*time.Millisecond)
+	done := occupy(t, handler, started)
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/team/get", nil))
+
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assertOverloaded(t, rec)
+	assert.Equal(t, 1.0, testutil.ToFloat64(shed))
+
+	close(release)
+	<-done
+}
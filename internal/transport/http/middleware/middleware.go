@@ -3,15 +3,38 @@ package middleware
 import (
 	"log/slog"
 	"net/http"
+	"regexp"
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
+
+	"avito_backend_task/pkg/logctx"
 )
 
+// RequestIDHeader - идентификатор запроса: принимается от клиента или прокси, иначе генерируется,
+// и возвращается в ответе
+const RequestIDHeader = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestID кладёт идентификатор запроса в контекст для logctx. Значение заголовка с посторонними
+// символами или длиннее 64 заменяется сгенерированным, чтобы клиент не мог подделать строки лога
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = logctx.NewID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		next.ServeHTTP(w, r.WithContext(logctx.WithRequestID(r.Context(), id)))
+	})
+}
+
 func LoggingMiddleware(log *slog.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			entry := log.With(
+			entry := logctx.With(r.Context(), log,
 				slog.String("method", r.Method),
 				slog.String("path", r.URL.Path),
 				slog.String("remote_addr", r.RemoteAddr),
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/transport/http/response"
+)
+
+// ReadOnly отклоняет изменяющие запросы ошибкой READ_ONLY, не обращаясь к БД. GET, HEAD и OPTIONS
+// проходят, как и POST на allowedPaths - эндпоинты, которые только читают данные
+func ReadOnly(lg *slog.Logger, allowedPaths ...string) func(next http.Handler) http.Handler {
+	allowed := make(map[string]struct{}, len(allowedPaths))
+	for _, path := range allowedPaths {
+		allowed[path] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				if _, ok := allowed[r.URL.Path]; !ok {
+					response.RespondError(w, lg.With(slog.String("path", r.URL.Path)), domain.ErrReadOnly)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnly(t *testing.T) {
+	lg := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		wantPass bool
+	}{
+		{name: "get passes", method: http.MethodGet, path: "/team/get", wantPass: true},
+		{name: "head passes", method: http.MethodHead, path: "/team/get", wantPass: true},
+		{name: "allowed post passes", method: http.MethodPost, path: "/users/validate", wantPass: true},
+		{name: "post rejected", method: http.MethodPost, path: "/pullRequest/create"},
+		{name: "patch rejected", method: http.MethodPatch, path: "/pullRequest"},
+		{name: "delete rejected", method: http.MethodDelete, path: "/team/blackouts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := ReadOnly(lg, "/users/validate")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.wantPass, called)
+			if tt.wantPass {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				return
+			}
+
+			assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+			var body struct {
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+			assert.Equal(t, "READ_ONLY", body.Error.Code)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package query
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/transport/http/response"
+	"avito_backend_task/internal/transport/http/validation"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// все ошибки разбора - response.InvalidRequestError с именем параметра и нарушенным правилом,
+// их можно напрямую отдавать в response.RespondError
+
+// Required возвращает непустое значение обязательного параметра
+func Required(r *http.Request, name string) (string, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return "", invalid(name, "required")
+	}
+
+	return value, nil
+}
+
+// RequiredID - Required для pull_request_id, user_id и team_name с проверкой domain.IsIdentifier
+func RequiredID(r *http.Request, name string) (string, error) {
+	value, err := Required(r, name)
+	if err != nil {
+		return "", err
+	}
+	if len(value) > 64 {
+		return "", invalid(name, "max=64")
+	}
+	if !domain.IsIdentifier(value) {
+		return "", invalid(name, validation.IdentifierTag)
+	}
+
+	return value, nil
+}
+
+// RequiredInt64 возвращает значение обязательного целочисленного параметра
+func RequiredInt64(r *http.Request, name string) (int64, error) {
+	raw, err := Required(r, name)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, invalid(name, "integer")
+	}
+
+	return value, nil
+}
+
+// Int возвращает def, если параметр не передан
+func Int(r *http.Request, name string, def int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, invalid(name, "integer")
+	}
+
+	return value, nil
+}
+
+// Bool возвращает def, если параметр не передан
+func Bool(r *http.Request, name string, def bool) (bool, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, invalid(name, "boolean")
+	}
+
+	return value, nil
+}
+
+// Time разбирает RFC3339, nil если параметр не передан
+func Time(r *http.Request, name string) (*time.Time, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, invalid(name, "rfc3339")
+	}
+
+	return &value, nil
+}
+
+// RequiredTime - Time для обязательного параметра
+func RequiredTime(r *http.Request, name string) (time.Time, error) {
+	value, err := Time(r, name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if value == nil {
+		return time.Time{}, invalid(name, "required")
+	}
+
+	return *value, nil
+}
+
+// Page разбирает limit/offset и непрозрачный cursor из NextCursor; cursor и offset вместе не передаются
+func Page(r *http.Request) (domain.Page, error) {
+	limit, err := Int(r, "limit", DefaultLimit)
+	if err != nil {
+		return domain.Page{}, err
+	}
+	if limit <= 0 {
+		return domain.Page{}, invalid("limit", "min=1")
+	}
+	if limit > MaxLimit {
+		return domain.Page{}, invalid("limit", "max="+strconv.Itoa(MaxLimit))
+	}
+
+	offset, err := Int(r, "offset", 0)
+	if err != nil {
+		return domain.Page{}, err
+	}
+	if offset < 0 {
+		return domain.Page{}, invalid("offset", "min=0")
+	}
+
+	raw := r.URL.Query().Get("cursor")
+	if raw == "" {
+		return domain.Page{Limit: limit, Offset: offset}, nil
+	}
+	if offset > 0 {
+		return domain.Page{}, invalid("offset", "excluded_with=cursor")
+	}
+	after, ok := decodeCursor(raw)
+	if !ok {
+		return domain.Page{}, invalid("cursor", "cursor")
+	}
+
+	return domain.Page{Limit: limit, After: &after}, nil
+}
+
+// NextCursor - cursor следующей страницы после last. Пусто, если страница неполная и дальше записей нет
+func NextCursor(page domain.Page, count int, last domain.PageCursor) string {
+	if count < page.Limit {
+		return ""
+	}
+	raw := strconv.FormatInt(last.CreatedAt.UnixNano(), 10) + ":" + strconv.FormatInt(last.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (domain.PageCursor, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return domain.PageCursor{}, false
+	}
+	nanosRaw, idRaw, found := strings.Cut(string(raw), ":")
+	if !found {
+		return domain.PageCursor{}, false
+	}
+	nanos, err := strconv.ParseInt(nanosRaw, 10, 64)
+	if err != nil {
+		return domain.PageCursor{}, false
+	}
+	id, err := strconv.ParseInt(idRaw, 10, 64)
+	if err != nil {
+		return domain.PageCursor{}, false
+	}
+
+	return domain.PageCursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: id}, true
+}
+
+func invalid(name, rule string) error {
+	return response.InvalidRequest(response.FieldError{Field: name, Rule: rule})
+}
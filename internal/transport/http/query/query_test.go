@@ -0,0 +1,175 @@
+package query
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/transport/http/response"
+)
+
+func request(rawQuery string) *http.Request {
+	return httptest.NewRequest(http.MethodGet, "/?"+rawQuery, nil)
+}
+
+func invalidField(name, rule string) error {
+	return response.InvalidRequest(response.FieldError{Field: name, Rule: rule})
+}
+
+func TestRequiredID(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr error
+	}{
+		{name: "valid", query: "user_id=u1", want: "u1"},
+		{name: "missing", query: "", wantErr: invalidField("user_id", "required")},
+		{name: "not identifier", query: "user_id=u%201", wantErr: invalidField("user_id", "identifier")},
+		{name: "too long", query: "user_id=" + strings.Repeat("u", 65), wantErr: invalidField("user_id", "max=64")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RequiredID(request(tt.query), "user_id")
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRequiredInt64(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    int64
+		wantErr error
+	}{
+		{name: "valid", query: "blackout_id=9000000000", want: 9000000000},
+		{name: "negative", query: "blackout_id=-3", want: -3},
+		{name: "missing", query: "", wantErr: invalidField("blackout_id", "required")},
+		{name: "not a number", query: "blackout_id=abc", wantErr: invalidField("blackout_id", "integer")},
+		{name: "fraction", query: "blackout_id=1.5", wantErr: invalidField("blackout_id", "integer")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RequiredInt64(request(tt.query), "blackout_id")
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    int
+		wantErr error
+	}{
+		{name: "valid", query: "count=7", want: 7},
+		{name: "missing uses default", query: "", want: 5},
+		{name: "empty uses default", query: "count=", want: 5},
+		{name: "not a number", query: "count=seven", wantErr: invalidField("count", "integer")},
+		{name: "overflow", query: "count=99999999999999999999", wantErr: invalidField("count", "integer")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Int(request(tt.query), "count", 5)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBool(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    bool
+		wantErr error
+	}{
+		{name: "true", query: "stream=true", want: true},
+		{name: "one", query: "stream=1", want: true},
+		{name: "false", query: "stream=false", want: false},
+		{name: "missing uses default", query: "", want: true},
+		{name: "invalid", query: "stream=yes", wantErr: invalidField("stream", "boolean")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Bool(request(tt.query), "stream", true)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTime(t *testing.T) {
+	at := time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC)
+
+	got, err := Time(request("from=2025-11-03T12:00:00Z"), "from")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.True(t, at.Equal(*got))
+
+	got, err = Time(request(""), "from")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	_, err = Time(request("from=2025-11-03"), "from")
+	assert.Equal(t, invalidField("from", "rfc3339"), err)
+
+	_, err = RequiredTime(request(""), "since")
+	assert.Equal(t, invalidField("since", "required"), err)
+
+	required, err := RequiredTime(request("since=2025-11-03T15:00:00%2B03:00"), "since")
+	require.NoError(t, err)
+	assert.True(t, at.Equal(required))
+}
+
+func TestPage_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr error
+	}{
+		{name: "limit not a number", query: "limit=ten", wantErr: invalidField("limit", "integer")},
+		{name: "limit zero", query: "limit=0", wantErr: invalidField("limit", "min=1")},
+		{name: "limit above max", query: "limit=501", wantErr: invalidField("limit", "max=500")},
+		{name: "negative offset", query: "offset=-1", wantErr: invalidField("offset", "min=0")},
+		{name: "offset with cursor", query: "offset=2&cursor=abc", wantErr: invalidField("offset", "excluded_with=cursor")},
+		{name: "broken cursor", query: "cursor=%21%21", wantErr: invalidField("cursor", "cursor")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Page(request(tt.query))
+			assert.Equal(t, tt.wantErr, err)
+			assert.ErrorIs(t, err, response.ErrInvalidRequest)
+		})
+	}
+}
+
+func TestPage_CursorRoundTrip(t *testing.T) {
+	last := domain.PageCursor{CreatedAt: time.Date(2025, 11, 3, 12, 0, 0, 123456000, time.UTC), ID: 42}
+
+	cursor := NextCursor(domain.Page{Limit: 2}, 2, last)
+	require.NotEmpty(t, cursor)
+
+	page, err := Page(httptest.NewRequest("GET", "/users/timeline?limit=2&cursor="+cursor, nil))
+	require.NoError(t, err)
+	assert.Equal(t, domain.Page{Limit: 2, After: &last}, page)
+}
+
+func TestNextCursor_LastPage(t *testing.T) {
+	assert.Empty(t, NextCursor(domain.Page{Limit: 50}, 3, domain.PageCursor{ID: 1}))
+}
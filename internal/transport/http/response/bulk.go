@@ -0,0 +1,60 @@
+package response
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// BulkResult - общий ответ bulk-эндпоинтов. Элементы запроса обрабатываются независимо,
+// results идут в порядке обработки, index - позиция элемента в запросе
+type BulkResult[T any] struct {
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []BulkItem[T] `json:"results"`
+}
+
+// BulkItem - итог одного элемента: resource при успехе или error с тем же кодом, что и у одиночного запроса
+type BulkItem[T any] struct {
+	Index    int          `json:"index"`
+	Status   int          `json:"status"`
+	Resource *T           `json:"resource,omitempty"`
+	Error    *ErrorDetail `json:"error,omitempty"`
+}
+
+// NewBulkResult готовит ответ на size элементов, results без элементов сериализуется как []
+func NewBulkResult[T any](size int) *BulkResult[T] {
+	return &BulkResult[T]{Results: make([]BulkItem[T], 0, size)}
+}
+
+// Succeed добавляет успешный элемент со статусом одиночного запроса
+func (b *BulkResult[T]) Succeed(index, status int, resource T) {
+	b.Succeeded++
+	b.Results = append(b.Results, BulkItem[T]{Index: index, Status: status, Resource: &resource})
+}
+
+// Fail добавляет неудачный элемент, статус и код ошибки берутся из MapError
+func (b *BulkResult[T]) Fail(index int, err error) {
+	mapping := MapError(err)
+	b.Failed++
+	b.Results = append(b.Results, BulkItem[T]{
+		Index:  index,
+		Status: mapping.StatusCode,
+		Error:  &ErrorDetail{Code: mapping.Code, Message: mapping.Message},
+	})
+}
+
+// RespondBulk отвечает 200, если все элементы успешны, и 207, если хотя бы один не обработан.
+// Ошибки элементов логируются одной записью с их числом, каждая уже есть в results
+func RespondBulk[T any](w http.ResponseWriter, lg *slog.Logger, result *BulkResult[T]) {
+	status := http.StatusOK
+	if result.Failed > 0 {
+		status = http.StatusMultiStatus
+		if lg != nil {
+			lg.Info("bulk request partially failed",
+				slog.Int("succeeded", result.Succeeded),
+				slog.Int("failed", result.Failed))
+		}
+	}
+
+	RespondJSON(w, status, result)
+}
@@ -0,0 +1,58 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"avito_backend_task/internal/domain"
+)
+
+type bulkResource struct {
+	ID string `json:"id"`
+}
+
+func TestBulkResult_Serialization(t *testing.T) {
+	t.Run("empty results are an array", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		RespondBulk(rec, nil, NewBulkResult[bulkResource](0))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"succeeded":0,"failed":0,"results":[]}`, rec.Body.String())
+	})
+
+	t.Run("all succeeded", func(t *testing.T) {
+		result := NewBulkResult[bulkResource](2)
+		result.Succeed(0, http.StatusCreated, bulkResource{ID: "a"})
+		result.Succeed(1, http.StatusCreated, bulkResource{ID: "b"})
+		rec := httptest.NewRecorder()
+
+		RespondBulk(rec, nil, result)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"succeeded":2,"failed":0,"results":[
+			{"index":0,"status":201,"resource":{"id":"a"}},
+			{"index":1,"status":201,"resource":{"id":"b"}}]}`, rec.Body.String())
+	})
+
+	t.Run("failures use error mapping", func(t *testing.T) {
+		result := NewBulkResult[bulkResource](3)
+		result.Succeed(0, http.StatusOK, bulkResource{ID: "a"})
+		result.Fail(1, fmt.Errorf("merge: %w", domain.ErrPRNotFound))
+		result.Fail(2, errors.New("db down"))
+		rec := httptest.NewRecorder()
+
+		RespondBulk(rec, nil, result)
+
+		assert.Equal(t, http.StatusMultiStatus, rec.Code)
+		assert.JSONEq(t, `{"succeeded":1,"failed":2,"results":[
+			{"index":0,"status":200,"resource":{"id":"a"}},
+			{"index":1,"status":404,"error":{"code":"NOT_FOUND","message":"pull request not found"}},
+			{"index":2,"status":500,"error":{"code":"INTERNAL_ERROR","message":"internal server error"}}]}`, rec.Body.String())
+	})
+}
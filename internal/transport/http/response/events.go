@@ -0,0 +1,55 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// EventStreamHeartbeat - как часто в простаивающий SSE-поток пишется комментарий, чтобы прокси
+// и балансировщики не закрывали соединение по таймауту простоя
+const EventStreamHeartbeat = 15 * time.Second
+
+// EventStream пишет ответ в формате Server-Sent Events, сбрасывая клиенту каждое событие
+type EventStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func NewEventStream(w http.ResponseWriter) *EventStream {
+	return &EventStream{w: w, rc: http.NewResponseController(w)}
+}
+
+// Start отправляет заголовки 200. SERVER_WRITE_TIMEOUT на поток не распространяется,
+// иначе сервер обрывал бы его через WriteTimeout после подключения
+func (s *EventStream) Start() error {
+	_ = s.rc.SetWriteDeadline(time.Time{})
+
+	s.w.Header().Set("Content-Type", "text/event-stream")
+	s.w.Header().Set("Cache-Control", "no-cache")
+	// nginx иначе буферизует ответ и события приходят пачками
+	s.w.Header().Set("X-Accel-Buffering", "no")
+	s.w.WriteHeader(http.StatusOK)
+	return s.rc.Flush()
+}
+
+// Send пишет событие event с data в JSON
+func (s *EventStream) Send(event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// Heartbeat пишет комментарий, клиенты SSE его игнорируют
+func (s *EventStream) Heartbeat() error {
+	if _, err := s.w.Write([]byte(": ping\n\n")); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
@@ -0,0 +1,46 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JSONTimeLayout - RFC3339 с миллисекундами, время всегда в UTC
+const JSONTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// JSONTime - метка времени в ответах API. В отличие от time.Time точность не плавает:
+// всегда ровно три знака после секунд, даже если они нулевые
+type JSONTime struct {
+	time.Time
+}
+
+func NewJSONTime(t time.Time) JSONTime {
+	return JSONTime{Time: t}
+}
+
+// OptionalJSONTime сохраняет nil, чтобы поле с omitempty пропадало из ответа
+func OptionalJSONTime(t *time.Time) *JSONTime {
+	if t == nil {
+		return nil
+	}
+	return &JSONTime{Time: *t}
+}
+
+func (t JSONTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.UTC().Format(JSONTimeLayout))
+}
+
+// UnmarshalJSON принимает любой RFC3339, с дробной частью или без
+func (t *JSONTime) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
@@ -0,0 +1,46 @@
+package response
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONTime_Marshal(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	tests := []struct {
+		name string
+		in   time.Time
+		want string
+	}{
+		{name: "whole seconds keep milliseconds", in: time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC), want: `"2025-11-01T10:00:00.000Z"`},
+		{name: "nanoseconds truncated", in: time.Date(2025, 11, 1, 10, 0, 0, 123456789, time.UTC), want: `"2025-11-01T10:00:00.123Z"`},
+		{name: "converted to UTC", in: time.Date(2025, 11, 1, 13, 0, 0, 5_000_000, moscow), want: `"2025-11-01T10:00:00.005Z"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(NewJSONTime(tt.in))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+}
+
+func TestJSONTime_Optional(t *testing.T) {
+	type dto struct {
+		At *JSONTime `json:"at,omitempty"`
+	}
+
+	data, err := json.Marshal(dto{At: OptionalJSONTime(nil)})
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(data))
+
+	var decoded dto
+	require.NoError(t, json.Unmarshal([]byte(`{"at":"2025-11-01T10:00:00Z"}`), &decoded))
+	assert.True(t, decoded.At.Equal(time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC)))
+}
@@ -1,10 +1,16 @@
 package response
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
 
 	"avito_backend_task/internal/domain"
 )
@@ -12,16 +18,44 @@ import (
 type ErrorCode string
 
 const (
-	ErrorCodeTeamExists    ErrorCode = "TEAM_EXISTS"
-	ErrorCodePRExists      ErrorCode = "PR_EXISTS"
-	ErrorCodePRMerged      ErrorCode = "PR_MERGED"
-	ErrorCodeNotAssigned   ErrorCode = "NOT_ASSIGNED"
-	ErrorCodeNoCandidate   ErrorCode = "NO_CANDIDATE"
-	ErrorCodeNotFound      ErrorCode = "NOT_FOUND"
-	ErrorCodeBadRequest    ErrorCode = "BAD_REQUEST"
-	ErrorCodeInternalError ErrorCode = "INTERNAL_ERROR"
+	ErrorCodeTeamExists       ErrorCode = "TEAM_EXISTS"
+	ErrorCodePRExists         ErrorCode = "PR_EXISTS"
+	ErrorCodePRMerged         ErrorCode = "PR_MERGED"
+	ErrorCodePRClosed         ErrorCode = "PR_CLOSED"
+	ErrorCodePRNotOpen        ErrorCode = "PR_NOT_OPEN"
+	ErrorCodeNotAssigned      ErrorCode = "NOT_ASSIGNED"
+	ErrorCodeNoCandidate      ErrorCode = "NO_CANDIDATE"
+	ErrorCodeNotEligible      ErrorCode = "REVIEWER_NOT_ELIGIBLE"
+	ErrorCodeAssigned         ErrorCode = "ALREADY_ASSIGNED"
+	ErrorCodeTooManyReviewers ErrorCode = "TOO_MANY_REVIEWERS"
+	ErrorCodeTransition       ErrorCode = "INVALID_TRANSITION"
+	ErrorCodeOverlap          ErrorCode = "BLACKOUT_OVERLAP"
+	ErrorCodeWebhookExists    ErrorCode = "WEBHOOK_EXISTS"
+	ErrorCodeTeamTooLarge     ErrorCode = "TEAM_TOO_LARGE"
+	ErrorCodeTeamMissing      ErrorCode = "AUTHOR_TEAM_MISSING"
+	ErrorCodeAuthorInactive   ErrorCode = "AUTHOR_INACTIVE"
+	ErrorCodeRateExceeded     ErrorCode = "REASSIGNMENT_RATE_EXCEEDED"
+	ErrorCodeNotFound         ErrorCode = "NOT_FOUND"
+	ErrorCodeBadRequest       ErrorCode = "BAD_REQUEST"
+	ErrorCodeUnauthorized     ErrorCode = "UNAUTHORIZED"
+	ErrorCodeForbidden        ErrorCode = "FORBIDDEN"
+	ErrorCodeTimeout          ErrorCode = "TIMEOUT"
+	ErrorCodeReadOnly         ErrorCode = "READ_ONLY"
+	ErrorCodeOverloaded       ErrorCode = "OVERLOADED"
+	ErrorCodeActorRequired    ErrorCode = "MERGE_ACTOR_REQUIRED"
+	ErrorCodeActorNotFound    ErrorCode = "MERGE_ACTOR_NOT_FOUND"
+	ErrorCodeActorInactive    ErrorCode = "MERGE_ACTOR_INACTIVE"
+	ErrorCodeMergeForbidden   ErrorCode = "MERGE_NOT_ALLOWED"
+	ErrorCodeInternalError    ErrorCode = "INTERNAL_ERROR"
+
+	ErrorCodeRouteNotFound    ErrorCode = "ROUTE_NOT_FOUND"
+	ErrorCodeMethodNotAllowed ErrorCode = "METHOD_NOT_ALLOWED"
 )
 
+// StatusClientClosedRequest - статус запроса, клиент которого отключился до ответа (как 499 в nginx).
+// Тело при нём не пишется, статус нужен логам и метрикам, чтобы не считать такие запросы 5xx
+const StatusClientClosedRequest = 499
+
 type ErrorDetail struct {
 	Code    ErrorCode `json:"code"`
 	Message string    `json:"message"`
@@ -29,83 +63,169 @@ type ErrorDetail struct {
 
 type ErrorResponse struct {
 	Error ErrorDetail `json:"error"`
+	// Details - какие параметры запроса не прошли проверку: для BAD_REQUEST и лимит для TEAM_TOO_LARGE
+	Details []FieldError `json:"details,omitempty"`
+	// Existing - конфликтующий объект из domain.ConflictError в представлении обработчика
+	Existing any `json:"existing,omitempty"`
+	// Diagnostics - пояснение отказа из domain.ConflictError, например разбивка кандидатов для NO_CANDIDATE
+	Diagnostics any `json:"diagnostics,omitempty"`
+}
+
+// FieldError - параметр или поле запроса и нарушенное правило (required, integer, ...)
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
 }
 
 var (
 	ErrInvalidRequest = errors.New("invalid request")
+	ErrUnauthorized   = errors.New("unauthorized")
 )
 
+// InvalidRequestError - ErrInvalidRequest с перечнем нарушений, которые попадают в details ответа
+type InvalidRequestError struct {
+	Details []FieldError
+}
+
+// InvalidRequest собирает InvalidRequestError из нарушений
+func InvalidRequest(details ...FieldError) error {
+	return &InvalidRequestError{Details: details}
+}
+
+func (e *InvalidRequestError) Error() string {
+	parts := make([]string, len(e.Details))
+	for i, d := range e.Details {
+		parts[i] = d.Field + ": " + d.Rule
+	}
+	return ErrInvalidRequest.Error() + ": " + strings.Join(parts, ", ")
+}
+
+func (e *InvalidRequestError) Unwrap() error {
+	return ErrInvalidRequest
+}
+
 type ErrorMapping struct {
 	Code       ErrorCode
 	Message    string
 	StatusCode int
 }
 
-var errorMappings = map[error]ErrorMapping{
-	domain.ErrTeamExists: {
-		Code:       ErrorCodeTeamExists,
-		Message:    "team_name already exists",
-		StatusCode: http.StatusBadRequest,
-	},
-	domain.ErrPRExists: {
-		Code:       ErrorCodePRExists,
-		Message:    "PR id already exists",
-		StatusCode: http.StatusConflict,
-	},
-	domain.ErrPRMerged: {
-		Code:       ErrorCodePRMerged,
-		Message:    "cannot reassign on merged PR",
-		StatusCode: http.StatusConflict,
-	},
-	domain.ErrNotAssigned: {
-		Code:       ErrorCodeNotAssigned,
-		Message:    "reviewer is not assigned to this PR",
-		StatusCode: http.StatusConflict,
-	},
-	domain.ErrNoCandidate: {
-		Code:       ErrorCodeNoCandidate,
-		Message:    "no active replacement candidate in team",
-		StatusCode: http.StatusConflict,
-	},
-	domain.ErrPRNotFound: {
-		Code:       ErrorCodeNotFound,
-		Message:    "pull request not found",
-		StatusCode: http.StatusNotFound,
-	},
-	domain.ErrTeamNotFound: {
-		Code:       ErrorCodeNotFound,
-		Message:    "team not found",
-		StatusCode: http.StatusNotFound,
-	},
-	domain.ErrUserNotFound: {
-		Code:       ErrorCodeNotFound,
-		Message:    "user not found",
-		StatusCode: http.StatusNotFound,
-	},
-	domain.ErrInvalidInput: {
-		Code:       ErrorCodeBadRequest,
-		Message:    "invalid input",
-		StatusCode: http.StatusBadRequest,
-	},
-	ErrInvalidRequest: {
-		Code:       ErrorCodeBadRequest,
-		Message:    "invalid request",
-		StatusCode: http.StatusBadRequest,
-	},
+// registeredError - маппинг вместе с ошибкой, для которой он зарегистрирован
+type registeredError struct {
+	err     error
+	mapping ErrorMapping
+}
+
+// errorMappings в порядке регистрации: MapError проверяет их по очереди, поэтому при нескольких
+// подходящих маппингах выигрывает первый, а каталог /errors выводится в том же порядке
+var errorMappings []registeredError
+
+// register добавляет маппинг в конец errorMappings
+func register(err error, code ErrorCode, statusCode int, message string) {
+	errorMappings = append(errorMappings, registeredError{
+		err:     err,
+		mapping: ErrorMapping{Code: code, Message: message, StatusCode: statusCode},
+	})
+}
+
+func init() {
+	register(domain.ErrTeamExists, ErrorCodeTeamExists, http.StatusBadRequest, "team_name already exists")
+	register(domain.ErrPRExists, ErrorCodePRExists, http.StatusConflict, "PR id already exists")
+	// ErrPRMerged и ErrPRClosed оборачивают ErrPRNotOpen и регистрируются раньше него
+	register(domain.ErrPRMerged, ErrorCodePRMerged, http.StatusConflict, "cannot modify merged PR")
+	register(domain.ErrPRClosed, ErrorCodePRClosed, http.StatusConflict, "cannot modify closed PR")
+	register(domain.ErrPRNotOpen, ErrorCodePRNotOpen, http.StatusConflict, "PR is not open")
+	register(domain.ErrInvalidTransition, ErrorCodeTransition, http.StatusConflict, "status transition is not allowed")
+	register(domain.ErrNotAssigned, ErrorCodeNotAssigned, http.StatusConflict, "reviewer is not assigned to this PR")
+	register(domain.ErrNoCandidate, ErrorCodeNoCandidate, http.StatusConflict, "no active replacement candidate in team")
+	// покрывает ErrReviewerInactive, ErrReviewerIsAuthor и ErrReviewerNotInTeam
+	register(domain.ErrReviewerNotEligible, ErrorCodeNotEligible, http.StatusUnprocessableEntity, "reviewer cannot be assigned to this PR")
+	register(domain.ErrAlreadyAssigned, ErrorCodeAssigned, http.StatusConflict, "reviewer is already assigned to this PR")
+	register(domain.ErrReviewerCapReached, ErrorCodeTooManyReviewers, http.StatusUnprocessableEntity, "PR already has the maximum number of reviewers")
+	register(domain.ErrBlackoutOverlap, ErrorCodeOverlap, http.StatusConflict, "blackout overlaps an existing window of the team")
+	register(domain.ErrTeamTooLarge, ErrorCodeTeamTooLarge, http.StatusUnprocessableEntity, "team would exceed the maximum number of members")
+	register(domain.ErrAuthorTeamMissing, ErrorCodeTeamMissing, http.StatusUnprocessableEntity, "author's team does not exist")
+	register(domain.ErrAuthorInactive, ErrorCodeAuthorInactive, http.StatusUnprocessableEntity, "author is inactive")
+	register(domain.ErrReassignRateExceeded, ErrorCodeRateExceeded, http.StatusTooManyRequests, "too many reassignments for this PR, retry later")
+	register(domain.ErrBlackoutNotFound, ErrorCodeNotFound, http.StatusNotFound, "blackout not found")
+	register(domain.ErrWebhookExists, ErrorCodeWebhookExists, http.StatusConflict, "team already has a webhook")
+	register(domain.ErrWebhookNotFound, ErrorCodeNotFound, http.StatusNotFound, "team webhook not found")
+	register(domain.ErrPRNotFound, ErrorCodeNotFound, http.StatusNotFound, "pull request not found")
+	register(domain.ErrTeamNotFound, ErrorCodeNotFound, http.StatusNotFound, "team not found")
+	register(domain.ErrUserNotFound, ErrorCodeNotFound, http.StatusNotFound, "user not found")
+	register(domain.ErrInvalidSchedule, ErrorCodeBadRequest, http.StatusBadRequest, "invalid working hours or timezone")
+	register(domain.ErrInvalidReviewCapacity, ErrorCodeBadRequest, http.StatusBadRequest, "review capacity must be in (0, 10]")
+	register(domain.ErrInvalidInput, ErrorCodeBadRequest, http.StatusBadRequest, "invalid input")
+	register(domain.ErrForbidden, ErrorCodeForbidden, http.StatusForbidden, "api key is not allowed to access this team")
+	register(domain.ErrMergeActorRequired, ErrorCodeActorRequired, http.StatusUnprocessableEntity, "merged_by is required to merge PR")
+	register(domain.ErrMergeActorNotFound, ErrorCodeActorNotFound, http.StatusUnprocessableEntity, "merged_by user not found")
+	register(domain.ErrMergeActorInactive, ErrorCodeActorInactive, http.StatusForbidden, "inactive user cannot merge PR")
+	register(domain.ErrMergeNotAllowed, ErrorCodeMergeForbidden, http.StatusForbidden, "user is not allowed to merge this PR")
+	register(domain.ErrReadOnly, ErrorCodeReadOnly, http.StatusServiceUnavailable, "service is in read-only mode, writes are temporarily unavailable")
+	register(domain.ErrOverloaded, ErrorCodeOverloaded, http.StatusServiceUnavailable, "service is overloaded, retry later")
+	register(ErrUnauthorized, ErrorCodeUnauthorized, http.StatusUnauthorized, "missing or invalid api key")
+	register(ErrInvalidRequest, ErrorCodeBadRequest, http.StatusBadRequest, "invalid request")
+	// истёк дедлайн на стороне сервера (таймаут запроса к БД и т.п.), клиент ещё ждёт ответа
+	register(context.DeadlineExceeded, ErrorCodeTimeout, http.StatusServiceUnavailable, "request timed out")
 }
 
+// internalError - ответ на ошибку без маппинга
+var internalError = ErrorMapping{
+	Code:       ErrorCodeInternalError,
+	Message:    "internal server error",
+	StatusCode: http.StatusInternalServerError,
+}
+
+var (
+	routeNotFound    = ErrorMapping{Code: ErrorCodeRouteNotFound, Message: "route not found", StatusCode: http.StatusNotFound}
+	methodNotAllowed = ErrorMapping{Code: ErrorCodeMethodNotAllowed, Message: "method not allowed", StatusCode: http.StatusMethodNotAllowed}
+)
+
+// builtinErrors - ответы, которые пишутся не по маппингу ошибки: роутером или для ошибки без маппинга
+var builtinErrors = []ErrorMapping{routeNotFound, methodNotAllowed, internalError}
+
 func MapError(err error) ErrorMapping {
-	for domainErr, mapping := range errorMappings {
-		if errors.Is(err, domainErr) {
-			return mapping
+	for _, registered := range errorMappings {
+		if errors.Is(err, registered.err) {
+			return registered.mapping
 		}
 	}
 
-	return ErrorMapping{
-		Code:       ErrorCodeInternalError,
-		Message:    "internal server error",
-		StatusCode: http.StatusInternalServerError,
+	return internalError
+}
+
+// Catalog - все ответы об ошибках, которые может вернуть API: маппинги в порядке регистрации,
+// затем встроенные. Один код встречается несколько раз, если у него несколько сообщений
+func Catalog() []ErrorMapping {
+	catalog := make([]ErrorMapping, 0, len(errorMappings)+len(builtinErrors))
+	for _, registered := range errorMappings {
+		catalog = append(catalog, registered.mapping)
+	}
+	return append(catalog, builtinErrors...)
+}
+
+// RespondRouteNotFound - обработчик несуществующих маршрутов для chi.Router.NotFound
+func RespondRouteNotFound(w http.ResponseWriter, _ *http.Request) {
+	respondMapping(w, routeNotFound)
+}
+
+// RespondMethodNotAllowed - обработчик неподдерживаемых методов для chi.Router.MethodNotAllowed
+func RespondMethodNotAllowed(w http.ResponseWriter, _ *http.Request) {
+	respondMapping(w, methodNotAllowed)
+}
+
+func respondMapping(w http.ResponseWriter, mapping ErrorMapping) {
+	RespondJSON(w, mapping.StatusCode, ErrorResponse{
+		Error: ErrorDetail{Code: mapping.Code, Message: mapping.Message},
+	})
+}
+
+// EmptyIfNil заменяет nil-срез пустым, чтобы коллекция в ответе всегда была [], а не null
+func EmptyIfNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
 	}
+	return items
 }
 
 func RespondJSON(w http.ResponseWriter, statusCode int, data interface{}) {
@@ -118,15 +238,124 @@ func RespondJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	}
 }
 
-func RespondError(w http.ResponseWriter, err error) {
+// RespondCreated отвечает 201 с заголовком Location - адресом GET-эндпоинта созданного ресурса
+func RespondCreated(w http.ResponseWriter, location string, data interface{}) {
+	w.Header().Set("Location", location)
+	RespondJSON(w, http.StatusCreated, data)
+}
+
+// Location строит адрес GET-эндпоинта path с query-параметрами для Location. Префикс
+// смонтированного роутера (/api/v1) сохраняется, значения экранируются, пробел - как %20
+func Location(r *http.Request, path string, params url.Values) string {
+	var prefix string
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && len(rctx.RoutePatterns) > 1 {
+		for _, pattern := range rctx.RoutePatterns[:len(rctx.RoutePatterns)-1] {
+			prefix += strings.TrimSuffix(pattern, "/*")
+		}
+	}
+
+	location := prefix + path
+	if len(params) > 0 {
+		location += "?" + strings.ReplaceAll(params.Encode(), "+", "%20")
+	}
+	return location
+}
+
+// RespondConflict пишет ответ по маппингу ошибки, добавляя existing - уже сериализуемое
+// представление Payload из domain.ConflictError. Доменный объект напрямую не отдаётся, DTO строит обработчик
+func RespondConflict(w http.ResponseWriter, lg *slog.Logger, err error, existing any) {
+	respondError(w, lg, err, func(resp *ErrorResponse) { resp.Existing = existing })
+}
+
+// RespondDiagnostics пишет ответ по маппингу ошибки с полем diagnostics - представлением Payload,
+// которое объясняет отказ, а не описывает конфликтующий объект
+func RespondDiagnostics(w http.ResponseWriter, lg *slog.Logger, err error, diagnostics any) {
+	respondError(w, lg, err, func(resp *ErrorResponse) { resp.Diagnostics = diagnostics })
+}
+
+// RespondError пишет ответ по маппингу ошибки и логирует её с уровнем по статусу:
+// 5xx - Error, 409 - Warn, остальные 4xx - Info, чтобы ожидаемые отказы не засоряли ошибки.
+// Отмену контекста клиентом (ClientGone) логирует как Info и отвечает StatusClientClosedRequest без тела
+func RespondError(w http.ResponseWriter, lg *slog.Logger, err error) {
+	respondError(w, lg, err, nil)
+}
+
+// ClientGone - ошибка вызвана отменой контекста запроса, то есть клиент отключился
+func ClientGone(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+func respondError(w http.ResponseWriter, lg *slog.Logger, err error, extend func(*ErrorResponse)) {
+	if ClientGone(err) {
+		if lg != nil {
+			lg.Info("request canceled by client",
+				slog.Int("status", StatusClientClosedRequest),
+				slog.Any("error", err))
+		}
+		w.WriteHeader(StatusClientClosedRequest)
+		return
+	}
+
 	mapping := MapError(err)
 
+	if lg != nil {
+		lg.Log(context.Background(), severity(mapping.StatusCode), "request failed",
+			slog.Int("status", mapping.StatusCode),
+			slog.String("code", string(mapping.Code)),
+			slog.Any("error", err))
+	}
+
 	response := ErrorResponse{
 		Error: ErrorDetail{
 			Code:    mapping.Code,
 			Message: mapping.Message,
 		},
 	}
+	if extend != nil {
+		extend(&response)
+	}
+	var invalid *InvalidRequestError
+	if errors.As(err, &invalid) {
+		response.Details = invalid.Details
+	}
+	var violations *domain.ValidationError
+	if errors.As(err, &violations) {
+		response.Details = make([]FieldError, len(violations.Violations))
+		for i, v := range violations.Violations {
+			response.Details[i] = FieldError{Field: v.Field, Rule: v.Rule}
+		}
+	}
+	var conflict *domain.ConflictError
+	if errors.As(err, &conflict) {
+		switch payload := conflict.Payload.(type) {
+		case *domain.TeamSizeLimit:
+			if payload != nil {
+				response.Details = []FieldError{{Field: "members", Rule: "max=" + strconv.Itoa(payload.Limit)}}
+			}
+		case *domain.ReviewerCapLimit:
+			if payload != nil {
+				response.Diagnostics = reviewerCapDiagnostics{Limit: payload.Limit, Reviewers: payload.Reviewers}
+			}
+		}
+	}
 
 	RespondJSON(w, mapping.StatusCode, response)
 }
+
+// reviewerCapDiagnostics - diagnostics TOO_MANY_REVIEWERS: лимит и сколько ревьюеров уже есть у PR
+// (или запрошено в setReviewers)
+type reviewerCapDiagnostics struct {
+	Limit     int `json:"limit"`
+	Reviewers int `json:"reviewers"`
+}
+
+func severity(statusCode int) slog.Level {
+	switch {
+	case statusCode >= http.StatusInternalServerError:
+		return slog.LevelError
+	case statusCode == http.StatusConflict:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,249 @@
+package response
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/domain"
+)
+
+func TestRespondError_LogSeverity(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantLevel  string
+	}{
+		{name: "conflict logs at warn", err: fmt.Errorf("create: %w", domain.ErrPRExists), wantStatus: http.StatusConflict, wantLevel: "WARN"},
+		{name: "not found logs at info", err: domain.ErrPRNotFound, wantStatus: http.StatusNotFound, wantLevel: "INFO"},
+		{name: "bad request logs at info", err: ErrInvalidRequest, wantStatus: http.StatusBadRequest, wantLevel: "INFO"},
+		{name: "unmapped error logs at error", err: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantLevel: "ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			lg := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			rec := httptest.NewRecorder()
+
+			RespondError(rec, lg, tt.err)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			assert.Equal(t, tt.wantLevel, entry["level"])
+			assert.EqualValues(t, tt.wantStatus, entry["status"])
+			assert.Equal(t, tt.err.Error(), entry["error"])
+		})
+	}
+}
+
+func TestRespondError_ContextErrors(t *testing.T) {
+	t.Run("client gone writes no body", func(t *testing.T) {
+		var buf bytes.Buffer
+		lg := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		rec := httptest.NewRecorder()
+		err := fmt.Errorf("failed to get PR: %w", context.Canceled)
+
+		RespondError(rec, lg, err)
+
+		assert.Equal(t, StatusClientClosedRequest, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.Empty(t, rec.Header().Get("Content-Type"))
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "INFO", entry["level"])
+		assert.Equal(t, err.Error(), entry["error"])
+	})
+
+	t.Run("server deadline is timeout", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		RespondError(rec, nil, fmt.Errorf("failed to get PR: %w", context.DeadlineExceeded))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		var resp ErrorResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		assert.Equal(t, ErrorCodeTimeout, resp.Error.Code)
+	})
+}
+
+func TestRespondError_ReadOnly(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	RespondError(rec, nil, fmt.Errorf("failed to insert PR: %w", domain.ErrReadOnly))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var resp ErrorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, ErrorCodeReadOnly, resp.Error.Code)
+}
+
+func TestRespondError_NilLogger(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	RespondError(rec, nil, domain.ErrTeamExists)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestMapError_UnwrapsConflictError(t *testing.T) {
+	err := fmt.Errorf("create: %w", &domain.ConflictError{Err: domain.ErrPRExists, Payload: &domain.PullRequest{}})
+
+	mapping := MapError(err)
+
+	assert.Equal(t, ErrorCodePRExists, mapping.Code)
+	assert.Equal(t, http.StatusConflict, mapping.StatusCode)
+}
+
+func TestRespondError_InvalidRequestDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	RespondError(rec, nil, fmt.Errorf("parse: %w", InvalidRequest(FieldError{Field: "team_name", Rule: "required"})))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"BAD_REQUEST","message":"invalid request"},"details":[{"field":"team_name","rule":"required"}]}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	RespondError(rec, nil, ErrInvalidRequest)
+	assert.NotContains(t, rec.Body.String(), "details")
+}
+
+func TestRespondError_DomainValidationDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	err := domain.Team{TeamName: "backend"}.Validate()
+	RespondError(rec, nil, fmt.Errorf("create team: %w", err))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"details":[{"field":"members","rule":"min=1"}]`)
+}
+
+func TestRespondError_AuthorTeamMissing(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	RespondError(rec, nil, fmt.Errorf("tx failed: %w", domain.ErrAuthorTeamMissing))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"AUTHOR_TEAM_MISSING","message":"author's team does not exist"}}`, rec.Body.String())
+}
+
+func TestRespondError_AuthorInactive(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	RespondError(rec, nil, domain.ErrAuthorInactive)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"AUTHOR_INACTIVE","message":"author is inactive"}}`, rec.Body.String())
+}
+
+func TestRespondError_ReassignRateExceeded(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	RespondError(rec, nil, &domain.ConflictError{Err: domain.ErrReassignRateExceeded})
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"REASSIGNMENT_RATE_EXCEEDED","message":"too many reassignments for this PR, retry later"}}`, rec.Body.String())
+}
+
+func TestRespondError_TeamTooLargeDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	RespondError(rec, nil, domain.CheckTeamSize(1000, 1001))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"TEAM_TOO_LARGE","message":"team would exceed the maximum number of members"},"details":[{"field":"members","rule":"max=1000"}]}`, rec.Body.String())
+}
+
+// TestErrorMappings_CoverDomainErrors - каждая ошибка из domain/errors.go отдаётся со своим кодом, а не INTERNAL_ERROR.
+// Список имён берётся разбором файла, поэтому новая ошибка без записи в domainErrors роняет тест
+func TestErrorMappings_CoverDomainErrors(t *testing.T) {
+	domainErrors := map[string]error{
+		"ErrInvalidInput":          domain.ErrInvalidInput,
+		"ErrTeamExists":            domain.ErrTeamExists,
+		"ErrPRExists":              domain.ErrPRExists,
+		"ErrPRNotOpen":             domain.ErrPRNotOpen,
+		"ErrPRMerged":              domain.ErrPRMerged,
+		"ErrPRClosed":              domain.ErrPRClosed,
+		"ErrNotAssigned":           domain.ErrNotAssigned,
+		"ErrNoCandidate":           domain.ErrNoCandidate,
+		"ErrPRNotFound":            domain.ErrPRNotFound,
+		"ErrTeamNotFound":          domain.ErrTeamNotFound,
+		"ErrUserNotFound":          domain.ErrUserNotFound,
+		"ErrInvalidSchedule":       domain.ErrInvalidSchedule,
+		"ErrInvalidReviewCapacity": domain.ErrInvalidReviewCapacity,
+		"ErrInvalidTransition":     domain.ErrInvalidTransition,
+		"ErrForbidden":             domain.ErrForbidden,
+		"ErrReviewerNotEligible":   domain.ErrReviewerNotEligible,
+		"ErrReviewerInactive":      domain.ErrReviewerInactive,
+		"ErrReviewerIsAuthor":      domain.ErrReviewerIsAuthor,
+		"ErrReviewerNotInTeam":     domain.ErrReviewerNotInTeam,
+		"ErrAlreadyAssigned":       domain.ErrAlreadyAssigned,
+		"ErrReviewerCapReached":    domain.ErrReviewerCapReached,
+		"ErrBlackoutOverlap":       domain.ErrBlackoutOverlap,
+		"ErrBlackoutNotFound":      domain.ErrBlackoutNotFound,
+		"ErrWebhookExists":         domain.ErrWebhookExists,
+		"ErrWebhookNotFound":       domain.ErrWebhookNotFound,
+		"ErrTeamTooLarge":          domain.ErrTeamTooLarge,
+		"ErrAuthorTeamMissing":     domain.ErrAuthorTeamMissing,
+		"ErrAuthorInactive":        domain.ErrAuthorInactive,
+		"ErrReassignRateExceeded":  domain.ErrReassignRateExceeded,
+		"ErrMergeActorRequired":    domain.ErrMergeActorRequired,
+		"ErrMergeActorNotFound":    domain.ErrMergeActorNotFound,
+		"ErrMergeActorInactive":    domain.ErrMergeActorInactive,
+		"ErrMergeNotAllowed":       domain.ErrMergeNotAllowed,
+		"ErrReadOnly":              domain.ErrReadOnly,
+		"ErrOverloaded":            domain.ErrOverloaded,
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "../../../domain/errors.go", nil, 0)
+	require.NoError(t, err)
+	var declared []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				if name.IsExported() && strings.HasPrefix(name.Name, "Err") {
+					declared = append(declared, name.Name)
+				}
+			}
+		}
+	}
+	assert.ElementsMatch(t, declared, slices.Collect(maps.Keys(domainErrors)))
+
+	for name, domainErr := range domainErrors {
+		assert.NotEqual(t, ErrorCodeInternalError, MapError(domainErr).Code, name)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	catalog := Catalog()
+
+	assert.Equal(t, catalog, Catalog(), "order is stable")
+	assert.Len(t, catalog, len(errorMappings)+len(builtinErrors))
+	assert.Equal(t, MapError(domain.ErrTeamExists), catalog[0])
+	assert.Equal(t, []ErrorCode{ErrorCodeRouteNotFound, ErrorCodeMethodNotAllowed, ErrorCodeInternalError}, []ErrorCode{
+		catalog[len(catalog)-3].Code, catalog[len(catalog)-2].Code, catalog[len(catalog)-1].Code,
+	})
+}
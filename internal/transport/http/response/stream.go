@@ -0,0 +1,105 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// streamFlushEvery - через сколько элементов буфер сбрасывается клиенту
+const streamFlushEvery = 100
+
+// ArrayStream пишет JSON-объект, у которого поле key - массив, по одному элементу, не собирая
+// результат в памяти. Статус 200 и начало объекта отправляются с первым элементом или в Close,
+// поэтому ошибку до первого элемента ещё можно вернуть обычным RespondError.
+// Если поток оборван без Close, клиент получит невалидный JSON и сможет отличить обрыв от результата
+type ArrayStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	head    []byte
+	count   int
+	started bool
+	err     error
+}
+
+// NewArrayStream принимает envelope - остальные поля ответа, которые сериализуются в JSON-объект
+func NewArrayStream(w http.ResponseWriter, envelope any, key string) (*ArrayStream, error) {
+	head, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+	if len(head) < 2 || head[0] != '{' || head[len(head)-1] != '}' {
+		return nil, errors.New("stream envelope must be a JSON object")
+	}
+	name, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+
+	head = head[:len(head)-1]
+	if len(head) > 1 {
+		head = append(head, ',')
+	}
+	head = append(head, name...)
+	head = append(head, ':', '[')
+
+	flusher, _ := w.(http.Flusher)
+	return &ArrayStream{w: w, flusher: flusher, head: head}, nil
+}
+
+// Started сообщает, ушли ли заголовки клиенту
+func (s *ArrayStream) Started() bool {
+	return s.started
+}
+
+// Write дописывает элемент массива, после первой ошибки записи все вызовы возвращают её
+func (s *ArrayStream) Write(item any) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	s.start()
+	if s.count > 0 {
+		s.write([]byte{','})
+	}
+	s.write(data)
+	s.count++
+
+	if s.count%streamFlushEvery == 0 {
+		s.flush()
+	}
+	return s.err
+}
+
+// Close закрывает массив и объект
+func (s *ArrayStream) Close() error {
+	s.start()
+	s.write([]byte("]}\n"))
+	s.flush()
+	return s.err
+}
+
+func (s *ArrayStream) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+
+	s.w.Header().Set("Content-Type", "application/json")
+	s.w.WriteHeader(http.StatusOK)
+	s.write(s.head)
+}
+
+func (s *ArrayStream) write(data []byte) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = s.w.Write(data)
+}
+
+func (s *ArrayStream) flush() {
+	if s.err == nil && s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
@@ -0,0 +1,62 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type streamItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestArrayStream_LargeResponse(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	stream, err := NewArrayStream(rec, struct {
+		UserID string `json:"user_id"`
+	}{UserID: "u1"}, "items")
+	require.NoError(t, err)
+
+	const n = 10_000
+	for i := 0; i < n; i++ {
+		require.NoError(t, stream.Write(streamItem{ID: fmt.Sprintf("pr-%d", i), Name: `quoted "name"`}))
+	}
+	require.NoError(t, stream.Close())
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.True(t, rec.Flushed)
+
+	var got struct {
+		UserID string       `json:"user_id"`
+		Items  []streamItem `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, "u1", got.UserID)
+	require.Len(t, got.Items, n)
+	assert.Equal(t, "pr-0", got.Items[0].ID)
+	assert.Equal(t, fmt.Sprintf("pr-%d", n-1), got.Items[n-1].ID)
+}
+
+func TestArrayStream_Empty(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	stream, err := NewArrayStream(rec, struct{}{}, "items")
+	require.NoError(t, err)
+	assert.False(t, stream.Started())
+	require.NoError(t, stream.Close())
+
+	assert.JSONEq(t, `{"items":[]}`, rec.Body.String())
+}
+
+func TestArrayStream_EnvelopeMustBeObject(t *testing.T) {
+	_, err := NewArrayStream(httptest.NewRecorder(), []string{"a"}, "items")
+	assert.Error(t, err)
+}
@@ -8,21 +8,83 @@ import (
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-playground/validator/v10"
 
+	"avito_backend_task/internal/auth"
+	"avito_backend_task/internal/transport/http/handlers/admin"
 	"avito_backend_task/internal/transport/http/handlers/pullrequest"
+	"avito_backend_task/internal/transport/http/handlers/stats"
 	"avito_backend_task/internal/transport/http/handlers/team"
 	"avito_backend_task/internal/transport/http/handlers/user"
 	"avito_backend_task/internal/transport/http/middleware"
+	"avito_backend_task/internal/transport/http/response"
 )
 
 type Services struct {
 	TeamService        team.TeamService
 	UserService        user.UserService
 	PullRequestService pullrequest.PullRequestService
+	JobScheduler       admin.JobScheduler
+	ReviewerService    admin.ReviewerService
+	SchemaChecker      admin.SchemaChecker
+	StatsService       stats.StatsService
 }
 
-func NewRouter(services Services, lg *slog.Logger, validator *validator.Validate) http.Handler {
+type routerConfig struct {
+	apiKeys map[string]auth.Scope
+	metrics http.Handler
+	// compressMinSize < 0 - сжатие выключено
+	compressMinSize int
+	readOnly        bool
+	loadShed        *middleware.LoadShedConfig
+}
+
+type RouterOption func(*routerConfig)
+
+// WithAPIKeys включает проверку X-API-Key для всех маршрутов, кроме /health и /ready
+func WithAPIKeys(keys map[string]auth.Scope) RouterOption {
+	return func(c *routerConfig) {
+		c.apiKeys = keys
+	}
+}
+
+// WithMetrics отдаёт метрики Prometheus на /metrics без проверки API-ключа
+func WithMetrics(handler http.Handler) RouterOption {
+	return func(c *routerConfig) {
+		c.metrics = handler
+	}
+}
+
+// WithCompression сжимает ответы API от minSize байт gzip, служебные /health, /ready и /metrics не сжимаются
+func WithCompression(minSize int) RouterOption {
+	return func(c *routerConfig) {
+		c.compressMinSize = max(minSize, 0)
+	}
+}
+
+// WithReadOnly отвечает READ_ONLY на изменяющие запросы API до обращения к БД, чтение и /ready продолжают работать
+func WithReadOnly() RouterOption {
+	return func(c *routerConfig) {
+		c.readOnly = true
+	}
+}
+
+// WithLoadShedding ограничивает число одновременно обрабатываемых запросов API, /health, /ready и /metrics не ограничиваются
+func WithLoadShedding(cfg middleware.LoadShedConfig) RouterOption {
+	return func(c *routerConfig) {
+		c.loadShed = &cfg
+	}
+}
+
+func NewRouter(services Services, lg *slog.Logger, validator *validator.Validate, opts ...RouterOption) http.Handler {
+	cfg := routerConfig{compressMinSize: -1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	r := chi.NewRouter()
+	r.NotFound(response.RespondRouteNotFound)
+	r.MethodNotAllowed(response.RespondMethodNotAllowed)
 	r.Use(chimiddleware.Recoverer)
+	r.Use(middleware.RequestID)
 	r.Use(middleware.LoggingMiddleware(lg))
 
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -30,19 +92,157 @@ func NewRouter(services Services, lg *slog.Logger, validator *validator.Validate
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(`{"status":"ok"}`))
 	})
+	r.Get("/ready", readyHandler(services.SchemaChecker, lg))
+	if cfg.metrics != nil {
+		r.Method(http.MethodGet, "/metrics", cfg.metrics)
+	}
+
+	r.Group(func(r chi.Router) {
+		if cfg.loadShed != nil {
+			shed := *cfg.loadShed
+			shed.ExemptPaths = append(shed.ExemptPaths, "/pullRequest/events", "/api/v1/pullRequest/events")
+			r.Use(middleware.LoadShed(shed))
+		}
+		if len(cfg.apiKeys) > 0 {
+			r.Use(middleware.APIKeyAuth(cfg.apiKeys, lg))
+		}
+		if cfg.readOnly {
+			// эти маршруты принимают POST, но только читают данные
+			r.Use(middleware.ReadOnly(lg,
+				"/users/validate", "/api/v1/users/validate",
+				"/pullRequest/batchGet", "/api/v1/pullRequest/batchGet"))
+		}
+		if cfg.compressMinSize >= 0 {
+			r.Use(middleware.Compress(cfg.compressMinSize))
+		}
+
+		mountRoutes(r, services, lg, validator, cfg)
+		// /api/v1 повторяет старые маршруты, но все поля ответов в snake_case
+		r.Route("/api/v1", func(r chi.Router) {
+			mountRoutes(r, services, lg, validator, cfg, pullrequest.WithSnakeCase())
+		})
+	})
+
+	return r
+}
+
+func mountRoutes(
+	r chi.Router,
+	services Services,
+	lg *slog.Logger,
+	validator *validator.Validate,
+	cfg routerConfig,
+	prOpts ...pullrequest.Option,
+) {
+	r.Get("/errors", errorsHandler)
 
 	teamHandler := team.NewTeamHandler(services.TeamService, lg, validator)
 	r.Post("/team/add", teamHandler.AddTeam)
 	r.Get("/team/get", teamHandler.GetTeam)
+	r.Get("/team/byMember", teamHandler.GetTeamByMember)
+	r.Get("/team/ageReport", teamHandler.GetAgeReport)
+	r.Get("/team/isHealthy", teamHandler.IsHealthy)
+	r.Post("/team/blackouts", teamHandler.CreateBlackout)
+	r.Delete("/team/blackouts", teamHandler.DeleteBlackout)
+	r.Post("/team/webhooks", teamHandler.CreateWebhook)
+	r.Get("/team/webhooks", teamHandler.GetWebhook)
+	r.Patch("/team/webhooks", teamHandler.UpdateWebhook)
+	r.Delete("/team/webhooks", teamHandler.DeleteWebhook)
+	r.Get("/team/webhooks/status", teamHandler.GetWebhookStatus)
 
 	userHandler := user.NewUserHandler(services.UserService, lg, validator)
+	r.Post("/team/removeMember", userHandler.RemoveFromTeam)
 	r.Post("/users/setIsActive", userHandler.SetIsActive)
+	r.Post("/users/setSchedule", userHandler.SetSchedule)
+	r.Post("/users/setCapacity", userHandler.SetCapacity)
 	r.Get("/users/getReview", userHandler.GetReview)
+	r.Get("/users/timeline", userHandler.GetTimeline)
+	r.Get("/users/formerReviews", userHandler.GetFormerReviews)
+	r.Get("/users/overview", userHandler.GetOverview)
+	r.Post("/users/validate", userHandler.ValidateUserIDs)
 
-	prHandler := pullrequest.NewPullRequestHandler(services.PullRequestService, lg, validator)
+	prHandler := pullrequest.NewPullRequestHandler(services.PullRequestService, lg, validator, prOpts...)
 	r.Post("/pullRequest/create", prHandler.CreatePullRequest)
 	r.Post("/pullRequest/merge", prHandler.MergePullRequest)
+	r.Post("/pullRequest/mergeBulk", prHandler.MergeBulk)
+	r.Patch("/pullRequest", prHandler.UpdatePullRequest)
 	r.Post("/pullRequest/reassign", prHandler.ReassignReviewer)
+	r.Post("/pullRequest/reassignIfInactive", prHandler.ReassignIfInactive)
+	r.Post("/pullRequest/assign", prHandler.AssignReviewer)
+	r.Post("/pullRequest/selfAssign", prHandler.SelfAssign)
+	r.Post("/pullRequest/snooze", prHandler.SnoozeReview)
+	r.Get("/pullRequest/get", prHandler.GetPullRequest)
+	r.Get("/pullRequest/events", prHandler.StreamEvents)
+	r.Post("/pullRequest/batchGet", prHandler.BatchGet)
+	r.Get("/pullRequest/reviewerIds", prHandler.GetReviewerIDs)
+	r.Get("/pullRequest/reviewLatency", prHandler.GetReviewLatency)
+	r.Get("/pullRequest/queueInfo", prHandler.GetQueueInfo)
+	r.Get("/team/policy", prHandler.GetTeamPolicy)
+	r.Post("/team/rebalance", prHandler.RebalanceTeam)
 
-	return r
+	statsHandler := stats.NewStatsHandler(services.StatsService, lg)
+	r.Get("/stats/byAuthor", statsHandler.GetByAuthor)
+
+	adminHandler := admin.NewAdminHandler(services.JobScheduler, services.ReviewerService, services.SchemaChecker, lg)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireAdmin(lg))
+		r.Get("/admin/jobs", adminHandler.GetJobs)
+		r.Get("/admin/schema", adminHandler.GetSchema)
+		r.Get("/admin/anomalies", adminHandler.GetAnomalies)
+		r.Post("/admin/reassignInactive", adminHandler.ReassignInactive)
+		r.Post("/admin/backfill", adminHandler.Backfill)
+		r.Post("/admin/pullRequest/setReviewers", prHandler.SetReviewers)
+		r.Post("/admin/reconcileTeams", teamHandler.ReconcileTeams)
+		// события всех команд, как и сводка ниже
+		r.Get("/events/assignments", userHandler.GetAssignmentEvents)
+		// сводка по всем командам, поэтому ключу команды недоступна
+		r.Get("/stats/global", statsHandler.GetGlobal)
+		// лента PR всех команд
+		r.Get("/pullRequest/recent", prHandler.GetRecentPullRequests)
+	})
+}
+
+// errorCatalogEntry - элемент каталога /errors
+type errorCatalogEntry struct {
+	Code    response.ErrorCode `json:"code"`
+	Status  int                `json:"status"`
+	Message string             `json:"message"`
+}
+
+// errorsHandler отдаёт response.Catalog, поэтому каталог не расходится с тем, что реально возвращает API
+func errorsHandler(w http.ResponseWriter, _ *http.Request) {
+	catalog := response.Catalog()
+	entries := make([]errorCatalogEntry, len(catalog))
+	for i, mapping := range catalog {
+		entries[i] = errorCatalogEntry{Code: mapping.Code, Status: mapping.StatusCode, Message: mapping.Message}
+	}
+
+	response.RespondJSON(w, http.StatusOK, map[string][]errorCatalogEntry{"errors": entries})
+}
+
+// readyHandler не пускает трафик, пока схема БД отстаёт от миграций, встроенных в бинарник
+func readyHandler(schema admin.SchemaChecker, lg *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		status, err := schema.Status(r.Context())
+		if err != nil {
+			lg.Warn("readiness check failed", slog.Any("error", err))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"status":"unavailable"}`))
+			return
+		}
+		if !status.UpToDate() {
+			lg.Warn("database schema is behind",
+				slog.Uint64("expected_version", uint64(status.Expected)),
+				slog.Uint64("applied_version", uint64(status.Applied)),
+				slog.Bool("dirty", status.Dirty))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"status":"schema_outdated"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}
 }
@@ -0,0 +1,519 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/internal/auth"
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/transport/http/handlers/pullrequest/mocks"
+	"avito_backend_task/internal/transport/http/middleware"
+	"avito_backend_task/internal/transport/http/response"
+	"avito_backend_task/internal/transport/http/validation"
+	"avito_backend_task/pkg/db/migrate"
+)
+
+func TestRouter_PullRequestTimestampNaming(t *testing.T) {
+	createdAt := time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC)
+	prService := mocks.NewPullRequestService(t)
+	prService.On("MergePullRequest", mock.Anything, "pr1", "").Return(&domain.PullRequest{
+		PullRequestID: "pr1",
+		Status:        domain.PRStatusMerged,
+		CreatedAt:     &createdAt,
+		MergedAt:      &createdAt,
+	}, nil)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	router := NewRouter(Services{PullRequestService: prService}, logger, validation.New())
+
+	tests := []struct {
+		path    string
+		want    []string
+		notWant []string
+	}{
+		{path: "/pullRequest/merge", want: []string{`"createdAt"`, `"mergedAt"`}, notWant: []string{`"created_at"`}},
+		{path: "/api/v1/pullRequest/merge", want: []string{`"created_at"`, `"merged_at"`}, notWant: []string{`"createdAt"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(`{"pull_request_id":"pr1"}`))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			for _, key := range tt.want {
+				assert.Contains(t, rec.Body.String(), key)
+			}
+			for _, key := range tt.notWant {
+				assert.NotContains(t, rec.Body.String(), key)
+			}
+		})
+	}
+}
+
+func TestRouter_MergeBulk(t *testing.T) {
+	mergedAt := time.Date(2025, 11, 2, 12, 0, 0, 0, time.UTC)
+	prService := mocks.NewPullRequestService(t)
+	prService.On("MergePullRequest", mock.Anything, "pr1", "").Return(&domain.PullRequest{
+		PullRequestID: "pr1", AuthorID: "u1", Status: domain.PRStatusMerged, Priority: domain.PRPriorityNormal, MergedAt: &mergedAt,
+	}, nil)
+	prService.On("MergePullRequest", mock.Anything, "missing", "").Return(nil, domain.ErrPRNotFound)
+	prService.On("MergePullRequest", mock.Anything, "closed", "").Return(nil, domain.ErrInvalidTransition)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	router := NewRouter(Services{PullRequestService: prService}, logger, validation.New())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pullRequest/mergeBulk",
+		strings.NewReader(`{"pull_request_ids":["pr1","missing","closed"]}`))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusMultiStatus, rec.Code)
+	assert.JSONEq(t, `{"succeeded":1,"failed":2,"results":[
+		{"index":0,"status":200,"resource":{"pull_request_id":"pr1","pull_request_name":"","author_id":"u1","status":"MERGED",
+			"priority":"NORMAL","assigned_reviewers":[],"reassignment_count":0,"orphaned":false,"pending_assignment":false,
+			"merged_at":"2025-11-02T12:00:00.000Z"}},
+		{"index":1,"status":404,"error":{"code":"NOT_FOUND","message":"pull request not found"}},
+		{"index":2,"status":409,"error":{"code":"INVALID_TRANSITION","message":"status transition is not allowed"}}]}`,
+		rec.Body.String())
+
+	t.Run("empty list is rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/mergeBulk", strings.NewReader(`{"pull_request_ids":[]}`))
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `{"field":"pull_request_ids","rule":"min=1"}`)
+	})
+}
+
+type fakeSchemaChecker struct {
+	status migrate.Status
+	err    error
+}
+
+func (f fakeSchemaChecker) Status(context.Context) (migrate.Status, error) {
+	return f.status, f.err
+}
+
+func TestRouter_Ready(t *testing.T) {
+	tests := []struct {
+		name       string
+		checker    fakeSchemaChecker
+		wantStatus int
+	}{
+		{name: "schema up to date", checker: fakeSchemaChecker{status: migrate.Status{Expected: 4, Applied: 4}}, wantStatus: http.StatusOK},
+		{name: "schema behind", checker: fakeSchemaChecker{status: migrate.Status{Expected: 4, Applied: 3}}, wantStatus: http.StatusServiceUnavailable},
+		{name: "dirty schema", checker: fakeSchemaChecker{status: migrate.Status{Expected: 4, Applied: 4, Dirty: true}}, wantStatus: http.StatusServiceUnavailable},
+		{name: "database unavailable", checker: fakeSchemaChecker{err: errors.New("db down")}, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := NewRouter(Services{SchemaChecker: tt.checker}, logger, validation.New())
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestRouter_CompressionSkipsMetrics(t *testing.T) {
+	checker := fakeSchemaChecker{status: migrate.Status{Expected: 4, Applied: 4}}
+	metricsBody := strings.Repeat("reviewer_assignments_total 1\n", 200)
+	metricsHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(metricsBody))
+	})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	router := NewRouter(Services{SchemaChecker: checker}, logger, validation.New(),
+		WithMetrics(metricsHandler), WithCompression(0))
+
+	request := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	api := request("/admin/schema")
+	assert.Equal(t, http.StatusOK, api.Code)
+	assert.Equal(t, "gzip", api.Header().Get("Content-Encoding"))
+
+	metrics := request("/metrics")
+	assert.Empty(t, metrics.Header().Get("Content-Encoding"))
+	assert.Equal(t, metricsBody, metrics.Body.String())
+}
+
+func TestRouter_APIKeys(t *testing.T) {
+	keys := map[string]auth.Scope{
+		"admin-key": {},
+		"team-key":  {Team: "backend"},
+	}
+	checker := fakeSchemaChecker{status: migrate.Status{Expected: 4, Applied: 4}}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	metricsHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	router := NewRouter(Services{SchemaChecker: checker}, logger, validation.New(),
+		WithAPIKeys(keys), WithMetrics(metricsHandler))
+
+	tests := []struct {
+		name       string
+		path       string
+		key        string
+		wantStatus int
+	}{
+		{name: "health without key", path: "/health", wantStatus: http.StatusOK},
+		{name: "ready without key", path: "/ready", wantStatus: http.StatusOK},
+		{name: "metrics without key", path: "/metrics", wantStatus: http.StatusOK},
+		{name: "missing key", path: "/admin/schema", wantStatus: http.StatusUnauthorized},
+		{name: "unknown key", path: "/admin/schema", key: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "unknown key on v1", path: "/api/v1/admin/schema", key: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "team key on admin route", path: "/admin/schema", key: "team-key", wantStatus: http.StatusForbidden},
+		{name: "admin key on admin route", path: "/admin/schema", key: "admin-key", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set(middleware.APIKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestRouter_ReadOnly(t *testing.T) {
+	checker := fakeSchemaChecker{status: migrate.Status{Expected: 4, Applied: 4}}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	router := NewRouter(Services{UserService: emptyBackend{}, PullRequestService: emptyBackend{}, SchemaChecker: checker},
+		logger, validation.New(), WithReadOnly())
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "ready", method: http.MethodGet, path: "/ready", wantStatus: http.StatusOK},
+		{name: "read", method: http.MethodGet, path: "/admin/schema", wantStatus: http.StatusOK},
+		{name: "read-only post", method: http.MethodPost, path: "/api/v1/users/validate", body: `{"user_ids":["u1"]}`, wantStatus: http.StatusOK},
+		{name: "batch get", method: http.MethodPost, path: "/pullRequest/batchGet", body: `{"pull_request_ids":["pr1"]}`, wantStatus: http.StatusOK},
+		{name: "create", method: http.MethodPost, path: "/pullRequest/create", body: `{}`, wantStatus: http.StatusServiceUnavailable},
+		{name: "update on v1", method: http.MethodPatch, path: "/api/v1/pullRequest", body: `{}`, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestRouter_QueryParameterDetails(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	// до сервисов запрос не доходит
+	router := NewRouter(Services{}, logger, validation.New())
+
+	tests := []struct {
+		path string
+		want response.FieldError
+	}{
+		{path: "/team/get", want: response.FieldError{Field: "team_name", Rule: "required"}},
+		{path: "/team/byMember", want: response.FieldError{Field: "user_id", Rule: "required"}},
+		{path: "/team/ageReport", want: response.FieldError{Field: "team_name", Rule: "required"}},
+		{path: "/team/isHealthy", want: response.FieldError{Field: "team_name", Rule: "required"}},
+		{path: "/users/getReview", want: response.FieldError{Field: "user_id", Rule: "required"}},
+		{path: "/users/getReview?user_id=u1&stream=maybe", want: response.FieldError{Field: "stream", Rule: "boolean"}},
+		{path: "/users/timeline", want: response.FieldError{Field: "user_id", Rule: "required"}},
+		{path: "/users/formerReviews", want: response.FieldError{Field: "user_id", Rule: "required"}},
+		{path: "/users/timeline?user_id=u1&from=yesterday", want: response.FieldError{Field: "from", Rule: "rfc3339"}},
+		{path: "/users/timeline?user_id=u1&to=2025", want: response.FieldError{Field: "to", Rule: "rfc3339"}},
+		{path: "/users/timeline?user_id=u1&limit=ten", want: response.FieldError{Field: "limit", Rule: "integer"}},
+		{path: "/users/timeline?user_id=u1&limit=0", want: response.FieldError{Field: "limit", Rule: "min=1"}},
+		{path: "/users/timeline?user_id=u1&limit=501", want: response.FieldError{Field: "limit", Rule: "max=500"}},
+		{path: "/users/timeline?user_id=u1&offset=-1", want: response.FieldError{Field: "offset", Rule: "min=0"}},
+		{path: "/users/timeline?user_id=u1&cursor=not-a-cursor", want: response.FieldError{Field: "cursor", Rule: "cursor"}},
+		{path: "/users/timeline?user_id=u1&cursor=MTow&offset=5", want: response.FieldError{Field: "offset", Rule: "excluded_with=cursor"}},
+		{path: "/events/assignments", want: response.FieldError{Field: "since", Rule: "required"}},
+		{path: "/events/assignments?since=yesterday", want: response.FieldError{Field: "since", Rule: "rfc3339"}},
+		{path: "/pullRequest/get", want: response.FieldError{Field: "pull_request_id", Rule: "required"}},
+		{path: "/pullRequest/reviewerIds", want: response.FieldError{Field: "pull_request_id", Rule: "required"}},
+		{path: "/pullRequest/queueInfo", want: response.FieldError{Field: "pull_request_id", Rule: "required"}},
+		{path: "/pullRequest/reviewLatency", want: response.FieldError{Field: "team_name", Rule: "required"}},
+		{path: "/pullRequest/reviewLatency?team_name=backend&from=today", want: response.FieldError{Field: "from", Rule: "rfc3339"}},
+		{path: "/api/v1/team/get", want: response.FieldError{Field: "team_name", Rule: "required"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			var resp response.ErrorResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, response.ErrorCodeBadRequest, resp.Error.Code)
+			assert.Equal(t, []response.FieldError{tt.want}, resp.Details)
+		})
+	}
+}
+
+func TestRouter_IdentifierDetails(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	// до сервисов запрос не доходит
+	router := NewRouter(Services{}, logger, validation.New())
+
+	badIDs := map[string]string{
+		"unicode":        "юзер",
+		"emoji":          "u1\U0001F600",
+		"newline":        "u1\n",
+		"control":        "u1\x00",
+		"leading space":  " u1",
+		"trailing space": "u1 ",
+		"traversal":      "../etc/passwd",
+		"dot segment":    "team/./a",
+	}
+
+	// body - валидный запрос, field подменяется некорректным значением; пустой body - GET с параметром field
+	endpoints := []struct {
+		method string
+		path   string
+		field  string
+		body   map[string]any
+	}{
+		{method: http.MethodPost, path: "/team/add", field: "team_name",
+			body: map[string]any{"team_name": "t", "members": []map[string]any{{"user_id": "u1", "username": "A", "is_active": true}}}},
+		{method: http.MethodPost, path: "/team/add", field: "members[0].user_id",
+			body: map[string]any{"team_name": "t", "members": []map[string]any{{"user_id": "u1", "username": "A", "is_active": true}}}},
+		{method: http.MethodPost, path: "/team/blackouts", field: "team_name",
+			body: map[string]any{"team_name": "t", "starts_at": "2025-11-01T00:00:00Z", "ends_at": "2025-11-02T00:00:00Z"}},
+		{method: http.MethodPost, path: "/team/removeMember", field: "user_id", body: map[string]any{"team_name": "t", "user_id": "u1"}},
+		{method: http.MethodPost, path: "/team/removeMember", field: "team_name", body: map[string]any{"team_name": "t", "user_id": "u1"}},
+		{method: http.MethodPost, path: "/team/rebalance", field: "team_name", body: map[string]any{"team_name": "t"}},
+		{method: http.MethodPost, path: "/users/setIsActive", field: "user_id", body: map[string]any{"user_id": "u1", "is_active": true}},
+		{method: http.MethodPost, path: "/users/setIsActive", field: "team_name",
+			body: map[string]any{"user_id": "u1", "is_active": true, "create_if_missing": true, "username": "A", "team_name": "t"}},
+		{method: http.MethodPost, path: "/users/setSchedule", field: "user_id", body: map[string]any{"user_id": "u1"}},
+		{method: http.MethodPost, path: "/users/setCapacity", field: "user_id", body: map[string]any{"user_id": "u1", "review_capacity": 2}},
+		{method: http.MethodPost, path: "/users/validate", field: "user_ids[0]", body: map[string]any{"user_ids": []string{"u1"}}},
+		{method: http.MethodPost, path: "/pullRequest/create", field: "pull_request_id",
+			body: map[string]any{"pull_request_id": "pr1", "pull_request_name": "PR", "author_id": "u1"}},
+		{method: http.MethodPost, path: "/pullRequest/create", field: "author_id",
+			body: map[string]any{"pull_request_id": "pr1", "pull_request_name": "PR", "author_id": "u1"}},
+		{method: http.MethodPost, path: "/pullRequest/merge", field: "pull_request_id", body: map[string]any{"pull_request_id": "pr1"}},
+		{method: http.MethodPost, path: "/pullRequest/mergeBulk", field: "pull_request_ids[0]", body: map[string]any{"pull_request_ids": []string{"pr1"}}},
+		{method: http.MethodPost, path: "/pullRequest/batchGet", field: "pull_request_ids[0]", body: map[string]any{"pull_request_ids": []string{"pr1"}}},
+		{method: http.MethodPatch, path: "/pullRequest", field: "pull_request_id", body: map[string]any{"pull_request_id": "pr1"}},
+		{method: http.MethodPost, path: "/pullRequest/reassign", field: "old_user_id", body: map[string]any{"pull_request_id": "pr1", "old_user_id": "u1"}},
+		{method: http.MethodPost, path: "/pullRequest/reassignIfInactive", field: "user_id", body: map[string]any{"pull_request_id": "pr1", "user_id": "u1"}},
+		{method: http.MethodPost, path: "/pullRequest/assign", field: "user_id", body: map[string]any{"pull_request_id": "pr1", "user_id": "u1"}},
+		{method: http.MethodPost, path: "/pullRequest/selfAssign", field: "pull_request_id", body: map[string]any{"pull_request_id": "pr1", "user_id": "u1"}},
+		{method: http.MethodPost, path: "/admin/pullRequest/setReviewers", field: "reviewer_ids[0]",
+			body: map[string]any{"pull_request_id": "pr1", "reviewer_ids": []string{"u1"}}},
+		{method: http.MethodGet, path: "/team/get", field: "team_name"},
+		{method: http.MethodGet, path: "/team/byMember", field: "user_id"},
+		{method: http.MethodGet, path: "/team/ageReport", field: "team_name"},
+		{method: http.MethodGet, path: "/team/isHealthy", field: "team_name"},
+		{method: http.MethodDelete, path: "/team/blackouts", field: "team_name"},
+		{method: http.MethodGet, path: "/users/getReview", field: "user_id"},
+		{method: http.MethodGet, path: "/users/timeline", field: "user_id"},
+		{method: http.MethodGet, path: "/users/overview", field: "user_id"},
+		{method: http.MethodGet, path: "/team/policy", field: "team_name"},
+		{method: http.MethodGet, path: "/users/formerReviews", field: "user_id"},
+		{method: http.MethodGet, path: "/pullRequest/get", field: "pull_request_id"},
+		{method: http.MethodGet, path: "/pullRequest/reviewerIds", field: "pull_request_id"},
+		{method: http.MethodGet, path: "/pullRequest/queueInfo", field: "pull_request_id"},
+		{method: http.MethodGet, path: "/pullRequest/reviewLatency", field: "team_name"},
+	}
+
+	for _, ep := range endpoints {
+		for name, bad := range badIDs {
+			t.Run(ep.path+" "+ep.field+" "+name, func(t *testing.T) {
+				var req *http.Request
+				if ep.body == nil {
+					req = httptest.NewRequest(ep.method, ep.path+"?"+url.Values{ep.field: {bad}}.Encode(), nil)
+				} else {
+					body, err := json.Marshal(withBadField(ep.body, ep.field, bad))
+					require.NoError(t, err)
+					req = httptest.NewRequest(ep.method, ep.path, bytes.NewReader(body))
+				}
+				rec := httptest.NewRecorder()
+
+				router.ServeHTTP(rec, req)
+
+				require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+				var resp response.ErrorResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				assert.Equal(t, []response.FieldError{{Field: ep.field, Rule: validation.IdentifierTag}}, resp.Details)
+			})
+		}
+	}
+}
+
+// withBadField копирует тело запроса, подставляя value в поле field вида name, name[0] или name[0].sub
+func withBadField(body map[string]any, field, value string) map[string]any {
+	result := maps.Clone(body)
+	name, rest, nested := strings.Cut(field, "[0]")
+	if !nested {
+		result[name] = value
+		return result
+	}
+
+	switch items := body[name].(type) {
+	case []string:
+		result[name] = []string{value}
+	case []map[string]any:
+		item := maps.Clone(items[0])
+		item[strings.TrimPrefix(rest, ".")] = value
+		result[name] = []map[string]any{item}
+	}
+	return result
+}
+
+func TestRouter_CreatedLocation(t *testing.T) {
+	backend := emptyBackend{}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	router := NewRouter(Services{
+		TeamService:        backend,
+		UserService:        backend,
+		PullRequestService: backend,
+	}, logger, validation.New())
+
+	tests := []struct {
+		path string
+		body string
+		want string
+	}{
+		{
+			path: "/team/add",
+			body: `{"team_name":"core/team","members":[{"user_id":"u1","username":"A","is_active":true}]}`,
+			want: "/team/get?team_name=core%2Fteam",
+		},
+		{
+			path: "/team/blackouts",
+			body: `{"team_name":"core/team","starts_at":"2025-11-01T00:00:00Z","ends_at":"2025-11-02T00:00:00Z"}`,
+			want: "/team/get?team_name=core%2Fteam",
+		},
+		{
+			path: "/pullRequest/create",
+			body: `{"pull_request_id":"pr#1","pull_request_name":"PR","author_id":"u1"}`,
+			want: "/pullRequest/get?pull_request_id=pr%231",
+		},
+		{
+			path: "/users/setIsActive",
+			body: `{"user_id":"u#9","is_active":true,"create_if_missing":true,"username":"A","team_name":"backend"}`,
+			want: "/team/byMember?user_id=u%239",
+		},
+	}
+
+	for _, prefix := range []string{"", "/api/v1"} {
+		for _, tt := range tests {
+			t.Run(prefix+tt.path, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodPost, prefix+tt.path, strings.NewReader(tt.body))
+				rec := httptest.NewRecorder()
+
+				router.ServeHTTP(rec, req)
+
+				require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+				assert.Equal(t, prefix+tt.want, rec.Header().Get("Location"))
+			})
+		}
+	}
+}
+
+func TestRouter_SetIsActiveCreateIfMissingValidation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	// до сервиса запрос не доходит
+	router := NewRouter(Services{}, logger, validation.New())
+
+	bodies := []string{
+		`{"user_id":"u9","is_active":true,"create_if_missing":true,"team_name":"backend"}`,
+		`{"user_id":"u9","is_active":true,"create_if_missing":true,"username":"New"}`,
+	}
+
+	for _, body := range bodies {
+		req := httptest.NewRequest(http.MethodPost, "/users/setIsActive", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+}
+
+func TestRouter_ErrorCatalog(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	router := NewRouter(Services{}, logger, validation.New())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/errors", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Errors []struct {
+			Code    response.ErrorCode `json:"code"`
+			Status  int                `json:"status"`
+			Message string             `json:"message"`
+		} `json:"errors"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.Len(t, body.Errors, len(response.Catalog()))
+	for i, mapping := range response.Catalog() {
+		assert.Equal(t, mapping.Code, body.Errors[i].Code)
+		assert.Equal(t, mapping.StatusCode, body.Errors[i].Status)
+		assert.Equal(t, mapping.Message, body.Errors[i].Message)
+	}
+
+	t.Run("unknown route", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/nope", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.JSONEq(t, `{"error":{"code":"ROUTE_NOT_FOUND","message":"route not found"}}`, rec.Body.String())
+	})
+
+	t.Run("unsupported method", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/errors", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.JSONEq(t, `{"error":{"code":"METHOD_NOT_ALLOWED","message":"method not allowed"}}`, rec.Body.String())
+	})
+}
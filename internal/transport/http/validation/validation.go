@@ -0,0 +1,55 @@
+// Package validation настраивает общий validator для DTO запросов и переводит его ошибки в details ответа
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"avito_backend_task/internal/domain"
+	"avito_backend_task/internal/transport/http/response"
+)
+
+// IdentifierTag - правило для pull_request_id, user_id и team_name
+const IdentifierTag = domain.RuleIdentifier
+
+// New возвращает validator с правилом identifier, в ошибках поля называются по json-тегам
+func New() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	// регистрация встроенного по имени тега не может завершиться ошибкой
+	_ = v.RegisterValidation(IdentifierTag, func(fl validator.FieldLevel) bool {
+		return domain.IsIdentifier(fl.Field().String())
+	})
+
+	return v
+}
+
+// Details переводит ошибку validator.Struct в response.InvalidRequestError с полем и правилом каждого нарушения
+func Details(err error) error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return response.ErrInvalidRequest
+	}
+
+	details := make([]response.FieldError, len(verrs))
+	for i, fe := range verrs {
+		// Namespace начинается с имени типа DTO: TeamDTO.members[0].user_id
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		rule := fe.Tag()
+		if fe.Param() != "" {
+			rule += "=" + fe.Param()
+		}
+		details[i] = response.FieldError{Field: field, Rule: rule}
+	}
+
+	return response.InvalidRequest(details...)
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_pr_reviewer_events_user_id;
+DROP TABLE IF EXISTS pr_reviewer_events;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS pr_reviewer_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(64) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(16) NOT NULL CHECK (event_type IN ('ASSIGNED', 'REMOVED')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewer_events_user_id ON pr_reviewer_events(user_id, created_at);
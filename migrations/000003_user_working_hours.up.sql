@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS working_hours VARCHAR(64) NULL,
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NULL;
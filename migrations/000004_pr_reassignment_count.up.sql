@@ -0,0 +1,2 @@
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS reassignment_count INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS removed_at TIMESTAMP WITH TIME ZONE NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_pull_requests_pending;
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS pending_assignment;
+
+DROP TABLE IF EXISTS team_blackouts;
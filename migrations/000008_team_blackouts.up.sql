@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS team_blackouts (
+    blackout_id BIGSERIAL PRIMARY KEY,
+    team_name VARCHAR(64) NOT NULL REFERENCES teams(team_name) ON UPDATE CASCADE ON DELETE CASCADE,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_blackouts_team_ends ON team_blackouts(team_name, ends_at);
+
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS pending_assignment BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_pending ON pull_requests(pull_request_id) WHERE pending_assignment;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_pr_reviewer_events_created_at;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_pr_reviewer_events_created_at ON pr_reviewer_events(created_at, event_id);
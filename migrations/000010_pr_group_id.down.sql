@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pull_requests_group_open;
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS group_id;
@@ -0,0 +1,4 @@
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS group_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_group_open ON pull_requests(group_id) WHERE status = 'OPEN' AND group_id IS NOT NULL;
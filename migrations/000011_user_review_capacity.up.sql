@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS review_capacity DOUBLE PRECISION NOT NULL DEFAULT 1 CHECK (review_capacity > 0);
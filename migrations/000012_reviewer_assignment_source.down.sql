@@ -0,0 +1,5 @@
+ALTER TABLE pr_reviewer_events
+    DROP COLUMN IF EXISTS assignment_source;
+
+ALTER TABLE pr_reviewers
+    DROP COLUMN IF EXISTS assignment_source;
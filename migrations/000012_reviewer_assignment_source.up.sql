@@ -0,0 +1,7 @@
+-- назначения до миграции считаются автоматическими, у событий снятия источника нет
+ALTER TABLE pr_reviewers
+    ADD COLUMN IF NOT EXISTS assignment_source VARCHAR(16) NOT NULL DEFAULT 'AUTO'
+        CHECK (assignment_source IN ('AUTO', 'MANUAL', 'REASSIGNMENT', 'REBALANCE', 'ADMIN'));
+
+ALTER TABLE pr_reviewer_events
+    ADD COLUMN IF NOT EXISTS assignment_source VARCHAR(16) NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS merged_understaffed,
+    DROP COLUMN IF EXISTS merged_reviewer_count;
@@ -0,0 +1,4 @@
+-- у PR, смерженных до миграции, состав на момент merge неизвестен
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS merged_reviewer_count INTEGER NULL,
+    ADD COLUMN IF NOT EXISTS merged_understaffed BOOLEAN NOT NULL DEFAULT FALSE;
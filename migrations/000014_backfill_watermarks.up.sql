@@ -0,0 +1,7 @@
+-- прогресс разовых backfill-задач: последняя обработанная строка, с которой продолжается следующий запуск
+CREATE TABLE IF NOT EXISTS backfill_watermarks (
+    name VARCHAR(64) PRIMARY KEY,
+    last_pull_request_id VARCHAR(64) NOT NULL DEFAULT '',
+    last_user_id VARCHAR(64) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- кто смержил PR, у PR, смерженных без merged_by или до миграции, NULL
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS merged_by VARCHAR(64) NULL;
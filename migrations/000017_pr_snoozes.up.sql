@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS pr_snoozes (
+    pull_request_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    snoozed_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (pull_request_id, user_id),
+    -- снятие ревьюера снимает и его snooze
+    FOREIGN KEY (pull_request_id, user_id) REFERENCES pr_reviewers(pull_request_id, user_id) ON DELETE CASCADE
+);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_pr_created_at ON pull_requests(created_at DESC, pull_request_id DESC);
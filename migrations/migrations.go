@@ -0,0 +1,7 @@
+// Package migrations встраивает SQL-миграции в бинарник, чтобы сервис знал ожидаемую версию схемы
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -3,10 +3,15 @@ package db
 import (
 	"context"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
+	"github.com/avito-tech/go-transaction-manager/trm/v2"
 	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/settings"
+
+	"avito_backend_task/pkg/logctx"
 )
 
 type DB struct {
@@ -25,8 +30,20 @@ func (db *DB) Conn(ctx context.Context) trmpgx.Tr {
 	return db.getter.DefaultTrOrDB(ctx, db.pool)
 }
 
+// AdvisoryXactLock берёт pg_advisory_xact_lock, лок снимается при завершении текущей транзакции
+func (db *DB) AdvisoryXactLock(ctx context.Context, name string) error {
+	_, err := db.Conn(ctx).Exec(ctx, "SELECT pg_advisory_xact_lock($1)", AdvisoryLockKey(name))
+	return err
+}
+
 type TransactionManagerInterface interface {
 	Do(ctx context.Context, fn func(ctx context.Context) error) error
+	// DoReadOnly выполняет fn в транзакции READ ONLY REPEATABLE READ: все её запросы видят один снимок.
+	// Транзакция занимает одно соединение, поэтому запросы внутри fn нельзя выполнять параллельно
+	DoReadOnly(ctx context.Context, fn func(ctx context.Context) error) error
+	// DoNested выполняет fn в savepoint текущей транзакции (вне транзакции - в отдельной): ошибка fn
+	// откатывает только её запросы, и транзакция остаётся пригодной для продолжения
+	DoNested(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type TransactionManager struct {
@@ -44,5 +61,29 @@ func NewTransactionManager(pool *pgxpool.Pool) (*TransactionManager, error) {
 }
 
 func (tm *TransactionManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
-	return tm.manager.Do(ctx, fn)
+	return tm.manager.Do(WithTxID(ctx), fn)
+}
+
+// WithTxID помечает контекст новым tx_id для логов (logctx), если он ещё не внутри транзакции:
+// вложенный Do и savepoint DoNested выполняются в той же транзакции и сохраняют её tx_id
+func WithTxID(ctx context.Context) context.Context {
+	if logctx.TxID(ctx) != "" {
+		return ctx
+	}
+	return logctx.WithTxID(ctx, logctx.NewID())
+}
+
+var readOnlySettings = trmpgx.MustSettings(settings.Must(), trmpgx.WithTxOptions(pgx.TxOptions{
+	IsoLevel:   pgx.RepeatableRead,
+	AccessMode: pgx.ReadOnly,
+}))
+
+func (tm *TransactionManager) DoReadOnly(ctx context.Context, fn func(ctx context.Context) error) error {
+	return tm.manager.DoWithSettings(WithTxID(ctx), readOnlySettings, fn)
+}
+
+var nestedSettings = settings.Must(settings.WithPropagation(trm.PropagationNested))
+
+func (tm *TransactionManager) DoNested(ctx context.Context, fn func(ctx context.Context) error) error {
+	return tm.manager.DoWithSettings(WithTxID(ctx), nestedSettings, fn)
 }
@@ -0,0 +1,102 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// имя таблицы, которую ведёт golang-migrate
+const migrationsTable = "schema_migrations"
+
+const undefinedTableCode = "42P01"
+
+var upMigrationRe = regexp.MustCompile(`^(\d+)_.+\.up\.sql$`)
+
+// LatestVersion возвращает номер последней up-миграции в fsys
+func LatestVersion(fsys fs.FS) (uint, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var latest uint
+	for _, entry := range entries {
+		m := upMigrationRe.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration version %q: %w", entry.Name(), err)
+		}
+		latest = max(latest, uint(version))
+	}
+
+	return latest, nil
+}
+
+type VersionSource interface {
+	AppliedVersion(ctx context.Context) (version uint, dirty bool, err error)
+}
+
+// PoolVersionSource читает применённую версию из schema_migrations
+type PoolVersionSource struct {
+	pool *pgxpool.Pool
+}
+
+func NewVersionSource(pool *pgxpool.Pool) *PoolVersionSource {
+	return &PoolVersionSource{pool: pool}
+}
+
+// до первого запуска migrate таблицы нет, это версия 0
+func (s *PoolVersionSource) AppliedVersion(ctx context.Context) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := s.pool.QueryRow(ctx, "SELECT version, dirty FROM "+migrationsTable+" LIMIT 1").Scan(&version, &dirty)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.Is(err, pgx.ErrNoRows) || (errors.As(err, &pgErr) && pgErr.Code == undefinedTableCode) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	return uint(version), dirty, nil
+}
+
+type Status struct {
+	Expected uint
+	Applied  uint
+	Dirty    bool
+}
+
+// UpToDate допускает схему новее бинарника: при выкатке старые реплики работают поверх новых миграций
+func (s Status) UpToDate() bool {
+	return !s.Dirty && s.Applied >= s.Expected
+}
+
+type Checker struct {
+	expected uint
+	source   VersionSource
+}
+
+func NewChecker(expected uint, source VersionSource) *Checker {
+	return &Checker{expected: expected, source: source}
+}
+
+func (c *Checker) Status(ctx context.Context) (Status, error) {
+	applied, dirty, err := c.source.AppliedVersion(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+
+	return Status{Expected: c.expected, Applied: applied, Dirty: dirty}, nil
+}
@@ -0,0 +1,77 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"avito_backend_task/migrations"
+)
+
+type fakeSource struct {
+	version uint
+	dirty   bool
+	err     error
+}
+
+func (f fakeSource) AppliedVersion(context.Context) (uint, bool, error) {
+	return f.version, f.dirty, f.err
+}
+
+func TestLatestVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000001_init.up.sql":   {},
+		"000001_init.down.sql": {},
+		"000012_tags.up.sql":   {},
+		"000012_tags.down.sql": {},
+		"000003_x.up.sql":      {},
+		"README.md":            {},
+	}
+
+	version, err := LatestVersion(fsys)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(12), version)
+}
+
+func TestLatestVersion_Embedded(t *testing.T) {
+	version, err := LatestVersion(migrations.FS)
+
+	require.NoError(t, err)
+	assert.Positive(t, version)
+}
+
+func TestChecker_Status(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   fakeSource
+		upToDate bool
+	}{
+		{name: "same version", source: fakeSource{version: 4}, upToDate: true},
+		{name: "database ahead", source: fakeSource{version: 5}, upToDate: true},
+		{name: "database behind", source: fakeSource{version: 3}, upToDate: false},
+		{name: "not migrated", source: fakeSource{}, upToDate: false},
+		{name: "dirty", source: fakeSource{version: 4, dirty: true}, upToDate: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := NewChecker(4, tt.source).Status(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, uint(4), status.Expected)
+			assert.Equal(t, tt.source.version, status.Applied)
+			assert.Equal(t, tt.source.dirty, status.Dirty)
+			assert.Equal(t, tt.upToDate, status.UpToDate())
+		})
+	}
+
+	t.Run("source error", func(t *testing.T) {
+		_, err := NewChecker(4, fakeSource{err: errors.New("db down")}).Status(context.Background())
+		assert.Error(t, err)
+	})
+}
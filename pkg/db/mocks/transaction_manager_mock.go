@@ -2,14 +2,34 @@ package mocks
 
 import (
 	"context"
+
+	"avito_backend_task/pkg/db"
 )
 
+type txKey struct{}
+
 type MockTransactionManager struct{}
 
 func NewMockTransactionManager() *MockTransactionManager {
 	return &MockTransactionManager{}
 }
 
+// Do помечает контекст транзакции, чтобы тесты могли проверить, какие вызовы репозиториев выполнены внутри неё,
+// и, как настоящий менеджер, даёт ему tx_id
 func (m *MockTransactionManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
-	return fn(ctx)
+	return fn(context.WithValue(db.WithTxID(ctx), txKey{}, true))
+}
+
+func (m *MockTransactionManager) DoReadOnly(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(db.WithTxID(ctx), txKey{}, true))
+}
+
+func (m *MockTransactionManager) DoNested(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(db.WithTxID(ctx), txKey{}, true))
+}
+
+// InTransaction - ctx получен внутри Do, DoReadOnly или DoNested мок-менеджера
+func InTransaction(ctx context.Context) bool {
+	inTx, _ := ctx.Value(txKey{}).(bool)
+	return inTx
 }
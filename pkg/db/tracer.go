@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const redactedArg = "[REDACTED]"
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	sql  string
+	args []any
+	at   time.Time
+}
+
+// QueryTracer пишет в debug-лог каждый выполненный SQL-запрос с аргументами и длительностью
+type QueryTracer struct {
+	lg         *slog.Logger
+	redactArgs bool
+}
+
+// при redactArgs значения аргументов заменяются на [REDACTED], сохраняется только их количество
+func NewQueryTracer(lg *slog.Logger, redactArgs bool) *QueryTracer {
+	return &QueryTracer{lg: lg, redactArgs: redactArgs}
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{
+		sql:  data.SQL,
+		args: data.Args,
+		at:   time.Now(),
+	})
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("sql", start.sql),
+		slog.Any("args", t.args(start.args)),
+		slog.Duration("duration", time.Since(start.at)),
+	}
+	if data.Err != nil {
+		attrs = append(attrs, slog.Any("error", data.Err))
+	} else {
+		attrs = append(attrs, slog.Int64("rows_affected", data.CommandTag.RowsAffected()))
+	}
+
+	t.lg.LogAttrs(ctx, slog.LevelDebug, "sql query", attrs...)
+}
+
+func (t *QueryTracer) args(args []any) []any {
+	if !t.redactArgs {
+		return args
+	}
+
+	redacted := make([]any, len(args))
+	for i := range args {
+		redacted[i] = redactedArg
+	}
+	return redacted
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func traceQuery(t *testing.T, tracer *QueryTracer, err error) {
+	t.Helper()
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+		SQL:  "SELECT * FROM users WHERE user_id = $1",
+		Args: []any{"u1"},
+	})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{
+		CommandTag: pgconn.NewCommandTag("SELECT 1"),
+		Err:        err,
+	})
+}
+
+func TestQueryTracer(t *testing.T) {
+	newTracer := func(level slog.Level, redact bool) (*QueryTracer, *bytes.Buffer) {
+		var buf bytes.Buffer
+		lg := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level}))
+		return NewQueryTracer(lg, redact), &buf
+	}
+
+	decode := func(t *testing.T, buf *bytes.Buffer) map[string]any {
+		t.Helper()
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		return entry
+	}
+
+	t.Run("logs statement with args", func(t *testing.T) {
+		tracer, buf := newTracer(slog.LevelDebug, false)
+		traceQuery(t, tracer, nil)
+
+		entry := decode(t, buf)
+		assert.Equal(t, "sql query", entry["msg"])
+		assert.Equal(t, "DEBUG", entry["level"])
+		assert.Equal(t, "SELECT * FROM users WHERE user_id = $1", entry["sql"])
+		assert.Equal(t, []any{"u1"}, entry["args"])
+		assert.Contains(t, entry, "duration")
+		assert.EqualValues(t, 1, entry["rows_affected"])
+	})
+
+	t.Run("redacts args", func(t *testing.T) {
+		tracer, buf := newTracer(slog.LevelDebug, true)
+		traceQuery(t, tracer, nil)
+
+		entry := decode(t, buf)
+		assert.Equal(t, []any{redactedArg}, entry["args"])
+		assert.NotContains(t, buf.String(), "u1")
+	})
+
+	t.Run("logs query error", func(t *testing.T) {
+		tracer, buf := newTracer(slog.LevelDebug, false)
+		traceQuery(t, tracer, errors.New("syntax error"))
+
+		entry := decode(t, buf)
+		assert.Equal(t, "syntax error", entry["error"])
+		assert.NotContains(t, entry, "rows_affected")
+	})
+
+	t.Run("silent above debug level", func(t *testing.T) {
+		tracer, buf := newTracer(slog.LevelInfo, false)
+		traceQuery(t, tracer, nil)
+
+		assert.Empty(t, buf.String())
+	})
+}
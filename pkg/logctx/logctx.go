@@ -0,0 +1,96 @@
+// Package logctx переносит идентификаторы запроса и транзакции через context в slog:
+// каждая запись, сделанная с таким контекстом, получает атрибуты request_id и tx_id
+package logctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+type requestIDKey struct{}
+
+type txIDKey struct{}
+
+// NewID - случайный идентификатор для request_id и tx_id
+func NewID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID - идентификатор HTTP-запроса, пусто вне запроса (фоновые задачи)
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func WithTxID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, txIDKey{}, id)
+}
+
+// TxID - идентификатор текущей транзакции, пусто вне транзакции
+func TxID(ctx context.Context) string {
+	id, _ := ctx.Value(txIDKey{}).(string)
+	return id
+}
+
+// Handler дописывает к записи request_id и tx_id из контекста *Context-вызова, а если их там нет -
+// из контекста, к которому логгер привязан через With
+type Handler struct {
+	inner slog.Handler
+	bound context.Context
+}
+
+func NewHandler(inner slog.Handler) *Handler {
+	return &Handler{inner: inner}
+}
+
+// With - логгер, записи которого получают идентификаторы из ctx, в том числе через Debug, Info
+// и другие вызовы без контекста. Записи с контекстом транзакции (DebugContext(txCtx, ...)) получают её tx_id
+func With(ctx context.Context, lg *slog.Logger, args ...any) *slog.Logger {
+	h, ok := lg.Handler().(*Handler)
+	if !ok {
+		h = NewHandler(lg.Handler())
+	}
+	return slog.New(&Handler{inner: h.inner, bound: ctx}).With(args...)
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if id := h.lookup(ctx, RequestID); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if id := h.lookup(ctx, TxID); id != "" {
+		record.AddAttrs(slog.String("tx_id", id))
+	}
+	return h.inner.Handle(ctx, record)
+}
+
+func (h *Handler) lookup(ctx context.Context, get func(context.Context) string) string {
+	if ctx != nil {
+		if id := get(ctx); id != "" {
+			return id
+		}
+	}
+	if h.bound != nil {
+		return get(h.bound)
+	}
+	return ""
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{inner: h.inner.WithAttrs(attrs), bound: h.bound}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{inner: h.inner.WithGroup(name), bound: h.bound}
+}
@@ -0,0 +1,65 @@
+package logctx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capture - логгер, чьи записи можно прочитать как JSON-объекты
+func capture(t *testing.T) (*slog.Logger, func() []map[string]any) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	lg := slog.New(NewHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	return lg, func() []map[string]any {
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var record map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &record))
+			records = append(records, record)
+		}
+		return records
+	}
+}
+
+func TestHandler_AddsIDsFromContext(t *testing.T) {
+	lg, records := capture(t)
+	ctx := WithRequestID(context.Background(), "req-1")
+	txCtx := WithTxID(ctx, "tx-1")
+
+	log := With(ctx, lg, slog.String("op", "Test"))
+	log.Info("outside transaction")
+	log.InfoContext(txCtx, "inside transaction")
+	lg.Info("no context")
+
+	got := records()
+	require.Len(t, got, 3)
+
+	assert.Equal(t, "req-1", got[0]["request_id"])
+	assert.Equal(t, "Test", got[0]["op"])
+	assert.NotContains(t, got[0], "tx_id")
+
+	assert.Equal(t, "req-1", got[1]["request_id"])
+	assert.Equal(t, "tx-1", got[1]["tx_id"])
+
+	assert.NotContains(t, got[2], "request_id")
+	assert.NotContains(t, got[2], "tx_id")
+}
+
+func TestWith_WrapsPlainLogger(t *testing.T) {
+	var buf bytes.Buffer
+	lg := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	With(WithTxID(context.Background(), "tx-1"), lg).Info("wrapped")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "tx-1", record["tx_id"])
+}